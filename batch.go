@@ -0,0 +1,126 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// batchRecorder is the response writer used to record the responses of the items of batch
+// requests.
+type batchRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+// Header returns the response headers.
+func (r *batchRecorder) Header() http.Header { return r.header }
+
+// Write records the response body.
+func (r *batchRecorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+
+// WriteHeader is a no-op, the status code is recorded by the context response data.
+func (r *batchRecorder) WriteHeader(int) {}
+
+// BatchItem runs the handler of a single item of a batch request and returns the status code and
+// the body of the response it sends. The code generated for the batch actions defined with the
+// Batch DSL calls BatchItem for each item so that the failure of an item does not fail the others.
+// The handler is given a context whose response data records the response, errors returned by the
+// handler are rendered as error responses. The body is the raw JSON sent by the handler, the
+// response body as a string if it is not JSON or nil if the handler sent no body.
+func BatchItem(ctx context.Context, req *http.Request, h func(context.Context) error) (int, interface{}) {
+	var params url.Values
+	if reqData := ContextRequest(ctx); reqData != nil {
+		params = reqData.Params
+	}
+	rec := &batchRecorder{header: make(http.Header)}
+	ictx := NewContext(ctx, rec, req, params)
+	resp := ContextResponse(ictx)
+	if parent := ContextResponse(ctx); parent != nil {
+		resp.Service = parent.Service
+	}
+	if err := h(ictx); err != nil {
+		se, ok := err.(ServiceError)
+		if !ok {
+			LogError(ctx, "batch item failed", "err", err)
+			se = ErrInternal(http.StatusText(http.StatusInternalServerError)).(ServiceError)
+		}
+		return se.ResponseStatus(), se
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	b := rec.body.Bytes()
+	switch {
+	case len(bytes.TrimSpace(b)) == 0:
+		return status, nil
+	case json.Valid(b):
+		return status, json.RawMessage(b)
+	default:
+		return status, string(b)
+	}
+}
+
+// ReplayIdempotentItem wraps the handler of the item with the given index of a batch request so
+// that the response of the item is replayed to the retries of the batch request that carry the
+// same Idempotency-Key header value, see ReplayIdempotent. Items are identified by a SHA-256 hash
+// of the batch request method, URI and idempotency key and of the item index, the items of
+// requests without key are handled normally. Only the 2xx responses are stored so that failed
+// items can be retried. The code generated for the batch actions derived from actions that
+// define the Cacheable DSL calls ReplayIdempotentItem for each item, the returned handler must be
+// given the item context created by BatchItem.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func (service *Service) ReplayIdempotentItem(index int, h func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		req := ContextRequest(ctx)
+		resp := ContextResponse(ctx)
+		k := req.Header.Get(IdempotencyKeyHeader)
+		if k == "" {
+			return h(ctx)
+		}
+		store := service.DedupeStore
+		if store == nil {
+			return ErrNoDedupeStore("no dedupe store set on service")
+		}
+		hash := sha256.New()
+		io.WriteString(hash, req.Method)
+		io.WriteString(hash, " ")
+		io.WriteString(hash, req.URL.RequestURI())
+		io.WriteString(hash, "\nkey:")
+		io.WriteString(hash, k)
+		fmt.Fprintf(hash, "\nitem:%d", index)
+		key := hex.EncodeToString(hash.Sum(nil))
+		cached, ok, err := store.Get(ctx, key)
+		if err != nil {
+			service.LogError("dedupe store get failed", "err", err)
+		} else if ok {
+			for k, v := range cached.Header {
+				resp.Header()[k] = v
+			}
+			resp.WriteHeader(cached.Status)
+			resp.Write(cached.Body)
+			return nil
+		}
+		rw := resp.ResponseWriter
+		rec := &dedupeRecorder{ResponseWriter: rw}
+		resp.ResponseWriter = rec
+		err = h(ctx)
+		resp.ResponseWriter = rw
+		if err != nil || rec.status < 200 || rec.status > 299 {
+			return err
+		}
+		cached = &CachedResponse{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}
+		if err := store.Set(ctx, key, cached, IdempotentResponseTTL); err != nil {
+			service.LogError("dedupe store set failed", "err", err)
+		}
+		return nil
+	}
+}
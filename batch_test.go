@@ -0,0 +1,149 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BatchItem", func() {
+	var service *goa.Service
+	var ctx context.Context
+	var handler func(context.Context) error
+	var status int
+	var body interface{}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		req, _ := http.NewRequest("POST", "/bottles/batch", nil)
+		ctx = goa.NewContext(context.Background(), httptest.NewRecorder(), req, nil)
+		goa.ContextResponse(ctx).Service = service
+	})
+
+	JustBeforeEach(func() {
+		status, body = goa.BatchItem(ctx, goa.ContextRequest(ctx).Request, handler)
+	})
+
+	Context("with a handler that succeeds", func() {
+		BeforeEach(func() {
+			handler = func(ctx context.Context) error {
+				return service.Send(ctx, 201, map[string]interface{}{"id": 1})
+			}
+		})
+
+		It("returns the status and body of the item response", func() {
+			Ω(status).Should(Equal(201))
+			Ω(body).Should(BeAssignableToTypeOf(json.RawMessage{}))
+			Ω(string(body.(json.RawMessage))).Should(MatchJSON(`{"id":1}`))
+		})
+
+		It("does not write the batch response", func() {
+			Ω(goa.ContextResponse(ctx).Written()).Should(BeFalse())
+		})
+	})
+
+	Context("with a handler that returns a service error", func() {
+		BeforeEach(func() {
+			handler = func(context.Context) error {
+				return goa.ErrBadRequest("invalid item")
+			}
+		})
+
+		It("reports the error for the item", func() {
+			Ω(status).Should(Equal(400))
+			Ω(body).Should(BeAssignableToTypeOf(&goa.ErrorResponse{}))
+			Ω(body.(*goa.ErrorResponse).Detail).Should(Equal("invalid item"))
+		})
+	})
+
+	Context("with a handler that returns another error", func() {
+		BeforeEach(func() {
+			handler = func(context.Context) error {
+				return errors.New("boom")
+			}
+		})
+
+		It("reports an internal error for the item", func() {
+			Ω(status).Should(Equal(500))
+			Ω(body.(*goa.ErrorResponse).Detail).ShouldNot(ContainSubstring("boom"))
+		})
+	})
+})
+
+var _ = Describe("ReplayIdempotentItem", func() {
+	var service *goa.Service
+	var store *fakeDedupeStore
+	var calls int
+	var handler func(context.Context) error
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		store = &fakeDedupeStore{responses: make(map[string]*goa.CachedResponse)}
+		service.DedupeStore = store
+		calls = 0
+		handler = func(ctx context.Context) error {
+			calls++
+			return service.Send(ctx, 201, map[string]interface{}{"id": calls})
+		}
+	})
+
+	send := func(key string, index int) (int, interface{}) {
+		req, _ := http.NewRequest("POST", "/bottles/batch", nil)
+		if key != "" {
+			req.Header.Set(goa.IdempotencyKeyHeader, key)
+		}
+		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, nil)
+		goa.ContextResponse(ctx).Service = service
+		return goa.BatchItem(ctx, req, service.ReplayIdempotentItem(index, handler))
+	}
+
+	It("returns the cached response of the item to retries", func() {
+		status, body := send("key", 0)
+		Ω(status).Should(Equal(201))
+		Ω(string(body.(json.RawMessage))).Should(MatchJSON(`{"id":1}`))
+		status, body = send("key", 0)
+		Ω(calls).Should(Equal(1))
+		Ω(status).Should(Equal(201))
+		Ω(string(body.(json.RawMessage))).Should(MatchJSON(`{"id":1}`))
+		Ω(store.ttl).Should(Equal(goa.IdempotentResponseTTL))
+	})
+
+	It("handles the other items of the request", func() {
+		send("key", 0)
+		_, body := send("key", 1)
+		Ω(calls).Should(Equal(2))
+		Ω(string(body.(json.RawMessage))).Should(MatchJSON(`{"id":2}`))
+	})
+
+	It("handles the items of requests without idempotency key", func() {
+		send("", 0)
+		send("", 0)
+		Ω(calls).Should(Equal(2))
+		Ω(store.responses).Should(BeEmpty())
+	})
+
+	Context("with an item that fails", func() {
+		BeforeEach(func() {
+			handler = func(context.Context) error {
+				calls++
+				return goa.ErrBadRequest("invalid item")
+			}
+		})
+
+		It("does not store the response", func() {
+			status, _ := send("key", 0)
+			Ω(status).Should(Equal(400))
+			send("key", 0)
+			Ω(calls).Should(Equal(2))
+			Ω(store.responses).Should(BeEmpty())
+		})
+	})
+})
@@ -217,6 +217,27 @@ func shortID() string {
 	return base64.StdEncoding.EncodeToString(b)
 }
 
+// ContextBody returns a reader that reads from body until ctx is done. Reads made after ctx is
+// canceled or its deadline expires fail with the context error so that the generated decode
+// functions stop decoding response bodies as soon as the caller gives up on them.
+func ContextBody(ctx context.Context, body io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: body}
+}
+
+// contextReader is the reader returned by ContextBody.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader.
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
 // clientKey is the private type used to store values in the context.
 // It is private to avoid possible collisions with keys used by other packages.
 type clientKey int
@@ -2,6 +2,8 @@ package client_test
 
 import (
 	"context"
+	"io/ioutil"
+	"strings"
 
 	"github.com/goadesign/goa/client"
 
@@ -45,4 +47,30 @@ var _ = Describe("client", func() {
 			})
 		})
 	})
+
+	Context("ContextBody", func() {
+		var ctx context.Context
+		var cancel context.CancelFunc
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("reads the body", func() {
+			b, err := ioutil.ReadAll(client.ContextBody(ctx, strings.NewReader("foo")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("foo"))
+		})
+
+		It("stops reading once the context is canceled", func() {
+			body := client.ContextBody(ctx, strings.NewReader("foo"))
+			cancel()
+			_, err := ioutil.ReadAll(body)
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
 })
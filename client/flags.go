@@ -0,0 +1,200 @@
+package client
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// FlagKind describes how the value of a command line flag is parsed.
+type FlagKind int
+
+const (
+	// StringFlag is the kind of flags whose values are strings. It is used for attributes of
+	// type String, DateTime, UUID and File.
+	StringFlag FlagKind = iota + 1
+	// IntegerFlag is the kind of flags whose values are integers.
+	IntegerFlag
+	// NumberFlag is the kind of flags whose values are floating point numbers.
+	NumberFlag
+	// BooleanFlag is the kind of flags whose values are booleans. Boolean flags may be set
+	// without value in which case the value is true.
+	BooleanFlag
+	// JSONFlag is the kind of flags whose values are JSON documents. It is used for arrays,
+	// objects, hashes and attributes of type Any.
+	JSONFlag
+)
+
+type (
+	// Flag describes a command line flag used to set a request parameter, header or payload
+	// attribute.
+	Flag struct {
+		// Name is the name of the flag. It is also the name of the attribute set by the flag.
+		Name string
+		// Kind is the kind of the flag value.
+		Kind FlagKind
+		// Usage describes the flag.
+		Usage string
+		// Required is true if the flag must be set when it has no default value.
+		Required bool
+		// Default is the default value of the flag if any, empty otherwise.
+		Default string
+	}
+
+	// FlagSet parses command line flags into values that can be decoded into the request
+	// parameters, headers and payload.
+	FlagSet struct {
+		*flag.FlagSet
+		flags  []*Flag
+		values map[string]*flagValue
+	}
+
+	// flagValue implements flag.Value for a given flag kind.
+	flagValue struct {
+		kind FlagKind
+		raw  string
+		val  interface{}
+		set  bool
+	}
+)
+
+// NewFlagSet creates a flag set with the given name and flags. The flag set prints errors and
+// usage to os.Stderr and returns errors from Parse.
+func NewFlagSet(name string, flags ...*Flag) *FlagSet {
+	fs := &FlagSet{
+		FlagSet: flag.NewFlagSet(name, flag.ContinueOnError),
+		flags:   flags,
+		values:  make(map[string]*flagValue, len(flags)),
+	}
+	for _, f := range flags {
+		v := &flagValue{kind: f.Kind}
+		if f.Default != "" {
+			if err := v.Set(f.Default); err != nil {
+				panic(fmt.Sprintf("invalid default value for flag %q: %s", f.Name, err)) // bug
+			}
+			v.set = false
+		}
+		usage := f.Usage
+		if f.Kind == JSONFlag {
+			usage += " (JSON)"
+		}
+		if f.Required && f.Default == "" {
+			usage += " (required)"
+		}
+		fs.Var(v, f.Name, usage)
+		fs.values[f.Name] = v
+	}
+	return fs
+}
+
+// Parse parses the flags from args. It returns an error if a required flag without default value
+// is missing. Errors are printed together with the flag set usage like the flag package does.
+func (fs *FlagSet) Parse(args []string) error {
+	if err := fs.FlagSet.Parse(args); err != nil {
+		return err
+	}
+	for _, f := range fs.flags {
+		if f.Required && fs.values[f.Name].val == nil {
+			err := fmt.Errorf("missing required flag -%s", f.Name)
+			fmt.Fprintln(fs.Output(), err)
+			fs.Usage()
+			return err
+		}
+	}
+	return nil
+}
+
+// IsSet returns true if the flag with the given name was set on the command line.
+func (fs *FlagSet) IsSet(name string) bool {
+	v, ok := fs.values[name]
+	return ok && v.set
+}
+
+// Value decodes the value of the flag with the given name into v which must be a pointer. Value
+// leaves v untouched if the flag was not set and has no default value.
+func (fs *FlagSet) Value(name string, v interface{}) error {
+	fv, ok := fs.values[name]
+	if !ok {
+		return fmt.Errorf("unknown flag -%s", name)
+	}
+	if fv.val == nil {
+		return nil
+	}
+	b, err := json.Marshal(fv.val)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("invalid value for flag -%s: %s", name, err)
+	}
+	return nil
+}
+
+// Decode decodes the values of the flags with the given names into v which must be a pointer to
+// a struct or a map. The flag names are used as JSON field names, the flags that are not set and
+// have no default value are omitted.
+func (fs *FlagSet) Decode(v interface{}, names ...string) error {
+	fields := make(map[string]interface{}, len(names))
+	for _, n := range names {
+		fv, ok := fs.values[n]
+		if !ok {
+			return fmt.Errorf("unknown flag -%s", n)
+		}
+		if fv.val != nil {
+			fields[n] = fv.val
+		}
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("invalid flag value: %s", err)
+	}
+	return nil
+}
+
+// String returns the flag value as given on the command line.
+func (v *flagValue) String() string {
+	return v.raw
+}
+
+// Set parses the given string according to the flag kind.
+func (v *flagValue) Set(s string) error {
+	var val interface{}
+	switch v.kind {
+	case IntegerFlag:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		val = i
+	case NumberFlag:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		val = f
+	case BooleanFlag:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		val = b
+	case JSONFlag:
+		if !json.Valid([]byte(s)) {
+			return fmt.Errorf("invalid JSON %q", s)
+		}
+		val = json.RawMessage(s)
+	default:
+		val = s
+	}
+	v.raw, v.val, v.set = s, val, true
+	return nil
+}
+
+// IsBoolFlag makes it possible to set boolean flags without value.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.kind == BooleanFlag
+}
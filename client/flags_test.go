@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FlagSet", func() {
+	type nested struct {
+		Color *string `json:"color,omitempty"`
+	}
+	type payload struct {
+		Name    string     `json:"name"`
+		Vintage *int       `json:"vintage,omitempty"`
+		Rating  *float64   `json:"rating,omitempty"`
+		Sweet   *bool      `json:"sweet,omitempty"`
+		Bottled *time.Time `json:"bottled,omitempty"`
+		Tags    []string   `json:"tags,omitempty"`
+		Details *nested    `json:"details,omitempty"`
+	}
+
+	var flags []*client.Flag
+	var args []string
+	var fs *client.FlagSet
+	var parseErr error
+
+	BeforeEach(func() {
+		flags = []*client.Flag{
+			{Name: "name", Kind: client.StringFlag, Required: true},
+			{Name: "vintage", Kind: client.IntegerFlag, Default: "2012"},
+			{Name: "rating", Kind: client.NumberFlag},
+			{Name: "sweet", Kind: client.BooleanFlag},
+			{Name: "bottled", Kind: client.StringFlag},
+			{Name: "tags", Kind: client.JSONFlag},
+			{Name: "details", Kind: client.JSONFlag},
+		}
+		args = nil
+	})
+
+	JustBeforeEach(func() {
+		fs = client.NewFlagSet("test", flags...)
+		fs.SetOutput(ioutil.Discard)
+		parseErr = fs.Parse(args)
+	})
+
+	Context("with all the flags set", func() {
+		BeforeEach(func() {
+			args = []string{
+				"-name", "Number 8",
+				"-vintage", "2015",
+				"-rating", "4.5",
+				"-sweet",
+				"-bottled", "2016-01-02T15:04:05Z",
+				"-tags", `["red","dry"]`,
+				"-details", `{"color":"ruby"}`,
+			}
+		})
+
+		It("decodes the payload", func() {
+			Ω(parseErr).ShouldNot(HaveOccurred())
+			var p *payload
+			err := fs.Decode(&p, "name", "vintage", "rating", "sweet", "bottled", "tags", "details")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p).ShouldNot(BeNil())
+			Ω(p.Name).Should(Equal("Number 8"))
+			Ω(*p.Vintage).Should(Equal(2015))
+			Ω(*p.Rating).Should(Equal(4.5))
+			Ω(*p.Sweet).Should(BeTrue())
+			Ω(p.Bottled.Equal(time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC))).Should(BeTrue())
+			Ω(p.Tags).Should(Equal([]string{"red", "dry"}))
+			Ω(*p.Details.Color).Should(Equal("ruby"))
+		})
+
+		It("decodes individual values", func() {
+			var vintage *int
+			Ω(fs.Value("vintage", &vintage)).Should(Succeed())
+			Ω(*vintage).Should(Equal(2015))
+			Ω(fs.IsSet("vintage")).Should(BeTrue())
+		})
+	})
+
+	Context("with only the required flags set", func() {
+		BeforeEach(func() {
+			args = []string{"-name", "Number 8"}
+		})
+
+		It("uses the default values and omits the other flags", func() {
+			Ω(parseErr).ShouldNot(HaveOccurred())
+			var p payload
+			Ω(fs.Decode(&p, "name", "vintage", "rating", "tags")).Should(Succeed())
+			Ω(*p.Vintage).Should(Equal(2012))
+			Ω(p.Rating).Should(BeNil())
+			Ω(p.Tags).Should(BeNil())
+			Ω(fs.IsSet("vintage")).Should(BeFalse())
+		})
+
+		It("leaves values of unset flags untouched", func() {
+			rating := 1.0
+			Ω(fs.Value("rating", &rating)).Should(Succeed())
+			Ω(rating).Should(Equal(1.0))
+		})
+	})
+
+	Context("with a missing required flag", func() {
+		BeforeEach(func() {
+			args = []string{"-vintage", "2015"}
+		})
+
+		It("returns an error", func() {
+			Ω(parseErr).Should(MatchError("missing required flag -name"))
+		})
+	})
+
+	Context("with an invalid integer", func() {
+		BeforeEach(func() {
+			args = []string{"-name", "n", "-vintage", "old"}
+		})
+
+		It("returns an error", func() {
+			Ω(parseErr).Should(HaveOccurred())
+			Ω(parseErr.Error()).Should(ContainSubstring("-vintage"))
+		})
+	})
+
+	Context("with invalid JSON", func() {
+		BeforeEach(func() {
+			args = []string{"-name", "n", "-tags", "[red"}
+		})
+
+		It("returns an error", func() {
+			Ω(parseErr).Should(HaveOccurred())
+			Ω(parseErr.Error()).Should(ContainSubstring("invalid JSON"))
+		})
+	})
+})
@@ -0,0 +1,215 @@
+package goa
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Compressor creates a writer that compresses the data written to it into w. Closing the writer
+// flushes the compressed data but does not close w.
+type Compressor func(w io.Writer) io.WriteCloser
+
+// compressors indexes the registered compressors by content coding.
+var compressors = map[string]Compressor{
+	"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	"deflate": func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	},
+}
+
+// RegisterCompressor registers the compressor used to produce responses with the given content
+// coding, for example "br". The gzip and deflate content codings are registered by default.
+// RegisterCompressor is not safe for concurrent use and should be called before the service
+// starts.
+func RegisterCompressor(encoding string, c Compressor) {
+	compressors[strings.ToLower(encoding)] = c
+}
+
+// Compress returns a handler that compresses the responses written by h with the content coding
+// that the request Accept-Encoding header prefers among the given encodings. Responses whose body
+// is smaller than threshold bytes are written uncompressed. Flushing the response of a streaming
+// handler compresses the data written so far so that the client receives it incrementally. The
+// encodings whose compressor is not registered are ignored, see RegisterCompressor.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func Compress(h Handler, threshold int64, encodings ...string) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		resp := ContextResponse(ctx)
+		if resp == nil {
+			return h(ctx, rw, req)
+		}
+		resp.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"), encodings)
+		if encoding == "" {
+			return h(ctx, rw, req)
+		}
+		cw := &compressWriter{
+			ResponseWriter: resp.SwitchWriter(nil),
+			encoding:       encoding,
+			threshold:      threshold,
+		}
+		resp.SwitchWriter(cw)
+		err := h(ctx, rw, req)
+		if cerr := cw.Close(); err == nil {
+			err = cerr
+		}
+		resp.SwitchWriter(cw.ResponseWriter)
+		return err
+	}
+}
+
+// negotiateEncoding returns the encoding listed in encodings that has a registered compressor and
+// that the given Accept-Encoding header value prefers, the empty string if none. Encodings with
+// the same preference are picked in the order they are listed.
+func negotiateEncoding(accept string, encodings []string) string {
+	if accept == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qs[name] = q
+	}
+	var best string
+	var bestQ float64
+	for _, e := range encodings {
+		e = strings.ToLower(e)
+		if _, ok := compressors[e]; !ok {
+			continue
+		}
+		q, ok := qs[e]
+		if !ok {
+			q = qs["*"]
+		}
+		if q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the response body until it reaches the threshold and then compresses
+// it.
+type compressWriter struct {
+	http.ResponseWriter
+	// encoding is the negotiated content coding.
+	encoding string
+	// threshold is the minimum size of the compressed bodies.
+	threshold int64
+	// status is the response status code, 0 until WriteHeader is called.
+	status int
+	// buf holds the body until the compression decision is made.
+	buf bytes.Buffer
+	// cw is the compressing writer once the body is compressed.
+	cw io.WriteCloser
+	// plain is true once the body is written uncompressed.
+	plain bool
+}
+
+// WriteHeader records the status code, the header is written once the compression decision is
+// made.
+func (w *compressWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// Write buffers b until the body reaches the threshold and compresses it from then on.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.cw != nil {
+		return w.cw.Write(b)
+	}
+	if w.plain {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.Header().Get("Content-Encoding") != "" {
+		// The handler encoded the body already.
+		if err := w.writePlain(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf.Write(b)
+	if int64(w.buf.Len()) >= w.threshold {
+		if err := w.compress(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush compresses and sends the data written so far to the client.
+func (w *compressWriter) Flush() {
+	if w.cw == nil && !w.plain {
+		if w.buf.Len() == 0 {
+			return
+		}
+		if err := w.compress(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.cw.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes the buffered body uncompressed if it did not reach the threshold and flushes the
+// compressed data otherwise.
+func (w *compressWriter) Close() error {
+	if w.cw != nil {
+		return w.cw.Close()
+	}
+	if w.plain || (w.status == 0 && w.buf.Len() == 0) {
+		return nil
+	}
+	return w.writePlain()
+}
+
+// compress writes the response header and the buffered body using the compressor.
+func (w *compressWriter) compress() error {
+	w.Header().Set("Content-Encoding", w.encoding)
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.statusCode())
+	w.cw = compressors[w.encoding](w.ResponseWriter)
+	_, err := w.cw.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// writePlain writes the response header and the buffered body uncompressed.
+func (w *compressWriter) writePlain() error {
+	w.plain = true
+	w.ResponseWriter.WriteHeader(w.statusCode())
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// statusCode returns the status code recorded by WriteHeader or 200 if none.
+func (w *compressWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
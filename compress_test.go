@@ -0,0 +1,129 @@
+package goa_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compress", func() {
+	var body string
+	var acceptEncoding string
+	var encodings []string
+	var rw *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		body = strings.Repeat("a", 2048)
+		acceptEncoding = "gzip"
+		encodings = []string{"gzip"}
+	})
+
+	JustBeforeEach(func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.WriteHeader(http.StatusOK)
+			_, err := rw.Write([]byte(body))
+			return err
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rw = httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		err := goa.Compress(h, 1024, encodings...)(ctx, goa.ContextResponse(ctx), req)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("compresses responses above the threshold", func() {
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+		Ω(rw.Header().Get("Vary")).Should(Equal("Accept-Encoding"))
+		Ω(gunzip(rw.Body)).Should(Equal(body))
+	})
+
+	Context("with a response below the threshold", func() {
+		BeforeEach(func() {
+			body = "small"
+		})
+
+		It("does not compress the response", func() {
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+			Ω(rw.Body.String()).Should(Equal("small"))
+		})
+	})
+
+	Context("with several encodings", func() {
+		BeforeEach(func() {
+			encodings = []string{"gzip", "deflate"}
+			acceptEncoding = "gzip;q=0.5, deflate"
+		})
+
+		It("uses the encoding preferred by the request", func() {
+			Ω(rw.Header().Get("Content-Encoding")).Should(Equal("deflate"))
+		})
+	})
+
+	Context("with a request that does not accept the encodings", func() {
+		BeforeEach(func() {
+			acceptEncoding = "gzip;q=0, identity"
+		})
+
+		It("does not compress the response", func() {
+			Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+			Ω(rw.Body.String()).Should(Equal(body))
+		})
+	})
+
+	Context("with an encoding that has no registered compressor", func() {
+		BeforeEach(func() {
+			encodings = []string{"br"}
+			acceptEncoding = "br"
+		})
+
+		It("does not compress the response", func() {
+			Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+			Ω(rw.Body.String()).Should(Equal(body))
+		})
+	})
+})
+
+var _ = Describe("Compress with a streaming handler", func() {
+	It("sends the data written before each flush compressed", func() {
+		rw := httptest.NewRecorder()
+		var sent int
+		h := func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+			enc := goa.NewNDJSONEncoder(w)
+			if err := enc.Encode(map[string]string{"name": "a"}); err != nil {
+				return err
+			}
+			sent = rw.Body.Len()
+			return enc.Encode(map[string]string{"name": "b"})
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		Ω(goa.Compress(h, 1024, "gzip")(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+		Ω(sent).Should(BeNumerically(">", 0))
+		Ω(rw.Flushed).Should(BeTrue())
+		Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+		Ω(gunzip(rw.Body)).Should(Equal("{\"name\":\"a\"}\n{\"name\":\"b\"}\n"))
+	})
+})
+
+// gunzip returns the decompressed content of r.
+func gunzip(r io.Reader) string {
+	zr, err := gzip.NewReader(r)
+	Ω(err).ShouldNot(HaveOccurred())
+	b, err := ioutil.ReadAll(zr)
+	Ω(err).ShouldNot(HaveOccurred())
+	return string(b)
+}
@@ -19,6 +19,7 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	scopeCheckerKey
 )
 
 type (
@@ -31,6 +32,12 @@ type (
 		// Params contains the raw values for the parameters defined in the design including
 		// path parameters, query string parameters and header parameters.
 		Params url.Values
+		// Meta is the meta object of the request body envelope for actions whose design
+		// defines a body envelope, see Envelope.
+		Meta map[string]interface{}
+
+		// maxBodyLength is the request body length limit set with LimitRequestBody.
+		maxBodyLength int64
 	}
 
 	// ResponseData provides access to the underlying HTTP response.
@@ -45,6 +52,9 @@ type (
 		Status int
 		// Length is the response body length.
 		Length int
+		// Meta is the meta object written in the response body envelope for actions whose
+		// design defines a body envelope, see Envelope.
+		Meta map[string]interface{}
 	}
 
 	// key is the type used to store internal values in the context.
@@ -160,6 +170,13 @@ func (r *ResponseData) WriteHeader(status int) {
 	r.ResponseWriter.WriteHeader(status)
 }
 
+// Flush sends any buffered data to the client if the underlying writer supports it.
+func (r *ResponseData) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Write records the amount of data written and calls the underlying writer.
 func (r *ResponseData) Write(b []byte) (int, error) {
 	if !r.Written() {
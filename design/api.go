@@ -185,6 +185,213 @@ var (
 		AttributeDefinition: &AttributeDefinition{Type: errorMediaType},
 		Name:                "default",
 	}
+
+	// ProblemDetailsMediaIdentifier is the media type identifier used for error responses
+	// rendered as problem details.
+	ProblemDetailsMediaIdentifier = "application/problem+json"
+
+	// ProblemDetailsMedia is the built-in media type for error responses rendered as problem
+	// details (RFC 7807), see the ProblemDetails DSL.
+	ProblemDetailsMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        problemDetailsMediaType,
+				Description: "Problem details (RFC 7807) error response media type",
+				Example: map[string]interface{}{
+					"type":     "https://goa.design/problems/invalid_value",
+					"title":    "Bad Request",
+					"status":   400,
+					"detail":   "Value of ID must be an integer",
+					"instance": "urn:goa:error:3F1FKVRR",
+					"code":     "invalid_value",
+					"meta":     map[string]interface{}{"timestamp": 1458609066},
+				},
+			},
+			TypeName: "ProblemDetails",
+		},
+		Identifier: ProblemDetailsMediaIdentifier,
+		Views:      map[string]*ViewDefinition{"default": problemDetailsMediaView},
+	}
+
+	problemDetailsMediaType = Object{
+		"type": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies the problem type, made of the problem type base URI and of the error code.",
+			Example:     "https://goa.design/problems/invalid_value",
+		},
+		"title": &AttributeDefinition{
+			Type:        String,
+			Description: "a short, human-readable summary of the problem type.",
+			Example:     "Bad Request",
+		},
+		"status": &AttributeDefinition{
+			Type:        Integer,
+			Description: "the HTTP status code applicable to this problem.",
+			Example:     400,
+		},
+		"detail": &AttributeDefinition{
+			Type:        String,
+			Description: "a human-readable explanation specific to this occurrence of the problem.",
+			Example:     "Value of ID must be an integer",
+		},
+		"instance": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies this particular occurrence of the problem.",
+			Example:     "urn:goa:error:3F1FKVRR",
+		},
+		"code": &AttributeDefinition{
+			Type:        String,
+			Description: "an application-specific error code, expressed as a string value.",
+			Example:     "invalid_value",
+		},
+		"meta": &AttributeDefinition{
+			Type: &Hash{
+				KeyType:  &AttributeDefinition{Type: String},
+				ElemType: &AttributeDefinition{Type: Any},
+			},
+			Description: "a meta object containing non-standard meta-information about the error.",
+			Example:     map[string]interface{}{"timestamp": 1458609066},
+		},
+	}
+
+	// JSONPatchOperation is the built-in type that describes a JSON Patch (RFC 6902) operation.
+	// The payload of actions that use the JSON Patch format is an array of JSONPatchOperation,
+	// see the PatchFormat DSL.
+	JSONPatchOperation = &UserTypeDefinition{
+		AttributeDefinition: &AttributeDefinition{
+			Type:        jsonPatchOperationType,
+			Description: "JSON Patch (RFC 6902) operation",
+			Validation:  &dslengine.ValidationDefinition{Required: []string{"op", "path"}},
+			Example: map[string]interface{}{
+				"op":    "replace",
+				"path":  "/name",
+				"value": "Number 8",
+			},
+		},
+		TypeName: "JSONPatchOperation",
+	}
+
+	jsonPatchOperationType = Object{
+		"op": &AttributeDefinition{
+			Type:        String,
+			Description: "the operation to perform.",
+			Validation: &dslengine.ValidationDefinition{
+				Values: []interface{}{"add", "remove", "replace", "move", "copy", "test"},
+			},
+			Example: "replace",
+		},
+		"path": &AttributeDefinition{
+			Type:        String,
+			Description: "a JSON pointer to the target location of the operation.",
+			Example:     "/name",
+		},
+		"from": &AttributeDefinition{
+			Type:        String,
+			Description: "a JSON pointer to the source location of the move and copy operations.",
+			Example:     "/nickname",
+		},
+		"value": &AttributeDefinition{
+			Type:        Any,
+			Description: "the value used by the add, replace and test operations.",
+			Example:     "Number 8",
+		},
+	}
+
+	// BatchResultMediaIdentifier is the media type identifier used for the results of the items
+	// of batch requests.
+	BatchResultMediaIdentifier = "application/vnd.goa.batch-result"
+
+	// BatchResultMedia is the built-in media type that describes the result of a single item of a
+	// batch request, see the Batch DSL.
+	BatchResultMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        batchResultMediaType,
+				Description: "Result of a batch request item",
+				Validation:  &dslengine.ValidationDefinition{Required: []string{"status"}},
+				Example: map[string]interface{}{
+					"status": 201,
+					"body":   map[string]interface{}{"id": 1, "name": "Number 8"},
+				},
+			},
+			TypeName: "GoaBatchResult",
+		},
+		Identifier: BatchResultMediaIdentifier,
+		Views:      map[string]*ViewDefinition{"default": batchResultMediaView},
+	}
+
+	// BatchResultsMedia is the built-in media type that describes the response of batch
+	// requests: the results of the items in the order of the request items, see the Batch DSL.
+	BatchResultsMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        &Array{ElemType: &AttributeDefinition{Type: BatchResultMedia}},
+				Description: "Results of the items of a batch request",
+			},
+			TypeName: "GoaBatchResultCollection",
+		},
+		Identifier: BatchResultMediaIdentifier + "; type=collection",
+		Views:      map[string]*ViewDefinition{"default": batchResultMediaView},
+	}
+
+	batchResultMediaType = Object{
+		"status": &AttributeDefinition{
+			Type:        Integer,
+			Description: "the HTTP status code of the item response.",
+			Example:     201,
+		},
+		"body": &AttributeDefinition{
+			Type:        Any,
+			Description: "the body of the item response, an error response if the item failed.",
+		},
+	}
+
+	batchResultMediaView = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: batchResultMediaType},
+		Name:                "default",
+	}
+
+	problemDetailsMediaView = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: problemDetailsMediaType},
+		Name:                "default",
+	}
+)
+
+const (
+	// MergePatch is the JSON Merge Patch (RFC 7396) format, see the PatchFormat DSL.
+	MergePatch = "merge-patch"
+
+	// JSONPatch is the JSON Patch (RFC 6902) format, see the PatchFormat DSL.
+	JSONPatch = "json-patch"
+)
+
+const (
+	// LessThan requires a field to be less than another field, see the Bound DSL.
+	LessThan = "<"
+
+	// LessThanOrEqual requires a field to be less than or equal to another field, see the
+	// Bound DSL.
+	LessThanOrEqual = "<="
+
+	// GreaterThan requires a field to be greater than another field, see the Bound DSL.
+	GreaterThan = ">"
+
+	// GreaterThanOrEqual requires a field to be greater than or equal to another field, see the
+	// Bound DSL.
+	GreaterThanOrEqual = ">="
+)
+
+const (
+	// CamelFieldNaming renders the JSON names of the fields in camelCase, see the FieldNaming DSL.
+	CamelFieldNaming = "camel"
+
+	// SnakeFieldNaming renders the JSON names of the fields in snake_case, see the FieldNaming
+	// DSL.
+	SnakeFieldNaming = "snake"
+
+	// KebabFieldNaming renders the JSON names of the fields in kebab-case, see the FieldNaming
+	// DSL.
+	KebabFieldNaming = "kebab"
 )
 
 func init() {
@@ -200,6 +407,8 @@ func init() {
 		{MIMETypes: GobContentTypes, PackagePath: goa, Function: "NewGobDecoder"},
 	}
 	errorMediaView.Parent = ErrorMedia
+	problemDetailsMediaView.Parent = ProblemDetailsMedia
+	batchResultMediaView.Parent = BatchResultMedia
 }
 
 // CanonicalIdentifier returns the media type identifier sans suffix
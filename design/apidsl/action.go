@@ -2,8 +2,11 @@ package apidsl
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"unicode"
 
+	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -246,6 +249,26 @@ func PATCH(path string, dsl ...func()) *design.RouteDefinition {
 	return route
 }
 
+// Method is used as an argument to Routing
+//
+// Method creates a route using the given HTTP method. It makes it possible to define routes that
+// use methods other than the standard ones, for example WebDAV methods:
+//
+//	Routing(Method("PROPFIND", "/files/*path"))
+//
+// The method must be an uppercase HTTP token as defined by RFC 7230. The generated OpenAPI
+// specification describes the operations of non-standard methods with "x-" extensions on the
+// path, e.g. "x-propfind", as the specification only supports standard methods.
+func Method(verb, path string, dsl ...func()) *design.RouteDefinition {
+	route := &design.RouteDefinition{Verb: verb, Path: path}
+	if len(dsl) != 0 {
+		if !dslengine.Execute(dsl[0], route) {
+			return nil
+		}
+	}
+	return route
+}
+
 // Headers can be used in: Action, Response, Resource
 //
 // Headers implements the DSL for describing HTTP headers. The DSL syntax is identical to the one
@@ -437,6 +460,7 @@ func payload(isOptional bool, p interface{}, dsls ...func()) {
 			if len(dsls) == 0 {
 				a.Payload = actual
 				a.PayloadOptional = isOptional
+				applyMergePatch(a)
 				return
 			}
 			att = design.DupAtt(actual.Definition())
@@ -474,6 +498,7 @@ func payload(isOptional bool, p interface{}, dsls ...func()) {
 			TypeName:            fmt.Sprintf("%s%sPayload", an, rn),
 		}
 		a.PayloadOptional = isOptional
+		applyMergePatch(a)
 	}
 }
 
@@ -489,6 +514,881 @@ func MultipartForm() {
 	}
 }
 
+// Emits can be used in: Action
+//
+// Emits declares that the action produces a domain event once it succeeds. The first argument is
+// the name of the event, it must be unique among the events of the resource. The second argument
+// is the event payload type, either a type, a media type or the name of a type. The type must
+// describe an object:
+//
+//	Action("create", func() {
+//		Routing(POST("/"))
+//		Payload(BottlePayload)
+//		Emits("BottleCreated", BottleCreated)
+//	})
+//
+// The generated action context exposes a field for each event that the controller sets to emit
+// the event. The events are dispatched to the controller when it implements the resource
+// EventEmitter interface.
+func Emits(name string, event interface{}) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	e := &design.EventDefinition{Name: name, Parent: a}
+	switch actual := event.(type) {
+	case *design.UserTypeDefinition:
+		e.Type, e.TypeName = actual, actual.TypeName
+	case *design.MediaTypeDefinition:
+		e.Type, e.TypeName = actual, actual.TypeName
+	case string:
+		e.TypeName = actual
+		if ut, ok := design.Design.Types[actual]; ok {
+			e.Type = ut
+		}
+	default:
+		dslengine.ReportError("invalid Emits argument, event payload must be a type, a media type or a type name")
+		return
+	}
+	a.Events = append(a.Events, e)
+}
+
+// MaxBodySize can be used in: API, Resource, Action
+//
+// MaxBodySize sets the maximum size of the request bodies accepted by the action, by all the
+// resource actions or by all the API actions. The size is a number of bytes optionally followed by
+// one of the units B, KB, MB or GB (powers of 1024). Requests whose body is larger are rejected
+// with a 413 Request Entity Too Large response. The size set on an action overrides the size set
+// on its resource which overrides the size set on the API:
+//
+//	Action("upload", func() {
+//		Routing(POST("/"))
+//		Payload(Upload)
+//		MaxBodySize("1MB")
+//	})
+//
+// The value is stored in the "http:body:max-size" metadata of the definition.
+func MaxBodySize(size string) {
+	setMaxBodySize := func(metadata dslengine.MetadataDefinition) dslengine.MetadataDefinition {
+		if metadata == nil {
+			metadata = make(dslengine.MetadataDefinition)
+		}
+		metadata["http:body:max-size"] = []string{size}
+		return metadata
+	}
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		def.Metadata = setMaxBodySize(def.Metadata)
+	case *design.ResourceDefinition:
+		def.Metadata = setMaxBodySize(def.Metadata)
+	case *design.APIDefinition:
+		def.Metadata = setMaxBodySize(def.Metadata)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// CompressionThreshold is the minimum size of the response bodies compressed by Compress as
+// returned by Threshold.
+type CompressionThreshold string
+
+// Compress can be used in: API, Resource, Action
+//
+// Compress makes the generated code compress the responses of the action, of all the resource
+// actions or of all the API actions. The arguments list the supported content codings in order of
+// preference: "gzip", "deflate" or "br". The generated code uses the coding preferred by the
+// request Accept-Encoding header. The last argument may be a Threshold, the responses whose body
+// is smaller than the threshold are not compressed. The setting of an action overrides the setting
+// of its resource which overrides the setting of the API:
+//
+//	Action("list", func() {
+//		Routing(GET("/"))
+//		Response(OK, CollectionOf(Bottle))
+//		Compress("br", "gzip", Threshold("1KB"))
+//	})
+//
+// The gzip and deflate codings are always available, the "br" coding requires registering a
+// compressor with goa.RegisterCompressor. The codings are stored in the "http:compress" metadata
+// of the definition and the threshold in the "http:compress:threshold" metadata.
+func Compress(args ...interface{}) {
+	var encodings []string
+	var threshold CompressionThreshold
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case string:
+			encodings = append(encodings, a)
+		case CompressionThreshold:
+			threshold = a
+		default:
+			dslengine.InvalidArgError("string or Threshold", arg)
+			return
+		}
+	}
+	setCompress := func(metadata dslengine.MetadataDefinition) dslengine.MetadataDefinition {
+		if metadata == nil {
+			metadata = make(dslengine.MetadataDefinition)
+		}
+		metadata["http:compress"] = encodings
+		delete(metadata, "http:compress:threshold")
+		if threshold != "" {
+			metadata["http:compress:threshold"] = []string{string(threshold)}
+		}
+		return metadata
+	}
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		def.Metadata = setCompress(def.Metadata)
+	case *design.ResourceDefinition:
+		def.Metadata = setCompress(def.Metadata)
+	case *design.APIDefinition:
+		def.Metadata = setCompress(def.Metadata)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Threshold can be used in: Compress
+//
+// Threshold sets the minimum size of the response bodies compressed by Compress. The size is a
+// number of bytes optionally followed by one of the units B, KB, MB or GB (powers of 1024).
+func Threshold(size string) CompressionThreshold {
+	return CompressionThreshold(size)
+}
+
+// SurrogateKeys can be used in: Action
+//
+// SurrogateKeys lists the attributes of the action success response media type whose values are
+// written to the Surrogate-Key response header. CDNs use the header to purge cached responses by
+// tag. The attributes are listed using Attribute and must be strings or integers. The values of
+// all the elements are used when the media type is a collection:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Response(OK, BottleMedia)
+//		SurrogateKeys(func() {
+//			Attribute("id")
+//			Attribute("account_id")
+//		})
+//	})
+//
+// The attribute names are stored in the "http:surrogate-keys" metadata of the action.
+func SurrogateKeys(dsl func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	keys := &design.AttributeDefinition{Type: make(design.Object)}
+	if !dslengine.Execute(dsl, keys) {
+		return
+	}
+	var names []string
+	for n := range keys.Type.ToObject() {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if a.Metadata == nil {
+		a.Metadata = make(dslengine.MetadataDefinition)
+	}
+	a.Metadata["http:surrogate-keys"] = names
+}
+
+// ResponseFromField can be used in: Action
+//
+// ResponseFromField makes it possible to choose the action response at runtime from the value of a
+// boolean attribute of the response media type. The first response is sent when the attribute is
+// true and the second otherwise. Both responses must be defined with Response and use the same
+// media type and view. The generated action context defines a Respond method that sends the
+// response corresponding to the attribute value:
+//
+//	Action("upsert", func() {
+//		Routing(PUT("/:id"))
+//		Response(Created, BottleMedia)
+//		Response(OK, BottleMedia)
+//		ResponseFromField("created", Created, OK)
+//	})
+//
+// The attribute and response names are stored in the "http:response:from-field" metadata of the
+// action.
+func ResponseFromField(field string, responses ...string) {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:response:from-field"] = append([]string{field}, responses...)
+	}
+}
+
+// VerifySignature can be used in: Action
+//
+// VerifySignature makes the generated code verify the signature of the action request body before
+// decoding it, typically to authenticate webhook calls. The raw body and the value of the given
+// request header are given to the service SignatureVerifier and requests whose signature cannot be
+// verified are rejected with a 401 Unauthorized response. The action must define a payload that is
+// not optional:
+//
+//	Action("push", func() {
+//		Routing(POST("/hooks/push"))
+//		Payload(PushEvent)
+//		VerifySignature("X-Hub-Signature-256")
+//	})
+//
+// The header name is stored in the "http:signature:header" metadata of the action.
+func VerifySignature(header string) {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:signature:header"] = []string{header}
+	}
+}
+
+// RequireContentLength can be used in: Action
+//
+// RequireContentLength makes the generated code reject the requests that do not set the
+// Content-Length header with a 411 Length Required response before reading their body, typically
+// for upload actions that stream the body to storage. Requests whose Content-Length exceeds the
+// size set with MaxBodySize are rejected with a 413 Request Entity Too Large response without
+// reading the body:
+//
+//	Action("upload", func() {
+//		Routing(PUT("/files/:name"))
+//		RequireContentLength()
+//		RequireContentType("application/octet-stream")
+//		MaxBodySize("100MB")
+//	})
+//
+// The setting is stored in the "http:body:require-length" metadata of the action.
+func RequireContentLength() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:body:require-length"] = []string{"true"}
+	}
+}
+
+// RequireContentType can be used in: Action
+//
+// RequireContentType makes the generated code reject the requests whose Content-Type header does
+// not match one of the given media types with a 415 Unsupported Media Type response before reading
+// their body. Media type parameters such as charset are ignored when matching the header, see
+// RequireContentLength for an example.
+//
+// The media types are stored in the "http:body:content-type" metadata of the action.
+func RequireContentType(mediaTypes ...string) {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:body:content-type"] = append(a.Metadata["http:body:content-type"], mediaTypes...)
+	}
+}
+
+// SkipRequestBodyValidation can be used in: Action
+//
+// SkipRequestBodyValidation makes the generated code decode the action request body without
+// validating it. This saves the cost of the validation for trusted high-throughput clients, the
+// controller must not rely on the payload validations as required attributes may be missing and
+// values may violate the attribute validations. The action must define a payload:
+//
+//	Action("ingest", func() {
+//		Routing(POST("/metrics"))
+//		Payload(MetricsBatch)
+//		SkipRequestBodyValidation()
+//	})
+//
+// The setting is stored in the "http:body:skip-validation" metadata of the action.
+func SkipRequestBodyValidation() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:body:skip-validation"] = []string{"true"}
+	}
+}
+
+// OperationID can be used in: Action
+//
+// OperationID sets the identifier of the operation exposed by the action transport, for example
+// the Swagger operationId, when it must differ from the action name. The generated Go code keeps
+// using the action name:
+//
+//	Action("list", func() {
+//		Routing(GET("/users"))
+//		OperationID("listUsersV2")
+//	})
+//
+// Operation identifiers must be unique across the API. The operations of actions with multiple
+// routes are suffixed with the route index as with the default identifiers. The identifier is
+// stored in the "operation:id" metadata of the action.
+func OperationID(id string) {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["operation:id"] = []string{id}
+	}
+}
+
+// StreamJSON can be used in: Action
+//
+// StreamJSON makes the generated code stream the action success responses as newline delimited
+// JSON (application/x-ndjson) rather than as a single JSON array: the response methods write one
+// collection element per line and flush the response after each element. The generated client
+// decodes the responses one element at a time. The action must define a success response whose
+// media type is a collection of objects:
+//
+//	Action("list", func() {
+//		Routing(GET("/"))
+//		Response(OK, CollectionOf(Bottle))
+//		StreamJSON()
+//	})
+//
+// The setting is stored in the "http:response:ndjson" metadata of the action.
+func StreamJSON() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:response:ndjson"] = []string{"true"}
+	}
+}
+
+// Dedupe can be used in: Action
+//
+// Dedupe makes the generated code replay the response of the first request to the identical
+// requests received within the given window, for example to absorb the retries of at-least-once
+// delivery sources. Requests are identified by a hash of their method, URI and either the value of
+// their Idempotency-Key header or their body. The responses are stored in the service DedupeStore,
+// only successful responses are stored so that failed requests can be retried. The window is a
+// duration string as accepted by time.ParseDuration:
+//
+//	Action("deliver", func() {
+//		Routing(POST("/deliveries"))
+//		Payload(Delivery)
+//		Dedupe("10m")
+//	})
+//
+// The window is stored in the "http:dedupe:window" metadata of the action.
+func Dedupe(window string) {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:dedupe:window"] = []string{window}
+	}
+}
+
+// IdempotencyKey can be used in: Action
+//
+// IdempotencyKey declares that clients identify the retries of a request with the value of the
+// required Idempotency-Key request header. Use Cacheable to make the generated code replay the
+// response of the first request to its retries:
+//
+//	Action("charge", func() {
+//		Routing(POST("/charges"))
+//		Payload(Charge)
+//		Response(Created)
+//		IdempotencyKey()
+//		Cacheable()
+//	})
+//
+// IdempotencyKey defines the "Idempotency-Key" header unless already defined and sets the
+// "http:idempotency-key" metadata of the action.
+func IdempotencyKey() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:idempotency-key"] = []string{"true"}
+		if a.Headers != nil {
+			if _, ok := a.Headers.Type.ToObject()[goa.IdempotencyKeyHeader]; ok {
+				return
+			}
+		}
+		key := &design.AttributeDefinition{
+			Type:        design.String,
+			Description: "Key that identifies the retries of the request",
+		}
+		a.Headers = a.Headers.Merge(&design.AttributeDefinition{
+			Type:       design.Object{goa.IdempotencyKeyHeader: key},
+			Validation: &dslengine.ValidationDefinition{Required: []string{goa.IdempotencyKeyHeader}},
+		})
+	}
+}
+
+// Cacheable can be used in: Action
+//
+// Cacheable makes the generated code store the response of the first request that carries a
+// given idempotency key and replay it to the retries of the request rather than calling the
+// controller again. The responses are stored in the service DedupeStore for the duration of
+// goa.IdempotentResponseTTL, only successful responses are stored so that failed requests can be
+// retried. The action must define the IdempotencyKey DSL and cannot be routed to GET requests.
+//
+// The setting is stored in the "http:idempotency-key:cacheable" metadata of the action.
+func Cacheable() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:idempotency-key:cacheable"] = []string{"true"}
+	}
+}
+
+// SparseFieldsets can be used in: Action
+//
+// SparseFieldsets lets clients request a subset of the attributes of the action success responses
+// with the "fields" query string parameter as defined by JSON:API sparse fieldsets. The parameter
+// contains a comma separated list of attribute names, the generated response methods omit the
+// attributes that are not listed when it is set. The action success responses must use media
+// types that describe objects or collections of objects:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Response(OK, BottleMedia)
+//		SparseFieldsets()
+//	})
+//
+// SparseFieldsets defines the "fields" parameter unless already defined and sets the
+// "http:sparse-fieldsets" metadata of the action.
+func SparseFieldsets() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:sparse-fieldsets"] = []string{"true"}
+		if a.Params != nil {
+			if _, ok := a.Params.Type.ToObject()["fields"]; ok {
+				return
+			}
+		}
+		fields := &design.AttributeDefinition{
+			Type:        design.String,
+			Description: "Comma separated list of the response attributes to render",
+		}
+		a.Params = a.Params.Merge(&design.AttributeDefinition{Type: design.Object{"fields": fields}})
+	}
+}
+
+// Batch can be used in: Action
+//
+// Batch derives a batch action from the action. The batch action is named after the action
+// prefixed with "batch_", its payload is an array of the action payload and its routes are the
+// action routes suffixed with "/batch" and using the POST method. The generated handler of the
+// batch action handles each item like a request of the action: the item is finalized and
+// validated, the action controller is called and the action events are emitted. The responses of
+// the items of cacheable actions are replayed to the retries of the batch request that carry the
+// same idempotency key. The handler responds with the results of the items in order, each result
+// contains the status code and the body of the item response so that the failure of an item, its
+// validation included, does not fail the others. The action must have an object payload that is
+// not multipart and cannot stream its responses:
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		Payload(UserPayload)
+//		Response(Created)
+//		Batch() // Defines the "batch_create" action with route "POST /users/batch"
+//	})
+//
+// Batch sets the "batch:action" metadata of the action, the batch action is created when the
+// design is finalized.
+func Batch() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["batch:action"] = []string{"batch_" + a.Name}
+		if design.Design.MediaTypes == nil {
+			design.Design.MediaTypes = make(map[string]*design.MediaTypeDefinition)
+		}
+		for _, mt := range []*design.MediaTypeDefinition{design.BatchResultMedia, design.BatchResultsMedia} {
+			design.Design.MediaTypes[design.CanonicalIdentifier(mt.Identifier)] = mt
+		}
+	}
+}
+
+// Sortable can be used in: Action
+//
+// Sortable lists the fields that clients may use to sort the results of a list action with the
+// "sort" query string parameter. The parameter contains a comma separated list of field names, a
+// name prefixed with "-" sorts the results in descending order. The generated context rejects
+// requests that list other fields with a 400 Bad Request response and exposes the parsed fields
+// in its SortFields field. The fields must be attributes of the elements of the action success
+// response media type, see SortableField to set the default sort options of a field:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Response(OK, CollectionOf(BottleMedia))
+//		Sortable("name", "created_at")
+//	})
+//
+// Sortable defines the "sort" parameter unless already defined and sets the "http:sortable"
+// metadata of the action.
+func Sortable(fields ...string) {
+	if a, ok := actionDefinition(); ok {
+		addSortableFields(a, fields...)
+	}
+}
+
+// SortOption is a default sort option of a sortable field, see SortableField.
+type SortOption string
+
+const (
+	// Asc sorts the results in ascending order of the field values by default.
+	Asc SortOption = "asc"
+	// Desc sorts the results in descending order of the field values by default.
+	Desc SortOption = "desc"
+	// NullsFirst places null values before the other values.
+	NullsFirst SortOption = "nulls-first"
+	// NullsLast places null values after the other values.
+	NullsLast SortOption = "nulls-last"
+)
+
+// SortableField can be used in: Action
+//
+// SortableField adds a field to the fields that clients may use to sort the results of a list
+// action, see Sortable, and sets its default sort options. The direction, Asc or Desc, applies
+// when the client does not prefix the field name with "+" or "-". NullsFirst and NullsLast set the
+// placement of null values. The generated context exposes the options in the SortFields field so
+// that the query builder gets the complete ordering:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Response(OK, CollectionOf(BottleMedia))
+//		Sortable("name")
+//		SortableField("created_at", Desc, NullsLast)
+//	})
+//
+// SortableField defines the "sort" parameter unless already defined and sets the "http:sortable"
+// and "http:sortable:<field>" metadata of the action.
+func SortableField(name string, opts ...SortOption) {
+	if a, ok := actionDefinition(); ok {
+		addSortableFields(a, name)
+		vals := make([]string, len(opts))
+		for i, o := range opts {
+			vals[i] = string(o)
+		}
+		a.Metadata["http:sortable:"+name] = vals
+	}
+}
+
+// addSortableFields adds the given fields to the sortable fields of the action unless already
+// present and defines the "sort" parameter unless already defined.
+func addSortableFields(a *design.ActionDefinition, fields ...string) {
+	if a.Metadata == nil {
+		a.Metadata = make(dslengine.MetadataDefinition)
+	}
+	for _, f := range fields {
+		found := false
+		for _, s := range a.Metadata["http:sortable"] {
+			if s == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a.Metadata["http:sortable"] = append(a.Metadata["http:sortable"], f)
+		}
+	}
+	if a.Params != nil {
+		if _, ok := a.Params.Type.ToObject()["sort"]; ok {
+			return
+		}
+	}
+	param := &design.AttributeDefinition{
+		Type:        design.String,
+		Description: "Comma separated list of the fields used to sort the results, prefix with - for descending order",
+	}
+	a.Params = a.Params.Merge(&design.AttributeDefinition{Type: design.Object{"sort": param}})
+}
+
+// Filterable can be used in: Action
+//
+// Filterable lists the fields that clients may use to filter the results of a list action with
+// the "filter[field]" query string parameters, e.g. "filter[status]=active". The generated
+// context rejects requests that filter on other fields with a 400 Bad Request response and
+// exposes the filter values indexed by field name in its Filters field. The fields must be
+// attributes of the elements of the action success response media type:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Response(OK, CollectionOf(BottleMedia))
+//		Filterable("status")
+//	})
+//
+// Filterable sets the "http:filterable" metadata of the action.
+func Filterable(fields ...string) {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:filterable"] = fields
+	}
+}
+
+// Pagination can be used in: Action
+//
+// Pagination makes the action accept the "page_size" integer query string parameter that sets the
+// maximum number of elements listed in the response. The parameter is defined unless already
+// defined. The optional DSL sets the page size limits enforced by the generated code with
+// PageSize:
+//
+//	Action("list", func() {
+//		Routing(GET("/"))
+//		Response(OK, CollectionOf(Bottle))
+//		Pagination(func() {
+//			PageSize(20, 100)
+//		})
+//	})
+//
+// Pagination sets the "http:pagination" metadata of the action.
+func Pagination(dsl ...func()) {
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Pagination")
+		return
+	}
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(dslengine.MetadataDefinition)
+	}
+	a.Metadata["http:pagination"] = []string{"true"}
+	if a.Params == nil || a.Params.Type.ToObject()["page_size"] == nil {
+		min := float64(1)
+		pageSize := &design.AttributeDefinition{
+			Type:        design.Integer,
+			Description: "Maximum number of elements listed in the response",
+			Validation:  &dslengine.ValidationDefinition{Minimum: &min},
+		}
+		a.Params = a.Params.Merge(&design.AttributeDefinition{Type: design.Object{"page_size": pageSize}})
+	}
+	if len(dsl) == 1 {
+		dsl[0]()
+	}
+}
+
+// PageSize can be used in: Pagination
+//
+// PageSize sets the default and maximum values of the "page_size" parameter defined by Pagination.
+// The generated code uses the default value when the request does not set the parameter and
+// clamps the values greater than the maximum to the maximum, in which case the response includes
+// a Warning header. The values are stored in the "http:pagination:page-size" metadata of the
+// action.
+func PageSize(def, max int) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if _, ok := a.Metadata["http:pagination"]; !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	a.Metadata["http:pagination:page-size"] = []string{strconv.Itoa(def), strconv.Itoa(max)}
+	if p := a.Params.Type.ToObject()["page_size"]; p != nil && p.Type.Kind() == design.IntegerKind {
+		p.SetDefault(def)
+	}
+}
+
+// PatchFormat can be used in: Action
+//
+// PatchFormat sets the format of the request body of PATCH actions to either MergePatch or
+// JSONPatch.
+//
+// With MergePatch (JSON Merge Patch, RFC 7396) the request body has the shape of the payload and
+// describes the changes to apply: fields that are absent are left unchanged and fields set to null
+// are deleted. The generated payload fields are all optional and the top level fields of type
+// String, Integer, Number or Boolean that do not use a custom Go type are nullable (see Nullable)
+// so that the action can tell the two cases apart. The validations of these fields and the
+// default values of all fields do not apply:
+//
+//	Action("patch", func() {
+//		Routing(PATCH("/:id"))
+//		Payload(BottlePayload)
+//		PatchFormat(MergePatch)
+//	})
+//
+// With JSONPatch (JSON Patch, RFC 6902) the request body is a list of operations that the action
+// applies in order. The payload is an array of the built-in JSONPatchOperation type and must not be
+// defined by the action:
+//
+//	Action("patch", func() {
+//		Routing(PATCH("/:id"))
+//		PatchFormat(JSONPatch)
+//	})
+//
+// The format is stored in the "http:patch:format" metadata of the action.
+func PatchFormat(format string) {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:patch:format"] = []string{format}
+		switch format {
+		case design.MergePatch:
+			applyMergePatch(a)
+		case design.JSONPatch:
+			if a.Payload != nil {
+				dslengine.ReportError("action cannot define a payload when using the JSON Patch format")
+				return
+			}
+			if design.Design.Types == nil {
+				design.Design.Types = make(map[string]*design.UserTypeDefinition)
+			}
+			name := design.JSONPatchOperation.TypeName
+			if ut, ok := design.Design.Types[name]; ok && ut != design.JSONPatchOperation {
+				dslengine.ReportError("type %#v is reserved for JSON Patch operations", name)
+				return
+			}
+			design.Design.Types[name] = design.JSONPatchOperation
+			a.Payload = &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.JSONPatchOperation}},
+				},
+				TypeName: fmt.Sprintf("%s%sPayload", camelize(a.Name), camelize(a.Parent.Name)),
+			}
+		}
+	}
+}
+
+// Deprecated can be used in: Action
+//
+// Deprecated marks the action as deprecated. The generated code adds the "Deprecation: true" header
+// to the action responses and the generated Swagger specification flags the operation as
+// deprecated. The optional DSL may use Sunset to give the date after which the action becomes
+// unavailable:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Deprecated(func() {
+//			Sunset("2025-12-31")
+//		})
+//		Response(OK, BottleMedia)
+//	})
+//
+// Deprecated sets the "http:deprecated" metadata of the action.
+func Deprecated(dsl ...func()) {
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Deprecated")
+		return
+	}
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(dslengine.MetadataDefinition)
+	}
+	a.Metadata["http:deprecated"] = []string{"true"}
+	if len(dsl) == 1 {
+		dsl[0]()
+	}
+}
+
+// Sunset can be used in: Deprecated
+//
+// Sunset sets the date after which the deprecated action becomes unavailable. The date is given
+// either as a date, e.g. "2025-12-31", or using the RFC 3339 format. The generated code adds the
+// "Sunset" header with the date in the HTTP format to the action responses. The date is stored in
+// the "http:deprecated:sunset" metadata of the action.
+func Sunset(date string) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if _, ok := a.Metadata["http:deprecated"]; !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	a.Metadata["http:deprecated:sunset"] = []string{date}
+}
+
+// EchoUpdatedFields can be used in: Action
+//
+// EchoUpdatedFields restricts the success responses of PATCH actions that use the MergePatch format
+// to the attributes set in the request body: attributes that are absent from the request are
+// omitted from the response. The response attributes are matched with the payload attributes by
+// name. The success responses must use media types that describe objects:
+//
+//	Action("patch", func() {
+//		Routing(PATCH("/:id"))
+//		Payload(BottlePayload)
+//		PatchFormat(MergePatch)
+//		Response(OK, BottleMedia)
+//		EchoUpdatedFields()
+//	})
+//
+// EchoUpdatedFields sets the "http:echo-updated-fields" metadata of the action.
+func EchoUpdatedFields() {
+	if a, ok := actionDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["http:echo-updated-fields"] = []string{"true"}
+	}
+}
+
+// applyMergePatch replaces the payload of actions that use the JSON Merge Patch format with a
+// copy whose fields are optional and whose primitive fields are nullable.
+func applyMergePatch(a *design.ActionDefinition) {
+	if a.PatchFormat() != design.MergePatch || a.Payload == nil {
+		return
+	}
+	o := a.Payload.Type.ToObject()
+	if o == nil {
+		return
+	}
+	patch := make(design.Object, len(o))
+	for n, att := range o {
+		dup := design.DupAtt(att)
+		dup.Metadata = make(dslengine.MetadataDefinition, len(att.Metadata))
+		for k, v := range att.Metadata {
+			if k != "struct:default:from" {
+				dup.Metadata[k] = v
+			}
+		}
+		dup.DefaultValue = nil
+		if canBeNullable(att) {
+			dup.Validation = nil
+			dup.Transitions = nil
+			dup.SetNullable()
+		}
+		patch[n] = dup
+	}
+	att := design.DupAtt(a.Payload.AttributeDefinition)
+	att.Type = patch
+	att.NonZeroAttributes = nil
+	if att.Validation != nil {
+		att.Validation.Required = nil
+	}
+	a.Payload = &design.UserTypeDefinition{
+		AttributeDefinition: att,
+		TypeName:            fmt.Sprintf("%s%sPayload", camelize(a.Name), camelize(a.Parent.Name)),
+	}
+}
+
+// canBeNullable returns true if the attribute is a String, Integer, Number or Boolean that is not
+// a flag set, generated using a custom Go type or encoded using a different wire type.
+func canBeNullable(att *design.AttributeDefinition) bool {
+	switch att.Type.Kind() {
+	case design.StringKind, design.IntegerKind, design.NumberKind, design.BooleanKind:
+	default:
+		return false
+	}
+	if len(att.Flags) > 0 {
+		return false
+	}
+	for _, k := range []string{"enum:names", "json:marshaler", "json:wire", "struct:field:type"} {
+		if _, ok := att.Metadata[k]; ok {
+			return false
+		}
+	}
+	return true
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
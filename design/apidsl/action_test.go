@@ -148,6 +148,35 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with emitted events", func() {
+		var created, deleted *UserTypeDefinition
+
+		BeforeEach(func() {
+			name = "foo"
+			created = Type("BottleCreated", func() {
+				Attribute("id", Integer)
+			})
+			deleted = Type("BottleDeleted", func() {
+				Attribute("id", Integer)
+			})
+			dsl = func() {
+				Routing(POST("/"))
+				Emits("BottleCreated", created)
+				Emits("BottleDeleted", "BottleDeleted")
+			}
+		})
+
+		It("sets the action events", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Events).Should(HaveLen(2))
+			Ω(action.Events[0].Name).Should(Equal("BottleCreated"))
+			Ω(action.Events[0].Type).Should(Equal(created))
+			Ω(action.Events[1].Name).Should(Equal("BottleDeleted"))
+			Ω(action.Events[1].Type).Should(Equal(deleted))
+			Ω(action.Events[1].Parent).Should(Equal(action))
+		})
+	})
+
 	Context("with multiple headers sections", func() {
 		const typeName = "typeName"
 		const headerName = "Foo"
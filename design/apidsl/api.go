@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -90,12 +91,45 @@ func API(name string, dsl func()) *design.APIDefinition {
 	return design.Design
 }
 
-// Version can be used in: API
+// Version can be used in: API, Resource
 //
-// Version specifies the API version. One design describes one version.
+// Version specifies the API version when used in API. One design describes one version.
+//
+// Version specifies the version of the resource when used in Resource. The resources that share
+// the same base path define the versions of the same endpoints, the generated code dispatches the
+// requests to the version selected by their Accept header as described by VersionMedia:
+//
+//	Resource("bottle_v2", func() {
+//		BasePath("/bottles")
+//		Version("v2")
+//	})
 func Version(ver string) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.Version = ver
+	case *design.ResourceDefinition:
+		def.Version = ver
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// VersionMedia can be used in: API
+//
+// VersionMedia defines the pattern of the media types used in the request Accept header to select
+// the version of the resources, "{version}" stands for the version defined with Version in
+// Resource:
+//
+//	API("cellar", func() {
+//		VersionMedia("application/vnd.cellar.{version}+json")
+//	})
+//
+// The generated code routes requests with the header "Accept: application/vnd.cellar.v2+json" to
+// the resource with version "v2". Requests that do not select a version are routed to the
+// resource that does not define a version if any, to the first mounted version otherwise.
+func VersionMedia(pattern string) {
 	if api, ok := apiDefinition(); ok {
-		api.Version = ver
+		api.VersionMedia = pattern
 	}
 }
 
@@ -127,6 +161,52 @@ func Description(d string) {
 	}
 }
 
+// RequestID can be used in: API
+//
+// RequestID sets the name of the header that carries the request ID. The generated app package
+// defines a RequestID middleware that reads the request ID from the header or generates one if
+// missing, stores it in the request context and sets it in the same header of the response. The
+// generated RequestIDFromContext function returns the request ID stored in the context. The
+// generated main uses the middleware in place of the default goa request ID middleware.
+// Example:
+//
+//	API("cellar", func() {
+//		RequestID("X-Request-ID")
+//	})
+//
+func RequestID(header string) {
+	if a, ok := apiDefinition(); ok {
+		a.RequestIDHeader = header
+	}
+}
+
+// BodyEnvelope can be used in: API, Resource
+//
+// BodyEnvelope wraps the request and response bodies of the actions in an envelope object. The
+// body is stored under the dataField field of the envelope and the meta object under the
+// metaField field, the envelope has no meta object if metaField is empty. The generated code
+// unwraps the request payloads and wraps the response media types so that the controllers still
+// deal with the unwrapped values. The meta objects are available through the Meta fields of
+// goa.RequestData and goa.ResponseData. The envelope only applies to JSON bodies.
+// BodyEnvelope in Resource overrides the API envelope.
+// Example:
+//
+//	API("cellar", func() {
+//		BodyEnvelope("data", "meta") // {"data": {...}, "meta": {...}}
+//	})
+//
+func BodyEnvelope(dataField, metaField string) {
+	env := &design.EnvelopeDefinition{DataField: dataField, MetaField: metaField}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.Envelope = env
+	case *design.ResourceDefinition:
+		def.Envelope = env
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
 // BasePath can used in: API, Resource
 //
 // BasePath defines the API base path, i.e. the common path prefix to all the API actions.
@@ -179,28 +259,28 @@ func Origin(origin string, dsl func()) {
 		cors.Origin = strings.Trim(origin, "/")
 	}
 
-	if !dslengine.Execute(dsl, cors) {
-		return
-	}
-	var parent dslengine.Definition
+	var origins map[string]*design.CORSDefinition
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
-		parent = def
+		cors.Parent = def
 		if def.Origins == nil {
 			def.Origins = make(map[string]*design.CORSDefinition)
 		}
-		def.Origins[origin] = cors
+		origins = def.Origins
 	case *design.ResourceDefinition:
-		parent = def
+		cors.Parent = def
 		if def.Origins == nil {
 			def.Origins = make(map[string]*design.CORSDefinition)
 		}
-		def.Origins[origin] = cors
+		origins = def.Origins
 	default:
 		dslengine.IncompatibleDSL()
 		return
 	}
-	cors.Parent = parent
+	if !dslengine.Execute(dsl, cors) {
+		return
+	}
+	origins[origin] = cors
 }
 
 // Methods can be used in: Origin
@@ -221,12 +301,48 @@ func Expose(vals ...string) {
 	}
 }
 
-// MaxAge can be used in: Origin
+// MaxAge can be used in: Origin, Cookie
 //
-// MaxAge sets the cache expiry for preflight request responses.
-func MaxAge(val uint) {
-	if cors, ok := corsDefinition(); ok {
-		cors.MaxAge = val
+// MaxAge sets the cache expiry for preflight request responses when used in Origin and the number
+// of seconds until the cookie expires when used in Cookie. The value is either a number of seconds
+// or a duration string as accepted by time.ParseDuration, for example "1h".
+func MaxAge(val interface{}) {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.CORSDefinition, *design.CookieDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	var seconds uint
+	switch actual := val.(type) {
+	case uint:
+		seconds = actual
+	case int:
+		if actual < 0 {
+			dslengine.ReportError("invalid max age %d, max age cannot be negative", actual)
+			return
+		}
+		seconds = uint(actual)
+	case string:
+		d, err := time.ParseDuration(actual)
+		if err != nil {
+			dslengine.ReportError("invalid max age %#v: %s", actual, err)
+			return
+		}
+		if d < 0 {
+			dslengine.ReportError("invalid max age %#v, max age cannot be negative", actual)
+			return
+		}
+		seconds = uint(d / time.Second)
+	default:
+		dslengine.ReportError("invalid max age %#v, max age must be a number of seconds or a duration", val)
+		return
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.CORSDefinition:
+		def.MaxAge = seconds
+	case *design.CookieDefinition:
+		def.MaxAge = int(seconds)
 	}
 }
 
@@ -248,6 +364,24 @@ func TermsOfService(terms string) {
 	}
 }
 
+// ProblemDetails can be used in: API
+//
+// ProblemDetails renders the error responses of the API as problem details documents as defined
+// by RFC 7807 using the "application/problem+json" content type. The argument is the base URI of
+// the problem types: the type of a problem is the base URI followed by the error code. The error
+// detail becomes the problem detail. Resources that define a default error response using
+// DefaultErrorResponse keep rendering their errors with the corresponding media type.
+//
+//	API("cellar", func() {
+//		ProblemDetails("https://cellar.goa.design/problems/")
+//	})
+//
+func ProblemDetails(typeBase string) {
+	if a, ok := apiDefinition(); ok {
+		a.ProblemTypeBase = typeBase
+	}
+}
+
 // Regular expression used to validate RFC1035 hostnames*/
 var hostnameRegex = regexp.MustCompile(`^[[:alnum:]][[:alnum:]\-]{0,61}[[:alnum:]]|[[:alpha:]]$`)
 
@@ -338,15 +472,17 @@ func Docs(dsl func()) {
 	}
 }
 
-// Name can be used in: Contact, License.
+// Name can be used in: Contact, License, Cookie.
 //
-// Name sets the contact or license name.
+// Name sets the contact, license or cookie name.
 func Name(name string) {
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.ContactDefinition:
 		def.Name = name
 	case *design.LicenseDefinition:
 		def.Name = name
+	case *design.CookieDefinition:
+		def.Name = name
 	default:
 		dslengine.IncompatibleDSL()
 	}
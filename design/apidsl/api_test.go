@@ -56,6 +56,38 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Context("with an origin using an invalid max age", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Origin("*", func() {
+					MaxAge("1 hour")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid max age "1 hour"`))
+		})
+	})
+
+	Context("with an origin using an invalid method", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Origin("*", func() {
+					Methods("GET POST")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid method "GET POST"`))
+		})
+	})
+
 	Context("with valid DSL", func() {
 		JustBeforeEach(func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
@@ -201,7 +233,7 @@ var _ = Describe("API", func() {
 		})
 
 		Context("with a BasePath", func() {
-			const basePath = "basePath"
+			const basePath = "/basePath"
 
 			BeforeEach(func() {
 				dsl = func() {
@@ -214,6 +246,91 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with an origin", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Origin("https://app.example.com", func() {
+						Methods("GET", "POST")
+						Headers("Authorization")
+						MaxAge("1h")
+					})
+				}
+			})
+
+			It("sets the API CORS policy", func() {
+				Ω(Design.Origins).Should(HaveKey("https://app.example.com"))
+				cors := Design.Origins["https://app.example.com"]
+				Ω(cors.Methods).Should(Equal([]string{"GET", "POST"}))
+				Ω(cors.Headers).Should(Equal([]string{"Authorization"}))
+				Ω(cors.MaxAge).Should(Equal(uint(3600)))
+			})
+		})
+
+		Context("with ProblemDetails", func() {
+			const typeBase = "https://goa.design/problems/"
+
+			BeforeEach(func() {
+				dsl = func() {
+					ProblemDetails(typeBase)
+				}
+			})
+
+			It("sets the API problem type base", func() {
+				Ω(Design.ProblemTypeBase).Should(Equal(typeBase))
+			})
+		})
+
+		Context("with DefaultResponse", func() {
+			BeforeEach(func() {
+				MediaType("application/vnd.fault", func() {
+					Attributes(func() {
+						Attribute("message", String)
+						Attribute("code", String)
+					})
+					View("default", func() {
+						Attribute("message")
+						Attribute("code")
+					})
+				})
+				dsl = func() {
+					DefaultResponse(InternalServerError, func() {
+						Media("application/vnd.fault")
+					})
+				}
+			})
+
+			It("sets the API default response", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.DefaultResponse).ShouldNot(BeNil())
+				Ω(Design.DefaultResponse.Status).Should(Equal(500))
+				Ω(Design.DefaultResponse.MediaType).Should(Equal("application/vnd.fault"))
+			})
+		})
+
+		Context("with RequestID", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					RequestID("X-Request-ID")
+				}
+			})
+
+			It("sets the API request ID header", func() {
+				Ω(Design.RequestIDHeader).Should(Equal("X-Request-ID"))
+			})
+		})
+
+		Context("with BodyEnvelope", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					BodyEnvelope("data", "meta")
+				}
+			})
+
+			It("sets the API body envelope", func() {
+				Ω(Design.Envelope).Should(Equal(&EnvelopeDefinition{DataField: "data", MetaField: "meta"}))
+			})
+		})
+
 		Context("with Params", func() {
 			const param1Name = "accountID"
 			const param1Type = Integer
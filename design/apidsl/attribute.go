@@ -135,6 +135,21 @@ func Attribute(name string, args ...interface{}) {
 			// DSL did not contain an "Attribute" declaration
 			baseAttr.Type = design.String
 		}
+		if _, ok := baseAttr.Metadata["enum:names"]; ok {
+			if _, ok := baseAttr.Metadata["enum:type"]; !ok {
+				// Name the Go type of enums with named values after the attribute
+				baseAttr.Metadata["enum:type"] = []string{name}
+			}
+		}
+		if baseAttr.Flags != nil {
+			if _, ok := baseAttr.Metadata["flags:type"]; !ok {
+				// Name the Go type of flag sets after the attribute
+				if baseAttr.Metadata == nil {
+					baseAttr.Metadata = make(dslengine.MetadataDefinition)
+				}
+				baseAttr.Metadata["flags:type"] = []string{name}
+			}
+		}
 		parent.Type.(design.Object)[name] = baseAttr
 	}
 }
@@ -310,13 +325,270 @@ func Default(def interface{}) {
 //		Attribute("price", String) //If no Example() is provided, goa generates one that fits your specification
 //	})
 //
-// If you do not want an auto-generated example for an attribute, add NoExample() to it.
-func Example(exp interface{}) {
+// If you do not want an auto-generated example for an attribute, add NoExample() to it. The
+// auto-generated example of an attribute with an Enum validation is the first enum value.
+//
+// Example accepts an optional DSL that sets the options of the example. SkipValidation makes it
+// possible to document values that do not pass the attribute validations such as masked values:
+//
+//	Attribute("card_number", String, func() {
+//		Pattern("^[0-9]{16}$")
+//		Example("XXXX-XXXX-XXXX-1234", func() {
+//			SkipValidation()
+//		})
+//	})
+func Example(exp interface{}, dsl ...func()) {
 	if a, ok := attributeDefinition(); ok {
 		if pass := a.SetExample(exp); !pass {
 			dslengine.ReportError("example value %#v is incompatible with attribute of type %s",
 				exp, a.Type.Name())
+			return
+		}
+		delete(a.Metadata, "example:skip-validation")
+		if len(dsl) == 0 {
+			return
+		}
+		ex := &design.ExampleDefinition{Parent: a}
+		if !dslengine.Execute(dsl[0], ex) {
+			return
+		}
+		if ex.SkipValidation {
+			if a.Metadata == nil {
+				a.Metadata = make(dslengine.MetadataDefinition)
+			}
+			a.Metadata["example:skip-validation"] = []string{"true"}
+		}
+	}
+}
+
+// SkipValidation can be used in: Example
+//
+// SkipValidation indicates that the example does not have to pass the attribute validations. The
+// example is still used in the documentation but the generated tests that send the examples to
+// the generated code and expect them to be accepted, such as the round-trip tests, are not
+// generated for the actions that use it. The setting is stored in the "example:skip-validation"
+// metadata of the attribute and only applies to the example it is set on: attributes that
+// override an inherited example do not inherit the setting.
+func SkipValidation() {
+	if ex, ok := dslengine.CurrentDefinition().(*design.ExampleDefinition); ok {
+		ex.SkipValidation = true
+	} else {
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Faker can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// Faker makes goa generate the examples of the attribute using the given faker directive rather
+// than random values. The examples are realistic and deterministic: they only depend on the seed
+// of the example generator, the API name. The examples are used in the generated documentation and
+// anywhere goa generates examples. The supported directives are "name", "first_name", "last_name",
+// "username", "email", "phone", "company", "job_title", "street_address", "city", "state",
+// "country", "postcode", "domain", "url", "ipv4", "ipv6", "word", "sentence" and "paragraph" for
+// String attributes, "uuid" for String and UUID attributes and "latitude" and "longitude" for
+// Number attributes:
+//
+//	Attribute("email", String, func() {
+//		Format("email")
+//		Faker("email")
+//	})
+//
+// Example takes precedence over Faker. The directive is stored in the "example:faker" metadata of
+// the attribute.
+func Faker(directive string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["example:faker"] = []string{directive}
+	}
+}
+
+// Column can be used in: Attribute
+//
+// Column sets the name of the database column that stores the attribute. The generated struct
+// field has a GORM style "gorm" tag that lists the storage hints of the attribute set with Column,
+// Index and PrimaryKey unless the tag is set explicitly with the "struct:tag:gorm" metadata:
+//
+//	var Account = Type("Account", func() {
+//		Attribute("id", Integer, func() {
+//			PrimaryKey()
+//		})
+//		Attribute("createdAt", DateTime, func() {
+//			Column("created_at")
+//			Index()
+//		})
+//	})
+//
+// The column name is stored in the "storage:column" metadata of the attribute.
+func Column(name string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["storage:column"] = []string{name}
+	}
+}
+
+// Index can be used in: Attribute
+//
+// Index marks the database column that stores the attribute as indexed. The optional argument sets
+// the name of the index, the attributes of a type that use the same index name make up a composite
+// index. See Column for an example. The setting is stored in the "storage:index" metadata of the
+// attribute.
+func Index(name ...string) {
+	if len(name) > 1 {
+		dslengine.ReportError("too many arguments given to Index")
+		return
+	}
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["storage:index"] = name
+	}
+}
+
+// PrimaryKey can be used in: Attribute
+//
+// PrimaryKey marks the database column that stores the attribute as the primary key of its table.
+// A type may define at most one primary key. See Column for an example. The setting is stored in
+// the "storage:primary-key" metadata of the attribute.
+func PrimaryKey() {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["storage:primary-key"] = nil
+	}
+}
+
+// RequiredScope can be used in: Attribute
+//
+// RequiredScope guards the attribute with a security scope: the attribute is omitted from the
+// responses sent to callers that were not granted the scope. The scopes granted to the caller are
+// given by the goa.ScopeChecker that the security middleware stores in the request context, the
+// guarded attributes are omitted if there is none. Guarded attributes cannot be required. The
+// setting only applies to the top level attributes of response media types and is stored in the
+// "security:scope" metadata of the attribute. Example:
+//
+//	var EmployeeMedia = MediaType("application/vnd.employee+json", func() {
+//		Attributes(func() {
+//			Attribute("name", String)
+//			Attribute("salary", Integer, func() {
+//				RequiredScope("admin")
+//			})
+//			Required("name")
+//		})
+//		View("default", func() {
+//			Attribute("name")
+//			Attribute("salary")
+//		})
+//	})
+func RequiredScope(scope string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["security:scope"] = []string{scope}
+	}
+}
+
+// SinceVersion can be used in: Attribute
+//
+// SinceVersion limits the attribute to the given version of the resources and the later ones. The
+// resources that use the attribute as a top level field of their payloads or responses and whose
+// version, as set with the Version DSL, is lower ignore the attribute in the requests and omit it
+// from the responses. Versions are dot separated numbers optionally prefixed with "v", e.g. "v2"
+// or "1.3". The attribute cannot be required. Example:
+//
+//	var BottleMedia = MediaType("application/vnd.bottle+json", func() {
+//		Attributes(func() {
+//			Attribute("name", String)
+//			Attribute("rating", Integer, func() {
+//				SinceVersion("v2") // omitted from the responses of the v1 resources
+//			})
+//		})
+//		View("default", func() {
+//			Attribute("name")
+//			Attribute("rating")
+//		})
+//	})
+//
+// SinceVersion sets the "version:since" metadata of the attribute.
+func SinceVersion(version string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["version:since"] = []string{version}
+	}
+}
+
+// UntilVersion can be used in: Attribute
+//
+// UntilVersion limits the attribute to the given version of the resources and the earlier ones,
+// the resources whose version is greater ignore the attribute in the requests and omit it from the
+// responses. See SinceVersion. UntilVersion sets the "version:until" metadata of the attribute.
+func UntilVersion(version string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["version:until"] = []string{version}
+	}
+}
+
+// EncodeTransform can be used in: Attribute
+//
+// EncodeTransform normalizes the attribute values to a canonical form before they are encoded in
+// the responses, the values held by the controllers are not modified. The built-in transforms are
+// "e164" (phone numbers in E.164 format), "lowercase", "uppercase", "trim" (removes leading and
+// trailing white space) and "url" (lowercases the URL scheme and host). Custom transforms must be
+// registered with design.RegisterEncodeTransform and at runtime with goa.RegisterEncodeTransform.
+// Encode transforms apply to string attributes. The setting only applies to the top level
+// attributes of response media types and is stored in the "encode:transform" metadata of the
+// attribute. Example:
+//
+//	var ContactMedia = MediaType("application/vnd.contact+json", func() {
+//		Attributes(func() {
+//			Attribute("phone", String, func() {
+//				EncodeTransform("e164")
+//			})
+//		})
+//		View("default", func() {
+//			Attribute("phone")
+//		})
+//	})
+func EncodeTransform(name string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["encode:transform"] = []string{name}
+	}
+}
+
+// WireType can be used in: Attribute
+//
+// WireType sets the type used to encode the attribute values in JSON when it differs from the
+// type of the generated Go field. The only supported wire type is String: it applies to Integer,
+// Number and Boolean attributes whose values are encoded as quoted JSON strings, for example to
+// avoid the loss of precision of large integers in JavaScript clients:
+//
+//	Attribute("id", Integer, func() {
+//		WireType(String) // Go field is an int, JSON value is "9007199254740993"
+//	})
+//
+// The generated struct field keeps its Go type and its json tag uses the "string" option so that
+// requests that contain an invalid string are rejected. The wire type is stored in the
+// "json:wire" metadata of the attribute.
+func WireType(t design.DataType) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
 		}
+		a.Metadata["json:wire"] = []string{t.Name()}
 	}
 }
 
@@ -329,6 +601,89 @@ func ReadOnly() {
 	}
 }
 
+// Nullable can be used in: Attribute
+//
+// Nullable marks the attribute as nullable: the request may set it explicitly to null which is
+// distinct from omitting it, for example to clear a value in a PATCH request. The generated field
+// uses the goa.NullableString, goa.NullableInteger, goa.NullableNumber or goa.NullableBoolean type
+// which records whether the attribute was present and whether it was null:
+//
+//	Attribute("nickname", String, func() {
+//		Nullable()
+//	})
+//
+// Nullable only applies to attributes of type String, Integer, Number or Boolean that are neither
+// required nor define a default value or validations.
+func Nullable() {
+	if a, ok := attributeDefinition(); ok {
+		a.SetNullable()
+	}
+}
+
+// DeepObject can be used in: Param
+//
+// DeepObject makes it possible for an action parameter to be an object. The object fields are
+// given in the query string using the bracketed syntax, for example the request
+// "GET /bottles?filter[status]=active&filter[ids][]=1&filter[ids][]=2" sets the fields of:
+//
+//	Params(func() {
+//		Param("filter", func() {
+//			DeepObject()
+//			Attribute("status", String)
+//			Attribute("ids", ArrayOf(Integer))
+//		})
+//	})
+//
+// The object fields must be primitives or arrays of primitives.
+func DeepObject() {
+	if a, ok := attributeDefinition(); ok {
+		a.SetDeepObject()
+	}
+}
+
+// ArrayParamStyle can be used in: Param, Header
+//
+// ArrayParamStyle sets the style used to give the values of an array query string parameter or
+// header:
+//
+//	- "multi" repeats the parameter for each value, e.g. "?ids=1&ids=2", this is the default.
+//	- "csv" separates the values with commas, e.g. "?ids=1,2".
+//	- "ssv" separates the values with spaces, e.g. "?ids=1%202".
+//	- "pipes" separates the values with pipes, e.g. "?ids=1|2".
+//
+// Example:
+//
+//	Params(func() {
+//		Param("ids", ArrayOf(Integer), func() {
+//			ArrayParamStyle("csv")
+//		})
+//	})
+func ArrayParamStyle(style string) {
+	if a, ok := attributeDefinition(); ok {
+		a.SetArrayStyle(style)
+	}
+}
+
+// DefaultFrom can be used in: Attribute
+//
+// DefaultFrom sets the value of the attribute to the value of the given sibling attribute when the
+// former is not set and the latter is. The copy happens in the generated Finalize method once the
+// request payload has been decoded:
+//
+//	Attributes(func() {
+//		Attribute("username", String)
+//		Attribute("nickname", String, func() {
+//			DefaultFrom("username") // nickname defaults to username
+//		})
+//	})
+//
+// The source attribute must exist and have the same kind as the attribute.
+func DefaultFrom(source string) {
+	if a, ok := attributeDefinition(); ok {
+		a.SetDefaultFrom(source)
+	}
+}
+
 // NoExample can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // NoExample sets the example of an attribute to be blank for the documentation. It is used when
@@ -382,23 +737,150 @@ func Enum(val ...interface{}) {
 	}
 }
 
-// SupportedValidationFormats lists the supported formats for use with the
-// Format DSL.
-var SupportedValidationFormats = []string{
-	"cidr",
-	"date",
-	"date-time",
-	"email",
-	"hostname",
-	"ipv4",
-	"ipv6",
-	"ip",
-	"mac",
-	"regexp",
-	"rfc1123",
-	"uri",
+// EnumValues can be used in: Attribute
+//
+// EnumValues defines the values of an integer attribute as a list of named values using Value.
+// The values are added to the attribute "enum" validation and the fields generated for the
+// attribute use a named Go type that defines one constant per value as well as String and Parse
+// functions. The Go type is named after the attribute, use the "enum:type" metadata to override the
+// name. Attributes that share the same Go type must define the same values.
+// Values decoded from JSON may be given using either their name or their number, the generated
+// code encodes the values using their number unless the "enum:encode" metadata is set to "name".
+// Example:
+//
+//	Attribute("status", Integer, func() {
+//		EnumValues(func() {
+//			Value("ACTIVE", 1)
+//			Value("INACTIVE", 2)
+//		})
+//		Metadata("enum:encode", "name") // Encode values as "ACTIVE" or "INACTIVE"
+//	})
+//
+func EnumValues(dsl func()) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.IntegerKind {
+			dslengine.ReportError("EnumValues requires an integer attribute, got %s", a.Type.Name())
+			return
+		}
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["enum:names"] = []string{}
+		dslengine.Execute(dsl, a)
+	}
+}
+
+// Value can be used in: EnumValues
+//
+// Value defines a named value of an integer enum, see EnumValues.
+func Value(name string, value int) {
+	if a, ok := attributeDefinition(); ok {
+		if _, ok := a.Metadata["enum:names"]; !ok {
+			dslengine.IncompatibleDSL()
+			return
+		}
+		a.Metadata["enum:names"] = append(a.Metadata["enum:names"], name)
+		if a.Validation == nil {
+			a.Validation = &dslengine.ValidationDefinition{}
+		}
+		a.Validation.Values = append(a.Validation.Values, value)
+	}
+}
+
+// FlagSet can be used in: Attribute
+//
+// FlagSet defines a string attribute whose values are sets of flags defined with Flag. The values
+// are comma separated lists of flag names on the wire and the fields generated for the attribute
+// use a named Go integer type that holds the bitmask of the flags. The Go type defines one
+// constant per flag as well as String and Parse functions and is named after the attribute, use
+// the "flags:type" metadata to override the name. Attributes that share the same Go type must
+// define the same flags. Flag set attributes cannot define validations. Example:
+//
+//	Attribute("permissions", String, func() {
+//		FlagSet(func() {
+//			Flag("READ", 1)
+//			Flag("WRITE", 2)
+//		})
+//		Default("READ")
+//	})
+//
+// The flags are stored in the Flags field of the attribute.
+func FlagSet(dsl func()) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.StringKind {
+			dslengine.ReportError("FlagSet requires a string attribute, got %s", a.Type.Name())
+			return
+		}
+		a.Flags = []*design.FlagDefinition{}
+		dslengine.Execute(dsl, a)
+	}
+}
+
+// Flag can be used in: FlagSet
+//
+// Flag defines a flag of a flag set, see FlagSet. The value is the bit of the flag and must be a
+// power of two.
+func Flag(name string, value int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Flags == nil {
+			dslengine.IncompatibleDSL()
+			return
+		}
+		a.Flags = append(a.Flags, &design.FlagDefinition{Name: name, Value: value})
+	}
 }
 
+// Transitions can be used in: Attribute
+//
+// Transitions defines the allowed transitions between the values of a string enum attribute, for
+// example the values of a status field. The transitions are listed with From and To, all the
+// values must be listed in the attribute Enum validation. goagen generates a function that
+// validates a transition for each user type attribute that defines transitions as well as a
+// ValidateTransitions method on the user type that validates the transitions between an old value
+// of the type (e.g. loaded by the business logic prior to an update) and the new value. Keeping the
+// same value is always allowed. Example:
+//
+//	Attribute("status", String, func() {
+//		Enum("pending", "active", "cancelled", "closed")
+//		Transitions(func() {
+//			From("pending").To("active", "cancelled")
+//			From("active").To("closed")
+//		})
+//	})
+//
+func Transitions(dsl func()) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.StringKind {
+			incompatibleAttributeType("transitions", a.Type.Name(), "a string")
+			return
+		}
+		if a.Transitions == nil {
+			a.Transitions = []*design.TransitionDefinition{}
+		}
+		dslengine.Execute(dsl, a)
+	}
+}
+
+// From can be used in: Transitions
+//
+// From starts the definition of the transitions from the given value, use To on the result to
+// list the values the attribute may transition to, see Transitions.
+func From(state string) *design.TransitionDefinition {
+	t := &design.TransitionDefinition{From: state}
+	if a, ok := attributeDefinition(); ok {
+		if a.Transitions == nil {
+			dslengine.IncompatibleDSL()
+			return t
+		}
+		a.Transitions = append(a.Transitions, t)
+	}
+	return t
+}
+
+// SupportedValidationFormats lists the built-in formats supported by the Format DSL. Additional
+// formats may be registered with design.RegisterFormat.
+var SupportedValidationFormats = design.BuiltinFormats
+
 // Format can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // Format adds a "format" validation to the attribute.
@@ -424,12 +906,16 @@ var SupportedValidationFormats = []string{
 // "regexp": RE2 regular expression
 //
 // "rfc1123": RFC1123 date time
+//
+// "decimal": decimal number, e.g. "-12.50", see DecimalScale and DecimalPrecision
+//
+// Custom formats registered with design.RegisterFormat may also be used.
 func Format(f string) {
 	if a, ok := attributeDefinition(); ok {
 		if a.Type != nil && a.Type.Kind() != design.StringKind {
 			incompatibleAttributeType("format", a.Type.Name(), "a string")
 		} else {
-			supported := false
+			supported := design.CustomFormat(f) != nil
 			for _, s := range SupportedValidationFormats {
 				if s == f {
 					supported = true
@@ -569,6 +1055,99 @@ func MaxLength(val int) {
 	}
 }
 
+// DecimalScale can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// DecimalScale sets the maximum number of fractional digits of a string attribute that uses the
+// "decimal" format. Example:
+//
+//	Attribute("price", String, func() {
+//		Format("decimal")
+//		DecimalScale(2)      // At most 2 fractional digits, e.g. "12.50"
+//		DecimalPrecision(10) // At most 10 digits in total
+//	})
+//
+func DecimalScale(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.StringKind {
+			incompatibleAttributeType("decimal scale", a.Type.Name(), "a string")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.DecimalScale = &val
+		}
+	}
+}
+
+// DecimalPrecision can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// DecimalPrecision sets the maximum total number of digits of a string attribute that uses the
+// "decimal" format, leading zeros excluded. See DecimalScale.
+func DecimalPrecision(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.StringKind {
+			incompatibleAttributeType("decimal precision", a.Type.Name(), "a string")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.DecimalPrecision = &val
+		}
+	}
+}
+
+// MinItems can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// MinItems adds a "minItems" validation to the array attribute.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor45.
+func MinItems(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.ArrayKind {
+			incompatibleAttributeType("minimum items", a.Type.Name(), "an array")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.MinItems = &val
+		}
+	}
+}
+
+// MaxItems can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// MaxItems adds a "maxItems" validation to the array attribute.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor42.
+func MaxItems(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.ArrayKind {
+			incompatibleAttributeType("maximum items", a.Type.Name(), "an array")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.MaxItems = &val
+		}
+	}
+}
+
+// UniqueItems can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// UniqueItems adds a "uniqueItems" validation to the array attribute. The array elements must be
+// of a comparable primitive type: Boolean, Integer, Number, String, DateTime or UUID.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor49.
+func UniqueItems() {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.ArrayKind {
+			incompatibleAttributeType("unique items", a.Type.Name(), "an array")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.UniqueItems = true
+		}
+	}
+}
+
 // Required can be used in: Attributes, Headers, Payload, Type, Params
 //
 // Required adds a "required" validation to the attribute.
@@ -596,6 +1175,83 @@ func Required(names ...string) {
 	}
 }
 
+// Bound can be used in: Attributes, Type, MediaType, Payload and Attribute of type Object
+//
+// Bound requires the value of a numeric field to be bounded by the value of another numeric field of
+// the same object. The operator is one of LessThan, LessThanOrEqual, GreaterThan or
+// GreaterThanOrEqual. The generated validation code only checks the bound when both fields are
+// present. Example:
+//
+//	var OrderLine = Type("OrderLine", func() {
+//		Attribute("price", Number)
+//		Attribute("discount", Number)
+//		Bound("discount", LessThanOrEqual, "price")
+//	})
+func Bound(field, operator, other string) {
+	var at *design.AttributeDefinition
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		at = def
+	case *design.MediaTypeDefinition:
+		at = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+
+	if at.Type != nil && at.Type.Kind() != design.ObjectKind {
+		incompatibleAttributeType("bound", at.Type.Name(), "an object")
+		return
+	}
+	switch operator {
+	case design.LessThan, design.LessThanOrEqual, design.GreaterThan, design.GreaterThanOrEqual:
+	default:
+		dslengine.ReportError("invalid bound operator %#v, must be one of %#v, %#v, %#v or %#v",
+			operator, design.LessThan, design.LessThanOrEqual, design.GreaterThan, design.GreaterThanOrEqual)
+		return
+	}
+	if at.Validation == nil {
+		at.Validation = &dslengine.ValidationDefinition{}
+	}
+	at.Validation.AddBounds([]*dslengine.BoundDefinition{{Field: field, Operator: operator, Other: other}})
+}
+
+// RequiredTogether can be used in: Attributes, Type, MediaType, Payload and Attribute of type Object
+//
+// RequiredTogether requires the given fields of the object to be either all present or all absent.
+// The fields must be optional. The generated validation code returns an error naming the group when
+// only some of the fields are present. Example:
+//
+//	var Location = Type("Location", func() {
+//		Attribute("name", String)
+//		Attribute("lat", Number)
+//		Attribute("lng", Number)
+//		RequiredTogether("lat", "lng")
+//	})
+func RequiredTogether(names ...string) {
+	var at *design.AttributeDefinition
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		at = def
+	case *design.MediaTypeDefinition:
+		at = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+
+	if at.Type != nil && at.Type.Kind() != design.ObjectKind {
+		incompatibleAttributeType("required together", at.Type.Name(), "an object")
+		return
+	}
+	if at.Validation == nil {
+		at.Validation = &dslengine.ValidationDefinition{}
+	}
+	at.Validation.AddRequiredTogether([][]string{names})
+}
+
 // incompatibleAttributeType reports an error for validations defined on
 // incompatible attributes (e.g. max value on string).
 func incompatibleAttributeType(validation, actual, expected string) {
@@ -212,6 +212,38 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name and a DSL defining a nullable attribute", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { Nullable() }
+		})
+
+		It("produces a nullable attribute", func() {
+			t := parent.Type
+			Ω(t).ShouldNot(BeNil())
+			Ω(t).Should(BeAssignableToTypeOf(Object{}))
+			o := t.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].IsNullable()).Should(BeTrue())
+		})
+	})
+
+	Context("with a name and a DSL defining a default source", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { DefaultFrom("bar") }
+		})
+
+		It("records the default source", func() {
+			t := parent.Type
+			Ω(t).ShouldNot(BeNil())
+			Ω(t).Should(BeAssignableToTypeOf(Object{}))
+			o := t.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].DefaultFrom()).Should(Equal("bar"))
+		})
+	})
+
 	Context("with a name and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -231,6 +263,46 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name and a DSL defining an unknown format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { Format("unknown") }
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unsupported format "unknown"`))
+		})
+	})
+
+	Context("with a name and a DSL defining a registered custom format", func() {
+		BeforeEach(func() {
+			RegisterFormat("isbn", func(string) error { return nil }, "")
+			name = "foo"
+			dsl = func() { Format("isbn") }
+		})
+
+		It("produces an attribute with a format validation", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].Validation).ShouldNot(BeNil())
+			Ω(o[name].Validation.Format).Should(Equal("isbn"))
+		})
+	})
+
+	Context("with a name, type string and a DSL defining an items validation", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { UniqueItems() }
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("unique items"))
+		})
+	})
+
 	Context("with a name, type datetime and a DSL defining a default value", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -251,6 +323,47 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type array and a DSL defining a Go slice default value", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = ArrayOf(String)
+			dsl = func() { Default([]string{"a", "b"}) }
+		})
+
+		It("converts the default value to a design array value", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].DefaultValue).Should(Equal([]interface{}{"a", "b"}))
+		})
+	})
+
+	Context("with a name, type hash and a DSL defining a Go map default value", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = HashOf(String, ArrayOf(Integer))
+			dsl = func() { Default(map[string][]int{"a": {1, 2}}) }
+		})
+
+		It("converts the default value to a design hash value", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].DefaultValue).Should(Equal(map[interface{}]interface{}{"a": []interface{}{1, 2}}))
+		})
+	})
+
+	Context("with a name, type array and a DSL defining a default value with invalid elements", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = ArrayOf(String)
+			dsl = func() { Default([]int{1, 2}) }
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("is incompatible with attribute of type"))
+		})
+	})
+
 	Context("with a name, type integer and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -271,6 +384,170 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type integer and a DSL defining named enum values", func() {
+		BeforeEach(func() {
+			name = "status"
+			dataType = Integer
+			dsl = func() {
+				EnumValues(func() {
+					Value("ACTIVE", 1)
+					Value("INACTIVE", 2)
+				})
+			}
+		})
+
+		It("produces an attribute of type integer with named enum values", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Type).Should(Equal(Integer))
+			Ω(o[name].Validation.Values).Should(Equal([]interface{}{1, 2}))
+			Ω(o[name].NamedEnumValues()).Should(Equal([]*EnumValue{
+				{Name: "ACTIVE", Value: 1},
+				{Name: "INACTIVE", Value: 2},
+			}))
+			Ω(o[name].EnumTypeName()).Should(Equal("status"))
+			Ω(o[name].EncodesEnumNames()).Should(BeFalse())
+		})
+	})
+
+	Context("with a name, type string and a DSL defining named enum values", func() {
+		BeforeEach(func() {
+			name = "status"
+			dataType = String
+			dsl = func() {
+				EnumValues(func() {
+					Value("ACTIVE", 1)
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("EnumValues requires an integer attribute"))
+		})
+	})
+
+	Context("with a name, type string and a DSL defining a flag set", func() {
+		BeforeEach(func() {
+			name = "permissions"
+			dataType = String
+			dsl = func() {
+				FlagSet(func() {
+					Flag("READ", 1)
+					Flag("WRITE", 2)
+				})
+			}
+		})
+
+		It("produces an attribute with flags", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Flags).Should(Equal([]*FlagDefinition{
+				{Name: "READ", Value: 1},
+				{Name: "WRITE", Value: 2},
+			}))
+			Ω(o[name].FlagSetTypeName()).Should(Equal("permissions"))
+		})
+	})
+
+	Context("with a name, type integer and a DSL defining a flag set", func() {
+		BeforeEach(func() {
+			name = "permissions"
+			dataType = Integer
+			dsl = func() {
+				FlagSet(func() {
+					Flag("READ", 1)
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("FlagSet requires a string attribute"))
+		})
+	})
+
+	Context("with a name, type string and a DSL defining transitions", func() {
+		BeforeEach(func() {
+			name = "status"
+			dataType = String
+			dsl = func() {
+				Enum("pending", "active", "cancelled", "closed")
+				Transitions(func() {
+					From("pending").To("active", "cancelled")
+					From("active").To("closed")
+				})
+			}
+		})
+
+		It("produces an attribute with a transition table", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Transitions).Should(Equal([]*TransitionDefinition{
+				{From: "pending", Targets: []string{"active", "cancelled"}},
+				{From: "active", Targets: []string{"closed"}},
+			}))
+		})
+	})
+
+	Context("with a name, type integer and a DSL defining transitions", func() {
+		BeforeEach(func() {
+			name = "status"
+			dataType = Integer
+			dsl = func() {
+				Transitions(func() {
+					From("1").To("2")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid transitions validation definition: attribute must be a string"))
+		})
+	})
+
+	Context("with a name, type string and a DSL defining an example that skips validation", func() {
+		BeforeEach(func() {
+			name = "card_number"
+			dataType = String
+			dsl = func() {
+				Pattern("^[0-9]{16}$")
+				Example("XXXX-XXXX-XXXX-1234", func() {
+					SkipValidation()
+				})
+			}
+		})
+
+		It("records the example and the setting", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Example).Should(Equal("XXXX-XXXX-XXXX-1234"))
+			Ω(o[name].ExampleSkipsValidation()).Should(BeTrue())
+		})
+	})
+
+	Context("with a name, type string and a DSL using SkipValidation outside of Example", func() {
+		BeforeEach(func() {
+			name = "card_number"
+			dataType = String
+			dsl = func() {
+				Example("1234123412341234")
+				SkipValidation()
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].ExampleSkipsValidation()).Should(BeFalse())
+		})
+	})
+
 	Context("with a name, type integer, a description and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -399,3 +676,38 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 })
+
+var _ = Describe("SkipValidation", func() {
+	var child *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		parent := Type("Card", func() {
+			Attribute("number", String, func() {
+				Example("XXXX-XXXX-XXXX-1234", func() {
+					SkipValidation()
+				})
+			})
+			Attribute("masked", String, func() {
+				Example("XXXX-XXXX-XXXX-1234", func() {
+					SkipValidation()
+				})
+			})
+		})
+		child = Type("CardPayload", func() {
+			Reference(parent)
+			Attribute("number", func() {
+				Example("1234123412341234")
+			})
+			Attribute("masked")
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	It("only applies to the example it is set on", func() {
+		o := child.Type.ToObject()
+		Ω(o["number"].Example).Should(Equal("1234123412341234"))
+		Ω(o["number"].ExampleSkipsValidation()).Should(BeFalse())
+		Ω(o["masked"].ExampleSkipsValidation()).Should(BeTrue())
+	})
+})
@@ -112,6 +112,16 @@ func corsDefinition() (*design.CORSDefinition, bool) {
 	return cors, ok
 }
 
+// cookieDefinition returns true and current context if it is a CookieDefinition,
+// nil and false otherwise.
+func cookieDefinition() (*design.CookieDefinition, bool) {
+	c, ok := dslengine.CurrentDefinition().(*design.CookieDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return c, ok
+}
+
 // actionDefinition returns true and current context if it is an ActionDefinition,
 // nil and false otherwise.
 func actionDefinition() (*design.ActionDefinition, bool) {
@@ -329,6 +329,52 @@ func Attributes(apidsl func()) {
 	}
 }
 
+// Computed can be used in: Attributes
+//
+// Computed defines a media type attribute whose value is computed from the other attributes when
+// a response is rendered rather than being set by the controller. Computed accepts the same
+// arguments as Attribute. The DSL must set the compute function with the "compute:function"
+// metadata: the first value is the qualified name of the function and the second optional value
+// is the Go import path of its package. The "compute:args" metadata lists the media type
+// attributes whose values are given to the function in order. The function accepts the values
+// using the Go types of the generated fields and returns a value of the attribute type. Example:
+//
+//	var PersonMedia = MediaType("application/vnd.person+json", func() {
+//		Attributes(func() {
+//			Attribute("first", String)
+//			Attribute("last", String)
+//			Computed("full_name", String, func() {
+//				Metadata("compute:function", "names.FullName", "github.com/me/names")
+//				Metadata("compute:args", "first", "last")
+//			})
+//		})
+//		View("default", func() {
+//			Attribute("first")
+//			Attribute("last")
+//			Attribute("full_name")
+//		})
+//	})
+//
+// The generated media type struct exposes a ComputeFields method called by the response helpers
+// before the response is encoded. Computed attributes are read-only and cannot be required so that
+// decoders do not expect them.
+func Computed(name string, args ...interface{}) {
+	mt, ok := mediaTypeDefinition()
+	if !ok {
+		return
+	}
+	Attribute(name, args...)
+	att, ok := mt.Type.ToObject()[name]
+	if !ok {
+		return
+	}
+	if _, ok := att.Metadata["compute:function"]; !ok {
+		dslengine.ReportError(`computed attribute %#v must set the compute function with Metadata("compute:function", ...)`, name)
+		return
+	}
+	att.SetReadOnly()
+}
+
 // Links can be used in: MediaType
 //
 // Links implements the media type links apidsl. See MediaType.
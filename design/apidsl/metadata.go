@@ -27,13 +27,28 @@ import (
 //        Metadata("struct:field:type", "json.RawMessage", "encoding/json")
 //        Metadata("struct:field:type", "mypackage.MyType", "github.com/me/mypackage")
 //
-// `struct:tag:xxx`: sets the struct field tag xxx on generated Go structs.  Overrides tags that
-// goagen would otherwise set.  If the metadata value is a slice then the strings are joined with
-// the space character as separator.
+// `struct:tag:xxx`: sets the struct field tag xxx on generated Go structs.  Overrides the tag with
+// the same name that goagen would otherwise set (form, json, yaml or xml), other tags are added
+// after the tags set by goagen in alphabetical order.  If the metadata value is a slice then the
+// strings are joined with the comma character as separator.
 // Applicable to attributes only.
 //
 //        Metadata("struct:tag:json", "myName,omitempty")
 //        Metadata("struct:tag:xml", "myName,attr")
+//        Metadata("struct:tag:bson", "my_name,omitempty")
+//
+// `json:marshaler`: sets the Go type used to encode and decode the field to and from JSON. The type
+// must have the same underlying type as the field and implement json.Marshaler and
+// json.Unmarshaler. The second optional value specifies the Go import path to the package
+// defining the type. Applicable to attributes of primitive types only.
+//
+//        Metadata("json:marshaler", "mypackage.EpochMillis", "github.com/me/mypackage")
+//
+// `i18n:key`: sets the localization key included in the "i18n_key" metadata of the errors produced
+// when the attribute fails to validate so that clients may translate the error messages.
+// Applicable to attributes only.
+//
+//        Metadata("i18n:key", "errors.bottle.vintage")
 //
 // `swagger:generate`: specifies whether Swagger specification should be generated. Defaults to
 // true.
@@ -54,6 +69,11 @@ import (
 //        Metadata("swagger:tag:Backend:url", "http://example.com")
 //        Metadata("swagger:tag:Backend:url:desc", "See more docs here")
 //
+// `swagger:tags`: adds tags to the Swagger operations, see Tags.
+// Applicable to resources and actions.
+//
+//        Metadata("swagger:tags", "billing", "v2")
+//
 // `swagger:extension:xxx`: sets the Swagger extensions xxx. It can have any valid JSON format value.
 // Applicable to
 // api as within the info and tag object,
@@ -67,6 +87,39 @@ import (
 //
 //        Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
 //
+// `enum:type`: overrides the name of the Go type generated for integer enums defined with
+// EnumValues.
+// Applicable to attributes only.
+//
+//        Metadata("enum:type", "BottleStatus")
+//
+// `enum:encode`: sets how the values of integer enums defined with EnumValues are encoded, either
+// "number" (the default) or "name".
+// Applicable to attributes only.
+//
+//        Metadata("enum:encode", "name")
+//
+// `flags:type`: overrides the name of the Go type generated for flag sets defined with FlagSet.
+// Applicable to attributes only.
+//
+//        Metadata("flags:type", "Permissions")
+//
+// `sql:bindable`: generates a SQLWhere method on the payload type that builds a SQL WHERE clause
+// made of equality predicates for the payload fields that are set together with the corresponding
+// query arguments. The optional value sets the placeholder style, either "?" (the default) or "$"
+// for numbered placeholders.
+// Applicable to payloads and types.
+//
+//        Metadata("sql:bindable")
+//        Metadata("sql:bindable", "$")
+//
+// `terraform:resource`: marks the resource as backing the Terraform resource with the given name.
+// The resource must define the "create", "show", "update" and "delete" actions that implement the
+// Terraform resource lifecycle. See genschema.GenerateTerraformSchemas.
+// Applicable to resources only.
+//
+//        Metadata("terraform:resource", "user")
+//
 // The special key names listed above may be used as follows:
 //
 //        var Account = Type("Account", func() {
@@ -111,3 +164,30 @@ func Metadata(name string, value ...string) {
 		dslengine.IncompatibleDSL()
 	}
 }
+
+// Tags can be used in: Resource, Action
+//
+// Tags adds tags to the Swagger operations of the resource actions or of the action. The
+// operations are tagged with the name of their resource unless the resource or the action sets
+// tags with the "swagger:tag:xxx" metadata, the tags given to Tags are added to these. The
+// top level tags of the Swagger specification list the tags added by Tags and the resource names
+// used as tags, the description of the resource is used as the description of its tag. Example:
+//
+//	Resource("invoice", func() {
+//		Description("Invoice management")
+//		Tags("billing")
+//		Action("pay", func() {
+//			Routing(POST("/:id/pay"))
+//			Tags("v2") // The operation tags are "invoice", "billing" and "v2"
+//		})
+//	})
+//
+// Tags appends the names to the "swagger:tags" metadata of the definition.
+func Tags(names ...string) {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition, *design.ResourceDefinition:
+		Metadata("swagger:tags", names...)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
@@ -109,6 +109,50 @@ func DefaultMedia(val interface{}, viewName ...string) {
 	}
 }
 
+// DefaultErrorResponse can be used in: Resource
+//
+// DefaultErrorResponse sets the media type used to render all the error responses of the resource
+// actions, by identifier or by reference using a value returned by MediaType. Responses that use
+// the built-in ErrorMedia media type as well as the errors returned by the action handlers (for
+// example the request validation errors) are rendered using the given media type instead:
+//
+//	var APIError = MediaType("application/vnd.api.error", func() {
+//		Attributes(func() {
+//			Attribute("message", String)
+//			Attribute("code", String)
+//			Required("message", "code")
+//		})
+//		View("default", func() {
+//			Attribute("message")
+//			Attribute("code")
+//		})
+//	})
+//
+//	var _ = Resource("bottle", func() {
+//		DefaultErrorResponse(APIError)
+//		Response(BadRequest, ErrorMedia) // Rendered using APIError
+//		// ...
+//	})
+//
+// The media type must define the "message" and "code" string attributes which receive the detail
+// and code of the error respectively. It may also define the "id" string and "status" integer
+// attributes. Responses that explicitly use a different media type are not affected.
+func DefaultErrorResponse(val interface{}) {
+	if r, ok := resourceDefinition(); ok {
+		if m, ok := val.(*design.MediaTypeDefinition); ok {
+			if m.UserTypeDefinition == nil {
+				dslengine.ReportError("invalid media type specification, media type is not initialized")
+			} else {
+				r.ErrorMediaType = m.Identifier
+			}
+		} else if identifier, ok := val.(string); ok {
+			r.ErrorMediaType = identifier
+		} else {
+			dslengine.ReportError("media type must be a string or a *design.MediaTypeDefinition, got %#v", val)
+		}
+	}
+}
+
 // Parent can be used in: Resource
 //
 // Parent sets the resource parent. The parent resource is used to compute the path to the resource
@@ -308,6 +308,129 @@ var _ = Describe("Resource", func() {
 		})
 	})
 
+	Context("with a default error response", func() {
+		const identifier = "application/vnd.api.error"
+
+		BeforeEach(func() {
+			MediaType(identifier, func() {
+				Attributes(func() {
+					Attribute("message", String)
+					Attribute("code", String)
+					Attribute("status", Integer)
+				})
+				View("default", func() {
+					Attribute("message")
+					Attribute("code")
+					Attribute("status")
+				})
+			})
+			name = "foo"
+			dsl = func() {
+				DefaultErrorResponse(identifier)
+			}
+		})
+
+		It("sets the error media type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(res.ErrorMediaType).Should(Equal(identifier))
+			Ω(res.ErrorMedia()).ShouldNot(BeNil())
+		})
+	})
+
+	Context("with a default error response missing the standard error fields", func() {
+		const identifier = "application/vnd.api.error"
+
+		BeforeEach(func() {
+			MediaType(identifier, func() {
+				Attributes(func() {
+					Attribute("message", String)
+					Attribute("status", String)
+				})
+				View("default", func() {
+					Attribute("message")
+					Attribute("status")
+				})
+			})
+			name = "foo"
+			dsl = func() {
+				DefaultErrorResponse(identifier)
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`must define the "code" attribute`))
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`attribute "status" of default error response media type`))
+		})
+	})
+
+	Context("with a default response", func() {
+		var fields func()
+		var status string
+
+		BeforeEach(func() {
+			fields = func() {
+				Attribute("message", String)
+				Attribute("code", String)
+			}
+			status = ServiceUnavailable
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				DefaultResponse(InternalServerError, func() {
+					Media("application/vnd.fault")
+				})
+			})
+			MediaType("application/vnd.fault", func() {
+				Attributes(fields)
+				View("default", func() {
+					Attribute("message")
+				})
+			})
+			res = Resource("foo", func() {
+				DefaultResponse(status, func() {
+					Media("application/vnd.fault")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("overrides the API default response", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(res.DefaultResponse).ShouldNot(BeNil())
+			Ω(res.CatchAllResponse().Status).Should(Equal(503))
+			Ω(Design.DefaultResponse.Status).Should(Equal(500))
+		})
+
+		Context("with a success status", func() {
+			BeforeEach(func() {
+				status = OK
+			})
+
+			It("fails", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("default response status must be an error status (4xx or 5xx), got 200"))
+			})
+		})
+
+		Context("with a media type that cannot receive the error fields", func() {
+			BeforeEach(func() {
+				fields = func() {
+					Attribute("message", String)
+					Attribute("id", Integer)
+				}
+			})
+
+			It("fails", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`default response media type "application/vnd.fault" must define the "code" attribute`))
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`attribute "id" of default response media type "application/vnd.fault" must be of type string`))
+			})
+		})
+	})
+
 	Context("with a trait that does not exist", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -113,6 +113,54 @@ func Response(name string, paramsAndDSL ...interface{}) {
 	}
 }
 
+// DefaultResponse can be used in: API, Resource
+//
+// DefaultResponse defines the response used to render the errors returned by the action handlers
+// that are not goa.ServiceError values and the panics of the action handlers, these are otherwise
+// rendered as generic internal errors by the error handler middleware. DefaultResponse accepts the
+// same arguments as Response, the response must use a media type defined in the design:
+//
+//	var Fault = MediaType("application/vnd.fault", func() {
+//		Attributes(func() {
+//			Attribute("message", String)
+//			Attribute("code", String)
+//			Attribute("id", String)
+//			Required("message", "code")
+//		})
+//		View("default", func() {
+//			Attribute("message")
+//			Attribute("code")
+//			Attribute("id")
+//		})
+//	})
+//
+//	var _ = API("cellar", func() {
+//		DefaultResponse(InternalServerError, func() {
+//			Media(Fault)
+//		})
+//	})
+//
+// As with DefaultErrorResponse the media type must define the "message" and "code" string
+// attributes and may define the "id" string and "status" integer attributes. The message is the
+// text of the response status so that the details of the error are not leaked, the error is logged
+// together with the ID of the response. A resource default response overrides the API one.
+func DefaultResponse(name string, paramsAndDSL ...interface{}) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
+			resp.Parent = def
+			def.DefaultResponse = resp
+		}
+	case *design.ResourceDefinition:
+		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
+			resp.Parent = def
+			def.DefaultResponse = resp
+		}
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
 // Status can be used in: Response, ResponseTemplate
 //
 // Status sets the Response status.
@@ -122,6 +170,63 @@ func Status(status int) {
 	}
 }
 
+// Cookie can be used in: Response
+//
+// Cookie defines a cookie set by the response with a Set-Cookie header. The value of the cookie is
+// the value of the response media type string attribute whose name is given by Name. The attribute
+// is removed from the response body and the generated client sets it back from the cookie when
+// decoding the response. The cookie attributes are defined with HTTPOnly, Secure, SameSite and
+// MaxAge. Example:
+//
+//	Response(OK, SessionMedia, func() {
+//		Cookie(func() {
+//			Name("session")         // Name of the cookie and of the media type attribute
+//			HTTPOnly()              // Cookie is not accessible to scripts
+//			Secure()                // Cookie is only sent over HTTPS
+//			SameSite("Strict")      // "Strict", "Lax" or "None"
+//			MaxAge("24h")           // Number of seconds or duration until the cookie expires
+//		})
+//	})
+func Cookie(dsl func()) {
+	r, ok := responseDefinition()
+	if !ok {
+		return
+	}
+	c := &design.CookieDefinition{Parent: r}
+	if !dslengine.Execute(dsl, c) {
+		return
+	}
+	r.Cookies = append(r.Cookies, c)
+}
+
+// HTTPOnly can be used in: Cookie
+//
+// HTTPOnly sets the HttpOnly attribute of the cookie so that it is not accessible to scripts.
+func HTTPOnly() {
+	if c, ok := cookieDefinition(); ok {
+		c.HTTPOnly = true
+	}
+}
+
+// Secure can be used in: Cookie
+//
+// Secure sets the Secure attribute of the cookie so that it is only sent over HTTPS.
+func Secure() {
+	if c, ok := cookieDefinition(); ok {
+		c.Secure = true
+	}
+}
+
+// SameSite can be used in: Cookie
+//
+// SameSite sets the SameSite attribute of the cookie, one of "Strict", "Lax" or "None". Cookies
+// with the "None" SameSite attribute must be Secure.
+func SameSite(mode string) {
+	if c, ok := cookieDefinition(); ok {
+		c.SameSite = mode
+	}
+}
+
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
 	var params []string
 	var dsl func()
@@ -61,6 +61,146 @@ func Type(name string, dsl func()) *design.UserTypeDefinition {
 	return t
 }
 
+// Extend can be used in: Type
+//
+// Extend makes the type extend the given base type. The attributes and required attributes of the
+// base type are copied into the type unless the type defines an attribute with the same name. The
+// generated Go struct thus contains all the fields of the base type while the generated OpenAPI
+// specification describes the type with "allOf" referring to the base type definition. If the base
+// type defines a discriminator (see Discriminator) the type discriminator attribute may only take
+// the value of the type name. Example:
+//
+//	var Animal = Type("Animal", func() {
+//		Attribute("kind", String, func() {
+//			Enum("Dog", "Cat")
+//		})
+//		Attribute("name", String)
+//		Discriminator("kind")
+//	})
+//
+//	var Dog = Type("Dog", func() {
+//		Extend(Animal)
+//		Attribute("barks", Boolean)
+//	})
+func Extend(base *design.UserTypeDefinition) {
+	parent, ok := dslengine.CurrentDefinition().(*design.AttributeDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if base == nil {
+		dslengine.ReportError("base type cannot be nil")
+		return
+	}
+	if base.DSLFunc != nil {
+		dsl := base.DSLFunc
+		base.DSLFunc = nil
+		dslengine.Execute(dsl, base.AttributeDefinition)
+	}
+	obj := base.ToObject()
+	if obj == nil {
+		dslengine.ReportError("base type %#v must be an object", base.TypeName)
+		return
+	}
+	if parent.Type == nil {
+		parent.Type = make(design.Object)
+	}
+	o, ok := parent.Type.(design.Object)
+	if !ok {
+		dslengine.ReportError("can't extend type %#v with attribute of type %s", base.TypeName, parent.Type.Name())
+		return
+	}
+	var name string
+	for n, t := range design.Design.Types {
+		if t.AttributeDefinition == parent {
+			name = n
+			break
+		}
+	}
+	for n, att := range obj {
+		if _, ok := o[n]; ok {
+			continue
+		}
+		dup := design.DupAtt(att)
+		if n == base.Discriminator() && name != "" {
+			if dup.Validation == nil {
+				dup.Validation = &dslengine.ValidationDefinition{}
+			}
+			dup.Validation.Values = []interface{}{name}
+		}
+		o[n] = dup
+	}
+	if base.Validation != nil && len(base.Validation.Required) > 0 {
+		if parent.Validation == nil {
+			parent.Validation = &dslengine.ValidationDefinition{}
+		}
+		parent.Validation.AddRequired(base.Validation.Required)
+	}
+	if parent.Metadata == nil {
+		parent.Metadata = make(dslengine.MetadataDefinition)
+	}
+	parent.Metadata["type:extends"] = []string{base.TypeName}
+}
+
+// Discriminator can be used in: Type
+//
+// Discriminator sets the name of the attribute whose value identifies the type that extends the
+// type being defined, see Extend. The attribute must be a string attribute whose enum values list
+// the names of all the types that extend the type. Discriminator also makes the attribute required.
+func Discriminator(name string) {
+	parent, ok := dslengine.CurrentDefinition().(*design.AttributeDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if parent.Validation == nil {
+		parent.Validation = &dslengine.ValidationDefinition{}
+	}
+	parent.Validation.AddRequired([]string{name})
+	if parent.Metadata == nil {
+		parent.Metadata = make(dslengine.MetadataDefinition)
+	}
+	parent.Metadata["type:discriminator"] = []string{name}
+}
+
+// FieldNaming can be used in: API, Type, MediaType
+//
+// FieldNaming sets the casing policy applied to the JSON names of the fields of the generated
+// structs, one of "camel", "snake" or "kebab". The policy set on a type or media type applies to
+// the type fields and overrides the policy set on the API which applies to all the types.
+// Acronyms are handled as words so that an attribute named "userID" is rendered as "userId",
+// "user_id" or "user-id". A "struct:tag:json" metadata set on an attribute overrides the policy:
+//
+//	var Account = Type("Account", func() {
+//		FieldNaming("camel")
+//		Attribute("user_id", String)          // Rendered as "userId"
+//		Attribute("org_id", String, func() {
+//			Metadata("struct:tag:json", "org") // Rendered as "org"
+//		})
+//	})
+//
+// FieldNaming sets the "json:naming" metadata of the definition.
+func FieldNaming(policy string) {
+	setNaming := func(metadata dslengine.MetadataDefinition) dslengine.MetadataDefinition {
+		if metadata == nil {
+			metadata = make(dslengine.MetadataDefinition)
+		}
+		metadata["json:naming"] = []string{policy}
+		return metadata
+	}
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.Metadata = setNaming(def.Metadata)
+	case *design.MediaTypeDefinition:
+		def.Metadata = setNaming(def.Metadata)
+	case *design.AttributeDefinition:
+		def.Metadata = setNaming(def.Metadata)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
 // ArrayOf creates an array type from its element type. The result can be used
 // anywhere a type can. Examples:
 //
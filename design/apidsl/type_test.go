@@ -289,3 +289,66 @@ var _ = Describe("HashOf", func() {
 		})
 	})
 })
+
+var _ = Describe("Extend", func() {
+	var animal, dog *UserTypeDefinition
+	var dogDSL func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dogDSL = func() {
+			Extend(animal)
+			Attribute("barks", Boolean)
+		}
+	})
+
+	JustBeforeEach(func() {
+		dog = Type("Dog", func() { dogDSL() })
+		animal = Type("Animal", func() {
+			Attribute("kind", String, func() {
+				Enum("Dog", "Cat")
+			})
+			Attribute("name", String)
+			Required("name")
+			Discriminator("kind")
+		})
+		dslengine.Run()
+	})
+
+	It("copies the base type attributes", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		obj := dog.ToObject()
+		Ω(obj).Should(HaveKey("kind"))
+		Ω(obj).Should(HaveKey("name"))
+		Ω(obj).Should(HaveKey("barks"))
+		Ω(dog.Validation.Required).Should(ConsistOf("kind", "name"))
+	})
+
+	It("records the base type", func() {
+		Ω(dog.Base()).Should(Equal(animal))
+		Ω(animal.Variants()).Should(ConsistOf(dog))
+		Ω(animal.Discriminator()).Should(Equal("kind"))
+	})
+
+	It("restricts the discriminator values to the type name", func() {
+		Ω(dog.ToObject()["kind"].Validation.Values).Should(Equal([]interface{}{"Dog"}))
+		Ω(animal.ToObject()["kind"].Validation.Values).Should(Equal([]interface{}{"Dog", "Cat"}))
+	})
+
+	Context("with an attribute overriding a base type attribute", func() {
+		BeforeEach(func() {
+			dogDSL = func() {
+				Extend(animal)
+				Attribute("name", String, func() {
+					MinLength(2)
+				})
+			}
+		})
+
+		It("keeps the type attribute", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(dog.ToObject()["name"].Validation).ShouldNot(BeNil())
+			Ω(*dog.ToObject()["name"].Validation.MinLength).Should(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,350 @@
+package design
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// changelogEntry is a single change listed in the changelog produced by GenerateChangelog.
+type changelogEntry struct {
+	// Breaking is true if the change may break existing clients.
+	Breaking bool
+	// Description describes the change in Markdown.
+	Description string
+}
+
+// GenerateChangelog compares the old and new versions of an API design and returns a Markdown
+// document that lists the changes grouped by resource. Each change is marked as breaking if it may
+// break the existing clients of the API. The changelog lists the added and removed resources and
+// actions, the changed routes, the added, removed and changed request payload and parameter fields,
+// the added, removed and changed response media type fields and the changed error responses.
+//
+// Removing an action, a route, a response field or a successful response, changing the type of a
+// field and adding a required request field or requiring an existing one are breaking changes.
+func GenerateChangelog(old, new *APIDefinition) ([]byte, error) {
+	if old == nil || new == nil {
+		return nil, fmt.Errorf("missing API definition, both the old and new designs are required")
+	}
+	names := make(map[string]bool)
+	for n := range old.Resources {
+		names[n] = true
+	}
+	for n := range new.Resources {
+		names[n] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	var (
+		sections             = make(map[string][]*changelogEntry)
+		breaking, compatible int
+	)
+	for _, n := range sorted {
+		entries := diffResources(old, new, old.Resources[n], new.Resources[n])
+		for _, e := range entries {
+			if e.Breaking {
+				breaking++
+			} else {
+				compatible++
+			}
+		}
+		sections[n] = entries
+	}
+
+	var buf bytes.Buffer
+	name := new.Name
+	if name == "" {
+		name = old.Name
+	}
+	fmt.Fprintf(&buf, "# %s API changelog\n\n", name)
+	if breaking+compatible == 0 {
+		buf.WriteString("No changes.\n")
+		return buf.Bytes(), nil
+	}
+	fmt.Fprintf(&buf, "%d breaking change(s), %d non-breaking change(s).\n", breaking, compatible)
+	for _, n := range sorted {
+		entries := sections[n]
+		if len(entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\n## %s\n\n", n)
+		for _, e := range entries {
+			if e.Breaking {
+				fmt.Fprintf(&buf, "- **Breaking:** %s\n", e.Description)
+			} else {
+				fmt.Fprintf(&buf, "- %s\n", e.Description)
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// diffResources returns the changes between the old and new versions of a resource, either may be
+// nil if the resource was added or removed.
+func diffResources(oldAPI, newAPI *APIDefinition, old, new *ResourceDefinition) []*changelogEntry {
+	if old == nil {
+		return []*changelogEntry{{Description: "Added resource."}}
+	}
+	if new == nil {
+		return []*changelogEntry{{Breaking: true, Description: "Removed resource."}}
+	}
+	var entries []*changelogEntry
+	names := make(map[string]bool)
+	for n := range old.Actions {
+		names[n] = true
+	}
+	for n := range new.Actions {
+		names[n] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+	for _, n := range sorted {
+		oa, na := old.Actions[n], new.Actions[n]
+		switch {
+		case oa == nil:
+			entries = append(entries, &changelogEntry{Description: fmt.Sprintf("Added action `%s` (%s).", n, routesDesc(na))})
+		case na == nil:
+			entries = append(entries, &changelogEntry{Breaking: true, Description: fmt.Sprintf("Removed action `%s` (%s).", n, routesDesc(oa))})
+		default:
+			entries = append(entries, diffActions(oldAPI, newAPI, oa, na)...)
+		}
+	}
+	return entries
+}
+
+// diffActions returns the changes between the old and new versions of an action.
+func diffActions(oldAPI, newAPI *APIDefinition, old, new *ActionDefinition) []*changelogEntry {
+	var entries []*changelogEntry
+	add := func(breaking bool, format string, args ...interface{}) {
+		desc := fmt.Sprintf("`%s`: %s.", new.Name, fmt.Sprintf(format, args...))
+		entries = append(entries, &changelogEntry{Breaking: breaking, Description: desc})
+	}
+
+	oldRoutes, newRoutes := routeSet(old), routeSet(new)
+	for _, r := range sortedKeys(oldRoutes) {
+		if !newRoutes[r] {
+			add(true, "removed route `%s`", r)
+		}
+	}
+	for _, r := range sortedKeys(newRoutes) {
+		if !oldRoutes[r] {
+			add(false, "added route `%s`", r)
+		}
+	}
+
+	var op, np *AttributeDefinition
+	if old.Payload != nil {
+		op = old.Payload.AttributeDefinition
+	}
+	if new.Payload != nil {
+		np = new.Payload.AttributeDefinition
+	}
+	for _, c := range diffRequestFields("request", op, np, "") {
+		add(c.Breaking, "%s", c.Description)
+	}
+	for _, c := range diffRequestFields("parameter", old.Params, new.Params, "") {
+		add(c.Breaking, "%s", c.Description)
+	}
+
+	statuses := make(map[int]bool)
+	oldResps, newResps := responsesByStatus(old), responsesByStatus(new)
+	for s := range oldResps {
+		statuses[s] = true
+	}
+	for s := range newResps {
+		statuses[s] = true
+	}
+	sortedStatuses := make([]int, 0, len(statuses))
+	for s := range statuses {
+		sortedStatuses = append(sortedStatuses, s)
+	}
+	sort.Ints(sortedStatuses)
+	for _, s := range sortedStatuses {
+		or, nr := oldResps[s], newResps[s]
+		isError := s >= 400
+		switch {
+		case or == nil && isError:
+			add(false, "added error response %d (%s)", s, nr.Name)
+		case or == nil:
+			add(false, "added response %d (%s)", s, nr.Name)
+		case nr == nil && isError:
+			add(false, "removed error response %d (%s)", s, or.Name)
+		case nr == nil:
+			add(true, "removed response %d (%s)", s, or.Name)
+		default:
+			for _, c := range diffResponseFields(responseAttribute(oldAPI, or), responseAttribute(newAPI, nr), "") {
+				add(c.Breaking, "%s of response %d", c.Description, s)
+			}
+		}
+	}
+	return entries
+}
+
+// diffRequestFields returns the changes between the fields of the old and new versions of a
+// request payload or of the request parameters. kind is used to describe the fields, prefix is
+// the path to the fields for nested objects.
+func diffRequestFields(kind string, old, new *AttributeDefinition, prefix string) []*changelogEntry {
+	oo, no := objectOf(old), objectOf(new)
+	if oo == nil && no == nil {
+		return nil
+	}
+	var entries []*changelogEntry
+	add := func(breaking bool, format string, args ...interface{}) {
+		entries = append(entries, &changelogEntry{Breaking: breaking, Description: fmt.Sprintf(format, args...)})
+	}
+	for _, n := range sortedFields(oo, no) {
+		path := prefix + n
+		oa, na := oo[n], no[n]
+		switch {
+		case oa == nil:
+			if new.IsRequired(n) {
+				add(true, "added required %s field `%s`", kind, path)
+			} else {
+				add(false, "added optional %s field `%s`", kind, path)
+			}
+		case na == nil:
+			add(true, "removed %s field `%s`", kind, path)
+		default:
+			ot, nt := changelogTypeName(oa.Type), changelogTypeName(na.Type)
+			if ot != nt {
+				add(true, "changed type of %s field `%s` from %s to %s", kind, path, ot, nt)
+				continue
+			}
+			if !old.IsRequired(n) && new.IsRequired(n) {
+				add(true, "%s field `%s` is now required", kind, path)
+			} else if old.IsRequired(n) && !new.IsRequired(n) {
+				add(false, "%s field `%s` is now optional", kind, path)
+			}
+			entries = append(entries, diffRequestFields(kind, oa, na, path+".")...)
+		}
+	}
+	return entries
+}
+
+// diffResponseFields returns the changes between the fields of the old and new versions of a
+// response media type. prefix is the path to the fields for nested objects.
+func diffResponseFields(old, new *AttributeDefinition, prefix string) []*changelogEntry {
+	oo, no := objectOf(old), objectOf(new)
+	if oo == nil && no == nil {
+		return nil
+	}
+	var entries []*changelogEntry
+	add := func(breaking bool, format string, args ...interface{}) {
+		entries = append(entries, &changelogEntry{Breaking: breaking, Description: fmt.Sprintf(format, args...)})
+	}
+	for _, n := range sortedFields(oo, no) {
+		path := prefix + n
+		oa, na := oo[n], no[n]
+		switch {
+		case oa == nil:
+			add(false, "added field `%s`", path)
+		case na == nil:
+			add(true, "removed field `%s`", path)
+		default:
+			ot, nt := changelogTypeName(oa.Type), changelogTypeName(na.Type)
+			if ot != nt {
+				add(true, "changed type of field `%s` from %s to %s", path, ot, nt)
+				continue
+			}
+			entries = append(entries, diffResponseFields(oa, na, path+".")...)
+		}
+	}
+	return entries
+}
+
+// responseAttribute returns the attribute that describes the body of the given response using the
+// response view, nil if the response has no media type.
+func responseAttribute(api *APIDefinition, r *ResponseDefinition) *AttributeDefinition {
+	mt, ok := r.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = api.MediaTypeWithIdentifier(r.MediaType)
+	}
+	if mt == nil {
+		if r.Type != nil {
+			return &AttributeDefinition{Type: r.Type}
+		}
+		return nil
+	}
+	view := r.ViewName
+	if view == "" {
+		view = "default"
+	}
+	if v, ok := mt.Views[view]; ok {
+		return v.AttributeDefinition
+	}
+	return mt.AttributeDefinition
+}
+
+// responsesByStatus indexes the responses of the action by status code.
+func responsesByStatus(a *ActionDefinition) map[int]*ResponseDefinition {
+	resps := make(map[int]*ResponseDefinition, len(a.Responses))
+	for _, r := range a.Responses {
+		resps[r.Status] = r
+	}
+	return resps
+}
+
+// routeSet returns the routes of the action formatted as "VERB /path".
+func routeSet(a *ActionDefinition) map[string]bool {
+	routes := make(map[string]bool, len(a.Routes))
+	for _, r := range a.Routes {
+		routes[r.Verb+" "+r.FullPath()] = true
+	}
+	return routes
+}
+
+// routesDesc describes the routes of the action.
+func routesDesc(a *ActionDefinition) string {
+	return strings.Join(sortedKeys(routeSet(a)), ", ")
+}
+
+// objectOf returns the object type of the attribute, nil if the attribute is nil or not an object.
+func objectOf(att *AttributeDefinition) Object {
+	if att == nil || att.Type == nil {
+		return nil
+	}
+	return att.Type.ToObject()
+}
+
+// sortedFields returns the sorted names of the fields of both objects.
+func sortedFields(o1, o2 Object) []string {
+	names := make(map[string]bool)
+	for n := range o1 {
+		names[n] = true
+	}
+	for n := range o2 {
+		names[n] = true
+	}
+	return sortedKeys(names)
+}
+
+// sortedKeys returns the sorted keys of the map.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// changelogTypeName returns the name used to compare the types of two fields.
+func changelogTypeName(t DataType) string {
+	switch actual := t.(type) {
+	case *Array:
+		return fmt.Sprintf("array<%s>", changelogTypeName(actual.ElemType.Type))
+	case *Hash:
+		return fmt.Sprintf("hash<%s, %s>", changelogTypeName(actual.KeyType.Type), changelogTypeName(actual.ElemType.Type))
+	case *UserTypeDefinition, *MediaTypeDefinition, Object:
+		return "object"
+	}
+	return t.Name()
+}
@@ -0,0 +1,93 @@
+package design_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateChangelog", func() {
+	var old, new *APIDefinition
+	var changelog string
+	var err error
+
+	newAPI := func(bottle Object) *APIDefinition {
+		mt := &MediaTypeDefinition{
+			UserTypeDefinition: &UserTypeDefinition{
+				AttributeDefinition: &AttributeDefinition{Type: bottle},
+				TypeName:            "Bottle",
+			},
+			Identifier: "application/vnd.bottle",
+		}
+		api := &APIDefinition{
+			Name:       "cellar",
+			MediaTypes: map[string]*MediaTypeDefinition{mt.Identifier: mt},
+		}
+		res := &ResourceDefinition{Name: "bottle"}
+		show := &ActionDefinition{
+			Name:   "show",
+			Parent: res,
+			Responses: map[string]*ResponseDefinition{
+				"OK":       {Name: "OK", Status: 200, MediaType: mt.Identifier},
+				"NotFound": {Name: "NotFound", Status: 404},
+			},
+		}
+		show.Routes = []*RouteDefinition{{Verb: "GET", Path: "/bottles/:id", Parent: show}}
+		res.Actions = map[string]*ActionDefinition{"show": show}
+		api.Resources = map[string]*ResourceDefinition{"bottle": res}
+		return api
+	}
+
+	BeforeEach(func() {
+		old = newAPI(Object{"name": {Type: String}, "vintage": {Type: Integer}})
+		new = newAPI(Object{"name": {Type: String}, "vintage": {Type: Integer}})
+	})
+
+	JustBeforeEach(func() {
+		var b []byte
+		b, err = GenerateChangelog(old, new)
+		changelog = string(b)
+	})
+
+	It("reports that there are no changes", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(changelog).Should(Equal("# cellar API changelog\n\nNo changes.\n"))
+	})
+
+	Context("with a removed response field", func() {
+		BeforeEach(func() {
+			new = newAPI(Object{"name": {Type: String}, "rating": {Type: Integer}})
+		})
+
+		It("lists the removed field as a breaking change", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(changelog).Should(Equal("# cellar API changelog\n\n" +
+				"1 breaking change(s), 1 non-breaking change(s).\n\n" +
+				"## bottle\n\n" +
+				"- `show`: added field `rating` of response 200.\n" +
+				"- **Breaking:** `show`: removed field `vintage` of response 200.\n"))
+		})
+	})
+
+	Context("with a removed error response", func() {
+		BeforeEach(func() {
+			delete(new.Resources["bottle"].Actions["show"].Responses, "NotFound")
+		})
+
+		It("lists it as a non-breaking change", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(changelog).Should(ContainSubstring("- `show`: removed error response 404 (NotFound).\n"))
+		})
+	})
+
+	Context("with a removed action", func() {
+		BeforeEach(func() {
+			delete(new.Resources["bottle"].Actions, "show")
+		})
+
+		It("lists it as a breaking change", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(changelog).Should(ContainSubstring("- **Breaking:** Removed action `show` (GET /bottles/:id).\n"))
+		})
+	})
+})
@@ -3,10 +3,15 @@ package design
 import (
 	"errors"
 	"fmt"
+	"math"
+	"mime"
 	"net/http"
 	"path"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -23,6 +28,9 @@ type (
 		Description string
 		// Version is the version of the API described by this design.
 		Version string
+		// VersionMedia is the pattern of the media types that select the resource versions in
+		// the request Accept header, "{version}" stands for the version.
+		VersionMedia string
 		// Host is the default API hostname
 		Host string
 		// Schemes is the supported API URL schemes
@@ -74,6 +82,18 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// ProblemTypeBase is the base URI of the problem types used to render error responses
+		// as problem details (RFC 7807), error responses use the ErrorMedia media type if empty.
+		ProblemTypeBase string
+		// DefaultResponse describes the response used to render the errors that are not
+		// mapped to a response and the panics of the API actions if any, see CatchAllResponse.
+		DefaultResponse *ResponseDefinition
+		// Envelope describes the envelope that wraps the request and response bodies of the
+		// API actions if any.
+		Envelope *EnvelopeDefinition
+		// RequestIDHeader is the name of the header that carries the request ID, see the
+		// RequestID DSL.
+		RequestIDHeader string
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
@@ -115,6 +135,9 @@ type (
 		Schemes []string
 		// Common URL prefix to all resource action HTTP requests
 		BasePath string
+		// Version is the version of the resource selected with the API VersionMedia, the
+		// resources that share the same base path define the versions of the same endpoints.
+		Version string
 		// Path and query string parameters that apply to all actions.
 		Params *AttributeDefinition
 		// Name of parent resource if any
@@ -125,6 +148,16 @@ type (
 		MediaType string
 		// Default view name if default media type is MediaTypeDefinition
 		DefaultViewName string
+		// Media type used to render the error responses of the resource actions, the built-in
+		// ErrorMedia media type if empty.
+		ErrorMediaType string
+		// DefaultResponse describes the response used to render the errors that are not
+		// mapped to a response and the panics of the resource actions if any, overrides the
+		// API default response.
+		DefaultResponse *ResponseDefinition
+		// Envelope describes the envelope that wraps the request and response bodies of the
+		// resource actions if any, overrides the API envelope.
+		Envelope *EnvelopeDefinition
 		// Exposed resource actions indexed by name
 		Actions map[string]*ActionDefinition
 		// FileServers is the list of static asset serving endpoints
@@ -146,6 +179,16 @@ type (
 		Security *SecurityDefinition
 	}
 
+	// EnvelopeDefinition describes the envelope that wraps request and response bodies, e.g.
+	// {"data": {...}, "meta": {...}}.
+	EnvelopeDefinition struct {
+		// DataField is the name of the envelope field that contains the body.
+		DataField string
+		// MetaField is the name of the envelope field that contains the meta object, the
+		// envelope has no meta object if empty.
+		MetaField string
+	}
+
 	// CORSDefinition contains the definition for a specific origin CORS policy.
 	CORSDefinition struct {
 		// Parent API or resource
@@ -204,6 +247,26 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Standard is true if the response definition comes from the goa default responses
 		Standard bool
+		// Cookies lists the cookies set by the response from the values of the response media
+		// type attributes.
+		Cookies []*CookieDefinition
+	}
+
+	// CookieDefinition defines a cookie set by a response. The value of the cookie is the value
+	// of the response media type attribute with the same name.
+	CookieDefinition struct {
+		// Name of the cookie and of the attribute that holds its value
+		Name string
+		// MaxAge is the number of seconds until the cookie expires, 0 if not set
+		MaxAge int
+		// HTTPOnly is true if the cookie is not accessible to scripts
+		HTTPOnly bool
+		// Secure is true if the cookie is only sent over HTTPS
+		Secure bool
+		// SameSite is the value of the SameSite attribute: "Strict", "Lax", "None" or empty
+		SameSite string
+		// Parent response
+		Parent *ResponseDefinition
 	}
 
 	// ResponseTemplateDefinition defines a response template.
@@ -253,6 +316,20 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// Events lists the domain events emitted by the action when it succeeds.
+		Events []*EventDefinition
+	}
+
+	// EventDefinition describes a domain event emitted by an action, see the Emits DSL.
+	EventDefinition struct {
+		// Name is the event name, e.g. "BottleCreated"
+		Name string
+		// Type is the event payload type, nil if TypeName does not refer to a declared type.
+		Type DataType
+		// TypeName is the name of the event payload type as given to the Emits DSL if any.
+		TypeName string
+		// Parent is the action emitting the event.
+		Parent *ActionDefinition
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -333,10 +410,63 @@ type (
 		// NonZeroAttributes lists the names of the child attributes that cannot have a
 		// zero value (and thus whose presence does not need to be validated).
 		NonZeroAttributes map[string]bool
+		// Transitions lists the allowed transitions between the values of a string enum
+		// attribute defined with the Transitions DSL, nil if the attribute does not define
+		// any.
+		Transitions []*TransitionDefinition
+		// Flags lists the flags of a flag set attribute defined with the FlagSet DSL, nil if
+		// the attribute is not a flag set.
+		Flags []*FlagDefinition
 		// DSLFunc contains the initialization DSL. This is used for user types.
 		DSLFunc func()
 	}
 
+	// TransitionDefinition lists the values an enum attribute may transition to from a given
+	// value.
+	TransitionDefinition struct {
+		// From is the value the transitions start from.
+		From string
+		// Targets lists the values the attribute may transition to.
+		Targets []string
+	}
+
+	// SortFieldDefinition describes a field that may be used to sort the results of a list
+	// action and its default sort options as defined by the Sortable and SortableField DSLs.
+	SortFieldDefinition struct {
+		// Name is the name of the field.
+		Name string
+		// Descending is true if the results are sorted in descending order by default.
+		Descending bool
+		// Nulls is the placement of null values, "first", "last" or the empty string if
+		// unspecified.
+		Nulls string
+	}
+
+	// ExampleDefinition contains the options of an attribute example set with the Example DSL.
+	ExampleDefinition struct {
+		// Parent is the attribute the example is set on.
+		Parent *AttributeDefinition
+		// SkipValidation is true if the example does not have to pass the attribute
+		// validations.
+		SkipValidation bool
+	}
+
+	// FlagDefinition is a flag of a flag set attribute defined with the FlagSet DSL.
+	FlagDefinition struct {
+		// Name is the name of the flag.
+		Name string
+		// Value is the bit of the flag.
+		Value int
+	}
+
+	// EnumValue is a named value of an integer enum defined with the EnumValues DSL.
+	EnumValue struct {
+		// Name is the name of the value.
+		Name string
+		// Value is the integer value.
+		Value int
+	}
+
 	// ContainerDefinition defines a generic container definition that contains attributes.
 	// This makes it possible for plugins to use attributes in their own data structures.
 	ContainerDefinition interface {
@@ -651,6 +781,261 @@ func (a *APIDefinition) IterateResources(it ResourceIterator) error {
 	return nil
 }
 
+// SortedResources returns the API resources sorted in alphabetical order. Contrary to
+// IterateResources the order does not depend on the relationships between resources so that it
+// can be used by generators that produce files aggregating all the resources to keep their
+// output stable when the design is reorganized.
+func (a *APIDefinition) SortedResources() []*ResourceDefinition {
+	names := make([]string, 0, len(a.Resources))
+	for n := range a.Resources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	res := make([]*ResourceDefinition, len(names))
+	for i, n := range names {
+		res[i] = a.Resources[n]
+	}
+	return res
+}
+
+// ContentTypes returns the sorted and deduplicated content types of the requests the API accepts and
+// of the responses it sends. consumes lists the MIME types of the API decoders and
+// multipart/form-data if an action accepts multipart payloads. produces lists the MIME types of the
+// API encoders and of the responses defined on the actions, media type parameters are stripped.
+// Both lists default to application/json if no content type is declared.
+func (a *APIDefinition) ContentTypes() (consumes, produces []string) {
+	cs := make(map[string]bool)
+	ps := make(map[string]bool)
+	for _, enc := range a.Consumes {
+		for _, mt := range enc.MIMETypes {
+			cs[mt] = true
+		}
+	}
+	for _, enc := range a.Produces {
+		for _, mt := range enc.MIMETypes {
+			ps[mt] = true
+		}
+	}
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			if ac.PayloadMultipart {
+				cs["multipart/form-data"] = true
+			}
+			return ac.IterateResponses(func(resp *ResponseDefinition) error {
+				mt := resp.MediaType
+				if mt == "" {
+					return nil
+				}
+				if mt == ErrorMediaIdentifier && a.ProblemTypeBase != "" && r.ErrorMedia() == nil {
+					mt = ProblemDetailsMediaIdentifier
+				}
+				if ac.StreamsJSON() && resp.Status < 300 {
+					mt = "application/x-ndjson"
+				}
+				if base, _, err := mime.ParseMediaType(mt); err == nil {
+					mt = base
+				}
+				ps[mt] = true
+				return nil
+			})
+		})
+	})
+	sorted := func(set map[string]bool) []string {
+		if len(set) == 0 {
+			return []string{"application/json"}
+		}
+		res := make([]string, 0, len(set))
+		for mt := range set {
+			res = append(res, mt)
+		}
+		sort.Strings(res)
+		return res
+	}
+	return sorted(cs), sorted(ps)
+}
+
+// NamedEnums returns the attributes that define integer enums with named values indexed by the
+// name of the Go type generated for the enum. The first attribute visited by WalkAttributes is
+// returned for each name.
+func (a *APIDefinition) NamedEnums() map[string]*AttributeDefinition {
+	enums := make(map[string]*AttributeDefinition)
+	a.WalkAttributes(func(_ string, att *AttributeDefinition) error {
+		if name := att.EnumTypeName(); name != "" {
+			if _, ok := enums[name]; !ok {
+				enums[name] = att
+			}
+		}
+		return nil
+	})
+	return enums
+}
+
+// FlagSets returns the attributes that define flag sets indexed by the name of the Go type
+// generated for the flag set. The first attribute visited by WalkAttributes is returned for each
+// name.
+func (a *APIDefinition) FlagSets() map[string]*AttributeDefinition {
+	sets := make(map[string]*AttributeDefinition)
+	a.WalkAttributes(func(_ string, att *AttributeDefinition) error {
+		if name := att.FlagSetTypeName(); name != "" {
+			if _, ok := sets[name]; !ok {
+				sets[name] = att
+			}
+		}
+		return nil
+	})
+	return sets
+}
+
+// ErrorNames returns the sorted names of the error responses, that is responses with a status of
+// 400 or more, defined on the API, its resources and its actions. Names defined at multiple levels
+// are only listed once, use ResolveError to retrieve the response that applies to a given scope.
+func (a *APIDefinition) ErrorNames() []string {
+	seen := make(map[string]bool)
+	collect := func(responses map[string]*ResponseDefinition) {
+		for n, resp := range responses {
+			if resp.Status >= 400 {
+				seen[n] = true
+			}
+		}
+	}
+	collect(a.Responses)
+	a.IterateResources(func(r *ResourceDefinition) error {
+		collect(r.Responses)
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			collect(ac.Responses)
+			return nil
+		})
+	})
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveError returns the error response with the given name that applies to the given scope, nil
+// if there isn't one. scope is empty for the API, the name of a resource or the name of a resource
+// and of one of its actions separated with a dot, e.g. "bottle.show". The response defined in the
+// closest scope shadows the others: action responses take precedence over resource responses which
+// take precedence over API responses which take precedence over the built-in responses. ResolveError
+// returns nil if the closest response with the given name is not an error response.
+func (a *APIDefinition) ResolveError(scope, name string) *ResponseDefinition {
+	var candidates []map[string]*ResponseDefinition
+	if scope != "" {
+		elems := strings.SplitN(scope, ".", 2)
+		r, ok := a.Resources[elems[0]]
+		if !ok {
+			return nil
+		}
+		if len(elems) > 1 {
+			ac, ok := r.Actions[elems[1]]
+			if !ok {
+				return nil
+			}
+			candidates = append(candidates, ac.Responses)
+		}
+		candidates = append(candidates, r.Responses)
+	}
+	candidates = append(candidates, a.Responses, a.DefaultResponses)
+	for _, responses := range candidates {
+		if resp, ok := responses[name]; ok {
+			if resp.Status < 400 {
+				return nil
+			}
+			return resp
+		}
+	}
+	return nil
+}
+
+// WalkAttributes calls the given function once on each attribute of the design: the attributes of
+// the user types and media types followed by the parameters, headers, payloads and responses of
+// the API resources and actions. The path given to the function locates the attribute in the
+// design, for example "Bottle.vintage" or "bottle.show.params.id". The elements of arrays are
+// located with "[]" and the keys and values of hashes with "[key]" and "[value]".
+// The attributes of a user type or media type are always located under the type name and are
+// visited only once no matter how many attributes refer to the type, this also prevents infinite
+// recursions with recursive types.
+// WalkAttributes stops if the function returns an error and in this case returns that error.
+func (a *APIDefinition) WalkAttributes(walker func(path string, att *AttributeDefinition) error) error {
+	seen := make(map[*UserTypeDefinition]bool)
+	walkAtt := func(path string, att *AttributeDefinition) error {
+		if att == nil {
+			return nil
+		}
+		return walkPath(path, att, walker, seen)
+	}
+	walkResponses := func(path string, responses map[string]*ResponseDefinition) error {
+		names := make([]string, 0, len(responses))
+		for n := range responses {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			r := responses[n]
+			if r.Type != nil {
+				if err := walkTypePath(path+".responses."+n, r.Type, walker, seen); err != nil {
+					return err
+				}
+			} else if r.MediaType != "" {
+				if mt := a.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+					if err := walkUserType(mt.UserTypeDefinition, walker, seen); err != nil {
+						return err
+					}
+				}
+			}
+			if err := walkAtt(path+".responses."+n+".headers", r.Headers); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := a.IterateUserTypes(func(u *UserTypeDefinition) error {
+		return walkUserType(u, walker, seen)
+	}); err != nil {
+		return err
+	}
+	if err := a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		return walkUserType(mt.UserTypeDefinition, walker, seen)
+	}); err != nil {
+		return err
+	}
+	if err := walkAtt(a.Name+".params", a.Params); err != nil {
+		return err
+	}
+	if err := walkResponses(a.Name, a.Responses); err != nil {
+		return err
+	}
+	return a.IterateResources(func(r *ResourceDefinition) error {
+		if err := walkAtt(r.Name+".params", r.Params); err != nil {
+			return err
+		}
+		if err := walkAtt(r.Name+".headers", r.Headers); err != nil {
+			return err
+		}
+		if err := walkResponses(r.Name, r.Responses); err != nil {
+			return err
+		}
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			path := r.Name + "." + ac.Name
+			if err := walkAtt(path+".params", ac.Params); err != nil {
+				return err
+			}
+			if err := walkAtt(path+".headers", ac.Headers); err != nil {
+				return err
+			}
+			if ac.Payload != nil {
+				if err := walkUserType(ac.Payload, walker, seen); err != nil {
+					return err
+				}
+			}
+			return walkResponses(path, ac.Responses)
+		})
+	})
+}
+
 // DSL returns the initialization DSL.
 func (a *APIDefinition) DSL() func() {
 	return a.DSLFunc
@@ -846,6 +1231,24 @@ func (r *ResourceDefinition) AllOrigins() []*CORSDefinition {
 	return cors
 }
 
+// IsVersioned returns true if the resource or another resource with the same base path defines a
+// version. The requests sent to versioned resources are dispatched according to the API
+// VersionMedia.
+func (r *ResourceDefinition) IsVersioned() bool {
+	if r.Version != "" {
+		return true
+	}
+	if Design == nil {
+		return false
+	}
+	for _, other := range Design.Resources {
+		if other.Version != "" && other.FullPath() == r.FullPath() {
+			return true
+		}
+	}
+	return false
+}
+
 // PreflightPaths returns the paths that should handle OPTIONS requests.
 func (r *ResourceDefinition) PreflightPaths() []string {
 	var paths []string
@@ -894,6 +1297,7 @@ func (r *ResourceDefinition) DSL() func() {
 // parameters, initializes querystring parameters, sets path parameters as non zero attributes
 // and sets the fallbacks for security schemes.
 func (r *ResourceDefinition) Finalize() {
+	r.addBatchActions()
 	meta := r.Metadata["swagger:generate"]
 	r.IterateFileServers(func(f *FileServerDefinition) error {
 		if meta != nil {
@@ -915,6 +1319,80 @@ func (r *ResourceDefinition) Finalize() {
 	})
 }
 
+// addBatchActions creates the batch actions derived from the resource actions that use the Batch
+// DSL.
+func (r *ResourceDefinition) addBatchActions() {
+	r.IterateActions(func(a *ActionDefinition) error {
+		name := a.BatchActionName()
+		if name == "" || a.Payload == nil {
+			return nil
+		}
+		if _, ok := r.Actions[name]; ok {
+			return nil
+		}
+		var params, headers *AttributeDefinition
+		if a.Params != nil {
+			params = DupAtt(a.Params)
+		}
+		if a.Headers != nil {
+			headers = DupAtt(a.Headers)
+		}
+		batch := &ActionDefinition{
+			Name:        name,
+			Description: fmt.Sprintf("Batch version of the %s action, the results of the items are listed in order.", a.Name),
+			Parent:      r,
+			Schemes:     a.Schemes,
+			Params:      params,
+			Headers:     headers,
+			Security:    a.Security,
+			Payload: &UserTypeDefinition{
+				AttributeDefinition: &AttributeDefinition{
+					Type: &Array{ElemType: &AttributeDefinition{Type: a.Payload}},
+				},
+				TypeName: "Batch" + a.Payload.TypeName,
+			},
+			Metadata: dslengine.MetadataDefinition{"batch:source": []string{a.Name}},
+		}
+		for _, route := range a.Routes {
+			batch.Routes = append(batch.Routes, &RouteDefinition{
+				Verb:   "POST",
+				Path:   strings.TrimSuffix(route.Path, "/") + "/batch",
+				Parent: batch,
+			})
+		}
+		batch.Responses = map[string]*ResponseDefinition{
+			OK: {
+				Name:      OK,
+				Status:    200,
+				MediaType: BatchResultsMedia.Identifier,
+				Type:      BatchResultsMedia,
+				Parent:    batch,
+			},
+		}
+		r.Actions[name] = batch
+		return nil
+	})
+}
+
+// ErrorMedia returns the media type used to render the resource error responses if set via
+// DefaultErrorResponse, nil otherwise.
+func (r *ResourceDefinition) ErrorMedia() *MediaTypeDefinition {
+	if r.ErrorMediaType == "" {
+		return nil
+	}
+	return Design.MediaTypeWithIdentifier(r.ErrorMediaType)
+}
+
+// CatchAllResponse returns the response used to render the errors returned by the resource
+// actions that are not goa.ServiceError values and the panics of the action handlers as defined by
+// the DefaultResponse DSL, nil if the resource and the API do not define one.
+func (r *ResourceDefinition) CatchAllResponse() *ResponseDefinition {
+	if r.DefaultResponse != nil {
+		return r.DefaultResponse
+	}
+	return Design.DefaultResponse
+}
+
 // UserTypes returns all the user types used by the resource action payloads and parameters.
 func (r *ResourceDefinition) UserTypes() map[string]*UserTypeDefinition {
 	types := make(map[string]*UserTypeDefinition)
@@ -929,6 +1407,106 @@ func (r *ResourceDefinition) UserTypes() map[string]*UserTypeDefinition {
 	return types
 }
 
+// TypeClosure returns the user types and the media types used by the resource directly or
+// transitively: the types of its parameters, headers, payloads and responses, the media types of
+// its responses and the types of their attributes. The user types are indexed by name and the
+// media types by canonical identifier.
+func (r *ResourceDefinition) TypeClosure() (map[string]*UserTypeDefinition, map[string]*MediaTypeDefinition) {
+	types := make(map[string]*UserTypeDefinition)
+	mediaTypes := make(map[string]*MediaTypeDefinition)
+	var addType func(dt DataType)
+	addAtt := func(att *AttributeDefinition) {
+		if att != nil {
+			addType(att.Type)
+		}
+	}
+	addType = func(dt DataType) {
+		switch actual := dt.(type) {
+		case *MediaTypeDefinition:
+			id := CanonicalIdentifier(actual.Identifier)
+			if _, ok := mediaTypes[id]; ok {
+				return
+			}
+			mediaTypes[id] = actual
+			addAtt(actual.AttributeDefinition)
+		case *UserTypeDefinition:
+			if _, ok := types[actual.TypeName]; ok {
+				return
+			}
+			types[actual.TypeName] = actual
+			addAtt(actual.AttributeDefinition)
+		case *Array:
+			addAtt(actual.ElemType)
+		case *Hash:
+			addAtt(actual.KeyType)
+			addAtt(actual.ElemType)
+		case Object:
+			for _, att := range actual {
+				addAtt(att)
+			}
+		}
+	}
+	addMediaType := func(id string) {
+		if id == "" {
+			return
+		}
+		if mt := Design.MediaTypeWithIdentifier(id); mt != nil {
+			addType(mt)
+		}
+	}
+	addResponses := func(responses map[string]*ResponseDefinition) {
+		for _, resp := range responses {
+			if resp.Type != nil {
+				addType(resp.Type)
+			} else {
+				addMediaType(resp.MediaType)
+			}
+			addAtt(resp.Headers)
+		}
+	}
+
+	addMediaType(r.MediaType)
+	addMediaType(r.ErrorMediaType)
+	if resp := r.CatchAllResponse(); resp != nil {
+		addMediaType(resp.MediaType)
+	}
+	addAtt(r.Params)
+	addAtt(r.Headers)
+	addResponses(r.Responses)
+	for _, a := range r.Actions {
+		addAtt(a.Params)
+		addAtt(a.Headers)
+		if a.Payload != nil {
+			addType(a.Payload)
+		}
+		addResponses(a.Responses)
+	}
+	return types, mediaTypes
+}
+
+// Events returns the domain events emitted by the resource actions sorted by name.
+func (r *ResourceDefinition) Events() []*EventDefinition {
+	var events []*EventDefinition
+	for _, a := range r.Actions {
+		events = append(events, a.Events...)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	return events
+}
+
+// TerraformResource returns the name of the Terraform resource backed by r as defined with the
+// "terraform:resource" metadata, the empty string if none.
+func (r *ResourceDefinition) TerraformResource() string {
+	if n, ok := r.Metadata["terraform:resource"]; ok && len(n) > 0 {
+		return n[0]
+	}
+	return ""
+}
+
+// TerraformLifecycle lists the names of the actions that implement the create, read, update and
+// delete operations of Terraform resources in that order.
+var TerraformLifecycle = []string{"create", "show", "update", "delete"}
+
 // byParent makes it possible to sort resources - parents first the children.
 type byParent []*ResourceDefinition
 
@@ -996,7 +1574,7 @@ func (a *AttributeDefinition) HasDefaultValue(attName string) bool {
 }
 
 // SetDefault sets the default for the attribute. It also converts HashVal
-// and ArrayVal to map and slice respectively.
+// and ArrayVal as well as Go slices and maps of any type to map and slice respectively.
 func (a *AttributeDefinition) SetDefault(def interface{}) {
 	switch actual := def.(type) {
 	case HashVal:
@@ -1004,7 +1582,7 @@ func (a *AttributeDefinition) SetDefault(def interface{}) {
 	case ArrayVal:
 		a.DefaultValue = actual.ToSlice()
 	default:
-		a.DefaultValue = actual
+		a.DefaultValue = toGenericValue(actual)
 	}
 }
 
@@ -1131,6 +1709,19 @@ func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []stri
 		seen = append(seen, key)
 	}
 
+	if d := a.FakerDirective(); d != "" {
+		if fake := rand.Fake(d); fake != nil {
+			a.Example = fake
+			return a.Example
+		}
+	}
+
+	if len(a.Flags) > 0 {
+		// Random strings are not valid flag names
+		a.Example = a.FormatFlags(a.Flags[0].Value)
+		return a.Example
+	}
+
 	switch {
 	case a.Type.IsArray():
 		a.Example = a.arrayExample(rand, seen)
@@ -1164,14 +1755,416 @@ func (a *AttributeDefinition) IsReadOnly() bool {
 	return false
 }
 
+// FakerDirective returns the name of the directive used to generate the attribute examples as
+// defined by the Faker DSL, the empty string if none.
+func (a *AttributeDefinition) FakerDirective() string {
+	if v := a.Metadata["example:faker"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// ExampleSkipsValidation returns true if the example of the attribute does not have to pass the
+// attribute validations as defined by the SkipValidation DSL.
+func (a *AttributeDefinition) ExampleSkipsValidation() bool {
+	v := a.Metadata["example:skip-validation"]
+	return len(v) > 0 && v[0] == "true"
+}
+
+// SetNullable marks the attribute as nullable: it may be explicitly set to null in which case
+// the generated field records that it is present but null.
+func (a *AttributeDefinition) SetNullable() {
+	if a.Metadata == nil {
+		a.Metadata = map[string][]string{}
+	}
+	a.Metadata["struct:field:nullable"] = nil
+}
+
+// IsNullable returns true if attribute is nullable (set using SetNullable() method)
+func (a *AttributeDefinition) IsNullable() bool {
+	_, ok := a.Metadata["struct:field:nullable"]
+	return ok
+}
+
+// SetDeepObject marks the attribute as an object parameter whose fields are given in the query
+// string using the bracketed syntax, e.g. "filter[status]=active&filter[ids][]=1".
+func (a *AttributeDefinition) SetDeepObject() {
+	if a.Metadata == nil {
+		a.Metadata = map[string][]string{}
+	}
+	a.Metadata["param:style"] = []string{"deepObject"}
+}
+
+// IsDeepObject returns true if the attribute is an object parameter whose fields are given using
+// the bracketed query string syntax (set using SetDeepObject() method).
+func (a *AttributeDefinition) IsDeepObject() bool {
+	if v, ok := a.Metadata["param:style"]; ok && len(v) > 0 {
+		return v[0] == "deepObject"
+	}
+	return false
+}
+
+// SetArrayStyle sets the style used to give the values of an array parameter or header, one of
+// "multi", "csv", "ssv" or "pipes".
+func (a *AttributeDefinition) SetArrayStyle(style string) {
+	if a.Metadata == nil {
+		a.Metadata = map[string][]string{}
+	}
+	a.Metadata["param:style"] = []string{style}
+}
+
+// ArrayStyle returns the style used to give the values of an array parameter or header (set
+// using SetArrayStyle() method), the empty string if there is none.
+func (a *AttributeDefinition) ArrayStyle() string {
+	if v, ok := a.Metadata["param:style"]; ok && len(v) > 0 && v[0] != "deepObject" {
+		return v[0]
+	}
+	return ""
+}
+
+// SetDefaultFrom records the name of the sibling attribute whose value is used when the
+// attribute is not set.
+func (a *AttributeDefinition) SetDefaultFrom(source string) {
+	if a.Metadata == nil {
+		a.Metadata = map[string][]string{}
+	}
+	a.Metadata["struct:default:from"] = []string{source}
+}
+
+// DefaultFrom returns the name of the sibling attribute whose value is used when the attribute is
+// not set (set using SetDefaultFrom() method), the empty string if there isn't one.
+func (a *AttributeDefinition) DefaultFrom() string {
+	if v, ok := a.Metadata["struct:default:from"]; ok && len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// To adds the given values to the values the attribute may transition to, see the Transitions
+// DSL.
+func (t *TransitionDefinition) To(states ...string) {
+	t.Targets = append(t.Targets, states...)
+}
+
+// NamedEnumValues returns the values of integer enums defined with the EnumValues DSL in the order
+// they were declared, nil if the attribute values are not named.
+func (a *AttributeDefinition) NamedEnumValues() []*EnumValue {
+	names, ok := a.Metadata["enum:names"]
+	if !ok || a.Validation == nil {
+		return nil
+	}
+	values := make([]*EnumValue, 0, len(names))
+	for i, n := range names {
+		if i >= len(a.Validation.Values) {
+			break
+		}
+		v, ok := a.Validation.Values[i].(int)
+		if !ok {
+			break
+		}
+		values = append(values, &EnumValue{Name: n, Value: v})
+	}
+	return values
+}
+
+// EnumTypeName returns the name of the Go type generated for the integer enum with named values
+// defined by the attribute, the empty string if the attribute values are not named.
+func (a *AttributeDefinition) EnumTypeName() string {
+	if _, ok := a.Metadata["enum:names"]; !ok {
+		return ""
+	}
+	if v, ok := a.Metadata["enum:type"]; ok && len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// FlagSetTypeName returns the name of the Go type generated for the flag set defined by the
+// attribute, the empty string if the attribute is not a flag set.
+func (a *AttributeDefinition) FlagSetTypeName() string {
+	if a.Flags == nil {
+		return ""
+	}
+	if v, ok := a.Metadata["flags:type"]; ok && len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// ParseFlags returns the bitmask of the flags of the flag set defined by the attribute named in
+// the given comma separated list.
+func (a *AttributeDefinition) ParseFlags(s string) (int, error) {
+	mask := 0
+	if s == "" {
+		return mask, nil
+	}
+	for _, n := range strings.Split(s, ",") {
+		n = strings.TrimSpace(n)
+		found := false
+		for _, f := range a.Flags {
+			if f.Name == n {
+				mask |= f.Value
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid flag %#v", n)
+		}
+	}
+	return mask, nil
+}
+
+// FormatFlags returns the comma separated list of the names of the flags of the flag set defined
+// by the attribute that are set in the given bitmask in the order the flags were declared.
+func (a *AttributeDefinition) FormatFlags(mask int) string {
+	var names []string
+	for _, f := range a.Flags {
+		if mask&f.Value != 0 {
+			names = append(names, f.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// EncodesEnumNames returns true if the values of the integer enum with named values defined by
+// the attribute are encoded using their names rather than their numbers ("enum:encode" metadata
+// set to "name").
+func (a *AttributeDefinition) EncodesEnumNames() bool {
+	if v, ok := a.Metadata["enum:encode"]; ok && len(v) > 0 {
+		return v[0] == "name"
+	}
+	return false
+}
+
+// SQLPlaceholderStyle returns the style of the placeholders used by the SQL query helper generated
+// for attributes tagged with the "sql:bindable" metadata: "?" (the default) or "$" for numbered
+// placeholders. It returns the empty string if the attribute is not tagged.
+func (a *AttributeDefinition) SQLPlaceholderStyle() string {
+	v, ok := a.Metadata["sql:bindable"]
+	if !ok {
+		return ""
+	}
+	if len(v) == 0 || v[0] == "" {
+		return "?"
+	}
+	return v[0]
+}
+
+// StorageColumn returns the name of the database column that stores the attribute as defined by
+// the Column DSL, the empty string if none.
+func (a *AttributeDefinition) StorageColumn() string {
+	if v := a.Metadata["storage:column"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// StorageIndex returns true if the database column that stores the attribute is indexed as
+// defined by the Index DSL together with the name of the index if any.
+func (a *AttributeDefinition) StorageIndex() (string, bool) {
+	v, ok := a.Metadata["storage:index"]
+	if !ok || len(v) == 0 {
+		return "", ok
+	}
+	return v[0], true
+}
+
+// IsPrimaryKey returns true if the database column that stores the attribute is the primary key of
+// its table as defined by the PrimaryKey DSL.
+func (a *AttributeDefinition) IsPrimaryKey() bool {
+	_, ok := a.Metadata["storage:primary-key"]
+	return ok
+}
+
+// RequiredScope returns the scope that the caller must be granted for the attribute to be included
+// in the responses as defined by the RequiredScope DSL, the empty string if none.
+func (a *AttributeDefinition) RequiredScope() string {
+	if v := a.Metadata["security:scope"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// SinceVersion returns the first version of the resources whose requests and responses include
+// the attribute as defined by the SinceVersion DSL, the empty string if none.
+func (a *AttributeDefinition) SinceVersion() string {
+	if v := a.Metadata["version:since"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// UntilVersion returns the last version of the resources whose requests and responses include
+// the attribute as defined by the UntilVersion DSL, the empty string if none.
+func (a *AttributeDefinition) UntilVersion() string {
+	if v := a.Metadata["version:until"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// IsVersioned returns true if the attribute is only included in some versions of the resources
+// as defined by the SinceVersion and UntilVersion DSLs.
+func (a *AttributeDefinition) IsVersioned() bool {
+	return a.SinceVersion() != "" || a.UntilVersion() != ""
+}
+
+// InVersion returns true if the requests and responses of the given resource version include the
+// attribute. All attributes are included if the version is empty or cannot be compared.
+func (a *AttributeDefinition) InVersion(version string) bool {
+	if version == "" {
+		return true
+	}
+	if since := a.SinceVersion(); since != "" {
+		if c, err := CompareVersions(version, since); err == nil && c < 0 {
+			return false
+		}
+	}
+	if until := a.UntilVersion(); until != "" {
+		if c, err := CompareVersions(version, until); err == nil && c > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// VersionHiddenFields returns the sorted names of the fields of the object attribute or of the
+// elements of the array attribute that the given resource version does not include.
+func (a *AttributeDefinition) VersionHiddenFields(version string) []string {
+	obj := a
+	if a.Type.IsArray() {
+		obj = a.Type.ToArray().ElemType
+	}
+	var hidden []string
+	for n, att := range obj.Type.ToObject() {
+		if !att.InVersion(version) {
+			hidden = append(hidden, n)
+		}
+	}
+	sort.Strings(hidden)
+	return hidden
+}
+
+// CompareVersions compares two versions made of dot separated numbers optionally prefixed with
+// "v", e.g. "v2" or "1.3". It returns -1 if v1 is lower than v2, 1 if v1 is greater than v2 and 0
+// if the versions are equal. Missing numbers count as zero so that "v2" equals "v2.0".
+// CompareVersions returns an error if one of the versions does not have this form.
+func CompareVersions(v1, v2 string) (int, error) {
+	n1, err := parseVersion(v1)
+	if err != nil {
+		return 0, err
+	}
+	n2, err := parseVersion(v2)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(n1) || i < len(n2); i++ {
+		var x, y int
+		if i < len(n1) {
+			x = n1[i]
+		}
+		if i < len(n2) {
+			y = n2[i]
+		}
+		if x < y {
+			return -1, nil
+		}
+		if x > y {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseVersion returns the numbers of the given version.
+func parseVersion(v string) ([]int, error) {
+	elems := strings.Split(strings.TrimPrefix(strings.ToLower(v), "v"), ".")
+	nums := make([]int, len(elems))
+	for i, e := range elems {
+		n, err := strconv.Atoi(e)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %#v, versions must be dot separated numbers optionally prefixed with \"v\" such as \"v2\" or \"1.3\"", v)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// EncodeTransform returns the name of the transform applied to the attribute values before they
+// are encoded in the responses as defined by the EncodeTransform DSL, the empty string if none.
+func (a *AttributeDefinition) EncodeTransform() string {
+	if v := a.Metadata["encode:transform"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// WireType returns the name of the type used to encode the attribute values in JSON as defined by
+// the WireType DSL, the empty string if the values are encoded using the attribute type.
+func (a *AttributeDefinition) WireType() string {
+	if v := a.Metadata["json:wire"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// IsComputed returns true if the attribute value is computed when the response is rendered as
+// defined by the Computed DSL.
+func (a *AttributeDefinition) IsComputed() bool {
+	v := a.Metadata["compute:function"]
+	return len(v) > 0
+}
+
+// ComputedFields returns the sorted names of the computed fields of the object attribute or of
+// the element media type of the collection attribute, nil if there are none.
+func (a *AttributeDefinition) ComputedFields() []string {
+	if a.Type == nil {
+		return nil
+	}
+	if arr := a.Type.ToArray(); arr != nil {
+		if mt, ok := arr.ElemType.Type.(*MediaTypeDefinition); ok {
+			return mt.ComputedFields()
+		}
+		return nil
+	}
+	var fields []string
+	for n, att := range a.Type.ToObject() {
+		if att.IsComputed() {
+			fields = append(fields, n)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// FieldNaming returns the naming policy applied to the JSON names of the fields of the object
+// attribute as set with the FieldNaming DSL on the attribute type or else on the API, the empty
+// string if none.
+func (a *AttributeDefinition) FieldNaming() string {
+	if v := a.Metadata["json:naming"]; len(v) > 0 {
+		return v[0]
+	}
+	if Design != nil {
+		if v := Design.Metadata["json:naming"]; len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
 func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string) interface{} {
 	ary := a.Type.ToArray()
 	ln := newExampleGenerator(a, rand).ExampleLength()
 	var res []interface{}
-	for i := 0; i < ln; i++ {
-		ex := ary.ElemType.GenerateExample(rand, seen)
-		if ex != nil {
-			res = append(res, ex)
+	if a.Validation != nil && a.Validation.UniqueItems {
+		res = uniqueItemsExample(ary.ElemType, ln, rand, seen)
+	} else {
+		for i := 0; i < ln; i++ {
+			ex := ary.ElemType.GenerateExample(rand, seen)
+			if ex != nil {
+				res = append(res, ex)
+			}
 		}
 	}
 	if len(res) == 0 {
@@ -1180,6 +2173,43 @@ func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string)
 	return ary.MakeSlice(res)
 }
 
+// uniqueItemsExample returns up to ln distinct examples of elem. The example of an attribute is
+// generated once so the other items are generated from copies of elem, picking the enum values in
+// order if any. The result may contain fewer than ln items if elem cannot produce enough distinct
+// examples.
+func uniqueItemsExample(elem *AttributeDefinition, ln int, rand *RandomGenerator, seen []string) []interface{} {
+	var res []interface{}
+	for i := 0; len(res) < ln && i < maxAttempts; i++ {
+		att := elem
+		if i > 0 {
+			att = DupAtt(elem)
+			att.Example = nil
+			if elem.Validation != nil && len(elem.Validation.Values) > 0 {
+				vals := elem.Validation.Values
+				if i >= len(vals) {
+					break
+				}
+				att.Example = vals[i]
+			}
+		}
+		ex := att.GenerateExample(rand, seen)
+		if ex == nil {
+			continue
+		}
+		dup := false
+		for _, v := range res {
+			if reflect.DeepEqual(v, ex) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			res = append(res, ex)
+		}
+	}
+	return res
+}
+
 func (a *AttributeDefinition) hashExample(rand *RandomGenerator, seen []string) interface{} {
 	h := a.Type.ToHash()
 	ln := newExampleGenerator(a, rand).ExampleLength()
@@ -1226,6 +2256,10 @@ func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string
 	for _, n := range keys {
 		att := aObj[n]
 		if ex := att.GenerateExample(rand, seen); ex != nil {
+			if att.WireType() == String.Name() {
+				// The field is encoded as a JSON string
+				ex = fmt.Sprint(ex)
+			}
 			res[n] = ex
 		}
 	}
@@ -1318,15 +2352,23 @@ func (a *AttributeDefinition) inheritRecursive(parent *AttributeDefinition, seen
 					att.Inherit(patt.Type.ToObject()[n], s)
 				}
 			}
-			if att.Example == nil {
+			// The SkipValidation setting of the parent example only applies to that example.
+			ownExample := att.Example != nil
+			if !ownExample {
 				att.Example = patt.Example
 			}
 			if patt.Metadata != nil {
-				if att.Metadata == nil {
+				if att.Metadata == nil && !ownExample {
 					att.Metadata = patt.Metadata
 				} else {
+					if att.Metadata == nil {
+						att.Metadata = make(dslengine.MetadataDefinition)
+					}
 					// Copy all key/value pairs from parent to child that DO NOT exist in child; existing ones will remain with the same value
 					for k, v := range patt.Metadata {
+						if ownExample && k == "example:skip-validation" {
+							continue
+						}
 						if _, keyMetadataIsPresent := att.Metadata[k]; !keyMetadataIsPresent {
 							att.Metadata[k] = v
 						}
@@ -1373,6 +2415,11 @@ func (d *DocsDefinition) Context() string {
 	return fmt.Sprintf("documentation for %s", Design.Name)
 }
 
+// Context returns the generic definition name used in error messages.
+func (e *ExampleDefinition) Context() string {
+	return "example"
+}
+
 // Context returns the generic definition name used in error messages.
 func (t *UserTypeDefinition) Context() string {
 	if t.TypeName != "" {
@@ -1400,6 +2447,15 @@ func (r *ResponseDefinition) Context() string {
 	return prefix + suffix
 }
 
+// Context returns the generic definition name used in error messages.
+func (c *CookieDefinition) Context() string {
+	suffix := ""
+	if c.Parent != nil {
+		suffix = " of " + c.Parent.Context()
+	}
+	return fmt.Sprintf("cookie %#v%s", c.Name, suffix)
+}
+
 // Finalize sets the response media type from its type if the type is a media type and no media
 // type is already specified.
 func (r *ResponseDefinition) Finalize() {
@@ -1428,6 +2484,11 @@ func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
 	}
+	for _, c := range r.Cookies {
+		dup := *c
+		dup.Parent = &res
+		res.Cookies = append(res.Cookies, &dup)
+	}
 	return &res
 }
 
@@ -1449,6 +2510,9 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 		r.MediaType = other.MediaType
 		r.ViewName = other.ViewName
 	}
+	if len(r.Cookies) == 0 {
+		r.Cookies = other.Cookies
+	}
 	if other.Headers != nil {
 		otherHeaders := other.Headers.Type.ToObject()
 		if len(otherHeaders) > 0 {
@@ -1584,6 +2648,273 @@ func (a *ActionDefinition) WebSocket() bool {
 	return true
 }
 
+// BodyEnvelope returns the envelope that wraps the action request and response bodies as defined
+// by the BodyEnvelope DSL in the action resource or the API in this order of precedence, nil if
+// the bodies are not wrapped.
+func (a *ActionDefinition) BodyEnvelope() *EnvelopeDefinition {
+	if a.Parent != nil && a.Parent.Envelope != nil {
+		return a.Parent.Envelope
+	}
+	if Design != nil {
+		return Design.Envelope
+	}
+	return nil
+}
+
+// MaxBodySize returns the maximum number of bytes accepted in the action request bodies. The value
+// is read from the "http:body:max-size" metadata of the action, its resource or the API in this
+// order of precedence. MaxBodySize returns 0 if the body size is not limited.
+func (a *ActionDefinition) MaxBodySize() int64 {
+	mds := []dslengine.MetadataDefinition{a.Metadata}
+	if a.Parent != nil {
+		mds = append(mds, a.Parent.Metadata)
+	}
+	if Design != nil {
+		mds = append(mds, Design.Metadata)
+	}
+	for _, md := range mds {
+		if v, ok := md["http:body:max-size"]; ok && len(v) > 0 {
+			size, _ := ParseByteSize(v[0])
+			return size
+		}
+	}
+	return 0
+}
+
+// Compression returns the content codings used to compress the action responses in order of
+// preference and the minimum size of the compressed response bodies as defined by the Compress
+// DSL. The values are read from the "http:compress" metadata of the action, its resource or the
+// API in this order of precedence. Compression returns nil if the responses are not compressed.
+func (a *ActionDefinition) Compression() ([]string, int64) {
+	mds := []dslengine.MetadataDefinition{a.Metadata}
+	if a.Parent != nil {
+		mds = append(mds, a.Parent.Metadata)
+	}
+	if Design != nil {
+		mds = append(mds, Design.Metadata)
+	}
+	for _, md := range mds {
+		if v, ok := md["http:compress"]; ok {
+			var threshold int64
+			if t := md["http:compress:threshold"]; len(t) > 0 {
+				threshold, _ = ParseByteSize(t[0])
+			}
+			return v, threshold
+		}
+	}
+	return nil, 0
+}
+
+// SurrogateKeys returns the names of the success response attributes whose values are written to
+// the Surrogate-Key response header as defined by the SurrogateKeys DSL, nil if none.
+func (a *ActionDefinition) SurrogateKeys() []string {
+	return a.Metadata["http:surrogate-keys"]
+}
+
+// SignatureHeader returns the name of the request header that contains the signature of the
+// request body as defined by the VerifySignature DSL, the empty string if none.
+func (a *ActionDefinition) SignatureHeader() string {
+	if v := a.Metadata["http:signature:header"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// RequiresContentLength returns true if the action requests must set the Content-Length header
+// as defined by the RequireContentLength DSL.
+func (a *ActionDefinition) RequiresContentLength() bool {
+	v := a.Metadata["http:body:require-length"]
+	return len(v) > 0 && v[0] == "true"
+}
+
+// RequiredContentTypes returns the media types accepted in the Content-Type header of the action
+// requests as defined by the RequireContentType DSL, nil if any content type is accepted.
+func (a *ActionDefinition) RequiredContentTypes() []string {
+	return a.Metadata["http:body:content-type"]
+}
+
+// SkipsRequestBodyValidation returns true if the generated code decodes the action request body
+// without validating it as defined by the SkipRequestBodyValidation DSL.
+func (a *ActionDefinition) SkipsRequestBodyValidation() bool {
+	v := a.Metadata["http:body:skip-validation"]
+	return len(v) > 0 && v[0] == "true"
+}
+
+// PageSize returns the default and maximum values of the "page_size" parameter as defined by the
+// PageSize DSL, zero values if none.
+func (a *ActionDefinition) PageSize() (def, max int) {
+	v := a.Metadata["http:pagination:page-size"]
+	if len(v) != 2 {
+		return 0, 0
+	}
+	def, _ = strconv.Atoi(v[0])
+	max, _ = strconv.Atoi(v[1])
+	return def, max
+}
+
+// OperationID returns the identifier of the operation exposed by the action transport as defined
+// by the OperationID DSL, the empty string if none.
+func (a *ActionDefinition) OperationID() string {
+	if v := a.Metadata["operation:id"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// StreamsJSON returns true if the action success responses are streamed as newline delimited
+// JSON as defined by the StreamJSON DSL.
+func (a *ActionDefinition) StreamsJSON() bool {
+	v := a.Metadata["http:response:ndjson"]
+	return len(v) > 0 && v[0] == "true"
+}
+
+// DedupeWindow returns the duration during which the responses of the action are replayed to
+// identical requests as defined by the Dedupe DSL, 0 if none or if the window cannot be parsed.
+func (a *ActionDefinition) DedupeWindow() time.Duration {
+	if v := a.Metadata["http:dedupe:window"]; len(v) > 0 {
+		d, _ := time.ParseDuration(v[0])
+		return d
+	}
+	return 0
+}
+
+// RequiresIdempotencyKey returns true if clients identify the retries of the action requests with
+// the Idempotency-Key header as defined by the IdempotencyKey DSL.
+func (a *ActionDefinition) RequiresIdempotencyKey() bool {
+	v := a.Metadata["http:idempotency-key"]
+	return len(v) > 0 && v[0] == "true"
+}
+
+// CachesResponses returns true if the response of the first request that carries a given
+// idempotency key is replayed to its retries as defined by the Cacheable DSL.
+func (a *ActionDefinition) CachesResponses() bool {
+	v := a.Metadata["http:idempotency-key:cacheable"]
+	return len(v) > 0 && v[0] == "true"
+}
+
+// PatchFormat returns the format of the action request body, either MergePatch or JSONPatch, as
+// defined by the PatchFormat DSL, the empty string if none.
+func (a *ActionDefinition) PatchFormat() string {
+	if v := a.Metadata["http:patch:format"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// IsDeprecated returns true if the action is deprecated as defined by the Deprecated DSL.
+func (a *ActionDefinition) IsDeprecated() bool {
+	_, ok := a.Metadata["http:deprecated"]
+	return ok
+}
+
+// Sunset returns the date after which the deprecated action becomes unavailable as defined by the
+// Sunset DSL, the zero time if there is none or if the date is invalid.
+func (a *ActionDefinition) Sunset() time.Time {
+	v, ok := a.Metadata["http:deprecated:sunset"]
+	if !ok || len(v) == 0 {
+		return time.Time{}
+	}
+	t, _ := parseSunset(v[0])
+	return t
+}
+
+// parseSunset parses a sunset date given either as a date, e.g. "2025-12-31", or using the RFC
+// 3339 format.
+func parseSunset(date string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, date)
+}
+
+// EchoesUpdatedFields returns true if the action success responses are restricted to the
+// attributes set in the request body as defined by the EchoUpdatedFields DSL.
+func (a *ActionDefinition) EchoesUpdatedFields() bool {
+	_, ok := a.Metadata["http:echo-updated-fields"]
+	return ok
+}
+
+// HasSparseFieldsets returns true if the action success responses can be restricted to the
+// attributes listed in the "fields" query string parameter as defined by the SparseFieldsets DSL.
+func (a *ActionDefinition) HasSparseFieldsets() bool {
+	_, ok := a.Metadata["http:sparse-fieldsets"]
+	return ok
+}
+
+// BatchActionName returns the name of the batch action derived from the action as defined by the
+// Batch DSL, the empty string if none.
+func (a *ActionDefinition) BatchActionName() string {
+	if v := a.Metadata["batch:action"]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// BatchSource returns the action the batch action is derived from, nil if the action is not a
+// batch action.
+func (a *ActionDefinition) BatchSource() *ActionDefinition {
+	v := a.Metadata["batch:source"]
+	if len(v) == 0 || a.Parent == nil {
+		return nil
+	}
+	return a.Parent.Actions[v[0]]
+}
+
+// SortableFields returns the names of the fields that may be used to sort the action results as
+// defined by the Sortable DSL, nil if none.
+func (a *ActionDefinition) SortableFields() []string {
+	return a.Metadata["http:sortable"]
+}
+
+// SortFieldDefinitions returns the fields that may be used to sort the action results together
+// with their default sort options as defined by the Sortable and SortableField DSLs, nil if none.
+func (a *ActionDefinition) SortFieldDefinitions() []*SortFieldDefinition {
+	var defs []*SortFieldDefinition
+	for _, n := range a.SortableFields() {
+		def := &SortFieldDefinition{Name: n}
+		for _, o := range a.Metadata["http:sortable:"+n] {
+			switch o {
+			case "desc":
+				def.Descending = true
+			case "nulls-first":
+				def.Nulls = "first"
+			case "nulls-last":
+				def.Nulls = "last"
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// HasSortDefaults returns true if at least one of the sortable fields of the action defines
+// default sort options with the SortableField DSL.
+func (a *ActionDefinition) HasSortDefaults() bool {
+	for _, n := range a.SortableFields() {
+		if len(a.Metadata["http:sortable:"+n]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterableFields returns the names of the fields that may be used to filter the action results
+// as defined by the Filterable DSL, nil if none.
+func (a *ActionDefinition) FilterableFields() []string {
+	return a.Metadata["http:filterable"]
+}
+
+// ResponseFromField returns the name of the boolean response attribute whose value selects the
+// action response and the names of the responses sent when the attribute is true and false as
+// defined by the ResponseFromField DSL, empty strings if none.
+func (a *ActionDefinition) ResponseFromField() (field, whenTrue, whenFalse string) {
+	v := a.Metadata["http:response:from-field"]
+	if len(v) != 3 {
+		return "", "", ""
+	}
+	return v[0], v[1], v[2]
+}
+
 // Finalize inherits security scheme and action responses from parent and top level design.
 func (a *ActionDefinition) Finalize() {
 	// Inherit security scheme
@@ -1850,6 +3181,11 @@ func (r *RouteDefinition) Context() string {
 	return fmt.Sprintf(`route %s "%s" of %s`, r.Verb, r.Path, r.Parent.Context())
 }
 
+// Context returns the generic definition name used in error messages.
+func (e *EventDefinition) Context() string {
+	return fmt.Sprintf("event %#v of %s", e.Name, e.Parent.Context())
+}
+
 // Params returns the route parameters.
 // For example for the route "GET /foo/:fooID" Params returns []string{"fooID"}.
 func (r *RouteDefinition) Params() []string {
@@ -1902,3 +3238,36 @@ func iterateHeaders(headers *AttributeDefinition, isRequired func(name string) b
 	}
 	return nil
 }
+
+// byteUnits lists the units accepted by ParseByteSize, longest suffix first.
+var byteUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size expressed as a number of bytes optionally followed by one of the
+// units B, KB, MB or GB (powers of 1024), e.g. "512", "64KB" or "1MB". Units are case insensitive.
+func ParseByteSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	factor := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			factor = u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %#v, must be a positive number of bytes optionally followed by B, KB, MB or GB", size)
+	}
+	if n > math.MaxInt64/factor {
+		return 0, fmt.Errorf("size %#v is too large", size)
+	}
+	return n * factor, nil
+}
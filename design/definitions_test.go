@@ -1,6 +1,7 @@
 package design_test
 
 import (
+	"errors"
 	"path"
 
 	"github.com/goadesign/goa/design"
@@ -148,6 +149,71 @@ var _ = Describe("IterateHeaders", func() {
 	})
 
 })
+var _ = Describe("SortedResources", func() {
+	var api *design.APIDefinition
+
+	BeforeEach(func() {
+		api = &design.APIDefinition{
+			Resources: map[string]*design.ResourceDefinition{
+				"zoo":    {Name: "zoo"},
+				"animal": {Name: "animal", ParentName: "zoo"},
+				"keeper": {Name: "keeper"},
+			},
+		}
+	})
+
+	It("sorts the resources in alphabetical order regardless of their parents", func() {
+		var names []string
+		for _, r := range api.SortedResources() {
+			names = append(names, r.Name)
+		}
+		Ω(names).Should(Equal([]string{"animal", "keeper", "zoo"}))
+	})
+})
+
+var _ = Describe("TypeClosure", func() {
+	var res *design.ResourceDefinition
+
+	BeforeEach(func() {
+		inner := &design.UserTypeDefinition{
+			AttributeDefinition: &design.AttributeDefinition{Type: design.Object{"id": {Type: design.Integer}}},
+			TypeName:            "Inner",
+		}
+		outer := &design.UserTypeDefinition{
+			AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+				"inners": {Type: &design.Array{ElemType: &design.AttributeDefinition{Type: inner}}},
+			}},
+			TypeName: "Outer",
+		}
+		mt := &design.MediaTypeDefinition{
+			UserTypeDefinition: &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{Type: design.Object{"outer": {Type: outer}}},
+				TypeName:            "Result",
+			},
+			Identifier: "application/vnd.result+json",
+		}
+		res = &design.ResourceDefinition{
+			Name: "foo",
+			Actions: map[string]*design.ActionDefinition{
+				"show": {
+					Name:      "show",
+					Payload:   outer,
+					Responses: map[string]*design.ResponseDefinition{"OK": {Name: "OK", Type: mt}},
+				},
+			},
+		}
+	})
+
+	It("returns the user types and media types used by the resource transitively", func() {
+		types, mediaTypes := res.TypeClosure()
+		Ω(types).Should(HaveLen(2))
+		Ω(types).Should(HaveKey("Outer"))
+		Ω(types).Should(HaveKey("Inner"))
+		Ω(mediaTypes).Should(HaveLen(1))
+		Ω(mediaTypes).Should(HaveKey("application/vnd.result"))
+	})
+})
+
 var _ = Describe("Finalize ActionDefinition", func() {
 	Context("with an action with no response", func() {
 		var action *design.ActionDefinition
@@ -537,3 +603,298 @@ var _ = Describe("IterateSets", func() {
 	})
 
 })
+
+var _ = Describe("WalkAttributes", func() {
+	var api *design.APIDefinition
+	var paths []string
+	var walker func(string, *design.AttributeDefinition) error
+	var walkErr error
+
+	BeforeEach(func() {
+		shared := &design.UserTypeDefinition{
+			TypeName: "Shared",
+			AttributeDefinition: &design.AttributeDefinition{
+				Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+			},
+		}
+		node := &design.UserTypeDefinition{TypeName: "Node"}
+		node.AttributeDefinition = &design.AttributeDefinition{
+			Type: design.Object{
+				"children": &design.AttributeDefinition{
+					Type: &design.Array{ElemType: &design.AttributeDefinition{Type: node}},
+				},
+				"shared": &design.AttributeDefinition{Type: shared},
+			},
+		}
+		payload := &design.UserTypeDefinition{
+			TypeName: "CreateFooPayload",
+			AttributeDefinition: &design.AttributeDefinition{
+				Type: design.Object{
+					"first":  &design.AttributeDefinition{Type: shared},
+					"second": &design.AttributeDefinition{Type: shared},
+				},
+			},
+		}
+		res := &design.ResourceDefinition{Name: "foo"}
+		res.Actions = map[string]*design.ActionDefinition{
+			"create": {
+				Name:   "create",
+				Parent: res,
+				Params: &design.AttributeDefinition{
+					Type: design.Object{"id": &design.AttributeDefinition{Type: design.Integer}},
+				},
+				Payload: payload,
+			},
+		}
+		api = &design.APIDefinition{
+			Name:      "test",
+			Types:     map[string]*design.UserTypeDefinition{"Node": node, "Shared": shared},
+			Resources: map[string]*design.ResourceDefinition{"foo": res},
+		}
+		paths = nil
+		walker = func(path string, att *design.AttributeDefinition) error {
+			paths = append(paths, path)
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		walkErr = api.WalkAttributes(walker)
+	})
+
+	It("visits the attributes of shared and recursive types once", func() {
+		Ω(walkErr).ShouldNot(HaveOccurred())
+		Ω(paths).Should(Equal([]string{
+			"Node",
+			"Node.children",
+			"Node.children[]",
+			"Node.shared",
+			"Shared",
+			"Shared.name",
+			"foo.create.params",
+			"foo.create.params.id",
+			"CreateFooPayload",
+			"CreateFooPayload.first",
+			"CreateFooPayload.second",
+		}))
+	})
+
+	Context("with a function returning an error", func() {
+		BeforeEach(func() {
+			walker = func(path string, att *design.AttributeDefinition) error {
+				paths = append(paths, path)
+				if path == "Node.children" {
+					return errors.New("stop")
+				}
+				return nil
+			}
+		})
+
+		It("stops on the first error", func() {
+			Ω(walkErr).Should(MatchError("stop"))
+			Ω(paths).Should(Equal([]string{"Node", "Node.children"}))
+		})
+	})
+})
+
+var _ = Describe("Error responses", func() {
+	var api *design.APIDefinition
+
+	BeforeEach(func() {
+		res := &design.ResourceDefinition{
+			Name: "bottle",
+			Responses: map[string]*design.ResponseDefinition{
+				"NotFound": {Name: "NotFound", Status: 404, Description: "resource"},
+				"Conflict": {Name: "Conflict", Status: 409},
+			},
+		}
+		res.Actions = map[string]*design.ActionDefinition{
+			"show": {
+				Name:   "show",
+				Parent: res,
+				Responses: map[string]*design.ResponseDefinition{
+					"NotFound": {Name: "NotFound", Status: 404, Description: "action"},
+					"OK":       {Name: "OK", Status: 200},
+				},
+			},
+			"list": {Name: "list", Parent: res},
+		}
+		api = &design.APIDefinition{
+			Name:      "test",
+			Resources: map[string]*design.ResourceDefinition{"bottle": res},
+			Responses: map[string]*design.ResponseDefinition{
+				"NotFound":     {Name: "NotFound", Status: 404, Description: "api"},
+				"Unauthorized": {Name: "Unauthorized", Status: 401},
+			},
+			DefaultResponses: map[string]*design.ResponseDefinition{
+				"NotFound":   {Name: "NotFound", Status: 404, Description: "default"},
+				"BadRequest": {Name: "BadRequest", Status: 400},
+			},
+		}
+	})
+
+	Describe("ErrorNames", func() {
+		It("returns the sorted unique names of the error responses", func() {
+			Ω(api.ErrorNames()).Should(Equal([]string{"Conflict", "NotFound", "Unauthorized"}))
+		})
+	})
+
+	Describe("ResolveError", func() {
+		It("gives precedence to the action responses", func() {
+			Ω(api.ResolveError("bottle.show", "NotFound").Description).Should(Equal("action"))
+		})
+
+		It("falls back to the resource responses", func() {
+			Ω(api.ResolveError("bottle.list", "NotFound").Description).Should(Equal("resource"))
+			Ω(api.ResolveError("bottle", "NotFound").Description).Should(Equal("resource"))
+		})
+
+		It("falls back to the API and built-in responses", func() {
+			Ω(api.ResolveError("", "NotFound").Description).Should(Equal("api"))
+			Ω(api.ResolveError("bottle.show", "Unauthorized").Status).Should(Equal(401))
+			Ω(api.ResolveError("bottle.show", "BadRequest").Status).Should(Equal(400))
+		})
+
+		It("ignores non error responses and unknown scopes", func() {
+			Ω(api.ResolveError("bottle.show", "OK")).Should(BeNil())
+			Ω(api.ResolveError("bottle.delete", "NotFound")).Should(BeNil())
+			Ω(api.ResolveError("", "Conflict")).Should(BeNil())
+		})
+	})
+})
+
+var _ = Describe("ContentTypes", func() {
+	var api *design.APIDefinition
+
+	BeforeEach(func() {
+		api = &design.APIDefinition{Name: "test"}
+	})
+
+	It("defaults to JSON", func() {
+		consumes, produces := api.ContentTypes()
+		Ω(consumes).Should(Equal([]string{"application/json"}))
+		Ω(produces).Should(Equal([]string{"application/json"}))
+	})
+
+	Context("with a design mixing JSON, XML and msgpack", func() {
+		BeforeEach(func() {
+			res := &design.ResourceDefinition{Name: "bottle"}
+			res.Actions = map[string]*design.ActionDefinition{
+				"show": {
+					Name:   "show",
+					Parent: res,
+					Responses: map[string]*design.ResponseDefinition{
+						"OK":       {Name: "OK", Status: 200, MediaType: "application/vnd.bottle+json; type=collection"},
+						"NotFound": {Name: "NotFound", Status: 404, MediaType: "application/xml"},
+					},
+				},
+				"upload": {
+					Name:             "upload",
+					Parent:           res,
+					PayloadMultipart: true,
+					Responses: map[string]*design.ResponseDefinition{
+						"OK": {Name: "OK", Status: 200, MediaType: "application/msgpack"},
+					},
+				},
+			}
+			api.Resources = map[string]*design.ResourceDefinition{"bottle": res}
+			api.Consumes = []*design.EncodingDefinition{
+				{MIMETypes: []string{"application/json", "application/msgpack"}},
+			}
+			api.Produces = []*design.EncodingDefinition{
+				{MIMETypes: []string{"application/json"}},
+				{MIMETypes: []string{"application/xml"}},
+			}
+		})
+
+		It("lists the sorted and deduplicated content types", func() {
+			consumes, produces := api.ContentTypes()
+			Ω(consumes).Should(Equal([]string{"application/json", "application/msgpack", "multipart/form-data"}))
+			Ω(produces).Should(Equal([]string{"application/json", "application/msgpack", "application/vnd.bottle+json", "application/xml"}))
+		})
+	})
+})
+
+var _ = Describe("CompareVersions", func() {
+	It("compares the version numbers", func() {
+		for _, c := range []struct {
+			v1, v2 string
+			res    int
+		}{
+			{"v1", "v2", -1},
+			{"v2", "v1", 1},
+			{"v2", "V2.0", 0},
+			{"1.10", "1.9", 1},
+			{"v2", "v2.1", -1},
+		} {
+			res, err := design.CompareVersions(c.v1, c.v2)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(res).Should(Equal(c.res), "%s vs. %s", c.v1, c.v2)
+		}
+	})
+
+	It("returns an error for versions that are not numbers", func() {
+		_, err := design.CompareVersions("v1", "beta")
+		Ω(err).Should(HaveOccurred())
+		_, err = design.CompareVersions("2019-01", "v1")
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("InVersion", func() {
+	var att *design.AttributeDefinition
+
+	BeforeEach(func() {
+		att = &design.AttributeDefinition{
+			Type:     design.Integer,
+			Metadata: dslengine.MetadataDefinition{"version:since": {"v2"}, "version:until": {"v3"}},
+		}
+	})
+
+	It("includes the attribute in the versions between the first and last versions", func() {
+		Ω(att.InVersion("v1")).Should(BeFalse())
+		Ω(att.InVersion("v2")).Should(BeTrue())
+		Ω(att.InVersion("v3")).Should(BeTrue())
+		Ω(att.InVersion("v3.1")).Should(BeFalse())
+	})
+
+	It("includes the attribute in unversioned resources", func() {
+		Ω(att.InVersion("")).Should(BeTrue())
+	})
+
+	It("lists the fields omitted by a version", func() {
+		obj := &design.AttributeDefinition{Type: design.Object{"id": {Type: design.Integer}, "rating": att}}
+		Ω(obj.VersionHiddenFields("v1")).Should(Equal([]string{"rating"}))
+		Ω(obj.VersionHiddenFields("v2")).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("ParseFlags", func() {
+	var att *design.AttributeDefinition
+
+	BeforeEach(func() {
+		att = &design.AttributeDefinition{
+			Type:  design.String,
+			Flags: []*design.FlagDefinition{{Name: "READ", Value: 1}, {Name: "WRITE", Value: 2}, {Name: "ADMIN", Value: 4}},
+		}
+	})
+
+	It("round-trips flag names and bitmasks", func() {
+		mask, err := att.ParseFlags("READ,WRITE")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mask).Should(Equal(3))
+		Ω(att.FormatFlags(mask)).Should(Equal("READ,WRITE"))
+	})
+
+	It("parses the empty list", func() {
+		mask, err := att.ParseFlags("")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mask).Should(Equal(0))
+		Ω(att.FormatFlags(0)).Should(Equal(""))
+	})
+
+	It("returns an error for unknown flags", func() {
+		_, err := att.ParseFlags("READ,EXECUTE")
+		Ω(err).Should(HaveOccurred())
+	})
+})
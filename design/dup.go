@@ -47,6 +47,8 @@ func (d *dupper) DupAttribute(att *AttributeDefinition) *AttributeDefinition {
 		Metadata:          att.Metadata,
 		DefaultValue:      att.DefaultValue,
 		NonZeroAttributes: att.NonZeroAttributes,
+		Transitions:       att.Transitions,
+		Flags:             att.Flags,
 		View:              att.View,
 		DSLFunc:           att.DSLFunc,
 		Example:           att.Example,
@@ -72,9 +72,15 @@ func (eg *exampleGenerator) ExampleLength() int {
 		if eg.a.Validation.MinLength != nil {
 			minlength = float64(*eg.a.Validation.MinLength)
 		}
+		if n := eg.a.Validation.MinItems; n != nil && (math.IsInf(minlength, 1) || float64(*n) > minlength) {
+			minlength = float64(*n)
+		}
 		if eg.a.Validation.MaxLength != nil {
 			maxlength = float64(*eg.a.Validation.MaxLength)
 		}
+		if n := eg.a.Validation.MaxItems; n != nil && (math.IsInf(maxlength, -1) || float64(*n) < maxlength) {
+			maxlength = float64(*n)
+		}
 		count := 0
 		if math.IsInf(minlength, 1) {
 			count = int(maxlength) - (eg.r.Int() % 3)
@@ -106,7 +112,8 @@ func (eg *exampleGenerator) hasLengthValidation() bool {
 	if eg.a.Validation == nil {
 		return false
 	}
-	return eg.a.Validation.MinLength != nil || eg.a.Validation.MaxLength != nil
+	v := eg.a.Validation
+	return v.MinLength != nil || v.MaxLength != nil || v.MinItems != nil || v.MaxItems != nil
 }
 
 const maxExampleLength = 10
@@ -128,15 +135,13 @@ func (eg *exampleGenerator) hasEnumValidation() bool {
 	return eg.a.Validation != nil && len(eg.a.Validation.Values) > 0
 }
 
-// generateValidatedEnumExample returns a random selected enum value.
+// generateValidatedEnumExample returns the first enum value so that examples of enum attributes
+// are stable and match the value listed first in the design.
 func (eg *exampleGenerator) generateValidatedEnumExample() interface{} {
 	if !eg.hasEnumValidation() {
 		return nil
 	}
-	values := eg.a.Validation.Values
-	count := len(values)
-	i := eg.r.Int() % count
-	return values[i]
+	return eg.a.Validation.Values[0]
 }
 
 func (eg *exampleGenerator) hasFormatValidation() bool {
@@ -149,6 +154,14 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 		return nil
 	}
 	format := eg.a.Validation.Format
+	if CustomFormat(format) != nil {
+		// Examples of custom formats cannot be generated, let the other validations or the
+		// type produce one.
+		return nil
+	}
+	if format == "decimal" {
+		return eg.generateDecimalExample()
+	}
 	if res, ok := map[string]interface{}{
 		"email":     eg.r.faker.Email(),
 		"hostname":  eg.r.faker.DomainName() + "." + eg.r.faker.DomainSuffix(),
@@ -174,6 +187,33 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 	panic("Validation: unknown format '" + format + "'") // bug
 }
 
+// generateDecimalExample returns a random decimal value that satisfies the decimal scale and
+// precision validations if any.
+func (eg *exampleGenerator) generateDecimalExample() string {
+	scale, precision := 2, 6
+	if s := eg.a.Validation.DecimalScale; s != nil {
+		scale = *s
+	}
+	if p := eg.a.Validation.DecimalPrecision; p != nil && *p < precision {
+		precision = *p
+	}
+	if scale > precision {
+		scale = precision
+	}
+	digits := make([]byte, precision)
+	for i := range digits {
+		digits[i] = byte('0' + eg.r.Int()%10)
+	}
+	integer, fraction := string(digits[:precision-scale]), string(digits[precision-scale:])
+	if integer == "" {
+		integer = "0"
+	}
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
+
 func (eg *exampleGenerator) hasPatternValidation() bool {
 	return eg.a.Validation != nil && eg.a.Validation.Pattern != ""
 }
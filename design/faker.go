@@ -0,0 +1,114 @@
+package design
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/satori/go.uuid"
+)
+
+// fakerDirective describes a directive supported by the Faker DSL.
+type fakerDirective struct {
+	// kinds lists the kinds of the attributes the directive applies to.
+	kinds []Kind
+	// fake produces a fake value using the given random generator.
+	fake func(r *RandomGenerator) interface{}
+}
+
+// fakerDirectives indexes the directives supported by the Faker DSL by name.
+var fakerDirectives = map[string]*fakerDirective{
+	"name":           fakeString(func(r *RandomGenerator) string { return r.faker.Name() }),
+	"first_name":     fakeString(func(r *RandomGenerator) string { return r.faker.FirstName() }),
+	"last_name":      fakeString(func(r *RandomGenerator) string { return r.faker.LastName() }),
+	"username":       fakeString(func(r *RandomGenerator) string { return r.faker.UserName() }),
+	"email":          fakeString(func(r *RandomGenerator) string { return r.faker.Email() }),
+	"phone":          fakeString(fakePhone),
+	"company":        fakeString(func(r *RandomGenerator) string { return r.faker.CompanyName() }),
+	"job_title":      fakeString(func(r *RandomGenerator) string { return r.faker.JobTitle() }),
+	"street_address": fakeString(fakeStreetAddress),
+	"city":           fakeString(func(r *RandomGenerator) string { return r.faker.City() }),
+	"state":          fakeString(func(r *RandomGenerator) string { return r.faker.State() }),
+	"country":        fakeString(func(r *RandomGenerator) string { return r.faker.Country() }),
+	"postcode":       fakeString(fakePostcode),
+	"domain":         fakeString(func(r *RandomGenerator) string { return r.faker.DomainName() }),
+	"url":            fakeString(func(r *RandomGenerator) string { return r.faker.URL() }),
+	"ipv4":           fakeString(func(r *RandomGenerator) string { return r.faker.IPv4Address().String() }),
+	"ipv6":           fakeString(func(r *RandomGenerator) string { return r.faker.IPv6Address().String() }),
+	"word":           fakeString(func(r *RandomGenerator) string { return r.faker.Words(1, false)[0] }),
+	"sentence":       fakeString(func(r *RandomGenerator) string { return r.faker.Sentence(5, false) }),
+	"paragraph":      fakeString(func(r *RandomGenerator) string { return r.faker.Paragraph(3, false) }),
+	"uuid": {
+		kinds: []Kind{StringKind, UUIDKind},
+		fake: func(r *RandomGenerator) interface{} {
+			// Do not use uuid.NewV4 so that the value only depends on the seed.
+			b := make([]byte, 16)
+			r.rand.Read(b)
+			b[6] = (b[6] & 0x0f) | 0x40
+			b[8] = (b[8] & 0x3f) | 0x80
+			return uuid.FromBytesOrNil(b).String()
+		},
+	},
+	"latitude": {
+		kinds: []Kind{NumberKind},
+		fake:  func(r *RandomGenerator) interface{} { return r.faker.Latitude() },
+	},
+	"longitude": {
+		kinds: []Kind{NumberKind},
+		fake:  func(r *RandomGenerator) interface{} { return r.faker.Longitude() },
+	},
+}
+
+// fakeString returns a directive that applies to string attributes.
+func fakeString(fake func(r *RandomGenerator) string) *fakerDirective {
+	return &fakerDirective{
+		kinds: []Kind{StringKind},
+		fake:  func(r *RandomGenerator) interface{} { return fake(r) },
+	}
+}
+
+// The faker package draws the digits of phone numbers, postcodes and street addresses from the
+// global random source, produce them from the seeded source instead.
+
+func fakePhone(r *RandomGenerator) string {
+	return fmt.Sprintf("%03d-%03d-%04d", 200+r.rand.Intn(800), r.rand.Intn(1000), r.rand.Intn(10000))
+}
+
+func fakePostcode(r *RandomGenerator) string {
+	return fmt.Sprintf("%05d", r.rand.Intn(100000))
+}
+
+func fakeStreetAddress(r *RandomGenerator) string {
+	return fmt.Sprintf("%d %s", 1+r.rand.Intn(9999), r.faker.StreetName())
+}
+
+// appliesTo returns true if the directive applies to attributes of the given kind.
+func (d *fakerDirective) appliesTo(k Kind) bool {
+	for _, kind := range d.kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// FakerDirectives returns the sorted names of the directives supported by the Faker DSL.
+func FakerDirectives() []string {
+	names := make([]string, len(fakerDirectives))
+	i := 0
+	for n := range fakerDirectives {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fake produces a fake value for the given Faker directive using the given random generator. The
+// values only depend on the generator seed. Fake returns nil if the directive is unknown.
+func (r *RandomGenerator) Fake(directive string) interface{} {
+	d, ok := fakerDirectives[directive]
+	if !ok {
+		return nil
+	}
+	return d.fake(r)
+}
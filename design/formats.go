@@ -0,0 +1,76 @@
+package design
+
+import "fmt"
+
+// BuiltinFormats lists the formats supported natively by goa.
+var BuiltinFormats = []string{
+	"cidr",
+	"date",
+	"date-time",
+	"decimal",
+	"email",
+	"hostname",
+	"ipv4",
+	"ipv6",
+	"ip",
+	"mac",
+	"regexp",
+	"rfc1123",
+	"uri",
+}
+
+// FormatDefinition describes a custom format registered with RegisterFormat.
+type FormatDefinition struct {
+	// Name is the name of the format as used in the Format DSL.
+	Name string
+	// Validate validates string values against the format.
+	Validate func(string) error
+	// OpenAPIFormat is the value of the "format" field in the generated OpenAPI specification.
+	OpenAPIFormat string
+}
+
+// customFormats records the formats registered with RegisterFormat indexed by name.
+var customFormats = make(map[string]*FormatDefinition)
+
+// RegisterFormat registers a custom format that can then be used with the Format DSL. validate is
+// used to check the default values of the attributes that use the format, openAPIFormat is the
+// value of the "format" field in the generated OpenAPI specification, the format name is used if
+// empty. The generated code validates values with goa.ValidateFormat so the service must also
+// register the format at runtime with goa.RegisterFormat.
+// RegisterFormat panics if name is empty, collides with the name of a built-in format or if
+// validate is nil.
+func RegisterFormat(name string, validate func(string) error, openAPIFormat string) {
+	if name == "" {
+		panic("design: format name cannot be empty")
+	}
+	// "uuid" is reserved by the runtime for values of the UUID type.
+	if name == "uuid" {
+		panic(fmt.Sprintf("design: cannot register built-in format %#v", name))
+	}
+	for _, f := range BuiltinFormats {
+		if f == name {
+			panic(fmt.Sprintf("design: cannot register built-in format %#v", name))
+		}
+	}
+	if validate == nil {
+		panic(fmt.Sprintf("design: nil validation function for format %#v", name))
+	}
+	if openAPIFormat == "" {
+		openAPIFormat = name
+	}
+	customFormats[name] = &FormatDefinition{Name: name, Validate: validate, OpenAPIFormat: openAPIFormat}
+}
+
+// CustomFormat returns the custom format registered with the given name, nil if there isn't one.
+func CustomFormat(name string) *FormatDefinition {
+	return customFormats[name]
+}
+
+// OpenAPIFormat returns the value of the "format" field in the OpenAPI specification for the given
+// format name.
+func OpenAPIFormat(name string) string {
+	if f := CustomFormat(name); f != nil {
+		return f.OpenAPIFormat
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package design
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BuiltinEncodeTransforms lists the encode transforms supported natively by goa.
+var BuiltinEncodeTransforms = []string{
+	"e164",
+	"lowercase",
+	"trim",
+	"uppercase",
+	"url",
+}
+
+// customEncodeTransforms records the names of the encode transforms registered with
+// RegisterEncodeTransform.
+var customEncodeTransforms = make(map[string]bool)
+
+// RegisterEncodeTransform registers a custom encode transform that can then be used with the
+// EncodeTransform DSL. Encode transforms apply to string attributes. The generated code applies
+// the transforms with goa.ApplyEncodeTransforms so the service must also register the transform at
+// runtime with goa.RegisterEncodeTransform. RegisterEncodeTransform panics if name is empty or
+// collides with the name of a built-in transform.
+func RegisterEncodeTransform(name string) {
+	if name == "" {
+		panic("design: encode transform name cannot be empty")
+	}
+	for _, t := range BuiltinEncodeTransforms {
+		if t == name {
+			panic(fmt.Sprintf("design: cannot register built-in encode transform %#v", name))
+		}
+	}
+	customEncodeTransforms[name] = true
+}
+
+// EncodeTransforms returns the sorted names of the built-in and registered encode transforms.
+func EncodeTransforms() []string {
+	names := append([]string{}, BuiltinEncodeTransforms...)
+	for n := range customEncodeTransforms {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsEncodeTransform returns true if name is the name of a built-in or registered encode transform.
+func IsEncodeTransform(name string) bool {
+	for _, t := range BuiltinEncodeTransforms {
+		if t == name {
+			return true
+		}
+	}
+	return customEncodeTransforms[name]
+}
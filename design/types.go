@@ -674,6 +674,32 @@ func (h HashVal) ToMap() map[interface{}]interface{} {
 	return mp
 }
 
+// toGenericValue converts slices and maps of any Go type into the []interface{} and
+// map[interface{}]interface{} values used to represent array and hash values in the design.
+// Other values are returned unchanged.
+func toGenericValue(val interface{}) interface{} {
+	if val == nil {
+		return nil
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		arr := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			arr[i] = toGenericValue(v.Index(i).Interface())
+		}
+		return arr
+	case reflect.Map:
+		mp := make(map[interface{}]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			mp[k.Interface()] = toGenericValue(v.MapIndex(k).Interface())
+		}
+		return mp
+	default:
+		return val
+	}
+}
+
 // NewUserTypeDefinition creates a user type definition but does not
 // execute the DSL.
 func NewUserTypeDefinition(name string, dsl func()) *UserTypeDefinition {
@@ -721,6 +747,35 @@ func (u *UserTypeDefinition) IsCompatible(val interface{}) bool {
 	return u.Type == nil || u.Type.IsCompatible(val)
 }
 
+// Base returns the type u extends as defined with the Extend DSL, nil if none.
+func (u *UserTypeDefinition) Base() *UserTypeDefinition {
+	if b, ok := u.Metadata["type:extends"]; ok && len(b) > 0 {
+		return Design.Types[b[0]]
+	}
+	return nil
+}
+
+// Discriminator returns the name of the attribute used to discriminate the types that extend u
+// as defined with the Discriminator DSL, the empty string if none.
+func (u *UserTypeDefinition) Discriminator() string {
+	if d, ok := u.Metadata["type:discriminator"]; ok && len(d) > 0 {
+		return d[0]
+	}
+	return ""
+}
+
+// Variants returns the types that extend u sorted by name.
+func (u *UserTypeDefinition) Variants() []*UserTypeDefinition {
+	var variants []*UserTypeDefinition
+	for _, t := range Design.Types {
+		if b, ok := t.Metadata["type:extends"]; ok && len(b) > 0 && b[0] == u.TypeName {
+			variants = append(variants, t)
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].TypeName < variants[j].TypeName })
+	return variants
+}
+
 // Finalize merges base type attributes.
 func (u *UserTypeDefinition) Finalize() {
 	if u.Reference != nil {
@@ -863,6 +918,9 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 			},
 		},
 	}
+	if naming, ok := m.Metadata["json:naming"]; ok {
+		p.Metadata = dslengine.MetadataDefinition{"json:naming": naming}
+	}
 	p.Views = map[string]*ViewDefinition{"default": {
 		Name:                "default",
 		AttributeDefinition: DupAtt(v.AttributeDefinition),
@@ -1071,6 +1129,54 @@ func walk(at *AttributeDefinition, walker func(*AttributeDefinition) error, seen
 	return nil
 }
 
+// walkUserType calls walker on the attributes of the given user type located under the type name
+// unless the type was already walked.
+func walkUserType(ut *UserTypeDefinition, walker func(string, *AttributeDefinition) error, seen map[*UserTypeDefinition]bool) error {
+	if seen[ut] {
+		return nil
+	}
+	seen[ut] = true
+	return walkPath(ut.TypeName, ut.AttributeDefinition, walker, seen)
+}
+
+// walkPath calls walker on the given attribute and recursively on its child attributes. This is
+// the implementation of APIDefinition.WalkAttributes.
+func walkPath(path string, at *AttributeDefinition, walker func(string, *AttributeDefinition) error, seen map[*UserTypeDefinition]bool) error {
+	if err := walker(path, at); err != nil {
+		return err
+	}
+	return walkTypePath(path, at.Type, walker, seen)
+}
+
+// walkTypePath walks the child attributes of the given data type.
+func walkTypePath(path string, dt DataType, walker func(string, *AttributeDefinition) error, seen map[*UserTypeDefinition]bool) error {
+	switch actual := dt.(type) {
+	case *Array:
+		return walkPath(path+"[]", actual.ElemType, walker, seen)
+	case *Hash:
+		if err := walkPath(path+"[key]", actual.KeyType, walker, seen); err != nil {
+			return err
+		}
+		return walkPath(path+"[value]", actual.ElemType, walker, seen)
+	case Object:
+		names := make([]string, 0, len(actual))
+		for n := range actual {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if err := walkPath(path+"."+n, actual[n], walker, seen); err != nil {
+				return err
+			}
+		}
+	case *UserTypeDefinition:
+		return walkUserType(actual, walker, seen)
+	case *MediaTypeDefinition:
+		return walkUserType(actual.UserTypeDefinition, walker, seen)
+	}
+	return nil
+}
+
 // toReflectType converts the DataType to reflect.Type.
 func toReflectType(dtype DataType) reflect.Type {
 	switch dtype.Kind() {
@@ -602,4 +602,151 @@ var _ = Describe("GenerateExample", func() {
 			Ω(h.GenerateExample(rand, nil)).Should(BeAssignableToTypeOf(map[string]string{"foo": "bar"}))
 		})
 	})
+
+	Context("Given a decimal attribute with scale and precision validations", func() {
+		It("generates a valid decimal example", func() {
+			rand := NewRandomGenerator("foo")
+			scale, precision := 3, 5
+			att := &AttributeDefinition{
+				Type: String,
+				Validation: &dslengine.ValidationDefinition{
+					Format:           "decimal",
+					DecimalScale:     &scale,
+					DecimalPrecision: &precision,
+				},
+			}
+			Ω(att.GenerateExample(rand, nil)).Should(MatchRegexp(`^[0-9]{2}\.[0-9]{3}$`))
+		})
+	})
+
+	Context("Given an enum attribute", func() {
+		var att *AttributeDefinition
+
+		BeforeEach(func() {
+			att = &AttributeDefinition{
+				Type:       String,
+				Validation: &dslengine.ValidationDefinition{Values: []interface{}{"red", "green", "blue"}},
+			}
+		})
+
+		It("uses the first enum value", func() {
+			for _, seed := range []string{"foo", "bar", "baz"} {
+				Ω(DupAtt(att).GenerateExample(NewRandomGenerator(seed), nil)).Should(Equal("red"))
+			}
+		})
+
+		Context("with an explicit example", func() {
+			BeforeEach(func() {
+				att.Example = "blue"
+			})
+
+			It("uses the explicit example", func() {
+				Ω(att.GenerateExample(NewRandomGenerator("foo"), nil)).Should(Equal("blue"))
+			})
+		})
+	})
+
+	Context("Given an array attribute with unique items", func() {
+		var elem *AttributeDefinition
+		var att *AttributeDefinition
+
+		BeforeEach(func() {
+			elem = &AttributeDefinition{Type: String}
+		})
+
+		JustBeforeEach(func() {
+			min := 3
+			att = &AttributeDefinition{
+				Type:       &Array{ElemType: elem},
+				Validation: &dslengine.ValidationDefinition{MinItems: &min, UniqueItems: true},
+			}
+		})
+
+		It("generates distinct items", func() {
+			for _, seed := range []string{"foo", "bar", "baz"} {
+				example := DupAtt(att).GenerateExample(NewRandomGenerator(seed), nil)
+				Ω(example).Should(BeAssignableToTypeOf([]string{}))
+				items := example.([]string)
+				Ω(len(items)).Should(BeNumerically(">=", 3))
+				seen := make(map[string]bool)
+				for _, item := range items {
+					Ω(seen).ShouldNot(HaveKey(item))
+					seen[item] = true
+				}
+			}
+		})
+
+		Context("with enum items", func() {
+			BeforeEach(func() {
+				elem.Validation = &dslengine.ValidationDefinition{Values: []interface{}{"red", "green", "blue", "white"}}
+			})
+
+			It("uses the enum values in order", func() {
+				example := att.GenerateExample(NewRandomGenerator("foo"), nil).([]string)
+				Ω(example).Should(Equal([]string{"red", "green", "blue", "white"}[:len(example)]))
+			})
+		})
+
+		Context("with fewer enum values than items", func() {
+			BeforeEach(func() {
+				elem.Validation = &dslengine.ValidationDefinition{Values: []interface{}{"red", "green"}}
+			})
+
+			It("uses each value once", func() {
+				Ω(att.GenerateExample(NewRandomGenerator("foo"), nil)).Should(Equal([]string{"red", "green"}))
+			})
+		})
+	})
+
+	Context("Given an object with a field encoded as a string", func() {
+		It("generates a quoted example for the field", func() {
+			id := &AttributeDefinition{
+				Type:     Integer,
+				Example:  1252288009208053951,
+				Metadata: dslengine.MetadataDefinition{"json:wire": {"string"}},
+			}
+			att := &AttributeDefinition{Type: Object{"id": id, "count": {Type: Integer, Example: 3}}}
+			example := att.GenerateExample(NewRandomGenerator("foo"), nil)
+			Ω(example).Should(Equal(map[string]interface{}{"id": "1252288009208053951", "count": 3}))
+			Ω(id.Example).Should(Equal(1252288009208053951))
+		})
+	})
+
+	Context("Given attributes with faker directives", func() {
+		var newAtt func(DataType, string) *AttributeDefinition
+
+		BeforeEach(func() {
+			dslengine.Reset()
+			newAtt = func(t DataType, directive string) *AttributeDefinition {
+				return &AttributeDefinition{
+					Type:     t,
+					Metadata: dslengine.MetadataDefinition{"example:faker": {directive}},
+				}
+			}
+		})
+
+		It("generates fake values", func() {
+			rand := NewRandomGenerator("foo")
+			Ω(newAtt(String, "email").GenerateExample(rand, nil)).Should(MatchRegexp(`^\S+@\S+\.\S+$`))
+			Ω(newAtt(UUID, "uuid").GenerateExample(rand, nil)).Should(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+			Ω(newAtt(Number, "latitude").GenerateExample(rand, nil)).Should(BeNumerically("~", 0, 90))
+		})
+
+		It("generates the same values for the same seed", func() {
+			generate := func(seed string) []interface{} {
+				rand := NewRandomGenerator(seed)
+				var examples []interface{}
+				for _, d := range FakerDirectives() {
+					t := DataType(String)
+					if d == "latitude" || d == "longitude" {
+						t = Number
+					}
+					examples = append(examples, newAtt(t, d).GenerateExample(rand, nil))
+				}
+				return examples
+			}
+			Ω(generate("foo")).Should(Equal(generate("foo")))
+			Ω(generate("foo")).ShouldNot(Equal(generate("bar")))
+		})
+	})
 })
@@ -7,10 +7,16 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/dslengine"
 )
 
@@ -85,6 +91,20 @@ func (a *APIDefinition) Validate() error {
 	a.validateLicense(verr)
 	a.validateDocs(verr)
 	a.validateOrigins(verr)
+	a.validateProblemTypeBase(verr)
+	validateDefaultResponse(verr, a.DefaultResponse)
+	a.validateBasePath(verr)
+	a.validateVersions(verr)
+	a.validateOperationIDs(verr)
+	a.validateNamedEnums(verr)
+	a.validateFlagSets(verr)
+	validateMaxBodySize(verr, a, a.Metadata)
+	validateCompression(verr, a, a.Metadata)
+	validateEnvelope(verr, a, a.Envelope)
+	if a.RequestIDHeader != "" && !isHTTPToken(a.RequestIDHeader) {
+		verr.Add(a, "invalid request ID header name %#v", a.RequestIDHeader)
+	}
+	validateFieldNaming(verr, a, a.Metadata)
 
 	var allRoutes []*routeInfo
 	a.IterateResources(func(r *ResourceDefinition) error {
@@ -123,6 +143,7 @@ func (a *APIDefinition) Validate() error {
 		verr.Merge(mt.Validate())
 		return nil
 	})
+	a.validateViewUsage()
 	a.IterateUserTypes(func(t *UserTypeDefinition) error {
 		verr.Merge(t.Validate("", a))
 		return nil
@@ -146,6 +167,95 @@ func (a *APIDefinition) Validate() error {
 	return err
 }
 
+// validateViewUsage reports a warning for each view of a media type that is never used to render
+// a response, a media type attribute or a link. Unused views only add dead generated code.
+func (a *APIDefinition) validateViewUsage() {
+	used := make(map[*MediaTypeDefinition]map[string]bool)
+	var use func(mt *MediaTypeDefinition, view string)
+	use = func(mt *MediaTypeDefinition, view string) {
+		if mt == nil {
+			return
+		}
+		if view == "" {
+			view = "default"
+		}
+		if used[mt] == nil {
+			used[mt] = make(map[string]bool)
+		}
+		used[mt][view] = true
+		if !mt.IsArray() {
+			return
+		}
+		if arr := mt.Type.ToArray(); arr.ElemType != nil {
+			if emt, ok := arr.ElemType.Type.(*MediaTypeDefinition); ok {
+				use(emt, view)
+			}
+		}
+	}
+	useResponses := func(resps map[string]*ResponseDefinition) {
+		for _, r := range resps {
+			mt, ok := r.Type.(*MediaTypeDefinition)
+			if !ok {
+				mt = a.MediaTypeWithIdentifier(r.MediaType)
+			}
+			use(mt, r.ViewName)
+		}
+	}
+	useAttributes := func(o Object) {
+		for _, att := range o {
+			if mt, ok := att.Type.(*MediaTypeDefinition); ok {
+				use(mt, att.View)
+			}
+		}
+	}
+
+	useResponses(a.Responses)
+	a.IterateResources(func(r *ResourceDefinition) error {
+		if r.MediaType != "" {
+			use(a.MediaTypeWithIdentifier(r.MediaType), r.DefaultViewName)
+		}
+		useResponses(r.Responses)
+		r.IterateActions(func(ac *ActionDefinition) error {
+			useResponses(ac.Responses)
+			return nil
+		})
+		return nil
+	})
+	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		obj := objectOf(mt.AttributeDefinition)
+		useAttributes(obj)
+		for _, v := range mt.Views {
+			useAttributes(objectOf(v.AttributeDefinition))
+		}
+		for _, l := range mt.Links {
+			if att, ok := obj[l.Name]; ok {
+				if lmt, ok := att.Type.(*MediaTypeDefinition); ok {
+					use(lmt, l.View)
+				}
+			}
+		}
+		return nil
+	})
+
+	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		if mt.IsArray() {
+			// Collection views mirror the views of their elements.
+			return nil
+		}
+		names := make([]string, 0, len(mt.Views))
+		for n := range mt.Views {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if n != "default" && !used[mt][n] {
+				dslengine.ReportWarning(mt.Views[n], "view is never used to render a response, an attribute or a link")
+			}
+		}
+		return nil
+	})
+}
+
 func (a *APIDefinition) validateRoutes(verr *dslengine.ValidationErrors, routes []*routeInfo) {
 	for _, route := range routes {
 		for _, other := range routes {
@@ -202,6 +312,139 @@ func (a *APIDefinition) validateDocs(verr *dslengine.ValidationErrors) {
 	}
 }
 
+func (a *APIDefinition) validateProblemTypeBase(verr *dslengine.ValidationErrors) {
+	if a.ProblemTypeBase != "" {
+		if _, err := url.Parse(a.ProblemTypeBase); err != nil {
+			verr.Add(a, "invalid problem type base URI: %s", err)
+		}
+	}
+}
+
+// validateBasePath checks that the API base path is an absolute path.
+func (a *APIDefinition) validateBasePath(verr *dslengine.ValidationErrors) {
+	if a.BasePath != "" && !strings.HasPrefix(a.BasePath, "/") {
+		verr.Add(a, "invalid base path %#v, base path must start with /", a.BasePath)
+	}
+}
+
+// versionRegex matches valid resource versions.
+var versionRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// validateVersions checks that the API version media type pattern is a valid media type that
+// contains the version placeholder and that the versions of the resources that share the same
+// base path are valid and unique.
+func (a *APIDefinition) validateVersions(verr *dslengine.ValidationErrors) {
+	if a.VersionMedia != "" {
+		if strings.Count(a.VersionMedia, "{version}") != 1 {
+			verr.Add(a, "invalid version media %#v, must contain {version} exactly once", a.VersionMedia)
+		} else if _, _, err := mime.ParseMediaType(strings.Replace(a.VersionMedia, "{version}", "v1", 1)); err != nil {
+			verr.Add(a, "invalid version media %#v: %s", a.VersionMedia, err)
+		}
+	}
+	versions := make(map[string]*ResourceDefinition)
+	a.IterateResources(func(r *ResourceDefinition) error {
+		if r.Version == "" {
+			return nil
+		}
+		if a.VersionMedia == "" {
+			verr.Add(r, "resource version %#v requires the API to define VersionMedia", r.Version)
+		}
+		if !versionRegex.MatchString(r.Version) {
+			verr.Add(r, "invalid version %#v, must only contain letters, digits, '.', '-' and '_'", r.Version)
+		} else if _, err := parseVersion(r.Version); err != nil && r.usesVersionedFields() {
+			verr.Add(r, "version %#v cannot be compared with the versions set with SinceVersion or UntilVersion: %s", r.Version, err)
+		}
+		key := r.FullPath() + " " + strings.ToLower(r.Version)
+		if other, ok := versions[key]; ok {
+			verr.Add(r, "version %#v of %s is already defined by resource %#v", r.Version, r.FullPath(), other.Name)
+		} else {
+			versions[key] = r
+		}
+		return nil
+	})
+}
+
+// usesVersionedFields returns true if the payloads or the response media types of the resource
+// actions have top level fields limited to some versions with SinceVersion or UntilVersion.
+func (r *ResourceDefinition) usesVersionedFields() bool {
+	versioned := func(att *AttributeDefinition) bool {
+		if att == nil {
+			return false
+		}
+		if att.Type.IsArray() {
+			att = att.Type.ToArray().ElemType
+		}
+		for _, f := range att.Type.ToObject() {
+			if f.IsVersioned() {
+				return true
+			}
+		}
+		return false
+	}
+	for _, a := range r.Actions {
+		if a.Payload != nil && versioned(a.Payload.AttributeDefinition) {
+			return true
+		}
+		for _, resp := range a.Responses {
+			mt, ok := resp.Type.(*MediaTypeDefinition)
+			if !ok {
+				mt = Design.MediaTypeWithIdentifier(resp.MediaType)
+			}
+			if mt != nil && versioned(mt.AttributeDefinition) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateNamedEnums checks that the integer enums with named values that share the same Go type
+// define the same values and encode them the same way.
+func (a *APIDefinition) validateNamedEnums(verr *dslengine.ValidationErrors) {
+	enums := a.NamedEnums()
+	a.WalkAttributes(func(path string, att *AttributeDefinition) error {
+		name := att.EnumTypeName()
+		if name == "" {
+			return nil
+		}
+		first := enums[name]
+		if first == att {
+			return nil
+		}
+		if !reflect.DeepEqual(first.NamedEnumValues(), att.NamedEnumValues()) {
+			verr.Add(a, "enum type %s of %s is defined with different values elsewhere", name, path)
+		} else if first.EncodesEnumNames() != att.EncodesEnumNames() {
+			verr.Add(a, "enum type %s of %s is encoded %s but %s elsewhere", name, path,
+				enumEncoding(att), enumEncoding(first))
+		}
+		return nil
+	})
+}
+
+// enumEncoding describes how the values of the integer enum with named values defined by the
+// given attribute are encoded.
+func enumEncoding(att *AttributeDefinition) string {
+	if att.EncodesEnumNames() {
+		return "using the value names"
+	}
+	return "using the value numbers"
+}
+
+// validateFlagSets checks that the flag sets that share the same Go type define the same flags.
+func (a *APIDefinition) validateFlagSets(verr *dslengine.ValidationErrors) {
+	sets := a.FlagSets()
+	a.WalkAttributes(func(path string, att *AttributeDefinition) error {
+		name := att.FlagSetTypeName()
+		if name == "" {
+			return nil
+		}
+		if first := sets[name]; first != att && !reflect.DeepEqual(first.Flags, att.Flags) {
+			verr.Add(a, "flag set type %s of %s is defined with different flags elsewhere", name, path)
+		}
+		return nil
+	})
+}
+
 func (a *APIDefinition) validateOrigins(verr *dslengine.ValidationErrors) {
 	for _, origin := range a.Origins {
 		verr.Merge(origin.Validate())
@@ -228,9 +471,179 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	for _, origin := range r.Origins {
 		verr.Merge(origin.Validate())
 	}
+	if r.ErrorMediaType != "" {
+		r.validateErrorMedia(verr)
+	}
+	validateDefaultResponse(verr, r.DefaultResponse)
+	validateMaxBodySize(verr, r, r.Metadata)
+	validateCompression(verr, r, r.Metadata)
+	validateTags(verr, r, r.Metadata)
+	validateEnvelope(verr, r, r.Envelope)
+	r.validateEvents(verr)
+	r.validateTerraformResource(verr)
 	return verr.AsError()
 }
 
+// terraformNameRegex matches valid Terraform resource names.
+var terraformNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// validateTerraformResource checks that resources tagged with the "terraform:resource" metadata
+// have a valid Terraform resource name and define the actions that implement the resource
+// lifecycle: a create action with an object payload and a show action with an OK response.
+func (r *ResourceDefinition) validateTerraformResource(verr *dslengine.ValidationErrors) {
+	if _, ok := r.Metadata["terraform:resource"]; !ok {
+		return
+	}
+	name := r.TerraformResource()
+	if !terraformNameRegex.MatchString(name) {
+		verr.Add(r, "invalid terraform resource name %#v, name must only contain lowercase letters, digits and underscores and start with a letter", name)
+		return
+	}
+	for _, n := range TerraformLifecycle {
+		if _, ok := r.Actions[n]; !ok {
+			verr.Add(r, "terraform resource %#v requires a %#v action", name, n)
+		}
+	}
+	if a, ok := r.Actions["create"]; ok && (a.Payload == nil || a.Payload.ToObject() == nil) {
+		verr.Add(a, "terraform resource %#v requires the create action to have an object payload", name)
+	}
+	if a, ok := r.Actions["show"]; ok {
+		if _, ok := a.Responses[OK]; !ok {
+			verr.Add(a, "terraform resource %#v requires the show action to have an OK response", name)
+		}
+	}
+}
+
+// validateSunset checks that the sunset date of the deprecated action parses.
+func (a *ActionDefinition) validateSunset(verr *dslengine.ValidationErrors) {
+	v, ok := a.Metadata["http:deprecated:sunset"]
+	if !ok {
+		return
+	}
+	if len(v) != 1 {
+		verr.Add(a, "sunset requires exactly one date")
+		return
+	}
+	if _, err := parseSunset(v[0]); err != nil {
+		verr.Add(a, `invalid sunset date %q, date must use the "2006-01-02" or RFC 3339 format`, v[0])
+	}
+}
+
+// validateEchoUpdatedFields checks that actions that define the EchoUpdatedFields DSL use the JSON
+// Merge Patch format and that their success responses use media types that describe objects.
+func (a *ActionDefinition) validateEchoUpdatedFields(verr *dslengine.ValidationErrors) {
+	if !a.EchoesUpdatedFields() {
+		return
+	}
+	if a.PatchFormat() != MergePatch {
+		verr.Add(a, "echoing the updated fields requires the action to use the JSON Merge Patch format")
+	}
+	found := false
+	for _, r := range a.Responses {
+		if r.Status < 200 || r.Status >= 300 {
+			continue
+		}
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt == nil {
+			continue
+		}
+		found = true
+		if !mt.Type.IsObject() {
+			verr.Add(a, "echoing the updated fields requires the %s response media type to describe an object", r.Name)
+		}
+	}
+	if !found {
+		verr.Add(a, "echoing the updated fields requires a success response with a media type")
+	}
+}
+
+// validateEvents checks that the names of the events emitted by the resource actions are unique.
+func (r *ResourceDefinition) validateEvents(verr *dslengine.ValidationErrors) {
+	emitters := make(map[string]*ActionDefinition)
+	r.IterateActions(func(a *ActionDefinition) error {
+		for _, e := range a.Events {
+			if other, ok := emitters[e.Name]; ok {
+				if other == a {
+					verr.Add(a, "event %#v is emitted more than once", e.Name)
+				} else {
+					verr.Add(r, "event %#v is emitted by both actions %#v and %#v, event names must be unique", e.Name, other.Name, a.Name)
+				}
+				continue
+			}
+			emitters[e.Name] = a
+		}
+		return nil
+	})
+}
+
+func (r *ResourceDefinition) validateErrorMedia(verr *dslengine.ValidationErrors) {
+	mt := r.ErrorMedia()
+	if mt == nil {
+		verr.Add(r, "unknown default error response media type %#v", r.ErrorMediaType)
+		return
+	}
+	validateErrorFields(verr, r, "default error response media type", mt)
+}
+
+// validateDefaultResponse checks that the response defined with the DefaultResponse DSL uses an
+// error status and a media type that can receive the error fields.
+func validateDefaultResponse(verr *dslengine.ValidationErrors, resp *ResponseDefinition) {
+	if resp == nil {
+		return
+	}
+	verr.Merge(resp.Validate())
+	if resp.Status != 0 && resp.Status < 400 {
+		verr.Add(resp, "default response status must be an error status (4xx or 5xx), got %d", resp.Status)
+	}
+	if resp.MediaType == "" {
+		verr.Add(resp, "default response must define a media type")
+		return
+	}
+	mt := Design.MediaTypeWithIdentifier(resp.MediaType)
+	if mt == nil {
+		verr.Add(resp, "unknown default response media type %#v", resp.MediaType)
+		return
+	}
+	validateErrorFields(verr, resp, "default response media type", mt)
+}
+
+// validateErrorFields checks that the given media type is an object that defines the "message"
+// and "code" string attributes and that the optional "id" and "status" attributes have the string
+// and integer types respectively. desc describes the usage of the media type in error messages.
+func validateErrorFields(verr *dslengine.ValidationErrors, def dslengine.Definition, desc string, mt *MediaTypeDefinition) {
+	o := mt.Type.ToObject()
+	if o == nil {
+		verr.Add(def, "%s %#v must be an object", desc, mt.Identifier)
+		return
+	}
+	fields := []struct {
+		name     string
+		kind     Kind
+		required bool
+	}{
+		{"message", StringKind, true},
+		{"code", StringKind, true},
+		{"id", StringKind, false},
+		{"status", IntegerKind, false},
+	}
+	for _, f := range fields {
+		att, ok := o[f.name]
+		if !ok {
+			if f.required {
+				verr.Add(def, `%s %#v must define the "%s" attribute`, desc, mt.Identifier, f.name)
+			}
+			continue
+		}
+		if att.Type.Kind() != f.kind {
+			verr.Add(def, `attribute "%s" of %s %#v must be of type %s`,
+				f.name, desc, mt.Identifier, Primitive(f.kind).Name())
+		}
+	}
+}
+
 func (r *ResourceDefinition) validateActions(verr *dslengine.ValidationErrors) {
 	found := false
 	for _, a := range r.Actions {
@@ -238,6 +651,9 @@ func (r *ResourceDefinition) validateActions(verr *dslengine.ValidationErrors) {
 			found = true
 		}
 		verr.Merge(a.Validate())
+		if !a.hasErrorResponse() && r.CatchAllResponse() == nil {
+			dslengine.ReportWarning(a, "no error response defined, errors returned by the action are rendered with status 500")
+		}
 	}
 	for _, f := range r.FileServers {
 		verr.Merge(f.Validate())
@@ -258,6 +674,19 @@ func (r *ResourceDefinition) validateParent(verr *dslengine.ValidationErrors) {
 	}
 }
 
+// hasErrorResponse returns true if the action or its parent resource define a response with an
+// error status. Responses defined on the resource apply to all its actions.
+func (a *ActionDefinition) hasErrorResponse() bool {
+	for _, resps := range []map[string]*ResponseDefinition{a.Responses, a.Parent.Responses} {
+		for _, resp := range resps {
+			if resp.Status >= 400 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Validate makes sure the CORS definition origin is valid.
 func (cors *CORSDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -270,6 +699,11 @@ func (cors *CORSDefinition) Validate() *dslengine.ValidationErrors {
 			verr.Add(cors, "invalid origin, should be a valid regular expression")
 		}
 	}
+	for _, m := range cors.Methods {
+		if m != "*" && !isHTTPToken(m) {
+			verr.Add(cors, "invalid method %#v", m)
+		}
+	}
 	return verr
 }
 
@@ -329,6 +763,9 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if len(a.Routes) == 0 {
 		verr.Add(a, "No route defined for action")
 	}
+	for _, r := range a.Routes {
+		verr.Merge(r.Validate())
+	}
 	for i, r := range a.Responses {
 		for j, r2 := range a.Responses {
 			if i != j && r.Status == r2.Status {
@@ -341,6 +778,13 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 		}
 	}
 	verr.Merge(a.ValidateParams())
+	if a.Headers != nil {
+		for n, h := range a.Headers.Type.ToObject() {
+			if h.ArrayStyle() != "" {
+				a.validateArrayStyle(verr, "header", n, h)
+			}
+		}
+	}
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
 		if HasFile(a.Payload.Type) && a.PayloadMultipart != true {
@@ -350,8 +794,33 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
+	validateMaxBodySize(verr, a, a.Metadata)
+	validateCompression(verr, a, a.Metadata)
+	validateTags(verr, a, a.Metadata)
+	a.validateSurrogateKeys(verr)
+	a.validateResponseFromField(verr)
+	a.validateSignature(verr)
+	a.validateContentTypes(verr)
+	a.validateSparseFieldsets(verr)
+	a.validateSortFilter(verr)
+	a.validateBatch(verr)
+	a.validatePatchFormat(verr)
+	a.validateEchoUpdatedFields(verr)
+	a.validateSunset(verr)
+	a.validateEvents(verr)
+	a.validateDedupe(verr)
+	a.validateCacheable(verr)
+	a.validateStreamJSON(verr)
+	a.validatePageSize(verr)
+	if a.SkipsRequestBodyValidation() && a.Payload == nil {
+		verr.Add(a, "SkipRequestBodyValidation requires a payload")
+	}
 	if a.Params != nil {
 		for n, p := range a.Params.Type.ToObject() {
+			if p.IsDeepObject() {
+				// Validated by ValidateParams
+				continue
+			}
 			if p.Type.IsPrimitive() {
 				if HasFile(p.Type) {
 					verr.Add(a, "Param %s has an invalid type, action params cannot be a file", n)
@@ -373,102 +842,750 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
-// Validate checks the file server is properly initialized.
-func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
-	verr := new(dslengine.ValidationErrors)
-	if f.FilePath == "" {
-		verr.Add(f, "File server must have a non empty file path")
-	}
-	if f.RequestPath == "" {
-		verr.Add(f, "File server must have a non empty route path")
-	}
-	if f.Parent == nil {
-		verr.Add(f, "missing parent resource")
+// validateSurrogateKeys checks that the surrogate keys are string or integer attributes of the
+// media types of the action success responses.
+func (a *ActionDefinition) validateSurrogateKeys(verr *dslengine.ValidationErrors) {
+	keys := a.SurrogateKeys()
+	if len(keys) == 0 {
+		return
 	}
-	matches := WildcardRegex.FindAllString(f.RequestPath, -1)
-	if len(matches) == 1 {
-		if !strings.HasSuffix(f.RequestPath, matches[0]) {
-			verr.Add(f, "invalid request path %s, must end with a wildcard starting with *", f.RequestPath)
+	found := false
+	for _, r := range a.Responses {
+		if r.Status < 200 || r.Status >= 300 {
+			continue
+		}
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt == nil {
+			continue
+		}
+		found = true
+		obj := mt.Type.ToObject()
+		if mt.Type.IsArray() {
+			obj = mt.Type.ToArray().ElemType.Type.ToObject()
+		}
+		for _, k := range keys {
+			att, ok := obj[k]
+			if !ok {
+				verr.Add(a, "surrogate key %#v is not an attribute of the %s response media type", k, r.Name)
+				continue
+			}
+			if kind := att.Type.Kind(); kind != StringKind && kind != IntegerKind {
+				verr.Add(a, "surrogate key %#v must be a string or an integer, got %s", k, att.Type.Name())
+			}
 		}
 	}
-	if len(matches) > 2 {
-		verr.Add(f, "invalid request path, may only contain one wildcard")
+	if !found {
+		verr.Add(a, "surrogate keys require a success response with a media type")
 	}
-
-	return verr.AsError()
 }
 
-// ValidateParams checks the action parameters (make sure they have names, members and types).
-func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
-	verr := new(dslengine.ValidationErrors)
-	if a.Params == nil {
-		return nil
-	}
-	params, ok := a.Params.Type.(Object)
+// validateResponseFromField checks that the responses selected with the ResponseFromField DSL are
+// two responses of the action with different statuses that use the same media type and that the
+// attribute is a boolean attribute of the media type.
+func (a *ActionDefinition) validateResponseFromField(verr *dslengine.ValidationErrors) {
+	v, ok := a.Metadata["http:response:from-field"]
 	if !ok {
-		verr.Add(a, `"Params" field of action is not an object`)
+		return
 	}
-	var wcs []string
-	for _, r := range a.Routes {
-		rwcs := ExtractWildcards(r.FullPath())
-		for _, rwc := range rwcs {
-			found := false
-			for _, wc := range wcs {
-				if rwc == wc {
-					found = true
-					break
-				}
-			}
-			if !found {
-				wcs = append(wcs, rwc)
-			}
-		}
+	if len(v) != 3 {
+		verr.Add(a, "ResponseFromField must map exactly two responses, got %d", len(v)-1)
+		return
 	}
-	for n, p := range params {
-		if n == "" {
-			verr.Add(a, "action has parameter with no name")
-		} else if p == nil {
-			verr.Add(a, "definition of parameter %s cannot be nil", n)
-		} else if p.Type == nil {
-			verr.Add(a, "type of parameter %s cannot be nil", n)
+	field := v[0]
+	var mts [2]*MediaTypeDefinition
+	var views [2]string
+	for i, n := range v[1:] {
+		r, ok := a.Responses[n]
+		if !ok {
+			verr.Add(a, "response %s used by ResponseFromField is not defined", n)
+			return
 		}
-		if p.Type.Kind() == ObjectKind {
-			verr.Add(a, `parameter %s cannot be an object, only action payloads may be of type object`, n)
-		} else if p.Type.Kind() == HashKind {
-			verr.Add(a, `parameter %s cannot be a hash, only action payloads may be of type hash`, n)
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
 		}
-		ctx := fmt.Sprintf("parameter %s", n)
-		verr.Merge(p.Validate(ctx, a))
+		if mt == nil {
+			verr.Add(a, "response %s used by ResponseFromField must have a media type", n)
+			return
+		}
+		mts[i] = mt
+		views[i] = r.ViewName
 	}
-	for _, resp := range a.Responses {
-		verr.Merge(resp.Validate())
+	if a.Responses[v[1]].Status == a.Responses[v[2]].Status {
+		verr.Add(a, "ResponseFromField must map two different statuses")
+	}
+	if mts[0].Identifier != mts[1].Identifier || views[0] != views[1] {
+		verr.Add(a, "responses %s and %s used by ResponseFromField must use the same media type and view", v[1], v[2])
+		return
+	}
+	att, ok := mts[0].Type.ToObject()[field]
+	if !ok {
+		verr.Add(a, "response field %#v is not an attribute of the %s media type", field, mts[0].Identifier)
+		return
+	}
+	if att.Type.Kind() != BooleanKind {
+		verr.Add(a, "response field %#v must be a boolean, got %s", field, att.Type.Name())
 	}
-	return verr.AsError()
 }
 
-// validated keeps track of validated attributes to handle cyclical definitions.
-var validated = make(map[*AttributeDefinition]bool)
+// validateSignature checks that the header set with the VerifySignature DSL is a valid HTTP header
+// name and that the action requires a payload so that the signature is always verified.
+func (a *ActionDefinition) validateSignature(verr *dslengine.ValidationErrors) {
+	v, ok := a.Metadata["http:signature:header"]
+	if !ok {
+		return
+	}
+	if len(v) != 1 || !isHTTPToken(v[0]) {
+		verr.Add(a, "invalid signature header name %#v", strings.Join(v, ", "))
+	}
+	if a.Payload == nil || a.PayloadOptional {
+		verr.Add(a, "VerifySignature requires a payload that is not optional")
+	}
+}
 
-// Validate tests whether the attribute definition is consistent: required fields exist.
-// Since attributes are unaware of their context, additional context information can be provided
-// to be used in error messages.
-// The parent definition context is automatically added to error messages.
-func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
-	if validated[a] {
-		return nil
+// validateContentTypes checks that the media types set with the RequireContentType DSL are valid
+// media types without parameters.
+func (a *ActionDefinition) validateContentTypes(verr *dslengine.ValidationErrors) {
+	v, ok := a.Metadata["http:body:content-type"]
+	if !ok {
+		return
 	}
-	validated[a] = true
-	verr := new(dslengine.ValidationErrors)
-	if a.Type == nil {
-		verr.Add(parent, "attribute type is nil")
-		return verr
+	if len(v) == 0 {
+		verr.Add(a, "RequireContentType requires at least one media type")
 	}
-	if ctx != "" {
-		ctx += " - "
+	for _, t := range v {
+		mt, params, err := mime.ParseMediaType(t)
+		if err != nil || !strings.Contains(mt, "/") || len(params) > 0 {
+			verr.Add(a, "invalid content type %#v, content type must be a media type without parameters", t)
+		}
 	}
-	// If both Default and Enum are given, make sure the Default value is one of Enum values.
-	// TODO: We only do the default value and enum check just for primitive types.
-	// Issue 388 (https://github.com/goadesign/goa/issues/388) will address this for other types.
+}
+
+// validatePageSize checks that the page size limits set with the PageSize DSL are consistent and
+// that the "page_size" parameter they apply to is an integer.
+func (a *ActionDefinition) validatePageSize(verr *dslengine.ValidationErrors) {
+	if _, ok := a.Metadata["http:pagination:page-size"]; !ok {
+		return
+	}
+	def, max := a.PageSize()
+	if def <= 0 || def > max {
+		verr.Add(a, "invalid page size limits: default %d and maximum %d must verify 0 < default <= maximum", def, max)
+	}
+	var p *AttributeDefinition
+	if a.Params != nil {
+		p = a.Params.Type.ToObject()["page_size"]
+	}
+	if p == nil || p.Type.Kind() != IntegerKind {
+		verr.Add(a, "PageSize requires an integer page_size parameter")
+	}
+}
+
+// validateStreamJSON checks that actions that define the StreamJSON DSL have a success response
+// whose media type is a collection of objects.
+func (a *ActionDefinition) validateStreamJSON(verr *dslengine.ValidationErrors) {
+	if !a.StreamsJSON() {
+		return
+	}
+	for _, r := range a.Responses {
+		if r.Status < 200 || r.Status > 299 {
+			continue
+		}
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt != nil && mt.Type.IsArray() && mt.Type.ToArray().ElemType.Type.IsObject() {
+			return
+		}
+	}
+	verr.Add(a, "StreamJSON requires a success response whose media type is a collection of objects")
+}
+
+// validateDedupe checks that the window of actions that define the Dedupe DSL is a positive
+// duration.
+func (a *ActionDefinition) validateDedupe(verr *dslengine.ValidationErrors) {
+	v, ok := a.Metadata["http:dedupe:window"]
+	if !ok {
+		return
+	}
+	if len(v) != 1 {
+		verr.Add(a, "invalid dedupe window %#v", strings.Join(v, ", "))
+		return
+	}
+	d, err := time.ParseDuration(v[0])
+	if err != nil || d <= 0 {
+		verr.Add(a, "invalid dedupe window %#v, must be a positive duration such as \"10m\"", v[0])
+	}
+}
+
+// validateCacheable checks that actions that define the Cacheable DSL also define the
+// IdempotencyKey DSL, do not define the Dedupe DSL and are not routed to GET requests.
+func (a *ActionDefinition) validateCacheable(verr *dslengine.ValidationErrors) {
+	if !a.CachesResponses() {
+		return
+	}
+	if !a.RequiresIdempotencyKey() {
+		verr.Add(a, "Cacheable requires IdempotencyKey")
+	}
+	if a.DedupeWindow() > 0 {
+		verr.Add(a, "Cacheable cannot be combined with Dedupe")
+	}
+	for _, r := range a.Routes {
+		if r.Verb == "GET" {
+			verr.Add(a, "Cacheable cannot be used with GET routes, %s is routed to GET %s", a.Name, r.FullPath())
+		}
+	}
+}
+
+// validatePatchFormat checks that actions that define the PatchFormat DSL use a known format,
+// are only routed to PATCH requests and define a payload suitable for the format.
+func (a *ActionDefinition) validatePatchFormat(verr *dslengine.ValidationErrors) {
+	format := a.PatchFormat()
+	if format == "" {
+		return
+	}
+	if format != MergePatch && format != JSONPatch {
+		verr.Add(a, "invalid patch format %#v, must be %#v or %#v", format, MergePatch, JSONPatch)
+		return
+	}
+	for _, r := range a.Routes {
+		if r.Verb != "PATCH" {
+			verr.Add(a, "patch format cannot be used with %s routes, only PATCH", r.Verb)
+		}
+	}
+	switch format {
+	case MergePatch:
+		if a.Payload == nil || !a.Payload.Type.IsObject() {
+			verr.Add(a, "action using the JSON Merge Patch format must define an object payload")
+		}
+	case JSONPatch:
+		if a.Payload == nil {
+			break
+		}
+		if arr := a.Payload.Type.ToArray(); arr == nil || arr.ElemType.Type != JSONPatchOperation {
+			verr.Add(a, "action cannot define a payload when using the JSON Patch format")
+		}
+	}
+}
+
+// validateEvents checks that the events emitted by the action have a name and an object payload
+// type that is declared.
+func (a *ActionDefinition) validateEvents(verr *dslengine.ValidationErrors) {
+	for _, e := range a.Events {
+		if e.Name == "" {
+			verr.Add(a, "event name cannot be empty")
+			continue
+		}
+		if e.Type == nil {
+			if e.TypeName == "" {
+				verr.Add(e, "missing event payload type")
+			} else {
+				verr.Add(e, "event payload type %#v is not declared", e.TypeName)
+			}
+			continue
+		}
+		if !e.Type.IsObject() {
+			verr.Add(e, "event payload type %#v must be an object", e.TypeName)
+		}
+	}
+}
+
+// validateSparseFieldsets checks that the success responses of actions that define the
+// SparseFieldsets DSL use media types that describe objects or collections of objects and that
+// the "fields" parameter is an optional string.
+func (a *ActionDefinition) validateSparseFieldsets(verr *dslengine.ValidationErrors) {
+	if !a.HasSparseFieldsets() {
+		return
+	}
+	if a.Params != nil {
+		if fields, ok := a.Params.Type.ToObject()["fields"]; ok {
+			if fields.Type.Kind() != StringKind {
+				verr.Add(a, "sparse fieldsets parameter \"fields\" must be a string, got %s", fields.Type.Name())
+			}
+			if a.Params.IsRequired("fields") || fields.DefaultValue != nil {
+				verr.Add(a, "sparse fieldsets parameter \"fields\" must be optional and have no default value")
+			}
+		}
+	}
+	found := false
+	for _, r := range a.Responses {
+		if r.Status < 200 || r.Status >= 300 {
+			continue
+		}
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt == nil {
+			continue
+		}
+		found = true
+		dt := mt.Type
+		if dt.IsArray() {
+			dt = dt.ToArray().ElemType.Type
+		}
+		if !dt.IsObject() {
+			verr.Add(a, "sparse fieldsets require the %s response media type to describe an object or a collection of objects", r.Name)
+		}
+	}
+	if !found {
+		verr.Add(a, "sparse fieldsets require a success response with a media type")
+	}
+}
+
+// validateBatch checks that actions that define the Batch DSL have an object payload that is not
+// multipart, do not stream their responses and that the batch action name is not already used.
+func (a *ActionDefinition) validateBatch(verr *dslengine.ValidationErrors) {
+	name := a.BatchActionName()
+	if name == "" {
+		return
+	}
+	if a.Payload == nil {
+		verr.Add(a, "batch actions require the action to have a payload")
+	} else if !a.Payload.IsObject() {
+		verr.Add(a, "batch actions require the action payload to be an object")
+	}
+	if a.PayloadMultipart {
+		verr.Add(a, "batch actions cannot be derived from actions with multipart payloads")
+	}
+	if a.StreamsJSON() {
+		verr.Add(a, "batch actions cannot be derived from actions that stream their responses")
+	}
+	if a.WebSocket() {
+		verr.Add(a, "batch actions cannot be derived from websocket actions")
+	}
+	if a.Parent != nil {
+		if _, ok := a.Parent.Actions[name]; ok {
+			verr.Add(a, "batch action %#v conflicts with the action of the same name", name)
+		}
+	}
+}
+
+// validateSortFilter checks that the fields listed by the Sortable and Filterable DSLs are
+// attributes of the elements of the action success response media types and that the "sort"
+// parameter is a string.
+func (a *ActionDefinition) validateSortFilter(verr *dslengine.ValidationErrors) {
+	sortable, filterable := a.SortableFields(), a.FilterableFields()
+	if len(sortable) == 0 && len(filterable) == 0 {
+		return
+	}
+	for _, f := range sortable {
+		validateSortOptions(verr, a, f, a.Metadata["http:sortable:"+f])
+	}
+	if len(sortable) > 0 && a.Params != nil {
+		if sort, ok := a.Params.Type.ToObject()["sort"]; ok && sort.Type.Kind() != StringKind {
+			verr.Add(a, "sort parameter \"sort\" must be a string, got %s", sort.Type.Name())
+		}
+	}
+	found := false
+	for _, r := range a.Responses {
+		if r.Status < 200 || r.Status >= 300 {
+			continue
+		}
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt == nil {
+			continue
+		}
+		found = true
+		dt := mt.Type
+		if dt.IsArray() {
+			dt = dt.ToArray().ElemType.Type
+		}
+		obj := dt.ToObject()
+		for _, f := range sortable {
+			if _, ok := obj[f]; !ok {
+				verr.Add(a, "sortable field %#v is not an attribute of the %s response media type", f, r.Name)
+			}
+		}
+		for _, f := range filterable {
+			if _, ok := obj[f]; !ok {
+				verr.Add(a, "filterable field %#v is not an attribute of the %s response media type", f, r.Name)
+			}
+		}
+	}
+	if !found {
+		verr.Add(a, "sortable and filterable fields require a success response with a media type")
+	}
+}
+
+// validateSortOptions checks that the default sort options of the given sortable field set with
+// the SortableField DSL are valid and set at most one direction and one placement of null values.
+func validateSortOptions(verr *dslengine.ValidationErrors, a *ActionDefinition, field string, opts []string) {
+	var dirs, nulls int
+	for _, o := range opts {
+		switch o {
+		case "asc", "desc":
+			dirs++
+		case "nulls-first", "nulls-last":
+			nulls++
+		default:
+			verr.Add(a, "invalid sort option %#v of sortable field %#v, option must be one of \"asc\", \"desc\", \"nulls-first\" or \"nulls-last\"", o, field)
+		}
+	}
+	if dirs > 1 {
+		verr.Add(a, "sortable field %#v must define at most one default direction", field)
+	}
+	if nulls > 1 {
+		verr.Add(a, "sortable field %#v must define at most one placement of null values", field)
+	}
+}
+
+// isHTTPToken returns true if the given string is a valid HTTP token as defined by RFC 7230,
+// e.g. a valid header name.
+func isHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateMaxBodySize checks that the request body size limit set with the "http:body:max-size"
+// metadata, if any, is a valid size.
+func validateMaxBodySize(verr *dslengine.ValidationErrors, def dslengine.Definition, md dslengine.MetadataDefinition) {
+	v, ok := md["http:body:max-size"]
+	if !ok {
+		return
+	}
+	if len(v) != 1 {
+		verr.Add(def, "max body size must be set exactly once")
+		return
+	}
+	if _, err := ParseByteSize(v[0]); err != nil {
+		verr.Add(def, "invalid max body size: %s", err)
+	}
+}
+
+// validateEnvelope checks that the names of the body envelope fields, if any, are valid and
+// distinct JSON object keys.
+func validateEnvelope(verr *dslengine.ValidationErrors, def dslengine.Definition, env *EnvelopeDefinition) {
+	if env == nil {
+		return
+	}
+	validKey := func(k string) bool {
+		if strings.TrimSpace(k) == "" || !utf8.ValidString(k) {
+			return false
+		}
+		for _, c := range k {
+			if unicode.IsControl(c) {
+				return false
+			}
+		}
+		return true
+	}
+	if !validKey(env.DataField) {
+		verr.Add(def, "invalid body envelope data field %#v, must be a non-empty JSON key without control characters", env.DataField)
+	}
+	if env.MetaField != "" {
+		if !validKey(env.MetaField) {
+			verr.Add(def, "invalid body envelope meta field %#v, must be a JSON key without control characters", env.MetaField)
+		} else if env.MetaField == env.DataField {
+			verr.Add(def, "body envelope data and meta fields must be different, got %#v for both", env.DataField)
+		}
+	}
+}
+
+// validateOperationIDs checks that the operation identifiers set with the OperationID DSL are not
+// empty and unique across the API.
+func (a *APIDefinition) validateOperationIDs(verr *dslengine.ValidationErrors) {
+	ids := make(map[string]*ActionDefinition)
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			if _, ok := ac.Metadata["operation:id"]; !ok {
+				return nil
+			}
+			id := ac.OperationID()
+			if id == "" {
+				verr.Add(ac, "operation ID cannot be empty")
+				return nil
+			}
+			if other, ok := ids[id]; ok {
+				verr.Add(ac, "operation ID %#v is already used by action %#v of resource %#v", id, other.Name, other.Parent.Name)
+				return nil
+			}
+			ids[id] = ac
+			return nil
+		})
+	})
+}
+
+// validateFieldNaming checks that the policy set with the FieldNaming DSL is one of the supported
+// policies.
+func validateFieldNaming(verr *dslengine.ValidationErrors, def dslengine.Definition, md dslengine.MetadataDefinition) {
+	naming, ok := md["json:naming"]
+	if !ok {
+		return
+	}
+	for _, n := range naming {
+		switch n {
+		case CamelFieldNaming, SnakeFieldNaming, KebabFieldNaming:
+		default:
+			verr.Add(def, "invalid field naming policy %#v, must be one of \"camel\", \"snake\" or \"kebab\"", n)
+		}
+	}
+}
+
+// validateCompression checks that the content codings and threshold set with the Compress DSL are
+// valid.
+func validateCompression(verr *dslengine.ValidationErrors, def dslengine.Definition, md dslengine.MetadataDefinition) {
+	encodings, ok := md["http:compress"]
+	if !ok {
+		return
+	}
+	if len(encodings) == 0 {
+		verr.Add(def, "Compress requires at least one content coding")
+	}
+	for _, e := range encodings {
+		switch e {
+		case "gzip", "deflate", "br":
+		default:
+			verr.Add(def, "unsupported compression content coding %#v, must be one of \"gzip\", \"deflate\" or \"br\"", e)
+		}
+	}
+	if t := md["http:compress:threshold"]; len(t) > 0 {
+		if _, err := ParseByteSize(t[0]); err != nil {
+			verr.Add(def, "invalid compression threshold: %s", err)
+		}
+	}
+}
+
+// validateTags checks that the tags added with the Tags DSL have names.
+func validateTags(verr *dslengine.ValidationErrors, def dslengine.Definition, md dslengine.MetadataDefinition) {
+	tags, ok := md["swagger:tags"]
+	if !ok {
+		return
+	}
+	if len(tags) == 0 {
+		verr.Add(def, "Tags requires at least one tag name")
+	}
+	for _, t := range tags {
+		if strings.TrimSpace(t) == "" {
+			verr.Add(def, "tag names cannot be empty")
+		}
+	}
+}
+
+// Validate checks the file server is properly initialized.
+func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if f.FilePath == "" {
+		verr.Add(f, "File server must have a non empty file path")
+	}
+	if f.RequestPath == "" {
+		verr.Add(f, "File server must have a non empty route path")
+	}
+	if f.Parent == nil {
+		verr.Add(f, "missing parent resource")
+	}
+	matches := WildcardRegex.FindAllString(f.RequestPath, -1)
+	if len(matches) == 1 {
+		if !strings.HasSuffix(f.RequestPath, matches[0]) {
+			verr.Add(f, "invalid request path %s, must end with a wildcard starting with *", f.RequestPath)
+		}
+	}
+	if len(matches) > 2 {
+		verr.Add(f, "invalid request path, may only contain one wildcard")
+	}
+
+	return verr.AsError()
+}
+
+// ValidateParams checks the action parameters (make sure they have names, members and types).
+func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if a.Params == nil {
+		return nil
+	}
+	params, ok := a.Params.Type.(Object)
+	if !ok {
+		verr.Add(a, `"Params" field of action is not an object`)
+	}
+	var wcs []string
+	for _, r := range a.Routes {
+		rwcs := ExtractWildcards(r.FullPath())
+		for _, rwc := range rwcs {
+			found := false
+			for _, wc := range wcs {
+				if rwc == wc {
+					found = true
+					break
+				}
+			}
+			if !found {
+				wcs = append(wcs, rwc)
+				// Path parameters are always present: the generated code
+				// initializes them from the request path.
+				if p, ok := params[rwc]; ok && p != nil && p.DefaultValue != nil {
+					verr.Add(a, "path parameter %s of route %s %s cannot define a default value, path parameters are required",
+						rwc, r.Verb, r.FullPath())
+				}
+			}
+		}
+	}
+	for n, p := range params {
+		if n == "" {
+			verr.Add(a, "action has parameter with no name")
+		} else if p == nil {
+			verr.Add(a, "definition of parameter %s cannot be nil", n)
+		} else if p.Type == nil {
+			verr.Add(a, "type of parameter %s cannot be nil", n)
+		}
+		if p.IsDeepObject() {
+			a.validateDeepObjectParam(verr, n, p)
+		} else if p.Type.Kind() == ObjectKind {
+			verr.Add(a, `parameter %s cannot be an object, only action payloads may be of type object`, n)
+		} else if p.Type.Kind() == HashKind {
+			verr.Add(a, `parameter %s cannot be a hash, only action payloads may be of type hash`, n)
+		}
+		if p.ArrayStyle() != "" {
+			a.validateArrayStyle(verr, "parameter", n, p)
+			for _, wc := range wcs {
+				if wc == n {
+					verr.Add(a, "path parameter %s cannot define an array style, only query string parameters and headers may", n)
+				}
+			}
+		}
+		ctx := fmt.Sprintf("parameter %s", n)
+		verr.Merge(p.Validate(ctx, a))
+	}
+	for _, resp := range a.Responses {
+		verr.Merge(resp.Validate())
+	}
+	return verr.AsError()
+}
+
+// validateArrayStyle checks that the parameter or header with the given name that defines an array
+// style is an array and that the style is known.
+func (a *ActionDefinition) validateArrayStyle(verr *dslengine.ValidationErrors, kind, n string, p *AttributeDefinition) {
+	switch style := p.ArrayStyle(); style {
+	case "multi", "csv", "ssv", "pipes":
+	default:
+		verr.Add(a, `%s %s has an invalid array style %q, style must be one of "multi", "csv", "ssv" or "pipes"`, kind, n, style)
+	}
+	if !p.Type.IsArray() {
+		verr.Add(a, "%s %s defines an array style but is not an array", kind, n)
+	}
+}
+
+// validateDeepObjectParam checks that a parameter using the bracketed query string syntax is an
+// object whose fields are primitives or arrays of primitives.
+func (a *ActionDefinition) validateDeepObjectParam(verr *dslengine.ValidationErrors, n string, p *AttributeDefinition) {
+	if !p.Type.IsObject() {
+		verr.Add(a, `parameter %s uses the deep object style but is not an object`, n)
+		return
+	}
+	for fn, f := range p.Type.ToObject() {
+		t := f.Type
+		if t.IsArray() {
+			t = t.ToArray().ElemType.Type
+		}
+		if !t.IsPrimitive() || t.Kind() == FileKind {
+			verr.Add(a, `field %s of parameter %s must be a primitive or an array of primitives`, fn, n)
+		}
+	}
+}
+
+// validateDecimal checks that the decimal scale and precision validations apply to a string
+// attribute that uses the "decimal" format and that they are consistent with each other and with
+// the attribute default value.
+func (a *AttributeDefinition) validateDecimal(ctx string, parent dslengine.Definition, verr *dslengine.ValidationErrors) {
+	v := a.Validation
+	if a.Type.Kind() != StringKind || v.Format != "decimal" {
+		verr.Add(parent, `%sdecimal scale and precision require a string attribute with the "decimal" format`, ctx)
+		return
+	}
+	if v.DecimalScale != nil && *v.DecimalScale < 0 {
+		verr.Add(parent, "%sdecimal scale must be positive or zero, got %d", ctx, *v.DecimalScale)
+	}
+	if v.DecimalPrecision != nil && *v.DecimalPrecision < 1 {
+		verr.Add(parent, "%sdecimal precision must be strictly positive, got %d", ctx, *v.DecimalPrecision)
+	}
+	if v.DecimalScale != nil && v.DecimalPrecision != nil && *v.DecimalScale > *v.DecimalPrecision {
+		verr.Add(parent, "%sdecimal scale %d is greater than decimal precision %d", ctx, *v.DecimalScale, *v.DecimalPrecision)
+	}
+	def, ok := a.DefaultValue.(string)
+	if !ok {
+		return
+	}
+	if err := goa.ValidateFormat(goa.FormatDecimal, def); err != nil {
+		verr.Add(parent, "%sdefault value %#v is not a valid decimal value", ctx, def)
+		return
+	}
+	precision, scale := goa.DecimalDigits(def)
+	if v.DecimalScale != nil && scale > *v.DecimalScale {
+		verr.Add(parent, "%sdefault value %#v has more than %d fractional digits", ctx, def, *v.DecimalScale)
+	}
+	if v.DecimalPrecision != nil && precision > *v.DecimalPrecision {
+		verr.Add(parent, "%sdefault value %#v has more than %d digits", ctx, def, *v.DecimalPrecision)
+	}
+}
+
+// validateTransitions checks that the transitions apply to a string enum attribute and only use
+// values of the enum.
+func (a *AttributeDefinition) validateTransitions(ctx string, parent dslengine.Definition, verr *dslengine.ValidationErrors) {
+	if a.Type.Kind() != StringKind || a.Validation == nil || len(a.Validation.Values) == 0 {
+		verr.Add(parent, "%stransitions require a string attribute with an enum validation", ctx)
+		return
+	}
+	values := make(map[string]bool, len(a.Validation.Values))
+	for _, v := range a.Validation.Values {
+		if s, ok := v.(string); ok {
+			values[s] = true
+		}
+	}
+	seen := make(map[string]bool, len(a.Transitions))
+	for _, t := range a.Transitions {
+		if !values[t.From] {
+			verr.Add(parent, "%stransition state %#v is not one of the enum values", ctx, t.From)
+		}
+		if seen[t.From] {
+			verr.Add(parent, "%stransitions from %#v are defined more than once", ctx, t.From)
+		}
+		seen[t.From] = true
+		if len(t.Targets) == 0 {
+			verr.Add(parent, "%stransitions from %#v do not list any target state", ctx, t.From)
+		}
+		for _, to := range t.Targets {
+			if !values[to] {
+				verr.Add(parent, "%stransition state %#v is not one of the enum values", ctx, to)
+			}
+		}
+	}
+}
+
+// validated keeps track of validated attributes to handle cyclical definitions.
+var validated = make(map[*AttributeDefinition]bool)
+
+// Validate tests whether the attribute definition is consistent: required fields exist.
+// Since attributes are unaware of their context, additional context information can be provided
+// to be used in error messages.
+// The parent definition context is automatically added to error messages.
+func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	if validated[a] {
+		return nil
+	}
+	validated[a] = true
+	verr := new(dslengine.ValidationErrors)
+	if a.Type == nil {
+		verr.Add(parent, "attribute type is nil")
+		return verr
+	}
+	if ctx != "" {
+		ctx += " - "
+	}
+	// If both Default and Enum are given, make sure the Default value is one of Enum values.
+	// TODO: We only do the default value and enum check just for primitive types.
+	// Issue 388 (https://github.com/goadesign/goa/issues/388) will address this for other types.
 	if a.Type.IsPrimitive() && a.DefaultValue != nil && a.Validation != nil && a.Validation.Values != nil {
 		var found bool
 		for _, e := range a.Validation.Values {
@@ -481,6 +1598,110 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			verr.Add(parent, "%sdefault value %#v is not one of the accepted values: %#v", ctx, a.DefaultValue, a.Validation.Values)
 		}
 	}
+	// If the attribute uses a custom format make sure the default value is valid.
+	if a.Validation != nil && a.Validation.Format != "" {
+		if f := CustomFormat(a.Validation.Format); f != nil {
+			if def, ok := a.DefaultValue.(string); ok {
+				if err := f.Validate(def); err != nil {
+					verr.Add(parent, "%sdefault value %#v is not a valid %s value: %s", ctx, def, f.Name, err)
+				}
+			}
+		}
+	}
+	if a.Validation != nil && (a.Validation.DecimalScale != nil || a.Validation.DecimalPrecision != nil) {
+		a.validateDecimal(ctx, parent, verr)
+	}
+	if a.Transitions != nil {
+		a.validateTransitions(ctx, parent, verr)
+	}
+	if d := a.FakerDirective(); d != "" {
+		if fd, ok := fakerDirectives[d]; !ok {
+			verr.Add(parent, "%sunknown faker directive %#v, supported directives are %s", ctx, d, strings.Join(FakerDirectives(), ", "))
+		} else if !fd.appliesTo(a.Type.Kind()) {
+			verr.Add(parent, "%sfaker directive %#v cannot be used with attribute of type %s", ctx, d, a.Type.Name())
+		}
+	}
+	if _, ok := a.Metadata["encode:transform"]; ok {
+		if t := a.EncodeTransform(); !IsEncodeTransform(t) {
+			verr.Add(parent, "%sunknown encode transform %#v, supported transforms are %s", ctx, t, strings.Join(EncodeTransforms(), ", "))
+		} else if a.Type.Kind() != StringKind {
+			verr.Add(parent, "%sencode transform %#v cannot be used with attribute of type %s, encode transforms apply to strings", ctx, t, a.Type.Name())
+		}
+	}
+	if a.IsNullable() {
+		switch a.Type.Kind() {
+		case StringKind, IntegerKind, NumberKind, BooleanKind:
+		default:
+			verr.Add(parent, "%sattribute of type %s cannot be nullable", ctx, a.Type.Name())
+		}
+		if a.DefaultValue != nil {
+			verr.Add(parent, "%snullable attribute cannot have a default value", ctx)
+		}
+		if a.Validation != nil {
+			verr.Add(parent, "%snullable attribute cannot have validations", ctx)
+		}
+	}
+	var tagKeys []string
+	for key := range a.Metadata {
+		if strings.HasPrefix(key, "struct:tag:") {
+			tagKeys = append(tagKeys, key)
+		}
+	}
+	sort.Strings(tagKeys)
+	for _, key := range tagKeys {
+		if tag := key[11:]; !isStructTagKey(tag) {
+			verr.Add(parent, "%sinvalid struct tag key %#v", ctx, tag)
+		} else if v := strings.Join(a.Metadata[key], ","); strings.ContainsAny(v, "\"`") {
+			verr.Add(parent, "%sstruct tag %s value %#v cannot contain quotes or backquotes", ctx, tag, v)
+		}
+	}
+	validateFieldNaming(verr, parent, a.Metadata)
+	if marshaler, ok := a.Metadata["json:marshaler"]; ok {
+		if len(marshaler) == 0 || !qualifiedIdentifierRegex.MatchString(marshaler[0]) {
+			verr.Add(parent, "%sJSON marshaler must be a qualified Go type name such as \"mypkg.MyType\"", ctx)
+		}
+		if !a.Type.IsPrimitive() {
+			verr.Add(parent, "%sattribute of type %s cannot use a custom JSON marshaler", ctx, a.Type.Name())
+		} else if a.IsNullable() {
+			verr.Add(parent, "%snullable attribute cannot use a custom JSON marshaler", ctx)
+		}
+	}
+	if fn, ok := a.Metadata["compute:function"]; ok {
+		if len(fn) == 0 || !qualifiedIdentifierRegex.MatchString(fn[0]) {
+			verr.Add(parent, "%scompute function must be a qualified Go function name such as \"mypkg.MyFunc\"", ctx)
+		}
+		if !a.Type.IsPrimitive() {
+			verr.Add(parent, "%scomputed attribute of type %s must be of a primitive type matching the compute function return type", ctx, a.Type.Name())
+		} else if a.IsNullable() {
+			verr.Add(parent, "%snullable attribute cannot be computed", ctx)
+		}
+	}
+	if _, ok := a.Metadata["json:wire"]; ok {
+		a.validateWireType(verr, ctx, parent)
+	}
+	a.validateVersions(verr, ctx, parent)
+	if key, ok := a.Metadata["i18n:key"]; ok {
+		if len(key) == 0 || key[0] == "" {
+			verr.Add(parent, "%si18n key cannot be empty", ctx)
+		}
+	}
+	if names, ok := a.Metadata["enum:names"]; ok {
+		a.validateNamedEnum(verr, ctx, parent, names)
+	}
+	if a.Flags != nil {
+		a.validateFlagSet(verr, ctx, parent)
+	}
+	if a.Validation != nil {
+		a.validateItems(verr, ctx, parent)
+	}
+	if style := a.SQLPlaceholderStyle(); style != "" {
+		if style != "?" && style != "$" {
+			verr.Add(parent, `%sinvalid SQL placeholder style %#v, must be "?" or "$"`, ctx, style)
+		}
+		if !a.Type.IsObject() {
+			verr.Add(parent, "%sattribute of type %s cannot be SQL bindable", ctx, a.Type.Name())
+		}
+	}
 	o := a.Type.ToObject()
 	if o != nil {
 		for _, n := range a.AllRequired() {
@@ -493,8 +1714,53 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			}
 			if !found {
 				verr.Add(parent, `%srequired field "%s" does not exist`, ctx, n)
+			} else if o[n].IsNullable() {
+				verr.Add(parent, `%snullable field "%s" cannot be required`, ctx, n)
+			} else if o[n].IsVersioned() {
+				verr.Add(parent, `%srequired field "%s" cannot be limited to some versions, fields limited with SinceVersion or UntilVersion are omitted from the requests and responses of the other versions and must be optional`, ctx, n)
+			} else if o[n].RequiredScope() != "" {
+				verr.Add(parent, `%srequired field "%s" cannot require a scope, fields guarded by RequiredScope are omitted from the responses and must be optional`, ctx, n)
+			} else if o[n].DefaultValue != nil {
+				// Not an error to keep existing designs valid: the generated code sets the
+				// default value before validating so the field is never actually missing.
+				dslengine.ReportWarning(parent, `%srequired field "%s" has a default value which makes it optional, remove either the default value or the field from the required fields`, ctx, n)
+			}
+		}
+		if a.Validation != nil {
+			for _, b := range a.Validation.Bounds {
+				for _, n := range []string{b.Field, b.Other} {
+					if att, ok := o[n]; !ok {
+						verr.Add(parent, `%sbound field "%s" does not exist`, ctx, n)
+					} else if k := att.Type.Kind(); k != IntegerKind && k != NumberKind {
+						verr.Add(parent, `%sbound field "%s" must be numeric, field is of type %s`, ctx, n, att.Type.Name())
+					}
+				}
+				if b.Field == b.Other {
+					verr.Add(parent, `%sfield "%s" cannot be bounded by itself`, ctx, b.Field)
+				}
+			}
+			for _, g := range a.Validation.RequiredTogether {
+				a.validateRequiredTogether(verr, ctx, parent, g)
 			}
 		}
+		for n, att := range o {
+			if _, ok := att.Metadata["security:scope"]; ok && att.RequiredScope() == "" {
+				verr.Add(parent, `%sfield "%s" required scope cannot be empty`, ctx, n)
+			}
+			if src := att.DefaultFrom(); src != "" {
+				if srcAtt, ok := o[src]; !ok || src == n {
+					verr.Add(parent, `%sfield "%s" default source "%s" does not exist`, ctx, n, src)
+				} else if !identicalTypes(srcAtt, att) {
+					verr.Add(parent, `%sfield "%s" cannot default from field "%s", the fields must have identical types`,
+						ctx, n, src)
+				} else if att.DefaultValue != nil {
+					verr.Add(parent, `%sfield "%s" cannot define both a default value and a default source`, ctx, n)
+				} else if att.IsNullable() || srcAtt.IsNullable() {
+					verr.Add(parent, `%sfield "%s" cannot default from field "%s", nullable fields cannot have a default source`, ctx, n, src)
+				}
+			}
+		}
+		validateStorageHints(verr, ctx, parent, o)
 		for n, att := range o {
 			ctx = fmt.Sprintf("field %s", n)
 			verr.Merge(att.Validate(ctx, parent))
@@ -509,6 +1775,275 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 	return verr.AsError()
 }
 
+// identicalTypes returns true if the given attributes are generated using the same Go type:
+// primitives of the same kind, the same user or media type, or arrays, hashes and anonymous
+// objects whose elements, keys and fields have identical types.
+func identicalTypes(a, b *AttributeDefinition) bool {
+	if a.Type.Kind() != b.Type.Kind() {
+		return false
+	}
+	switch t := a.Type.(type) {
+	case *UserTypeDefinition:
+		return t.TypeName == b.Type.(*UserTypeDefinition).TypeName
+	case *MediaTypeDefinition:
+		return t.Identifier == b.Type.(*MediaTypeDefinition).Identifier
+	case *Array:
+		return identicalTypes(t.ElemType, b.Type.ToArray().ElemType)
+	case *Hash:
+		h := b.Type.ToHash()
+		return identicalTypes(t.KeyType, h.KeyType) && identicalTypes(t.ElemType, h.ElemType)
+	case Object:
+		o := b.Type.ToObject()
+		if len(t) != len(o) {
+			return false
+		}
+		for n, att := range t {
+			other, ok := o[n]
+			if !ok || !identicalTypes(att, other) || a.IsRequired(n) != b.IsRequired(n) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateRequiredTogether checks that the fields of a group defined with the RequiredTogether DSL
+// are distinct optional fields of the object and that there are at least two of them.
+func (a *AttributeDefinition) validateRequiredTogether(verr *dslengine.ValidationErrors, ctx string, parent dslengine.Definition, group []string) {
+	if len(group) < 2 {
+		verr.Add(parent, "%sfields required together must list at least two fields, got %v", ctx, group)
+	}
+	o := a.Type.ToObject()
+	seen := make(map[string]bool, len(group))
+	for _, n := range group {
+		if seen[n] {
+			verr.Add(parent, `%sfield "%s" is listed more than once in the fields required together`, ctx, n)
+			continue
+		}
+		seen[n] = true
+		if _, ok := o[n]; !ok {
+			verr.Add(parent, `%sfield "%s" required together with other fields does not exist`, ctx, n)
+		} else if a.IsRequired(n) {
+			verr.Add(parent, `%sfield "%s" required together with other fields must be optional`, ctx, n)
+		} else if a.HasDefaultValue(n) {
+			verr.Add(parent, `%sfield "%s" required together with other fields cannot have a default value`, ctx, n)
+		}
+	}
+}
+
+// validateVersions checks that the versions set with the SinceVersion and UntilVersion DSLs can be
+// compared and that the first version is not greater than the last one.
+func (a *AttributeDefinition) validateVersions(verr *dslengine.ValidationErrors, ctx string, parent dslengine.Definition) {
+	since, until := a.SinceVersion(), a.UntilVersion()
+	valid := true
+	for _, v := range []string{since, until} {
+		if v == "" {
+			continue
+		}
+		if _, err := parseVersion(v); err != nil {
+			verr.Add(parent, "%s%s", ctx, err)
+			valid = false
+		}
+	}
+	if valid && since != "" && until != "" {
+		if c, _ := CompareVersions(since, until); c > 0 {
+			verr.Add(parent, "%sfirst version %#v is greater than last version %#v", ctx, since, until)
+		}
+	}
+}
+
+// validateWireType checks that the attribute values can be converted to and from the wire type set
+// with the WireType DSL.
+func (a *AttributeDefinition) validateWireType(verr *dslengine.ValidationErrors, ctx string, parent dslengine.Definition) {
+	wire := a.WireType()
+	if wire != String.Name() {
+		verr.Add(parent, "%sunsupported wire type %#v, the only supported wire type is %#v", ctx, wire, String.Name())
+		return
+	}
+	switch a.Type.Kind() {
+	case IntegerKind, NumberKind, BooleanKind:
+	default:
+		verr.Add(parent, "%sattribute of type %s cannot use wire type %#v, only Integer, Number and Boolean attributes can", ctx, a.Type.Name(), wire)
+		return
+	}
+	if a.IsNullable() {
+		verr.Add(parent, "%snullable attribute cannot use a wire type", ctx)
+	}
+	if _, ok := a.Metadata["json:marshaler"]; ok {
+		verr.Add(parent, "%sattribute with a custom JSON marshaler cannot use a wire type", ctx)
+	}
+}
+
+// columnRegex matches valid database column and index names.
+var columnRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateStorageHints checks that the column names set with the Column DSL on the fields of the
+// given object are valid and unique, that the index names are valid and that at most one field is
+// the primary key.
+func validateStorageHints(verr *dslengine.ValidationErrors, ctx string, parent dslengine.Definition, o Object) {
+	names := make([]string, 0, len(o))
+	for n := range o {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var pk string
+	columns := make(map[string]string)
+	for _, n := range names {
+		att := o[n]
+		if att.IsPrimaryKey() {
+			if pk != "" {
+				verr.Add(parent, `%sfields "%s" and "%s" cannot both be primary keys, a type may define at most one primary key`, ctx, pk, n)
+			} else {
+				pk = n
+			}
+		}
+		if index, _ := att.StorageIndex(); index != "" && !columnRegex.MatchString(index) {
+			verr.Add(parent, `%sfield "%s" index name %#v is invalid, must start with a letter or underscore followed by letters, digits or underscores`, ctx, n, index)
+		}
+		column := att.StorageColumn()
+		if _, ok := att.Metadata["storage:column"]; ok && !columnRegex.MatchString(column) {
+			verr.Add(parent, `%sfield "%s" column name %#v is invalid, must start with a letter or underscore followed by letters, digits or underscores`, ctx, n, column)
+			continue
+		}
+		if column == "" {
+			column = n
+		}
+		if other, ok := columns[column]; ok {
+			verr.Add(parent, `%sfields "%s" and "%s" are both stored in column "%s", column names must be unique`, ctx, other, n, column)
+		} else {
+			columns[column] = n
+		}
+	}
+}
+
+// qualifiedIdentifierRegex matches qualified Go identifiers such as "mypkg.MyType".
+var qualifiedIdentifierRegex = regexp.MustCompile(`^[\pL_][\pL\pN_]*\.[\pL_][\pL\pN_]*$`)
+
+// isStructTagKey returns true if the given string is a valid Go struct tag key: a non-empty
+// sequence of non-control characters other than space, quote and colon.
+func isStructTagKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if r <= ' ' || r == 0x7f || r == ':' || r == '"' || r == '`' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateItems checks that the MinItems, MaxItems and UniqueItems validations are only used on
+// array attributes and that the elements of arrays with unique items are comparable.
+func (a *AttributeDefinition) validateItems(verr *dslengine.ValidationErrors, ctx string, parent dslengine.Definition) {
+	val := a.Validation
+	if val.MinItems == nil && val.MaxItems == nil && !val.UniqueItems {
+		return
+	}
+	arr := a.Type.ToArray()
+	if arr == nil {
+		verr.Add(parent, "%sattribute of type %s cannot have items validations, only arrays can", ctx, a.Type.Name())
+		return
+	}
+	if val.MinItems != nil && *val.MinItems < 0 {
+		verr.Add(parent, "%sminimum items %d cannot be negative", ctx, *val.MinItems)
+	}
+	if val.MinItems != nil && val.MaxItems != nil && *val.MinItems > *val.MaxItems {
+		verr.Add(parent, "%sminimum items %d is greater than maximum items %d", ctx, *val.MinItems, *val.MaxItems)
+	}
+	if val.UniqueItems {
+		switch arr.ElemType.Type.Kind() {
+		case BooleanKind, IntegerKind, NumberKind, StringKind, DateTimeKind, UUIDKind:
+		default:
+			verr.Add(parent, "%sarray of %s cannot have unique items, elements must be of a comparable primitive type", ctx, arr.ElemType.Type.Name())
+		}
+	}
+}
+
+// validateFlagSet checks that a flag set defined with the FlagSet DSL applies to a string
+// attribute without validations and that its flags are named, unique and powers of two.
+func (a *AttributeDefinition) validateFlagSet(verr *dslengine.ValidationErrors, ctx string, parent dslengine.Definition) {
+	if a.Type.Kind() != StringKind {
+		verr.Add(parent, "%sflag set requires a string attribute, got %s", ctx, a.Type.Name())
+		return
+	}
+	if len(a.Flags) == 0 {
+		verr.Add(parent, "%sflag set must define at least one flag", ctx)
+		return
+	}
+	if a.FlagSetTypeName() == "" {
+		verr.Add(parent, "%sflag set must be defined on an attribute", ctx)
+	}
+	if a.Validation != nil && !a.Validation.HasRequiredOnly() {
+		verr.Add(parent, "%sflag set cannot define validations", ctx)
+	}
+	if a.IsNullable() {
+		verr.Add(parent, "%sflag set cannot be nullable", ctx)
+	}
+	seenNames := make(map[string]bool)
+	seenValues := make(map[int]bool)
+	for _, f := range a.Flags {
+		if f.Name == "" || strings.ContainsAny(f.Name, ", ") {
+			verr.Add(parent, "%sinvalid flag name %#v, must be non empty and cannot contain commas or spaces", ctx, f.Name)
+		} else if seenNames[f.Name] {
+			verr.Add(parent, "%sduplicate flag name %#v", ctx, f.Name)
+		}
+		seenNames[f.Name] = true
+		if f.Value <= 0 || f.Value&(f.Value-1) != 0 {
+			verr.Add(parent, "%svalue %d of flag %#v is not a power of two", ctx, f.Value, f.Name)
+		} else if seenValues[f.Value] {
+			verr.Add(parent, "%sduplicate flag value %d", ctx, f.Value)
+		}
+		seenValues[f.Value] = true
+	}
+	if def, ok := a.DefaultValue.(string); ok {
+		if _, err := a.ParseFlags(def); err != nil {
+			verr.Add(parent, "%sdefault value %#v is not a valid flag set: %s", ctx, def, err)
+		}
+	}
+	if ex, ok := a.Example.(string); ok && ex != "-" && !a.ExampleSkipsValidation() {
+		if _, err := a.ParseFlags(ex); err != nil {
+			verr.Add(parent, "%sexample %#v is not a valid flag set: %s", ctx, ex, err)
+		}
+	}
+}
+
+// validateNamedEnum checks that the values of an integer enum defined with the EnumValues DSL
+// are all named and that both their names and numbers are unique.
+func (a *AttributeDefinition) validateNamedEnum(verr *dslengine.ValidationErrors, ctx string, parent dslengine.Definition, names []string) {
+	if a.Type.Kind() != IntegerKind {
+		verr.Add(parent, "%snamed enum values require an integer attribute, got %s", ctx, a.Type.Name())
+		return
+	}
+	if a.Validation == nil || len(a.Validation.Values) != len(names) {
+		verr.Add(parent, "%snamed enum cannot define values without a name", ctx)
+		return
+	}
+	if a.EnumTypeName() == "" {
+		verr.Add(parent, "%snamed enum values must be defined on an attribute", ctx)
+	}
+	if v, ok := a.Metadata["enum:encode"]; ok && len(v) > 0 && v[0] != "name" && v[0] != "number" {
+		verr.Add(parent, `%sinvalid enum encoding %#v, must be "name" or "number"`, ctx, v[0])
+	}
+	seenNames := make(map[string]bool)
+	seenValues := make(map[interface{}]bool)
+	for i, n := range names {
+		if n == "" {
+			verr.Add(parent, "%senum value name cannot be empty", ctx)
+		} else if _, err := strconv.Atoi(n); err == nil {
+			verr.Add(parent, "%senum value name %#v cannot be a number", ctx, n)
+		} else if seenNames[n] {
+			verr.Add(parent, "%sduplicate enum value name %#v", ctx, n)
+		}
+		seenNames[n] = true
+		if v := a.Validation.Values[i]; seenValues[v] {
+			verr.Add(parent, "%sduplicate enum value %v", ctx, v)
+		} else {
+			seenValues[v] = true
+		}
+	}
+}
+
 // Validate checks that the response definition is consistent: its status is set and the media
 // type definition if any is valid.
 func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
@@ -519,15 +2054,65 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
+	r.validateCookies(verr)
 	return verr.AsError()
 }
 
+// validateCookies checks that the cookies set by the response have valid names and options and
+// that their values come from string attributes of the response media type.
+func (r *ResponseDefinition) validateCookies(verr *dslengine.ValidationErrors) {
+	if len(r.Cookies) == 0 {
+		return
+	}
+	mt, ok := r.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = Design.MediaTypeWithIdentifier(r.MediaType)
+	}
+	if mt == nil || !mt.Type.IsObject() {
+		verr.Add(r, "Cookie requires a response whose media type is an object")
+		return
+	}
+	seen := make(map[string]bool)
+	for _, c := range r.Cookies {
+		if !isHTTPToken(c.Name) {
+			verr.Add(c, "invalid cookie name %#v, must be a token as defined by RFC 7230", c.Name)
+			continue
+		}
+		if seen[c.Name] {
+			verr.Add(c, "cookie %#v is defined multiple times", c.Name)
+		}
+		seen[c.Name] = true
+		if att, ok := mt.Type.ToObject()[c.Name]; !ok {
+			verr.Add(c, "cookie %#v has no corresponding attribute in media type %#v", c.Name, mt.Identifier)
+		} else if att.Type.Kind() != StringKind {
+			verr.Add(c, "cookie %#v value must be a string attribute, attribute is of type %s", c.Name, att.Type.Name())
+		}
+		if c.MaxAge < 0 {
+			verr.Add(c, "invalid cookie max age %d, max age cannot be negative", c.MaxAge)
+		}
+		switch c.SameSite {
+		case "", "Strict", "Lax":
+		case "None":
+			if !c.Secure {
+				verr.Add(c, `cookie with SameSite "None" must be Secure`)
+			}
+		default:
+			verr.Add(c, `invalid cookie SameSite value %#v, must be "Strict", "Lax" or "None"`, c.SameSite)
+		}
+	}
+}
+
 // Validate checks that the route definition is consistent: it has a parent.
 func (r *RouteDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
 	if r.Parent == nil {
 		verr.Add(r, "missing route parent action")
 	}
+	if !isHTTPToken(r.Verb) {
+		verr.Add(r, "invalid HTTP method %#v, must be a token as defined by RFC 7230", r.Verb)
+	} else if strings.ToUpper(r.Verb) != r.Verb {
+		verr.Add(r, "invalid HTTP method %#v, must be uppercase", r.Verb)
+	}
 	return verr.AsError()
 }
 
@@ -539,9 +2124,60 @@ func (u *UserTypeDefinition) Validate(ctx string, parent dslengine.Definition) *
 		verr.Add(parent, "%s - %s", ctx, "User type must have a name")
 	}
 	verr.Merge(u.AttributeDefinition.Validate(ctx, u))
+	u.validateExtend(verr)
 	return verr.AsError()
 }
 
+// validateExtend checks that the base type set with Extend exists and that the discriminator set
+// with Discriminator is a string attribute whose enum values cover the types that extend u. It also
+// checks that each type that extends u maps to discriminator values that no other type uses so
+// that decoding can dispatch on the discriminator unambiguously.
+func (u *UserTypeDefinition) validateExtend(verr *dslengine.ValidationErrors) {
+	if b, ok := u.Metadata["type:extends"]; ok && len(b) > 0 && u.Base() == nil {
+		verr.Add(u, "base type %#v not found", b[0])
+	}
+	d := u.Discriminator()
+	if d == "" {
+		return
+	}
+	att := u.ToObject()[d]
+	if att == nil {
+		verr.Add(u, "discriminator %#v is not an attribute of the type", d)
+		return
+	}
+	if att.Type.Kind() != StringKind || att.Validation == nil || len(att.Validation.Values) == 0 {
+		verr.Add(u, "discriminator %#v must be a string attribute with an enum validation", d)
+		return
+	}
+	for _, v := range u.Variants() {
+		found := false
+		for _, val := range att.Validation.Values {
+			if val == v.TypeName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			verr.Add(u, "enum values of discriminator %#v must include %#v", d, v.TypeName)
+		}
+	}
+	owners := make(map[interface{}]string)
+	for _, v := range u.Variants() {
+		vatt := v.ToObject()[d]
+		if vatt == nil || vatt.Validation == nil || len(vatt.Validation.Values) == 0 {
+			verr.Add(u, "type %#v does not map to a value of discriminator %#v", v.TypeName, d)
+			continue
+		}
+		for _, val := range vatt.Validation.Values {
+			if other, ok := owners[val]; ok {
+				verr.Add(u, "value %#v of discriminator %#v is used by both %#v and %#v", val, d, other, v.TypeName)
+				continue
+			}
+			owners[val] = v.TypeName
+		}
+	}
+}
+
 // Validate checks that the media type definition is consistent: its identifier is a valid media
 // type identifier.
 func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
@@ -582,6 +2218,7 @@ func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
 			}
 		}
 	}
+	m.validateComputedFields(verr, obj)
 	hasDefaultView := false
 	for n, v := range m.Views {
 		if n == "default" {
@@ -599,6 +2236,45 @@ func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
+// validateComputedFields checks that the computed attributes of the media type are not required,
+// that their arguments are attributes of the media type that are not computed and that the views
+// that render them also render their arguments.
+func (m *MediaTypeDefinition) validateComputedFields(verr *dslengine.ValidationErrors, obj Object) {
+	if m.IsArray() {
+		return
+	}
+	viewNames := make([]string, 0, len(m.Views))
+	for n := range m.Views {
+		viewNames = append(viewNames, n)
+	}
+	sort.Strings(viewNames)
+	for _, n := range m.ComputedFields() {
+		if m.IsRequired(n) {
+			verr.Add(m, "computed attribute %#v cannot be required", n)
+		}
+		args := obj[n].Metadata["compute:args"]
+		for _, arg := range args {
+			att, ok := obj[arg]
+			if !ok {
+				verr.Add(m, "argument %#v of computed attribute %#v is not an attribute of the media type", arg, n)
+			} else if att.IsComputed() {
+				verr.Add(m, "argument %#v of computed attribute %#v cannot be computed", arg, n)
+			}
+		}
+		for _, vn := range viewNames {
+			vobj := objectOf(m.Views[vn].AttributeDefinition)
+			if _, ok := vobj[n]; !ok {
+				continue
+			}
+			for _, arg := range args {
+				if _, ok := vobj[arg]; !ok {
+					verr.Add(m, "view %#v renders computed attribute %#v but not its argument %#v", vn, n, arg)
+				}
+			}
+		}
+	}
+}
+
 // Validate checks that the link definition is consistent: it has a media type or the name of an
 // attribute part of the parent media type.
 func (l *LinkDefinition) Validate() *dslengine.ValidationErrors {
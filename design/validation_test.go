@@ -1,10 +1,12 @@
 package design_test
 
 import (
+	"errors"
 	"go/build"
 	"io/ioutil"
 	"os"
 	"path"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -83,6 +85,166 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with a custom format and a default value", func() {
+			var def string
+
+			BeforeEach(func() {
+				RegisterFormat("isbn", func(v string) error {
+					if len(v) != 13 {
+						return errors.New("must contain 13 digits")
+					}
+					return nil
+				}, "")
+				def = "978030640615"
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Format("isbn")
+						Default(def)
+					})
+				}
+			})
+
+			It("produces an error if the default value is invalid", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - default value "978030640615" is not a valid isbn value: must contain 13 digits`))
+			})
+
+			Context("with a valid default value", func() {
+				BeforeEach(func() {
+					def = "9780306406157"
+				})
+
+				It("does not produce an error", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				})
+			})
+		})
+
+		Context("with transitions", func() {
+			var target string
+
+			BeforeEach(func() {
+				target = "active"
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Enum("pending", "active", "closed")
+						Transitions(func() {
+							From("pending").To(target)
+							From("active").To("closed")
+						})
+					})
+				}
+			})
+
+			It("does not produce an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+
+			Context("using a state that is not an enum value", func() {
+				BeforeEach(func() {
+					target = "archived"
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(Equal(
+						`type "bar": field attName - transition state "archived" is not one of the enum values`))
+				})
+			})
+
+			Context("on an attribute without enum validation", func() {
+				BeforeEach(func() {
+					dsl = func() {
+						Attribute(attName, String, func() {
+							Transitions(func() {
+								From("pending").To("active")
+							})
+						})
+					}
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(Equal(
+						`type "bar": field attName - transitions require a string attribute with an enum validation`))
+				})
+			})
+		})
+
+		Context("with decimal scale and precision validations", func() {
+			var format, def string
+			var scale int
+
+			BeforeEach(func() {
+				format = "decimal"
+				def = "12345678.90"
+				scale = 2
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Format(format)
+						DecimalScale(scale)
+						DecimalPrecision(10)
+						Default(def)
+					})
+				}
+			})
+
+			It("accepts a default value at the boundary", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+
+			Context("with a default value with too many fractional digits", func() {
+				BeforeEach(func() {
+					def = "1.234"
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(Equal(
+						`type "bar": field attName - default value "1.234" has more than 2 fractional digits`))
+				})
+			})
+
+			Context("with a default value with too many digits", func() {
+				BeforeEach(func() {
+					def = "123456789.12"
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(Equal(
+						`type "bar": field attName - default value "123456789.12" has more than 10 digits`))
+				})
+			})
+
+			Context("with a scale greater than the precision", func() {
+				BeforeEach(func() {
+					scale = 11
+					def = "1.5"
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(Equal(
+						`type "bar": field attName - decimal scale 11 is greater than decimal precision 10`))
+				})
+			})
+
+			Context("without the decimal format", func() {
+				BeforeEach(func() {
+					format = "email"
+					def = "foo@bar.com"
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(Equal(
+						`type "bar": field attName - decimal scale and precision require a string attribute with the "decimal" format`))
+				})
+			})
+		})
+
 		Context("with a valid format validation", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -113,6 +275,73 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with items validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, ArrayOf(String), func() {
+						MinItems(1)
+						MaxItems(3)
+						UniqueItems()
+					})
+				}
+			})
+
+			It("records the validations", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(att.Validation).ShouldNot(BeNil())
+				Ω(*att.Validation.MinItems).Should(Equal(1))
+				Ω(*att.Validation.MaxItems).Should(Equal(3))
+				Ω(att.Validation.UniqueItems).Should(BeTrue())
+			})
+		})
+
+		Context("with items validations on an attribute that is not an array", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						MinItems(1)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(
+					"attribute of type string cannot have items validations, only arrays can"))
+			})
+		})
+
+		Context("with a minimum items greater than the maximum items", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, ArrayOf(String), func() {
+						MinItems(3)
+						MaxItems(1)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("minimum items 3 is greater than maximum items 1"))
+			})
+		})
+
+		Context("with unique items on an array of non comparable elements", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, ArrayOf(HashOf(String, String)), func() {
+						UniqueItems()
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot have unique items"))
+			})
+		})
+
 		Context("with a valid pattern validation", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -291,130 +520,3674 @@ var _ = Describe("Validation", func() {
 				Ω(Design.Types["bar"].Validation.Required).Should(Equal([]string{attName}))
 			})
 		})
-	})
-
-	Context("actions with different http methods", func() {
-		It("should be valid because methods are different", func() {
-			dslengine.Reset()
-
-			Resource("one", func() {
-				Action("first", func() {
-					Routing(GET("/:first"))
-				})
-				Action("second", func() {
-					Routing(DELETE("/:second"))
-				})
+		Context("with a nullable attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Nullable()
+					})
+				}
 			})
 
-			dslengine.Run()
-
-			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			It("records the nullability", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(att.IsNullable()).Should(BeTrue())
+			})
 		})
-	})
 
-	Context("with an action", func() {
-		var dsl func()
+		Context("with a nullable attribute of an unsupported type", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, DateTime, func() {
+						Nullable()
+					})
+				}
+			})
 
-		JustBeforeEach(func() {
-			dslengine.Reset()
-			Resource("foo", func() {
-				Action("bar", func() {
-					Routing(GET("/buz"))
-					dsl()
-				})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot be nullable"))
 			})
-			dslengine.Run()
 		})
 
-		Context("which has a file type param", func() {
+		Context("with a required nullable attribute", func() {
 			BeforeEach(func() {
 				dsl = func() {
-					Params(func() {
-						Param("file", File)
+					Attribute(attName, String, func() {
+						Nullable()
 					})
+					Required(attName)
 				}
 			})
 
 			It("produces an error", func() {
-				Ω(dslengine.Errors.Error()).Should(Equal(
-					`resource "foo" action "bar": Param file has an invalid type, action params cannot be a file`,
-				))
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot be required"))
 			})
 		})
 
-		Context("which has a file array type param", func() {
+		Context("with a nullable attribute with validations", func() {
 			BeforeEach(func() {
 				dsl = func() {
-					Params(func() {
-						Param("file_array", ArrayOf(File))
+					Attribute(attName, String, func() {
+						Nullable()
+						MinLength(1)
 					})
 				}
 			})
 
 			It("produces an error", func() {
-				Ω(dslengine.Errors.Error()).Should(Equal(
-					`resource "foo" action "bar": Param file_array has an invalid type, action params cannot be a file array`,
-				))
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("nullable attribute cannot have validations"))
 			})
 		})
 
-		Context("which has a payload contains a file", func() {
-			dslengine.Reset()
-			var payload = Type("qux", func() {
-				Attribute("file", File)
-				Required("file")
+		Context("with a default source", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute("source", String)
+					Attribute(attName, String, func() {
+						DefaultFrom("source")
+					})
+				}
 			})
-			dslengine.Run()
 
+			It("records the default source", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(att.DefaultFrom()).Should(Equal("source"))
+			})
+		})
+
+		Context("with a default source that does not exist", func() {
 			BeforeEach(func() {
 				dsl = func() {
-					Payload(payload)
+					Attribute(attName, String, func() {
+						DefaultFrom("missing")
+					})
 				}
 			})
 
 			It("produces an error", func() {
-				Ω(dslengine.Errors.Error()).Should(Equal(
-					`resource "foo" action "bar": Payload qux contains an invalid type, action payloads cannot contain a file`,
-				))
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`default source "missing" does not exist`))
 			})
+		})
 
-			Context("and multipart form", func() {
-				BeforeEach(func() {
-					dsl = func() {
-						Payload(payload)
-						MultipartForm()
-					}
-				})
+		Context("with a default source of an incompatible kind", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute("source", Integer)
+					Attribute(attName, String, func() {
+						DefaultFrom("source")
+					})
+				}
+			})
 
-				It("produces no error", func() {
-					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
-				})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot default from"))
 			})
 		})
 
-		Context("which has a response contains a file", func() {
+		Context("with a default source of a different element type", func() {
 			BeforeEach(func() {
-				dslengine.Reset()
-				var response = MediaType("application/vnd.goa.example", func() {
-					TypeName("quux")
-					Attributes(func() {
-						Attribute("file", File)
-						Required("file")
-					})
-					View("default", func() {
-						Attribute("file")
+				dsl = func() {
+					Attribute("source", ArrayOf(String))
+					Attribute(attName, ArrayOf(Integer), func() {
+						DefaultFrom("source")
 					})
-				})
-				dslengine.Run()
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`cannot default from field "source", the fields must have identical types`))
+			})
+		})
+
+		Context("with a default source of a different hash key type", func() {
+			BeforeEach(func() {
 				dsl = func() {
-					Response(OK, response)
+					Attribute("source", HashOf(String, Integer))
+					Attribute(attName, HashOf(Integer, Integer), func() {
+						DefaultFrom("source")
+					})
 				}
 			})
 
 			It("produces an error", func() {
-				Ω(dslengine.Errors.Error()).Should(Equal(
-					`resource "foo" action "bar": Response OK contains an invalid type, action responses cannot contain a file`,
-				))
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("the fields must have identical types"))
+			})
+		})
+
+		Context("with a default source of a different user type", func() {
+			BeforeEach(func() {
+				newType := func(name string) *UserTypeDefinition {
+					return &UserTypeDefinition{
+						TypeName:            name,
+						AttributeDefinition: &AttributeDefinition{Type: Object{"name": {Type: String}}},
+					}
+				}
+				dsl = func() {
+					Attribute("source", newType("Source"))
+					Attribute(attName, newType("Target"), func() {
+						DefaultFrom("source")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("the fields must have identical types"))
+			})
+		})
+
+		Context("with a default source of the same array type", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute("source", ArrayOf(String))
+					Attribute(attName, ArrayOf(String), func() {
+						DefaultFrom("source")
+					})
+				}
+			})
+
+			It("does not produce an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with both a default value and a default source", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute("source", String)
+					Attribute(attName, String, func() {
+						Default("foo")
+						DefaultFrom("source")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("both a default value and a default source"))
+			})
+		})
+
+		Context("with a required attribute with a default value", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Default("foo")
+					})
+					Required(attName)
+				}
+			})
+
+			It("produces a warning naming the attribute", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(ConsistOf(
+					`type "bar": required field "attName" has a default value which makes it optional, remove either the default value or the field from the required fields`,
+				))
+			})
+		})
+
+		Context("with a nested required attribute with a default value", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Attribute("child", Integer, func() {
+							Default(1)
+						})
+						Required("child")
+					})
+				}
+			})
+
+			It("produces a warning naming the attribute path", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(HaveLen(1))
+				Ω(dslengine.Warnings[0]).Should(ContainSubstring(`field attName - required field "child" has a default value`))
+			})
+		})
+
+		Context("with a required attribute without a default value", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String)
+					Required(attName)
+				}
+			})
+
+			It("does not produce a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(BeEmpty())
+			})
+		})
+
+		Context("with an optional attribute with a default value", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Default("foo")
+					})
+				}
+			})
+
+			It("does not produce a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("actions with different http methods", func() {
+		It("should be valid because methods are different", func() {
+			dslengine.Reset()
+
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/:first"))
+				})
+				Action("second", func() {
+					Routing(DELETE("/:second"))
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with actions using custom http methods", func() {
+		var verb string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("one", func() {
+				Action("find", func() {
+					Routing(Method(verb, "/:find"))
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid method", func() {
+			BeforeEach(func() {
+				verb = "PROPFIND"
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with a method that is not a token", func() {
+			BeforeEach(func() {
+				verb = "PROP FIND"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid HTTP method "PROP FIND"`))
+			})
+		})
+
+		Context("with a lowercase method", func() {
+			BeforeEach(func() {
+				verb = "propfind"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("must be uppercase"))
+			})
+		})
+	})
+
+	Context("with an action", func() {
+		var dsl func()
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/buz"))
+					dsl()
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("which has a file type param", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() {
+						Param("file", File)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": Param file has an invalid type, action params cannot be a file`,
+				))
+			})
+		})
+
+		Context("which has a file array type param", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() {
+						Param("file_array", ArrayOf(File))
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": Param file_array has an invalid type, action params cannot be a file array`,
+				))
+			})
+		})
+
+		Context("which has a deep object param", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() {
+						Param("filter", func() {
+							DeepObject()
+							Attribute("status", String)
+							Attribute("ids", ArrayOf(Integer))
+						})
+					})
+				}
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("which has a deep object param that is not an object", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() {
+						Param("filter", String, func() {
+							DeepObject()
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": parameter filter uses the deep object style but is not an object`,
+				))
+			})
+		})
+
+		Context("which has a deep object param with an object field", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() {
+						Param("filter", func() {
+							DeepObject()
+							Attribute("owner", func() {
+								Attribute("name", String)
+							})
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": field owner of parameter filter must be a primitive or an array of primitives`,
+				))
+			})
+		})
+
+		Context("which has an array param with a style", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() {
+						Param("ids", ArrayOf(Integer), func() {
+							ArrayParamStyle("csv")
+						})
+					})
+					Headers(func() {
+						Header("X-Ids", ArrayOf(String), func() {
+							ArrayParamStyle("pipes")
+						})
+					})
+				}
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("which has an array param with an unknown style", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() {
+						Param("ids", ArrayOf(Integer), func() {
+							ArrayParamStyle("tsv")
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": parameter ids has an invalid array style "tsv", style must be one of "multi", "csv", "ssv" or "pipes"`,
+				))
+			})
+		})
+
+		Context("which has a header with a style that is not an array", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Headers(func() {
+						Header("X-Id", String, func() {
+							ArrayParamStyle("csv")
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": header X-Id defines an array style but is not an array`,
+				))
+			})
+		})
+
+		Context("which has a path param with a style", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/:ids"))
+					Params(func() {
+						Param("ids", ArrayOf(Integer), func() {
+							ArrayParamStyle("csv")
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": path parameter ids cannot define an array style, only query string parameters and headers may`,
+				))
+			})
+		})
+
+		Context("which has a payload contains a file", func() {
+			dslengine.Reset()
+			var payload = Type("qux", func() {
+				Attribute("file", File)
+				Required("file")
+			})
+			dslengine.Run()
+
+			BeforeEach(func() {
+				dsl = func() {
+					Payload(payload)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": Payload qux contains an invalid type, action payloads cannot contain a file`,
+				))
+			})
+
+			Context("and multipart form", func() {
+				BeforeEach(func() {
+					dsl = func() {
+						Payload(payload)
+						MultipartForm()
+					}
+				})
+
+				It("produces no error", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				})
+			})
+		})
+
+		Context("which has a response contains a file", func() {
+			BeforeEach(func() {
+				dslengine.Reset()
+				var response = MediaType("application/vnd.goa.example", func() {
+					TypeName("quux")
+					Attributes(func() {
+						Attribute("file", File)
+						Required("file")
+					})
+					View("default", func() {
+						Attribute("file")
+					})
+				})
+				dslengine.Run()
+				dsl = func() {
+					Response(OK, response)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": Response OK contains an invalid type, action responses cannot contain a file`,
+				))
+			})
+		})
+	})
+
+	Context("with actions that may return errors", func() {
+		var resDSL, actionDSL func()
+		var catchAll bool
+
+		BeforeEach(func() {
+			catchAll = false
+			resDSL = func() {}
+			actionDSL = func() {}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			fault := MediaType("application/vnd.fault", func() {
+				Attributes(func() {
+					Attribute("message", String)
+					Attribute("code", String)
+					Required("message", "code")
+				})
+				View("default", func() {
+					Attribute("message")
+					Attribute("code")
+				})
+			})
+			if catchAll {
+				API("test", func() {
+					DefaultResponse(InternalServerError, func() { Media(fault) })
+				})
+			}
+			Resource("foo", func() {
+				resDSL()
+				Action("bar", func() {
+					Routing(GET("/bar"))
+					Response(OK)
+					actionDSL()
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("which do not define error responses", func() {
+			It("produces a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(ConsistOf(
+					`resource "foo" action "bar": no error response defined, errors returned by the action are rendered with status 500`,
+				))
+			})
+		})
+
+		Context("which define error responses", func() {
+			BeforeEach(func() {
+				actionDSL = func() {
+					Response(NotFound)
+					Response(Conflict)
+				}
+			})
+
+			It("does not produce a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(BeEmpty())
+			})
+		})
+
+		Context("whose resource defines a default error response", func() {
+			BeforeEach(func() {
+				resDSL = func() {
+					Response(NotFound)
+				}
+			})
+
+			It("does not produce a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(BeEmpty())
+			})
+		})
+
+		Context("whose API defines a catch-all response", func() {
+			BeforeEach(func() {
+				catchAll = true
+			})
+
+			It("does not produce a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("with a media type that defines multiple views", func() {
+		var useTiny bool
+
+		BeforeEach(func() {
+			useTiny = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				Response(NotFound)
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Response(OK, bottle)
+				})
+				if useTiny {
+					Action("list", func() {
+						Routing(GET(""))
+						Response(OK, func() {
+							Media(bottle, "tiny")
+						})
+					})
+				}
+			})
+			dslengine.Run()
+		})
+
+		Context("with a view that is never used", func() {
+			It("produces a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(ConsistOf(
+					`view "tiny" of type "Bottle": view is never used to render a response, an attribute or a link`,
+				))
+			})
+		})
+
+		Context("with all views used", func() {
+			BeforeEach(func() {
+				useTiny = true
+			})
+
+			It("does not produce a warning", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(dslengine.Warnings).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("with a media type that defines computed attributes", func() {
+		var computeFunc string
+		var required bool
+		var tinyView bool
+
+		BeforeEach(func() {
+			computeFunc = "names.FullName"
+			required = false
+			tinyView = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			MediaType("application/vnd.person", func() {
+				Attributes(func() {
+					Attribute("first", String)
+					Attribute("last", String)
+					Computed("full_name", String, func() {
+						Metadata("compute:function", computeFunc, "github.com/me/names")
+						Metadata("compute:args", "first", "last")
+					})
+					if required {
+						Required("full_name")
+					}
+				})
+				View("default", func() {
+					Attribute("first")
+					Attribute("last")
+					Attribute("full_name")
+				})
+				if tinyView {
+					View("tiny", func() {
+						Attribute("full_name")
+					})
+				}
+			})
+			dslengine.Run()
+		})
+
+		It("does not produce an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			mt := Design.MediaTypeWithIdentifier("application/vnd.person")
+			Ω(mt.ComputedFields()).Should(Equal([]string{"full_name"}))
+			Ω(mt.Type.ToObject()["full_name"].IsReadOnly()).Should(BeTrue())
+		})
+
+		Context("with a compute function that is not a qualified identifier", func() {
+			BeforeEach(func() {
+				computeFunc = "FullName"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("compute function must be a qualified Go function name"))
+			})
+		})
+
+		Context("that is required", func() {
+			BeforeEach(func() {
+				required = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`computed attribute "full_name" cannot be required`))
+			})
+		})
+
+		Context("with a view that does not render the arguments", func() {
+			BeforeEach(func() {
+				tinyView = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`view "tiny" renders computed attribute "full_name" but not its argument "first"`))
+			})
+		})
+	})
+
+	Context("with a response selected from a field", func() {
+		var fieldType DataType
+		var responses []string
+
+		BeforeEach(func() {
+			fieldType = Boolean
+			responses = []string{Created, OK}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("created", fieldType)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("created")
+				})
+			})
+			Resource("bottle", func() {
+				Action("upsert", func() {
+					Routing(PUT("/:id"))
+					Response(Created, bottle)
+					Response(OK, bottle)
+					Response(NoContent)
+					ResponseFromField("created", responses...)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces no error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			field, whenTrue, whenFalse := Design.Resources["bottle"].Actions["upsert"].ResponseFromField()
+			Ω(field).Should(Equal("created"))
+			Ω(whenTrue).Should(Equal(Created))
+			Ω(whenFalse).Should(Equal(OK))
+		})
+
+		Context("with a field that is not a boolean", func() {
+			BeforeEach(func() {
+				fieldType = String
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`response field "created" must be a boolean, got string`))
+			})
+		})
+
+		Context("with three responses", func() {
+			BeforeEach(func() {
+				responses = []string{Created, OK, NoContent}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("ResponseFromField must map exactly two responses, got 3"))
+			})
+		})
+
+		Context("with a response without media type", func() {
+			BeforeEach(func() {
+				responses = []string{Created, NoContent}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("response NoContent used by ResponseFromField must have a media type"))
+			})
+		})
+	})
+
+	Context("with custom struct tags", func() {
+		var tag, value string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Bottle", func() {
+				Attribute("name", String, func() {
+					Metadata("struct:tag:"+tag, value)
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid key", func() {
+			BeforeEach(func() {
+				tag = "bson"
+				value = "name,omitempty"
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with an invalid key", func() {
+			BeforeEach(func() {
+				tag = "my tag"
+				value = "name"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid struct tag key "my tag"`))
+			})
+		})
+
+		Context("with a value containing quotes", func() {
+			BeforeEach(func() {
+				tag = "bson"
+				value = `na"me`
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("struct tag bson value"))
+			})
+		})
+	})
+
+	Context("with an i18n key", func() {
+		var key string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Bottle", func() {
+				Attribute("vintage", Integer, func() {
+					Minimum(1900)
+					Metadata("i18n:key", key)
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a key", func() {
+			BeforeEach(func() {
+				key = "errors.bottle.vintage"
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with an empty key", func() {
+			BeforeEach(func() {
+				key = ""
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("i18n key cannot be empty"))
+			})
+		})
+	})
+
+	Context("with a custom JSON marshaler", func() {
+		var typ DataType
+		var marshaler string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Bottle", func() {
+				Attribute("created", typ, func() {
+					Metadata("json:marshaler", marshaler, "example.com/mypkg")
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid marshaler", func() {
+			BeforeEach(func() {
+				typ = DateTime
+				marshaler = "mypkg.EpochMillis"
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with an unqualified marshaler type", func() {
+			BeforeEach(func() {
+				typ = DateTime
+				marshaler = "EpochMillis"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("JSON marshaler must be a qualified Go type name"))
+			})
+		})
+
+		Context("with a non primitive attribute", func() {
+			BeforeEach(func() {
+				typ = ArrayOf(String)
+				marshaler = "mypkg.Names"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot use a custom JSON marshaler"))
+			})
+		})
+	})
+
+	Context("with a flag set", func() {
+		var flagsDSL func()
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Grant", func() {
+				Attribute("permissions", String, flagsDSL)
+			})
+			dslengine.Run()
+		})
+
+		Context("with unique powers of two", func() {
+			BeforeEach(func() {
+				flagsDSL = func() {
+					FlagSet(func() {
+						Flag("READ", 1)
+						Flag("WRITE", 2)
+					})
+					Default("READ,WRITE")
+				}
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with invalid values", func() {
+			BeforeEach(func() {
+				flagsDSL = func() {
+					FlagSet(func() {
+						Flag("READ", 1)
+						Flag("WRITE", 3)
+						Flag("ADMIN", 1)
+						Flag("READ", 4)
+					})
+				}
+			})
+
+			It("produces errors", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`value 3 of flag "WRITE" is not a power of two`))
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("duplicate flag value 1"))
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`duplicate flag name "READ"`))
+			})
+		})
+
+		Context("with validations", func() {
+			BeforeEach(func() {
+				flagsDSL = func() {
+					FlagSet(func() {
+						Flag("READ", 1)
+					})
+					Pattern("^[A-Z,]+$")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("flag set cannot define validations"))
+			})
+		})
+
+		Context("with an invalid default value", func() {
+			BeforeEach(func() {
+				flagsDSL = func() {
+					FlagSet(func() {
+						Flag("READ", 1)
+					})
+					Default("READ,EXECUTE")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`default value "READ,EXECUTE" is not a valid flag set`))
+			})
+		})
+	})
+
+	Context("with named enum values", func() {
+		var enumDSL func()
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Bottle", func() {
+				Attribute("status", Integer, enumDSL)
+			})
+			dslengine.Run()
+		})
+
+		Context("that are unique", func() {
+			BeforeEach(func() {
+				enumDSL = func() {
+					EnumValues(func() {
+						Value("ACTIVE", 1)
+						Value("INACTIVE", 2)
+					})
+					Metadata("enum:encode", "name")
+				}
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Types["Bottle"].Type.ToObject()["status"].EncodesEnumNames()).Should(BeTrue())
+			})
+		})
+
+		Context("with duplicate names and values", func() {
+			BeforeEach(func() {
+				enumDSL = func() {
+					EnumValues(func() {
+						Value("ACTIVE", 1)
+						Value("ACTIVE", 2)
+						Value("INACTIVE", 2)
+					})
+				}
+			})
+
+			It("produces errors", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`duplicate enum value name "ACTIVE"`))
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("duplicate enum value 2"))
+			})
+		})
+
+		Context("with values without a name", func() {
+			BeforeEach(func() {
+				enumDSL = func() {
+					EnumValues(func() {
+						Value("ACTIVE", 1)
+					})
+					Enum(1, 2)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("named enum cannot define values without a name"))
+			})
+		})
+
+		Context("with an invalid encoding", func() {
+			BeforeEach(func() {
+				enumDSL = func() {
+					EnumValues(func() {
+						Value("ACTIVE", 1)
+					})
+					Metadata("enum:encode", "string")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid enum encoding "string"`))
+			})
+		})
+	})
+
+	Context("with named enums sharing the same type", func() {
+		var otherDSL func()
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Bottle", func() {
+				Attribute("status", Integer, func() {
+					EnumValues(func() {
+						Value("ACTIVE", 1)
+						Value("INACTIVE", 2)
+					})
+					Metadata("enum:type", "Status")
+					Metadata("enum:encode", "name")
+				})
+			})
+			Type("Box", func() {
+				Attribute("status", Integer, otherDSL)
+			})
+			dslengine.Run()
+		})
+
+		Context("that define different values", func() {
+			BeforeEach(func() {
+				otherDSL = func() {
+					EnumValues(func() {
+						Value("ACTIVE", 1)
+					})
+					Metadata("enum:type", "Status")
+					Metadata("enum:encode", "name")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("is defined with different values elsewhere"))
+			})
+		})
+
+		Context("that encode the values differently", func() {
+			BeforeEach(func() {
+				otherDSL = func() {
+					EnumValues(func() {
+						Value("ACTIVE", 1)
+						Value("INACTIVE", 2)
+					})
+					Metadata("enum:type", "Status")
+				}
+			})
+
+			It("reports the encoding mismatch", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("enum type Status of"))
+				Ω(dslengine.Errors.Error()).Should(MatchRegexp("is encoded using the value (names|numbers) but using the value (names|numbers) elsewhere"))
+				Ω(dslengine.Errors.Error()).ShouldNot(ContainSubstring("different values"))
+			})
+		})
+	})
+
+	Context("with a SQL bindable payload", func() {
+		var style string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottle", func() {
+				Action("list", func() {
+					Routing(POST("/search"))
+					Payload(func() {
+						Metadata("sql:bindable", style)
+						Attribute("account", String)
+						Attribute("vintage", Integer)
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid placeholder style", func() {
+			BeforeEach(func() {
+				style = "$"
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["bottle"].Actions["list"].Payload.SQLPlaceholderStyle()).Should(Equal("$"))
+			})
+		})
+
+		Context("with an invalid placeholder style", func() {
+			BeforeEach(func() {
+				style = ":name"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid SQL placeholder style ":name", must be "?" or "$"`))
+			})
+		})
+	})
+
+	Context("with surrogate keys", func() {
+		var keysDSL func()
+		var responseDSL func()
+
+		BeforeEach(func() {
+			responseDSL = func() {
+				Response(OK, func() {
+					Media("application/vnd.bottle")
+				})
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("account", String)
+					Attribute("tags", ArrayOf(String))
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("account")
+					Attribute("tags")
+				})
+			})
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					responseDSL()
+					Response(NotFound)
+					SurrogateKeys(keysDSL)
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("referencing string and integer attributes", func() {
+			BeforeEach(func() {
+				keysDSL = func() {
+					Attribute("id")
+					Attribute("account")
+				}
+			})
+
+			It("sets the action surrogate keys", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["bottle"].Actions["show"].SurrogateKeys()).Should(Equal([]string{"account", "id"}))
+			})
+		})
+
+		Context("referencing an unknown attribute", func() {
+			BeforeEach(func() {
+				keysDSL = func() {
+					Attribute("foo")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`surrogate key "foo" is not an attribute of the OK response media type`))
+			})
+		})
+
+		Context("referencing an array attribute", func() {
+			BeforeEach(func() {
+				keysDSL = func() {
+					Attribute("tags")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`surrogate key "tags" must be a string or an integer`))
+			})
+		})
+
+		Context("with no success response media type", func() {
+			BeforeEach(func() {
+				keysDSL = func() {
+					Attribute("id")
+				}
+				responseDSL = func() {
+					Response(NoContent)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("surrogate keys require a success response with a media type"))
+			})
+		})
+	})
+
+	Context("with an invalid problem type base URI", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				ProblemDetails("://goa.design")
+			})
+			dslengine.Run()
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid problem type base URI"))
+		})
+	})
+
+	Context("with a request ID header", func() {
+		var header string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				RequestID(header)
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid header name", func() {
+			BeforeEach(func() {
+				header = "X-Request-ID"
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with an invalid header name", func() {
+			BeforeEach(func() {
+				header = "X Request"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid request ID header name "X Request"`))
+			})
+		})
+	})
+
+	Context("with a body envelope", func() {
+		var dataField, metaField string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				BodyEnvelope(dataField, metaField)
+			})
+			Resource("foo", func() {
+				BodyEnvelope("result", "")
+				Action("bar", func() {
+					Routing(GET("/"))
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with valid field names", func() {
+			BeforeEach(func() {
+				dataField, metaField = "data", "meta"
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+
+			It("uses the resource envelope for the resource actions", func() {
+				env := Design.Resources["foo"].Actions["bar"].BodyEnvelope()
+				Ω(env).ShouldNot(BeNil())
+				Ω(env.DataField).Should(Equal("result"))
+				Ω(env.MetaField).Should(BeEmpty())
+			})
+		})
+
+		Context("with an invalid data field name", func() {
+			BeforeEach(func() {
+				dataField, metaField = "da\nta", "meta"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid body envelope data field "da\nta"`))
+			})
+		})
+
+		Context("with the same data and meta fields", func() {
+			BeforeEach(func() {
+				dataField, metaField = "data", "data"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("body envelope data and meta fields must be different"))
+			})
+		})
+	})
+
+	Context("with a base path", func() {
+		var basePath string
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				BasePath(basePath)
+			})
+			Resource("foo", func() {
+				BasePath("/foo")
+				Action("bar", func() {
+					Routing(GET("/:id"))
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("that is absolute", func() {
+			BeforeEach(func() {
+				basePath = "/api/v1"
+			})
+
+			It("prefixes the action routes", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				route := Design.Resources["foo"].Actions["bar"].Routes[0]
+				Ω(route.FullPath()).Should(Equal("/api/v1/foo/:id"))
+			})
+		})
+
+		Context("that is relative", func() {
+			BeforeEach(func() {
+				basePath = "api/v1"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid base path "api/v1", base path must start with /`))
+			})
+		})
+	})
+
+	Context("with resource versions", func() {
+		var versionMedia, v1, v2 string
+
+		BeforeEach(func() {
+			versionMedia = "application/vnd.cellar.{version}+json"
+			v1 = "v1"
+			v2 = "v2"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				VersionMedia(versionMedia)
+			})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Version(v1)
+				Action("show", func() {
+					Routing(GET("/:id"))
+				})
+			})
+			Resource("bottle_v2", func() {
+				BasePath("/bottles")
+				Version(v2)
+				Action("show", func() {
+					Routing(GET("/:id"))
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the versions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.VersionMedia).Should(Equal(versionMedia))
+			Ω(Design.Resources["bottle"].Version).Should(Equal("v1"))
+			Ω(Design.Resources["bottle_v2"].Version).Should(Equal("v2"))
+			Ω(Design.Resources["bottle"].IsVersioned()).Should(BeTrue())
+		})
+
+		Context("that are not unique", func() {
+			BeforeEach(func() {
+				v2 = "V1"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`version "V1" of /bottles is already defined by resource "bottle"`))
+			})
+		})
+
+		Context("that are invalid", func() {
+			BeforeEach(func() {
+				v2 = "v2/beta"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid version "v2/beta"`))
+			})
+		})
+
+		Context("with no version media", func() {
+			BeforeEach(func() {
+				versionMedia = ""
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`resource version "v1" requires the API to define VersionMedia`))
+			})
+		})
+
+		Context("with a version media without version placeholder", func() {
+			BeforeEach(func() {
+				versionMedia = "application/vnd.cellar+json"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("must contain {version} exactly once"))
+			})
+		})
+	})
+
+	Context("with sparse fieldsets", func() {
+		var noMedia bool
+		var fieldsType DataType
+
+		BeforeEach(func() {
+			noMedia = false
+			fieldsType = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+			})
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					if fieldsType != nil {
+						Params(func() {
+							Param("fields", fieldsType)
+						})
+					}
+					SparseFieldsets()
+					if noMedia {
+						Response(NoContent)
+					} else {
+						Response(OK, bottle)
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with an object response", func() {
+			It("defines the fields parameter", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				a := Design.Resources["bottle"].Actions["show"]
+				Ω(a.HasSparseFieldsets()).Should(BeTrue())
+				Ω(a.Params.Type.ToObject()).Should(HaveKey("fields"))
+				Ω(a.Params.Type.ToObject()["fields"].Type).Should(Equal(String))
+			})
+		})
+
+		Context("with no response media type", func() {
+			BeforeEach(func() {
+				noMedia = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("sparse fieldsets require a success response with a media type"))
+			})
+		})
+
+		Context("with a fields parameter that is not a string", func() {
+			BeforeEach(func() {
+				fieldsType = Integer
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`sparse fieldsets parameter "fields" must be a string`))
+			})
+		})
+	})
+
+	Context("with a batch action", func() {
+		var noPayload, arrayPayload bool
+
+		BeforeEach(func() {
+			noPayload = false
+			arrayPayload = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("user", func() {
+				BasePath("/users")
+				Action("create", func() {
+					Routing(POST(""))
+					if arrayPayload {
+						Payload(ArrayOf(String))
+					} else if !noPayload {
+						Payload(func() {
+							Attribute("name", String)
+						})
+					}
+					Response(Created)
+					Batch()
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("synthesizes the batch action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			r := Design.Resources["user"]
+			create := r.Actions["create"]
+			Ω(create.BatchActionName()).Should(Equal("batch_create"))
+			batch, ok := r.Actions["batch_create"]
+			Ω(ok).Should(BeTrue())
+			Ω(batch.BatchSource()).Should(Equal(create))
+			Ω(batch.Routes).Should(HaveLen(1))
+			Ω(batch.Routes[0].Verb).Should(Equal("POST"))
+			Ω(batch.Routes[0].FullPath()).Should(Equal("/users/batch"))
+			Ω(batch.Payload.TypeName).Should(Equal("BatchCreateUserPayload"))
+			Ω(batch.Payload.Type.IsArray()).Should(BeTrue())
+			Ω(batch.Payload.Type.ToArray().ElemType.Type).Should(Equal(create.Payload))
+			Ω(batch.Responses).Should(HaveKey("OK"))
+			Ω(batch.Responses["OK"].MediaType).Should(Equal(BatchResultsMedia.Identifier))
+			Ω(Design.MediaTypeWithIdentifier(BatchResultsMedia.Identifier)).Should(Equal(BatchResultsMedia))
+		})
+
+		Context("with no payload", func() {
+			BeforeEach(func() {
+				noPayload = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("batch actions require the action to have a payload"))
+			})
+		})
+
+		Context("with a payload that is not an object", func() {
+			BeforeEach(func() {
+				arrayPayload = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("batch actions require the action payload to be an object"))
+			})
+		})
+	})
+
+	Context("with a field naming policy", func() {
+		var policy string
+
+		BeforeEach(func() {
+			policy = "camel"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("account", func() {
+				FieldNaming(policy)
+				Attribute("userID", String)
+			})
+			dslengine.Run()
+		})
+
+		It("stores the policy", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Types["account"].FieldNaming()).Should(Equal("camel"))
+		})
+
+		Context("that is not supported", func() {
+			BeforeEach(func() {
+				policy = "pascal"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid field naming policy "pascal"`))
+			})
+		})
+	})
+
+	Context("with sortable and filterable fields", func() {
+		var sortable []string
+		var sortableField func()
+
+		BeforeEach(func() {
+			sortable = []string{"name"}
+			sortableField = func() {}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+					Attribute("status", String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+					Attribute("status")
+				})
+			})
+			Resource("bottle", func() {
+				Action("list", func() {
+					Routing(GET(""))
+					Sortable(sortable...)
+					sortableField()
+					Filterable("status")
+					Response(OK, CollectionOf(bottle))
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with valid sort fields", func() {
+			It("defines the sort parameter", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				a := Design.Resources["bottle"].Actions["list"]
+				Ω(a.SortableFields()).Should(Equal([]string{"name"}))
+				Ω(a.FilterableFields()).Should(Equal([]string{"status"}))
+				Ω(a.Params.Type.ToObject()).Should(HaveKey("sort"))
+				Ω(a.Params.Type.ToObject()["sort"].Type).Should(Equal(String))
+			})
+		})
+
+		Context("with an invalid sort field", func() {
+			BeforeEach(func() {
+				sortable = []string{"name", "vintage"}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`sortable field "vintage" is not an attribute of the OK response media type`))
+			})
+		})
+
+		Context("with default sort options", func() {
+			BeforeEach(func() {
+				sortableField = func() { SortableField("id", Desc, NullsLast) }
+			})
+
+			It("sets the default sort options", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				a := Design.Resources["bottle"].Actions["list"]
+				Ω(a.SortableFields()).Should(Equal([]string{"name", "id"}))
+				Ω(a.HasSortDefaults()).Should(BeTrue())
+				Ω(a.SortFieldDefinitions()).Should(Equal([]*SortFieldDefinition{
+					{Name: "name"},
+					{Name: "id", Descending: true, Nulls: "last"},
+				}))
+			})
+		})
+
+		Context("with conflicting default sort options", func() {
+			BeforeEach(func() {
+				sortableField = func() { SortableField("name", Asc, Desc) }
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`sortable field "name" must define at most one default direction`))
+			})
+		})
+
+		Context("with an invalid default sort option", func() {
+			BeforeEach(func() {
+				sortableField = func() { SortableField("name", SortOption("sideways")) }
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid sort option "sideways" of sortable field "name"`))
+			})
+		})
+	})
+
+	Context("with a request body signature", func() {
+		var header string
+		var payload bool
+
+		BeforeEach(func() {
+			header = "X-Hub-Signature-256"
+			payload = true
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("hooks", func() {
+				Action("push", func() {
+					Routing(POST("/push"))
+					if payload {
+						Payload(func() {
+							Attribute("ref", String)
+						})
+					}
+					VerifySignature(header)
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid header", func() {
+			It("sets the signature header", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["hooks"].Actions["push"].SignatureHeader()).Should(Equal(header))
+			})
+		})
+
+		Context("with an invalid header", func() {
+			BeforeEach(func() {
+				header = "X Signature"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid signature header name "X Signature"`))
+			})
+		})
+
+		Context("with no payload", func() {
+			BeforeEach(func() {
+				payload = false
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("VerifySignature requires a payload"))
+			})
+		})
+	})
+
+	Context("with request body requirements", func() {
+		var contentType string
+
+		BeforeEach(func() {
+			contentType = "application/octet-stream"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("files", func() {
+				Action("upload", func() {
+					Routing(PUT("/files/:name"))
+					RequireContentLength()
+					RequireContentType(contentType)
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid content type", func() {
+			It("sets the requirements", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				a := Design.Resources["files"].Actions["upload"]
+				Ω(a.RequiresContentLength()).Should(BeTrue())
+				Ω(a.RequiredContentTypes()).Should(Equal([]string{contentType}))
+			})
+		})
+
+		Context("with an invalid content type", func() {
+			BeforeEach(func() {
+				contentType = "octet-stream"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid content type "octet-stream"`))
+			})
+		})
+
+		Context("with a content type with parameters", func() {
+			BeforeEach(func() {
+				contentType = "text/plain; charset=utf-8"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid content type "text/plain; charset=utf-8"`))
+			})
+		})
+	})
+
+	Context("with a request body that skips validation", func() {
+		var payload bool
+
+		BeforeEach(func() {
+			payload = true
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("metrics", func() {
+				Action("ingest", func() {
+					Routing(POST("/metrics"))
+					if payload {
+						Payload(func() {
+							Attribute("name", String)
+							Required("name")
+						})
+					}
+					SkipRequestBodyValidation()
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("skips the request body validation", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["metrics"].Actions["ingest"].SkipsRequestBodyValidation()).Should(BeTrue())
+		})
+
+		Context("with no payload", func() {
+			BeforeEach(func() {
+				payload = false
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("SkipRequestBodyValidation requires a payload"))
+			})
+		})
+	})
+
+	Context("with path parameters", func() {
+		var params func()
+
+		BeforeEach(func() {
+			params = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					if params != nil {
+						Params(params)
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("that are not declared", func() {
+			It("declares them as required string parameters", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				ps := Design.Resources["bottle"].Actions["show"].Params
+				Ω(ps.Type.ToObject()).Should(HaveKey("id"))
+				Ω(ps.Type.ToObject()["id"].Type).Should(Equal(String))
+				Ω(ps.IsPrimitivePointer("id")).Should(BeFalse())
+			})
+		})
+
+		Context("that are declared without Required", func() {
+			BeforeEach(func() {
+				params = func() {
+					Param("id", Integer)
+				}
+			})
+
+			It("makes them required", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				ps := Design.Resources["bottle"].Actions["show"].Params
+				Ω(ps.IsPrimitivePointer("id")).Should(BeFalse())
+			})
+		})
+
+		Context("that define a default value", func() {
+			BeforeEach(func() {
+				params = func() {
+					Param("id", Integer, func() {
+						Default(1)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(
+					"path parameter id of route GET /bottles/:id cannot define a default value, path parameters are required",
+				))
+			})
+		})
+	})
+
+	Context("with a cacheable action", func() {
+		var verb string
+		var key bool
+
+		BeforeEach(func() {
+			verb = "POST"
+			key = true
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("charges", func() {
+				Action("charge", func() {
+					if verb == "GET" {
+						Routing(GET("/charges"))
+					} else {
+						Routing(POST("/charges"))
+					}
+					if key {
+						IdempotencyKey()
+					}
+					Cacheable()
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("defines the idempotency key header", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["charges"].Actions["charge"]
+			Ω(a.RequiresIdempotencyKey()).Should(BeTrue())
+			Ω(a.CachesResponses()).Should(BeTrue())
+			Ω(a.Headers.Type.ToObject()).Should(HaveKey("Idempotency-Key"))
+			Ω(a.Headers.IsRequired("Idempotency-Key")).Should(BeTrue())
+		})
+
+		Context("with no idempotency key", func() {
+			BeforeEach(func() {
+				key = false
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Cacheable requires IdempotencyKey"))
+			})
+		})
+
+		Context("routed to GET requests", func() {
+			BeforeEach(func() {
+				verb = "GET"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Cacheable cannot be used with GET routes"))
+			})
+		})
+	})
+
+	Context("with a dedupe window", func() {
+		var window string
+
+		BeforeEach(func() {
+			window = "10m"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("deliveries", func() {
+				Action("deliver", func() {
+					Routing(POST("/deliveries"))
+					Payload(func() {
+						Attribute("id", String)
+					})
+					Dedupe(window)
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the dedupe window", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["deliveries"].Actions["deliver"].DedupeWindow()).Should(Equal(10 * time.Minute))
+		})
+
+		Context("that does not parse", func() {
+			BeforeEach(func() {
+				window = "ten minutes"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid dedupe window "ten minutes"`))
+			})
+		})
+
+		Context("that is not positive", func() {
+			BeforeEach(func() {
+				window = "0s"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid dedupe window "0s"`))
+			})
+		})
+	})
+
+	Context("with a faker directive", func() {
+		var typ DataType
+		var directive string
+
+		BeforeEach(func() {
+			typ = String
+			directive = "email"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Account", func() {
+				Attribute("contact", typ, func() {
+					Faker(directive)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("stores the directive", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			att := Design.Types["Account"].Type.ToObject()["contact"]
+			Ω(att.FakerDirective()).Should(Equal("email"))
+		})
+
+		Context("that is unknown", func() {
+			BeforeEach(func() {
+				directive = "nickname"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown faker directive "nickname"`))
+			})
+		})
+
+		Context("that does not apply to the attribute type", func() {
+			BeforeEach(func() {
+				typ = Integer
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`faker directive "email" cannot be used with attribute of type integer`))
+			})
+		})
+	})
+
+	Context("with a streamed JSON response", func() {
+		var media interface{}
+
+		BeforeEach(func() {
+			media = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			if media == nil {
+				media = CollectionOf(bottle)
+			}
+			Resource("bottles", func() {
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Response(OK, media)
+					StreamJSON()
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("streams the responses", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["bottles"].Actions["list"].StreamsJSON()).Should(BeTrue())
+		})
+
+		Context("whose media type is not a collection", func() {
+			BeforeEach(func() {
+				media = "application/vnd.bottle"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("StreamJSON requires a success response whose media type is a collection of objects"))
+			})
+		})
+	})
+
+	Context("with a patch format", func() {
+		var format string
+		var verb func(string, ...func()) *RouteDefinition
+		var payloadFirst, noPayload, flags bool
+		var bottle *UserTypeDefinition
+
+		BeforeEach(func() {
+			format = MergePatch
+			verb = PATCH
+			payloadFirst = true
+			noPayload = false
+			flags = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle = Type("BottlePayload", func() {
+				Attribute("name", String, func() {
+					MinLength(1)
+				})
+				Attribute("rating", Integer, func() {
+					Default(3)
+				})
+				Attribute("tags", ArrayOf(String))
+				if flags {
+					Attribute("perms", String, func() {
+						FlagSet(func() {
+							Flag("READ", 1)
+							Flag("WRITE", 2)
+						})
+					})
+				}
+				Required("name")
+			})
+			Resource("bottle", func() {
+				Action("patch", func() {
+					Routing(verb("/:id"))
+					if payloadFirst && !noPayload {
+						Payload(bottle)
+					}
+					PatchFormat(format)
+					if !payloadFirst && !noPayload {
+						Payload(bottle)
+					}
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("using JSON Merge Patch", func() {
+			It("makes the payload fields optional and nullable", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				a := Design.Resources["bottle"].Actions["patch"]
+				Ω(a.PatchFormat()).Should(Equal(MergePatch))
+				Ω(a.Payload.TypeName).Should(Equal("PatchBottlePayload"))
+				Ω(a.Payload.AllRequired()).Should(BeEmpty())
+				o := a.Payload.Type.ToObject()
+				Ω(o["name"].IsNullable()).Should(BeTrue())
+				Ω(o["name"].Validation).Should(BeNil())
+				Ω(o["rating"].IsNullable()).Should(BeTrue())
+				Ω(o["rating"].DefaultValue).Should(BeNil())
+				Ω(o["tags"].IsNullable()).Should(BeFalse())
+			})
+
+			It("leaves the payload type unchanged", func() {
+				o := bottle.Type.ToObject()
+				Ω(bottle.AllRequired()).Should(ConsistOf("name"))
+				Ω(o["name"].IsNullable()).Should(BeFalse())
+				Ω(o["rating"].DefaultValue).Should(Equal(3))
+			})
+
+			Context("with the payload defined after the format", func() {
+				BeforeEach(func() {
+					payloadFirst = false
+				})
+
+				It("makes the payload fields nullable", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+					a := Design.Resources["bottle"].Actions["patch"]
+					Ω(a.Payload.Type.ToObject()["name"].IsNullable()).Should(BeTrue())
+				})
+			})
+
+			Context("with no payload", func() {
+				BeforeEach(func() {
+					noPayload = true
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring("must define an object payload"))
+				})
+			})
+
+			Context("with a flag set field", func() {
+				BeforeEach(func() {
+					flags = true
+				})
+
+				It("leaves the flag set field not nullable", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+					a := Design.Resources["bottle"].Actions["patch"]
+					o := a.Payload.Type.ToObject()
+					Ω(o["perms"].IsNullable()).Should(BeFalse())
+					Ω(o["perms"].Flags).Should(HaveLen(2))
+					Ω(o["name"].IsNullable()).Should(BeTrue())
+				})
+			})
+		})
+
+		Context("using JSON Patch", func() {
+			BeforeEach(func() {
+				format = JSONPatch
+				noPayload = true
+			})
+
+			It("sets the payload to a list of operations", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				a := Design.Resources["bottle"].Actions["patch"]
+				Ω(a.PatchFormat()).Should(Equal(JSONPatch))
+				Ω(a.Payload.Type.IsArray()).Should(BeTrue())
+				Ω(a.Payload.Type.ToArray().ElemType.Type).Should(Equal(JSONPatchOperation))
+				Ω(Design.Types).Should(HaveKeyWithValue("JSONPatchOperation", JSONPatchOperation))
+			})
+
+			Context("with a payload", func() {
+				BeforeEach(func() {
+					noPayload = false
+					payloadFirst = false
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot define a payload when using the JSON Patch format"))
+				})
+			})
+		})
+
+		Context("with an invalid format", func() {
+			BeforeEach(func() {
+				format = "xml-patch"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid patch format "xml-patch"`))
+			})
+		})
+
+		Context("with a route that is not PATCH", func() {
+			BeforeEach(func() {
+				verb = PUT
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("patch format cannot be used with PUT routes"))
+			})
+		})
+	})
+
+	Context("echoing the updated fields", func() {
+		var format string
+		var collection bool
+
+		BeforeEach(func() {
+			format = MergePatch
+			collection = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("bottle", func() {
+				Action("patch", func() {
+					Routing(PATCH("/:id"))
+					if format == MergePatch {
+						Payload(func() {
+							Attribute("name", String)
+						})
+					}
+					PatchFormat(format)
+					if collection {
+						Response(OK, CollectionOf(bottle))
+					} else {
+						Response(OK, bottle)
+					}
+					EchoUpdatedFields()
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces no error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["bottle"].Actions["patch"].EchoesUpdatedFields()).Should(BeTrue())
+		})
+
+		Context("using JSON Patch", func() {
+			BeforeEach(func() {
+				format = JSONPatch
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("echoing the updated fields requires the action to use the JSON Merge Patch format"))
+			})
+		})
+
+		Context("with a collection response", func() {
+			BeforeEach(func() {
+				collection = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("echoing the updated fields requires the OK response media type to describe an object"))
+			})
+		})
+	})
+
+	Context("with request body size limits", func() {
+		var apiSize, resSize, actionSize string
+
+		BeforeEach(func() {
+			apiSize = ""
+			resSize = ""
+			actionSize = ""
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				if apiSize != "" {
+					MaxBodySize(apiSize)
+				}
+			})
+			Resource("foo", func() {
+				if resSize != "" {
+					MaxBodySize(resSize)
+				}
+				Action("bar", func() {
+					Routing(POST("/bar"))
+					Response(OK)
+					Response(BadRequest)
+					if actionSize != "" {
+						MaxBodySize(actionSize)
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("with a valid size", func() {
+			BeforeEach(func() {
+				actionSize = "1MB"
+			})
+
+			It("sets the action max body size", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["foo"].Actions["bar"].MaxBodySize()).Should(Equal(int64(1 << 20)))
+			})
+		})
+
+		Context("with sizes set at all levels", func() {
+			BeforeEach(func() {
+				apiSize = "1GB"
+				resSize = "1MB"
+				actionSize = "512"
+			})
+
+			It("uses the action size", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["foo"].Actions["bar"].MaxBodySize()).Should(Equal(int64(512)))
+			})
+		})
+
+		Context("with sizes set on the API and resource", func() {
+			BeforeEach(func() {
+				apiSize = "1GB"
+				resSize = "64kb"
+			})
+
+			It("uses the resource size", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["foo"].Actions["bar"].MaxBodySize()).Should(Equal(int64(64 << 10)))
+			})
+		})
+
+		Context("with no size", func() {
+			It("does not limit the body size", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["foo"].Actions["bar"].MaxBodySize()).Should(BeZero())
+			})
+		})
+
+		Context("with an invalid size", func() {
+			BeforeEach(func() {
+				resSize = "1 potato"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`resource "foo": invalid max body size: invalid size "1 potato"`))
+			})
+		})
+
+		Context("with a negative size", func() {
+			BeforeEach(func() {
+				apiSize = "-1KB"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid max body size`))
+			})
+		})
+	})
+
+	Context("with an attribute that requires a scope", func() {
+		var required bool
+
+		BeforeEach(func() {
+			required = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			MediaType("application/vnd.employee", func() {
+				Attributes(func() {
+					Attribute("name", String)
+					Attribute("salary", Integer, func() {
+						RequiredScope("admin")
+					})
+					if required {
+						Required("salary")
+					}
+				})
+				View("default", func() {
+					Attribute("name")
+					Attribute("salary")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("stores the scope", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			mt := Design.MediaTypeWithIdentifier("application/vnd.employee")
+			Ω(mt.Type.ToObject()["salary"].RequiredScope()).Should(Equal("admin"))
+		})
+
+		Context("that is required", func() {
+			BeforeEach(func() {
+				required = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`required field "salary" cannot require a scope`))
+			})
+		})
+	})
+
+	Context("with an attribute limited to some versions", func() {
+		var since, until, version string
+		var required bool
+
+		BeforeEach(func() {
+			since, until, version = "v2", "v3", "v1"
+			required = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				VersionMedia("application/vnd.test.{version}+json")
+			})
+			mt := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("name", String)
+					Attribute("rating", Integer, func() {
+						SinceVersion(since)
+						UntilVersion(until)
+					})
+					if required {
+						Required("rating")
+					}
+				})
+				View("default", func() {
+					Attribute("name")
+					Attribute("rating")
+				})
+			})
+			Resource("bottles", func() {
+				Version(version)
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					Response(OK, mt)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("stores the versions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			mt := Design.MediaTypeWithIdentifier("application/vnd.bottle")
+			rating := mt.Type.ToObject()["rating"]
+			Ω(rating.SinceVersion()).Should(Equal("v2"))
+			Ω(rating.UntilVersion()).Should(Equal("v3"))
+		})
+
+		Context("that is required", func() {
+			BeforeEach(func() {
+				required = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`required field "rating" cannot be limited to some versions`))
+			})
+		})
+
+		Context("with a version that cannot be compared", func() {
+			BeforeEach(func() {
+				since = "beta"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid version "beta"`))
+			})
+		})
+
+		Context("with a first version greater than the last version", func() {
+			BeforeEach(func() {
+				since, until = "v3", "v2.1"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`first version "v3" is greater than last version "v2.1"`))
+			})
+		})
+
+		Context("used by a resource whose version cannot be compared", func() {
+			BeforeEach(func() {
+				version = "2019-01"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`version "2019-01" cannot be compared with the versions set with SinceVersion or UntilVersion`))
+			})
+		})
+	})
+
+	Context("with a deprecated action", func() {
+		var sunset string
+
+		BeforeEach(func() {
+			sunset = "2025-12-31"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottles", func() {
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					Deprecated(func() {
+						Sunset(sunset)
+					})
+					Response(NoContent)
+				})
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("records the deprecation and the sunset date", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["bottles"].Actions["show"]
+			Ω(a.IsDeprecated()).Should(BeTrue())
+			Ω(a.Sunset()).Should(Equal(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)))
+			Ω(Design.Resources["bottles"].Actions["list"].IsDeprecated()).Should(BeFalse())
+		})
+
+		Context("with a RFC 3339 sunset date", func() {
+			BeforeEach(func() {
+				sunset = "2025-12-31T12:00:00Z"
+			})
+
+			It("parses the date", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				a := Design.Resources["bottles"].Actions["show"]
+				Ω(a.Sunset()).Should(Equal(time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC)))
+			})
+		})
+
+		Context("with an invalid sunset date", func() {
+			BeforeEach(func() {
+				sunset = "next year"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid sunset date "next year"`))
+			})
+		})
+	})
+
+	Context("with pagination", func() {
+		var def, max int
+
+		BeforeEach(func() {
+			def, max = 20, 100
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottles", func() {
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Pagination(func() {
+						PageSize(def, max)
+					})
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("defines the page size param with the default value", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["bottles"].Actions["list"]
+			p := a.Params.Type.ToObject()["page_size"]
+			Ω(p).ShouldNot(BeNil())
+			Ω(p.Type).Should(Equal(Integer))
+			Ω(p.DefaultValue).Should(Equal(20))
+			d, m := a.PageSize()
+			Ω(d).Should(Equal(20))
+			Ω(m).Should(Equal(100))
+		})
+
+		Context("with a default greater than the maximum", func() {
+			BeforeEach(func() {
+				def = 200
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid page size limits: default 200 and maximum 100"))
+			})
+		})
+
+		Context("with a zero default", func() {
+			BeforeEach(func() {
+				def = 0
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid page size limits: default 0 and maximum 100"))
+			})
+		})
+	})
+
+	Context("with fields required together", func() {
+		var group []string
+		var required bool
+
+		BeforeEach(func() {
+			group = []string{"lat", "lng"}
+			required = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Location", func() {
+				Attribute("lat", Number)
+				Attribute("lng", Number)
+				Attribute("name", String, func() {
+					Default("home")
+				})
+				RequiredTogether(group...)
+				if required {
+					Required("lng")
+				}
+			})
+			dslengine.Run()
+		})
+
+		It("records the group", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			ut := Design.Types["Location"]
+			Ω(ut.Validation.RequiredTogether).Should(Equal([][]string{{"lat", "lng"}}))
+		})
+
+		Context("with a field that does not exist", func() {
+			BeforeEach(func() {
+				group = []string{"lat", "long"}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`field "long" required together with other fields does not exist`))
+			})
+		})
+
+		Context("with a required field", func() {
+			BeforeEach(func() {
+				required = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`field "lng" required together with other fields must be optional`))
+			})
+		})
+
+		Context("with a field that has a default value", func() {
+			BeforeEach(func() {
+				group = []string{"lat", "name"}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`field "name" required together with other fields cannot have a default value`))
+			})
+		})
+
+		Context("with a single field", func() {
+			BeforeEach(func() {
+				group = []string{"lat"}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`fields required together must list at least two fields`))
+			})
+		})
+	})
+
+	Context("with bounds", func() {
+		var other string
+		var otherType DataType
+
+		BeforeEach(func() {
+			other = "price"
+			otherType = Number
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("OrderLine", func() {
+				Attribute("price", otherType)
+				Attribute("discount", Number)
+				Bound("discount", LessThanOrEqual, other)
+			})
+			dslengine.Run()
+		})
+
+		It("records the bound", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			ut := Design.Types["OrderLine"]
+			Ω(ut.Validation.Bounds).Should(HaveLen(1))
+			Ω(*ut.Validation.Bounds[0]).Should(Equal(dslengine.BoundDefinition{Field: "discount", Operator: "<=", Other: "price"}))
+		})
+
+		Context("with a field that does not exist", func() {
+			BeforeEach(func() {
+				other = "cost"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`bound field "cost" does not exist`))
+			})
+		})
+
+		Context("with a non numeric field", func() {
+			BeforeEach(func() {
+				otherType = String
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`bound field "price" must be numeric, field is of type string`))
+			})
+		})
+	})
+
+	Context("with encode transforms", func() {
+		var transform string
+		var typ DataType
+
+		BeforeEach(func() {
+			transform = "e164"
+			typ = String
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			MediaType("application/vnd.contact", func() {
+				Attributes(func() {
+					Attribute("phone", typ, func() {
+						EncodeTransform(transform)
+					})
+				})
+				View("default", func() {
+					Attribute("phone")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("records the transform", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			mt := Design.MediaTypeWithIdentifier("application/vnd.contact")
+			Ω(mt.Type.ToObject()["phone"].EncodeTransform()).Should(Equal("e164"))
+		})
+
+		Context("with an unknown transform", func() {
+			BeforeEach(func() {
+				transform = "rot13"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown encode transform "rot13"`))
+			})
+		})
+
+		Context("with a registered transform", func() {
+			BeforeEach(func() {
+				RegisterEncodeTransform("rot13")
+				transform = "rot13"
+			})
+
+			It("does not produce an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with a non string attribute", func() {
+			BeforeEach(func() {
+				typ = Integer
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`encode transform "e164" cannot be used with attribute of type integer`))
+			})
+		})
+	})
+
+	Context("with a wire type", func() {
+		var typ, wire DataType
+		var nullable bool
+
+		BeforeEach(func() {
+			typ = Integer
+			wire = String
+			nullable = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Account", func() {
+				Attribute("id", typ, func() {
+					WireType(wire)
+					if nullable {
+						Nullable()
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("records the wire type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Types["Account"].Type.ToObject()["id"].WireType()).Should(Equal("string"))
+		})
+
+		Context("with an unsupported wire type", func() {
+			BeforeEach(func() {
+				typ = String
+				wire = Integer
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unsupported wire type "integer"`))
+			})
+		})
+
+		Context("with an attribute that cannot be converted", func() {
+			BeforeEach(func() {
+				typ = ArrayOf(Integer)
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`attribute of type array cannot use wire type "string"`))
+			})
+		})
+
+		Context("with a nullable attribute", func() {
+			BeforeEach(func() {
+				nullable = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("nullable attribute cannot use a wire type"))
+			})
+		})
+	})
+
+	Context("with response cookies", func() {
+		var sessionType DataType
+		var sameSite string
+
+		BeforeEach(func() {
+			sessionType = String
+			sameSite = "Strict"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			session := MediaType("application/vnd.session", func() {
+				Attributes(func() {
+					Attribute("user", String)
+					Attribute("session", sessionType)
+				})
+				View("default", func() {
+					Attribute("user")
+					Attribute("session")
+				})
+			})
+			Resource("sessions", func() {
+				Action("create", func() {
+					Routing(POST("/sessions"))
+					Response(OK, session, func() {
+						Cookie(func() {
+							Name("session")
+							HTTPOnly()
+							Secure()
+							SameSite(sameSite)
+							MaxAge("24h")
+						})
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("records the cookie", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			r := Design.Resources["sessions"].Actions["create"].Responses["OK"]
+			Ω(r.Cookies).Should(HaveLen(1))
+			c := r.Cookies[0]
+			Ω(c.Name).Should(Equal("session"))
+			Ω(c.HTTPOnly).Should(BeTrue())
+			Ω(c.Secure).Should(BeTrue())
+			Ω(c.SameSite).Should(Equal("Strict"))
+			Ω(c.MaxAge).Should(Equal(86400))
+		})
+
+		Context("with a non string attribute", func() {
+			BeforeEach(func() {
+				sessionType = Integer
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`cookie "session" value must be a string attribute, attribute is of type integer`))
+			})
+		})
+
+		Context("with an invalid SameSite value", func() {
+			BeforeEach(func() {
+				sameSite = "Sometimes"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid cookie SameSite value "Sometimes"`))
+			})
+		})
+	})
+
+	Context("with operation IDs", func() {
+		var otherID string
+
+		BeforeEach(func() {
+			otherID = "showUser"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("users", func() {
+				Action("list", func() {
+					Routing(GET("/users"))
+					OperationID("listUsersV2")
+					Response(NoContent)
+				})
+				Action("show", func() {
+					Routing(GET("/users/:id"))
+					OperationID(otherID)
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the operation IDs", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["users"].Actions["list"].OperationID()).Should(Equal("listUsersV2"))
+		})
+
+		Context("that are not unique", func() {
+			BeforeEach(func() {
+				otherID = "listUsersV2"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(MatchRegexp(`operation ID "listUsersV2" is already used by action "(list|show)" of resource "users"`))
+			})
+		})
+
+		Context("that are empty", func() {
+			BeforeEach(func() {
+				otherID = ""
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("operation ID cannot be empty"))
+			})
+		})
+	})
+
+	Context("with storage hints", func() {
+		var idColumn, createdColumn string
+		var twoKeys bool
+
+		BeforeEach(func() {
+			idColumn = "id"
+			createdColumn = "created_at"
+			twoKeys = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Account", func() {
+				Attribute("id", Integer, func() {
+					Column(idColumn)
+					PrimaryKey()
+				})
+				Attribute("createdAt", DateTime, func() {
+					Column(createdColumn)
+					Index("idx_created")
+				})
+				Attribute("email", String, func() {
+					if twoKeys {
+						PrimaryKey()
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("stores the hints", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := Design.Types["Account"].Type.ToObject()
+			Ω(o["id"].IsPrimaryKey()).Should(BeTrue())
+			Ω(o["createdAt"].StorageColumn()).Should(Equal("created_at"))
+			index, ok := o["createdAt"].StorageIndex()
+			Ω(ok).Should(BeTrue())
+			Ω(index).Should(Equal("idx_created"))
+		})
+
+		Context("with two primary keys", func() {
+			BeforeEach(func() {
+				twoKeys = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`fields "email" and "id" cannot both be primary keys`))
+			})
+		})
+
+		Context("with duplicate column names", func() {
+			BeforeEach(func() {
+				createdColumn = "email"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`fields "createdAt" and "email" are both stored in column "email"`))
+			})
+		})
+
+		Context("with an invalid column name", func() {
+			BeforeEach(func() {
+				idColumn = "account id"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`field "id" column name "account id" is invalid`))
+			})
+		})
+	})
+
+	Context("with response compression", func() {
+		var apiCompress, actionCompress []interface{}
+
+		BeforeEach(func() {
+			apiCompress = nil
+			actionCompress = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				if apiCompress != nil {
+					Compress(apiCompress...)
+				}
+			})
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/bar"))
+					Response(OK)
+					if actionCompress != nil {
+						Compress(actionCompress...)
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("set on the API", func() {
+			BeforeEach(func() {
+				apiCompress = []interface{}{"br", "gzip", Threshold("1KB")}
+			})
+
+			It("compresses the action responses", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				encodings, threshold := Design.Resources["foo"].Actions["bar"].Compression()
+				Ω(encodings).Should(Equal([]string{"br", "gzip"}))
+				Ω(threshold).Should(Equal(int64(1024)))
+			})
+
+			Context("and on the action", func() {
+				BeforeEach(func() {
+					actionCompress = []interface{}{"deflate"}
+				})
+
+				It("uses the action setting", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+					encodings, threshold := Design.Resources["foo"].Actions["bar"].Compression()
+					Ω(encodings).Should(Equal([]string{"deflate"}))
+					Ω(threshold).Should(BeZero())
+				})
+			})
+		})
+
+		Context("with an unknown content coding", func() {
+			BeforeEach(func() {
+				actionCompress = []interface{}{"zstd"}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unsupported compression content coding "zstd"`))
+			})
+		})
+
+		Context("with an invalid threshold", func() {
+			BeforeEach(func() {
+				apiCompress = []interface{}{"gzip", Threshold("1 potato")}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid compression threshold: invalid size "1 potato"`))
+			})
+		})
+	})
+
+	Context("with tags", func() {
+		var resourceTags, actionTags []string
+
+		BeforeEach(func() {
+			resourceTags = []string{"billing"}
+			actionTags = []string{"v2"}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", nil)
+			Resource("foo", func() {
+				Tags(resourceTags...)
+				Action("bar", func() {
+					Routing(GET("/bar"))
+					Tags(actionTags...)
+					Response(OK)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("does not produce an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		Context("with an empty tag name", func() {
+			BeforeEach(func() {
+				actionTags = []string{"v2", " "}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("tag names cannot be empty"))
+			})
+		})
+
+		Context("with no tag name", func() {
+			BeforeEach(func() {
+				resourceTags = []string{}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Tags requires at least one tag name"))
+			})
+		})
+	})
+
+	Context("with emitted events", func() {
+		var barEvents, bazEvents func()
+
+		BeforeEach(func() {
+			barEvents = func() { Emits("Created", "Created") }
+			bazEvents = func() {}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Created", func() {
+				Attribute("id", Integer)
+			})
+			Type("Name", nil)
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(POST("/bar"))
+					barEvents()
+				})
+				Action("baz", func() {
+					Routing(POST("/baz"))
+					bazEvents()
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("does not produce an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		Context("with an undeclared payload type", func() {
+			BeforeEach(func() {
+				barEvents = func() { Emits("Created", "Missing") }
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`event payload type "Missing" is not declared`))
+			})
+		})
+
+		Context("with a payload type that is not an object", func() {
+			BeforeEach(func() {
+				barEvents = func() { Emits("Created", "Name") }
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`event payload type "Name" must be an object`))
+			})
+		})
+
+		Context("with the same event emitted by two actions", func() {
+			BeforeEach(func() {
+				bazEvents = func() { Emits("Created", "Created") }
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`event "Created" is emitted by both actions "bar" and "baz"`))
+			})
+		})
+	})
+
+	Context("with a terraform resource", func() {
+		var name string
+		var update func()
+
+		BeforeEach(func() {
+			name = "user"
+			update = func() {
+				Action("update", func() {
+					Routing(PATCH("/:id"))
+					Payload(func() { Attribute("name", String) })
+					Response(NoContent)
+				})
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			user := MediaType("application/vnd.user+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+			})
+			Resource("user", func() {
+				Metadata("terraform:resource", name)
+				BasePath("/users")
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(func() { Attribute("name", String) })
+					Response(Created)
+				})
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Response(OK, user)
+				})
+				update()
+				Action("delete", func() {
+					Routing(DELETE("/:id"))
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("does not produce an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		Context("with a missing lifecycle action", func() {
+			BeforeEach(func() {
+				update = func() {}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`terraform resource "user" requires a "update" action`))
+			})
+		})
+
+		Context("with an invalid name", func() {
+			BeforeEach(func() {
+				name = "User"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid terraform resource name "User"`))
+			})
+		})
+	})
+
+	Context("with type inheritance", func() {
+		var kind, cat func()
+
+		BeforeEach(func() {
+			kind = func() {
+				Attribute("kind", String, func() {
+					Enum("Dog", "Cat")
+				})
+			}
+			cat = func() {}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			animal := Type("Animal", func() {
+				kind()
+				Attribute("name", String)
+				Discriminator("kind")
+			})
+			Type("Dog", func() {
+				Extend(animal)
+			})
+			Type("Cat", func() {
+				cat()
+				Extend(animal)
+			})
+			dslengine.Run()
+		})
+
+		It("accepts a discriminator listing the variants", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		Context("with a discriminator that is not an attribute", func() {
+			BeforeEach(func() {
+				kind = func() {}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`discriminator "kind" is not an attribute of the type`))
+			})
+		})
+
+		Context("with a discriminator that is not an enum", func() {
+			BeforeEach(func() {
+				kind = func() {
+					Attribute("kind", String)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`discriminator "kind" must be a string attribute with an enum validation`))
+			})
+		})
+
+		Context("with a discriminator that does not cover all the variants", func() {
+			BeforeEach(func() {
+				kind = func() {
+					Attribute("kind", String, func() {
+						Enum("Dog")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`enum values of discriminator "kind" must include "Cat"`))
+			})
+		})
+
+		Context("with a variant that uses the discriminator value of another variant", func() {
+			BeforeEach(func() {
+				cat = func() {
+					Attribute("kind", String, func() {
+						Enum("Dog")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`value "Dog" of discriminator "kind" is used by both "Cat" and "Dog"`))
+			})
+		})
+
+		Context("with a variant that does not map to a discriminator value", func() {
+			BeforeEach(func() {
+				cat = func() {
+					Attribute("kind", String)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`type "Cat" does not map to a value of discriminator "kind"`))
 			})
 		})
 	})
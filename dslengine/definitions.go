@@ -1,6 +1,9 @@
 package dslengine
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type (
 
@@ -100,9 +103,41 @@ type (
 		// MaxLength represents an maximum length validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor26.
 		MaxLength *int
+		// DecimalScale is the maximum number of fractional digits of decimal string
+		// attributes, see the "decimal" format.
+		DecimalScale *int
+		// DecimalPrecision is the maximum total number of digits of decimal string
+		// attributes, see the "decimal" format.
+		DecimalPrecision *int
+		// MinItems represents a minimum number of items validation of array attributes as
+		// described at http://json-schema.org/latest/json-schema-validation.html#anchor45.
+		MinItems *int
+		// MaxItems represents a maximum number of items validation of array attributes as
+		// described at http://json-schema.org/latest/json-schema-validation.html#anchor42.
+		MaxItems *int
+		// UniqueItems represents a unique items validation of array attributes as described
+		// at http://json-schema.org/latest/json-schema-validation.html#anchor49.
+		UniqueItems bool
 		// Required list the required fields of object attributes as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor61.
 		Required []string
+		// Bounds lists the bounds that the numeric fields of object attributes must satisfy
+		// relative to other fields of the same object.
+		Bounds []*BoundDefinition
+		// RequiredTogether lists the groups of fields of object attributes that must be either
+		// all present or all absent.
+		RequiredTogether [][]string
+	}
+
+	// BoundDefinition represents a bound between the values of two numeric fields of an
+	// object, e.g. discount <= price.
+	BoundDefinition struct {
+		// Field is the name of the bounded field.
+		Field string
+		// Operator is the comparison operator, one of "<", "<=", ">" or ">=".
+		Operator string
+		// Other is the name of the field that bounds Field.
+		Other string
 	}
 )
 
@@ -147,7 +182,22 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 	if v.MaxLength == nil || (other.MaxLength != nil && *v.MaxLength < *other.MaxLength) {
 		v.MaxLength = other.MaxLength
 	}
+	if v.DecimalScale == nil || (other.DecimalScale != nil && *v.DecimalScale < *other.DecimalScale) {
+		v.DecimalScale = other.DecimalScale
+	}
+	if v.DecimalPrecision == nil || (other.DecimalPrecision != nil && *v.DecimalPrecision < *other.DecimalPrecision) {
+		v.DecimalPrecision = other.DecimalPrecision
+	}
+	if v.MinItems == nil || (other.MinItems != nil && *v.MinItems > *other.MinItems) {
+		v.MinItems = other.MinItems
+	}
+	if v.MaxItems == nil || (other.MaxItems != nil && *v.MaxItems < *other.MaxItems) {
+		v.MaxItems = other.MaxItems
+	}
+	v.UniqueItems = v.UniqueItems || other.UniqueItems
 	v.AddRequired(other.Required)
+	v.AddBounds(other.Bounds)
+	v.AddRequiredTogether(other.RequiredTogether)
 }
 
 // AddRequired merges the required fields from other into v
@@ -166,6 +216,38 @@ func (v *ValidationDefinition) AddRequired(required []string) {
 	}
 }
 
+// AddBounds merges the given bounds into v.
+func (v *ValidationDefinition) AddBounds(bounds []*BoundDefinition) {
+	for _, b := range bounds {
+		found := false
+		for _, bb := range v.Bounds {
+			if *b == *bb {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.Bounds = append(v.Bounds, b)
+		}
+	}
+}
+
+// AddRequiredTogether merges the given groups of fields into v.
+func (v *ValidationDefinition) AddRequiredTogether(groups [][]string) {
+	for _, g := range groups {
+		found := false
+		for _, gg := range v.RequiredTogether {
+			if strings.Join(g, ",") == strings.Join(gg, ",") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.RequiredTogether = append(v.RequiredTogether, g)
+		}
+	}
+}
+
 // HasRequiredOnly returns true if the validation only has the Required field with a non-zero value.
 func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if len(v.Values) > 0 {
@@ -177,19 +259,35 @@ func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MaxLength != nil) {
 		return false
 	}
+	if (v.MinItems != nil) || (v.MaxItems != nil) || v.UniqueItems {
+		return false
+	}
+	if (v.DecimalScale != nil) || (v.DecimalPrecision != nil) {
+		return false
+	}
+	if len(v.Bounds) > 0 || len(v.RequiredTogether) > 0 {
+		return false
+	}
 	return true
 }
 
 // Dup makes a shallow dup of the validation.
 func (v *ValidationDefinition) Dup() *ValidationDefinition {
 	return &ValidationDefinition{
-		Values:    v.Values,
-		Format:    v.Format,
-		Pattern:   v.Pattern,
-		Minimum:   v.Minimum,
-		Maximum:   v.Maximum,
-		MinLength: v.MinLength,
-		MaxLength: v.MaxLength,
-		Required:  v.Required,
+		Values:           v.Values,
+		Format:           v.Format,
+		Pattern:          v.Pattern,
+		Minimum:          v.Minimum,
+		Maximum:          v.Maximum,
+		MinLength:        v.MinLength,
+		MaxLength:        v.MaxLength,
+		DecimalScale:     v.DecimalScale,
+		DecimalPrecision: v.DecimalPrecision,
+		MinItems:         v.MinItems,
+		MaxItems:         v.MaxItems,
+		UniqueItems:      v.UniqueItems,
+		Required:         v.Required,
+		Bounds:           v.Bounds,
+		RequiredTogether: v.RequiredTogether,
 	}
 }
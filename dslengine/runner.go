@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 )
 
@@ -13,6 +14,9 @@ var (
 	// Errors contains the DSL execution errors if any.
 	Errors MultiError
 
+	// Warnings contains the warnings reported while validating the DSL if any.
+	Warnings []string
+
 	// Global DSL evaluation stack
 	ctxStack contextStack
 
@@ -72,6 +76,7 @@ func Reset() {
 		r.Reset()
 	}
 	Errors = nil
+	Warnings = nil
 }
 
 // Run runs the given root definitions. It iterates over the definition sets
@@ -87,6 +92,7 @@ func Run() error {
 		return err
 	}
 	Errors = nil
+	Warnings = nil
 	executed := 0
 	recursed := 0
 	for executed < len(roots) {
@@ -178,6 +184,27 @@ func ReportError(fm string, vals ...interface{}) {
 	})
 }
 
+// ReportWarning records a DSL warning for the given definition. Warnings do not cause the DSL
+// run to fail, they are printed by PrintWarnings.
+func ReportWarning(def Definition, fm string, vals ...interface{}) {
+	Warnings = append(Warnings, fmt.Sprintf("%s: %s", def.Context(), fmt.Sprintf(fm, vals...)))
+}
+
+// WarningPrefix is the prefix of the lines written by PrintWarnings.
+const WarningPrefix = "warning: "
+
+// PrintWarnings prints the warnings recorded while running the DSL to stderr. The warnings are
+// sorted and printed only once even if reported multiple times.
+func PrintWarnings() {
+	sort.Strings(Warnings)
+	for i, w := range Warnings {
+		if i > 0 && w == Warnings[i-1] {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s%s\n", WarningPrefix, w)
+	}
+}
+
 // FailOnError will exit with code 1 if `err != nil`. This function
 // will handle properly the MultiError this dslengine provides.
 func FailOnError(err error) {
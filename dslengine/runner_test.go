@@ -1,6 +1,9 @@
 package dslengine_test
 
 import (
+	"io/ioutil"
+	"os"
+
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -68,7 +71,7 @@ var _ = Describe("DSL errors", func() {
 		const errMsg = "err"
 
 		// See NOTE below.
-		const lineNumber = 76
+		const lineNumber = 79
 
 		BeforeEach(func() {
 			// NOTE: moving the line below requires updating the
@@ -165,3 +168,33 @@ var _ = Describe("DSL errors", func() {
 		})
 	})
 })
+
+var _ = Describe("PrintWarnings", func() {
+	var stderr *os.File
+	var output string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dslengine.Warnings = []string{"b", "a", "b", "c", "a"}
+	})
+
+	JustBeforeEach(func() {
+		r, w, err := os.Pipe()
+		Ω(err).ShouldNot(HaveOccurred())
+		stderr, os.Stderr = os.Stderr, w
+		dslengine.PrintWarnings()
+		os.Stderr = stderr
+		w.Close()
+		b, err := ioutil.ReadAll(r)
+		Ω(err).ShouldNot(HaveOccurred())
+		output = string(b)
+	})
+
+	AfterEach(func() {
+		dslengine.Reset()
+	})
+
+	It("prints each warning once in sorted order", func() {
+		Ω(output).Should(Equal("warning: a\nwarning: b\nwarning: c\n"))
+	})
+})
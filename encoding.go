@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"mime"
+	"strings"
 	"sync"
 	"time"
 )
@@ -68,6 +69,19 @@ type (
 		pools        map[string]*encoderPool // Registered encoders
 		contentTypes []string                // List of content types for type negotiation
 	}
+
+	// codec is an encoder and decoder pair registered with RegisterCodec.
+	codec struct {
+		newEncoder EncoderFunc
+		newDecoder DecoderFunc
+	}
+)
+
+var (
+	// codecs lists the codecs registered with RegisterCodec indexed by content type.
+	codecs = make(map[string]*codec)
+	// codecsMu protects codecs.
+	codecsMu sync.RWMutex
 )
 
 // NewJSONEncoder is an adapter for the encoding package JSON encoder.
@@ -88,6 +102,50 @@ func NewGobEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
 // NewGobDecoder is an adapter for the encoding package gob decoder.
 func NewGobDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
 
+// RegisterCodec registers the encoder and decoder used for the given content type by the
+// generated services and clients, e.g.:
+//
+//	func init() {
+//		goa.RegisterCodec("application/cbor", cbor.NewEncoder, cbor.NewDecoder)
+//	}
+//
+// The generated code calls UseCodecs to register the codecs after the encoders and decoders listed
+// in the design so that a registered codec takes precedence for its content type. Either newEncoder
+// or newDecoder may be nil to only register a decoder or an encoder. RegisterCodec returns an error
+// if the content type is not a well-formed "type/subtype" media type.
+func RegisterCodec(contentType string, newEncoder EncoderFunc, newDecoder DecoderFunc) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid codec content type %#v: %s", contentType, err)
+	}
+	elems := strings.Split(mediaType, "/")
+	if len(elems) != 2 || elems[0] == "" || elems[1] == "" || strings.Contains(mediaType, "*") {
+		return fmt.Errorf("invalid codec content type %#v: must be of the form type/subtype", contentType)
+	}
+	if newEncoder == nil && newDecoder == nil {
+		return fmt.Errorf("codec for %#v must define an encoder or a decoder", contentType)
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[mediaType] = &codec{newEncoder: newEncoder, newDecoder: newDecoder}
+	return nil
+}
+
+// UseCodecs registers the codecs registered with RegisterCodec with the given encoder and
+// decoder, either may be nil.
+func UseCodecs(encoder *HTTPEncoder, decoder *HTTPDecoder) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	for contentType, c := range codecs {
+		if encoder != nil && c.newEncoder != nil {
+			encoder.Register(c.newEncoder, contentType)
+		}
+		if decoder != nil && c.newDecoder != nil {
+			decoder.Register(c.newDecoder, contentType)
+		}
+	}
+}
+
 // NewHTTPEncoder creates an encoder that maps HTTP content types to low level encoders.
 func NewHTTPEncoder() *HTTPEncoder {
 	return &HTTPEncoder{
@@ -116,6 +174,13 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 		}
 	}
 	p = decoder.pools[contentType]
+	if p == nil {
+		// Use the decoder registered for the structured syntax suffix (RFC 6839) if any, e.g.
+		// the JSON decoder for "application/merge-patch+json".
+		if i := strings.LastIndex(contentType, "+"); i != -1 {
+			p = decoder.pools["application/"+contentType[i+1:]]
+		}
+	}
 	if p == nil {
 		p = decoder.pools["*/*"]
 	}
@@ -0,0 +1,157 @@
+package goa_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// lazyJSONDecoder is a JSON decoder that creates the underlying decoder on first use.
+type lazyJSONDecoder struct {
+	r io.Reader
+}
+
+func (d *lazyJSONDecoder) Decode(v interface{}) error {
+	return json.NewDecoder(d.r).Decode(v)
+}
+
+func newLazyJSONDecoder(r io.Reader) goa.Decoder {
+	return &lazyJSONDecoder{r: r}
+}
+
+var _ = Describe("HTTPDecoder", func() {
+	var decoder *goa.HTTPDecoder
+
+	BeforeEach(func() {
+		decoder = goa.NewHTTPDecoder()
+		decoder.Register(newLazyJSONDecoder, "application/json")
+	})
+
+	Context("with a content type that uses a structured syntax suffix", func() {
+		type patch struct {
+			Name    goa.NullableString  `json:"name"`
+			Rating  goa.NullableInteger `json:"rating"`
+			Vintage goa.NullableInteger `json:"vintage"`
+		}
+
+		var payload patch
+		var err error
+
+		JustBeforeEach(func() {
+			body := `{"name": "Number 8", "rating": null}`
+			err = decoder.Decode(&payload, strings.NewReader(body), "application/merge-patch+json")
+		})
+
+		It("uses the decoder registered for the suffix", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(payload.Name).Should(Equal(goa.NullableString{Present: true, Value: "Number 8"}))
+		})
+
+		It("records null fields as present and null", func() {
+			Ω(payload.Rating).Should(Equal(goa.NullableInteger{Present: true, Null: true}))
+		})
+
+		It("records absent fields as not present", func() {
+			Ω(payload.Vintage.Present).Should(BeFalse())
+		})
+	})
+})
+
+// cborEncoder is a fake CBOR encoder that writes the value prefixed with "cbor:".
+type cborEncoder struct {
+	w io.Writer
+}
+
+func (e *cborEncoder) Encode(v interface{}) error {
+	_, err := fmt.Fprintf(e.w, "cbor:%v", v)
+	return err
+}
+
+// cborDecoder is a fake CBOR decoder that strips the "cbor:" prefix written by cborEncoder.
+type cborDecoder struct {
+	r io.Reader
+}
+
+func (d *cborDecoder) Decode(v interface{}) error {
+	b, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	*(v.(*string)) = strings.TrimPrefix(string(b), "cbor:")
+	return nil
+}
+
+var _ = Describe("RegisterCodec", func() {
+	var contentType string
+	var err error
+
+	JustBeforeEach(func() {
+		err = goa.RegisterCodec(contentType,
+			func(w io.Writer) goa.Encoder { return &cborEncoder{w: w} },
+			func(r io.Reader) goa.Decoder { return &cborDecoder{r: r} })
+	})
+
+	Context("with a CBOR codec", func() {
+		var encoder *goa.HTTPEncoder
+		var decoder *goa.HTTPDecoder
+
+		BeforeEach(func() {
+			contentType = "application/cbor"
+			encoder = goa.NewHTTPEncoder()
+			encoder.Register(goa.NewJSONEncoder, "application/json")
+			decoder = goa.NewHTTPDecoder()
+			decoder.Register(goa.NewJSONDecoder, "application/json")
+		})
+
+		JustBeforeEach(func() {
+			goa.UseCodecs(encoder, decoder)
+		})
+
+		It("uses the codec encoder for the content type", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			var buf bytes.Buffer
+			Ω(encoder.Encode("wine", &buf, "application/cbor")).ShouldNot(HaveOccurred())
+			Ω(buf.String()).Should(Equal("cbor:wine"))
+		})
+
+		It("uses the codec decoder for the content type", func() {
+			var v string
+			Ω(decoder.Decode(&v, strings.NewReader("cbor:wine"), "application/cbor; charset=utf-8")).ShouldNot(HaveOccurred())
+			Ω(v).Should(Equal("wine"))
+		})
+
+		It("keeps the other encoders", func() {
+			var buf bytes.Buffer
+			Ω(encoder.Encode("wine", &buf, "application/json")).ShouldNot(HaveOccurred())
+			Ω(buf.String()).Should(Equal("\"wine\"\n"))
+		})
+	})
+
+	Context("with a malformed content type", func() {
+		BeforeEach(func() {
+			contentType = "cbor"
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("must be of the form type/subtype"))
+		})
+	})
+
+	Context("with a wildcard content type", func() {
+		BeforeEach(func() {
+			contentType = "application/*"
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Envelope wraps a request or response body in an object that stores the body under the
+// DataField field and a meta object under the MetaField field, e.g.
+//
+//	{"data": {"id": 1}, "meta": {"request_id": "abc"}}
+//
+// Envelope implements json.Marshaler and json.Unmarshaler so that it may be given to the service
+// encoders and decoders in place of the body. The generated code wraps the request payloads and
+// response media types of the actions whose design defines a body envelope. Only JSON bodies
+// are supported.
+type Envelope struct {
+	// DataField is the name of the envelope field that contains the body.
+	DataField string
+	// MetaField is the name of the envelope field that contains the meta object, the
+	// envelope has no meta object if empty.
+	MetaField string
+	// Data is the wrapped body. Unmarshaling decodes the data field into Data which must
+	// then be a pointer.
+	Data interface{}
+	// Meta is the envelope meta object.
+	Meta map[string]interface{}
+}
+
+// MarshalJSON renders the envelope, the meta object is rendered as an empty object if nil.
+func (e *Envelope) MarshalJSON() ([]byte, error) {
+	env := map[string]interface{}{e.DataField: e.Data}
+	if e.MetaField != "" {
+		meta := e.Meta
+		if meta == nil {
+			meta = map[string]interface{}{}
+		}
+		env[e.MetaField] = meta
+	}
+	return json.Marshal(env)
+}
+
+// UnmarshalJSON decodes the data field of the envelope into Data and the meta field into Meta.
+// It returns an error if the data field is missing.
+func (e *Envelope) UnmarshalJSON(b []byte) error {
+	var env map[string]json.RawMessage
+	if err := json.Unmarshal(b, &env); err != nil {
+		return err
+	}
+	data, ok := env[e.DataField]
+	if !ok {
+		return fmt.Errorf("missing envelope field %#v", e.DataField)
+	}
+	if err := json.Unmarshal(data, e.Data); err != nil {
+		return err
+	}
+	if e.MetaField != "" {
+		if meta, ok := env[e.MetaField]; ok {
+			e.Meta = nil
+			return json.Unmarshal(meta, &e.Meta)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Envelope", func() {
+	type bottle struct {
+		Name string `json:"name"`
+	}
+
+	It("extracts the inner payload and the meta object of a decoded envelope", func() {
+		var b bottle
+		env := &goa.Envelope{DataField: "data", MetaField: "meta", Data: &b}
+		err := json.Unmarshal([]byte(`{"data":{"name":"a"},"meta":{"trace":"t1"}}`), env)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b.Name).Should(Equal("a"))
+		Ω(env.Meta).Should(Equal(map[string]interface{}{"trace": "t1"}))
+	})
+
+	It("re-wraps the payload when encoding", func() {
+		env := &goa.Envelope{DataField: "data", MetaField: "meta", Data: &bottle{Name: "a"}}
+		b, err := json.Marshal(env)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"data":{"name":"a"},"meta":{}}`))
+	})
+
+	It("omits the meta object if the envelope does not define one", func() {
+		b, err := json.Marshal(&goa.Envelope{DataField: "result", Data: []int{1, 2}})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"result":[1,2]}`))
+	})
+
+	It("fails to decode bodies that are missing the data field", func() {
+		var b bottle
+		err := json.Unmarshal([]byte(`{"name":"a"}`), &goa.Envelope{DataField: "data", Data: &b})
+		Ω(err).Should(MatchError(`missing envelope field "data"`))
+	})
+
+	It("unwraps request bodies decoded by the service", func() {
+		service := goa.New("test")
+		service.Decoder.Register(goa.NewJSONDecoder, "application/json")
+		req, err := http.NewRequest("POST", "/", strings.NewReader(`{"data":{"name":"a"},"meta":{"page":1}}`))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Content-Type", "application/json")
+		var b bottle
+		env := &goa.Envelope{DataField: "data", MetaField: "meta", Data: &b}
+		Ω(service.DecodeRequest(req, env)).ShouldNot(HaveOccurred())
+		Ω(b.Name).Should(Equal("a"))
+		Ω(env.Meta).Should(HaveKeyWithValue("page", 1.0))
+	})
+})
@@ -34,6 +34,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 )
 
@@ -41,6 +42,10 @@ var (
 	// ErrorMediaIdentifier is the media type identifier used for error responses.
 	ErrorMediaIdentifier = "application/vnd.goa.error"
 
+	// ProblemDetailsMediaIdentifier is the media type identifier used for error responses
+	// rendered as problem details (RFC 7807).
+	ProblemDetailsMediaIdentifier = "application/problem+json"
+
 	// ErrBadRequest is a generic bad request error.
 	ErrBadRequest = NewErrorClass("bad_request", 400)
 
@@ -58,10 +63,34 @@ var (
 	// MaxRequestBodyLength bytes.
 	ErrRequestBodyTooLarge = NewErrorClass("request_too_large", 413)
 
+	// ErrLengthRequired is the error produced when a request that must set the Content-Length
+	// header does not.
+	ErrLengthRequired = NewErrorClass("length_required", 411)
+
+	// ErrUnsupportedMediaType is the error produced when the content type of a request body is
+	// not one of the content types accepted by the action.
+	ErrUnsupportedMediaType = NewErrorClass("unsupported_media_type", 415)
+
 	// ErrNoAuthMiddleware is the error produced when no auth middleware is mounted for a
 	// security scheme defined in the design.
 	ErrNoAuthMiddleware = NewErrorClass("no_auth_middleware", 500)
 
+	// ErrInvalidSignature is the error produced when the signature of a request body cannot be
+	// verified.
+	ErrInvalidSignature = NewErrorClass("invalid_signature", 401)
+
+	// ErrNoSignatureVerifier is the error produced when a request body signature must be
+	// verified but the service has no SignatureVerifier.
+	ErrNoSignatureVerifier = NewErrorClass("no_signature_verifier", 500)
+
+	// ErrNoDedupeStore is the error produced when the duplicates of a request must be detected
+	// but the service has no DedupeStore.
+	ErrNoDedupeStore = NewErrorClass("no_dedupe_store", 500)
+
+	// ErrUnsupportedVersion is the error produced when a request selects a version of a
+	// resource that does not exist.
+	ErrUnsupportedVersion = NewErrorClass("unsupported_version", 406)
+
 	// ErrInvalidFile is the error produced by ServeFiles when requested to serve non-existant
 	// or non-readable files.
 	ErrInvalidFile = NewErrorClass("invalid_file", 404)
@@ -123,8 +152,33 @@ type (
 		// Meta contains additional key/value pairs useful to clients.
 		Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
 	}
+
+	// ProblemDetails is the representation of errors as problem details documents as defined
+	// by RFC 7807. The code and meta members extend the standard members so that clients may
+	// build the corresponding ErrorResponse.
+	ProblemDetails struct {
+		// Type is the URI reference that identifies the problem type. It is made of a base URI
+		// followed by the error code.
+		Type string `json:"type" yaml:"type" xml:"type" form:"type"`
+		// Title is the summary of the problem type, the HTTP status text.
+		Title string `json:"title" yaml:"title" xml:"title" form:"title"`
+		// Status is the HTTP status code used by responses that cary the error.
+		Status int `json:"status" yaml:"status" xml:"status" form:"status"`
+		// Detail describes the specific error occurrence.
+		Detail string `json:"detail,omitempty" yaml:"detail,omitempty" xml:"detail,omitempty" form:"detail,omitempty"`
+		// Instance is the URI reference that identifies the specific error occurrence. It is
+		// built from the unique error instance identifier.
+		Instance string `json:"instance,omitempty" yaml:"instance,omitempty" xml:"instance,omitempty" form:"instance,omitempty"`
+		// Code identifies the class of errors.
+		Code string `json:"code" yaml:"code" xml:"code" form:"code"`
+		// Meta contains additional key/value pairs useful to clients.
+		Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+	}
 )
 
+// problemInstancePrefix is the prefix of the problem details instance URIs.
+const problemInstancePrefix = "urn:goa:error:"
+
 // NewErrorClass creates a new error class.
 // It is the responsibility of the client to guarantee uniqueness of code.
 func NewErrorClass(code string, status int) ErrorClass {
@@ -157,6 +211,45 @@ func NewErrorClass(code string, status int) ErrorClass {
 	}
 }
 
+// NewProblemDetails builds the problem details document describing the given error. The problem
+// type is the error code appended to typeBase. Errors that are not ErrorResponse values are
+// described as internal errors.
+func NewProblemDetails(err error, typeBase string) *ProblemDetails {
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		e = ErrInternal(err).(*ErrorResponse)
+		if se, ok := err.(ServiceError); ok {
+			e.ID = se.Token()
+			e.Status = se.ResponseStatus()
+		}
+	}
+	return &ProblemDetails{
+		Type:     typeBase + e.Code,
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Detail,
+		Instance: problemInstancePrefix + e.ID,
+		Code:     e.Code,
+		Meta:     e.Meta,
+	}
+}
+
+// ErrorResponse builds the error response described by the problem details.
+func (p *ProblemDetails) ErrorResponse() *ErrorResponse {
+	code := p.Code
+	if code == "" {
+		// Problem details produced by another server, use the last segment of the type URI.
+		code = p.Type[strings.LastIndexAny(p.Type, "/#:")+1:]
+	}
+	return &ErrorResponse{
+		ID:     strings.TrimPrefix(p.Instance, problemInstancePrefix),
+		Code:   code,
+		Status: p.Status,
+		Detail: p.Detail,
+		Meta:   p.Meta,
+	}
+}
+
 // MissingPayloadError is the error produced when a request is missing a required payload.
 func MissingPayloadError() error {
 	return ErrInvalidRequest("missing required payload")
@@ -242,6 +335,89 @@ func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool)
 	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
 }
 
+// InvalidDecimalError is the error produced when the value of a parameter or payload field does
+// not match the decimal scale or precision validation defined in the design. digits is the number
+// of fractional digits of target if scale is true, its total number of digits otherwise.
+func InvalidDecimalError(ctx, target string, digits, value int, scale bool) error {
+	desc := "digits"
+	if scale {
+		desc = "fractional digits"
+	}
+	msg := fmt.Sprintf("%s must have at most %d %s but got value %#v (%d %s)", ctx, value, desc, target, digits, desc)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "digits", digits, "expected", value)
+}
+
+// InvalidBoundError is the error produced when the value of a payload field does not satisfy the
+// bound relative to another field defined in the design with the Bound DSL. comp is the comparison
+// operator, one of "<", "<=", ">" or ">=".
+func InvalidBoundError(ctx, field string, value interface{}, comp, other string, otherValue interface{}) error {
+	var desc string
+	switch comp {
+	case "<":
+		desc = "less than"
+	case "<=":
+		desc = "less than or equal to"
+	case ">":
+		desc = "greater than"
+	default:
+		desc = "greater than or equal to"
+	}
+	msg := fmt.Sprintf("attribute %#v of %s must be %s attribute %#v (%v) but got value %#v", field, ctx, desc, other, otherValue, value)
+	return ErrInvalidRequest(msg, "attribute", field, "parent", ctx, "value", value, "comp", comp, "bound", other, "expected", otherValue)
+}
+
+// RequiredTogetherError is the error produced when some but not all the fields of a group of
+// payload fields defined in the design with the RequiredTogether DSL are present.
+func RequiredTogetherError(ctx string, group []string) error {
+	elems := make([]string, len(group))
+	for i, g := range group {
+		elems[i] = fmt.Sprintf("%#v", g)
+	}
+	msg := fmt.Sprintf("attributes %s of %s must be provided together or not at all", strings.Join(elems, ", "), ctx)
+	return ErrInvalidRequest(msg, "attributes", group, "parent", ctx)
+}
+
+// InvalidTransitionError is the error produced when the value of a payload field transitions from
+// a value to another that is not allowed by the transitions defined in the design with the
+// Transitions DSL. allowed lists the values the field may transition to from the old value.
+func InvalidTransitionError(ctx, from, to string, allowed []string) error {
+	elems := make([]string, len(allowed))
+	for i, a := range allowed {
+		elems[i] = fmt.Sprintf("%#v", a)
+	}
+	var msg string
+	if len(elems) == 0 {
+		msg = fmt.Sprintf("%s cannot transition from %#v", ctx, from)
+	} else {
+		msg = fmt.Sprintf("%s can only transition from %#v to %s but got value %#v", ctx, from, strings.Join(elems, ", "), to)
+	}
+	return ErrInvalidRequest(msg, "attribute", ctx, "from", from, "value", to, "expected", strings.Join(elems, ", "))
+}
+
+// DuplicateItemError is the error produced when the value of an array parameter or payload field
+// contains the same item twice but the design requires unique items. index is the index of the
+// duplicate item and first the index of the first occurrence of the same value.
+func DuplicateItemError(ctx string, item interface{}, index, first int) error {
+	msg := fmt.Sprintf("%s must contain unique items but item at index %d (%#v) duplicates item at index %d", ctx, index, item, first)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", item, "index", index, "duplicate_of", first)
+}
+
+// WithI18nKey sets the "i18n_key" metadata of the given error to key so that clients may look up
+// a translated message for the error instead of relying on its English detail. The code generated
+// by goagen calls WithI18nKey on the validation errors of attributes that define the "i18n:key"
+// metadata. WithI18nKey returns err unchanged if it was not created via an error class.
+func WithI18nKey(err error, key string) error {
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		return err
+	}
+	if e.Meta == nil {
+		e.Meta = make(map[string]interface{})
+	}
+	e.Meta["i18n_key"] = key
+	return e
+}
+
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
 // security scheme defined in the design.
 func NoAuthMiddleware(schemeName string) error {
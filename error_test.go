@@ -32,6 +32,55 @@ var _ = Describe("Error", func() {
 	})
 })
 
+var _ = Describe("ProblemDetails", func() {
+	const typeBase = "https://goa.design/problems/"
+
+	var err error
+	var problem *ProblemDetails
+
+	JustBeforeEach(func() {
+		problem = NewProblemDetails(err, typeBase)
+	})
+
+	Context("with a named error", func() {
+		BeforeEach(func() {
+			err = &ErrorResponse{ID: "foo", Code: "invalid_value", Status: 400, Detail: "error", Meta: map[string]interface{}{"what": 42}}
+		})
+
+		It("serializes to a problem details document", func() {
+			b, err := json.Marshal(problem)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(Equal(`{"type":"https://goa.design/problems/invalid_value","title":"Bad Request","status":400,"detail":"error","instance":"urn:goa:error:foo","code":"invalid_value","meta":{"what":42}}`))
+		})
+
+		It("builds the original error response", func() {
+			Ω(problem.ErrorResponse()).Should(Equal(err))
+		})
+	})
+
+	Context("with a Go error", func() {
+		BeforeEach(func() {
+			err = errors.New("boom")
+		})
+
+		It("describes an internal error", func() {
+			Ω(problem.Type).Should(Equal(typeBase + "internal"))
+			Ω(problem.Title).Should(Equal("Internal Server Error"))
+			Ω(problem.Status).Should(Equal(500))
+			Ω(problem.Detail).Should(Equal("boom"))
+		})
+	})
+
+	Context("decoded from a document without code", func() {
+		It("uses the type URI to build the error code", func() {
+			var p ProblemDetails
+			Ω(json.Unmarshal([]byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403}`), &p)).Should(Succeed())
+			Ω(p.ErrorResponse().Code).Should(Equal("out-of-credit"))
+			Ω(p.ErrorResponse().Status).Should(Equal(403))
+		})
+	})
+})
+
 var _ = Describe("InvalidParamTypeError", func() {
 	var valErr error
 	name := "param"
@@ -106,6 +155,27 @@ var _ = Describe("MissingAttributeError", func() {
 	})
 })
 
+var _ = Describe("WithI18nKey", func() {
+	var err error
+
+	It("sets the key in the error metadata", func() {
+		err = WithI18nKey(InvalidRangeError("ctx", 1, 2, true), "errors.ctx")
+		Ω(err).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		Ω(err.(*ErrorResponse).Meta).Should(HaveKeyWithValue("i18n_key", "errors.ctx"))
+		Ω(err.(*ErrorResponse).Meta).Should(HaveKeyWithValue("attribute", "ctx"))
+	})
+
+	It("keeps the key when merged", func() {
+		err = MergeErrors(nil, WithI18nKey(MissingAttributeError("ctx", "name"), "errors.name"))
+		Ω(err.(*ErrorResponse).Meta).Should(HaveKeyWithValue("i18n_key", "errors.name"))
+	})
+
+	It("leaves other errors unchanged", func() {
+		orig := errors.New("foo")
+		Ω(WithI18nKey(orig, "errors.foo")).Should(Equal(orig))
+	})
+})
+
 var _ = Describe("MissingHeaderError", func() {
 	var valErr error
 	name := "param"
@@ -269,6 +339,45 @@ var _ = Describe("InvalidRangeError", func() {
 	})
 })
 
+var _ = Describe("InvalidDecimalError", func() {
+	const ctx = "ctx"
+	const target = "12.345"
+	const value = 2
+
+	var scale bool
+
+	var valErr error
+
+	JustBeforeEach(func() {
+		valErr = InvalidDecimalError(ctx, target, 3, value, scale)
+	})
+
+	Context("on scale", func() {
+		BeforeEach(func() {
+			scale = true
+		})
+
+		It("creates a http error", func() {
+			Ω(valErr).ShouldNot(BeNil())
+			Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+			err := valErr.(*ErrorResponse)
+			Ω(err.Detail).Should(Equal(`ctx must have at most 2 fractional digits but got value "12.345" (3 fractional digits)`))
+		})
+	})
+
+	Context("on precision", func() {
+		BeforeEach(func() {
+			scale = false
+		})
+
+		It("creates a http error", func() {
+			Ω(valErr).ShouldNot(BeNil())
+			err := valErr.(*ErrorResponse)
+			Ω(err.Detail).Should(Equal(`ctx must have at most 2 digits but got value "12.345" (3 digits)`))
+		})
+	})
+})
+
 var _ = Describe("InvalidLengthError", func() {
 	const ctx = "ctx"
 	const value = 42
@@ -318,6 +427,31 @@ var _ = Describe("InvalidLengthError", func() {
 	})
 })
 
+var _ = Describe("DuplicateItemError", func() {
+	const ctx = "ctx"
+	const item = "dup"
+	const index = 3
+	const first = 1
+
+	var valErr error
+
+	JustBeforeEach(func() {
+		valErr = DuplicateItemError(ctx, item, index, first)
+	})
+
+	It("creates a http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(400))
+		Ω(err.Detail).Should(ContainSubstring(ctx))
+		Ω(err.Detail).Should(ContainSubstring("index 3"))
+		Ω(err.Detail).Should(ContainSubstring(fmt.Sprintf("%#v", item)))
+		Ω(err.Meta).Should(HaveKeyWithValue("index", index))
+		Ω(err.Meta).Should(HaveKeyWithValue("duplicate_of", first))
+	})
+})
+
 // MergeableErrorResponse contains the details of a error response.
 // It implements ServiceMergeableError.
 type MergeableErrorResponse struct {
@@ -601,3 +735,32 @@ var _ = Describe("Merge", func() {
 	})
 
 })
+
+var _ = Describe("InvalidBoundError", func() {
+	It("describes the violated bound", func() {
+		err := InvalidBoundError("request.body", "discount", 12.0, "<=", "price", 10.0)
+		Ω(err.Error()).Should(ContainSubstring(`attribute "discount" of request.body must be less than or equal to attribute "price" (10) but got value 12`))
+		Ω(err.(*ErrorResponse).Meta["bound"]).Should(Equal("price"))
+	})
+})
+
+var _ = Describe("RequiredTogetherError", func() {
+	It("names the group", func() {
+		err := RequiredTogetherError("request.body", []string{"lat", "lng"})
+		Ω(err.Error()).Should(ContainSubstring(`attributes "lat", "lng" of request.body must be provided together or not at all`))
+		Ω(err.(*ErrorResponse).Meta["attributes"]).Should(Equal([]string{"lat", "lng"}))
+	})
+})
+
+var _ = Describe("InvalidTransitionError", func() {
+	It("describes the allowed transitions", func() {
+		err := InvalidTransitionError("request.body.status", "pending", "closed", []string{"active", "cancelled"})
+		Ω(err.Error()).Should(ContainSubstring(`request.body.status can only transition from "pending" to "active", "cancelled" but got value "closed"`))
+		Ω(err.(*ErrorResponse).Meta["from"]).Should(Equal("pending"))
+	})
+
+	It("describes final states", func() {
+		err := InvalidTransitionError("request.body.status", "closed", "active", nil)
+		Ω(err.Error()).Should(ContainSubstring(`request.body.status cannot transition from "closed"`))
+	})
+})
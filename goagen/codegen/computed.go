@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+)
+
+var computeFieldsT *template.Template
+
+func init() {
+	computeFieldsT = template.Must(template.New("computeFields").Parse(computeFieldsTmpl))
+}
+
+// ComputeFields produces the ComputeFields method of the struct generated for the given media
+// type attribute when some of its fields are computed with the function set in the
+// "compute:function" metadata. The method of collections computes the fields of each element.
+// ComputeFields returns the empty string if none of the fields are computed.
+func ComputeFields(att *design.AttributeDefinition, typeName string) string {
+	if len(att.ComputedFields()) == 0 {
+		return ""
+	}
+	data := map[string]interface{}{"TypeName": typeName}
+	if att.Type.IsArray() {
+		data["Collection"] = true
+		return RunTemplate(computeFieldsT, data)
+	}
+	obj := att.Type.ToObject()
+	var fields []map[string]interface{}
+	for _, n := range att.ComputedFields() {
+		catt := obj[n]
+		var args []string
+		for _, arg := range catt.Metadata["compute:args"] {
+			args = append(args, "mt."+GoifyAtt(obj[arg], arg, true))
+		}
+		fields = append(fields, map[string]interface{}{
+			"Name":     GoifyAtt(catt, n, true),
+			"VarName":  Goify(n, false),
+			"Function": catt.Metadata["compute:function"][0],
+			"Args":     args,
+			"Pointer":  isPointerField(att, n, false),
+		})
+	}
+	data["Fields"] = fields
+	return RunTemplate(computeFieldsT, data)
+}
+
+const computeFieldsTmpl = `{{ if .Collection }}// ComputeFields sets the computed fields of the {{ .TypeName }} elements.
+func (mt {{ .TypeName }}) ComputeFields() {
+	for _, e := range mt {
+		if e != nil {
+			e.ComputeFields()
+		}
+	}
+}
+{{ else }}// ComputeFields sets the computed fields of the {{ .TypeName }} instance.
+func (mt *{{ .TypeName }}) ComputeFields() {
+{{ range .Fields }}{{ if .Pointer }}	{{ .VarName }} := {{ .Function }}({{ range $i, $a := .Args }}{{ if $i }}, {{ end }}{{ $a }}{{ end }})
+	mt.{{ .Name }} = &{{ .VarName }}
+{{ else }}	mt.{{ .Name }} = {{ .Function }}({{ range $i, $a := .Args }}{{ if $i }}, {{ end }}{{ $a }}{{ end }})
+{{ end }}{{ end }}}
+{{ end }}`
@@ -0,0 +1,77 @@
+package codegen_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ComputeFields", func() {
+	var att *AttributeDefinition
+	var typeName string
+	var code string
+
+	JustBeforeEach(func() {
+		code = codegen.ComputeFields(att, typeName)
+	})
+
+	Context("with no computed field", func() {
+		BeforeEach(func() {
+			att = &AttributeDefinition{Type: Object{"name": &AttributeDefinition{Type: String}}}
+			typeName = "Person"
+		})
+
+		It("produces no code", func() {
+			Ω(code).Should(BeEmpty())
+		})
+	})
+
+	Context("with a computed field", func() {
+		var person *MediaTypeDefinition
+
+		BeforeEach(func() {
+			person = &MediaTypeDefinition{
+				UserTypeDefinition: &UserTypeDefinition{
+					AttributeDefinition: &AttributeDefinition{
+						Type: Object{
+							"first": &AttributeDefinition{Type: String},
+							"last":  &AttributeDefinition{Type: String},
+							"full_name": &AttributeDefinition{
+								Type: String,
+								Metadata: dslengine.MetadataDefinition{
+									"compute:function": []string{"names.FullName", "example.com/names"},
+									"compute:args":     []string{"first", "last"},
+								},
+							},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"first"}},
+					},
+					TypeName: "Person",
+				},
+				Identifier: "application/vnd.person",
+			}
+			att = person.AttributeDefinition
+			typeName = "Person"
+		})
+
+		It("sets the field with the compute function", func() {
+			Ω(code).Should(ContainSubstring("func (mt *Person) ComputeFields() {"))
+			Ω(code).Should(ContainSubstring("fullName := names.FullName(mt.First, mt.Last)"))
+			Ω(code).Should(ContainSubstring("mt.FullName = &fullName"))
+		})
+
+		Context("in a collection", func() {
+			BeforeEach(func() {
+				att = &AttributeDefinition{Type: &Array{ElemType: &AttributeDefinition{Type: person}}}
+				typeName = "PersonCollection"
+			})
+
+			It("computes the fields of the elements", func() {
+				Ω(code).Should(ContainSubstring("func (mt PersonCollection) ComputeFields() {"))
+				Ω(code).Should(ContainSubstring("e.ComputeFields()"))
+			})
+		})
+	})
+})
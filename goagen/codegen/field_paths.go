@@ -0,0 +1,74 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+)
+
+// FieldPaths produces the Go expression that initializes a struct with one field per attribute of
+// the given object attribute. The fields of the object and array of objects attributes are
+// structs that list the paths of their child attributes recursively, the other fields hold the
+// canonical path of their attribute: the path used by the generated validation code without its
+// root context, for example "address.zip" or "items[*].name". Hand-written errors may use these
+// paths so that they reference the attributes the same way the validation errors do.
+// FieldPaths returns the empty string if att is not an object.
+func FieldPaths(att *design.AttributeDefinition) string {
+	if att.Type.ToObject() == nil {
+		return ""
+	}
+	typ, val := fieldPaths(att, "", 0, nil)
+	return typ + val
+}
+
+// fieldPaths returns the Go struct type and literal value that hold the paths of the children of
+// the given object attribute. The paths are prefixed with prefix and the code is indented at
+// depth.
+func fieldPaths(att *design.AttributeDefinition, prefix string, depth int, seen []string) (string, string) {
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok {
+		seen = append(seen, ut.TypeName)
+	} else if mt, ok := att.Type.(*design.MediaTypeDefinition); ok {
+		seen = append(seen, mt.TypeName)
+	}
+	var typ, val []string
+	att.Type.ToObject().IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+		field := GoifyAtt(catt, n, true)
+		path := prefix + n
+		child := catt
+		if arr := catt.Type.ToArray(); arr != nil {
+			child = arr.ElemType
+			path += "[*]"
+		}
+		if child.Type.ToObject() != nil && !isSeen(child, seen) {
+			ctyp, cval := fieldPaths(child, path+".", depth+1, seen)
+			typ = append(typ, fmt.Sprintf("%s%s %s", Tabs(depth+1), field, ctyp))
+			val = append(val, fmt.Sprintf("%s%s: %s%s,", Tabs(depth+1), field, ctyp, cval))
+			return nil
+		}
+		typ = append(typ, fmt.Sprintf("%s%s string", Tabs(depth+1), field))
+		val = append(val, fmt.Sprintf("%s%s: %q,", Tabs(depth+1), field, prefix+n))
+		return nil
+	})
+	end := "\n" + Tabs(depth) + "}"
+	return "struct {\n" + strings.Join(typ, "\n") + end, "{\n" + strings.Join(val, "\n") + end
+}
+
+// isSeen returns true if att is a user type or media type whose name is in seen.
+func isSeen(att *design.AttributeDefinition, seen []string) bool {
+	var name string
+	switch t := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		name = t.TypeName
+	case *design.MediaTypeDefinition:
+		name = t.TypeName
+	default:
+		return false
+	}
+	for _, s := range seen {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
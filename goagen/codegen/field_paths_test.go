@@ -0,0 +1,80 @@
+package codegen_test
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FieldPaths", func() {
+	var att *design.AttributeDefinition
+	var paths string
+
+	BeforeEach(func() {
+		zip := &design.AttributeDefinition{
+			Type:       design.String,
+			Validation: &dslengine.ValidationDefinition{Pattern: "^[0-9]{5}$"},
+		}
+		att = &design.AttributeDefinition{
+			Type: design.Object{
+				"name": &design.AttributeDefinition{Type: design.String},
+				"address": &design.AttributeDefinition{
+					Type: design.Object{"zip": zip},
+				},
+				"items": &design.AttributeDefinition{
+					Type: &design.Array{ElemType: &design.AttributeDefinition{
+						Type: design.Object{"sku": &design.AttributeDefinition{Type: design.String}},
+					}},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		paths = codegen.FieldPaths(att)
+	})
+
+	It("produces a nested struct of attribute paths", func() {
+		Ω(paths).Should(Equal(fieldPathsCode))
+	})
+
+	It("uses the paths produced by the validation code", func() {
+		code := codegen.NewValidator().Code(att, false, false, false, "payload", "raw", 1, false)
+		Ω(paths).Should(ContainSubstring(`Zip: "address.zip",`))
+		Ω(code).Should(ContainSubstring("goa.InvalidPatternError(`raw.address.zip`"))
+	})
+
+	Context("with a type that is not an object", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{Type: design.String}
+		})
+
+		It("produces no code", func() {
+			Ω(paths).Should(BeEmpty())
+		})
+	})
+})
+
+const fieldPathsCode = `struct {
+	Address struct {
+		Zip string
+	}
+	Items struct {
+		Sku string
+	}
+	Name string
+}{
+	Address: struct {
+		Zip string
+	}{
+		Zip: "address.zip",
+	},
+	Items: struct {
+		Sku string
+	}{
+		Sku: "items[*].sku",
+	},
+	Name: "name",
+}`
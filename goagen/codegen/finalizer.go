@@ -12,6 +12,7 @@ import (
 type Finalizer struct {
 	assignmentT      *template.Template
 	arrayAssignmentT *template.Template
+	defaultFromT     *template.Template
 	seen             map[*design.AttributeDefinition]map[*design.AttributeDefinition]*bytes.Buffer
 }
 
@@ -25,6 +26,7 @@ func NewFinalizer() *Finalizer {
 		"tabs":         Tabs,
 		"goify":        Goify,
 		"gotyperef":    GoTypeRef,
+		"gotypedef":    GoTypeDef,
 		"add":          Add,
 		"finalizeCode": f.Code,
 	}
@@ -36,6 +38,10 @@ func NewFinalizer() *Finalizer {
 	if err != nil {
 		panic(err)
 	}
+	f.defaultFromT, err = template.New("defaultFrom").Funcs(fm).Parse(defaultFromTmpl)
+	if err != nil {
+		panic(err)
+	}
 	return f
 }
 
@@ -64,13 +70,21 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 	if o := att.Type.ToObject(); o != nil {
 		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
 			if att.HasDefaultValue(n) {
+				defaultVal := PrintVal(catt.Type, catt.DefaultValue)
+				if tname := catt.EnumTypeName(); tname != "" {
+					defaultVal = fmt.Sprintf("%s(%s)", Goify(tname, true), defaultVal)
+				}
+				if tname := catt.FlagSetTypeName(); tname != "" {
+					mask, _ := catt.ParseFlags(fmt.Sprint(catt.DefaultValue))
+					defaultVal = fmt.Sprintf("%s(%d)", Goify(tname, true), mask)
+				}
 				data := map[string]interface{}{
 					"target":     target,
 					"field":      n,
 					"catt":       catt,
 					"depth":      depth,
 					"isDatetime": catt.Type == design.DateTime,
-					"defaultVal": PrintVal(catt.Type, catt.DefaultValue),
+					"defaultVal": defaultVal,
 				}
 				if !first {
 					buf.WriteByte('\n')
@@ -94,6 +108,24 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 			}
 			return nil
 		})
+		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+			if src := catt.DefaultFrom(); src != "" {
+				data := map[string]interface{}{
+					"target": target,
+					"field":  n,
+					"source": src,
+					"catt":   catt,
+					"depth":  depth,
+				}
+				if !first {
+					buf.WriteByte('\n')
+				} else {
+					first = false
+				}
+				buf.WriteString(RunTemplate(f.defaultFromT, data))
+			}
+			return nil
+		})
 	} else if a := att.Type.ToArray(); a != nil {
 		data := map[string]interface{}{
 			"elemType": a.ElemType,
@@ -170,6 +202,22 @@ const (
 {{ tabs .depth }}	{{ .target }}.{{ goify .field true }} = {{ .defaultVal }}
 }{{ end }}`
 
+	// defaultFromTmpl copies the source value so that changing one field does not change the
+	// other. The finalizer only runs on private types so the field types are private.
+	defaultFromTmpl = `{{ $src := printf "%s.%s" .target (goify .source true) }}{{/*
+*/}}{{ tabs .depth }}if {{ .target }}.{{ goify .field true }} == nil && {{ $src }} != nil {
+{{ if .catt.Type.IsArray }}{{ tabs .depth }}	tmp := make({{ gotypedef .catt .depth true true }}, len({{ $src }}))
+{{ tabs .depth }}	copy(tmp, {{ $src }})
+{{ tabs .depth }}	{{ .target }}.{{ goify .field true }} = tmp
+{{ else if .catt.Type.IsHash }}{{ tabs .depth }}	tmp := make({{ gotypedef .catt .depth true true }}, len({{ $src }}))
+{{ tabs .depth }}	for k, v := range {{ $src }} {
+{{ tabs .depth }}		tmp[k] = v
+{{ tabs .depth }}	}
+{{ tabs .depth }}	{{ .target }}.{{ goify .field true }} = tmp
+{{ else }}{{ tabs .depth }}	tmp := *{{ $src }}
+{{ tabs .depth }}	{{ .target }}.{{ goify .field true }} = &tmp
+{{ end }}{{ tabs .depth }}}`
+
 	arrayAssignmentTmpl = `{{ $a := finalizeCode .elemType "e" (add .depth 1) }}{{/*
 */}}{{ if $a }}{{ tabs .depth }}for _, e := range {{ .target }} {
 {{ $a }}
@@ -137,6 +137,88 @@ var _ = Describe("Struct finalize code generation", func() {
 		})
 	})
 
+	Context("given an object with a field defaulting from another", func() {
+		BeforeEach(func() {
+			nickname := &design.AttributeDefinition{Type: design.String}
+			nickname.SetDefaultFrom("username")
+			att = &design.AttributeDefinition{
+				Type: &design.Object{
+					"username": &design.AttributeDefinition{Type: design.String},
+					"nickname": nickname,
+				},
+			}
+			target = "ut"
+		})
+		It("copies the source only when the target is unset and the source is present", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(defaultFromAssignmentCode))
+		})
+	})
+
+	Context("given an array field defaulting from another", func() {
+		BeforeEach(func() {
+			aliases := &design.AttributeDefinition{
+				Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}},
+			}
+			aliases.SetDefaultFrom("names")
+			att = &design.AttributeDefinition{
+				Type: &design.Object{
+					"names": &design.AttributeDefinition{
+						Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}},
+					},
+					"aliases": aliases,
+				},
+			}
+			target = "ut"
+		})
+		It("copies the source value", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(defaultFromArrayAssignmentCode))
+		})
+	})
+
+	Context("given a hash field defaulting from another", func() {
+		BeforeEach(func() {
+			hash := func() *design.Hash {
+				return &design.Hash{
+					KeyType:  &design.AttributeDefinition{Type: design.String},
+					ElemType: &design.AttributeDefinition{Type: design.Integer},
+				}
+			}
+			aliases := &design.AttributeDefinition{Type: hash()}
+			aliases.SetDefaultFrom("names")
+			att = &design.AttributeDefinition{
+				Type: &design.Object{
+					"names":   &design.AttributeDefinition{Type: hash()},
+					"aliases": aliases,
+				},
+			}
+			target = "ut"
+		})
+		It("copies the source value", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(defaultFromHashAssignmentCode))
+		})
+	})
+
+	Context("given a field defaulting from a source with a default value", func() {
+		BeforeEach(func() {
+			nickname := &design.AttributeDefinition{Type: design.String}
+			nickname.SetDefaultFrom("username")
+			att = &design.AttributeDefinition{
+				Type: &design.Object{
+					"username": &design.AttributeDefinition{Type: design.String, DefaultValue: "anonymous"},
+					"nickname": nickname,
+				},
+			}
+			target = "ut"
+		})
+		It("applies the source default first", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(defaultFromSourceDefaultCode))
+		})
+	})
+
 	Context("given a recursive user type", func() {
 		BeforeEach(func() {
 			var (
@@ -213,6 +295,34 @@ if ut.Foo == nil {
 	ut.Foo = &defaultFoo
 }`
 
+	defaultFromAssignmentCode = `if ut.Nickname == nil && ut.Username != nil {
+	tmp := *ut.Username
+	ut.Nickname = &tmp
+}`
+
+	defaultFromArrayAssignmentCode = `if ut.Aliases == nil && ut.Names != nil {
+	tmp := make([]string, len(ut.Names))
+	copy(tmp, ut.Names)
+	ut.Aliases = tmp
+}`
+
+	defaultFromHashAssignmentCode = `if ut.Aliases == nil && ut.Names != nil {
+	tmp := make(map[string]int, len(ut.Names))
+	for k, v := range ut.Names {
+		tmp[k] = v
+	}
+	ut.Aliases = tmp
+}`
+
+	defaultFromSourceDefaultCode = `var defaultUsername = "anonymous"
+if ut.Username == nil {
+	ut.Username = &defaultUsername
+}
+if ut.Nickname == nil && ut.Username != nil {
+	tmp := *ut.Username
+	ut.Nickname = &tmp
+}`
+
 	recursiveAssignmentCodeA = `if ut.Child != nil {
 	var defaultOther = "foo"
 	if ut.Child.Other == nil {
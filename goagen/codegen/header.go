@@ -0,0 +1,109 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/build/constraint"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	// fileHeader is the template of the custom header written at the top of the generated Go
+	// files if any, see SetHeader.
+	fileHeader *template.Template
+
+	// buildTags is the build constraint of the generated Go files if any, see SetBuildTags.
+	buildTags string
+)
+
+// ConfigureHeader configures the custom header and build constraint of the generated Go files
+// from the values of the generator "header" and "build-tags" flags. headerPath is the path to the
+// file containing the header template, see SetHeader and SetBuildTags. Empty values leave the
+// corresponding setting unchanged.
+func ConfigureHeader(headerPath, tags string) error {
+	if headerPath != "" {
+		b, err := ioutil.ReadFile(headerPath)
+		if err != nil {
+			return fmt.Errorf("failed to read header template: %s", err)
+		}
+		if err := SetHeader(string(b)); err != nil {
+			return err
+		}
+	}
+	if tags != "" {
+		return SetBuildTags(tags)
+	}
+	return nil
+}
+
+// SetHeader sets the template of the header written at the top of the generated Go files before
+// the "Code generated" comment, e.g. a license header. The template data has a Year field set to
+// the current year. The lines of the rendered header that are not comments are turned into line
+// comments. An empty header removes the custom header.
+func SetHeader(header string) error {
+	if strings.TrimSpace(header) == "" {
+		fileHeader = nil
+		return nil
+	}
+	tmpl, err := template.New("fileHeader").Parse(header)
+	if err != nil {
+		return fmt.Errorf("invalid header template: %s", err)
+	}
+	fileHeader = tmpl
+	return nil
+}
+
+// SetBuildTags sets the build constraint expression written in a "//go:build" line at the top of
+// the generated Go files, e.g. "test" or "linux && !appengine". An empty expression removes the
+// build constraint.
+func SetBuildTags(expr string) error {
+	if err := ValidateBuildTags(expr); err != nil {
+		return err
+	}
+	buildTags = strings.TrimSpace(expr)
+	return nil
+}
+
+// ValidateBuildTags returns an error if expr is not a valid build constraint expression.
+func ValidateBuildTags(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return nil
+	}
+	if _, err := constraint.Parse("//go:build " + expr); err != nil {
+		return fmt.Errorf("invalid build tags %#v: %s", expr, err)
+	}
+	return nil
+}
+
+// FileHeader returns the custom header and the build constraint line written at the top of the
+// generated Go files, the empty string if neither is configured. The result is terminated with a
+// blank line so that it may be followed by the "Code generated" comment or the package clause.
+func FileHeader() (string, error) {
+	var buf bytes.Buffer
+	if fileHeader != nil {
+		var h bytes.Buffer
+		if err := fileHeader.Execute(&h, map[string]interface{}{"Year": time.Now().Year()}); err != nil {
+			return "", fmt.Errorf("failed to render header: %s", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(h.String()), "\n") {
+			line = strings.TrimRight(line, " \t\r")
+			switch {
+			case strings.HasPrefix(line, "//"):
+				buf.WriteString(line)
+			case line == "":
+				buf.WriteString("//")
+			default:
+				buf.WriteString("// " + line)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+	if buildTags != "" {
+		fmt.Fprintf(&buf, "//go:build %s\n\n", buildTags)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,88 @@
+package codegen_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteHeader", func() {
+	var dir string
+	var header, tags string
+	var content string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "header")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(codegen.MapImportPath(dir, "example.com/header")).Should(Succeed())
+		header = ""
+		tags = ""
+	})
+
+	JustBeforeEach(func() {
+		Ω(codegen.SetHeader(header)).Should(Succeed())
+		Ω(codegen.SetBuildTags(tags)).Should(Succeed())
+		file, err := codegen.SourceFileFor(filepath.Join(dir, "app.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(file.WriteHeader("test api", "app", nil)).Should(Succeed())
+		file.Close()
+		Ω(file.FormatCode()).Should(Succeed())
+		b, err := ioutil.ReadFile(filepath.Join(dir, "app.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		content = string(b)
+	})
+
+	AfterEach(func() {
+		codegen.SetHeader("")
+		codegen.SetBuildTags("")
+		os.RemoveAll(dir)
+	})
+
+	It("starts with the generated code comment by default", func() {
+		Ω(content).Should(HavePrefix("// Code generated by goagen"))
+	})
+
+	Context("with a custom header", func() {
+		BeforeEach(func() {
+			header = "Copyright {{ .Year }} Acme Corp.\n\nLicensed under the MIT license.\n"
+		})
+
+		It("starts with the header", func() {
+			expected := fmt.Sprintf("// Copyright %d Acme Corp.\n//\n// Licensed under the MIT license.\n\n// Code generated by goagen", time.Now().Year())
+			Ω(content).Should(HavePrefix(expected))
+		})
+	})
+
+	Context("with build tags", func() {
+		BeforeEach(func() {
+			header = "// Copyright Acme Corp."
+			tags = "test"
+		})
+
+		It("writes the build constraint before the package clause", func() {
+			Ω(content).Should(HavePrefix("// Copyright Acme Corp.\n\n//go:build test\n\n// Code generated by goagen"))
+			Ω(strings.Index(content, "//go:build test")).Should(BeNumerically("<", strings.Index(content, "package app")))
+		})
+	})
+})
+
+var _ = Describe("ValidateBuildTags", func() {
+	It("accepts valid build constraints", func() {
+		Ω(codegen.ValidateBuildTags("test")).Should(Succeed())
+		Ω(codegen.ValidateBuildTags("linux && (amd64 || !cgo)")).Should(Succeed())
+	})
+
+	It("rejects invalid build constraints", func() {
+		Ω(codegen.ValidateBuildTags("test &&")).ShouldNot(Succeed())
+		Ω(codegen.ValidateBuildTags("linux amd64")).ShouldNot(Succeed())
+		Ω(codegen.SetBuildTags("(test")).ShouldNot(Succeed())
+	})
+})
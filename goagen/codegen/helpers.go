@@ -171,3 +171,60 @@ func KebabCase(name string) string {
 	name = SnakeCase(name)
 	return strings.Replace(name, "_", "-", -1)
 }
+
+// FieldName returns the name of the field with the given attribute name cased according to the
+// given FieldNaming policy, one of "camel", "snake" or "kebab". Acronyms are handled as words so
+// that "userID" produces "userId", "user_id" and "user-id" respectively. FieldName returns the
+// name unchanged if the policy is empty or unknown.
+func FieldName(name, policy string) string {
+	words := fieldNameWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	switch policy {
+	case design.CamelFieldNaming:
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				w = strings.ToUpper(w[:1]) + w[1:]
+			}
+			words[i] = w
+		}
+		return strings.Join(words, "")
+	case design.SnakeFieldNaming:
+		return strings.ToLower(strings.Join(words, "_"))
+	case design.KebabFieldNaming:
+		return strings.ToLower(strings.Join(words, "-"))
+	}
+	return name
+}
+
+// fieldNameWords splits the given name into words. Words are separated by underscores, dashes,
+// spaces or dots and by case changes. A run of upper case letters is an acronym that ends before
+// the last letter if that letter starts a capitalized word, e.g. "HTTPServer" is made of "HTTP"
+// and "Server".
+func fieldNameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
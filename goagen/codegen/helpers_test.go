@@ -31,6 +31,24 @@ var _ = Describe("Helpers", func() {
 		})
 	})
 
+	Describe("FieldName", func() {
+		It("should handle acronyms as words", func() {
+			Expect(codegen.FieldName("userID", "camel")).To(Equal("userId"))
+			Expect(codegen.FieldName("userID", "snake")).To(Equal("user_id"))
+			Expect(codegen.FieldName("userID", "kebab")).To(Equal("user-id"))
+			Expect(codegen.FieldName("HTTPServer", "snake")).To(Equal("http_server"))
+		})
+
+		It("should split words on separators", func() {
+			Expect(codegen.FieldName("created_at", "camel")).To(Equal("createdAt"))
+			Expect(codegen.FieldName("created-at", "snake")).To(Equal("created_at"))
+		})
+
+		It("should leave the name unchanged without a policy", func() {
+			Expect(codegen.FieldName("userID", "")).To(Equal("userID"))
+		})
+	})
+
 	Describe("CommandLine", func() {
 		oldGOPATH, oldArgs := os.Getenv("GOPATH"), os.Args
 		BeforeEach(func() {
@@ -49,6 +49,23 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 		}
 	}
 
+	if marshaler, ok := att.Metadata["json:marshaler"]; ok {
+		if len(marshaler) > 1 {
+			imports = appendImports(imports, []*ImportSpec{SimpleImport(marshaler[1])})
+		}
+	}
+
+	if att.IsNullable() && att.Type.IsPrimitive() {
+		// Nullable fields use the goa package Nullable types.
+		imports = appendImports(imports, []*ImportSpec{SimpleImport("github.com/goadesign/goa")})
+	}
+
+	if fn, ok := att.Metadata["compute:function"]; ok {
+		if len(fn) > 1 {
+			imports = appendImports(imports, []*ImportSpec{SimpleImport(fn[1])})
+		}
+	}
+
 	switch t := att.Type.(type) {
 	case *design.UserTypeDefinition:
 		return appendImports(imports, AttributeImports(t.AttributeDefinition, imports, seen))
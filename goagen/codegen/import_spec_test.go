@@ -156,6 +156,19 @@ var _ = Describe("AttributeImports", func() {
 			})
 		})
 
+		Context("of object with nullable fields", func() {
+			It("imports the goa package", func() {
+				var imports []*codegen.ImportSpec
+				name := &AttributeDefinition{Type: String}
+				name.SetNullable()
+				att = &AttributeDefinition{Type: Object{"name": name}}
+				imports = codegen.AttributeImports(att, imports, nil)
+
+				Ω(imports).Should(HaveLen(1))
+				Ω(imports[0].Path).Should(Equal("github.com/goadesign/goa"))
+			})
+		})
+
 		Context("of MediaTypeDefinition", func() {
 			It("produces the import slice", func() {
 				var imports []*codegen.ImportSpec
@@ -0,0 +1,99 @@
+package codegen
+
+import (
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+)
+
+var jsonMarshalerT *template.Template
+
+func init() {
+	jsonMarshalerT = template.Must(template.New("jsonMarshaler").Parse(jsonMarshalerTmpl))
+}
+
+// JSONMarshaler produces the MarshalJSON and UnmarshalJSON methods of the struct generated for the
+// given object attribute when some of its fields are encoded using the custom JSON marshaler type
+// set with the "json:marshaler" metadata. The marshaler type must have the same underlying type as
+// the field and implement json.Marshaler and json.Unmarshaler. The MarshalJSON method also omits
+// the nullable fields that are not present as encoding/json always encodes struct values.
+// JSONMarshaler returns the empty string if none of the fields use a custom marshaler or are
+// nullable.
+func JSONMarshaler(att *design.AttributeDefinition, typeName string, private bool) string {
+	obj := att.Type.ToObject()
+	if obj == nil {
+		return ""
+	}
+	var fields, nullables []map[string]interface{}
+	obj.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+		if catt.IsNullable() && catt.Type.IsPrimitive() {
+			nullables = append(nullables, map[string]interface{}{
+				"Name":   GoifyAtt(catt, n, true),
+				"GoType": GoNullableType(catt.Type),
+				"Key":    jsonKey(att, catt, n),
+			})
+			return nil
+		}
+		marshaler, ok := catt.Metadata["json:marshaler"]
+		if !ok || len(marshaler) == 0 {
+			return nil
+		}
+		fields = append(fields, map[string]interface{}{
+			"Name":      GoifyAtt(catt, n, true),
+			"GoType":    GoTypeDef(catt, 0, false, private),
+			"Marshaler": marshaler[0],
+			"Pointer":   isPointerField(att, n, private),
+			"Tags":      attributeTags(att, catt, n, private),
+		})
+		return nil
+	})
+	if len(fields) == 0 && len(nullables) == 0 {
+		return ""
+	}
+	data := map[string]interface{}{
+		"TypeName":  typeName,
+		"Fields":    fields,
+		"Nullables": nullables,
+	}
+	return RunTemplate(jsonMarshalerT, data)
+}
+
+// jsonKey returns the key of the JSON object field that encodes the attribute with the given name.
+func jsonKey(parent, att *design.AttributeDefinition, name string) string {
+	if tag, ok := att.Metadata["struct:tag:json"]; ok && len(tag) > 0 && tag[0] != "" {
+		return tag[0]
+	}
+	return FieldName(name, parent.FieldNaming())
+}
+
+const jsonMarshalerTmpl = `// MarshalJSON encodes the {{ .TypeName }} instance{{ if .Fields }} using the custom JSON marshalers of its fields{{ end }}{{ if and .Fields .Nullables }} and{{ end }}{{ if .Nullables }} omitting the nullable fields that are not present{{ end }}.
+func (ut {{ .TypeName }}) MarshalJSON() ([]byte, error) {
+	type alias {{ .TypeName }}
+	return json.Marshal(&struct {
+		*alias
+{{ range .Fields }}		{{ .Name }} {{ if .Pointer }}*{{ end }}{{ .Marshaler }}{{ .Tags }}
+{{ end }}{{ range .Nullables }}		{{ .Name }} *{{ .GoType }} ` + "`" + `json:"{{ .Key }},omitempty"` + "`" + `
+{{ end }}	}{
+		alias: (*alias)(&ut),
+{{ range .Fields }}		{{ .Name }}: ({{ if .Pointer }}*{{ end }}{{ .Marshaler }})(ut.{{ .Name }}),
+{{ end }}{{ range .Nullables }}		{{ .Name }}: ut.{{ .Name }}.OrNil(),
+{{ end }}	})
+}
+{{ if .Fields }}
+// UnmarshalJSON decodes the {{ .TypeName }} instance using the custom JSON unmarshalers of its
+// fields.
+func (ut *{{ .TypeName }}) UnmarshalJSON(b []byte) error {
+	type alias {{ .TypeName }}
+	aux := &struct {
+		*alias
+{{ range .Fields }}		{{ .Name }} {{ if .Pointer }}*{{ end }}{{ .Marshaler }}{{ .Tags }}
+{{ end }}	}{
+		alias: (*alias)(ut),
+	}
+	if err := json.Unmarshal(b, aux); err != nil {
+		return err
+	}
+{{ range .Fields }}	ut.{{ .Name }} = ({{ if .Pointer }}*{{ end }}{{ .GoType }})(aux.{{ .Name }})
+{{ end }}	return nil
+}
+{{ end }}`
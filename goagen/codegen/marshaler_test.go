@@ -0,0 +1,81 @@
+package codegen_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONMarshaler", func() {
+	var att *AttributeDefinition
+	var code string
+
+	JustBeforeEach(func() {
+		code = codegen.JSONMarshaler(att, "Bottle", false)
+	})
+
+	Context("with no field using a custom marshaler", func() {
+		BeforeEach(func() {
+			att = &AttributeDefinition{Type: Object{"name": &AttributeDefinition{Type: String}}}
+		})
+
+		It("produces no code", func() {
+			Ω(code).Should(BeEmpty())
+		})
+	})
+
+	Context("with fields using a custom marshaler", func() {
+		BeforeEach(func() {
+			marshaler := dslengine.MetadataDefinition{"json:marshaler": []string{"mypkg.EpochMillis", "example.com/mypkg"}}
+			att = &AttributeDefinition{
+				Type: Object{
+					"created": &AttributeDefinition{Type: DateTime, Metadata: marshaler},
+					"updated": &AttributeDefinition{Type: DateTime, Metadata: marshaler},
+					"name":    &AttributeDefinition{Type: String},
+				},
+				Validation: &dslengine.ValidationDefinition{Required: []string{"created"}},
+			}
+		})
+
+		It("encodes the fields with the marshaler type", func() {
+			Ω(code).Should(ContainSubstring("func (ut Bottle) MarshalJSON() ([]byte, error) {"))
+			Ω(code).Should(ContainSubstring("Created mypkg.EpochMillis `form:\"created\" json:\"created\""))
+			Ω(code).Should(ContainSubstring("Updated *mypkg.EpochMillis `form:\"updated,omitempty\" json:\"updated,omitempty\""))
+			Ω(code).Should(ContainSubstring("Created: (mypkg.EpochMillis)(ut.Created),"))
+			Ω(code).Should(ContainSubstring("Updated: (*mypkg.EpochMillis)(ut.Updated),"))
+			Ω(code).ShouldNot(ContainSubstring("Name"))
+		})
+
+		It("decodes the fields with the marshaler type", func() {
+			Ω(code).Should(ContainSubstring("func (ut *Bottle) UnmarshalJSON(b []byte) error {"))
+			Ω(code).Should(ContainSubstring("ut.Created = (time.Time)(aux.Created)"))
+			Ω(code).Should(ContainSubstring("ut.Updated = (*time.Time)(aux.Updated)"))
+		})
+	})
+
+	Context("with nullable fields", func() {
+		BeforeEach(func() {
+			name := &AttributeDefinition{Type: String}
+			name.SetNullable()
+			att = &AttributeDefinition{
+				Type: Object{
+					"name":  name,
+					"count": &AttributeDefinition{Type: Integer},
+				},
+			}
+		})
+
+		It("omits the absent nullable fields", func() {
+			Ω(code).Should(ContainSubstring("func (ut Bottle) MarshalJSON() ([]byte, error) {"))
+			Ω(code).Should(ContainSubstring("Name *goa.NullableString `json:\"name,omitempty\"`"))
+			Ω(code).Should(ContainSubstring("Name: ut.Name.OrNil(),"))
+			Ω(code).ShouldNot(ContainSubstring("Count"))
+		})
+
+		It("does not override the decoding", func() {
+			Ω(code).ShouldNot(ContainSubstring("UnmarshalJSON"))
+		})
+	})
+})
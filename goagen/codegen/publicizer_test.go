@@ -53,6 +53,23 @@ var _ = Describe("Struct publicize code generation", func() {
 				Ω(publication).Should(Equal(objectPublicizeCode))
 			})
 		})
+		Context("given an object with a nullable field", func() {
+			BeforeEach(func() {
+				foo := &design.AttributeDefinition{Type: design.String}
+				foo.SetNullable()
+				att = &design.AttributeDefinition{
+					Type: design.Object{
+						"foo": foo,
+					},
+				}
+				sourceField = "source"
+				targetField = "target"
+			})
+			It("copies the nullable field as is", func() {
+				publication := codegen.Publicizer(att, sourceField, targetField, false, 0, false)
+				Ω(publication).Should(Equal(nullablePublicizeCode))
+			})
+		})
 		Context("given a user type", func() {
 			BeforeEach(func() {
 				att = &design.AttributeDefinition{
@@ -191,6 +208,11 @@ if source.Foo != nil {
 	target.Foo = source.Foo
 }`
 
+	nullablePublicizeCode = `target = &struct {
+	Foo goa.NullableString ` + "`" + `form:"foo,omitempty" json:"foo,omitempty" yaml:"foo,omitempty" xml:"foo,omitempty"` + "`" + `
+}{}
+target.Foo = source.Foo`
+
 	arrayPublicizeCode = `target = make([]*TheUserType, len(source))
 for i0, elem0 := range source {
 	target[i0] = elem0.Publicize()
@@ -70,7 +70,16 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 			return tname[0]
 		}
 	}
+	if tname := def.EnumTypeName(); tname != "" {
+		return Goify(tname, true)
+	}
+	if tname := def.FlagSetTypeName(); tname != "" {
+		return Goify(tname, true)
+	}
 	t := def.Type
+	if def.IsNullable() && t.IsPrimitive() {
+		return GoNullableType(t)
+	}
 	switch actual := t.(type) {
 	case design.Primitive:
 		return GoTypeName(t, nil, tabs, private)
@@ -116,7 +125,7 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 		WriteTabs(&buffer, tabs+1)
 		field := obj[name]
 		typedef := GoTypeDef(field, tabs+1, jsonTags, private)
-		if (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
+		if isPointerField(def, name, private) {
 			typedef = "*" + typedef
 		}
 		fname := GoifyAtt(field, name, true)
@@ -136,34 +145,83 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 	return buffer.String()
 }
 
+// isPointerField returns true if the struct field generated for the given child attribute of the
+// object attribute def is a pointer.
+func isPointerField(def *design.AttributeDefinition, name string, private bool) bool {
+	field := def.Type.ToObject()[name]
+	// Nullable fields are never pointers, their type records whether the attribute is set.
+	if field.IsNullable() && field.Type.IsPrimitive() {
+		return false
+	}
+	return (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || def.IsPrimitivePointer(name)
+}
+
 // attributeTags computes the struct field tags.
 func attributeTags(parent, att *design.AttributeDefinition, name string, private bool) string {
-	var elems []string
-	keys := make([]string, len(att.Metadata))
-	i := 0
-	for k := range att.Metadata {
-		keys[i] = k
-		i++
+	var omit string
+	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
+		omit = ",omitempty"
 	}
-	sort.Strings(keys)
-	for _, key := range keys {
-		val := att.Metadata[key]
+	custom := make(map[string]string)
+	var keys []string
+	for key, val := range att.Metadata {
 		if strings.HasPrefix(key, "struct:tag:") {
-			name := key[11:]
-			value := strings.Join(val, ",")
-			elems = append(elems, fmt.Sprintf("%s:\"%s\"", name, value))
+			tag := key[11:]
+			custom[tag] = strings.Join(val, ",")
+			keys = append(keys, tag)
 		}
 	}
-	if len(elems) > 0 {
-		return " `" + strings.Join(elems, " ") + "`"
+	if _, ok := custom["gorm"]; !ok {
+		if tag := storageTag(att); tag != "" {
+			custom["gorm"] = tag
+			keys = append(keys, "gorm")
+		}
 	}
-	// Default algorithm
-	var omit string
-	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
-		omit = ",omitempty"
+	sort.Strings(keys)
+	// Tags set with metadata override the default tags with the same name, the others are
+	// appended in alphabetical order.
+	var elems []string
+	for _, tag := range []string{"form", "json", "yaml", "xml"} {
+		value, ok := custom[tag]
+		if !ok {
+			value = name + omit
+			if tag == "json" {
+				value = FieldName(name, parent.FieldNaming()) + omit
+			}
+		}
+		if tag == "json" && att.WireType() == design.String.Name() && value != "-" && !strings.Contains(value, ",string") {
+			// Encode the value as a JSON string, see the WireType DSL.
+			value += ",string"
+		}
+		elems = append(elems, fmt.Sprintf("%s:\"%s\"", tag, value))
+		delete(custom, tag)
 	}
-	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" yaml:\"%s%s\" xml:\"%s%s\"`",
-		name, omit, name, omit, name, omit, name, omit)
+	for _, tag := range keys {
+		if value, ok := custom[tag]; ok {
+			elems = append(elems, fmt.Sprintf("%s:\"%s\"", tag, value))
+		}
+	}
+	return " `" + strings.Join(elems, " ") + "`"
+}
+
+// storageTag computes the value of the GORM style struct field tag that lists the storage hints
+// of the given attribute, the empty string if none.
+func storageTag(att *design.AttributeDefinition) string {
+	var elems []string
+	if column := att.StorageColumn(); column != "" {
+		elems = append(elems, "column:"+column)
+	}
+	if att.IsPrimaryKey() {
+		elems = append(elems, "primaryKey")
+	}
+	if name, ok := att.StorageIndex(); ok {
+		if name != "" {
+			elems = append(elems, "index:"+name)
+		} else {
+			elems = append(elems, "index")
+		}
+	}
+	return strings.Join(elems, ";")
 }
 
 // GoTypeRef returns the Go code that refers to the Go type which matches the given data type
@@ -201,8 +259,7 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 	case design.Object:
 		att := &design.AttributeDefinition{Type: actual}
 		if len(required) > 0 {
-			requiredVal := &dslengine.ValidationDefinition{Required: required}
-			att.Validation.Merge(requiredVal)
+			att.Validation = &dslengine.ValidationDefinition{Required: required}
 		}
 		return GoTypeDef(att, tabs, false, private)
 	case *design.Hash:
@@ -262,6 +319,23 @@ func GoNativeType(t design.DataType) string {
 	}
 }
 
+// GoNullableType returns the name of the goa type used for fields generated for nullable
+// attributes of the given primitive type.
+func GoNullableType(t design.DataType) string {
+	switch t.Kind() {
+	case design.BooleanKind:
+		return "goa.NullableBoolean"
+	case design.IntegerKind:
+		return "goa.NullableInteger"
+	case design.NumberKind:
+		return "goa.NullableNumber"
+	case design.StringKind:
+		return "goa.NullableString"
+	default:
+		panic(fmt.Sprintf("goa bug: type %s cannot be nullable", t.Name()))
+	}
+}
+
 // GoTypeDesc returns the description of a type.  If no description is defined
 // for the type, one will be generated.
 func GoTypeDesc(t design.DataType, upper bool) string {
@@ -1,6 +1,7 @@
 package codegen_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -232,7 +233,7 @@ var _ = Describe("code generation", func() {
 						expected := fmt.Sprintf("struct {\n"+
 							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" yaml:\"bar,omitempty\" xml:\"bar,omitempty\"`\n"+
 							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" yaml:\"baz,omitempty\" xml:\"baz,omitempty\"`\n"+
-							"	Foo *int `%s:\"%s,%s\" %s:\"%s\"`\n"+
+							"	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\" %s:\"%s,%s\" %s:\"%s\"`\n"+
 							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" yaml:\"qux,omitempty\" xml:\"qux,omitempty\"`\n"+
 							"	Quz interface{} `form:\"quz,omitempty\" json:\"quz,omitempty\" yaml:\"quz,omitempty\" xml:\"quz,omitempty\"`\n"+
 							"}", tn1[11:], tv11, tv12, tn2[11:], tv21)
@@ -240,6 +241,114 @@ var _ = Describe("code generation", func() {
 					})
 				})
 
+				Context("using struct tags metadata overriding the default tags", func() {
+					BeforeEach(func() {
+						object["foo"].Metadata = dslengine.MetadataDefinition{
+							"struct:tag:json":     []string{"renamed"},
+							"struct:tag:validate": []string{"min=1"},
+							"struct:tag:bson":     []string{"foo_id", "omitempty"},
+						}
+					})
+
+					It("merges the struct tags in sorted key order", func() {
+						expected := "struct {\n" +
+							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" yaml:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" yaml:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
+							"	Foo *int `form:\"foo,omitempty\" json:\"renamed\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\" bson:\"foo_id,omitempty\" validate:\"min=1\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" yaml:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
+							"	Quz interface{} `form:\"quz,omitempty\" json:\"quz,omitempty\" yaml:\"quz,omitempty\" xml:\"quz,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
+				Context("using a field naming policy", func() {
+					var policy string
+
+					JustBeforeEach(func() {
+						att.Metadata = dslengine.MetadataDefinition{"json:naming": []string{policy}}
+						st = codegen.GoTypeDef(att, 0, true, false)
+					})
+
+					BeforeEach(func() {
+						object = Object{
+							"userID": &AttributeDefinition{Type: String},
+							"orgID": &AttributeDefinition{
+								Type:     String,
+								Metadata: dslengine.MetadataDefinition{"struct:tag:json": []string{"org"}},
+							},
+						}
+					})
+
+					for p, name := range map[string]string{"camel": "userId", "snake": "user_id", "kebab": "user-id"} {
+						p, name := p, name
+						Context(p, func() {
+							BeforeEach(func() {
+								policy = p
+							})
+
+							It("renders the JSON tags with the policy", func() {
+								Ω(st).Should(ContainSubstring("UserID *string `form:\"userID,omitempty\" json:\"" + name + ",omitempty\" yaml:\"userID,omitempty\" xml:\"userID,omitempty\"`"))
+							})
+
+							It("lets the struct tag metadata override the policy", func() {
+								Ω(st).Should(ContainSubstring("OrgID *string `form:\"orgID,omitempty\" json:\"org\" yaml:\"orgID,omitempty\" xml:\"orgID,omitempty\"`"))
+							})
+						})
+					}
+				})
+
+				Context("using storage hints", func() {
+					BeforeEach(func() {
+						object["foo"].Metadata = dslengine.MetadataDefinition{
+							"storage:column":      []string{"foo_id"},
+							"storage:primary-key": nil,
+						}
+						object["baz"].Metadata = dslengine.MetadataDefinition{
+							"storage:index": []string{"idx_baz"},
+						}
+						object["qux"].Metadata = dslengine.MetadataDefinition{
+							"storage:index":   nil,
+							"struct:tag:gorm": []string{"-"},
+						}
+					})
+
+					It("produces GORM tags", func() {
+						expected := "struct {\n" +
+							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" yaml:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" yaml:\"baz,omitempty\" xml:\"baz,omitempty\" gorm:\"index:idx_baz\"`\n" +
+							"	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\" gorm:\"column:foo_id;primaryKey\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" yaml:\"qux,omitempty\" xml:\"qux,omitempty\" gorm:\"-\"`\n" +
+							"	Quz interface{} `form:\"quz,omitempty\" json:\"quz,omitempty\" yaml:\"quz,omitempty\" xml:\"quz,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
+				Context("using the string wire type", func() {
+					BeforeEach(func() {
+						object["foo"].Metadata = dslengine.MetadataDefinition{
+							"json:wire": []string{"string"},
+						}
+					})
+
+					It("encodes the field as a JSON string", func() {
+						Ω(st).Should(ContainSubstring("	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty,string\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\"`\n"))
+					})
+
+					It("round trips large integers without loss of precision", func() {
+						var v struct {
+							Foo *int64 `json:"foo,omitempty,string"`
+						}
+						Ω(json.Unmarshal([]byte(`{"foo":"9007199254740993"}`), &v)).ShouldNot(HaveOccurred())
+						Ω(*v.Foo).Should(Equal(int64(9007199254740993)))
+						b, err := json.Marshal(v)
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(string(b)).Should(Equal(`{"foo":"9007199254740993"}`))
+						Ω(json.Unmarshal([]byte(`{"foo":"abc"}`), &v)).Should(HaveOccurred())
+					})
+				})
+
 				Context("using struct field name metadata", func() {
 					BeforeEach(func() {
 						object["foo"].Metadata = dslengine.MetadataDefinition{
@@ -259,6 +368,23 @@ var _ = Describe("code generation", func() {
 					})
 				})
 
+				Context("using nullable metadata", func() {
+					BeforeEach(func() {
+						object["foo"].SetNullable()
+					})
+
+					It("produces a nullable field", func() {
+						expected := "struct {\n" +
+							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" yaml:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" yaml:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
+							"	Foo goa.NullableInteger `form:\"foo,omitempty\" json:\"foo,omitempty\" yaml:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" yaml:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
+							"	Quz interface{} `form:\"quz,omitempty\" json:\"quz,omitempty\" yaml:\"quz,omitempty\" xml:\"quz,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
 				Context("using struct field type metadata", func() {
 					BeforeEach(func() {
 						object["foo"].Metadata = dslengine.MetadataDefinition{
@@ -562,6 +688,41 @@ var _ = Describe("code generation", func() {
 	})
 })
 
+var _ = Describe("GoTypeDef of a type extending a base type", func() {
+	var dog *UserTypeDefinition
+	var st string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		animal := Type("Animal", func() {
+			Attribute("kind", String, func() {
+				Enum("Dog")
+			})
+			Attribute("name", String)
+			Discriminator("kind")
+		})
+		dog = Type("Dog", func() {
+			Extend(animal)
+			Attribute("barks", Boolean)
+		})
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		st = codegen.GoTypeDef(dog.AttributeDefinition, 0, true, false)
+	})
+
+	It("flattens the base type fields into the struct", func() {
+		expected := "struct {\n" +
+			"	Barks *bool `form:\"barks,omitempty\" json:\"barks,omitempty\" yaml:\"barks,omitempty\" xml:\"barks,omitempty\"`\n" +
+			"	Kind string `form:\"kind\" json:\"kind\" yaml:\"kind\" xml:\"kind\"`\n" +
+			"	Name *string `form:\"name,omitempty\" json:\"name,omitempty\" yaml:\"name,omitempty\" xml:\"name,omitempty\"`\n" +
+			"}"
+		Ω(st).Should(Equal(expected))
+	})
+})
+
 var _ = Describe("GoTypeTransform", func() {
 	var source, target *UserTypeDefinition
 	var targetPkg, funcName string
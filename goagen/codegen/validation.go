@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 )
 
 var (
@@ -17,7 +18,11 @@ var (
 	patternValT  *template.Template
 	minMaxValT   *template.Template
 	lengthValT   *template.Template
+	decimalValT  *template.Template
 	requiredValT *template.Template
+	uniqueValT   *template.Template
+	boundValT    *template.Template
+	togetherValT *template.Template
 )
 
 //  init instantiates the templates.
@@ -30,6 +35,7 @@ func init() {
 		"constant": constant,
 		"goifyAtt": GoifyAtt,
 		"add":      Add,
+		"i18nKey":  i18nKey,
 	}
 	if enumValT, err = template.New("enum").Funcs(fm).Parse(enumValTmpl); err != nil {
 		panic(err)
@@ -46,9 +52,21 @@ func init() {
 	if lengthValT, err = template.New("length").Funcs(fm).Parse(lengthValTmpl); err != nil {
 		panic(err)
 	}
+	if decimalValT, err = template.New("decimal").Funcs(fm).Parse(decimalValTmpl); err != nil {
+		panic(err)
+	}
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
+	if uniqueValT, err = template.New("unique").Funcs(fm).Parse(uniqueValTmpl); err != nil {
+		panic(err)
+	}
+	if boundValT, err = template.New("bound").Funcs(fm).Parse(boundValTmpl); err != nil {
+		panic(err)
+	}
+	if togetherValT, err = template.New("together").Funcs(fm).Parse(togetherValTmpl); err != nil {
+		panic(err)
+	}
 }
 
 // Validator is the code generator for the 'Validate' type methods.
@@ -348,11 +366,21 @@ func ValidationChecker(att *design.AttributeDefinition, nonzero, required, hasDe
 		"hash":      att.Type.IsHash(),
 		"depth":     depth,
 		"private":   private,
+		"i18nKey":   i18nKey(att),
 	}
 	res := validationsCode(att, data)
 	return strings.Join(res, "\n")
 }
 
+// i18nKey returns the localization key set on the attribute with the "i18n:key" metadata, the empty
+// string if there isn't one.
+func i18nKey(att *design.AttributeDefinition) string {
+	if key, ok := att.Metadata["i18n:key"]; ok && len(key) > 0 {
+		return key[0]
+	}
+	return ""
+}
+
 func validationsCode(att *design.AttributeDefinition, data map[string]interface{}) (res []string) {
 	validation := att.Validation
 	if values := validation.Values; values != nil {
@@ -413,6 +441,41 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 			res = append(res, val)
 		}
 	}
+	if scale := validation.DecimalScale; scale != nil && att.Type.Kind() == design.StringKind {
+		data["scale"] = *scale
+		data["isScale"] = true
+		if val := RunTemplate(decimalValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if precision := validation.DecimalPrecision; precision != nil && att.Type.Kind() == design.StringKind {
+		data["precision"] = *precision
+		data["isScale"] = false
+		if val := RunTemplate(decimalValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if minItems := validation.MinItems; minItems != nil {
+		data["minLength"] = minItems
+		data["isMinLength"] = true
+		delete(data, "maxLength")
+		if val := RunTemplate(lengthValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if maxItems := validation.MaxItems; maxItems != nil {
+		data["maxLength"] = maxItems
+		data["isMinLength"] = false
+		delete(data, "minLength")
+		if val := RunTemplate(lengthValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if validation.UniqueItems && att.Type.IsArray() {
+		if val := RunTemplate(uniqueValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	if required := validation.Required; len(required) > 0 {
 		var val string
 		for i, r := range required {
@@ -424,9 +487,111 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 		}
 		res = append(res, val)
 	}
+	if bounds := validation.Bounds; len(bounds) > 0 && att.Type.IsObject() {
+		var val string
+		for i, b := range bounds {
+			if i > 0 {
+				val += "\n"
+			}
+			val += boundCode(att, b, data)
+		}
+		res = append(res, val)
+	}
+	if groups := validation.RequiredTogether; len(groups) > 0 && att.Type.IsObject() {
+		var vals []string
+		for _, g := range groups {
+			if val := requiredTogetherCode(att, g, data); val != "" {
+				vals = append(vals, val)
+			}
+		}
+		if len(vals) > 0 {
+			res = append(res, strings.Join(vals, "\n"))
+		}
+	}
 	return
 }
 
+// requiredTogetherCode produces the Go code that checks that the given fields of the object
+// attribute are either all present or all absent.
+func requiredTogetherCode(att *design.AttributeDefinition, group []string, data map[string]interface{}) string {
+	o := att.Type.ToObject()
+	target := data["target"].(string)
+	private := data["private"].(bool)
+	var presence []string
+	for _, n := range group {
+		fatt := o[n]
+		if fatt == nil {
+			return ""
+		}
+		v := fmt.Sprintf("%s.%s", target, GoifyAtt(fatt, n, true))
+		switch {
+		case fatt.IsNullable() && fatt.Type.IsPrimitive():
+			presence = append(presence, v+".Present")
+		case private || !fatt.Type.IsPrimitive() || (!att.IsRequired(n) && !att.HasDefaultValue(n) && !att.IsNonZero(n)):
+			presence = append(presence, v+" != nil")
+		default:
+			// The field is always set.
+			presence = append(presence, "true")
+		}
+	}
+	if len(presence) < 2 {
+		return ""
+	}
+	conds := make([]string, len(presence)-1)
+	for i, p := range presence[1:] {
+		conds[i] = fmt.Sprintf("(%s) == (%s)", presence[0], p)
+	}
+	data["group"] = group
+	data["togetherCond"] = strings.Join(conds, " && ")
+	return RunTemplate(togetherValT, data)
+}
+
+// violatedOperators indexes the comparison operators that detect a bound violation by bound
+// operator.
+var violatedOperators = map[string]string{"<": ">=", "<=": ">", ">": "<=", ">=": "<"}
+
+// boundCode produces the Go code that checks the given bound between two fields of the object
+// attribute. The check only runs when both fields are set, nullable fields that are null are not
+// set.
+func boundCode(att *design.AttributeDefinition, b *dslengine.BoundDefinition, data map[string]interface{}) string {
+	o := att.Type.ToObject()
+	fatt, oatt := o[b.Field], o[b.Other]
+	if fatt == nil || oatt == nil {
+		return ""
+	}
+	target := data["target"].(string)
+	private := data["private"].(bool)
+	var conds []string
+	operand := func(n string, a *design.AttributeDefinition) string {
+		v := fmt.Sprintf("%s.%s", target, GoifyAtt(a, n, true))
+		switch {
+		case a.IsNullable() && a.Type.IsPrimitive():
+			conds = append(conds, v+".Present && !"+v+".Null")
+			v += ".Value"
+		case private || (!att.IsRequired(n) && !att.HasDefaultValue(n) && !att.IsNonZero(n)):
+			conds = append(conds, v+" != nil")
+			v = "*" + v
+		}
+		return v
+	}
+	field, other := operand(b.Field, fatt), operand(b.Other, oatt)
+	lhs, rhs := field, other
+	if fatt.Type.Kind() != oatt.Type.Kind() {
+		// Compare integers and numbers as float64.
+		if fatt.Type.Kind() == design.IntegerKind {
+			lhs = fmt.Sprintf("float64(%s)", field)
+		} else {
+			rhs = fmt.Sprintf("float64(%s)", other)
+		}
+	}
+	conds = append(conds, fmt.Sprintf("%s %s %s", lhs, violatedOperators[b.Operator], rhs))
+	data["bound"] = b
+	data["boundCond"] = strings.Join(conds, " && ")
+	data["boundField"] = field
+	data["boundOther"] = other
+	return RunTemplate(boundValT, data)
+}
+
 // renderInteger renders a max or min value properly, taking into account
 // overflows due to casting from a float value.
 func renderInteger(f float64) string {
@@ -476,6 +641,11 @@ func constant(formatName string) string {
 		return "goa.FormatRegexp"
 	case "rfc1123":
 		return "goa.FormatRFC1123"
+	case "decimal":
+		return "goa.FormatDecimal"
+	}
+	if design.CustomFormat(formatName) != nil {
+		return fmt.Sprintf("goa.Format(%q)", formatName)
 	}
 	panic("unknown format") // bug
 }
@@ -498,28 +668,28 @@ const (
 	enumValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
 {{ end }}{{ tabs $depth }}if !({{ oneof .targetVal .values }}) {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ slice .values }}))
+{{ tabs $depth }}	err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.InvalidEnumValueError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ slice .values }}){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
 	patternValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
 {{ end }}{{ tabs $depth }}if ok := goa.ValidatePattern(` + "`{{ .pattern }}`" + `, {{ .targetVal }}); !ok {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, ` + "`{{ .pattern }}`" + `))
+{{ tabs $depth }}	err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.InvalidPatternError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, ` + "`{{ .pattern }}`" + `){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
 {{ tabs $depth }}}{{ if .isPointer }}
 {{ tabs .depth }}}{{ end }}`
 
 	formatValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
 {{ end }}{{ tabs $depth }}if err2 := goa.ValidateFormat({{ constant .format }}, {{ .targetVal }}); err2 != nil {
-{{ tabs $depth }}		err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ constant .format }}, err2))
+{{ tabs $depth }}		err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.InvalidFormatError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ constant .format }}, err2){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
 	minMaxValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
 {{ end }}{{ tabs .depth }}	if {{ .targetVal }} {{ if .isMin }}<{{ else }}>{{ end }} {{ if .isMin }}{{ .min }}{{ else }}{{ .max }}{{ end }} {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isMin }}{{ .min }}, true{{ else }}{{ .max }}, false{{ end }}))
+{{ tabs $depth }}	err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.InvalidRangeError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isMin }}{{ .min }}, true{{ else }}{{ .max }}, false{{ end }}){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
@@ -527,14 +697,40 @@ const (
 */}}{{ $target := or (and (or (or .array .hash) .nonzero) .target) .targetVal }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
 {{ end }}{{ tabs .depth }}	if {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }} {{ if .isMinLength }}<{{ else }}>{{ end }} {{ if .isMinLength }}{{ .minLength }}{{ else }}{{ .maxLength }}{{ end }} {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `{{ .context }}` + "`" + `, {{ $target }}, {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }}, {{ if .isMinLength }}{{ .minLength }}, true{{ else }}{{ .maxLength }}, false{{ end }}))
+{{ tabs $depth }}	err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.InvalidLengthError(` + "`" + `{{ .context }}` + "`" + `, {{ $target }}, {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }}, {{ if .isMinLength }}{{ .minLength }}, true{{ else }}{{ .maxLength }}, false{{ end }}){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
-	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
+	decimalValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+{{ end }}{{ tabs $depth }}if {{ if .isScale }}_, scale{{ else }}precision, _{{ end }} := goa.DecimalDigits({{ .targetVal }}); {{ if .isScale }}scale > {{ .scale }}{{ else }}precision > {{ .precision }}{{ end }} {
+{{ tabs $depth }}	err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.InvalidDecimalError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isScale }}scale, {{ .scale }}, true{{ else }}precision, {{ .precision }}, false{{ end }}){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
+{{ if .isPointer }}{{ tabs $depth }}}
+{{ end }}{{ tabs .depth }}}`
+
+	uniqueValTmpl = `{{ tabs .depth }}if len({{ .target }}) > 1 {
+{{ tabs .depth }}	seen := make(map[interface{}]int, len({{ .target }}))
+{{ tabs .depth }}	for i, e := range {{ .target }} {
+{{ tabs .depth }}		if j, ok := seen[e]; ok {
+{{ tabs .depth }}			err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.DuplicateItemError(` + "`" + `{{ .context }}` + "`" + `, e, i, j){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
+{{ tabs .depth }}		} else {
+{{ tabs .depth }}			seen[e] = i
+{{ tabs .depth }}		}
+{{ tabs .depth }}	}
+{{ tabs .depth }}}`
+
+	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{ $key := i18nKey $att }}{{/*
 */}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
-{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
+{{ tabs $.depth }}	err = goa.MergeErrors(err, {{ if $key }}goa.WithI18nKey({{ end }}goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"){{ if $key }}, {{ printf "%q" $key }}){{ end }})
 {{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
-{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
+{{ tabs $.depth }}	err = goa.MergeErrors(err, {{ if $key }}goa.WithI18nKey({{ end }}goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"){{ if $key }}, {{ printf "%q" $key }}){{ end }})
 {{ tabs $.depth }}}{{ end }}`
+
+	boundValTmpl = `{{ tabs .depth }}if {{ .boundCond }} {
+{{ tabs .depth }}	err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.InvalidBoundError(` + "`" + `{{ .context }}` + "`" + `, {{ printf "%q" .bound.Field }}, {{ .boundField }}, {{ printf "%q" .bound.Operator }}, {{ printf "%q" .bound.Other }}, {{ .boundOther }}){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
+{{ tabs .depth }}}`
+
+	togetherValTmpl = `{{ tabs .depth }}if !({{ .togetherCond }}) {
+{{ tabs .depth }}	err = goa.MergeErrors(err, {{ if .i18nKey }}goa.WithI18nKey({{ end }}goa.RequiredTogetherError(` + "`" + `{{ .context }}` + "`" + `, []string{ {{- range $i, $n := .group }}{{ if $i }}, {{ end }}{{ printf "%q" $n }}{{ end -}} }){{ if .i18nKey }}, {{ printf "%q" .i18nKey }}){{ end }})
+{{ tabs .depth }}}`
 )
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	. "github.com/onsi/ginkgo"
@@ -59,6 +60,36 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of a custom format", func() {
+				BeforeEach(func() {
+					design.RegisterFormat("isbn", func(string) error { return nil }, "")
+					attType = design.String
+					validation = &dslengine.ValidationDefinition{
+						Format: "isbn",
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(customFormatValCode))
+				})
+			})
+
+			Context("of decimal scale and precision", func() {
+				BeforeEach(func() {
+					attType = design.String
+					scale, precision := 2, 10
+					validation = &dslengine.ValidationDefinition{
+						Format:           "decimal",
+						DecimalScale:     &scale,
+						DecimalPrecision: &precision,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(decimalValCode))
+				})
+			})
+
 			Context("of min value 0", func() {
 				BeforeEach(func() {
 					attType = design.Integer
@@ -119,6 +150,42 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of array min and max items", func() {
+				BeforeEach(func() {
+					attType = &design.Array{
+						ElemType: &design.AttributeDefinition{
+							Type: design.String,
+						},
+					}
+					min, max := 1, 5
+					validation = &dslengine.ValidationDefinition{
+						MinItems: &min,
+						MaxItems: &max,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(arrayMinMaxItemsValCode))
+				})
+			})
+
+			Context("of array unique items", func() {
+				BeforeEach(func() {
+					attType = &design.Array{
+						ElemType: &design.AttributeDefinition{
+							Type: design.Integer,
+						},
+					}
+					validation = &dslengine.ValidationDefinition{
+						UniqueItems: true,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(arrayUniqueItemsValCode))
+				})
+			})
+
 			Context("of array elements", func() {
 				BeforeEach(func() {
 					attType = &design.Array{
@@ -217,6 +284,54 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of bounds between fields", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"price":    &design.AttributeDefinition{Type: design.Number},
+						"discount": &design.AttributeDefinition{Type: design.Number},
+						"quantity": &design.AttributeDefinition{Type: design.Integer},
+					}
+					validation = &dslengine.ValidationDefinition{
+						Bounds: []*dslengine.BoundDefinition{
+							{Field: "discount", Operator: "<=", Other: "price"},
+							{Field: "quantity", Operator: ">", Other: "discount"},
+						},
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(boundsValCode))
+				})
+
+				Context("with a nullable field", func() {
+					BeforeEach(func() {
+						attType.ToObject()["price"].SetNullable()
+					})
+
+					It("checks the bound when the field is set and not null", func() {
+						Ω(code).Should(Equal(nullableBoundsValCode))
+					})
+				})
+			})
+
+			Context("of fields required together", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"lat":  &design.AttributeDefinition{Type: design.Number},
+						"lng":  &design.AttributeDefinition{Type: design.Number},
+						"alt":  &design.AttributeDefinition{Type: design.Number},
+						"tags": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+					}
+					validation = &dslengine.ValidationDefinition{
+						RequiredTogether: [][]string{{"lat", "lng", "alt"}, {"lat", "tags"}},
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(requiredTogetherValCode))
+				})
+			})
+
 			Context("of embedded object", func() {
 				var catt, ccatt *design.AttributeDefinition
 
@@ -332,6 +447,43 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("with an i18n key metadata", func() {
+				JustBeforeEach(func() {
+					att.Metadata = map[string][]string{"i18n:key": {"errors.val"}}
+					code = codegen.NewValidator().Code(att, false, false, false, target, context, 1, false)
+				})
+
+				BeforeEach(func() {
+					attType = design.Integer
+					min := 0.0
+					validation = &dslengine.ValidationDefinition{
+						Minimum: &min,
+					}
+				})
+
+				It("sets the key on the validation error", func() {
+					Ω(code).Should(ContainSubstring("err = goa.MergeErrors(err, goa.WithI18nKey(goa.InvalidRangeError(`context`, *val, 0, true), \"errors.val\"))"))
+				})
+			})
+
+			Context("of a required attribute with an i18n key metadata", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"name": &design.AttributeDefinition{
+							Type:     design.String,
+							Metadata: map[string][]string{"i18n:key": {"errors.name"}},
+						},
+					}
+					validation = &dslengine.ValidationDefinition{
+						Required: []string{"name"},
+					}
+				})
+
+				It("sets the key on the missing attribute error", func() {
+					Ω(code).Should(ContainSubstring("err = goa.MergeErrors(err, goa.WithI18nKey(goa.MissingAttributeError(`context`, \"name\"), \"errors.name\"))"))
+				})
+			})
+
 			Context("with a custom type metadata", func() {
 				JustBeforeEach(func() {
 					att.Metadata = map[string][]string{"struct:field:type": {"foo"}}
@@ -366,6 +518,28 @@ const (
 		}
 	}`
 
+	customFormatValCode = `	if val != nil {
+		if err2 := goa.ValidateFormat(goa.Format("isbn"), *val); err2 != nil {
+				err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`context`" + `, *val, goa.Format("isbn"), err2))
+		}
+	}`
+
+	decimalValCode = `	if val != nil {
+		if err2 := goa.ValidateFormat(goa.FormatDecimal, *val); err2 != nil {
+				err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`context`" + `, *val, goa.FormatDecimal, err2))
+		}
+	}
+	if val != nil {
+		if _, scale := goa.DecimalDigits(*val); scale > 2 {
+			err = goa.MergeErrors(err, goa.InvalidDecimalError(` + "`context`" + `, *val, scale, 2, true))
+		}
+	}
+	if val != nil {
+		if precision, _ := goa.DecimalDigits(*val); precision > 10 {
+			err = goa.MergeErrors(err, goa.InvalidDecimalError(` + "`context`" + `, *val, precision, 10, false))
+		}
+	}`
+
 	minValCode = `	if val != nil {
 		if *val < 0 {
 			err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `context` + "`" + `, *val, 0, true))
@@ -390,6 +564,49 @@ const (
 		}
 	}`
 
+	arrayMinMaxItemsValCode = `	if val != nil {
+		if len(val) < 1 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, val, len(val), 1, true))
+		}
+	}
+	if val != nil {
+		if len(val) > 5 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, val, len(val), 5, false))
+		}
+	}`
+
+	arrayUniqueItemsValCode = `	if len(val) > 1 {
+		seen := make(map[interface{}]int, len(val))
+		for i, e := range val {
+			if j, ok := seen[e]; ok {
+				err = goa.MergeErrors(err, goa.DuplicateItemError(` + "`" + `context` + "`" + `, e, i, j))
+			} else {
+				seen[e] = i
+			}
+		}
+	}`
+
+	boundsValCode = `	if val.Discount != nil && val.Price != nil && *val.Discount > *val.Price {
+		err = goa.MergeErrors(err, goa.InvalidBoundError(` + "`" + `context` + "`" + `, "discount", *val.Discount, "<=", "price", *val.Price))
+	}
+	if val.Quantity != nil && val.Discount != nil && float64(*val.Quantity) <= *val.Discount {
+		err = goa.MergeErrors(err, goa.InvalidBoundError(` + "`" + `context` + "`" + `, "quantity", *val.Quantity, ">", "discount", *val.Discount))
+	}`
+
+	nullableBoundsValCode = `	if val.Discount != nil && val.Price.Present && !val.Price.Null && *val.Discount > val.Price.Value {
+		err = goa.MergeErrors(err, goa.InvalidBoundError(` + "`" + `context` + "`" + `, "discount", *val.Discount, "<=", "price", val.Price.Value))
+	}
+	if val.Quantity != nil && val.Discount != nil && float64(*val.Quantity) <= *val.Discount {
+		err = goa.MergeErrors(err, goa.InvalidBoundError(` + "`" + `context` + "`" + `, "quantity", *val.Quantity, ">", "discount", *val.Discount))
+	}`
+
+	requiredTogetherValCode = `	if !((val.Lat != nil) == (val.Lng != nil) && (val.Lat != nil) == (val.Alt != nil)) {
+		err = goa.MergeErrors(err, goa.RequiredTogetherError(` + "`" + `context` + "`" + `, []string{"lat", "lng", "alt"}))
+	}
+	if !((val.Lat != nil) == (val.Tags != nil)) {
+		err = goa.MergeErrors(err, goa.RequiredTogetherError(` + "`" + `context` + "`" + `, []string{"lat", "tags"}))
+	}`
+
 	arrayElementsValCode = `	for _, e := range val {
 		if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
 			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
@@ -470,3 +687,35 @@ const (
 		}
 	}`
 )
+
+var _ = Describe("validation code of a merge patch payload with bounds", func() {
+	var code string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		Resource("orders", func() {
+			Action("patch", func() {
+				Routing(PATCH("/:id"))
+				Payload(func() {
+					Attribute("price", design.Number)
+					Attribute("discount", design.Number)
+					Bound("discount", design.LessThanOrEqual, "price")
+				})
+				PatchFormat(design.MergePatch)
+				Response(design.NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		payload := design.Design.Resources["orders"].Actions["patch"].Payload
+		code = codegen.NewValidator().Code(payload.AttributeDefinition, false, false, false, "payload", "request", 1, true)
+	})
+
+	It("checks the bound using the nullable field values", func() {
+		Ω(code).Should(ContainSubstring("if payload.Discount.Present && !payload.Discount.Null && payload.Price.Present && !payload.Price.Null && payload.Discount.Value > payload.Price.Value {"))
+		Ω(code).ShouldNot(ContainSubstring("!= nil"))
+	})
+})
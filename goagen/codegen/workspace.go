@@ -37,6 +37,9 @@ type (
 		Path string
 		// Workspace containing package
 		Workspace *Workspace
+		// dir is the absolute path to the package source directory when the package lives
+		// in a directory mapped with MapImportPath rather than in the workspace.
+		dir string
 	}
 
 	// SourceFile represents a single Go source file
@@ -67,6 +70,8 @@ var (
 		"gotypedesc":          GoTypeDesc,
 		"gotyperef":           GoTypeRef,
 		"join":                strings.Join,
+		"jsonMarshaler":       JSONMarshaler,
+		"computeFields":       ComputeFields,
 		"recursivePublicizer": RecursivePublicizer,
 		"tabs":                Tabs,
 		"tempvar":             Tempvar,
@@ -74,6 +79,10 @@ var (
 		"toLower":             strings.ToLower,
 		"validationChecker":   ValidationChecker,
 	}
+
+	// importPaths maps absolute directories to the import paths of the Go packages they
+	// contain, see MapImportPath.
+	importPaths = make(map[string]string)
 )
 
 // NewWorkspace returns a newly created temporary Go workspace.
@@ -160,6 +169,12 @@ func (w *Workspace) NewPackage(goPath string) (*Package, error) {
 
 // PackageFor returns the package for the given source file.
 func PackageFor(source string) (*Package, error) {
+	if dir, err := filepath.Abs(filepath.Dir(source)); err == nil {
+		if path, ok := mappedImportPath(dir); ok {
+			w := &Workspace{Path: dir, gopath: os.Getenv("GOPATH")}
+			return &Package{Workspace: w, Path: path, dir: dir}, nil
+		}
+	}
 	w, err := WorkspaceFor(source)
 	if err != nil {
 		return nil, err
@@ -173,6 +188,9 @@ func PackageFor(source string) (*Package, error) {
 
 // Abs returns the absolute path to the package source directory
 func (p *Package) Abs() string {
+	if p.dir != "" {
+		return p.dir
+	}
 	return filepath.Join(p.Workspace.Path, "src", p.Path)
 }
 
@@ -232,9 +250,15 @@ func SourceFileFor(path string) (*SourceFile, error) {
 	return p.OpenSourceFile(filepath.Base(absPath))
 }
 
-// WriteHeader writes the generic generated code header.
+// WriteHeader writes the generic generated code header preceded by the custom header and build
+// constraint configured with SetHeader and SetBuildTags if any.
 func (f *SourceFile) WriteHeader(title, pack string, imports []*ImportSpec) error {
+	header, err := FileHeader()
+	if err != nil {
+		return err
+	}
 	ctx := map[string]interface{}{
+		"Header":      header,
 		"Title":       title,
 		"ToolVersion": version.String(),
 		"Pkg":         pack,
@@ -309,13 +333,60 @@ func (f *SourceFile) ExecuteTemplate(name, source string, funcMap template.FuncM
 	return tmpl.Execute(f, data)
 }
 
+// MapImportPath records that the Go package in the given directory has the given import path.
+// PackagePath uses the recorded mappings to compute the import paths of the directory and of its
+// sub-directories before falling back to GOPATH. This makes it possible to generate code in
+// directories that are not under GOPATH, for example in a Go module, and to have the generated
+// files import the sibling generated packages using the module path. MapImportPath returns an
+// error if importPath is not a valid Go import path.
+func MapImportPath(dir, importPath string) error {
+	if err := ValidateImportPath(importPath); err != nil {
+		return err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	importPaths[absDir] = importPath
+	return nil
+}
+
+// ValidateImportPath returns an error if the given path is not a valid Go import path. A valid
+// import path is made of non-empty slash separated elements that contain only ASCII letters,
+// digits and the characters "-", ".", "_", "~" and "+" and that do not start or end with a dot.
+func ValidateImportPath(importPath string) error {
+	if importPath == "" {
+		return fmt.Errorf("invalid import path: import path cannot be empty")
+	}
+	for _, elem := range strings.Split(importPath, "/") {
+		if elem == "" {
+			return fmt.Errorf("invalid import path %#v: empty path element", importPath)
+		}
+		if strings.HasPrefix(elem, ".") || strings.HasSuffix(elem, ".") {
+			return fmt.Errorf("invalid import path %#v: path element %#v cannot start or end with a dot", importPath, elem)
+		}
+		for _, r := range elem {
+			switch {
+			case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			case strings.ContainsRune("-._~+", r):
+			default:
+				return fmt.Errorf("invalid import path %#v: invalid character %q", importPath, r)
+			}
+		}
+	}
+	return nil
+}
+
 // PackagePath returns the Go package path for the directory that lives under the given absolute
-// file path.
+// file path. The import paths recorded with MapImportPath take precedence over GOPATH.
 func PackagePath(path string) (string, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		absPath = path
 	}
+	if p, ok := mappedImportPath(absPath); ok {
+		return p, nil
+	}
 	gopaths := filepath.SplitList(os.Getenv("GOPATH"))
 	for _, gopath := range gopaths {
 		if gp, err := filepath.Abs(gopath); err == nil {
@@ -330,6 +401,31 @@ func PackagePath(path string) (string, error) {
 	return "", fmt.Errorf("%s does not contain a Go package", absPath)
 }
 
+// mappedImportPath returns the import path of the given absolute directory computed from the
+// mapping recorded with MapImportPath for the closest parent directory.
+func mappedImportPath(absPath string) (string, bool) {
+	var dir string
+	for d := range importPaths {
+		if absPath != d && !strings.HasPrefix(absPath, d+string(filepath.Separator)) {
+			continue
+		}
+		if len(d) > len(dir) {
+			dir = d
+		}
+	}
+	if dir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, absPath)
+	if err != nil {
+		return "", false
+	}
+	if rel == "." {
+		return importPaths[dir], true
+	}
+	return importPaths[dir] + "/" + filepath.ToSlash(rel), true
+}
+
 // PackageSourcePath returns the absolute path to the given package source.
 func PackageSourcePath(pkg string) (string, error) {
 	buildCtx := build.Default
@@ -369,7 +465,7 @@ func PackageName(path string) (string, error) {
 }
 
 const (
-	headerT = `{{if .Title}}// Code generated by goagen {{.ToolVersion}}, DO NOT EDIT.
+	headerT = `{{.Header}}{{if .Title}}// Code generated by goagen {{.ToolVersion}}, DO NOT EDIT.
 //
 // {{.Title}}
 //
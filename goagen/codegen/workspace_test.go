@@ -0,0 +1,63 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PackagePath", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "modpath")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("with a mapped import path", func() {
+		BeforeEach(func() {
+			Ω(codegen.MapImportPath(dir, "example.com/mono/svc")).Should(Succeed())
+		})
+
+		It("returns the import path of the directory", func() {
+			p, err := codegen.PackagePath(dir)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p).Should(Equal("example.com/mono/svc"))
+		})
+
+		It("returns the import paths of the sub-directories", func() {
+			p, err := codegen.PackagePath(filepath.Join(dir, "app", "test"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p).Should(Equal("example.com/mono/svc/app/test"))
+		})
+
+		It("ignores directories that share a prefix", func() {
+			_, err := codegen.PackagePath(dir + "2")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("ValidateImportPath", func() {
+	It("accepts valid import paths", func() {
+		Ω(codegen.ValidateImportPath("example.com/mono-repo/svc_v2")).Should(Succeed())
+		Ω(codegen.ValidateImportPath("gopkg.in/yaml.v2")).Should(Succeed())
+	})
+
+	It("rejects invalid import paths", func() {
+		Ω(codegen.ValidateImportPath("")).ShouldNot(Succeed())
+		Ω(codegen.ValidateImportPath("/example.com/svc")).ShouldNot(Succeed())
+		Ω(codegen.ValidateImportPath("example.com//svc")).ShouldNot(Succeed())
+		Ω(codegen.ValidateImportPath("example.com/../svc")).ShouldNot(Succeed())
+		Ω(codegen.ValidateImportPath("example.com/my svc")).ShouldNot(Succeed())
+	})
+})
@@ -0,0 +1,241 @@
+package genapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"reflect"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+// benchmarkType is the data used to render the benchmarks of a generated type.
+type benchmarkType struct {
+	// Name is the name of the generated Go type.
+	Name string
+	// Data is the JSON document used to seed the benchmarks.
+	Data string
+}
+
+// GenerateBenchmarks returns the source code of the Go benchmarks that measure the JSON encoding
+// and decoding of the types generated by the app generator indexed by file name. pkg is the name
+// of the generated app package, the benchmarks are generated in the same package. There is one
+// pair of benchmarks per user type and per media type view. The benchmarks are seeded with the
+// example of the type: the examples defined in the design are used first and values are
+// generated for the attributes that do not define one. Types without example are seeded with a
+// minimal value that only sets the required attributes. The attributes that use a custom JSON
+// marshaler are omitted from the seed as their examples do not use the marshaler encoding. Types
+// that contain file attributes are skipped as they cannot be encoded in JSON.
+func GenerateBenchmarks(api *design.APIDefinition, pkg string) (map[string][]byte, error) {
+	var types []*benchmarkType
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if design.HasFile(ut) {
+			return nil
+		}
+		bt, err := newBenchmarkType(api, codegen.GoTypeName(ut, ut.AllRequired(), 0, false), ut.AttributeDefinition)
+		if err != nil {
+			return err
+		}
+		types = append(types, bt)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() || !(mt.Type.IsObject() || mt.Type.IsArray()) || design.HasFile(mt) {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, _, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			bt, err := newBenchmarkType(api, codegen.GoTypeName(p, p.AllRequired(), 0, false), p.AttributeDefinition)
+			if err != nil {
+				return err
+			}
+			types = append(types, bt)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return map[string][]byte{}, nil
+	}
+
+	tmpl, err := template.New("benchmarks").Funcs(codegen.DefaultFuncMap).Parse(benchmarksT)
+	if err != nil {
+		panic(err) // bug
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"API":         api,
+		"Package":     pkg,
+		"Types":       types,
+		"ToolVersion": version.String(),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s\n========\nContent:\n%s", err, buf.String())
+	}
+	return map[string][]byte{"benchmarks_test.go": src}, nil
+}
+
+// newBenchmarkType computes the seed of the benchmarks of the given type.
+func newBenchmarkType(api *design.APIDefinition, name string, att *design.AttributeDefinition) (*benchmarkType, error) {
+	var b []byte
+	if ex := att.GenerateExample(api.RandomGenerator(), nil); ex != nil && ex != "-" {
+		b, _ = json.Marshal(withoutCustomMarshaled(att, ex))
+	}
+	if b == nil {
+		var err error
+		b, err = json.Marshal(minimalValue(att, nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render benchmark data of %s: %s", name, err)
+		}
+	}
+	return &benchmarkType{Name: name, Data: string(b)}, nil
+}
+
+// withoutCustomMarshaled removes the values of the attributes that use a custom JSON marshaler
+// from the given example value recursively and encodes the values of the attributes that use the
+// string wire type as strings.
+func withoutCustomMarshaled(att *design.AttributeDefinition, val interface{}) interface{} {
+	if val == nil {
+		return nil
+	}
+	v := reflect.ValueOf(val)
+	switch {
+	case att.Type.IsObject():
+		if v.Kind() != reflect.Map {
+			return val
+		}
+		obj := att.Type.ToObject()
+		res := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			n := fmt.Sprint(k.Interface())
+			catt, ok := obj[n]
+			if !ok {
+				continue
+			}
+			if _, ok := catt.Metadata["json:marshaler"]; ok {
+				continue
+			}
+			if catt.WireType() == design.String.Name() {
+				res[n] = fmt.Sprint(v.MapIndex(k).Interface())
+				continue
+			}
+			res[n] = withoutCustomMarshaled(catt, v.MapIndex(k).Interface())
+		}
+		return res
+	case att.Type.IsArray():
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return val
+		}
+		elem := att.Type.ToArray().ElemType
+		res := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res[i] = withoutCustomMarshaled(elem, v.Index(i).Interface())
+		}
+		return res
+	default:
+		return val
+	}
+}
+
+// minimalValue returns the smallest value of the given attribute type: the zero value of
+// primitive types, empty arrays and hashes and objects that only contain their required
+// attributes.
+func minimalValue(att *design.AttributeDefinition, seen []string) interface{} {
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok {
+		for _, s := range seen {
+			if s == ut.TypeName {
+				return nil
+			}
+		}
+		seen = append(seen, ut.TypeName)
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return false
+	case design.IntegerKind, design.NumberKind:
+		return 0
+	case design.StringKind:
+		return ""
+	case design.DateTimeKind:
+		return "1970-01-01T00:00:00Z"
+	case design.UUIDKind:
+		return "00000000-0000-0000-0000-000000000000"
+	case design.ArrayKind:
+		return []interface{}{}
+	case design.HashKind:
+		return map[string]interface{}{}
+	case design.ObjectKind, design.UserTypeKind, design.MediaTypeKind:
+		obj := att.Type.ToObject()
+		val := make(map[string]interface{})
+		for _, n := range att.AllRequired() {
+			if catt, ok := obj[n]; ok {
+				val[n] = minimalValue(catt, seen)
+				if catt.WireType() == design.String.Name() {
+					val[n] = fmt.Sprint(val[n])
+				}
+			}
+		}
+		return val
+	default:
+		return nil
+	}
+}
+
+const benchmarksT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }}: Application Encoding Benchmarks
+//
+// Command:
+{{ comment commandLine }}
+
+package {{ .Package }}
+
+import (
+	"encoding/json"
+	"testing"
+)
+{{ range .Types }}
+// BenchmarkEncode{{ .Name }} measures the JSON encoding of {{ .Name }} values.
+func BenchmarkEncode{{ .Name }}(b *testing.B) {
+	var v {{ .Name }}
+	if err := json.Unmarshal([]byte({{ printf "%q" .Data }}), &v); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(&v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecode{{ .Name }} measures the JSON decoding of {{ .Name }} values.
+func BenchmarkDecode{{ .Name }}(b *testing.B) {
+	data := []byte({{ printf "%q" .Data }})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v {{ .Name }}
+		if err := json.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+{{ end }}`
@@ -0,0 +1,183 @@
+package genapp_test
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_app"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateBenchmarks", func() {
+	var noExamples bool
+	var files map[string][]byte
+	var genErr error
+
+	BeforeEach(func() {
+		noExamples = false
+	})
+
+	JustBeforeEach(func() {
+		resetDesign()
+		API("cellar", func() {
+			if noExamples {
+				NoExample()
+			}
+		})
+		Type("BottlePayload", func() {
+			Attribute("name", String, func() { Example("Number 8") })
+			Attribute("vintage", Integer, func() { Example(2012) })
+			Attribute("bottled", DateTime, func() {
+				Metadata("json:marshaler", "marsh.EpochMillis", "github.com/goadesign/marsh")
+			})
+			Required("name")
+		})
+		Type("Upload", func() {
+			Attribute("file", File)
+		})
+		MediaType("application/vnd.bottle", func() {
+			TypeName("Bottle")
+			Attributes(func() {
+				Attribute("id", Integer, func() { Example(1) })
+				Attribute("name", String, func() { Example("Number 8") })
+				Required("id")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.GenerateBenchmarks(Design, "app")
+	})
+
+	It("generates a benchmark file in the app package", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(1))
+		Ω(files).Should(HaveKey("benchmarks_test.go"))
+		f, err := parser.ParseFile(token.NewFileSet(), "benchmarks_test.go", files["benchmarks_test.go"], 0)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(f.Name.Name).Should(Equal("app"))
+	})
+
+	It("generates encode and decode benchmarks for each type and view", func() {
+		src := string(files["benchmarks_test.go"])
+		for _, name := range []string{"BottlePayload", "Bottle", "BottleTiny"} {
+			Ω(src).Should(ContainSubstring("func BenchmarkEncode" + name + "(b *testing.B) {"))
+			Ω(src).Should(ContainSubstring("func BenchmarkDecode" + name + "(b *testing.B) {"))
+		}
+		Ω(src).ShouldNot(ContainSubstring("Upload"))
+	})
+
+	It("seeds the benchmarks with the examples outside of the loop", func() {
+		Ω(string(files["benchmarks_test.go"])).Should(ContainSubstring(benchmarkDecodeCode))
+	})
+
+	Context("with the generated app package", func() {
+		var workspace *codegen.Workspace
+		var outDir string
+		var output []byte
+		var runErr error
+
+		BeforeEach(func() {
+			var err error
+			workspace, err = codegen.NewWorkspace("test")
+			Ω(err).ShouldNot(HaveOccurred())
+			outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+			Ω(err).ShouldNot(HaveOccurred())
+			os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+			marsh, err := workspace.NewPackage("github.com/goadesign/marsh")
+			Ω(err).ShouldNot(HaveOccurred())
+			err = ioutil.WriteFile(filepath.Join(marsh.Abs(), "marsh.go"), []byte(marshCode), 0644)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			_, err := genapp.Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+			appDir := filepath.Join(outDir, "app")
+			err = ioutil.WriteFile(filepath.Join(appDir, "benchmarks_test.go"), files["benchmarks_test.go"], 0644)
+			Ω(err).ShouldNot(HaveOccurred())
+			cmd := exec.Command("go", "test", "-run", "xxx", "-bench", ".", "-benchtime", "1x")
+			cmd.Dir = appDir
+			cmd.Env = append(os.Environ(), "GO111MODULE=off")
+			output, runErr = cmd.CombinedOutput()
+		})
+
+		AfterEach(func() {
+			workspace.Delete()
+			delete(codegen.Reserved, "app")
+		})
+
+		It("compiles and runs the benchmarks", func() {
+			Ω(runErr).ShouldNot(HaveOccurred(), string(output))
+			for _, name := range []string{"BottlePayload", "Bottle", "BottleTiny"} {
+				Ω(string(output)).Should(ContainSubstring("BenchmarkEncode" + name))
+				Ω(string(output)).Should(ContainSubstring("BenchmarkDecode" + name))
+			}
+		})
+	})
+
+	Context("with examples disabled", func() {
+		BeforeEach(func() {
+			noExamples = true
+		})
+
+		It("seeds the benchmarks with minimal values", func() {
+			src := string(files["benchmarks_test.go"])
+			Ω(src).Should(ContainSubstring(`json.Unmarshal([]byte("{\"name\":\"\"}"), &v)`))
+		})
+	})
+})
+
+const benchmarkDecodeCode = `// BenchmarkDecodeBottlePayload measures the JSON decoding of BottlePayload values.
+func BenchmarkDecodeBottlePayload(b *testing.B) {
+	data := []byte("{\"name\":\"Number 8\",\"vintage\":2012}")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v BottlePayload
+		if err := json.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}`
+
+// marshCode is the source of the package that implements the custom JSON marshaler used by the
+// design.
+const marshCode = `package marsh
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EpochMillis time.Time
+
+func (t EpochMillis) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).UnixNano() / int64(time.Millisecond))
+}
+
+func (t *EpochMillis) UnmarshalJSON(data []byte) error {
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return err
+	}
+	*t = EpochMillis(time.Unix(0, ms*int64(time.Millisecond)))
+	return nil
+}
+`
@@ -0,0 +1,163 @@
+package genapp
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+// endpointInfo is the data used to render an entry of the generated endpoints registry.
+type endpointInfo struct {
+	// Resource is the name of the resource that defines the endpoint.
+	Resource string
+	// Action is the name of the action implemented by the endpoint.
+	Action string
+	// Transport is "websocket" for actions that use the "ws" or "wss" schemes, "http"
+	// otherwise.
+	Transport string
+	// Method is the HTTP method of the endpoint route.
+	Method string
+	// Path is the full path of the endpoint route.
+	Path string
+	// Tags lists the tags declared on the resource and the action with the "swagger:tag"
+	// metadata.
+	Tags []string
+}
+
+// GenerateEndpointRegistry returns the source code of a Go file that lists the endpoints of the
+// API indexed by file name. pkg is the name of the package of the generated file. The file
+// defines an EndpointInfo type and an Endpoints variable that lists one entry per action route
+// with its transport, HTTP method, path and tags, the tags being the names declared with the
+// "swagger:tag" metadata of the action and its resource. The entries are sorted by resource,
+// action, path and method so that the output is stable. The registry is computed from the design
+// only and may be used to export the API endpoints to a service catalog.
+func GenerateEndpointRegistry(api *design.APIDefinition, pkg string) (map[string][]byte, error) {
+	var endpoints []*endpointInfo
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			transport := "http"
+			if a.WebSocket() {
+				transport = "websocket"
+			}
+			tags := endpointTags(r.Metadata, a.Metadata)
+			for _, route := range a.Routes {
+				endpoints = append(endpoints, &endpointInfo{
+					Resource:  r.Name,
+					Action:    a.Name,
+					Transport: transport,
+					Method:    route.Verb,
+					Path:      route.FullPath(),
+					Tags:      tags,
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		ei, ej := endpoints[i], endpoints[j]
+		if ei.Resource != ej.Resource {
+			return ei.Resource < ej.Resource
+		}
+		if ei.Action != ej.Action {
+			return ei.Action < ej.Action
+		}
+		if ei.Path != ej.Path {
+			return ei.Path < ej.Path
+		}
+		return ei.Method < ej.Method
+	})
+
+	tmpl, err := template.New("endpoints").Funcs(codegen.DefaultFuncMap).Parse(endpointsT)
+	if err != nil {
+		panic(err) // bug
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"API":         api,
+		"Package":     pkg,
+		"Endpoints":   endpoints,
+		"ToolVersion": version.String(),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s\n========\nContent:\n%s", err, buf.String())
+	}
+	return map[string][]byte{"endpoints.go": src}, nil
+}
+
+// endpointTags returns the sorted names of the tags declared with the "swagger:tag:xxx" metadata
+// in the given metadata definitions.
+func endpointTags(mdatas ...dslengine.MetadataDefinition) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, mdata := range mdatas {
+		for key := range mdata {
+			chunks := strings.Split(key, ":")
+			if len(chunks) != 3 || chunks[0] != "swagger" || chunks[1] != "tag" {
+				continue
+			}
+			if !seen[chunks[2]] {
+				seen[chunks[2]] = true
+				tags = append(tags, chunks[2])
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+const endpointsT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }}: Application Endpoints Registry
+//
+// Command:
+{{ comment commandLine }}
+
+package {{ .Package }}
+
+// EndpointInfo describes an endpoint of the API.
+type EndpointInfo struct {
+	// Resource is the name of the resource that defines the endpoint.
+	Resource string
+	// Action is the name of the action implemented by the endpoint.
+	Action string
+	// Transport is the endpoint transport, "http" or "websocket".
+	Transport string
+	// Method is the HTTP method of the endpoint.
+	Method string
+	// Path is the request path of the endpoint.
+	Path string
+	// Tags lists the tags of the endpoint.
+	Tags []string
+}
+
+// Endpoints lists the endpoints of the API sorted by resource, action, path and method.
+var Endpoints = []EndpointInfo{
+{{- range .Endpoints }}
+	{
+		Resource:  {{ printf "%q" .Resource }},
+		Action:    {{ printf "%q" .Action }},
+		Transport: {{ printf "%q" .Transport }},
+		Method:    {{ printf "%q" .Method }},
+		Path:      {{ printf "%q" .Path }},
+{{- if .Tags }}
+		Tags:      []string{ {{- range $i, $t := .Tags }}{{ if $i }}, {{ end }}{{ printf "%q" $t }}{{ end -}} },
+{{- end }}
+	},
+{{- end }}
+}
+`
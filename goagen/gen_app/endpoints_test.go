@@ -0,0 +1,94 @@
+package genapp_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_app"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateEndpointRegistry", func() {
+	var files map[string][]byte
+	var genErr error
+
+	JustBeforeEach(func() {
+		resetDesign()
+		API("cellar", func() {})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Metadata("swagger:tag:Bottles")
+			Action("show", func() {
+				Routing(GET("/:id"), GET("/show/:id"))
+				Params(func() { Param("id", Integer) })
+				Metadata("swagger:tag:Public")
+				Response(OK)
+			})
+			Action("delete", func() {
+				Routing(DELETE("/:id"))
+				Params(func() { Param("id", Integer) })
+				Response(NoContent)
+			})
+		})
+		Resource("account", func() {
+			Action("watch", func() {
+				Scheme("ws")
+				Routing(GET("/accounts/watch"))
+				Response(SwitchingProtocols)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.GenerateEndpointRegistry(Design, "app")
+	})
+
+	It("generates an endpoints file in the given package", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(1))
+		Ω(files).Should(HaveKey("endpoints.go"))
+		f, err := parser.ParseFile(token.NewFileSet(), "endpoints.go", files["endpoints.go"], 0)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(f.Name.Name).Should(Equal("app"))
+	})
+
+	It("lists the endpoints sorted with their transport, path and tags", func() {
+		Ω(string(files["endpoints.go"])).Should(ContainSubstring(endpointsCode))
+	})
+})
+
+const endpointsCode = `var Endpoints = []EndpointInfo{
+	{
+		Resource:  "account",
+		Action:    "watch",
+		Transport: "websocket",
+		Method:    "GET",
+		Path:      "/accounts/watch",
+	},
+	{
+		Resource:  "bottle",
+		Action:    "delete",
+		Transport: "http",
+		Method:    "DELETE",
+		Path:      "/bottles/:id",
+		Tags:      []string{"Bottles"},
+	},
+	{
+		Resource:  "bottle",
+		Action:    "show",
+		Transport: "http",
+		Method:    "GET",
+		Path:      "/bottles/:id",
+		Tags:      []string{"Bottles", "Public"},
+	},
+	{
+		Resource:  "bottle",
+		Action:    "show",
+		Transport: "http",
+		Method:    "GET",
+		Path:      "/bottles/show/:id",
+		Tags:      []string{"Bottles", "Public"},
+	},
+}`
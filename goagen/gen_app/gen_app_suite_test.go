@@ -1,6 +1,8 @@
 package genapp_test
 
 import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -11,3 +13,15 @@ func TestGenApp(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "GenApp Suite")
 }
+
+// apiRoot is the API definition registered with the DSL engine, the generator tests replace
+// Design with API definitions that are not registered.
+var apiRoot = design.Design
+
+// resetDesign restores the registered API definition and resets the DSL roots so that the specs
+// running the DSL do not see the definitions left over by the other specs.
+func resetDesign() {
+	design.Design = apiRoot
+	design.ProjectedMediaTypes.Reset()
+	dslengine.Reset()
+}
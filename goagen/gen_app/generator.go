@@ -3,6 +3,7 @@ package genapp
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -26,19 +27,21 @@ func NewGenerator(options ...Option) *Generator {
 
 // Generator is the application code generator.
 type Generator struct {
-	API       *design.APIDefinition // The API definition
-	OutDir    string                // Path to output directory
-	Target    string                // Name of generated package
-	NoTest    bool                  // Whether to skip test generation
-	genfiles  []string              // Generated files
-	validator *codegen.Validator    // Validation code generator
+	API              *design.APIDefinition // The API definition
+	OutDir           string                // Path to output directory
+	Target           string                // Name of generated package
+	NoTest           bool                  // Whether to skip test generation
+	LegacySignatures bool                  // Whether to generate pre context-first test helper signatures
+	genfiles         []string              // Generated files
+	validator        *codegen.Validator    // Validation code generator
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, toolDir, target, ver string
-		notest, notool, regen        bool
+		header, buildTags                        string
+		outDir, toolDir, target, ver, modulePath string
+		notest, notool, regen, legacySig         bool
 	)
 
 	set := flag.NewFlagSet("app", flag.PanicOnError)
@@ -50,8 +53,24 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&notest, "notest", false, "")
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&legacySig, "legacy-signatures", false, "")
+	set.String("client-resources", "", "")
+	set.String("openapi-ui", "", "")
+	set.String("openapi-spec", "", "")
+	set.String("openapi-ui-assets", "", "")
 	set.Bool("force", false, "")
+	set.StringVar(&modulePath, "module-path", "", "")
+	set.StringVar(&header, "header", "", "")
+	set.StringVar(&buildTags, "build-tags", "", "")
 	set.Parse(os.Args[1:])
+	if err := codegen.ConfigureHeader(header, buildTags); err != nil {
+		return nil, err
+	}
+	if modulePath != "" {
+		if err := codegen.MapImportPath(outDir, modulePath); err != nil {
+			return nil, err
+		}
+	}
 	outDir = filepath.Join(outDir, target)
 
 	if err := codegen.CheckVersion(ver); err != nil {
@@ -59,7 +78,14 @@ func Generate() (files []string, err error) {
 	}
 
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, NoTest: notest, API: design.Design, validator: codegen.NewValidator()}
+	g := &Generator{
+		OutDir:           outDir,
+		Target:           target,
+		NoTest:           notest,
+		LegacySignatures: legacySig,
+		API:              design.Design,
+		validator:        codegen.NewValidator(),
+	}
 
 	return g.Generate()
 }
@@ -95,6 +121,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateSecurity(); err != nil {
 		return nil, err
 	}
+	if err := g.generateRequestID(); err != nil {
+		return nil, err
+	}
 	if err := g.generateHrefs(); err != nil {
 		return nil, err
 	}
@@ -144,6 +173,7 @@ func (g *Generator) generateContexts() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("strconv"),
@@ -167,8 +197,8 @@ func (g *Generator) generateContexts() (err error) {
 	if err = ctxWr.WriteHeader(title, g.Target, imports); err != nil {
 		return
 	}
-	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
-		return r.IterateActions(func(a *design.ActionDefinition) error {
+	for _, r := range g.API.SortedResources() {
+		err = r.IterateActions(func(a *design.ActionDefinition) error {
 			ctxName := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + "Context"
 			headers := &design.AttributeDefinition{
 				Type: design.Object{},
@@ -195,22 +225,50 @@ func (g *Generator) generateContexts() (err error) {
 					non101[k] = v
 				}
 			}
+			_, maxPageSize := a.PageSize()
 			ctxData := ContextTemplateData{
-				Name:         ctxName,
-				ResourceName: r.Name,
-				ActionName:   a.Name,
-				Payload:      a.Payload,
-				Params:       params,
-				Headers:      headers,
-				Routes:       a.Routes,
-				Responses:    non101,
-				API:          g.API,
-				DefaultPkg:   g.Target,
-				Security:     a.Security,
+				Name:           ctxName,
+				ResourceName:   r.Name,
+				ActionName:     a.Name,
+				Payload:        a.Payload,
+				Params:         params,
+				Headers:        headers,
+				Routes:         a.Routes,
+				Responses:      non101,
+				API:            g.API,
+				DefaultPkg:     g.Target,
+				Security:       a.Security,
+				ErrorMedia:     r.ErrorMedia(),
+				ProblemDetails: g.API.ProblemTypeBase != "",
+				SurrogateKeys:  a.SurrogateKeys(),
+				SparseFields:   a.HasSparseFieldsets(),
+				EchoUpdated:    a.EchoesUpdatedFields(),
+				StreamJSON:     a.StreamsJSON(),
+				MaxPageSize:    maxPageSize,
+				Version:        r.Version,
+				SortFields:     a.SortableFields(),
+				FilterFields:   a.FilterableFields(),
+				Events:         a.Events,
+				Envelope:       a.BodyEnvelope(),
+			}
+			if a.HasSortDefaults() {
+				ctxData.SortDefaults = a.SortFieldDefinitions()
+			}
+			if field, whenTrue, whenFalse := a.ResponseFromField(); field != "" {
+				ctxData.RespondFrom = []string{field, whenTrue, whenFalse}
+			}
+			if a.IsDeprecated() {
+				ctxData.Deprecated = true
+				if sunset := a.Sunset(); !sunset.IsZero() {
+					ctxData.Sunset = sunset.UTC().Format(http.TimeFormat)
+				}
 			}
 			return ctxWr.Execute(&ctxData)
 		})
-	})
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -280,7 +338,7 @@ func (g *Generator) generateControllers() (err error) {
 
 	g.genfiles = append(g.genfiles, ctlFile)
 	var controllersData []*ControllerTemplateData
-	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+	for _, r := range g.API.SortedResources() {
 		// Create file servers for all directory file servers that serve index.html.
 		fileServers := r.FileServers
 		for _, fs := range r.FileServers {
@@ -303,20 +361,59 @@ func (g *Generator) generateControllers() (err error) {
 			Resource:       codegen.Goify(r.Name, true),
 			PreflightPaths: r.PreflightPaths(),
 			FileServers:    fileServers,
+			ErrorMedia:     r.ErrorMedia(),
+			ProblemDetails: g.API.ProblemTypeBase != "",
+			Default:        r.CatchAllResponse(),
+			Events:         r.Events(),
+			Version:        r.Version,
+		}
+		if r.IsVersioned() {
+			data.VersionMedia = g.API.VersionMedia
 		}
 		r.IterateActions(func(a *design.ActionDefinition) error {
 			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
+			encodings, threshold := a.Compression()
 			action := map[string]interface{}{
-				"Name":             codegen.Goify(a.Name, true),
-				"DesignName":       a.Name,
-				"Routes":           a.Routes,
-				"Context":          context,
-				"Unmarshal":        unmarshal,
-				"Payload":          a.Payload,
-				"PayloadOptional":  a.PayloadOptional,
-				"PayloadMultipart": a.PayloadMultipart,
-				"Security":         a.Security,
+				"Name":              codegen.Goify(a.Name, true),
+				"DesignName":        a.Name,
+				"Routes":            a.Routes,
+				"Context":           context,
+				"Unmarshal":         unmarshal,
+				"Payload":           a.Payload,
+				"PayloadOptional":   a.PayloadOptional,
+				"PayloadMultipart":  a.PayloadMultipart,
+				"MaxBodySize":       a.MaxBodySize(),
+				"SignatureHeader":   a.SignatureHeader(),
+				"RequireLength":     a.RequiresContentLength(),
+				"ContentTypes":      a.RequiredContentTypes(),
+				"SkipValidation":    a.SkipsRequestBodyValidation(),
+				"DedupeWindow":      a.DedupeWindow(),
+				"Cacheable":         a.CachesResponses(),
+				"Compress":          encodings,
+				"CompressThreshold": threshold,
+				"Security":          a.Security,
+				"Events":            a.Events,
+				"Envelope":          a.BodyEnvelope(),
+			}
+			if a.Payload != nil && a.Payload.IsObject() && !a.PayloadMultipart {
+				action["VersionHidden"] = versionHiddenFields(a.Payload, r.Version)
+			}
+			if src := a.BatchSource(); src != nil {
+				item := src.Payload.AttributeDefinition
+				validate := ctlWr.Validator.Code(item, false, false, false, "payload", "raw", 1, true)
+				action["BatchOf"] = map[string]interface{}{
+					"Name":          codegen.Goify(src.Name, true),
+					"Context":       fmt.Sprintf("%s%sContext", codegen.Goify(src.Name, true), codegen.Goify(r.Name, true)),
+					"Results":       codegen.GoTypeRef(design.BatchResultsMedia, nil, 0, false),
+					"Result":        codegen.GoTypeName(design.BatchResultMedia, nil, 0, false),
+					"Item":          "*" + codegen.GoTypeName(src.Payload, nil, 1, true),
+					"Finalize":      ctlWr.Finalizer.Code(item, "payload", 1) != "",
+					"Validate":      validate != "" && !src.SkipsRequestBodyValidation(),
+					"VersionHidden": versionHiddenFields(src.Payload, r.Version),
+					"Cacheable":     src.CachesResponses(),
+					"Events":        src.Events,
+				}
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
@@ -327,8 +424,7 @@ func (g *Generator) generateControllers() (err error) {
 			data.Origins = r.AllOrigins()
 			controllersData = append(controllersData, data)
 		}
-		return nil
-	})
+	}
 	err = ctlWr.Execute(controllersData)
 	return
 }
@@ -373,6 +469,44 @@ func (g *Generator) generateSecurity() (err error) {
 	return
 }
 
+// generateRequestID generates the request ID middleware if the API defines a request ID header.
+func (g *Generator) generateRequestID() (err error) {
+	if g.API.RequestIDHeader == "" {
+		return nil
+	}
+
+	var (
+		reqIDFile string
+		reqIDWr   *RequestIDWriter
+	)
+	{
+		reqIDFile = filepath.Join(g.OutDir, "request_id.go")
+		reqIDWr, err = NewRequestIDWriter(reqIDFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		reqIDWr.Close()
+		if err == nil {
+			err = reqIDWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Request ID", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+	}
+	if err = reqIDWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, reqIDFile)
+	err = reqIDWr.Execute(g.API.RequestIDHeader)
+
+	return
+}
+
 // generateHrefs iterates through the API resources and generates the href factory methods.
 func (g *Generator) generateHrefs() (err error) {
 	var (
@@ -401,7 +535,7 @@ func (g *Generator) generateHrefs() (err error) {
 		return err
 	}
 	g.genfiles = append(g.genfiles, hrefFile)
-	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+	for _, r := range g.API.SortedResources() {
 		m := g.API.MediaTypeWithIdentifier(r.MediaType)
 		var identifier string
 		if m != nil {
@@ -417,8 +551,10 @@ func (g *Generator) generateHrefs() (err error) {
 			CanonicalTemplate: codegen.CanonicalTemplate(r),
 			CanonicalParams:   codegen.CanonicalParams(r),
 		}
-		return resWr.Execute(&data)
-	})
+		if err = resWr.Execute(&data); err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -445,6 +581,7 @@ func (g *Generator) generateMediaTypes() (err error) {
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
@@ -457,15 +594,35 @@ func (g *Generator) generateMediaTypes() (err error) {
 		return err
 	}
 	g.genfiles = append(g.genfiles, mtFile)
+	errorMedia := make(map[string]bool)
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		if mt := r.ErrorMedia(); mt != nil {
+			errorMedia[mt.Identifier] = true
+		}
+		if resp := r.CatchAllResponse(); resp != nil {
+			if mt := g.API.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+				errorMedia[mt.Identifier] = true
+			}
+		}
+		return nil
+	})
 	err = g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
 		if mt.IsError() {
 			return nil
 		}
 		if mt.Type.IsObject() || mt.Type.IsArray() {
-			return mtWr.Execute(mt)
+			if err := mtWr.Execute(mt); err != nil {
+				return err
+			}
+		}
+		if errorMedia[mt.Identifier] {
+			return mtWr.WriteErrorBuilder(mt)
 		}
 		return nil
 	})
+	if err == nil && g.API.ProblemTypeBase != "" {
+		err = mtWr.WriteProblemDetailsBuilder(g.API.ProblemTypeBase)
+	}
 	return
 }
 
@@ -491,8 +648,11 @@ func (g *Generator) generateUserTypes() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("mime/multipart"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
@@ -508,5 +668,30 @@ func (g *Generator) generateUserTypes() (err error) {
 	err = g.API.IterateUserTypes(func(t *design.UserTypeDefinition) error {
 		return utWr.Execute(t)
 	})
+	if err != nil {
+		return
+	}
+	enums := g.API.NamedEnums()
+	names := make([]string, 0, len(enums))
+	for n := range enums {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err = utWr.WriteEnum(enums[n]); err != nil {
+			return
+		}
+	}
+	sets := g.API.FlagSets()
+	names = make([]string, 0, len(sets))
+	for n := range sets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err = utWr.WriteFlagSet(sets[n]); err != nil {
+			return
+		}
+	}
 	return
 }
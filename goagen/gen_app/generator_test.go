@@ -70,6 +70,53 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with nested resources", func() {
+		BeforeEach(func() {
+			newResource := func(name, parent string) *design.ResourceDefinition {
+				res := &design.ResourceDefinition{
+					Name:                name,
+					BasePath:            "/" + strings.ToLower(name),
+					ParentName:          parent,
+					CanonicalActionName: "list",
+				}
+				res.Actions = map[string]*design.ActionDefinition{
+					"list": {
+						Name:      "list",
+						Parent:    res,
+						Routes:    []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+						Responses: map[string]*design.ResponseDefinition{},
+					},
+				}
+				res.Actions["list"].Routes[0].Parent = res.Actions["list"]
+				return res
+			}
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"Zoo":    newResource("Zoo", ""),
+					"Animal": newResource("Animal", "Zoo"),
+					"Keeper": newResource("Keeper", ""),
+				},
+			}
+		})
+
+		It("generates the resources in alphabetical order", func() {
+			Ω(genErr).Should(BeNil())
+			isSorted := func(filename string, elems ...string) {
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", filename))
+				Ω(err).ShouldNot(HaveOccurred())
+				prev := -1
+				for _, elem := range elems {
+					idx := strings.Index(string(content), elem)
+					Ω(idx).Should(BeNumerically(">", prev), elem)
+					prev = idx
+				}
+			}
+			isSorted("contexts.go", "type ListAnimalContext", "type ListKeeperContext", "type ListZooContext")
+			isSorted("controllers.go", "func MountAnimalController", "func MountKeeperController", "func MountZooController")
+		})
+	})
+
 	Context("with a simple API", func() {
 		var contextsCode, controllersCode, hrefsCode, mediaTypesCode string
 		var payload *design.UserTypeDefinition
@@ -200,6 +247,21 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with an operation ID", func() {
+			BeforeEach(func() {
+				get := design.Design.Resources["Widget"].Actions["get"]
+				get.Metadata = dslengine.MetadataDefinition{"operation:id": {"fetchWidgetV2"}}
+			})
+
+			It("keeps using the action name in the controller interface", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("	Get(*GetWidgetContext) error\n"))
+				Ω(string(content)).ShouldNot(ContainSubstring("fetchWidgetV2"))
+			})
+		})
+
 		Context("with a slice payload", func() {
 			BeforeEach(func() {
 				elemType := &design.AttributeDefinition{Type: design.Integer}
@@ -245,6 +307,157 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with a default error response", func() {
+			BeforeEach(func() {
+				errAt := design.AttributeDefinition{
+					Type: design.Object{
+						"message": &design.AttributeDefinition{Type: design.String},
+						"code":    &design.AttributeDefinition{Type: design.String},
+						"status":  &design.AttributeDefinition{Type: design.Integer},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"message", "code"}},
+				}
+				errMT := &design.MediaTypeDefinition{
+					UserTypeDefinition: &design.UserTypeDefinition{
+						AttributeDefinition: &errAt,
+						TypeName:            "APIError",
+					},
+					Identifier:  "application/vnd.api.error",
+					ContentType: "application/vnd.api.error",
+					Views: map[string]*design.ViewDefinition{
+						"default": {
+							AttributeDefinition: &errAt,
+							Name:                "default",
+						},
+					},
+				}
+				design.Design.MediaTypes["application/vnd.api.error"] = errMT
+				design.Design.MediaTypes[design.CanonicalIdentifier(design.ErrorMediaIdentifier)] = design.ErrorMedia
+				res := design.Design.Resources["Widget"]
+				res.ErrorMediaType = errMT.Identifier
+				res.Actions["get"].Responses["BadRequest"] = &design.ResponseDefinition{
+					Name:      "BadRequest",
+					Status:    400,
+					MediaType: design.ErrorMediaIdentifier,
+				}
+			})
+
+			It("renders errors using the custom media type", func() {
+				Ω(genErr).Should(BeNil())
+
+				mediaTypesContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(mediaTypesContent)).Should(ContainSubstring(errorBuilderCode))
+
+				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contextsContent)).Should(ContainSubstring(errorResponseCode))
+
+				controllersContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(controllersContent)).Should(ContainSubstring("	h = handleWidgetErrors(service, h)\n"))
+				Ω(string(controllersContent)).Should(ContainSubstring(handleErrorsCode))
+			})
+		})
+
+		Context("with a default response", func() {
+			BeforeEach(func() {
+				faultAt := design.AttributeDefinition{
+					Type: design.Object{
+						"message": &design.AttributeDefinition{Type: design.String},
+						"code":    &design.AttributeDefinition{Type: design.String},
+						"status":  &design.AttributeDefinition{Type: design.Integer},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"message", "code"}},
+				}
+				faultMT := &design.MediaTypeDefinition{
+					UserTypeDefinition: &design.UserTypeDefinition{
+						AttributeDefinition: &faultAt,
+						TypeName:            "Fault",
+					},
+					Identifier:  "application/vnd.fault",
+					ContentType: "application/vnd.fault",
+					Views: map[string]*design.ViewDefinition{
+						"default": {
+							AttributeDefinition: &faultAt,
+							Name:                "default",
+						},
+					},
+				}
+				design.Design.MediaTypes["application/vnd.fault"] = faultMT
+				design.Design.DefaultResponse = &design.ResponseDefinition{
+					Name:      "ServiceUnavailable",
+					Status:    503,
+					MediaType: faultMT.Identifier,
+				}
+			})
+
+			AfterEach(func() {
+				design.Design.DefaultResponse = nil
+			})
+
+			It("renders unmapped errors and panics using the default response", func() {
+				Ω(genErr).Should(BeNil())
+
+				mediaTypesContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(mediaTypesContent)).Should(ContainSubstring("func NewFaultFromError(err error) *Fault {"))
+
+				controllersContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(controllersContent)).Should(ContainSubstring("	h = handleWidgetErrors(service, h)\n"))
+				Ω(string(controllersContent)).Should(ContainSubstring(handleDefaultErrorsCode))
+			})
+		})
+
+		Context("with problem details", func() {
+			BeforeEach(func() {
+				design.Design.ProblemTypeBase = "https://goa.design/problems/"
+				design.Design.MediaTypes[design.CanonicalIdentifier(design.ErrorMediaIdentifier)] = design.ErrorMedia
+				res := design.Design.Resources["Widget"]
+				res.Actions["get"].Responses["BadRequest"] = &design.ResponseDefinition{
+					Name:      "BadRequest",
+					Status:    400,
+					MediaType: design.ErrorMediaIdentifier,
+				}
+			})
+
+			It("renders errors as problem details", func() {
+				Ω(genErr).Should(BeNil())
+
+				mediaTypesContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(mediaTypesContent)).Should(ContainSubstring(problemDetailsBuilderCode))
+
+				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contextsContent)).Should(ContainSubstring(problemDetailsResponseCode))
+
+				controllersContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(controllersContent)).Should(ContainSubstring("	h = handleWidgetErrors(service, h)\n"))
+				Ω(string(controllersContent)).Should(ContainSubstring(`rw.Header().Set("Content-Type", "application/problem+json")`))
+				Ω(string(controllersContent)).Should(ContainSubstring("return service.Send(ctx, se.ResponseStatus(), NewProblemDetailsFromError(err))"))
+			})
+		})
+
+		Context("with a request ID header", func() {
+			BeforeEach(func() {
+				design.Design.RequestIDHeader = "X-Request-ID"
+			})
+
+			It("generates the request ID middleware", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "request_id.go")))
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "request_id.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`const RequestIDHeader = "X-Request-ID"`))
+				Ω(string(content)).Should(ContainSubstring("return middleware.RequestIDWithResponseHeader(RequestIDHeader)"))
+				Ω(string(content)).Should(ContainSubstring("func RequestIDFromContext(ctx context.Context) string {"))
+			})
+		})
+
 		Context("with a multipart payload", func() {
 			BeforeEach(func() {
 				elemTypeInt := &design.AttributeDefinition{Type: design.Integer}
@@ -386,6 +599,9 @@ import (
 func initService(service *goa.Service) {
 	// Setup encoders and decoders
 
+	// Setup encoders and decoders registered with goa.RegisterCodec
+	goa.UseCodecs(service.Encoder, service.Decoder)
+
 	// Setup default encoder and decoder
 }
 
@@ -583,3 +799,90 @@ func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *h
 	return nil
 }
 `
+
+const errorBuilderCode = `// NewAPIErrorFromError builds a APIError error response from the given error.
+// Errors that are not goa.ErrorResponse values are rendered as internal errors.
+func NewAPIErrorFromError(err error) *APIError {
+	e, ok := err.(*goa.ErrorResponse)
+	if !ok {
+		e = goa.ErrInternal(err).(*goa.ErrorResponse)
+	}
+	return &APIError{
+		Code:    e.Code,
+		Message: e.Detail,
+		Status:  &e.Status,
+	}
+}
+`
+
+const errorResponseCode = `// BadRequest sends a HTTP response with status code 400.
+func (ctx *GetWidgetContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.api.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, NewAPIErrorFromError(r))
+}
+`
+
+const problemDetailsBuilderCode = `// NewProblemDetailsFromError builds a problem details (RFC 7807) error response from the given error.
+// Errors that are not goa.ErrorResponse values are rendered as internal errors.
+func NewProblemDetailsFromError(err error) *goa.ProblemDetails {
+	return goa.NewProblemDetails(err, "https://goa.design/problems/")
+}
+`
+
+const problemDetailsResponseCode = `// BadRequest sends a HTTP response with status code 400.
+func (ctx *GetWidgetContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/problem+json")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, NewProblemDetailsFromError(r))
+}
+`
+
+const handleErrorsCode = `// handleWidgetErrors renders the errors returned by the Widget handlers using the
+// application/vnd.api.error media type.
+func handleWidgetErrors(service *goa.Service, h goa.Handler) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		err := h(ctx, rw, req)
+		if err == nil {
+			return nil
+		}
+		se, ok := err.(goa.ServiceError)
+		if !ok {
+			// Let the error handler middleware deal with internal errors.
+			return err
+		}
+		rw.Header().Set("Content-Type", "application/vnd.api.error")
+		return service.Send(ctx, se.ResponseStatus(), NewAPIErrorFromError(err))
+	}
+}
+`
+
+const handleDefaultErrorsCode = `// handleWidgetErrors renders the errors returned by the Widget handlers.
+// Errors that are not goa.ServiceError values and panics are rendered using the
+// application/vnd.fault media type with status 503.
+func handleWidgetErrors(service *goa.Service, h goa.Handler) goa.Handler {
+	sendDefault := func(ctx context.Context, rw http.ResponseWriter, err error) error {
+		e := goa.NewErrorClass("internal", 503)(http.StatusText(503))
+		goa.LogError(ctx, "uncaught error", "err", fmt.Sprintf("%+v", err), "id", e.(*goa.ErrorResponse).ID)
+		rw.Header().Set("Content-Type", "application/vnd.fault")
+		return service.Send(ctx, 503, NewFaultFromError(e))
+	}
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = sendDefault(ctx, rw, fmt.Errorf("panic: %v", r))
+			}
+		}()
+		err = h(ctx, rw, req)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(goa.ServiceError); !ok {
+			return sendDefault(ctx, rw, err)
+		}
+		return err
+	}
+}
+`
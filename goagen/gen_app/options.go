@@ -32,3 +32,11 @@ func NoTest(noTest bool) Option {
 		g.NoTest = noTest
 	}
 }
+
+//LegacySignatures Whether to generate test helpers that accept the context after the testing
+//interface rather than as first argument
+func LegacySignatures(legacy bool) Option {
+	return func(g *Generator) {
+		g.LegacySignatures = legacy
+	}
+}
@@ -0,0 +1,80 @@
+package genapp
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+// GenerateProviders returns the source code of a Go file that defines provider functions for
+// dependency injection frameworks such as wire or fx indexed by file name. pkg is the name of the
+// generated app package, the providers are generated in the same package. ProvideService creates
+// the goa service of the API and each resource gets a ProvideXxxHTTPHandler function that mounts
+// the resource controller on the service and returns the service mux as a XxxHTTPHandler value.
+// The providers are thin wrappers around goa.New and the generated MountXxxController functions.
+// Each resource handler has its own type so that all the providers can be registered together,
+// the handlers all serve the same mux which dispatches the requests of the mounted resources. The
+// controllers are implemented by user code and must be provided separately.
+func GenerateProviders(api *design.APIDefinition, pkg string) (map[string][]byte, error) {
+	var resources []string
+	for _, r := range api.SortedResources() {
+		resources = append(resources, codegen.Goify(r.Name, true))
+	}
+
+	tmpl, err := template.New("providers").Funcs(codegen.DefaultFuncMap).Parse(providersT)
+	if err != nil {
+		panic(err) // bug
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"API":         api,
+		"Package":     pkg,
+		"Resources":   resources,
+		"ToolVersion": version.String(),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s\n========\nContent:\n%s", err, buf.String())
+	}
+	return map[string][]byte{"providers.go": src}, nil
+}
+
+const providersT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }}: Application Dependency Injection Providers
+//
+// Command:
+{{ comment commandLine }}
+
+package {{ .Package }}
+
+import (
+{{- if .Resources }}
+	"net/http"
+
+{{ end }}
+	"github.com/goadesign/goa"
+)
+
+// ProvideService creates the {{ .API.Name }} service.
+func ProvideService() *goa.Service {
+	return goa.New({{ printf "%q" .API.Name }})
+}
+{{ range .Resources }}
+// {{ . }}HTTPHandler is the HTTP handler that serves the {{ . }} resource actions.
+type {{ . }}HTTPHandler http.Handler
+
+// Provide{{ . }}HTTPHandler mounts the {{ . }} controller on the service and returns the service mux.
+func Provide{{ . }}HTTPHandler(service *goa.Service, ctrl {{ . }}Controller) {{ . }}HTTPHandler {
+	Mount{{ . }}Controller(service, ctrl)
+	return service.Mux
+}
+{{ end }}`
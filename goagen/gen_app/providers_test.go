@@ -0,0 +1,122 @@
+package genapp_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_app"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateProviders", func() {
+	var files map[string][]byte
+	var genErr error
+	var providers map[string]*ast.FuncType
+	var fset *token.FileSet
+
+	JustBeforeEach(func() {
+		resetDesign()
+		API("cellar", func() {})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() { Param("id", Integer) })
+				Response(OK)
+			})
+		})
+		Resource("account", func() {
+			Action("list", func() {
+				Routing(GET("/accounts"))
+				Response(OK)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.GenerateProviders(Design, "app")
+
+		providers = make(map[string]*ast.FuncType)
+		fset = token.NewFileSet()
+		f, err := parser.ParseFile(fset, "providers.go", files["providers.go"], 0)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(f.Name.Name).Should(Equal("app"))
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				providers[fn.Name.Name] = fn.Type
+			}
+		}
+	})
+
+	// types returns the source code of the types of the given fields.
+	types := func(fields *ast.FieldList) []string {
+		var res []string
+		for _, field := range fields.List {
+			var buf bytes.Buffer
+			Ω(printer.Fprint(&buf, fset, field.Type)).ShouldNot(HaveOccurred())
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				res = append(res, buf.String())
+			}
+		}
+		return res
+	}
+
+	It("generates a providers file in the given package", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(1))
+		Ω(files).Should(HaveKey("providers.go"))
+	})
+
+	It("generates the providers with their parameter and result types", func() {
+		Ω(providers).Should(HaveLen(3))
+		Ω(providers).Should(HaveKey("ProvideService"))
+		Ω(types(providers["ProvideService"].Params)).Should(BeEmpty())
+		Ω(types(providers["ProvideService"].Results)).Should(Equal([]string{"*goa.Service"}))
+		for _, res := range []string{"Account", "Bottle"} {
+			name := "Provide" + res + "HTTPHandler"
+			Ω(providers).Should(HaveKey(name))
+			Ω(types(providers[name].Params)).Should(Equal([]string{"*goa.Service", res + "Controller"}))
+			Ω(types(providers[name].Results)).Should(Equal([]string{res + "HTTPHandler"}))
+		}
+		src := string(files["providers.go"])
+		Ω(src).Should(ContainSubstring("type BottleHTTPHandler http.Handler"))
+		Ω(src).Should(ContainSubstring("MountBottleController(service, ctrl)"))
+	})
+
+	It("generates providers with acyclic dependencies", func() {
+		produced := make(map[string]string)
+		for name, fn := range providers {
+			for _, t := range types(fn.Results) {
+				Ω(produced).ShouldNot(HaveKey(t))
+				produced[t] = name
+			}
+		}
+		state := make(map[string]int) // 1: visiting, 2: done
+		var visit func(name string)
+		visit = func(name string) {
+			Ω(state[name]).ShouldNot(Equal(1), "cycle through "+name)
+			if state[name] == 2 {
+				return
+			}
+			state[name] = 1
+			for _, t := range types(providers[name].Params) {
+				if dep, ok := produced[t]; ok {
+					visit(dep)
+				}
+			}
+			state[name] = 2
+		}
+		for name := range providers {
+			visit(name)
+		}
+	})
+})
@@ -0,0 +1,547 @@
+package genapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+type (
+	// roundTripResource is the data used to render the round-trip test controller of a
+	// resource.
+	roundTripResource struct {
+		// Name is the Go name of the resource.
+		Name string
+		// Actions lists the actions of the resource controller interface.
+		Actions []*roundTripAction
+	}
+
+	// roundTripAction is the data used to render the round-trip test of an action.
+	roundTripAction struct {
+		// Name is the Go name of the action.
+		Name string
+		// Resource is the Go name of the action resource.
+		Resource string
+		// DesignName is the name of the action in the design.
+		DesignName string
+		// ResourceDesignName is the name of the action resource in the design.
+		ResourceDesignName string
+		// Context is the name of the action context type.
+		Context string
+		// Tested is true if the round-trip test of the action is generated, false if the
+		// action is not supported and the test controller only implements it.
+		Tested bool
+		// Method is the HTTP method of the request.
+		Method string
+		// Path is the path of the request with the path parameters set to their examples.
+		Path string
+		// Query lists the required query string parameters and their examples.
+		Query []*roundTripParam
+		// Headers lists the required headers and their examples.
+		Headers []*roundTripParam
+		// Payload is the JSON example of the request body if any.
+		Payload string
+		// Status is the status of the response.
+		Status int
+		// RespMethod is the name of the context method that sends the response.
+		RespMethod string
+		// RespType is the Go type of the response body, the empty string if the response
+		// has no body.
+		RespType string
+		// RespPointer is true if the response method takes a pointer to RespType.
+		RespPointer bool
+		// RespBytes is true if the response body is given as a byte slice.
+		RespBytes bool
+		// RespBody is the JSON example of the response body.
+		RespBody string
+		// Stream is true if the response is streamed as newline delimited JSON.
+		Stream bool
+		// Envelope is the envelope that wraps the request and response bodies if any.
+		Envelope *design.EnvelopeDefinition
+		// Compare is true if the decoded response must be equal to the value sent, byte
+		// slice responses are always compared.
+		Compare bool
+	}
+
+	// roundTripParam is a request parameter or header and its example value.
+	roundTripParam struct {
+		// Name is the name of the parameter or header.
+		Name string
+		// Values lists the example values.
+		Values []string
+	}
+)
+
+// GenerateRoundTripTests returns the source code of Go tests that exercise the HTTP stack of the
+// app package generated for the given API end-to-end indexed by file name. pkg is the name of the
+// generated app package, the tests are generated in the same package. There is one test per
+// action: the test mounts a controller that responds with the example of the action success
+// response that has the lowest status using the generated response method, starts a server with
+// net/http/httptest and sends the example request of the action built from the examples of the
+// path parameters, the required query string parameters and headers and the payload. The test
+// then checks the response status and that the response body decodes into the value sent by the
+// controller. Responses streamed as newline delimited JSON are read frame by frame. The value is
+// not compared when the response is altered by the generated code (computed attributes, encode
+// transforms, required scopes, cookies or echoed updated fields).
+//
+// Tests are not generated for the actions that cannot be exercised without user code or with
+// random examples: WebSocket actions, actions secured by a security scheme or by a request
+// signature, actions that deduplicate requests or replay their responses to retries, actions
+// that require a Content-Length or Content-Type header, actions with multipart or file payloads,
+// JSON Patch actions, actions that require deep object parameters, actions whose request examples
+// do not have to pass validation as defined by the SkipValidation DSL and actions whose response
+// uses an encode transform that validates its input such as "e164" or "url".
+func GenerateRoundTripTests(api *design.APIDefinition, pkg string) (map[string][]byte, error) {
+	var resources []*roundTripResource
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		res := &roundTripResource{Name: codegen.Goify(r.Name, true)}
+		tested := false
+		err := r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.BatchSource() != nil {
+				return nil
+			}
+			ra, err := newRoundTripAction(api, a)
+			if err != nil {
+				return err
+			}
+			tested = tested || ra.Tested
+			res.Actions = append(res.Actions, ra)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if tested {
+			resources = append(resources, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return map[string][]byte{}, nil
+	}
+
+	var payload, compare, stream bool
+	for _, r := range resources {
+		for _, a := range r.Actions {
+			if !a.Tested {
+				continue
+			}
+			payload = payload || a.Payload != ""
+			compare = compare || a.Compare
+			stream = stream || a.Stream
+		}
+	}
+	tmpl, err := template.New("roundtrip").Funcs(codegen.DefaultFuncMap).Parse(roundTripT)
+	if err != nil {
+		panic(err) // bug
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"API":         api,
+		"Package":     pkg,
+		"Resources":   resources,
+		"HasPayload":  payload,
+		"HasCompare":  compare,
+		"HasStream":   stream,
+		"ToolVersion": version.String(),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s\n========\nContent:\n%s", err, buf.String())
+	}
+	return map[string][]byte{"roundtrip_test.go": src}, nil
+}
+
+// newRoundTripAction computes the round-trip test of the given action.
+func newRoundTripAction(api *design.APIDefinition, a *design.ActionDefinition) (*roundTripAction, error) {
+	ra := &roundTripAction{
+		Name:               codegen.Goify(a.Name, true),
+		Resource:           codegen.Goify(a.Parent.Name, true),
+		DesignName:         a.Name,
+		ResourceDesignName: a.Parent.Name,
+		Context:            codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + "Context",
+		Envelope:           a.BodyEnvelope(),
+	}
+	if !roundTripSupported(a) {
+		return ra, nil
+	}
+	var statuses []int
+	byStatus := make(map[int]*design.ResponseDefinition)
+	for _, resp := range a.Responses {
+		if resp.Status >= 200 && resp.Status < 300 {
+			statuses = append(statuses, resp.Status)
+			byStatus[resp.Status] = resp
+		}
+	}
+	if len(statuses) == 0 {
+		return ra, nil
+	}
+	sort.Ints(statuses)
+	resp := byStatus[statuses[0]]
+	ra.Status = resp.Status
+	ra.RespMethod = codegen.Goify(resp.Name, true)
+
+	rand := api.RandomGenerator()
+	route := a.Routes[0]
+	ra.Method = route.Verb
+	params := a.AllParams().Type.ToObject()
+	ra.Path = design.WildcardRegex.ReplaceAllStringFunc(route.FullPath(), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		var values []string
+		if att, ok := params[name]; ok {
+			for _, v := range exampleStrings(att.GenerateExample(rand, nil)) {
+				values = append(values, url.PathEscape(v))
+			}
+		}
+		return "/" + strings.Join(values, ",")
+	})
+	routeParams := make(map[string]bool)
+	for _, n := range route.Params() {
+		routeParams[n] = true
+	}
+	all := a.AllParams()
+	for _, n := range sortedKeys(params) {
+		if routeParams[n] || !all.IsRequired(n) {
+			continue
+		}
+		ra.Query = append(ra.Query, &roundTripParam{Name: n, Values: exampleStrings(params[n].GenerateExample(rand, nil))})
+	}
+	if a.Headers != nil {
+		headers := a.Headers.Type.ToObject()
+		for _, n := range sortedKeys(headers) {
+			if !a.Headers.IsRequired(n) {
+				continue
+			}
+			ra.Headers = append(ra.Headers, &roundTripParam{Name: n, Values: exampleStrings(headers[n].GenerateExample(rand, nil))})
+		}
+	}
+	if a.Payload != nil {
+		var body interface{} = map[string]interface{}{}
+		if ex := a.Payload.GenerateExample(rand, nil); ex != nil && ex != "-" {
+			body = withoutCustomMarshaled(a.Payload.AttributeDefinition, ex)
+		} else if !a.Payload.Type.IsObject() {
+			body = minimalValue(a.Payload.AttributeDefinition, nil)
+		}
+		if ra.Envelope != nil {
+			env := map[string]interface{}{ra.Envelope.DataField: body}
+			if ra.Envelope.MetaField != "" {
+				env[ra.Envelope.MetaField] = map[string]interface{}{}
+			}
+			body = env
+		}
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to render example payload: %s", a.Context(), err)
+		}
+		ra.Payload = string(b)
+	}
+
+	var att *design.AttributeDefinition
+	mt, ok := resp.Type.(*design.MediaTypeDefinition)
+	if !ok && resp.Type == nil {
+		mt = api.MediaTypeWithIdentifier(resp.MediaType)
+	}
+	switch {
+	case mt != nil:
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		} else if view != design.DefaultView {
+			ra.RespMethod = codegen.Goify(fmt.Sprintf("%s%s", resp.Name, strings.Title(view)), true)
+		}
+		projected, _, err := mt.Project(view)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to project media type %#v: %s", a.Context(), mt.Identifier, err)
+		}
+		if usesStrictTransform(projected) {
+			return &roundTripAction{Name: ra.Name, Resource: ra.Resource, Context: ra.Context}, nil
+		}
+		att = projected.AttributeDefinition
+		ra.RespType = codegen.GoTypeRef(projected, projected.AllRequired(), 0, false)
+		ra.Stream = a.StreamsJSON() && projected.Type.IsArray()
+		ra.Compare = len(projected.ComputedFields()) == 0 && len(resp.Cookies) == 0 && !a.EchoesUpdatedFields() &&
+			fieldsLiteral(projected, (*design.AttributeDefinition).RequiredScope) == "" &&
+			fieldsLiteral(projected, (*design.AttributeDefinition).EncodeTransform) == "" &&
+			len(projected.VersionHiddenFields(a.Parent.Version)) == 0
+	case resp.Type != nil:
+		att = &design.AttributeDefinition{Type: resp.Type}
+		ra.RespType = codegen.GoTypeRef(resp.Type, nil, 0, false)
+		ra.Compare = true
+	case resp.MediaType != "":
+		ra.RespBytes = true
+		ra.RespBody = "roundtrip"
+	}
+	if att != nil {
+		if design.HasFile(att.Type) {
+			return &roundTripAction{Name: ra.Name, Resource: ra.Resource, Context: ra.Context}, nil
+		}
+		var body interface{}
+		if ex := att.GenerateExample(rand, nil); ex != nil && ex != "-" {
+			body = withoutCustomMarshaled(att, ex)
+		} else {
+			body = minimalValue(att, nil)
+		}
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to render example response: %s", a.Context(), err)
+		}
+		ra.RespBody = string(b)
+		if strings.HasPrefix(ra.RespType, "*") {
+			ra.RespPointer = true
+			ra.RespType = ra.RespType[1:]
+		}
+	}
+	ra.Tested = true
+	return ra, nil
+}
+
+// roundTripSupported returns true if the round-trip test of the given action can be generated.
+func roundTripSupported(a *design.ActionDefinition) bool {
+	if a.WebSocket() || len(a.Routes) == 0 || a.PayloadMultipart || a.SignatureHeader() != "" {
+		return false
+	}
+	if a.Security != nil && a.Security.Scheme != nil {
+		return false
+	}
+	if a.PatchFormat() == design.JSONPatch || a.DedupeWindow() > 0 || a.CachesResponses() {
+		return false
+	}
+	if a.RequiresContentLength() || len(a.RequiredContentTypes()) > 0 {
+		return false
+	}
+	if a.Payload != nil && design.HasFile(a.Payload) {
+		return false
+	}
+	params := a.AllParams()
+	routeParams := make(map[string]bool)
+	for _, n := range a.Routes[0].Params() {
+		routeParams[n] = true
+	}
+	for n, att := range params.Type.ToObject() {
+		if att.IsDeepObject() && params.IsRequired(n) {
+			return false
+		}
+		if (routeParams[n] || params.IsRequired(n)) && skipsExampleValidation(att, nil) {
+			return false
+		}
+	}
+	if a.Headers != nil {
+		for n, att := range a.Headers.Type.ToObject() {
+			if a.Headers.IsRequired(n) && skipsExampleValidation(att, nil) {
+				return false
+			}
+		}
+	}
+	if a.Payload != nil && skipsExampleValidation(a.Payload.AttributeDefinition, nil) {
+		return false
+	}
+	return true
+}
+
+// skipsExampleValidation returns true if the example of the given attribute or of one of its
+// child attributes does not have to pass the attribute validations as defined by the
+// SkipValidation DSL. seen lists the names of the user types already visited.
+func skipsExampleValidation(att *design.AttributeDefinition, seen []string) bool {
+	if att.ExampleSkipsValidation() {
+		return true
+	}
+	var name string
+	switch t := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		name = t.TypeName
+	case *design.MediaTypeDefinition:
+		name = t.TypeName
+	}
+	if name != "" {
+		for _, n := range seen {
+			if n == name {
+				return false
+			}
+		}
+		seen = append(seen, name)
+	}
+	switch {
+	case att.Type.IsObject():
+		for _, child := range att.Type.ToObject() {
+			if skipsExampleValidation(child, seen) {
+				return true
+			}
+		}
+	case att.Type.IsArray():
+		return skipsExampleValidation(att.Type.ToArray().ElemType, seen)
+	case att.Type.IsHash():
+		h := att.Type.ToHash()
+		return skipsExampleValidation(h.KeyType, seen) || skipsExampleValidation(h.ElemType, seen)
+	}
+	return false
+}
+
+// lenientTransforms lists the encode transforms that accept any string.
+var lenientTransforms = map[string]bool{"lowercase": true, "trim": true, "uppercase": true}
+
+// usesStrictTransform returns true if the top level attributes of the projected media type or of
+// its elements use an encode transform that may reject the example values.
+func usesStrictTransform(projected *design.MediaTypeDefinition) bool {
+	dt := projected.Type
+	if dt.IsArray() {
+		dt = dt.ToArray().ElemType.Type
+	}
+	for _, att := range dt.ToObject() {
+		if t := att.EncodeTransform(); t != "" && !lenientTransforms[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// exampleStrings returns the string representations of the given parameter or header example,
+// one per element if the example is an array.
+func exampleStrings(ex interface{}) []string {
+	switch v := ex.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		res := make([]string, len(v))
+		for i, e := range v {
+			res[i] = exampleString(e)
+		}
+		return res
+	default:
+		return []string{exampleString(v)}
+	}
+}
+
+// exampleString returns the string representation of the given primitive example value.
+func exampleString(ex interface{}) string {
+	if t, ok := ex.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(ex)
+}
+
+// sortedKeys returns the sorted names of the attributes of the given object.
+func sortedKeys(o design.Object) []string {
+	names := make([]string, 0, len(o))
+	for n := range o {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+const roundTripT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }}: Application Round-Trip Tests
+//
+// Command:
+{{ comment commandLine }}
+
+package {{ .Package }}
+
+import (
+{{ if .HasStream }}	"bytes"
+{{ end }}	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+{{ if .HasCompare }}	"reflect"
+{{ end }}{{ if .HasPayload }}	"strings"
+{{ end }}	"testing"
+
+	"github.com/goadesign/goa"
+)
+{{ range $res := .Resources }}
+// roundTrip{{ .Name }}Controller implements the {{ .Name }} controller with the example responses.
+type roundTrip{{ .Name }}Controller struct {
+	*goa.Controller
+}
+{{ range .Actions }}
+// {{ .Name }} {{ if .Tested }}sends the example response{{ else }}is not exercised by the round-trip tests{{ end }}.
+func (c *roundTrip{{ $res.Name }}Controller) {{ .Name }}(ctx *{{ .Context }}) error {
+{{ if not .Tested }}	return goa.ErrInternal("not implemented")
+{{ else if .RespBytes }}	return ctx.{{ .RespMethod }}([]byte({{ printf "%q" .RespBody }}))
+{{ else if .RespType }}	var res {{ .RespType }}
+	if err := json.Unmarshal([]byte({{ printf "%q" .RespBody }}), &res); err != nil {
+		return err
+	}
+	return ctx.{{ .RespMethod }}({{ if .RespPointer }}&{{ end }}res)
+{{ else }}	return ctx.{{ .RespMethod }}()
+{{ end }}}
+{{ end }}{{ end }}{{ range $res := .Resources }}{{ range .Actions }}{{ if .Tested }}
+// TestRoundTrip{{ .Name }}{{ .Resource }} sends the example request of the {{ .DesignName }} action
+// of the {{ .ResourceDesignName }} resource and checks the response.
+func TestRoundTrip{{ .Name }}{{ .Resource }}(t *testing.T) {
+	service := goa.New("roundtrip")
+	service.WithLogger(goa.NewLogger(log.New(ioutil.Discard, "", 0)))
+	Mount{{ .Resource }}Controller(service, &roundTrip{{ .Resource }}Controller{Controller: service.NewController("{{ .Resource }}Controller")})
+	server := httptest.NewServer(service.Mux)
+	defer server.Close()
+
+	req, err := http.NewRequest({{ printf "%q" .Method }}, server.URL+{{ printf "%q" .Path }}, {{ if .Payload }}strings.NewReader({{ printf "%q" .Payload }}){{ else }}nil{{ end }})
+	if err != nil {
+		t.Fatal(err)
+	}
+{{ if .Payload }}	req.Header.Set("Content-Type", "application/json")
+{{ end }}{{ range .Headers }}{{ $name := .Name }}{{ range .Values }}	req.Header.Add({{ printf "%q" $name }}, {{ printf "%q" . }})
+{{ end }}{{ end }}{{ if .Query }}	query := req.URL.Query()
+{{ range .Query }}{{ $name := .Name }}{{ range .Values }}	query.Add({{ printf "%q" $name }}, {{ printf "%q" . }})
+{{ end }}{{ end }}	req.URL.RawQuery = query.Encode()
+{{ end }}	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != {{ .Status }} {
+		body, _ := ioutil.ReadAll(resp.Body)
+		t.Fatalf("invalid response status, expected {{ .Status }}, got %d: %s", resp.StatusCode, body)
+	}
+{{ if .RespBytes }}	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != {{ printf "%q" .RespBody }} {
+		t.Errorf("invalid response body, expected %q, got %q", {{ printf "%q" .RespBody }}, body)
+	}
+{{ else if .RespType }}{{ if .Compare }}	var expected, actual {{ .RespType }}
+	if err := json.Unmarshal([]byte({{ printf "%q" .RespBody }}), &expected); err != nil {
+		t.Fatal(err)
+	}
+{{ else }}	var actual {{ .RespType }}
+{{ end }}	dec := json.NewDecoder(resp.Body)
+{{ if .Stream }}	var frames [][]byte
+	for dec.More() {
+		var frame json.RawMessage
+		if err := dec.Decode(&frame); err != nil {
+			t.Fatalf("failed to decode response frame: %s", err)
+		}
+		frames = append(frames, frame)
+	}
+	stream := append(append([]byte("["), bytes.Join(frames, []byte(","))...), ']')
+	if err := json.Unmarshal(stream, &actual); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+{{ else }}	if err := dec.Decode({{ if .Envelope }}&goa.Envelope{DataField: {{ printf "%q" .Envelope.DataField }}, MetaField: {{ printf "%q" .Envelope.MetaField }}, Data: &actual}{{ else }}&actual{{ end }}); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+{{ end }}{{ if .Compare }}	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("invalid response body, expected %#v, got %#v", expected, actual)
+	}
+{{ end }}{{ end }}}
+{{ end }}{{ end }}{{ end }}`
@@ -0,0 +1,119 @@
+package genapp_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_app"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateRoundTripTests", func() {
+	var files map[string][]byte
+	var genErr error
+	var skip bool
+
+	BeforeEach(func() {
+		skip = false
+	})
+
+	JustBeforeEach(func() {
+		resetDesign()
+		API("cellar", func() {
+			BasicAuthSecurity("password")
+		})
+		MediaType("application/vnd.bottle", func() {
+			TypeName("Bottle")
+			Attributes(func() {
+				Attribute("id", Integer, func() { Example(1) })
+				Attribute("name", String, func() { Example("Number 8") })
+				Required("id", "name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", String, func() { Example("first bottle") })
+				})
+				Response(OK, "application/vnd.bottle")
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(func() {
+					Member("name", String, func() { Example("Number 8") })
+					Member("code", String, func() {
+						Pattern("^[0-9]+$")
+						if skip {
+							Example("XXXX", func() { SkipValidation() })
+						} else {
+							Example("1234")
+						}
+					})
+					Required("name")
+				})
+				Response(Created)
+			})
+			Action("delete", func() {
+				Routing(DELETE("/:id"))
+				Security("password")
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genapp.GenerateRoundTripTests(Design, "app")
+	})
+
+	It("generates a test file in the app package", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(1))
+		Ω(files).Should(HaveKey("roundtrip_test.go"))
+		f, err := parser.ParseFile(token.NewFileSet(), "roundtrip_test.go", files["roundtrip_test.go"], 0)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(f.Name.Name).Should(Equal("app"))
+	})
+
+	It("generates a controller implementing all the actions", func() {
+		src := string(files["roundtrip_test.go"])
+		Ω(src).Should(ContainSubstring("type roundTripBottleController struct {"))
+		for _, name := range []string{"Show", "Create", "Delete"} {
+			Ω(src).Should(ContainSubstring("func (c *roundTripBottleController) " + name + "(ctx *" + name + "BottleContext) error {"))
+		}
+	})
+
+	It("generates a test for each supported action", func() {
+		src := string(files["roundtrip_test.go"])
+		Ω(src).Should(ContainSubstring("func TestRoundTripShowBottle(t *testing.T) {"))
+		Ω(src).Should(ContainSubstring("func TestRoundTripCreateBottle(t *testing.T) {"))
+		Ω(src).ShouldNot(ContainSubstring("TestRoundTripDeleteBottle"))
+	})
+
+	It("sends the path parameter examples and compares the responses", func() {
+		src := string(files["roundtrip_test.go"])
+		Ω(src).Should(ContainSubstring(`server.URL+"/bottles/first%20bottle"`))
+		Ω(src).Should(ContainSubstring(`strings.NewReader("{\"code\":\"1234\",\"name\":\"Number 8\"}")`))
+		Ω(src).Should(ContainSubstring("reflect.DeepEqual("))
+	})
+
+	Context("with a payload example that skips validation", func() {
+		BeforeEach(func() {
+			skip = true
+		})
+
+		It("does not generate the test of the action", func() {
+			src := string(files["roundtrip_test.go"])
+			Ω(src).Should(ContainSubstring("func TestRoundTripShowBottle(t *testing.T) {"))
+			Ω(src).ShouldNot(ContainSubstring("TestRoundTripCreateBottle"))
+			Ω(src).ShouldNot(ContainSubstring("XXXX"))
+		})
+	})
+})
@@ -44,6 +44,8 @@ type TestMethod struct {
 	QueryParams       []*ObjectType
 	Headers           []*ObjectType
 	Payload           *ObjectType
+	LegacySignature   bool
+	Enveloped         bool
 	reservedNames     map[string]bool
 }
 
@@ -63,6 +65,7 @@ type ObjectType struct {
 	Type        string
 	Pointer     string
 	Validatable bool
+	Fields      []*ObjectType
 }
 
 func (g *Generator) generateResourceTest() error {
@@ -120,6 +123,9 @@ func (g *Generator) generateResourceTest() error {
 		var methods []*TestMethod
 
 		if err = res.IterateActions(func(action *design.ActionDefinition) error {
+			if action.BatchSource() != nil { // Batch actions call the controller of their source action
+				return nil
+			}
 			if err := action.IterateResponses(func(response *design.ResponseDefinition) error {
 				if response.Status == 101 { // SwitchingProtocols, Don't currently handle WebSocket endpoints
 					return nil
@@ -205,7 +211,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 	comment += "."
 
 	path = pathParams(action, route)
-	query = queryParams(action)
+	query = queryParams(action, g.Target)
 	header = headers(action, resource.Headers)
 
 	if action.Payload != nil {
@@ -217,7 +223,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		}
 
 		validate := g.validator.Code(action.Payload.AttributeDefinition, false, false, false, "payload", "raw", 1, false)
-		if validate != "" {
+		if validate != "" && !action.SkipsRequestBodyValidation() {
 			payload.Validatable = true
 		}
 	}
@@ -239,6 +245,8 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		RouteVerb:         route.Verb,
 		Status:            response.Status,
 		FullPath:          goPathFormat(route.FullPath()),
+		LegacySignature:   g.LegacySignatures,
+		Enveloped:         action.BodyEnvelope() != nil && response.Status >= 200 && response.Status < 300,
 		reservedNames:     reservedNames(path, query, header, payload, returnType),
 	}
 }
@@ -280,7 +288,7 @@ func headers(action *design.ActionDefinition, headers *design.AttributeDefinitio
 }
 
 // queryParams returns the query string params for the given action.
-func queryParams(action *design.ActionDefinition) []*ObjectType {
+func queryParams(action *design.ActionDefinition, target string) []*ObjectType {
 	var qparams []string
 	if qps := action.QueryParams; qps != nil {
 		for pname := range qps.Type.ToObject() {
@@ -288,7 +296,37 @@ func queryParams(action *design.ActionDefinition) []*ObjectType {
 		}
 	}
 	sort.Strings(qparams)
-	return paramFromNames(action, qparams)
+	params := paramFromNames(action, qparams)
+	for i, name := range qparams {
+		if att := action.Params.Type.ToObject()[name]; att.IsDeepObject() {
+			deepObjectFields(params[i], att, target)
+		}
+	}
+	return params
+}
+
+// deepObjectFields initializes the fields of the given object param. The fields are given in the
+// query string using the bracketed syntax, e.g. "filter[status]=active".
+func deepObjectFields(param *ObjectType, att *design.AttributeDefinition, target string) {
+	obj := att
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok {
+		param.Type = fmt.Sprintf("*%s.%s", target, codegen.Goify(ut.TypeName, true))
+		obj = ut.AttributeDefinition
+	} else {
+		param.Type = codegen.GoTypeRef(att.Type, att.AllRequired(), 0, false)
+	}
+	var names []string
+	for n := range obj.Type.ToObject() {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		f := obj.Type.ToObject()[n]
+		field := attToObject(n, obj, f)
+		field.Label = fmt.Sprintf("%s[%s]", param.Label, n)
+		field.Name = fmt.Sprintf("%s.%s", param.Name, codegen.GoifyAtt(f, n, true))
+		param.Fields = append(param.Fields, field)
+	}
 }
 
 func paramFromNames(action *design.ActionDefinition, names []string) (params []*ObjectType) {
@@ -360,7 +398,7 @@ var testTmpl = `{{ define "convertParam" }}` + convertParamTmpl + `{{ end }}` +
 // {{ $test.Name }} {{ $test.Comment }}
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl {{ $test.ControllerName}}{{/*
+func {{ $test.Name }}({{ if $test.LegacySignature }}t goatest.TInterface, ctx context.Context{{ else }}ctx context.Context, t goatest.TInterface{{ end }}, service *goa.Service, ctrl {{ $test.ControllerName}}{{/*
 */}}{{ range $param := $test.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $param := $test.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $header := $test.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}{{/*
@@ -371,7 +409,11 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 		{{ $logBuf := $test.Escape "logBuf" }}{{ $logBuf }} bytes.Buffer
 		{{ $resp := $test.Escape "resp" }}{{ if $test.ReturnType }}{{ $resp }}   interface{}{{ end }}
 
-		{{ $respSetter := $test.Escape "respSetter" }}{{ $respSetter }} goatest.ResponseSetterFunc = func(r interface{}) { {{ if $test.ReturnType }}{{ $resp }} = r{{ end }} }
+		{{ $respSetter := $test.Escape "respSetter" }}{{ $respSetter }} goatest.ResponseSetterFunc = func(r interface{}) { {{ if $test.ReturnType }}{{ if $test.Enveloped }}
+			if env, ok := r.(*goa.Envelope); ok {
+				r = env.Data
+			}
+		{{ end }}{{ $resp }} = r{{ end }} }
 	)
 	if service == nil {
 		service = goatest.Service(&{{ $logBuf }}, {{ $respSetter }})
@@ -397,11 +439,17 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	// Setup request context
 	{{ $rw := $test.Escape "rw" }}{{ $rw }} := httptest.NewRecorder()
 {{ $query := $test.Escape "query" }}{{ if $test.QueryParams}}	{{ $query }} := url.Values{}
-{{ range $param := $test.QueryParams }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
+{{ range $param := $test.QueryParams }}{{ if $param.Fields }}	if {{ $param.Name }} != nil {
+{{ range $field := $param.Fields }}{{ if $field.Pointer }}	if {{ $field.Name }} != nil {{ end }}{
+{{ template "convertParam" $field }}
+		{{ $query }}[{{ printf "%q" $field.Label }}] = sliceVal
+	}
+{{ end }}	}
+{{ else }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
 {{ template "convertParam" $param }}
 		{{ $query }}[{{ printf "%q" $param.Label }}] = sliceVal
 	}
-{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
+{{ end }}{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
 		Path: fmt.Sprintf({{ printf "%q" $test.FullPath }}{{ range $param := $test.Params }}, {{ $param.Name }}{{ end }}),
 {{ if $test.QueryParams }}		RawQuery: {{ $query }}.Encode(),
 {{ end }}	}
@@ -415,11 +463,17 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	}
 {{ end }} {{ $prms := $test.Escape "prms" }}{{ $prms }} := url.Values{}
 {{ range $param := $test.Params }}	{{ $prms }}["{{ $param.Label }}"] = []string{fmt.Sprintf("%v",{{ $param.Name}})}
-{{ end }}{{ range $param := $test.QueryParams }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
+{{ end }}{{ range $param := $test.QueryParams }}{{ if $param.Fields }}	if {{ $param.Name }} != nil {
+{{ range $field := $param.Fields }}{{ if $field.Pointer }}	if {{ $field.Name }} != nil {{ end }}{
+{{ template "convertParam" $field }}
+		{{ $prms }}[{{ printf "%q" $field.Label }}] = sliceVal
+	}
+{{ end }}	}
+{{ else }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
 {{ template "convertParam" $param }}
 		{{ $prms }}[{{ printf "%q" $param.Label }}] = sliceVal
 	}
-{{ end }}	if ctx == nil {
+{{ end }}{{ end }}	if ctx == nil {
 		ctx = context.Background()
 	}
 	{{ $goaCtx := $test.Escape "goaCtx" }}{{ $goaCtx }} := goa.NewContext(goa.WithAction(ctx, "{{ $test.ResourceName }}Test"), {{ $rw }}, {{ $req }}, {{ $prms }})
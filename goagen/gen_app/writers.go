@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -46,6 +47,11 @@ type (
 		SecurityTmpl *template.Template
 	}
 
+	// RequestIDWriter generate code for the request ID middleware.
+	RequestIDWriter struct {
+		*codegen.SourceFile
+	}
+
 	// ResourcesWriter generate code for a goa application resources.
 	// Resources are data structures initialized by the application handlers and passed to controller
 	// actions.
@@ -74,17 +80,33 @@ type (
 	// ContextTemplateData contains all the information used by the template to render the context
 	// code for an action.
 	ContextTemplateData struct {
-		Name         string // e.g. "ListBottleContext"
-		ResourceName string // e.g. "bottles"
-		ActionName   string // e.g. "list"
-		Params       *design.AttributeDefinition
-		Payload      *design.UserTypeDefinition
-		Headers      *design.AttributeDefinition
-		Routes       []*design.RouteDefinition
-		Responses    map[string]*design.ResponseDefinition
-		API          *design.APIDefinition
-		DefaultPkg   string
-		Security     *design.SecurityDefinition
+		Name           string // e.g. "ListBottleContext"
+		ResourceName   string // e.g. "bottles"
+		ActionName     string // e.g. "list"
+		Params         *design.AttributeDefinition
+		Payload        *design.UserTypeDefinition
+		Headers        *design.AttributeDefinition
+		Routes         []*design.RouteDefinition
+		Responses      map[string]*design.ResponseDefinition
+		API            *design.APIDefinition
+		DefaultPkg     string
+		Security       *design.SecurityDefinition
+		ErrorMedia     *design.MediaTypeDefinition   // Media type used to render error responses if not the built-in one
+		ProblemDetails bool                          // Whether error responses are rendered as problem details
+		SurrogateKeys  []string                      // Names of the success response attributes written to the Surrogate-Key header
+		RespondFrom    []string                      // Boolean response attribute and names of the responses it selects if any
+		SparseFields   bool                          // Whether success responses are filtered with the "fields" param
+		EchoUpdated    bool                          // Whether success responses are restricted to the attributes set in the request body
+		StreamJSON     bool                          // Whether success collection responses are streamed as newline delimited JSON
+		MaxPageSize    int                           // Maximum value of the "page_size" param, 0 if not limited
+		Version        string                        // Version of the resource if any
+		Deprecated     bool                          // Whether responses include the "Deprecation" header
+		Sunset         string                        // Value of the "Sunset" header in the HTTP date format if any
+		SortFields     []string                      // Names of the fields allowed in the "sort" param
+		SortDefaults   []*design.SortFieldDefinition // Default sort options of the sortable fields, nil if none
+		FilterFields   []string                      // Names of the fields allowed in the "filter[field]" params
+		Events         []*design.EventDefinition     // Domain events emitted by the action
+		Envelope       *design.EnvelopeDefinition    // Envelope that wraps the request and success response bodies if any
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
@@ -97,6 +119,12 @@ type (
 		Decoders       []*EncoderTemplateData         // Decoder data
 		Origins        []*design.CORSDefinition       // CORS policies
 		PreflightPaths []string
+		ErrorMedia     *design.MediaTypeDefinition // Media type used to render error responses if not the built-in one
+		ProblemDetails bool                        // Whether error responses are rendered as problem details
+		Default        *design.ResponseDefinition  // Response used to render the unmapped errors and panics if any
+		Events         []*design.EventDefinition   // Domain events emitted by the resource actions
+		Version        string                      // Resource version if any
+		VersionMedia   string                      // Pattern of the media types that select the resource versions if the resource is versioned
 	}
 
 	// ResourceData contains the information required to generate the resource GoGenerator
@@ -211,12 +239,15 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 	}
 	fn := template.FuncMap{
 		"newCoerceData":      newCoerceData,
+		"newDeepObjectData":  newDeepObjectData,
+		"splitsArray":        splitsArray,
 		"arrayAttribute":     arrayAttribute,
 		"printVal":           codegen.PrintVal,
 		"canonicalHeaderKey": http.CanonicalHeaderKey,
 		"isPathParam":        data.IsPathParam,
 		"valueTypeOf":        valueTypeOf,
 		"fromString":         fromString,
+		"envelope":           envelopeCode,
 	}
 	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
 		return err
@@ -237,9 +268,31 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
 			}
+			if bind := sqlBindData(data.Payload, "payload"); bind != nil {
+				if err := w.ExecuteTemplate("sqlbind", sqlBindT, nil, bind); err != nil {
+					return err
+				}
+			}
+			if trans := transitionsData(data.Payload, "payload", "raw"); trans != nil {
+				if err := w.ExecuteTemplate("transitions", transitionsT, nil, trans); err != nil {
+					return err
+				}
+			}
+			if data.EchoUpdated {
+				if updated := updatedFieldsData(data.Payload, "payload"); updated != nil {
+					if err := w.ExecuteTemplate("updatedfields", updatedFieldsT, nil, updated); err != nil {
+						return err
+					}
+				}
+			}
+			if paths := fieldPathsData(data.Payload); paths != nil {
+				if err := w.ExecuteTemplate("fieldpaths", fieldPathsT, nil, paths); err != nil {
+					return err
+				}
+			}
 		}
 	}
-	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
+	err := data.IterateResponses(func(resp *design.ResponseDefinition) error {
 		respData := map[string]interface{}{
 			"Context":  data,
 			"Response": resp,
@@ -250,7 +303,10 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			if mt, ok = resp.Type.(*design.MediaTypeDefinition); !ok {
 				respData["Type"] = resp.Type
 				respData["ContentType"] = resp.MediaType
-				return w.ExecuteTemplate("response", ctxTRespT, nil, respData)
+				if resp.Status >= 200 && resp.Status < 300 {
+					respData["Envelope"] = data.Envelope
+				}
+				return w.ExecuteTemplate("response", ctxTRespT, fn, respData)
 			}
 		} else {
 			mt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
@@ -277,6 +333,32 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 				respData["ViewName"] = view
 				respData["MediaType"] = mt
 				respData["ContentType"] = mt.ContentType
+				if resp.Status >= 200 && resp.Status < 300 {
+					respData["SurrogateKeys"] = surrogateKeyFields(projected, data.SurrogateKeys)
+					respData["SparseFields"] = data.SparseFields && !mt.IsError()
+					if data.EchoUpdated && data.Payload != nil && !mt.IsError() {
+						respData["EchoUpdated"] = true
+						respData["EchoRenames"] = echoRenames(data.Payload, projected)
+					}
+					respData["ScopedFields"] = fieldsLiteral(projected, (*design.AttributeDefinition).RequiredScope)
+					respData["EncodeTransforms"] = fieldsLiteral(projected, (*design.AttributeDefinition).EncodeTransform)
+					respData["Envelope"] = data.Envelope
+					if data.StreamJSON && projected.Type.IsArray() {
+						respData["StreamJSON"] = true
+						respData["SparseFields"] = false
+						delete(respData, "EchoUpdated")
+						respData["ContentType"] = "application/x-ndjson"
+						delete(respData, "Envelope")
+					}
+				}
+				respData["Cookies"] = cookieFields(projected, resp.Cookies)
+				respData["VersionHidden"] = versionHiddenJSONNames(projected, data.Version)
+				if mt.IsError() {
+					if ct, builder := errorRendering(data.ErrorMedia, data.ProblemDetails); builder != "" {
+						respData["ContentType"] = ct
+						respData["ErrorBuilder"] = builder
+					}
+				}
 				if view == "default" {
 					respData["RespName"] = codegen.Goify(resp.Name, true)
 				} else {
@@ -291,6 +373,60 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		}
 		return w.ExecuteTemplate("response", ctxNoMTRespT, nil, respData)
 	})
+	if err != nil {
+		return err
+	}
+	if len(data.RespondFrom) == 3 {
+		return w.writeRespond(data)
+	}
+	return nil
+}
+
+// writeRespond writes the Respond method of contexts whose response is selected at runtime from
+// the value of a boolean response attribute.
+func (w *ContextsWriter) writeRespond(data *ContextTemplateData) error {
+	field := data.RespondFrom[0]
+	whenTrue, whenFalse := data.Responses[data.RespondFrom[1]], data.Responses[data.RespondFrom[2]]
+	if whenTrue == nil || whenFalse == nil {
+		return nil
+	}
+	mt, ok := whenTrue.Type.(*design.MediaTypeDefinition)
+	if !ok {
+		mt = design.Design.MediaTypeWithIdentifier(whenTrue.MediaType)
+	}
+	if mt == nil {
+		return nil
+	}
+	view := whenTrue.ViewName
+	if view == "" {
+		view = "default"
+	}
+	projected, _, err := mt.Project(view)
+	if err != nil {
+		return err
+	}
+	att, ok := projected.Type.ToObject()[field]
+	if !ok {
+		return nil
+	}
+	respName := func(resp *design.ResponseDefinition) string {
+		if view == "default" {
+			return codegen.Goify(resp.Name, true)
+		}
+		return codegen.Goify(fmt.Sprintf("%s%s", resp.Name, strings.Title(view)), true)
+	}
+	respData := map[string]interface{}{
+		"Context":   data,
+		"Projected": projected,
+		"Field":     field,
+		"FieldName": codegen.GoifyAtt(att, field, true),
+		"Pointer":   projected.IsPrimitivePointer(field),
+		"WhenTrue":  whenTrue,
+		"WhenFalse": whenFalse,
+		"TrueName":  respName(whenTrue),
+		"FalseName": respName(whenFalse),
+	}
+	return w.ExecuteTemplate("respond", ctxRespondT, nil, respData)
 }
 
 // NewControllersWriter returns a handlers code writer.
@@ -334,6 +470,24 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 				return err
 			}
 		}
+		if ct, builder := errorRendering(d.ErrorMedia, d.ProblemDetails); builder != "" || d.Default != nil {
+			data := map[string]interface{}{
+				"Resource":    d.Resource,
+				"ContentType": ct,
+				"Builder":     builder,
+			}
+			if d.Default != nil {
+				mt := design.Design.MediaTypeWithIdentifier(d.Default.MediaType)
+				data["Default"] = map[string]interface{}{
+					"Status":      d.Default.Status,
+					"ContentType": mt.ContentType,
+					"Builder":     errorBuilderName(mt),
+				}
+			}
+			if err := w.ExecuteTemplate("handleErrors", handleErrorsT, nil, data); err != nil {
+				return err
+			}
+		}
 		fn := template.FuncMap{
 			"newCoerceData":  newCoerceData,
 			"finalizeCode":   w.Finalizer.Code,
@@ -341,6 +495,7 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 			"validationCode": w.Validator.Code,
 			"valueTypeOf":    valueTypeOf,
 			"fromString":     fromString,
+			"envelope":       envelopeCode,
 		}
 		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
 			return err
@@ -364,6 +519,20 @@ func (w *SecurityWriter) Execute(schemes []*design.SecuritySchemeDefinition) err
 	return w.ExecuteTemplate("security_schemes", securitySchemesT, nil, schemes)
 }
 
+// NewRequestIDWriter returns a request ID middleware code writer.
+func NewRequestIDWriter(filename string) (*RequestIDWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestIDWriter{SourceFile: file}, nil
+}
+
+// Execute writes the request ID middleware and context accessor for the given header.
+func (w *RequestIDWriter) Execute(header string) error {
+	return w.ExecuteTemplate("request_id", requestIDT, nil, header)
+}
+
 // NewResourcesWriter returns a contexts code writer.
 // Resources provide the glue between the underlying request data and the user controller.
 func NewResourcesWriter(filename string) (*ResourcesWriter, error) {
@@ -416,6 +585,248 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 	return nil
 }
 
+// WriteErrorBuilder writes the function that builds instances of the given media type from the
+// errors returned by the generated code and the action handlers. The media type is used by
+// resources that define a default error response.
+func (w *MediaTypesWriter) WriteErrorBuilder(mt *design.MediaTypeDefinition) error {
+	p, _, err := mt.Project(design.DefaultView)
+	if err != nil {
+		return err
+	}
+	sources := map[string]string{"message": "e.Detail", "code": "e.Code", "id": "e.ID", "status": "e.Status"}
+	var fields []map[string]interface{}
+	p.Type.ToObject().IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+		if src, ok := sources[n]; ok {
+			fields = append(fields, map[string]interface{}{
+				"Name":    codegen.GoifyAtt(att, n, true),
+				"Source":  src,
+				"Pointer": p.IsPrimitivePointer(n),
+			})
+		}
+		return nil
+	})
+	data := map[string]interface{}{
+		"MediaType": p,
+		"Name":      errorBuilderName(mt),
+		"Fields":    fields,
+	}
+	return w.ExecuteTemplate("errorBuilder", errorBuilderT, nil, data)
+}
+
+// errorBuilderName returns the name of the function generated by WriteErrorBuilder.
+func errorBuilderName(mt *design.MediaTypeDefinition) string {
+	return fmt.Sprintf("New%sFromError", codegen.GoTypeName(mt, nil, 0, false))
+}
+
+// surrogateKeyFields returns the names of the fields of the given projected media type that hold
+// the values of the surrogate keys. The fields are the fields of the collection elements if the
+// media type is a collection. Keys that are not part of the projected view are skipped.
+func surrogateKeyFields(projected *design.MediaTypeDefinition, keys []string) []string {
+	obj := projected.Type.ToObject()
+	if projected.Type.IsArray() {
+		obj = projected.Type.ToArray().ElemType.Type.ToObject()
+	}
+	var fields []string
+	for _, k := range keys {
+		if att, ok := obj[k]; ok {
+			fields = append(fields, codegen.GoifyAtt(att, k, true))
+		}
+	}
+	return fields
+}
+
+// cookieFields returns the data used to render the code that sets the given cookies from the
+// fields of the given projected media type. Cookies whose attribute is not part of the projected
+// media type are ignored.
+func cookieFields(projected *design.MediaTypeDefinition, cookies []*design.CookieDefinition) []map[string]interface{} {
+	if !projected.Type.IsObject() {
+		return nil
+	}
+	var fields []map[string]interface{}
+	for _, c := range cookies {
+		att, ok := projected.Type.ToObject()[c.Name]
+		if !ok {
+			continue
+		}
+		fields = append(fields, map[string]interface{}{
+			"Name":     c.Name,
+			"JSONName": codegen.FieldName(c.Name, projected.FieldNaming()),
+			"Field":    codegen.GoifyAtt(att, c.Name, true),
+			"Pointer":  projected.IsPrimitivePointer(c.Name),
+			"MaxAge":   c.MaxAge,
+			"HTTPOnly": c.HTTPOnly,
+			"Secure":   c.Secure,
+			"SameSite": c.SameSite,
+		})
+	}
+	return fields
+}
+
+// versionHiddenJSONNames returns the JSON names of the top level fields of the projected media
+// type that the given resource version omits from the responses.
+func versionHiddenJSONNames(projected *design.MediaTypeDefinition, version string) []string {
+	hidden := projected.VersionHiddenFields(version)
+	if len(hidden) == 0 {
+		return nil
+	}
+	parent := projected.AttributeDefinition
+	if projected.Type.IsArray() {
+		parent = projected.Type.ToArray().ElemType
+		if ds, ok := parent.Type.(design.DataStructure); ok {
+			parent = ds.Definition()
+		}
+	}
+	names := make([]string, len(hidden))
+	for i, n := range hidden {
+		names[i] = codegen.FieldName(n, parent.FieldNaming())
+	}
+	return names
+}
+
+// versionHiddenFields returns the data used to reset the fields of the given payload that the
+// given resource version ignores in the requests. Each element has the Go field name and the zero
+// value of the field.
+func versionHiddenFields(payload *design.UserTypeDefinition, version string) []map[string]string {
+	hidden := payload.VersionHiddenFields(version)
+	if len(hidden) == 0 {
+		return nil
+	}
+	obj := payload.Type.ToObject()
+	fields := make([]map[string]string, len(hidden))
+	for i, n := range hidden {
+		att := obj[n]
+		zero := "nil"
+		if att.IsNullable() && att.Type.IsPrimitive() {
+			zero = codegen.GoNullableType(att.Type) + "{}"
+		}
+		fields[i] = map[string]string{"Field": codegen.GoifyAtt(att, n, true), "Zero": zero}
+	}
+	return fields
+}
+
+// updatedFieldsData returns the data given to the template that generates the method that lists
+// the attributes set in the request body of the given merge patch payload, nil if the payload is
+// not an object.
+func updatedFieldsData(t *design.UserTypeDefinition, receiver string) map[string]interface{} {
+	if !t.Type.IsObject() {
+		return nil
+	}
+	obj := t.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fields := make([]map[string]interface{}, len(names))
+	for i, n := range names {
+		att := obj[n]
+		field := fmt.Sprintf("%s.%s", receiver, codegen.GoifyAtt(att, n, true))
+		var cond string
+		switch {
+		case att.IsNullable():
+			cond = field + ".Present"
+		case t.IsPrimitivePointer(n) || !att.Type.IsPrimitive() || t.IsInterface(n):
+			cond = field + " != nil"
+		}
+		fields[i] = map[string]interface{}{"Name": n, "Cond": cond}
+	}
+	return map[string]interface{}{
+		"Receiver": receiver,
+		"TypeRef":  codegen.GoTypeRef(t, t.AllRequired(), 0, false),
+		"Fields":   fields,
+	}
+}
+
+// echoRenames returns the Go literal of the map that indexes the JSON names of the top level
+// attributes of the projected media type by the names of the payload attributes they echo, the
+// empty string if the names are identical.
+func echoRenames(payload *design.UserTypeDefinition, projected *design.MediaTypeDefinition) string {
+	policy := projected.FieldNaming()
+	if policy == "" || !payload.Type.IsObject() {
+		return ""
+	}
+	names := make([]string, 0, len(payload.Type.ToObject()))
+	for n := range payload.Type.ToObject() {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var elems []string
+	for _, n := range names {
+		if jn := codegen.FieldName(n, policy); jn != n {
+			elems = append(elems, fmt.Sprintf("%q: %q", n, jn))
+		}
+	}
+	if len(elems) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("map[string]string{%s}", strings.Join(elems, ", "))
+}
+
+// envelopeCode returns the Go expression that wraps the body value in the given envelope, the body
+// value itself if env is nil. meta is the Go expression of the envelope meta object if any.
+func envelopeCode(env *design.EnvelopeDefinition, body, meta string) string {
+	if env == nil {
+		return body
+	}
+	if meta == "" {
+		return fmt.Sprintf("&goa.Envelope{DataField: %q, MetaField: %q, Data: %s}", env.DataField, env.MetaField, body)
+	}
+	return fmt.Sprintf("&goa.Envelope{DataField: %q, MetaField: %q, Data: %s, Meta: %s}", env.DataField, env.MetaField, body, meta)
+}
+
+// fieldsLiteral returns the Go literal of the map that indexes the values returned by value for
+// the top level attributes of the given projected media type, or of its elements if it is a
+// collection, by JSON field name. Attributes for which value returns the empty string are omitted.
+// fieldsLiteral returns the empty string if there is no such attribute.
+func fieldsLiteral(projected *design.MediaTypeDefinition, value func(*design.AttributeDefinition) string) string {
+	parent := projected.AttributeDefinition
+	if projected.Type.IsArray() {
+		parent = projected.Type.ToArray().ElemType
+		if ds, ok := parent.Type.(design.DataStructure); ok {
+			parent = ds.Definition()
+		}
+	}
+	var fields []string
+	for n, att := range parent.Type.ToObject() {
+		if v := value(att); v != "" {
+			fields = append(fields, fmt.Sprintf("%q: %q", codegen.FieldName(n, parent.FieldNaming()), v))
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	sort.Strings(fields)
+	return fmt.Sprintf("map[string]string{%s}", strings.Join(fields, ", "))
+}
+
+// WriteProblemDetailsBuilder writes the function that builds problem details documents from the
+// errors returned by the generated code and the action handlers. The function is used when the
+// API renders errors as problem details.
+func (w *MediaTypesWriter) WriteProblemDetailsBuilder(typeBase string) error {
+	data := map[string]interface{}{
+		"Name":     problemDetailsBuilderName,
+		"TypeBase": typeBase,
+	}
+	return w.ExecuteTemplate("problemDetailsBuilder", problemDetailsBuilderT, nil, data)
+}
+
+// problemDetailsBuilderName is the name of the function generated by WriteProblemDetailsBuilder.
+const problemDetailsBuilderName = "NewProblemDetailsFromError"
+
+// errorRendering returns the content type and the name of the function used to build the error
+// responses of a resource that does not use the built-in error media type. The resource default
+// error response media type takes precedence over problem details. errorRendering returns empty
+// strings if the resource uses the built-in error media type.
+func errorRendering(errorMedia *design.MediaTypeDefinition, problemDetails bool) (contentType, builder string) {
+	if errorMedia != nil {
+		return errorMedia.ContentType, errorBuilderName(errorMedia)
+	}
+	if problemDetails {
+		return design.ProblemDetailsMediaIdentifier, problemDetailsBuilderName
+	}
+	return "", ""
+}
+
 // NewUserTypesWriter returns a contexts code writer.
 // User types contain custom data structured defined in the DSL with "Type".
 func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
@@ -436,7 +847,190 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
 	}
-	return w.ExecuteTemplate("types", userTypeT, fn, t)
+	if err := w.ExecuteTemplate("types", userTypeT, fn, t); err != nil {
+		return err
+	}
+	if bind := sqlBindData(t, "ut"); bind != nil {
+		if err := w.ExecuteTemplate("sqlbind", sqlBindT, nil, bind); err != nil {
+			return err
+		}
+	}
+	if trans := transitionsData(t, "ut", "type"); trans != nil {
+		if err := w.ExecuteTemplate("transitions", transitionsT, nil, trans); err != nil {
+			return err
+		}
+	}
+	if isPayload(t) {
+		if paths := fieldPathsData(t); paths != nil {
+			return w.ExecuteTemplate("fieldpaths", fieldPathsT, nil, paths)
+		}
+	}
+	return nil
+}
+
+// WriteEnum writes the Go type of the integer enum with named values defined by the given
+// attribute.
+func (w *UserTypesWriter) WriteEnum(att *design.AttributeDefinition) error {
+	typeName := codegen.Goify(att.EnumTypeName(), true)
+	values := att.NamedEnumValues()
+	consts := make([]map[string]interface{}, len(values))
+	for i, v := range values {
+		consts[i] = map[string]interface{}{
+			"Const": typeName + codegen.Goify(strings.ToLower(v.Name), true),
+			"Name":  v.Name,
+			"Value": v.Value,
+		}
+	}
+	data := map[string]interface{}{
+		"TypeName":    typeName,
+		"Values":      consts,
+		"EncodeNames": att.EncodesEnumNames(),
+	}
+	return w.ExecuteTemplate("enum", enumT, nil, data)
+}
+
+// WriteFlagSet writes the Go type of the flag set defined by the given attribute.
+func (w *UserTypesWriter) WriteFlagSet(att *design.AttributeDefinition) error {
+	typeName := codegen.Goify(att.FlagSetTypeName(), true)
+	flags := make([]map[string]interface{}, len(att.Flags))
+	for i, f := range att.Flags {
+		flags[i] = map[string]interface{}{
+			"Const": typeName + codegen.Goify(strings.ToLower(f.Name), true),
+			"Name":  f.Name,
+			"Value": f.Value,
+		}
+	}
+	data := map[string]interface{}{
+		"TypeName": typeName,
+		"Flags":    flags,
+	}
+	return w.ExecuteTemplate("flagset", flagSetT, nil, data)
+}
+
+// sqlBindData returns the data given to the template that generates the SQL query helper of the
+// given type, nil if the type is not tagged with the "sql:bindable" metadata. Only the primitive
+// fields of the type produce predicates, the predicates use the column names set with the Column
+// DSL.
+func sqlBindData(t *design.UserTypeDefinition, receiver string) map[string]interface{} {
+	style := t.SQLPlaceholderStyle()
+	if style == "" || !t.Type.IsObject() {
+		return nil
+	}
+	obj := t.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var fields []map[string]interface{}
+	for _, n := range names {
+		att := obj[n]
+		if !att.Type.IsPrimitive() || t.IsInterface(n) || t.IsFile(n) {
+			continue
+		}
+		column := n
+		if c := att.StorageColumn(); c != "" {
+			column = c
+		}
+		pred := strconv.Quote(column + " = ?")
+		if style == "$" {
+			pred = strconv.Quote(column+" = $") + "+strconv.Itoa(len(args))"
+		}
+		fields = append(fields, map[string]interface{}{
+			"IsNull":    strconv.Quote(column + " IS NULL"),
+			"Field":     fmt.Sprintf("%s.%s", receiver, codegen.GoifyAtt(att, n, true)),
+			"Predicate": pred,
+			"Pointer":   t.IsPrimitivePointer(n),
+			"Nullable":  att.IsNullable(),
+		})
+	}
+	return map[string]interface{}{
+		"Receiver": receiver,
+		"TypeRef":  codegen.GoTypeRef(t, t.AllRequired(), 0, false),
+		"Fields":   fields,
+	}
+}
+
+// transitionsData returns the data given to the template that generates the transition
+// validation functions of the given type, nil if none of the type attributes define transitions.
+// ctx is the context used in the validation error messages.
+func transitionsData(t *design.UserTypeDefinition, receiver, ctx string) map[string]interface{} {
+	if !t.Type.IsObject() {
+		return nil
+	}
+	obj := t.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n, att := range obj {
+		if len(att.Transitions) > 0 {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	typeName := codegen.Goify(t.TypeName, true)
+	fields := make([]map[string]interface{}, len(names))
+	for i, n := range names {
+		att := obj[n]
+		cases := make([]map[string]interface{}, len(att.Transitions))
+		for j, tr := range att.Transitions {
+			targets := make([]string, len(tr.Targets))
+			for k, to := range tr.Targets {
+				targets[k] = strconv.Quote(to)
+			}
+			cases[j] = map[string]interface{}{
+				"From":    strconv.Quote(tr.From),
+				"Targets": strings.Join(targets, ", "),
+			}
+		}
+		field := codegen.GoifyAtt(att, n, true)
+		fields[i] = map[string]interface{}{
+			"Name":     n,
+			"Func":     "Validate" + typeName + field + "Transition",
+			"Field":    field,
+			"Context":  fmt.Sprintf("%s.%s", ctx, n),
+			"Pointer":  t.IsPrimitivePointer(n),
+			"Cases":    cases,
+			"TypeName": typeName,
+		}
+	}
+	return map[string]interface{}{
+		"Receiver": receiver,
+		"TypeRef":  codegen.GoTypeRef(t, t.AllRequired(), 0, false),
+		"Fields":   fields,
+	}
+}
+
+// fieldPathsData returns the data given to the template that generates the variable that lists
+// the canonical paths of the attributes of the given payload type, nil if the type is not an
+// object.
+func fieldPathsData(t *design.UserTypeDefinition) map[string]interface{} {
+	paths := codegen.FieldPaths(t.AttributeDefinition)
+	if paths == "" {
+		return nil
+	}
+	typeName := codegen.GoTypeName(t, nil, 0, false)
+	return map[string]interface{}{
+		"Name":     typeName + "Fields",
+		"TypeName": typeName,
+		"Paths":    paths,
+	}
+}
+
+// isPayload returns true if the given user type is the payload of an action of the API.
+func isPayload(t *design.UserTypeDefinition) bool {
+	if design.Design == nil {
+		return false
+	}
+	for _, r := range design.Design.Resources {
+		for _, a := range r.Actions {
+			if a.Payload != nil && a.Payload.TypeName == t.TypeName {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
@@ -451,6 +1045,29 @@ func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, p
 	}
 }
 
+// splitsArray returns true if the values of the given array parameter or header are separated in
+// a single raw value and must be split before being coerced.
+func splitsArray(att *design.AttributeDefinition) bool {
+	style := att.ArrayStyle()
+	return style != "" && style != "multi"
+}
+
+// newDeepObjectData is a helper function that creates a map that can be given to the "DeepObject"
+// template.
+func newDeepObjectData(name string, att *design.AttributeDefinition, mustValidate bool) map[string]interface{} {
+	obj := att
+	if ds, ok := att.Type.(design.DataStructure); ok {
+		// User type: the fields and their validations are defined by the type.
+		obj = ds.Definition()
+	}
+	return map[string]interface{}{
+		"Name":         name,
+		"Attribute":    att,
+		"Object":       obj,
+		"MustValidate": mustValidate,
+	}
+}
+
 // arrayAttribute returns the array element attribute definition.
 func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
 	return a.Type.(*design.Array).ElemType
@@ -510,8 +1127,14 @@ type {{ .Name }} struct {
 {{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
 */}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
 {{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
-*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
+*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type .AllRequired 1 false }}
 {{ end }}{{ end }}{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
+{{ end }}{{ if .SortFields }}	// SortFields lists the fields used to sort the results in order of precedence.
+	SortFields []*goa.SortField
+{{ end }}{{ if .FilterFields }}	// Filters contains the values of the filter params indexed by field name.
+	Filters map[string][]string
+{{ end }}{{ range .Events }}	// {{ goify .Name true }}Event is emitted once the action succeeds if set by the controller.
+	{{ goify .Name true }}Event {{ gotyperef .Type nil 0 false }}
 {{ end }}}
 `
 	// coerceT generates the code that coerces the generic deserialized
@@ -605,9 +1228,43 @@ type {{ .Name }} struct {
 {{ tabs .Depth }}}
 {{ end }}`
 
+	// deepObjectT generates the code that builds an object parameter from the bracketed query
+	// string parameters.
+	// template input: map[string]interface{} as returned by newDeepObjectData
+	deepObjectT = `{{ $field := printf "rctx.%s" (goifyatt .Attribute .Name true) }}{{/*
+*/}}	param{{ goify .Name true }} := goa.DeepObjectParams(req.Params, "{{ .Name }}")
+{{ if .MustValidate }}	if len(param{{ goify .Name true }}) == 0 {
+		err = goa.MergeErrors(err, goa.MissingParamError("{{ .Name }}"))
+	} else {
+{{ else }}	if len(param{{ goify .Name true }}) > 0 {
+{{ end }}		{{ $field }} = &{{ gotypename .Attribute.Type .Attribute.AllRequired 2 false }}{}
+{{ $obj := .Object }}{{ range $fname, $fatt := $obj.Type.ToObject }}{{/*
+*/}}{{ $pname := printf "%s[%s]" $.Name $fname }}{{ $target := printf "%s.%s" $field (goifyatt $fatt $fname true) }}{{/*
+*/}}		param{{ goify $pname true }} := param{{ goify $.Name true }}["{{ $fname }}"]
+		if len(param{{ goify $pname true }}) > 0 {
+{{ if $fatt.Type.IsArray }}{{ if eq (arrayAttribute $fatt).Type.Kind 4 }}			{{ $target }} = param{{ goify $pname true }}
+{{ else }}			params := make({{ gotypedef $fatt 3 true false }}, len(param{{ goify $pname true }}))
+			for i, raw{{ goify $pname true }} := range param{{ goify $pname true }} {
+{{ template "Coerce" (newCoerceData $pname (arrayAttribute $fatt) false "params[i]" 4) }}{{/*
+*/}}			}
+			{{ $target }} = params
+{{ end }}{{ $validation := validationChecker (arrayAttribute $fatt) true true false "param" (printf "%s[0]" $pname) 4 false }}{{/*
+*/}}{{ if $validation }}			for _, param := range {{ $target }} {
+{{ $validation }}
+			}
+{{ end }}{{ else }}			raw{{ goify $pname true }} := param{{ goify $pname true }}[0]
+{{ template "Coerce" (newCoerceData $pname $fatt ($obj.IsPrimitivePointer $fname) $target 3) }}{{/*
+*/}}{{ $validation := validationChecker $fatt ($obj.IsNonZero $fname) ($obj.IsRequired $fname) ($obj.HasDefaultValue $fname) $target $pname 3 false }}{{/*
+*/}}{{ if $validation }}{{ $validation }}
+{{ end }}{{ end }}		}
+{{ end }}{{ $validation := validationChecker .Attribute false .MustValidate false $field .Name 2 false }}{{/*
+*/}}{{ if $validation }}{{ $validation }}
+{{ end }}	}
+`
+
 	// ctxNewT generates the code for the context factory method.
 	// template input: *ContextTemplateData
-	ctxNewT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `
+	ctxNewT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `{{ define "DeepObject" }}` + deepObjectT + `{{ end }}` + `
 // New{{ goify .Name true }} parses the incoming request URL and body, performs validations and creates the
 // context used by the {{ .ResourceName }} controller {{ .ActionName }} action.
 func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service) (*{{ .Name }}, error) {
@@ -618,7 +1275,7 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 	req.Request = r
 	rctx := {{ .Name }}{Context: ctx, ResponseData: resp, RequestData: req}{{/*
 */}}
-{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
+{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := {{ if splitsArray $att }}goa.SplitArrayParam(req.Header["{{ canonicalHeaderKey $name }}"], "{{ $att.ArrayStyle }}"){{ else }}req.Header["{{ canonicalHeaderKey $name }}"]{{ end }}
 {{ $mustValidate := $.Headers.IsRequired $name }}{{ if $mustValidate }}	if len(header{{ goify $name true }}) == 0 {
 		err = goa.MergeErrors(err, goa.MissingHeaderError("{{ $name }}"))
 	} else {
@@ -638,8 +1295,9 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 {{ end }}	}
 {{ end }}{{ end }}{{/* if .Headers }}{{/*
 
-*/}}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
-*/}}	param{{ goify $name true }} := req.Params["{{ $name }}"]
+*/}}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{ if $att.IsDeepObject }}{{/*
+*/}}{{ template "DeepObject" (newDeepObjectData $name $att ($.MustValidate $name)) }}{{ else }}{{/*
+*/}}	param{{ goify $name true }} := {{ if splitsArray $att }}goa.SplitArrayParam(req.Params["{{ $name }}"], "{{ $att.ArrayStyle }}"){{ else }}req.Params["{{ $name }}"]{{ end }}
 {{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $name true }}) == 0 {
 		{{ if $.Params.HasDefaultValue $name }}{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}{{else}}{{/*
 */}}err = goa.MergeErrors(err, goa.MissingParamError("{{ $name }}")){{end}}
@@ -660,9 +1318,36 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 */}}{{ if $validation }}for _, param := range {{ printf "rctx.%s" (goifyatt $att $name true) }} {
 	{{ $validation }}
 	}{{ end }}{{/*
+*/}}{{ $arrayValidation := validationChecker $att true true false (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+*/}}{{ if $arrayValidation }}{{ if $validation }}
+{{ end }}{{ $arrayValidation }}
+{{ end }}{{/*
 */}}{{ else }}{{ $validation := validationChecker $att ($.Params.IsNonZero $name) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
 */}}{{ if $validation }}{{ $validation }}{{ end }}{{ end }}	}
-{{ end }}{{ end }}{{/* if .Params */}}	return &rctx, err
+{{ end }}{{ end }}{{ end }}{{/* if .Params */}}{{ if .SortDefaults }}	if sortFields, err2 := goa.ParseSortFields(req.Params["sort"]{{ range .SortDefaults }}, {{/*
+*/}}&goa.SortField{Name: {{ printf "%q" .Name }}{{ if .Descending }}, Descending: true{{ end }}{{ if eq .Nulls "first" }}, Nulls: goa.NullsFirst{{ else if eq .Nulls "last" }}, Nulls: goa.NullsLast{{ end }}}{{ end }}); err2 == nil {
+		rctx.SortFields = sortFields
+	} else {
+		err = goa.MergeErrors(err, err2)
+	}
+{{ else if .SortFields }}	if sortFields, err2 := goa.ParseSort(req.Params["sort"]{{ range .SortFields }}, {{ printf "%q" . }}{{ end }}); err2 == nil {
+		rctx.SortFields = sortFields
+	} else {
+		err = goa.MergeErrors(err, err2)
+	}
+{{ end }}{{ if .FilterFields }}	if filters, err2 := goa.ParseFilters(req.Params{{ range .FilterFields }}, {{ printf "%q" . }}{{ end }}); err2 == nil {
+		rctx.Filters = filters
+	} else {
+		err = goa.MergeErrors(err, err2)
+	}
+{{ end }}{{ if .MaxPageSize }}{{ $pageSize := printf "rctx.%s" (goifyatt (index .Params.Type.ToObject "page_size") "page_size" true) }}{{/*
+*/}}	if {{ $pageSize }} > {{ .MaxPageSize }} {
+		{{ $pageSize }} = {{ .MaxPageSize }}
+		rctx.ResponseData.Header().Set("Warning", ` + "`" + `299 - "page_size clamped to {{ .MaxPageSize }}"` + "`" + `)
+	}
+{{ end }}{{ if .Deprecated }}	rctx.ResponseData.Header().Set("Deprecation", "true")
+{{ if .Sunset }}	rctx.ResponseData.Header().Set("Sunset", {{ printf "%q" .Sunset }})
+{{ end }}{{ end }}	return &rctx, err
 }
 `
 
@@ -676,8 +1361,78 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 {{ if .Projected.Type.IsArray }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
-{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
-}
+{{ end }}{{ if .Projected.ComputedFields }}{{ if .Projected.Type.IsArray }}	r.ComputeFields()
+{{ else }}	if r != nil {
+		r.ComputeFields()
+	}
+{{ end }}{{ end }}{{ if .SurrogateKeys }}	var keys []interface{}
+{{ if .Projected.Type.IsArray }}	for _, e := range r {
+		keys = append(keys{{ range .SurrogateKeys }}, e.{{ . }}{{ end }})
+	}
+{{ else }}	if r != nil {
+		keys = append(keys{{ range .SurrogateKeys }}, r.{{ . }}{{ end }})
+	}
+{{ end }}	if k := goa.SurrogateKeys(keys...); k != "" {
+		ctx.ResponseData.Header().Set("Surrogate-Key", k)
+	}
+{{ end }}{{ range .Cookies }}	if r != nil{{ if .Pointer }} && r.{{ .Field }} != nil{{ end }} {
+		http.SetCookie(ctx.ResponseData, &http.Cookie{Name: {{ printf "%q" .Name }}, Value: {{ if .Pointer }}*{{ end }}r.{{ .Field }}{{ if .MaxAge }}, MaxAge: {{ .MaxAge }}{{ end }}{{ if .HTTPOnly }}, HttpOnly: true{{ end }}{{ if .Secure }}, Secure: true{{ end }}{{ if .SameSite }}, SameSite: http.SameSite{{ .SameSite }}Mode{{ end }}})
+	}
+{{ end }}{{ $body := "r" }}{{ if and .ScopedFields (not .StreamJSON) }}	body, err := goa.OmitScopedFields(ctx.Context, r, {{ .ScopedFields }})
+	if err != nil {
+		return err
+	}
+{{ $body = "body" }}{{ end }}{{ if .Cookies }}	body, err {{ if eq $body "body" }}={{ else }}:={{ end }} goa.OmitFields({{ $body }}{{ range .Cookies }}, {{ printf "%q" .JSONName }}{{ end }})
+	if err != nil {
+		return err
+	}
+{{ $body = "body" }}{{ end }}{{ if and .VersionHidden (not .StreamJSON) }}	body, err {{ if eq $body "body" }}={{ else }}:={{ end }} goa.OmitFields({{ $body }}{{ range .VersionHidden }}, {{ printf "%q" . }}{{ end }})
+	if err != nil {
+		return err
+	}
+{{ $body = "body" }}{{ end }}{{ if and .EncodeTransforms (not .StreamJSON) }}	body, err {{ if eq $body "body" }}={{ else }}:={{ end }} goa.ApplyEncodeTransforms({{ $body }}, {{ .EncodeTransforms }})
+	if err != nil {
+		return err
+	}
+{{ $body = "body" }}{{ end }}{{ if .EchoUpdated }}	updated := ctx.Payload.UpdatedFields()
+{{ if .EchoRenames }}	for i, f := range updated {
+		if n, ok := {{ .EchoRenames }}[f]; ok {
+			updated[i] = n
+		}
+	}
+{{ end }}	body, err {{ if eq $body "body" }}={{ else }}:={{ end }} goa.SelectFields({{ $body }}, updated...)
+	if err != nil {
+		return err
+	}
+{{ $body = "body" }}{{ end }}{{ if .SparseFields }}	if ctx.Fields != nil {
+		v, err := goa.SparseFieldset({{ $body }}, *ctx.Fields)
+		if err != nil {
+			return err
+		}
+		return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, {{ envelope .Envelope "v" "ctx.ResponseData.Meta" }})
+	}
+{{ end }}{{ if .StreamJSON }}	ctx.ResponseData.WriteHeader({{ .Response.Status }})
+	enc := goa.NewNDJSONEncoder(ctx.ResponseData)
+	for _, e := range r {
+{{ $elem := "e" }}{{ if .ScopedFields }}		v, err := goa.OmitScopedFields(ctx.Context, e, {{ .ScopedFields }})
+		if err != nil {
+			return err
+		}
+{{ $elem = "v" }}{{ end }}{{ if .VersionHidden }}		v, err {{ if eq $elem "v" }}={{ else }}:={{ end }} goa.OmitFields({{ $elem }}{{ range .VersionHidden }}, {{ printf "%q" . }}{{ end }})
+		if err != nil {
+			return err
+		}
+{{ $elem = "v" }}{{ end }}{{ if .EncodeTransforms }}		v, err {{ if eq $elem "v" }}={{ else }}:={{ end }} goa.ApplyEncodeTransforms({{ $elem }}, {{ .EncodeTransforms }})
+		if err != nil {
+			return err
+		}
+{{ $elem = "v" }}{{ end }}		if err := enc.Encode({{ $elem }}); err != nil {
+			return err
+		}
+	}
+	return nil
+{{ else }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, {{ if .ErrorBuilder }}{{ .ErrorBuilder }}(r){{ else }}{{ envelope .Envelope $body "ctx.ResponseData.Meta" }}{{ end }})
+{{ end }}}
 `
 
 	// ctxTRespT generates the response helpers for responses with overridden types.
@@ -687,7 +1442,20 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 	if ctx.ResponseData.Header().Get("Content-Type") == "" {
 		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	}
-	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, {{ envelope .Envelope "r" "ctx.ResponseData.Meta" }})
+}
+`
+
+	// ctxRespondT generates the Respond method of contexts whose response is selected from the value
+	// of a boolean response attribute.
+	// template input: map[string]interface{}
+	ctxRespondT = `// Respond sends a HTTP response with status code {{ .WhenTrue.Status }} if the {{ .Field }} field of r is true,
+// with status code {{ .WhenFalse.Status }} otherwise.
+func (ctx *{{ .Context.Name }}) Respond(r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+	if r != nil && {{ if .Pointer }}r.{{ .FieldName }} != nil && *{{ end }}r.{{ .FieldName }} {
+		return ctx.{{ .TrueName }}(r)
+	}
+	return ctx.{{ .FalseName }}(r)
 }
 `
 
@@ -711,7 +1479,7 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.
 	payloadT = `{{ $payload := .Payload }}{{ if .Payload.IsObject }}// {{ gotypename .Payload nil 0 true }} is the {{ .ResourceName }} {{ .ActionName }} action payload.{{/*
 */}}{{ $privateTypeName := gotypename .Payload nil 1 true }}
 type {{ $privateTypeName }} {{ gotypedef .Payload 0 true true }}
-
+{{ jsonMarshaler .Payload.AttributeDefinition $privateTypeName true }}
 {{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}// Finalize sets the default values defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Finalize() {
 {{ $assignment }}
@@ -732,22 +1500,120 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize()
 
 // {{ gotypename .Payload nil 0 false }} is the {{ .ResourceName }} {{ .ActionName }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
-
+{{ jsonMarshaler .Payload.AttributeDefinition (gotypename .Payload nil 1 false) false }}
 {{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
 `
+	// fieldPathsT generates the variable that lists the canonical paths of the payload attributes.
+	// template input: map[string]interface{}
+	fieldPathsT = `// {{ .Name }} lists the canonical paths of the {{ .TypeName }} attributes as used by the
+// validation errors. Use them as the "attribute" value of hand-written errors so that these
+// reference the attributes the same way the validation errors do.
+var {{ .Name }} = {{ .Paths }}
+`
+
+	// sqlBindT generates the SQL query helper of types tagged with the "sql:bindable" metadata.
+	// template input: map[string]interface{}
+	sqlBindT = `// SQLWhere returns a SQL WHERE clause made of equality predicates for the fields that are set
+// joined with AND, and the corresponding query arguments.
+func ({{ .Receiver }} {{ .TypeRef }}) SQLWhere() (string, []interface{}) {
+	var preds []string
+	var args []interface{}
+{{ range .Fields }}{{ if .Nullable }}	if {{ .Field }}.Present {
+		if {{ .Field }}.Null {
+			preds = append(preds, {{ .IsNull }})
+		} else {
+			args = append(args, {{ .Field }}.Value)
+			preds = append(preds, {{ .Predicate }})
+		}
+	}
+{{ else if .Pointer }}	if {{ .Field }} != nil {
+		args = append(args, *{{ .Field }})
+		preds = append(preds, {{ .Predicate }})
+	}
+{{ else }}	args = append(args, {{ .Field }})
+	preds = append(preds, {{ .Predicate }})
+{{ end }}{{ end }}	return strings.Join(preds, " AND "), args
+}
+`
+
+	// transitionsT generates the transition validation functions of types whose attributes define
+	// transitions.
+	// template input: map[string]interface{}
+	transitionsT = `{{ range .Fields }}// {{ .Func }} returns an error if the {{ printf "%q" .Name }} attribute of {{ .TypeName }} may not
+// transition from the from value to the to value. Keeping the same value is always allowed.
+func {{ .Func }}(from, to string) error {
+	if from == to {
+		return nil
+	}
+	var allowed []string
+	switch from {
+{{ range .Cases }}	case {{ .From }}:
+		allowed = []string{ {{- .Targets -}} }
+{{ end }}	}
+	for _, a := range allowed {
+		if a == to {
+			return nil
+		}
+	}
+	return goa.InvalidTransitionError(` + "`" + `{{ .Context }}` + "`" + `, from, to, allowed)
+}
+
+{{ end }}// ValidateTransitions validates the transitions of the fields of {{ .Receiver }} from their values in
+// old, e.g. the value stored prior to an update. Fields that are not set in either value are not
+// validated.
+func ({{ .Receiver }} {{ .TypeRef }}) ValidateTransitions(old {{ .TypeRef }}) (err error) {
+	if old == nil {
+		return
+	}
+{{ $recv := .Receiver }}{{ range .Fields }}{{ if .Pointer }}	if {{ $recv }}.{{ .Field }} != nil && old.{{ .Field }} != nil {
+		if err2 := {{ .Func }}(*old.{{ .Field }}, *{{ $recv }}.{{ .Field }}); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}
+	}
+{{ else }}	if err2 := {{ .Func }}(old.{{ .Field }}, {{ $recv }}.{{ .Field }}); err2 != nil {
+		err = goa.MergeErrors(err, err2)
+	}
+{{ end }}{{ end }}	return
+}
+`
+
+	// updatedFieldsT generates the method that lists the attributes set in the request body of
+	// merge patch payloads.
+	// template input: map[string]interface{}
+	updatedFieldsT = `// UpdatedFields returns the names of the attributes set in the request body.
+func ({{ .Receiver }} {{ .TypeRef }}) UpdatedFields() []string {
+	if {{ .Receiver }} == nil {
+		return nil
+	}
+	var fields []string
+{{ range .Fields }}{{ if .Cond }}	if {{ .Cond }} {
+		fields = append(fields, {{ printf "%q" .Name }})
+	}
+{{ else }}	fields = append(fields, {{ printf "%q" .Name }})
+{{ end }}{{ end }}	return fields
+}
+`
+
 	// ctrlT generates the controller interface for a given resource.
 	// template input: *ControllerTemplateData
 	ctrlT = `// {{ .Resource }}Controller is the controller interface for the {{ .Resource }} actions.
 type {{ .Resource }}Controller interface {
 	goa.Muxer
 {{ if .FileServers }}	goa.FileServer
-{{ end }}{{ range .Actions }}	{{ .Name }}(*{{ .Context }}) error
+{{ end }}{{ range .Actions }}{{ if not .BatchOf }}	{{ .Name }}(*{{ .Context }}) error
+{{ end }}{{ end }}}
+{{ if .Events }}
+// {{ .Resource }}EventEmitter dispatches the domain events emitted by the {{ .Resource }} actions. The
+// events set in the action contexts are dispatched once the actions succeed if the controller
+// implements this interface.
+type {{ .Resource }}EventEmitter interface {
+{{ range .Events }}	Emit{{ goify .Name true }}(ctx context.Context, event {{ gotyperef .Type nil 0 false }}) error
 {{ end }}}
-`
+{{ end }}`
 
 	// serviceT generates the service initialization code.
 	// template input: *ControllerTemplateData
@@ -761,6 +1627,9 @@ func initService(service *goa.Service) {
 */}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
 {{ end }}
 
+	// Setup encoders and decoders registered with goa.RegisterCodec
+	goa.UseCodecs(service.Encoder, service.Decoder)
+
 	// Setup default encoder and decoder
 {{ range .Encoders }}{{ if .Default }}{{/*
 */}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
@@ -776,8 +1645,9 @@ func initService(service *goa.Service) {
 func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Controller) {
 	initService(service)
 	var h goa.Handler
-{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
-*/}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
+{{ if .Events }}	emitter, _ := ctrl.({{ .Resource }}EventEmitter)
+{{ end }}{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
+*/}}	{{ template "handle" $ }}"OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
 {{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
@@ -789,18 +1659,42 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 		if err != nil {
 			return err
 		}
-{{ if .Payload }}		// Build the payload
+{{ with .BatchOf }}		// Handle each item like the requests of the {{ .Name }} action
+		items, ok := goa.ContextRequest(ctx).Payload.([]{{ .Item }})
+		if !ok {
+			return goa.MissingPayloadError()
+		}
+		results := make({{ .Results }}, len(items))
+		for i, item := range items {
+			status, body := goa.BatchItem(ctx, req, {{ if .Cacheable }}service.ReplayIdempotentItem(i, {{ end }}func(ctx context.Context) error {
+				if item == nil {
+					return goa.MissingPayloadError()
+				}
+{{ if .Validate }}				if err := item.Validate(); err != nil {
+					return goa.ErrBadRequest(err)
+				}
+{{ end }}				rctx, err := New{{ .Context }}(ctx, req, service)
+				if err != nil {
+					return err
+				}
+				rctx.Payload = item.Publicize()
+{{ template "invoke" . }}			}{{ if .Cacheable }}){{ end }})
+			results[i] = &{{ .Result }}{Status: status, Body: body}
+		}
+		return rctx.OK(results)
+{{ else }}{{ if .Payload }}		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.({{ gotyperef .Payload nil 1 false }})
 {{ if not .PayloadOptional }}		} else {
 			return goa.MissingPayloadError()
 {{ end }}		}
-{{ end }}		return ctrl.{{ .Name }}(rctx)
-	}
+{{ end }}{{ template "invoke" . }}{{ end }}	}
 {{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
-	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
+{{ end }}{{ if or $.ErrorMedia $.ProblemDetails $.Default }}	h = handle{{ $res }}Errors(service, h)
+{{ end }}{{ if .Compress }}	h = goa.Compress(h, {{ .CompressThreshold }}{{ range .Compress }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ range .Routes }}	{{ template "handle" $ }}"{{ .Verb }}", {{ printf "%q" .FullPath }}, {{ if or $action.RequireLength $action.ContentTypes }}service.CheckRequestBody({{ $action.RequireLength }}, {{ $action.MaxBodySize }}, {{ if $action.ContentTypes }}{{ printf "%#v" $action.ContentTypes }}{{ else }}nil{{ end }}, {{ end }}{{ if $action.DedupeWindow }}service.Dedupe(time.Duration({{ $action.DedupeWindow.Nanoseconds }}), {{ else if $action.Cacheable }}service.ReplayIdempotent({{ end }}ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}){{ if or $action.DedupeWindow $action.Cacheable }}){{ end }}{{ if or $action.RequireLength $action.ContentTypes }}){{ end }})
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $.Version }}, "version", {{ printf "%q" . }}{{ end }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
 {{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
@@ -808,6 +1702,80 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}}
+{{ define "handle" }}{{ if .VersionMedia }}service.HandleVersion({{ printf "%q" .VersionMedia }}, {{ printf "%q" .Version }}, {{ else }}service.Mux.Handle({{ end }}{{ end }}{{/*
+*/}}{{ define "invoke" }}{{ $in := "" }}{{ if .Item }}{{/* batch items are handled in a nested function */}}{{ $in = "\t\t" }}{{ end }}{{/*
+*/}}{{ if .Events }}{{ $in }}		if err := ctrl.{{ .Name }}(rctx); err != nil {
+{{ $in }}			return err
+{{ $in }}		}
+{{ $in }}		if emitter == nil || rctx.ResponseData.Status >= 400 {
+{{ $in }}			return nil
+{{ $in }}		}
+{{ range .Events }}{{ $event := goify .Name true }}{{ $in }}		if rctx.{{ $event }}Event != nil {
+{{ $in }}			if err := emitter.Emit{{ $event }}(rctx, rctx.{{ $event }}Event); err != nil {
+{{ $in }}				goa.LogError(rctx, "failed to emit event", "event", {{ printf "%q" .Name }}, "err", err)
+{{ $in }}			}
+{{ $in }}		}
+{{ end }}{{ $in }}		return nil
+{{ else }}{{ $in }}		return ctrl.{{ .Name }}(rctx)
+{{ end }}{{ end }}`
+
+	// handleErrorsT generates the code that renders the errors returned by the resource
+	// handlers using the resource default error response media type or problem details and
+	// the unmapped errors and panics using the default response.
+	// template input: map[string]interface{}
+	handleErrorsT = `// handle{{ .Resource }}Errors renders the errors returned by the {{ .Resource }} handlers{{ if .ContentType }} using the
+// {{ .ContentType }} media type{{ end }}.{{ with .Default }}
+// Errors that are not goa.ServiceError values and panics are rendered using the
+// {{ .ContentType }} media type with status {{ .Status }}.{{ end }}
+func handle{{ .Resource }}Errors(service *goa.Service, h goa.Handler) goa.Handler {
+{{- if .Default }}{{ $builder := .Builder }}{{ $ct := .ContentType }}{{ with .Default }}
+	sendDefault := func(ctx context.Context, rw http.ResponseWriter, err error) error {
+		e := goa.NewErrorClass("internal", {{ .Status }})(http.StatusText({{ .Status }}))
+		goa.LogError(ctx, "uncaught error", "err", fmt.Sprintf("%+v", err), "id", e.(*goa.ErrorResponse).ID)
+		rw.Header().Set("Content-Type", "{{ .ContentType }}")
+		return service.Send(ctx, {{ .Status }}, {{ .Builder }}(e))
+	}
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = sendDefault(ctx, rw, fmt.Errorf("panic: %v", r))
+			}
+		}()
+		err = h(ctx, rw, req)
+		if err == nil {
+			return nil
+		}
+{{- if $ct }}
+		se, ok := err.(goa.ServiceError)
+		if !ok {
+			return sendDefault(ctx, rw, err)
+		}
+		rw.Header().Set("Content-Type", "{{ $ct }}")
+		return service.Send(ctx, se.ResponseStatus(), {{ $builder }}(err))
+{{- else }}
+		if _, ok := err.(goa.ServiceError); !ok {
+			return sendDefault(ctx, rw, err)
+		}
+		return err
+{{- end }}
+	}
+}
+{{ end }}{{ else }}
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		err := h(ctx, rw, req)
+		if err == nil {
+			return nil
+		}
+		se, ok := err.(goa.ServiceError)
+		if !ok {
+			// Let the error handler middleware deal with internal errors.
+			return err
+		}
+		rw.Header().Set("Content-Type", "{{ .ContentType }}")
+		return service.Send(ctx, se.ResponseStatus(), {{ .Builder }}(err))
+	}
+}
+{{ end }}
 `
 
 	// handleCORST generates the code that checks whether a CORS request is authorized
@@ -825,7 +1793,7 @@ func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
 {{ range $i, $policy := .Origins }}		{{ if $policy.Regexp }}if cors.MatchOriginRegexp(origin, spec{{$i}}){{else}}if cors.MatchOrigin(origin, {{ printf "%q" $policy.Origin }}){{end}} {
 			ctx = goa.WithLogContext(ctx, "origin", origin)
 			rw.Header().Set("Access-Control-Allow-Origin", origin)
-{{ if not (eq $policy.Origin "*") }}			rw.Header().Set("Vary", "Origin")
+{{ if not (eq $policy.Origin "*") }}			rw.Header().Add("Vary", "Origin")
 {{ end }}{{ if $policy.Exposed }}			rw.Header().Set("Access-Control-Expose-Headers", "{{ join $policy.Exposed ", " }}")
 {{ end }}{{ if gt $policy.MaxAge 0 }}			rw.Header().Set("Access-Control-Max-Age", "{{ $policy.MaxAge }}")
 {{ end }}			rw.Header().Set("Access-Control-Allow-Credentials", "{{ $policy.Credentials }}")
@@ -837,6 +1805,8 @@ func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
 			return h(ctx, rw, req)
 		}
 {{ end }}
+		// The origin is not allowed, the response does not include the CORS headers.
+		rw.Header().Add("Vary", "Origin")
 		return h(ctx, rw, req)
 	}
 }
@@ -847,7 +1817,30 @@ func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
 	unmarshalT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `{{ range .Actions }}{{ if .Payload }}
 // {{ .Unmarshal }} unmarshals the request body into the context request data Payload field.
 func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Request) error {
-	{{ if .PayloadMultipart}}var err error
+	{{ if .MaxBodySize }}goa.LimitRequestBody(ctx, req, {{ .MaxBodySize }})
+	{{ end }}{{ if .SignatureHeader }}if err := service.VerifySignature(ctx, req, {{ printf "%q" .SignatureHeader }}); err != nil {
+		return err
+	}
+	{{ end }}{{ $envelope := .Envelope }}{{ with .BatchOf }}var payload []{{ .Item }}
+	{{ if $envelope }}env := {{ envelope $envelope "&payload" "" }}
+	if err := service.DecodeRequest(req, env); err != nil {
+		return err
+	}
+	goa.ContextRequest(ctx).Meta = env.Meta{{ else }}if err := service.DecodeRequest(req, &payload); err != nil {
+		return err
+	}{{ end }}{{ if or .Finalize .VersionHidden }}
+	for _, item := range payload {
+		if item == nil {
+			continue
+		}{{ range .VersionHidden }}
+		item.{{ .Field }} = {{ .Zero }}{{ end }}{{ if .Finalize }}
+		item.Finalize(){{ end }}
+	}{{ end }}
+	// The items are validated individually so that invalid items do not fail the batch
+	goa.ContextRequest(ctx).Payload = payload
+	return nil
+}
+{{ else }}{{ if .PayloadMultipart}}var err error
 	var payload {{ gotypename .Payload nil 1 true }}
 {{ $o := .Payload.ToObject }}{{ range $name, $att := $o -}}
 	{{ if eq $att.Type.Kind 13 }}	_, raw{{ goify $name true }}, err2 := req.FormFile("{{ $name }}"){{ else if eq $att.Type.Kind 8 }}{{/*
@@ -857,22 +1850,31 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 */}}	if err != nil {
 		return err
 	}{{ else if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
-	if err := service.DecodeRequest(req, payload); err != nil {
+	{{ if .Envelope }}env := {{ envelope .Envelope "payload" "" }}
+	if err := service.DecodeRequest(req, env); err != nil {
+		return err
+	}
+	goa.ContextRequest(ctx).Meta = env.Meta{{ else }}if err := service.DecodeRequest(req, payload); err != nil {
 		return err
-	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
+	}{{ end }}{{ range .VersionHidden }}
+	payload.{{ .Field }} = {{ .Zero }}{{ end }}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
 	payload.Finalize(){{ end }}{{ else }}var payload {{ gotypename .Payload nil 1 false }}
-	if err := service.DecodeRequest(req, &payload); err != nil {
+	{{ if .Envelope }}env := {{ envelope .Envelope "&payload" "" }}
+	if err := service.DecodeRequest(req, env); err != nil {
 		return err
-	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 true }}{{ if $validation }}
+	}
+	goa.ContextRequest(ctx).Meta = env.Meta{{ else }}if err := service.DecodeRequest(req, &payload); err != nil {
+		return err
+	}{{ end }}{{ end }}{{ if not .SkipValidation }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 true }}{{ if $validation }}
 	if err := payload.Validate(); err != nil {
 		// Initialize payload with private data structure so it can be logged
 		goa.ContextRequest(ctx).Payload = payload
 		return err
-	}{{ end }}
+	}{{ end }}{{ end }}
 	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
 	return nil
 }
-{{ end }}
+{{ end }}{{ end }}
 {{ end }}`
 
 	// resourceT generates the code for a resource.
@@ -891,13 +1893,39 @@ func {{ .Name }}Href({{ if .CanonicalParams }}{{ join .CanonicalParams ", " }} i
 //
 // Identifier: {{ .Identifier }}{{ $typeName := gotypename . .AllRequired 0 false }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-
+{{ jsonMarshaler .AttributeDefinition $typeName false }}{{ computeFields .AttributeDefinition $typeName }}
 {{ $validation := validationCode .AttributeDefinition false false false "mt" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} media type instance.
 func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }
 {{ end }}
+`
+
+	// errorBuilderT generates the function that builds error response media types from errors.
+	// template input: map[string]interface{}
+	errorBuilderT = `// {{ .Name }} builds a {{ gotypename .MediaType nil 0 false }} error response from the given error.
+// Errors that are not goa.ErrorResponse values are rendered as internal errors.
+func {{ .Name }}(err error) {{ gotyperef .MediaType .MediaType.AllRequired 0 false }} {
+	e, ok := err.(*goa.ErrorResponse)
+	if !ok {
+		e = goa.ErrInternal(err).(*goa.ErrorResponse)
+	}
+	return &{{ gotypename .MediaType nil 0 false }}{
+{{ range .Fields }}		{{ .Name }}: {{ if .Pointer }}&{{ end }}{{ .Source }},
+{{ end }}	}
+}
+
+`
+
+	// problemDetailsBuilderT generates the function that builds problem details from errors.
+	// template input: map[string]interface{}
+	problemDetailsBuilderT = `// {{ .Name }} builds a problem details (RFC 7807) error response from the given error.
+// Errors that are not goa.ErrorResponse values are rendered as internal errors.
+func {{ .Name }}(err error) *goa.ProblemDetails {
+	return goa.NewProblemDetails(err, {{ printf "%q" .TypeBase }})
+}
+
 `
 
 	// mediaTypeLinkT generates the code for a media type link.
@@ -915,7 +1943,7 @@ func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 	// template input: UserTypeTemplateData
 	userTypeT = `// {{ gotypedesc . false }}{{ $privateTypeName := gotypename . .AllRequired 0 true }}
 type {{ $privateTypeName }} {{ gotypedef . 0 true true }}
-{{ $assignment := finalizeCode .AttributeDefinition "ut" 1 }}{{ if $assignment }}// Finalize sets the default values for {{$privateTypeName}} type instance.
+{{ jsonMarshaler .AttributeDefinition $privateTypeName true }}{{ $assignment := finalizeCode .AttributeDefinition "ut" 1 }}{{ if $assignment }}// Finalize sets the default values for {{$privateTypeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 true }}) Finalize() {
 {{ $assignment }}
 }{{ end }}
@@ -934,11 +1962,146 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllR
 
 // {{ gotypedesc . true }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ jsonMarshaler .AttributeDefinition $typeName false }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
+`
+
+	// enumT generates the Go type of an integer enum with named values.
+	// template input: map[string]interface{}
+	enumT = `// {{ .TypeName }} is an integer enum with named values.
+type {{ .TypeName }} int
+
+// {{ .TypeName }} values.
+const (
+{{ range .Values }}	{{ .Const }} {{ $.TypeName }} = {{ .Value }}
+{{ end }})
+
+// String returns the name of the value or its number if the value is unknown.
+func (e {{ .TypeName }}) String() string {
+	switch e {
+{{ range .Values }}	case {{ .Const }}:
+		return {{ printf "%q" .Name }}
+{{ end }}	}
+	return strconv.Itoa(int(e))
+}
+
+// Parse{{ .TypeName }} returns the {{ .TypeName }} value with the given name or number.
+func Parse{{ .TypeName }}(s string) ({{ .TypeName }}, error) {
+	switch s {
+{{ range .Values }}	case {{ printf "%q" .Name }}, {{ printf "%q" (print .Value) }}:
+		return {{ .Const }}, nil
+{{ end }}	}
+	return 0, fmt.Errorf("invalid {{ .TypeName }} value %q", s)
+}
+
+// MarshalJSON encodes the value using its {{ if .EncodeNames }}name{{ else }}number{{ end }}.
+func (e {{ .TypeName }}) MarshalJSON() ([]byte, error) {
+{{ if .EncodeNames }}	return json.Marshal(e.String())
+{{ else }}	return json.Marshal(int(e))
+{{ end }}}
+
+// UnmarshalJSON decodes the value from either its name or its number.
+func (e *{{ .TypeName }}) UnmarshalJSON(b []byte) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		*e = {{ .TypeName }}(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := Parse{{ .TypeName }}(s)
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+`
+
+	// flagSetT generates the Go type of a flag set.
+	// template input: map[string]interface{}
+	flagSetT = `// {{ .TypeName }} is a set of flags encoded as a comma separated list of flag names.
+type {{ .TypeName }} int
+
+// {{ .TypeName }} flags.
+const (
+{{ range .Flags }}	{{ .Const }} {{ $.TypeName }} = {{ .Value }}
+{{ end }})
+
+// Has returns true if all the given flags are set.
+func (f {{ .TypeName }}) Has(flags {{ .TypeName }}) bool {
+	return f&flags == flags
+}
+
+// String returns the comma separated list of the names of the flags that are set.
+func (f {{ .TypeName }}) String() string {
+	var names []string
+{{ range .Flags }}	if f&{{ .Const }} != 0 {
+		names = append(names, {{ printf "%q" .Name }})
+	}
+{{ end }}	return strings.Join(names, ",")
+}
+
+// Parse{{ .TypeName }} returns the {{ .TypeName }} value with the flags named in the given comma
+// separated list.
+func Parse{{ .TypeName }}(s string) ({{ .TypeName }}, error) {
+	var f {{ .TypeName }}
+	if s == "" {
+		return f, nil
+	}
+	for _, n := range strings.Split(s, ",") {
+		switch strings.TrimSpace(n) {
+{{ range .Flags }}		case {{ printf "%q" .Name }}:
+			f |= {{ .Const }}
+{{ end }}		default:
+			return 0, fmt.Errorf("invalid {{ .TypeName }} flag %q", n)
+		}
+	}
+	return f, nil
+}
+
+// MarshalJSON encodes the value as the comma separated list of the names of the flags that are set.
+func (f {{ .TypeName }}) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+// UnmarshalJSON decodes the value from a comma separated list of flag names.
+func (f *{{ .TypeName }}) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := Parse{{ .TypeName }}(s)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+`
+
+	// requestIDT generates the request ID middleware and context accessor.
+	// template input: string
+	requestIDT = `// RequestIDHeader is the name of the header that carries the request ID.
+const RequestIDHeader = {{ printf "%q" . }}
+
+// RequestID returns the middleware that reads the request ID from the {{ . }} header or generates
+// one if missing, stores it in the request context and sets it in the {{ . }} header of the
+// response. Use RequestIDFromContext to retrieve the request ID.
+func RequestID() goa.Middleware {
+	return middleware.RequestIDWithResponseHeader(RequestIDHeader)
+}
+
+// RequestIDFromContext returns the request ID stored in the context by the RequestID middleware,
+// the empty string if there isn't one.
+func RequestIDFromContext(ctx context.Context) string {
+	return middleware.ContextRequestID(ctx)
+}
 `
 
 	// securitySchemesT generates the code for the security module.
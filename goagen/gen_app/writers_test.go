@@ -3,6 +3,7 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/design/apidsl"
@@ -54,30 +55,71 @@ var _ = Describe("ContextsWriter", func() {
 			var payload *design.UserTypeDefinition
 			var responses map[string]*design.ResponseDefinition
 			var routes []*design.RouteDefinition
+			var surrogateKeys []string
+			var respondFrom []string
+			var sparseFields bool
+			var echoUpdated bool
+			var streamJSON bool
+			var maxPageSize int
+			var deprecated bool
+			var sunset string
+			var version string
+			var sortFields, filterFields []string
+			var sortDefaults []*design.SortFieldDefinition
+			var events []*design.EventDefinition
+			var envelope *design.EnvelopeDefinition
 
 			var data *genapp.ContextTemplateData
 
 			BeforeEach(func() {
+				events = nil
+				envelope = nil
 				params = nil
 				headers = nil
 				payload = nil
 				responses = nil
 				routes = nil
+				surrogateKeys = nil
+				respondFrom = nil
+				sparseFields = false
+				echoUpdated = false
+				streamJSON = false
+				maxPageSize = 0
+				deprecated = false
+				sunset = ""
+				version = ""
+				sortFields = nil
+				filterFields = nil
+				sortDefaults = nil
 				data = nil
 			})
 
 			JustBeforeEach(func() {
 				data = &genapp.ContextTemplateData{
-					Name:         "ListBottleContext",
-					ResourceName: "bottles",
-					ActionName:   "list",
-					Params:       params,
-					Payload:      payload,
-					Headers:      headers,
-					Responses:    responses,
-					Routes:       routes,
-					API:          design.Design,
-					DefaultPkg:   "",
+					Name:          "ListBottleContext",
+					ResourceName:  "bottles",
+					ActionName:    "list",
+					Params:        params,
+					Payload:       payload,
+					Headers:       headers,
+					Responses:     responses,
+					Routes:        routes,
+					API:           design.Design,
+					DefaultPkg:    "",
+					SurrogateKeys: surrogateKeys,
+					RespondFrom:   respondFrom,
+					SparseFields:  sparseFields,
+					EchoUpdated:   echoUpdated,
+					StreamJSON:    streamJSON,
+					MaxPageSize:   maxPageSize,
+					Deprecated:    deprecated,
+					Sunset:        sunset,
+					Version:       version,
+					SortFields:    sortFields,
+					SortDefaults:  sortDefaults,
+					FilterFields:  filterFields,
+					Events:        events,
+					Envelope:      envelope,
 				}
 			})
 
@@ -94,6 +136,29 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with emitted events", func() {
+				BeforeEach(func() {
+					events = []*design.EventDefinition{{
+						Name: "bottle_listed",
+						Type: &design.UserTypeDefinition{
+							TypeName: "BottleListed",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"count": {Type: design.Integer}},
+							},
+						},
+					}}
+				})
+
+				It("writes a context field for each event", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("	BottleListedEvent *BottleListed\n"))
+				})
+			})
+
 			Context("with a media type setting a ContentType", func() {
 				var contentType = "application/json"
 
@@ -136,439 +201,686 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
-			Context("with a collection media type", func() {
+			Context("with surrogate keys", func() {
 				BeforeEach(func() {
-					elemType := &design.MediaTypeDefinition{
+					mediaType := &design.MediaTypeDefinition{
 						UserTypeDefinition: &design.UserTypeDefinition{
 							AttributeDefinition: &design.AttributeDefinition{
-								Type: design.Object{"foo": {Type: design.String}},
+								Type: design.Object{
+									"id":      {Type: design.Integer},
+									"account": {Type: design.String},
+									"name":    {Type: design.String},
+								},
 							},
+							TypeName: "Bottle",
 						},
-						Identifier: "application/vnd.goa.test",
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
 					}
 					defView := &design.ViewDefinition{
-						AttributeDefinition: elemType.AttributeDefinition,
+						AttributeDefinition: mediaType.AttributeDefinition,
 						Name:                "default",
-						Parent:              elemType,
+						Parent:              mediaType,
 					}
-					elemType.Views = map[string]*design.ViewDefinition{"default": defView}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
 					design.Design = new(design.APIDefinition)
 					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
-						design.CanonicalIdentifier(elemType.Identifier): elemType,
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
 					}
 					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
-					mediaType := apidsl.CollectionOf(elemType)
-					dslengine.Execute(mediaType.DSL(), mediaType)
-					responses = map[string]*design.ResponseDefinition{"OK": {
-						Name:   "OK",
-						Status: 200,
-						Type:   mediaType,
-					}}
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
+						"Accepted": {
+							Name:      "Accepted",
+							Status:    202,
+							MediaType: mediaType.Identifier,
+						},
+					}
+					surrogateKeys = []string{"account", "id"}
 				})
 
-				It("the generated code sets the response to an empty collection if value is nil", func() {
+				It("the generated code sets the Surrogate-Key header", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(`	if r == nil {
-		r = Collection{}
-	}
-	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
+					Ω(written).Should(ContainSubstring(surrogateKeysResponse))
 				})
 			})
 
-			Context("with an integer param", func() {
-				var (
-					intParam   *design.AttributeDefinition
-					dataType   design.Object
-					validation *dslengine.ValidationDefinition
-				)
-
+			Context("with sparse fieldsets", func() {
 				BeforeEach(func() {
-					intParam = &design.AttributeDefinition{Type: design.Integer}
-					dataType = design.Object{
-						"param": intParam,
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":   {Type: design.Integer},
+									"name": {Type: design.String},
+								},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
 					}
-					validation = &dslengine.ValidationDefinition{}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
 					params = &design.AttributeDefinition{
-						Type:       dataType,
-						Validation: validation,
+						Type: design.Object{"fields": {Type: design.String}},
+					}
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
 					}
+					sparseFields = true
 				})
 
-				It("writes the integer contexts code", func() {
+				It("the generated code filters the response with the fields param", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(intContext))
-					Ω(written).Should(ContainSubstring(intContextFactory))
+					Ω(written).Should(ContainSubstring("	Fields *string\n"))
+					Ω(written).Should(ContainSubstring(sparseFieldsResponse))
 				})
+			})
 
-				Context("with a default value", func() {
-					BeforeEach(func() {
-						intParam.SetDefault(2)
-					})
-
-					It("writes the integer contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(intDefaultContext))
-						Ω(written).Should(ContainSubstring(intDefaultContextFactory))
-					})
+			Context("echoing the updated fields", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":         {Type: design.Integer},
+									"name":       {Type: design.String},
+									"created_at": {Type: design.DateTime},
+								},
+								Metadata: dslengine.MetadataDefinition{"json:naming": {"camel"}},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					name := &design.AttributeDefinition{Type: design.String}
+					name.SetNullable()
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"name":       name,
+								"created_at": {Type: design.DateTime},
+								"tags":       {Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+							},
+						},
+						TypeName: "ListBottlePayload",
+					}
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
+					}
+					echoUpdated = true
 				})
 
-				Context("with required attribute", func() {
-					BeforeEach(func() {
-						validation.Required = []string{"param"}
-					})
-
-					It("writes the integer contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(intRequiredContext))
-						Ω(written).Should(ContainSubstring(intRequiredContextFactory))
-					})
-
-					Context("with a default value", func() {
-						BeforeEach(func() {
-							intParam.SetDefault(2)
-						})
-
-						It("writes the integer contexts code", func() {
-							err := writer.Execute(data)
-							Ω(err).ShouldNot(HaveOccurred())
-							b, err := ioutil.ReadFile(filename)
-							Ω(err).ShouldNot(HaveOccurred())
-							written := string(b)
-							Ω(written).ShouldNot(BeEmpty())
-							Ω(written).Should(ContainSubstring(intRequiredDefaultContext))
-							Ω(written).Should(ContainSubstring(intRequiredDefaultContextFactory))
-						})
-					})
+				It("the generated code restricts the response to the fields set in the request", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(updatedFieldsCode))
+					Ω(written).Should(ContainSubstring(echoUpdatedResponse))
 				})
 			})
 
-			Context("with an string param", func() {
-				var (
-					strParam   *design.AttributeDefinition
-					dataType   design.Object
-					validation *dslengine.ValidationDefinition
-				)
-
+			Context("with a body envelope", func() {
 				BeforeEach(func() {
-					strParam = &design.AttributeDefinition{Type: design.String}
-					dataType = design.Object{
-						"param": strParam,
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": {Type: design.Integer},
+								},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
 					}
-					validation = &dslengine.ValidationDefinition{}
-					params = &design.AttributeDefinition{
-						Type:       dataType,
-						Validation: validation,
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
 					}
+					envelope = &design.EnvelopeDefinition{DataField: "data", MetaField: "meta"}
 				})
 
-				It("writes the string contexts code", func() {
+				It("the generated code wraps the response in the envelope", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(strContext))
-					Ω(written).Should(ContainSubstring(strContextFactory))
+					Ω(written).Should(ContainSubstring(envelopeResponse))
 				})
+			})
 
-				Context("with a default value", func() {
-					BeforeEach(func() {
-						strParam.SetDefault("foo")
-					})
+			Context("with attributes that require a scope", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"name": {Type: design.String},
+									"salary": {
+										Type:     design.Integer,
+										Metadata: dslengine.MetadataDefinition{"security:scope": {"admin"}},
+									},
+								},
+							},
+							TypeName: "Employee",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					params = &design.AttributeDefinition{
+						Type: design.Object{"fields": {Type: design.String}},
+					}
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
+					}
+					sparseFields = true
+				})
 
-					It("writes the string contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(strNonOptionalContext))
-						Ω(written).Should(ContainSubstring(strDefaultContextFactory))
-					})
+				It("the generated code omits the attributes the caller cannot see", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(scopedFieldsResponse))
 				})
+			})
 
-				Context("with required attribute", func() {
-					BeforeEach(func() {
-						validation.Required = []string{"param"}
-					})
-
-					It("writes the String contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(strNonOptionalContext))
-						Ω(written).Should(ContainSubstring(strRequiredContextFactory))
-					})
-
-					Context("with a default value", func() {
-						BeforeEach(func() {
-							strParam.SetDefault("foo")
-						})
-
-						It("writes the integer contexts code", func() {
-							err := writer.Execute(data)
-							Ω(err).ShouldNot(HaveOccurred())
-							b, err := ioutil.ReadFile(filename)
-							Ω(err).ShouldNot(HaveOccurred())
-							written := string(b)
-							Ω(written).ShouldNot(BeEmpty())
-							Ω(written).Should(ContainSubstring(strNonOptionalContext))
-							Ω(written).Should(ContainSubstring(strDefaultContextFactory))
-						})
-					})
-				})
-			})
-
-			Context("with a number param", func() {
-				var (
-					numParam   *design.AttributeDefinition
-					dataType   design.Object
-					validation *dslengine.ValidationDefinition
-				)
-
+			Context("with attributes limited to some versions", func() {
 				BeforeEach(func() {
-					numParam = &design.AttributeDefinition{Type: design.Number}
-					dataType = design.Object{
-						"param": numParam,
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"name": {Type: design.String},
+									"rating": {
+										Type:     design.Integer,
+										Metadata: dslengine.MetadataDefinition{"version:since": {"v2"}},
+									},
+								},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
 					}
-					validation = &dslengine.ValidationDefinition{}
-					params = &design.AttributeDefinition{
-						Type:       dataType,
-						Validation: validation,
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
 					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
+					}
+					version = "v1"
 				})
 
-				It("writes the number contexts code", func() {
+				It("the generated code of an earlier version omits the attributes", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(numContext))
-					Ω(written).Should(ContainSubstring(numContextFactory))
-				})
-
-				Context("with a default value", func() {
-					BeforeEach(func() {
-						numParam.SetDefault(2.3)
-					})
-
-					It("writes the number contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(numNonOptionalContext))
-						Ω(written).Should(ContainSubstring(numDefaultContextFactory))
-					})
+					Ω(written).Should(ContainSubstring(versionHiddenResponse))
 				})
 
-				Context("with required attribute", func() {
+				Context("of a later version", func() {
 					BeforeEach(func() {
-						validation.Required = []string{"param"}
+						version = "v2"
 					})
 
-					It("writes the number contexts code", func() {
+					It("the generated code includes the attributes", func() {
 						err := writer.Execute(data)
 						Ω(err).ShouldNot(HaveOccurred())
 						b, err := ioutil.ReadFile(filename)
 						Ω(err).ShouldNot(HaveOccurred())
 						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(numNonOptionalContext))
-						Ω(written).Should(ContainSubstring(numRequiredContextFactory))
-					})
-
-					Context("with a default value", func() {
-						BeforeEach(func() {
-							numParam.SetDefault(2.3)
-						})
-
-						It("writes the number contexts code", func() {
-							err := writer.Execute(data)
-							Ω(err).ShouldNot(HaveOccurred())
-							b, err := ioutil.ReadFile(filename)
-							Ω(err).ShouldNot(HaveOccurred())
-							written := string(b)
-							Ω(written).ShouldNot(BeEmpty())
-							Ω(written).Should(ContainSubstring(numNonOptionalContext))
-							Ω(written).Should(ContainSubstring(numDefaultContextFactory))
-						})
+						Ω(written).ShouldNot(ContainSubstring("OmitFields"))
+						Ω(written).Should(ContainSubstring("return ctx.ResponseData.Service.Send(ctx.Context, 200, r)"))
 					})
 				})
 			})
 
-			Context("with a boolean param", func() {
-				var (
-					boolParam  *design.AttributeDefinition
-					dataType   design.Object
-					validation *dslengine.ValidationDefinition
-				)
-
+			Context("with attributes that use an encode transform", func() {
 				BeforeEach(func() {
-					boolParam = &design.AttributeDefinition{Type: design.Boolean}
-					dataType = design.Object{
-						"param": boolParam,
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"name": {Type: design.String},
+									"phone": {
+										Type:     design.String,
+										Metadata: dslengine.MetadataDefinition{"encode:transform": {"e164"}},
+									},
+									"salary": {
+										Type:     design.Integer,
+										Metadata: dslengine.MetadataDefinition{"security:scope": {"admin"}},
+									},
+								},
+							},
+							TypeName: "Employee",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
 					}
-					validation = &dslengine.ValidationDefinition{}
-					params = &design.AttributeDefinition{
-						Type:       dataType,
-						Validation: validation,
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
 					}
 				})
 
-				It("writes the boolean contexts code", func() {
+				It("the generated code transforms the encoded values", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(boolContext))
-					Ω(written).Should(ContainSubstring(boolContextFactory))
-				})
-
-				Context("with a default value", func() {
-					BeforeEach(func() {
-						boolParam.SetDefault(true)
-					})
-
-					It("writes the boolean contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(boolNonOptionalContext))
-						Ω(written).Should(ContainSubstring(boolDefaultContextFactory))
-					})
-				})
-
-				Context("with required attribute", func() {
-					BeforeEach(func() {
-						validation.Required = []string{"param"}
-					})
-
-					It("writes the boolean contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(boolNonOptionalContext))
-						Ω(written).Should(ContainSubstring(boolRequiredContextFactory))
-					})
-
-					Context("with a default value", func() {
-						BeforeEach(func() {
-							boolParam.SetDefault(true)
-						})
-
-						It("writes the boolean contexts code", func() {
-							err := writer.Execute(data)
-							Ω(err).ShouldNot(HaveOccurred())
-							b, err := ioutil.ReadFile(filename)
-							Ω(err).ShouldNot(HaveOccurred())
-							written := string(b)
-							Ω(written).ShouldNot(BeEmpty())
-							Ω(written).Should(ContainSubstring(boolNonOptionalContext))
-							Ω(written).Should(ContainSubstring(boolDefaultContextFactory))
-						})
-					})
+					Ω(written).Should(ContainSubstring(encodeTransformsResponse))
 				})
 			})
 
-			Context("with a array param", func() {
-				var (
-					arrayParam *design.AttributeDefinition
-					dataType   design.Object
-					validation *dslengine.ValidationDefinition
-				)
-
+			Context("with computed attributes", func() {
 				BeforeEach(func() {
-					arrayParam = &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}}
-					dataType = design.Object{
-						"param": arrayParam,
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"first": {Type: design.String},
+									"last":  {Type: design.String},
+									"full_name": {
+										Type: design.String,
+										Metadata: dslengine.MetadataDefinition{
+											"compute:function": {"names.FullName", "example.com/names"},
+											"compute:args":     {"first", "last"},
+										},
+									},
+								},
+							},
+							TypeName: "Person",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
 					}
-					validation = &dslengine.ValidationDefinition{}
-					params = &design.AttributeDefinition{
-						Type:       dataType,
-						Validation: validation,
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
 					}
 				})
 
-				It("writes the array contexts code", func() {
+				It("the generated code computes the fields before encoding", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(arrayContext))
-					Ω(written).Should(ContainSubstring(arrayContextFactory))
+					Ω(written).Should(ContainSubstring(computedResponse))
 				})
+			})
 
-				Context("using a path param", func() {
-					BeforeEach(func() {
-						route := &design.RouteDefinition{Path: "/:param"}
-						routes = append(routes, route)
-					})
-
-					It("writes the array contexts code", func() {
-						err := writer.Execute(data)
-						Ω(err).ShouldNot(HaveOccurred())
-						b, err := ioutil.ReadFile(filename)
-						Ω(err).ShouldNot(HaveOccurred())
-						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(arrayParamContextFactory))
-					})
+			Context("with a response that sets a cookie", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"name":    {Type: design.String},
+									"session": {Type: design.String},
+								},
+							},
+							TypeName: "Login",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					ok := &design.ResponseDefinition{
+						Name:      "OK",
+						Status:    200,
+						MediaType: mediaType.Identifier,
+					}
+					ok.Cookies = []*design.CookieDefinition{{
+						Name:     "session",
+						MaxAge:   86400,
+						HTTPOnly: true,
+						Secure:   true,
+						SameSite: "Strict",
+						Parent:   ok,
+					}}
+					responses = map[string]*design.ResponseDefinition{"OK": ok}
+				})
+
+				It("the generated code sets the cookie and omits the attribute from the body", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(cookieResponse))
+				})
+			})
+
+			Context("with a streamed JSON collection", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"name": {Type: design.String},
+								},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": {
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}}
+					collection := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: &design.Array{ElemType: &design.AttributeDefinition{Type: mediaType}},
+							},
+							TypeName: "BottleCollection",
+						},
+						Identifier:  "application/vnd.goa.test; type=collection",
+						ContentType: "application/vnd.goa.test",
+					}
+					collection.Views = map[string]*design.ViewDefinition{"default": {
+						AttributeDefinition: collection.AttributeDefinition,
+						Name:                "default",
+						Parent:              collection,
+					}}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier):  mediaType,
+						design.CanonicalIdentifier(collection.Identifier): collection,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: collection.Identifier,
+						},
+					}
+					streamJSON = true
+				})
+
+				It("the generated code writes one element per line", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(streamJSONResponse))
+				})
+			})
+
+			Context("with a response selected from a boolean field", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":      {Type: design.Integer},
+									"created": {Type: design.Boolean},
+								},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier:  "application/vnd.goa.test",
+						ContentType: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
+						"Created": {
+							Name:      "Created",
+							Status:    201,
+							MediaType: mediaType.Identifier,
+						},
+					}
+					respondFrom = []string{"created", "Created", "OK"}
+				})
+
+				It("the generated code sends the response selected by the field value", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(respondFromField))
+				})
+			})
+
+			Context("with a collection media type", func() {
+				BeforeEach(func() {
+					elemType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"foo": {Type: design.String}},
+							},
+						},
+						Identifier: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: elemType.AttributeDefinition,
+						Name:                "default",
+						Parent:              elemType,
+					}
+					elemType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(elemType.Identifier): elemType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					mediaType := apidsl.CollectionOf(elemType)
+					dslengine.Execute(mediaType.DSL(), mediaType)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:   "OK",
+						Status: 200,
+						Type:   mediaType,
+					}}
+				})
+
+				It("the generated code sets the response to an empty collection if value is nil", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(`	if r == nil {
+		r = Collection{}
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
+				})
+			})
+
+			Context("with an integer param", func() {
+				var (
+					intParam   *design.AttributeDefinition
+					dataType   design.Object
+					validation *dslengine.ValidationDefinition
+				)
+
+				BeforeEach(func() {
+					intParam = &design.AttributeDefinition{Type: design.Integer}
+					dataType = design.Object{
+						"param": intParam,
+					}
+					validation = &dslengine.ValidationDefinition{}
+					params = &design.AttributeDefinition{
+						Type:       dataType,
+						Validation: validation,
+					}
+				})
+
+				It("writes the integer contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(intContext))
+					Ω(written).Should(ContainSubstring(intContextFactory))
 				})
 
 				Context("with a default value", func() {
 					BeforeEach(func() {
-						arrayParam.SetDefault([]interface{}{"foo", "bar", "baz"})
+						intParam.SetDefault(2)
 					})
 
-					It("writes the array contexts code", func() {
+					It("writes the integer contexts code", func() {
 						err := writer.Execute(data)
 						Ω(err).ShouldNot(HaveOccurred())
 						b, err := ioutil.ReadFile(filename)
 						Ω(err).ShouldNot(HaveOccurred())
 						written := string(b)
 						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(arrayContext))
-						Ω(written).Should(ContainSubstring(arrayDefaultContextFactory))
+						Ω(written).Should(ContainSubstring(intDefaultContext))
+						Ω(written).Should(ContainSubstring(intDefaultContextFactory))
 					})
 				})
 
@@ -577,47 +889,47 @@ var _ = Describe("ContextsWriter", func() {
 						validation.Required = []string{"param"}
 					})
 
-					It("writes the array contexts code", func() {
+					It("writes the integer contexts code", func() {
 						err := writer.Execute(data)
 						Ω(err).ShouldNot(HaveOccurred())
 						b, err := ioutil.ReadFile(filename)
 						Ω(err).ShouldNot(HaveOccurred())
 						written := string(b)
 						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(arrayContext))
-						Ω(written).Should(ContainSubstring(arrayRequiredContextFactory))
+						Ω(written).Should(ContainSubstring(intRequiredContext))
+						Ω(written).Should(ContainSubstring(intRequiredContextFactory))
 					})
 
 					Context("with a default value", func() {
 						BeforeEach(func() {
-							arrayParam.SetDefault([]interface{}{"foo", "bar", "baz"})
+							intParam.SetDefault(2)
 						})
 
-						It("writes the array contexts code", func() {
+						It("writes the integer contexts code", func() {
 							err := writer.Execute(data)
 							Ω(err).ShouldNot(HaveOccurred())
 							b, err := ioutil.ReadFile(filename)
 							Ω(err).ShouldNot(HaveOccurred())
 							written := string(b)
 							Ω(written).ShouldNot(BeEmpty())
-							Ω(written).Should(ContainSubstring(arrayContext))
-							Ω(written).Should(ContainSubstring(arrayDefaultContextFactory))
+							Ω(written).Should(ContainSubstring(intRequiredDefaultContext))
+							Ω(written).Should(ContainSubstring(intRequiredDefaultContextFactory))
 						})
 					})
 				})
 			})
 
-			Context("with an int array param", func() {
+			Context("with an string param", func() {
 				var (
-					arrayParam *design.AttributeDefinition
+					strParam   *design.AttributeDefinition
 					dataType   design.Object
 					validation *dslengine.ValidationDefinition
 				)
 
 				BeforeEach(func() {
-					arrayParam = &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}}}
+					strParam = &design.AttributeDefinition{Type: design.String}
 					dataType = design.Object{
-						"param": arrayParam,
+						"param": strParam,
 					}
 					validation = &dslengine.ValidationDefinition{}
 					params = &design.AttributeDefinition{
@@ -626,31 +938,31 @@ var _ = Describe("ContextsWriter", func() {
 					}
 				})
 
-				It("writes the array contexts code", func() {
+				It("writes the string contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(intArrayContext))
-					Ω(written).Should(ContainSubstring(intArrayContextFactory))
+					Ω(written).Should(ContainSubstring(strContext))
+					Ω(written).Should(ContainSubstring(strContextFactory))
 				})
 
 				Context("with a default value", func() {
 					BeforeEach(func() {
-						arrayParam.SetDefault([]interface{}{1, 1, 2, 3, 5, 8})
+						strParam.SetDefault("foo")
 					})
 
-					It("writes the array contexts code", func() {
+					It("writes the string contexts code", func() {
 						err := writer.Execute(data)
 						Ω(err).ShouldNot(HaveOccurred())
 						b, err := ioutil.ReadFile(filename)
 						Ω(err).ShouldNot(HaveOccurred())
 						written := string(b)
 						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(intArrayContext))
-						Ω(written).Should(ContainSubstring(intArrayDefaultContextFactory))
+						Ω(written).Should(ContainSubstring(strNonOptionalContext))
+						Ω(written).Should(ContainSubstring(strDefaultContextFactory))
 					})
 				})
 
@@ -659,149 +971,408 @@ var _ = Describe("ContextsWriter", func() {
 						validation.Required = []string{"param"}
 					})
 
-					It("writes the array contexts code", func() {
+					It("writes the String contexts code", func() {
 						err := writer.Execute(data)
 						Ω(err).ShouldNot(HaveOccurred())
 						b, err := ioutil.ReadFile(filename)
 						Ω(err).ShouldNot(HaveOccurred())
 						written := string(b)
 						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(intArrayContext))
-						Ω(written).Should(ContainSubstring(intArrayRequiredContextFactory))
+						Ω(written).Should(ContainSubstring(strNonOptionalContext))
+						Ω(written).Should(ContainSubstring(strRequiredContextFactory))
 					})
 
 					Context("with a default value", func() {
 						BeforeEach(func() {
-							arrayParam.SetDefault([]interface{}{1, 1, 2, 3, 5, 8})
+							strParam.SetDefault("foo")
 						})
 
-						It("writes the array contexts code", func() {
+						It("writes the integer contexts code", func() {
 							err := writer.Execute(data)
 							Ω(err).ShouldNot(HaveOccurred())
 							b, err := ioutil.ReadFile(filename)
 							Ω(err).ShouldNot(HaveOccurred())
 							written := string(b)
 							Ω(written).ShouldNot(BeEmpty())
-							Ω(written).Should(ContainSubstring(intArrayContext))
-							Ω(written).Should(ContainSubstring(intArrayDefaultContextFactory))
+							Ω(written).Should(ContainSubstring(strNonOptionalContext))
+							Ω(written).Should(ContainSubstring(strDefaultContextFactory))
 						})
 					})
 				})
 			})
 
-			Context("with an param using a reserved keyword as name", func() {
+			Context("with a number param", func() {
+				var (
+					numParam   *design.AttributeDefinition
+					dataType   design.Object
+					validation *dslengine.ValidationDefinition
+				)
+
 				BeforeEach(func() {
-					intParam := &design.AttributeDefinition{Type: design.Integer}
-					dataType := design.Object{
-						"int": intParam,
+					numParam = &design.AttributeDefinition{Type: design.Number}
+					dataType = design.Object{
+						"param": numParam,
 					}
+					validation = &dslengine.ValidationDefinition{}
 					params = &design.AttributeDefinition{
-						Type: dataType,
+						Type:       dataType,
+						Validation: validation,
 					}
 				})
 
-				It("writes the contexts code", func() {
+				It("writes the number contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(resContext))
-					Ω(written).Should(ContainSubstring(resContextFactory))
+					Ω(written).Should(ContainSubstring(numContext))
+					Ω(written).Should(ContainSubstring(numContextFactory))
+				})
+
+				Context("with a default value", func() {
+					BeforeEach(func() {
+						numParam.SetDefault(2.3)
+					})
+
+					It("writes the number contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(numNonOptionalContext))
+						Ω(written).Should(ContainSubstring(numDefaultContextFactory))
+					})
+				})
+
+				Context("with required attribute", func() {
+					BeforeEach(func() {
+						validation.Required = []string{"param"}
+					})
+
+					It("writes the number contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(numNonOptionalContext))
+						Ω(written).Should(ContainSubstring(numRequiredContextFactory))
+					})
+
+					Context("with a default value", func() {
+						BeforeEach(func() {
+							numParam.SetDefault(2.3)
+						})
+
+						It("writes the number contexts code", func() {
+							err := writer.Execute(data)
+							Ω(err).ShouldNot(HaveOccurred())
+							b, err := ioutil.ReadFile(filename)
+							Ω(err).ShouldNot(HaveOccurred())
+							written := string(b)
+							Ω(written).ShouldNot(BeEmpty())
+							Ω(written).Should(ContainSubstring(numNonOptionalContext))
+							Ω(written).Should(ContainSubstring(numDefaultContextFactory))
+						})
+					})
 				})
 			})
 
-			Context("with a required param", func() {
+			Context("with a boolean param", func() {
+				var (
+					boolParam  *design.AttributeDefinition
+					dataType   design.Object
+					validation *dslengine.ValidationDefinition
+				)
+
 				BeforeEach(func() {
-					intParam := &design.AttributeDefinition{Type: design.Integer}
-					dataType := design.Object{
-						"int": intParam,
-					}
-					required := &dslengine.ValidationDefinition{
-						Required: []string{"int"},
+					boolParam = &design.AttributeDefinition{Type: design.Boolean}
+					dataType = design.Object{
+						"param": boolParam,
 					}
+					validation = &dslengine.ValidationDefinition{}
 					params = &design.AttributeDefinition{
 						Type:       dataType,
-						Validation: required,
+						Validation: validation,
 					}
 				})
 
-				It("writes the contexts code", func() {
+				It("writes the boolean contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(requiredContext))
-					Ω(written).Should(ContainSubstring(requiredContextFactory))
+					Ω(written).Should(ContainSubstring(boolContext))
+					Ω(written).Should(ContainSubstring(boolContextFactory))
+				})
+
+				Context("with a default value", func() {
+					BeforeEach(func() {
+						boolParam.SetDefault(true)
+					})
+
+					It("writes the boolean contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(boolNonOptionalContext))
+						Ω(written).Should(ContainSubstring(boolDefaultContextFactory))
+					})
+				})
+
+				Context("with required attribute", func() {
+					BeforeEach(func() {
+						validation.Required = []string{"param"}
+					})
+
+					It("writes the boolean contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(boolNonOptionalContext))
+						Ω(written).Should(ContainSubstring(boolRequiredContextFactory))
+					})
+
+					Context("with a default value", func() {
+						BeforeEach(func() {
+							boolParam.SetDefault(true)
+						})
+
+						It("writes the boolean contexts code", func() {
+							err := writer.Execute(data)
+							Ω(err).ShouldNot(HaveOccurred())
+							b, err := ioutil.ReadFile(filename)
+							Ω(err).ShouldNot(HaveOccurred())
+							written := string(b)
+							Ω(written).ShouldNot(BeEmpty())
+							Ω(written).Should(ContainSubstring(boolNonOptionalContext))
+							Ω(written).Should(ContainSubstring(boolDefaultContextFactory))
+						})
+					})
 				})
 			})
 
-			Context("with a custom name param", func() {
+			Context("with a array param", func() {
+				var (
+					arrayParam *design.AttributeDefinition
+					dataType   design.Object
+					validation *dslengine.ValidationDefinition
+				)
+
 				BeforeEach(func() {
-					intParam := &design.AttributeDefinition{
-						Type: design.Integer,
-						Metadata: dslengine.MetadataDefinition{
-							"struct:field:name": []string{"custom"},
-						},
-					}
-					dataType := design.Object{
-						"int": intParam,
+					arrayParam = &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}}
+					dataType = design.Object{
+						"param": arrayParam,
 					}
+					validation = &dslengine.ValidationDefinition{}
 					params = &design.AttributeDefinition{
-						Type: dataType,
+						Type:       dataType,
+						Validation: validation,
 					}
 				})
 
-				It("writes the contexts code", func() {
+				It("writes the array contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(customContext))
-					Ω(written).Should(ContainSubstring(customContextFactory))
+					Ω(written).Should(ContainSubstring(arrayContext))
+					Ω(written).Should(ContainSubstring(arrayContextFactory))
+				})
+
+				Context("using a path param", func() {
+					BeforeEach(func() {
+						route := &design.RouteDefinition{Path: "/:param"}
+						routes = append(routes, route)
+					})
+
+					It("writes the array contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(arrayParamContextFactory))
+					})
+				})
+
+				Context("with a default value", func() {
+					BeforeEach(func() {
+						arrayParam.SetDefault([]interface{}{"foo", "bar", "baz"})
+					})
+
+					It("writes the array contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(arrayContext))
+						Ω(written).Should(ContainSubstring(arrayDefaultContextFactory))
+					})
+				})
+
+				Context("with required attribute", func() {
+					BeforeEach(func() {
+						validation.Required = []string{"param"}
+					})
+
+					It("writes the array contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(arrayContext))
+						Ω(written).Should(ContainSubstring(arrayRequiredContextFactory))
+					})
+
+					Context("with a default value", func() {
+						BeforeEach(func() {
+							arrayParam.SetDefault([]interface{}{"foo", "bar", "baz"})
+						})
+
+						It("writes the array contexts code", func() {
+							err := writer.Execute(data)
+							Ω(err).ShouldNot(HaveOccurred())
+							b, err := ioutil.ReadFile(filename)
+							Ω(err).ShouldNot(HaveOccurred())
+							written := string(b)
+							Ω(written).ShouldNot(BeEmpty())
+							Ω(written).Should(ContainSubstring(arrayContext))
+							Ω(written).Should(ContainSubstring(arrayDefaultContextFactory))
+						})
+					})
 				})
 			})
 
-			Context("with a string header", func() {
+			Context("with an int array param", func() {
+				var (
+					arrayParam *design.AttributeDefinition
+					dataType   design.Object
+					validation *dslengine.ValidationDefinition
+				)
+
 				BeforeEach(func() {
-					strHeader := &design.AttributeDefinition{Type: design.String}
-					dataType := design.Object{
-						"Header": strHeader,
+					arrayParam = &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}}}
+					dataType = design.Object{
+						"param": arrayParam,
 					}
-					headers = &design.AttributeDefinition{
-						Type: dataType,
+					validation = &dslengine.ValidationDefinition{}
+					params = &design.AttributeDefinition{
+						Type:       dataType,
+						Validation: validation,
 					}
 				})
 
-				It("writes the contexts code", func() {
+				It("writes the array contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(strHeaderContext))
-					Ω(written).Should(ContainSubstring(strHeaderContextFactory))
+					Ω(written).Should(ContainSubstring(intArrayContext))
+					Ω(written).Should(ContainSubstring(intArrayContextFactory))
+				})
+
+				Context("with items validations", func() {
+					BeforeEach(func() {
+						max := 5
+						arrayParam.Validation = &dslengine.ValidationDefinition{MaxItems: &max, UniqueItems: true}
+					})
+
+					It("writes the array contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(intArrayContext))
+						Ω(written).Should(ContainSubstring(intArrayItemsContextFactory))
+					})
+				})
+
+				Context("with a default value", func() {
+					BeforeEach(func() {
+						arrayParam.SetDefault([]interface{}{1, 1, 2, 3, 5, 8})
+					})
+
+					It("writes the array contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(intArrayContext))
+						Ω(written).Should(ContainSubstring(intArrayDefaultContextFactory))
+					})
+				})
+
+				Context("with required attribute", func() {
+					BeforeEach(func() {
+						validation.Required = []string{"param"}
+					})
+
+					It("writes the array contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(intArrayContext))
+						Ω(written).Should(ContainSubstring(intArrayRequiredContextFactory))
+					})
+
+					Context("with a default value", func() {
+						BeforeEach(func() {
+							arrayParam.SetDefault([]interface{}{1, 1, 2, 3, 5, 8})
+						})
+
+						It("writes the array contexts code", func() {
+							err := writer.Execute(data)
+							Ω(err).ShouldNot(HaveOccurred())
+							b, err := ioutil.ReadFile(filename)
+							Ω(err).ShouldNot(HaveOccurred())
+							written := string(b)
+							Ω(written).ShouldNot(BeEmpty())
+							Ω(written).Should(ContainSubstring(intArrayContext))
+							Ω(written).Should(ContainSubstring(intArrayDefaultContextFactory))
+						})
+					})
 				})
 			})
 
-			Context("with a string header and param with the same name", func() {
+			Context("with an param using a reserved keyword as name", func() {
 				BeforeEach(func() {
-					str := &design.AttributeDefinition{Type: design.String}
+					intParam := &design.AttributeDefinition{Type: design.Integer}
 					dataType := design.Object{
-						"param": str,
+						"int": intParam,
 					}
 					params = &design.AttributeDefinition{
 						Type: dataType,
 					}
-					headers = &design.AttributeDefinition{
-						Type: dataType,
-					}
 				})
 
 				It("writes the contexts code", func() {
@@ -811,17 +1382,23 @@ var _ = Describe("ContextsWriter", func() {
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(strContext))
-					Ω(written).Should(ContainSubstring(strHeaderParamContextFactory))
+					Ω(written).Should(ContainSubstring(resContext))
+					Ω(written).Should(ContainSubstring(resContextFactory))
 				})
 			})
 
-			Context("with a simple payload", func() {
+			Context("with a required param", func() {
 				BeforeEach(func() {
-					design.Design = new(design.APIDefinition)
-					payload = &design.UserTypeDefinition{
-						AttributeDefinition: &design.AttributeDefinition{Type: design.String},
-						TypeName:            "ListBottlePayload",
+					intParam := &design.AttributeDefinition{Type: design.Integer}
+					dataType := design.Object{
+						"int": intParam,
+					}
+					required := &dslengine.ValidationDefinition{
+						Required: []string{"int"},
+					}
+					params = &design.AttributeDefinition{
+						Type:       dataType,
+						Validation: required,
 					}
 				})
 
@@ -832,560 +1409,328 @@ var _ = Describe("ContextsWriter", func() {
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(payloadContext))
-					Ω(written).Should(ContainSubstring(payloadContextFactory))
+					Ω(written).Should(ContainSubstring(requiredContext))
+					Ω(written).Should(ContainSubstring(requiredContextFactory))
 				})
 			})
 
-			Context("with a object payload", func() {
+			Context("with a page size param", func() {
 				BeforeEach(func() {
-					design.Design = new(design.APIDefinition)
-					intParam := &design.AttributeDefinition{Type: design.Integer}
-					strParam := &design.AttributeDefinition{Type: design.String}
-					dataType := design.Object{
-						"int": intParam,
-						"str": strParam,
-					}
-					required := &dslengine.ValidationDefinition{
-						Required: []string{"int"},
-					}
-					payload = &design.UserTypeDefinition{
-						AttributeDefinition: &design.AttributeDefinition{
-							Type:       dataType,
-							Validation: required,
-						},
-						TypeName: "ListBottlePayload",
+					pageSize := &design.AttributeDefinition{Type: design.Integer}
+					pageSize.SetDefault(20)
+					params = &design.AttributeDefinition{
+						Type: design.Object{"page_size": pageSize},
 					}
+					maxPageSize = 100
 				})
 
-				It("writes the contexts code", func() {
+				It("writes the code that applies the default and clamps the page size", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(payloadObjContext))
-				})
-
-				var _ = Describe("IterateResponses", func() {
-					var resps []*design.ResponseDefinition
-					var testIt = func(r *design.ResponseDefinition) error {
-						resps = append(resps, r)
-						return nil
-					}
-					Context("with responses", func() {
-						BeforeEach(func() {
-							responses = map[string]*design.ResponseDefinition{
-								"OK":      {Status: 200},
-								"Created": {Status: 201},
-							}
-						})
-						It("iterates responses in order", func() {
-							data.IterateResponses(testIt)
-							Ω(resps).Should(Equal([]*design.ResponseDefinition{
-								responses["OK"],
-								responses["Created"],
-							}))
-						})
-					})
+					Ω(written).Should(ContainSubstring(pageSizeContextFactory))
 				})
 			})
-		})
-	})
-})
-
-var _ = Describe("ControllersWriter", func() {
-	var writer *genapp.ControllersWriter
-	var workspace *codegen.Workspace
-	var filename string
-
-	BeforeEach(func() {
-		var err error
-		workspace, err = codegen.NewWorkspace("test")
-		Ω(err).ShouldNot(HaveOccurred())
-		pkg, err := workspace.NewPackage("controllers")
-		Ω(err).ShouldNot(HaveOccurred())
-		src, err := pkg.CreateSourceFile("test.go")
-		Ω(err).ShouldNot(HaveOccurred())
-		defer src.Close()
-		filename = src.Abs()
-	})
-
-	JustBeforeEach(func() {
-		var err error
-		writer, err = genapp.NewControllersWriter(filename)
-		Ω(err).ShouldNot(HaveOccurred())
-	})
-
-	AfterEach(func() {
-		workspace.Delete()
-	})
-
-	Context("correctly configured", func() {
-		BeforeEach(func() {
-			os.Create(filename)
-		})
-
-		Context("with file servers", func() {
-			requestPath := "/swagger.json"
-			filePath := "swagger/swagger.json"
-			var origins []*design.CORSDefinition
-			var preflightPaths []string
 
-			var data []*genapp.ControllerTemplateData
+			Context("with a deprecated action", func() {
+				BeforeEach(func() {
+					deprecated = true
+					sunset = "Wed, 31 Dec 2025 00:00:00 GMT"
+				})
 
-			BeforeEach(func() {
-				origins = nil
-				preflightPaths = nil
-			})
+				It("writes the code that sets the deprecation headers", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(deprecatedContextFactory))
+				})
 
-			JustBeforeEach(func() {
-				codegen.TempCount = 0
-				fileServer := &design.FileServerDefinition{
-					FilePath:    filePath,
-					RequestPath: requestPath,
-				}
-				d := &genapp.ControllerTemplateData{
-					API:            &design.APIDefinition{},
-					Origins:        origins,
-					PreflightPaths: preflightPaths,
-					Resource:       "Public",
-					FileServers:    []*design.FileServerDefinition{fileServer},
-				}
-				data = []*genapp.ControllerTemplateData{d}
-			})
+				Context("that is not deprecated", func() {
+					BeforeEach(func() {
+						deprecated = false
+						sunset = ""
+					})
 
-			It("writes the file server code", func() {
-				err := writer.Execute(data)
-				Ω(err).ShouldNot(HaveOccurred())
-				b, err := ioutil.ReadFile(filename)
-				Ω(err).ShouldNot(HaveOccurred())
-				written := string(b)
-				Ω(written).ShouldNot(BeEmpty())
-				Ω(written).Should(ContainSubstring(simpleFileServer))
+					It("does not set the deprecation headers", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(ContainSubstring("Deprecation"))
+						Ω(written).ShouldNot(ContainSubstring("Sunset"))
+					})
+				})
 			})
 
-			Context("with CORS", func() {
+			Context("with sortable and filterable fields", func() {
 				BeforeEach(func() {
-					origins = []*design.CORSDefinition{
-						{
-							// NB: including backslash to ensure proper escaping
-							Origin:      "here.example.com",
-							Headers:     []string{"X-One", "X-Two"},
-							Methods:     []string{"GET", "POST"},
-							Exposed:     []string{"X-Three"},
-							Credentials: true,
-						},
+					params = &design.AttributeDefinition{
+						Type: design.Object{"sort": {Type: design.String}},
 					}
-					preflightPaths = []string{"/public/star\\*star/*filepath"}
+					sortFields = []string{"name", "created_at"}
+					filterFields = []string{"status"}
 				})
 
-				It("writes the OPTIONS handler code", func() {
+				It("writes the code that parses the sort and filter params", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(fileServerOptionsHandler))
+					Ω(written).Should(ContainSubstring(sortFilterContext))
+					Ω(written).Should(ContainSubstring(sortFilterContextFactory))
 				})
-			})
-		})
 
-		Context("with data", func() {
-			var multipart bool
-			var actions, verbs, paths, contexts, unmarshals []string
-			var payloads []*design.UserTypeDefinition
-			var encoders, decoders []*genapp.EncoderTemplateData
-			var origins []*design.CORSDefinition
+				Context("with default sort options", func() {
+					BeforeEach(func() {
+						sortDefaults = []*design.SortFieldDefinition{
+							{Name: "name"},
+							{Name: "created_at", Descending: true, Nulls: "last"},
+						}
+					})
 
-			var data []*genapp.ControllerTemplateData
-
-			BeforeEach(func() {
-				multipart = false
-				actions = nil
-				verbs = nil
-				paths = nil
-				contexts = nil
-				unmarshals = nil
-				payloads = nil
-				encoders = nil
-				decoders = nil
-				origins = nil
+					It("writes the code that parses the sort param with the default options", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(sortDefaultsContextFactory))
+					})
+				})
 			})
 
-			JustBeforeEach(func() {
-				codegen.TempCount = 0
-				api := &design.APIDefinition{}
-				d := &genapp.ControllerTemplateData{
-					Resource: "Bottles",
-					Origins:  origins,
-				}
-				as := make([]map[string]interface{}, len(actions))
-				for i, a := range actions {
-					var unmarshal string
-					var payload *design.UserTypeDefinition
-					if i < len(unmarshals) {
-						unmarshal = unmarshals[i]
-					}
-					if i < len(payloads) {
-						payload = payloads[i]
-					}
-					as[i] = map[string]interface{}{
-						"Name":       codegen.Goify(a, true),
-						"DesignName": a,
-						"Routes": []*design.RouteDefinition{
-							{
-								Verb: verbs[i],
-								Path: paths[i],
-							}},
-						"Context":          contexts[i],
-						"Unmarshal":        unmarshal,
-						"Payload":          payload,
-						"PayloadMultipart": multipart,
+			Context("with array params using a style", func() {
+				BeforeEach(func() {
+					ids := &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}}}
+					ids.SetArrayStyle("csv")
+					tags := &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}}
+					tags.SetArrayStyle("multi")
+					params = &design.AttributeDefinition{
+						Type: design.Object{"ids": ids, "tags": tags},
 					}
-				}
-				if len(as) > 0 {
-					d.API = api
-					d.Actions = as
-					d.Encoders = encoders
-					d.Decoders = decoders
-					data = []*genapp.ControllerTemplateData{d}
-				} else {
-					data = nil
-				}
-			})
+				})
 
-			Context("with missing data", func() {
-				It("returns an empty string", func() {
+				It("splits the values of the params that separate them", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).Should(BeEmpty())
+					Ω(written).Should(ContainSubstring(`paramIds := goa.SplitArrayParam(req.Params["ids"], "csv")`))
+					Ω(written).Should(ContainSubstring(`paramTags := req.Params["tags"]`))
 				})
 			})
 
-			Context("with a simple controller", func() {
+			Context("with a deep object param", func() {
+				var filter *design.AttributeDefinition
+
 				BeforeEach(func() {
-					actions = []string{"list"}
-					verbs = []string{"GET"}
-					paths = []string{"/accounts/:accountID/bottles"}
-					contexts = []string{"ListBottleContext"}
+					filter = &design.AttributeDefinition{
+						Type: design.Object{
+							"status": {Type: design.String},
+						},
+					}
+					filter.SetDeepObject()
+					params = &design.AttributeDefinition{
+						Type: design.Object{"filter": filter},
+					}
 				})
 
-				It("writes the controller code", func() {
+				It("writes the contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(simpleController))
-					Ω(written).Should(ContainSubstring(simpleMount))
+					Ω(written).Should(ContainSubstring(deepObjectContext))
+					Ω(written).Should(ContainSubstring(deepObjectContextFactory))
+				})
+
+				Context("with multiple fields including an array", func() {
+					BeforeEach(func() {
+						obj := filter.Type.ToObject()
+						obj["type"] = &design.AttributeDefinition{Type: design.String}
+						obj["ids"] = &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}}}
+						filter.Validation = &dslengine.ValidationDefinition{Required: []string{"status"}}
+					})
+
+					It("writes the contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(deepObjectArrayContextFactory))
+					})
 				})
 			})
 
-			Context("with actions that take a payload", func() {
+			Context("with a custom name param", func() {
 				BeforeEach(func() {
-					actions = []string{"list"}
-					verbs = []string{"GET"}
-					paths = []string{"/accounts/:accountID/bottles"}
-					contexts = []string{"ListBottleContext"}
-					unmarshals = []string{"unmarshalListBottlePayload"}
-					payloads = []*design.UserTypeDefinition{
-						{
-							TypeName: "ListBottlePayload",
-							AttributeDefinition: &design.AttributeDefinition{
-								Type: design.Object{
-									"id": &design.AttributeDefinition{
-										Type: design.String,
-									},
-								},
-							},
+					intParam := &design.AttributeDefinition{
+						Type: design.Integer,
+						Metadata: dslengine.MetadataDefinition{
+							"struct:field:name": []string{"custom"},
 						},
 					}
+					dataType := design.Object{
+						"int": intParam,
+					}
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
 				})
 
-				It("writes the payload unmarshal function", func() {
+				It("writes the contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).Should(ContainSubstring(payloadNoValidationsObjUnmarshal))
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(customContext))
+					Ω(written).Should(ContainSubstring(customContextFactory))
 				})
 			})
-			Context("with actions that take a payload with a required validation", func() {
+
+			Context("with a string header", func() {
 				BeforeEach(func() {
-					actions = []string{"list"}
-					required := &dslengine.ValidationDefinition{
-						Required: []string{"id"},
+					strHeader := &design.AttributeDefinition{Type: design.String}
+					dataType := design.Object{
+						"Header": strHeader,
 					}
-					verbs = []string{"GET"}
-					paths = []string{"/accounts/:accountID/bottles"}
-					contexts = []string{"ListBottleContext"}
-					unmarshals = []string{"unmarshalListBottlePayload"}
-					payloads = []*design.UserTypeDefinition{
-						{
-							TypeName: "ListBottlePayload",
-							AttributeDefinition: &design.AttributeDefinition{
-								Type: design.Object{
-									"id": &design.AttributeDefinition{
-										Type: design.String,
-									},
-								},
-								Validation: required,
-							},
-						},
+					headers = &design.AttributeDefinition{
+						Type: dataType,
 					}
 				})
 
-				It("writes the payload unmarshal function", func() {
+				It("writes the contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).Should(ContainSubstring(payloadObjUnmarshal))
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(strHeaderContext))
+					Ω(written).Should(ContainSubstring(strHeaderContextFactory))
 				})
 			})
 
-			Context("with actions that take a multipart payload", func() {
+			Context("with a string header and param with the same name", func() {
 				BeforeEach(func() {
-					actions = []string{"list"}
-					required := &dslengine.ValidationDefinition{
-						Required: []string{"id"},
+					str := &design.AttributeDefinition{Type: design.String}
+					dataType := design.Object{
+						"param": str,
 					}
-					verbs = []string{"GET"}
-					paths = []string{"/accounts/:accountID/bottles"}
-					contexts = []string{"ListBottleContext"}
-					unmarshals = []string{"unmarshalListBottlePayload"}
-					payloads = []*design.UserTypeDefinition{
-						{
-							TypeName: "ListBottlePayload",
-							AttributeDefinition: &design.AttributeDefinition{
-								Type: design.Object{
-									"id": &design.AttributeDefinition{
-										Type: design.Integer,
-									},
-									"title": &design.AttributeDefinition{
-										Type: design.String,
-									},
-									"icon": &design.AttributeDefinition{
-										Type: design.File,
-									},
-									"flags": &design.AttributeDefinition{
-										Type: &design.Array{
-											ElemType: &design.AttributeDefinition{
-												Type: design.Boolean,
-											},
-										},
-									},
-									"optionCodes": &design.AttributeDefinition{
-										Type: &design.Array{
-											ElemType: &design.AttributeDefinition{
-												Type: design.Integer,
-											},
-										},
-									},
-									"ratios": &design.AttributeDefinition{
-										Type: &design.Array{
-											ElemType: &design.AttributeDefinition{
-												Type: design.Number,
-											},
-										},
-									},
-									"commentLines": &design.AttributeDefinition{
-										Type: &design.Array{
-											ElemType: &design.AttributeDefinition{
-												Type: design.String,
-											},
-										},
-									},
-									"objs": &design.AttributeDefinition{
-										Type: &design.Array{
-											ElemType: &design.AttributeDefinition{
-												Type: &design.Object{},
-											},
-										},
-									},
-									"hashObjs": &design.AttributeDefinition{
-										Type: &design.Array{
-											ElemType: &design.AttributeDefinition{
-												Type: &design.Hash{
-													KeyType: &design.AttributeDefinition{
-														Type: design.String,
-													},
-													ElemType: &design.AttributeDefinition{
-														Type: &design.Array{
-															ElemType: &design.AttributeDefinition{
-																Type: design.String,
-															},
-														},
-													},
-												},
-											},
-										},
-									},
-								},
-								Validation: required,
-							},
-						},
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
+					headers = &design.AttributeDefinition{
+						Type: dataType,
 					}
-					multipart = true
 				})
 
-				It("writes the payload unmarshal function", func() {
+				It("writes the contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalID))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalTitle))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalIcon))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalFlags))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalOptionCodes))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalRatios))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalCommentLines))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalObjs))
-					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalHashObjs))
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(strContext))
+					Ω(written).Should(ContainSubstring(strHeaderParamContextFactory))
 				})
 			})
 
-			Context("with multiple controllers", func() {
+			Context("with a simple payload", func() {
 				BeforeEach(func() {
-					actions = []string{"list", "show"}
-					verbs = []string{"GET", "GET"}
-					paths = []string{"/accounts/:accountID/bottles", "/accounts/:accountID/bottles/:id"}
-					contexts = []string{"ListBottleContext", "ShowBottleContext"}
+					design.Design = new(design.APIDefinition)
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{Type: design.String},
+						TypeName:            "ListBottlePayload",
+					}
 				})
 
-				It("writes the controllers code", func() {
+				It("writes the contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(multiController))
-					Ω(written).Should(ContainSubstring(multiMount))
+					Ω(written).Should(ContainSubstring(payloadContext))
+					Ω(written).Should(ContainSubstring(payloadContextFactory))
 				})
 			})
 
-			Context("with encoder and decoder maps", func() {
+			Context("with a object payload", func() {
 				BeforeEach(func() {
-					actions = []string{"list"}
-					verbs = []string{"GET"}
-					paths = []string{"/accounts/:accountID/bottles"}
-					contexts = []string{"ListBottleContext"}
-					encoders = []*genapp.EncoderTemplateData{
-						{
-							PackageName: "goa",
-							Function:    "NewEncoder",
-							MIMETypes:   []string{"application/json"},
-						},
+					design.Design = new(design.APIDefinition)
+					intParam := &design.AttributeDefinition{Type: design.Integer}
+					strParam := &design.AttributeDefinition{Type: design.String}
+					dataType := design.Object{
+						"int": intParam,
+						"str": strParam,
 					}
-					decoders = []*genapp.EncoderTemplateData{
-						{
-							PackageName: "goa",
-							Function:    "NewDecoder",
-							MIMETypes:   []string{"application/json"},
-						},
+					required := &dslengine.ValidationDefinition{
+						Required: []string{"int"},
 					}
-				})
-
-				It("writes the controllers code", func() {
-					err := writer.Execute(data)
-					Ω(err).ShouldNot(HaveOccurred())
-					b, err := ioutil.ReadFile(filename)
-					Ω(err).ShouldNot(HaveOccurred())
-					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(encoderController))
-				})
-			})
-
-			Context("with multiple origins", func() {
-				BeforeEach(func() {
-					actions = []string{"list"}
-					verbs = []string{"GET"}
-					paths = []string{"/accounts"}
-					contexts = []string{"ListBottleContext"}
-					origins = []*design.CORSDefinition{
-						{
-							Origin:      "here.example.com",
-							Headers:     []string{"X-One", "X-Two"},
-							Methods:     []string{"GET", "POST"},
-							Exposed:     []string{"X-Three"},
-							Credentials: true,
-						},
-						{
-							Origin:  "there.example.com",
-							Headers: []string{"*"},
-							Methods: []string{"*"},
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type:       dataType,
+							Validation: required,
 						},
+						TypeName: "ListBottlePayload",
 					}
-
 				})
 
-				It("writes the controller code", func() {
+				It("writes the contexts code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(originsIntegration))
-					Ω(written).Should(ContainSubstring(originsHandler))
+					Ω(written).Should(ContainSubstring(payloadObjContext))
 				})
-			})
 
-			Context("with regexp origins", func() {
-				BeforeEach(func() {
-					actions = []string{"list"}
-					verbs = []string{"GET"}
-					paths = []string{"/accounts"}
-					contexts = []string{"ListBottleContext"}
-					origins = []*design.CORSDefinition{
-						{
-							Origin:      "[here|there].example.com",
-							Headers:     []string{"X-One", "X-Two"},
-							Methods:     []string{"GET", "POST"},
-							Exposed:     []string{"X-Three"},
-							Credentials: true,
-							Regexp:      true,
-						},
-						{
-							Origin:  "there.example.com",
-							Headers: []string{"*"},
-							Methods: []string{"*"},
-						},
+				var _ = Describe("IterateResponses", func() {
+					var resps []*design.ResponseDefinition
+					var testIt = func(r *design.ResponseDefinition) error {
+						resps = append(resps, r)
+						return nil
 					}
-
-				})
-
-				It("writes the controller code", func() {
-					err := writer.Execute(data)
-					Ω(err).ShouldNot(HaveOccurred())
-					b, err := ioutil.ReadFile(filename)
-					Ω(err).ShouldNot(HaveOccurred())
-					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(originsIntegration))
-					Ω(written).Should(ContainSubstring(regexpOriginsHandler))
+					Context("with responses", func() {
+						BeforeEach(func() {
+							responses = map[string]*design.ResponseDefinition{
+								"OK":      {Status: 200},
+								"Created": {Status: 201},
+							}
+						})
+						It("iterates responses in order", func() {
+							data.IterateResponses(testIt)
+							Ω(resps).Should(Equal([]*design.ResponseDefinition{
+								responses["OK"],
+								responses["Created"],
+							}))
+						})
+					})
 				})
 			})
-
 		})
 	})
 })
 
-var _ = Describe("HrefWriter", func() {
-	var writer *genapp.ResourcesWriter
+var _ = Describe("ControllersWriter", func() {
+	var writer *genapp.ControllersWriter
 	var workspace *codegen.Workspace
 	var filename string
 
@@ -1403,7 +1748,7 @@ var _ = Describe("HrefWriter", func() {
 
 	JustBeforeEach(func() {
 		var err error
-		writer, err = genapp.NewResourcesWriter(filename)
+		writer, err = genapp.NewControllersWriter(filename)
 		Ω(err).ShouldNot(HaveOccurred())
 	})
 
@@ -1412,226 +1757,1799 @@ var _ = Describe("HrefWriter", func() {
 	})
 
 	Context("correctly configured", func() {
-		Context("with data", func() {
-			var canoTemplate string
-			var canoParams []string
-			var mediaType *design.MediaTypeDefinition
+		BeforeEach(func() {
+			os.Create(filename)
+		})
 
-			var data *genapp.ResourceData
+		Context("with file servers", func() {
+			requestPath := "/swagger.json"
+			filePath := "swagger/swagger.json"
+			var origins []*design.CORSDefinition
+			var preflightPaths []string
+
+			var data []*genapp.ControllerTemplateData
 
 			BeforeEach(func() {
-				mediaType = nil
-				canoTemplate = ""
-				canoParams = nil
-				data = nil
+				origins = nil
+				preflightPaths = nil
 			})
 
 			JustBeforeEach(func() {
-				data = &genapp.ResourceData{
-					Name:              "Bottle",
-					Identifier:        "vnd.acme.com/resources",
-					Description:       "A bottle resource",
-					Type:              mediaType,
-					CanonicalTemplate: canoTemplate,
-					CanonicalParams:   canoParams,
+				codegen.TempCount = 0
+				fileServer := &design.FileServerDefinition{
+					FilePath:    filePath,
+					RequestPath: requestPath,
+				}
+				d := &genapp.ControllerTemplateData{
+					API:            &design.APIDefinition{},
+					Origins:        origins,
+					PreflightPaths: preflightPaths,
+					Resource:       "Public",
+					FileServers:    []*design.FileServerDefinition{fileServer},
 				}
+				data = []*genapp.ControllerTemplateData{d}
 			})
 
-			Context("with missing resource type definition", func() {
-				It("does not return an error", func() {
-					err := writer.Execute(data)
-					Ω(err).ShouldNot(HaveOccurred())
-				})
+			It("writes the file server code", func() {
+				err := writer.Execute(data)
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				written := string(b)
+				Ω(written).ShouldNot(BeEmpty())
+				Ω(written).Should(ContainSubstring(simpleFileServer))
 			})
 
-			Context("with a string resource", func() {
+			Context("with CORS", func() {
 				BeforeEach(func() {
-					attDef := &design.AttributeDefinition{
-						Type: design.String,
-					}
-					mediaType = &design.MediaTypeDefinition{
-						UserTypeDefinition: &design.UserTypeDefinition{
-							AttributeDefinition: attDef,
-							TypeName:            "Bottle",
+					origins = []*design.CORSDefinition{
+						{
+							// NB: including backslash to ensure proper escaping
+							Origin:      "here.example.com",
+							Headers:     []string{"X-One", "X-Two"},
+							Methods:     []string{"GET", "POST"},
+							Exposed:     []string{"X-Three"},
+							Credentials: true,
 						},
 					}
+					preflightPaths = []string{"/public/star\\*star/*filepath"}
 				})
-				It("does not return an error", func() {
+
+				It("writes the OPTIONS handler code", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(fileServerOptionsHandler))
 				})
 			})
+		})
 
-			Context("with a user type resource", func() {
-				BeforeEach(func() {
-					intParam := &design.AttributeDefinition{Type: design.Integer}
-					strParam := &design.AttributeDefinition{Type: design.String}
-					dataType := design.Object{
-						"int": intParam,
-						"str": strParam,
-					}
-					attDef := &design.AttributeDefinition{
-						Type: dataType,
-					}
-					mediaType = &design.MediaTypeDefinition{
-						UserTypeDefinition: &design.UserTypeDefinition{
-							AttributeDefinition: attDef,
-							TypeName:            "Bottle",
-						},
-					}
-				})
+		Context("with data", func() {
+			var multipart bool
+			var maxBodySize int64
+			var signatureHeader string
+			var versionHidden []map[string]string
+			var envelope *design.EnvelopeDefinition
+			var skipValidation bool
+			var dedupeWindow time.Duration
+			var cacheable bool
+			var requireLength bool
+			var contentTypes []string
+			var compress []string
+			var compressThreshold int64
+			var version, versionMedia string
+			var actions, verbs, paths, contexts, unmarshals []string
+			var payloads []*design.UserTypeDefinition
+			var encoders, decoders []*genapp.EncoderTemplateData
+			var origins []*design.CORSDefinition
+			var events []*design.EventDefinition
+			var batchOfs []map[string]interface{}
 
-				Context("and a canonical action", func() {
-					BeforeEach(func() {
-						canoTemplate = "/bottles/%v"
-						canoParams = []string{"id"}
-					})
+			var data []*genapp.ControllerTemplateData
 
-					It("writes the href method", func() {
+			BeforeEach(func() {
+				events = nil
+				batchOfs = nil
+				multipart = false
+				maxBodySize = 0
+				signatureHeader = ""
+				versionHidden = nil
+				envelope = nil
+				skipValidation = false
+				dedupeWindow = 0
+				cacheable = false
+				requireLength = false
+				contentTypes = nil
+				compress = nil
+				compressThreshold = 0
+				version = ""
+				versionMedia = ""
+				actions = nil
+				verbs = nil
+				paths = nil
+				contexts = nil
+				unmarshals = nil
+				payloads = nil
+				encoders = nil
+				decoders = nil
+				origins = nil
+			})
+
+			JustBeforeEach(func() {
+				codegen.TempCount = 0
+				api := &design.APIDefinition{}
+				d := &genapp.ControllerTemplateData{
+					Resource:     "Bottles",
+					Origins:      origins,
+					Events:       events,
+					Version:      version,
+					VersionMedia: versionMedia,
+				}
+				as := make([]map[string]interface{}, len(actions))
+				for i, a := range actions {
+					var unmarshal string
+					var payload *design.UserTypeDefinition
+					if i < len(unmarshals) {
+						unmarshal = unmarshals[i]
+					}
+					if i < len(payloads) {
+						payload = payloads[i]
+					}
+					as[i] = map[string]interface{}{
+						"Name":       codegen.Goify(a, true),
+						"DesignName": a,
+						"Routes": []*design.RouteDefinition{
+							{
+								Verb: verbs[i],
+								Path: paths[i],
+							}},
+						"Context":           contexts[i],
+						"Unmarshal":         unmarshal,
+						"Payload":           payload,
+						"PayloadMultipart":  multipart,
+						"MaxBodySize":       maxBodySize,
+						"SignatureHeader":   signatureHeader,
+						"SkipValidation":    skipValidation,
+						"VersionHidden":     versionHidden,
+						"DedupeWindow":      dedupeWindow,
+						"Cacheable":         cacheable,
+						"RequireLength":     requireLength,
+						"ContentTypes":      contentTypes,
+						"Compress":          compress,
+						"CompressThreshold": compressThreshold,
+						"Events":            events,
+						"Envelope":          envelope,
+					}
+					if i < len(batchOfs) && batchOfs[i] != nil {
+						as[i]["BatchOf"] = batchOfs[i]
+					}
+				}
+				if len(as) > 0 {
+					d.API = api
+					d.Actions = as
+					d.Encoders = encoders
+					d.Decoders = decoders
+					data = []*genapp.ControllerTemplateData{d}
+				} else {
+					data = nil
+				}
+			})
+
+			Context("with missing data", func() {
+				It("returns an empty string", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(BeEmpty())
+				})
+			})
+
+			Context("with a simple controller", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+				})
+
+				It("writes the controller code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(simpleController))
+					Ω(written).Should(ContainSubstring(simpleMount))
+				})
+			})
+
+			Context("with actions that take a payload", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.String,
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("writes the payload unmarshal function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadNoValidationsObjUnmarshal))
+				})
+			})
+			Context("with a batch action", func() {
+				BeforeEach(func() {
+					actions = []string{"batch_create", "create"}
+					verbs = []string{"POST", "POST"}
+					paths = []string{"/bottles/batch", "/bottles"}
+					contexts = []string{"BatchCreateBottleContext", "CreateBottleContext"}
+					unmarshals = []string{"unmarshalBatchCreateBottlePayload", "unmarshalCreateBottlePayload"}
+					create := &design.UserTypeDefinition{
+						TypeName: "CreateBottlePayload",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+						},
+					}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "BatchCreateBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: &design.Array{ElemType: &design.AttributeDefinition{Type: create}},
+							},
+						},
+						create,
+					}
+					batchOfs = []map[string]interface{}{{
+						"Name":     "Create",
+						"Context":  "CreateBottleContext",
+						"Results":  "GoaBatchResultCollection",
+						"Result":   "GoaBatchResult",
+						"Item":     "*createBottlePayload",
+						"Finalize": true,
+						"Validate": true,
+					}}
+				})
+
+				It("calls the source action controller for each item", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(batchController))
+					Ω(written).Should(ContainSubstring(batchMount))
+				})
+
+				It("finalizes the items without validating the batch", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(batchUnmarshal))
+				})
+
+				Context("derived from a cacheable action that emits events", func() {
+					BeforeEach(func() {
+						events = []*design.EventDefinition{{
+							Name: "BottleCreated",
+							Type: &design.UserTypeDefinition{
+								TypeName: "BottleCreated",
+								AttributeDefinition: &design.AttributeDefinition{
+									Type: design.Object{"id": {Type: design.Integer}},
+								},
+							},
+						}}
+						batchOfs[0]["Cacheable"] = true
+						batchOfs[0]["Events"] = events
+					})
+
+					It("replays the items and emits the events of each item", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(batchCacheableMount))
+					})
+				})
+			})
+
+			Context("with actions that limit the request body size", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					maxBodySize = 1024
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.String,
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("limits the request body before decoding it", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadMaxBodySizeUnmarshal))
+				})
+			})
+
+			Context("with actions that use a body envelope", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					envelope = &design.EnvelopeDefinition{DataField: "data", MetaField: "meta"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.String,
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("extracts the payload from the envelope", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadEnvelopeUnmarshal))
+				})
+			})
+
+			Context("with actions that verify the request body signature", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					maxBodySize = 1024
+					signatureHeader = "X-Signature"
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.String,
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("verifies the signature before decoding the request body", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadSignatureUnmarshal))
+				})
+			})
+
+			Context("with a payload with attributes limited to other versions", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":     &design.AttributeDefinition{Type: design.String},
+									"rating": &design.AttributeDefinition{Type: design.Integer},
+								},
+							},
+						},
+					}
+					versionHidden = []map[string]string{{"Field": "Rating", "Zero": "nil"}}
+				})
+
+				It("resets the attributes after decoding the request body", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadVersionUnmarshal))
+				})
+			})
+
+			Context("with actions that dedupe requests", func() {
+				BeforeEach(func() {
+					actions = []string{"create"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"CreateBottleContext"}
+					dedupeWindow = 10 * time.Minute
+				})
+
+				It("wraps the mux handler with the dedupe handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(dedupeMount))
+				})
+			})
+
+			Context("with actions that cache idempotent responses", func() {
+				BeforeEach(func() {
+					actions = []string{"create"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"CreateBottleContext"}
+					cacheable = true
+				})
+
+				It("wraps the mux handler with the replay handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(cacheableMount))
+				})
+			})
+
+			Context("with actions that check the request bodies", func() {
+				BeforeEach(func() {
+					actions = []string{"create"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"CreateBottleContext"}
+					maxBodySize = 1024
+					requireLength = true
+					contentTypes = []string{"application/octet-stream"}
+				})
+
+				It("wraps the mux handler with the request body checks", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(checkBodyMount))
+				})
+			})
+
+			Context("with actions that compress responses", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					compress = []string{"br", "gzip"}
+					compressThreshold = 1024
+				})
+
+				It("wraps the handler with the compression handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(compressMount))
+				})
+			})
+
+			Context("with a resource version", func() {
+				BeforeEach(func() {
+					actions = []string{"show"}
+					verbs = []string{"GET"}
+					paths = []string{"/bottles/:id"}
+					contexts = []string{"ShowBottleContext"}
+					version = "v2"
+					versionMedia = "application/vnd.cellar.{version}+json"
+				})
+
+				It("mounts the handlers by version", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(versionMount))
+				})
+			})
+
+			Context("with actions that emit events", func() {
+				BeforeEach(func() {
+					actions = []string{"create"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"CreateBottleContext"}
+					events = []*design.EventDefinition{{
+						Name: "BottleCreated",
+						Type: &design.UserTypeDefinition{
+							TypeName: "BottleCreated",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"id": {Type: design.Integer}},
+							},
+						},
+					}}
+				})
+
+				It("dispatches the events to the controller once the action succeeds", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(eventEmitterInterface))
+					Ω(written).Should(ContainSubstring(eventEmitterMount))
+				})
+			})
+
+			Context("with actions that take a payload with a required validation", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					required := &dslengine.ValidationDefinition{
+						Required: []string{"id"},
+					}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.String,
+									},
+								},
+								Validation: required,
+							},
+						},
+					}
+				})
+
+				It("writes the payload unmarshal function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadObjUnmarshal))
+				})
+
+				Context("and skip the request body validation", func() {
+					BeforeEach(func() {
+						skipValidation = true
+					})
+
+					It("does not validate the payload", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(payloadNoValidationUnmarshal))
+						Ω(written).ShouldNot(ContainSubstring("payload.Validate()"))
+					})
+				})
+			})
+
+			Context("with actions that take a multipart payload", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					required := &dslengine.ValidationDefinition{
+						Required: []string{"id"},
+					}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.Integer,
+									},
+									"title": &design.AttributeDefinition{
+										Type: design.String,
+									},
+									"icon": &design.AttributeDefinition{
+										Type: design.File,
+									},
+									"flags": &design.AttributeDefinition{
+										Type: &design.Array{
+											ElemType: &design.AttributeDefinition{
+												Type: design.Boolean,
+											},
+										},
+									},
+									"optionCodes": &design.AttributeDefinition{
+										Type: &design.Array{
+											ElemType: &design.AttributeDefinition{
+												Type: design.Integer,
+											},
+										},
+									},
+									"ratios": &design.AttributeDefinition{
+										Type: &design.Array{
+											ElemType: &design.AttributeDefinition{
+												Type: design.Number,
+											},
+										},
+									},
+									"commentLines": &design.AttributeDefinition{
+										Type: &design.Array{
+											ElemType: &design.AttributeDefinition{
+												Type: design.String,
+											},
+										},
+									},
+									"objs": &design.AttributeDefinition{
+										Type: &design.Array{
+											ElemType: &design.AttributeDefinition{
+												Type: &design.Object{},
+											},
+										},
+									},
+									"hashObjs": &design.AttributeDefinition{
+										Type: &design.Array{
+											ElemType: &design.AttributeDefinition{
+												Type: &design.Hash{
+													KeyType: &design.AttributeDefinition{
+														Type: design.String,
+													},
+													ElemType: &design.AttributeDefinition{
+														Type: &design.Array{
+															ElemType: &design.AttributeDefinition{
+																Type: design.String,
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+								Validation: required,
+							},
+						},
+					}
+					multipart = true
+				})
+
+				It("writes the payload unmarshal function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalID))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalTitle))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalIcon))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalFlags))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalOptionCodes))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalRatios))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalCommentLines))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalObjs))
+					Ω(written).Should(ContainSubstring(payloadMultipartObjUnmarshalHashObjs))
+				})
+			})
+
+			Context("with multiple controllers", func() {
+				BeforeEach(func() {
+					actions = []string{"list", "show"}
+					verbs = []string{"GET", "GET"}
+					paths = []string{"/accounts/:accountID/bottles", "/accounts/:accountID/bottles/:id"}
+					contexts = []string{"ListBottleContext", "ShowBottleContext"}
+				})
+
+				It("writes the controllers code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(multiController))
+					Ω(written).Should(ContainSubstring(multiMount))
+				})
+			})
+
+			Context("with encoder and decoder maps", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					encoders = []*genapp.EncoderTemplateData{
+						{
+							PackageName: "goa",
+							Function:    "NewEncoder",
+							MIMETypes:   []string{"application/json"},
+						},
+					}
+					decoders = []*genapp.EncoderTemplateData{
+						{
+							PackageName: "goa",
+							Function:    "NewDecoder",
+							MIMETypes:   []string{"application/json"},
+						},
+					}
+				})
+
+				It("writes the controllers code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(encoderController))
+				})
+			})
+
+			Context("with multiple origins", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts"}
+					contexts = []string{"ListBottleContext"}
+					origins = []*design.CORSDefinition{
+						{
+							Origin:      "here.example.com",
+							Headers:     []string{"X-One", "X-Two"},
+							Methods:     []string{"GET", "POST"},
+							Exposed:     []string{"X-Three"},
+							Credentials: true,
+						},
+						{
+							Origin:  "there.example.com",
+							Headers: []string{"*"},
+							Methods: []string{"*"},
+						},
+					}
+
+				})
+
+				It("writes the controller code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(originsIntegration))
+					Ω(written).Should(ContainSubstring(originsHandler))
+				})
+			})
+
+			Context("with regexp origins", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts"}
+					contexts = []string{"ListBottleContext"}
+					origins = []*design.CORSDefinition{
+						{
+							Origin:      "[here|there].example.com",
+							Headers:     []string{"X-One", "X-Two"},
+							Methods:     []string{"GET", "POST"},
+							Exposed:     []string{"X-Three"},
+							Credentials: true,
+							Regexp:      true,
+						},
+						{
+							Origin:  "there.example.com",
+							Headers: []string{"*"},
+							Methods: []string{"*"},
+						},
+					}
+
+				})
+
+				It("writes the controller code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(originsIntegration))
+					Ω(written).Should(ContainSubstring(regexpOriginsHandler))
+				})
+			})
+
+		})
+	})
+})
+
+var _ = Describe("HrefWriter", func() {
+	var writer *genapp.ResourcesWriter
+	var workspace *codegen.Workspace
+	var filename string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("controllers")
+		Ω(err).ShouldNot(HaveOccurred())
+		src, err := pkg.CreateSourceFile("test.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer src.Close()
+		filename = src.Abs()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewResourcesWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("correctly configured", func() {
+		Context("with data", func() {
+			var canoTemplate string
+			var canoParams []string
+			var mediaType *design.MediaTypeDefinition
+
+			var data *genapp.ResourceData
+
+			BeforeEach(func() {
+				mediaType = nil
+				canoTemplate = ""
+				canoParams = nil
+				data = nil
+			})
+
+			JustBeforeEach(func() {
+				data = &genapp.ResourceData{
+					Name:              "Bottle",
+					Identifier:        "vnd.acme.com/resources",
+					Description:       "A bottle resource",
+					Type:              mediaType,
+					CanonicalTemplate: canoTemplate,
+					CanonicalParams:   canoParams,
+				}
+			})
+
+			Context("with missing resource type definition", func() {
+				It("does not return an error", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+				})
+			})
+
+			Context("with a string resource", func() {
+				BeforeEach(func() {
+					attDef := &design.AttributeDefinition{
+						Type: design.String,
+					}
+					mediaType = &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: attDef,
+							TypeName:            "Bottle",
+						},
+					}
+				})
+				It("does not return an error", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+				})
+			})
+
+			Context("with a user type resource", func() {
+				BeforeEach(func() {
+					intParam := &design.AttributeDefinition{Type: design.Integer}
+					strParam := &design.AttributeDefinition{Type: design.String}
+					dataType := design.Object{
+						"int": intParam,
+						"str": strParam,
+					}
+					attDef := &design.AttributeDefinition{
+						Type: dataType,
+					}
+					mediaType = &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: attDef,
+							TypeName:            "Bottle",
+						},
+					}
+				})
+
+				Context("and a canonical action", func() {
+					BeforeEach(func() {
+						canoTemplate = "/bottles/%v"
+						canoParams = []string{"id"}
+					})
+
+					It("writes the href method", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(simpleResourceHref))
+					})
+				})
+
+				Context("and a canonical action with no param", func() {
+					BeforeEach(func() {
+						canoTemplate = "/bottles"
+					})
+
+					It("writes the href method", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring(noParamHref))
+					})
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("UserTypesWriter", func() {
+	var writer *genapp.UserTypesWriter
+	var workspace *codegen.Workspace
+	var filename string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("controllers")
+		Ω(err).ShouldNot(HaveOccurred())
+		src, err := pkg.CreateSourceFile("test.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer src.Close()
+		filename = src.Abs()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewUserTypesWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("correctly configured", func() {
+		Context("with data", func() {
+			var data *design.UserTypeDefinition
+			var attDef *design.AttributeDefinition
+			var typeName string
+
+			BeforeEach(func() {
+				data = nil
+				attDef = nil
+				typeName = ""
+			})
+
+			JustBeforeEach(func() {
+				data = &design.UserTypeDefinition{
+					AttributeDefinition: attDef,
+					TypeName:            typeName,
+				}
+			})
+
+			Context("with a simple user type", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"name": &design.AttributeDefinition{
+								Type: design.String,
+							},
+						},
+					}
+					typeName = "SimplePayload"
+				})
+				It("writes the simple user type code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(simpleUserType))
+				})
+			})
+
+			Context("with a user type used as an action payload", func() {
+				var api *design.APIDefinition
+
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"address": &design.AttributeDefinition{
+								Type: design.Object{"zip": &design.AttributeDefinition{Type: design.String}},
+							},
+						},
+					}
+					typeName = "UserPayload"
+					api = design.Design
+				})
+
+				JustBeforeEach(func() {
+					design.Design = &design.APIDefinition{
+						Resources: map[string]*design.ResourceDefinition{
+							"users": {
+								Name: "users",
+								Actions: map[string]*design.ActionDefinition{
+									"create": {Name: "create", Payload: data},
+								},
+							},
+						},
+					}
+				})
+
+				AfterEach(func() {
+					design.Design = api
+				})
+
+				It("writes the attribute paths of the payload", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(userPayloadFields))
+				})
+			})
+
+			Context("with an attribute that defines transitions", func() {
+				JustBeforeEach(func() {
+					data.AttributeDefinition = &design.AttributeDefinition{
+						Type: design.Object{
+							"status": &design.AttributeDefinition{
+								Type:       design.String,
+								Validation: &dslengine.ValidationDefinition{Values: []interface{}{"pending", "active", "cancelled", "closed"}},
+								Transitions: []*design.TransitionDefinition{
+									{From: "pending", Targets: []string{"active", "cancelled"}},
+									{From: "active", Targets: []string{"closed"}},
+								},
+							},
+						},
+					}
+					data.TypeName = "Order"
+				})
+
+				It("writes the transition validation code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(transitionsCode))
+					Ω(written).Should(ContainSubstring(validateTransitionsCode))
+				})
+			})
+
+			Context("with a SQL bindable user type", func() {
+				var style []string
+
+				BeforeEach(func() {
+					style = nil
+				})
+
+				JustBeforeEach(func() {
+					data.AttributeDefinition = &design.AttributeDefinition{
+						Type: design.Object{
+							"count":    &design.AttributeDefinition{Type: design.Integer},
+							"deleted":  &design.AttributeDefinition{Type: design.Boolean, Metadata: dslengine.MetadataDefinition{"struct:field:nullable": nil}},
+							"owner_id": &design.AttributeDefinition{Type: design.Integer},
+							"status":   &design.AttributeDefinition{Type: design.String},
+							"tags":     &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"count"}},
+						Metadata:   dslengine.MetadataDefinition{"sql:bindable": style},
+					}
+					data.TypeName = "FilterPayload"
+				})
+
+				It("writes the SQL query helper using question mark placeholders", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(sqlBindQuestionUserType))
+				})
+
+				Context("using numbered placeholders", func() {
+					BeforeEach(func() {
+						style = []string{"$"}
+					})
+
+					It("writes the SQL query helper using numbered placeholders", func() {
 						err := writer.Execute(data)
 						Ω(err).ShouldNot(HaveOccurred())
 						b, err := ioutil.ReadFile(filename)
 						Ω(err).ShouldNot(HaveOccurred())
 						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(simpleResourceHref))
+						Ω(written).Should(ContainSubstring(sqlBindDollarUserType))
 					})
 				})
 
-				Context("and a canonical action with no param", func() {
-					BeforeEach(func() {
-						canoTemplate = "/bottles"
+				Context("using column names", func() {
+					JustBeforeEach(func() {
+						status := data.Type.ToObject()["status"]
+						status.Metadata = dslengine.MetadataDefinition{"storage:column": {"state"}}
 					})
 
-					It("writes the href method", func() {
+					It("uses the column names in the predicates", func() {
 						err := writer.Execute(data)
 						Ω(err).ShouldNot(HaveOccurred())
 						b, err := ioutil.ReadFile(filename)
 						Ω(err).ShouldNot(HaveOccurred())
 						written := string(b)
-						Ω(written).ShouldNot(BeEmpty())
-						Ω(written).Should(ContainSubstring(noParamHref))
+						Ω(written).Should(ContainSubstring(`preds = append(preds, "state = ?")`))
+						Ω(written).Should(ContainSubstring("Status *string `form:\"status,omitempty\" json:\"status,omitempty\" yaml:\"status,omitempty\" xml:\"status,omitempty\" gorm:\"column:state\"`"))
+					})
+				})
+			})
+
+			Context("with an integer enum with named values", func() {
+				var enumAtt *design.AttributeDefinition
+
+				BeforeEach(func() {
+					enumAtt = &design.AttributeDefinition{
+						Type:       design.Integer,
+						Validation: &dslengine.ValidationDefinition{Values: []interface{}{1, 2}},
+						Metadata: dslengine.MetadataDefinition{
+							"enum:names": {"ACTIVE", "IN_STOCK"},
+							"enum:type":  {"bottle_status"},
+						},
+					}
+				})
+
+				It("writes the enum type encoding the value numbers", func() {
+					err := writer.WriteEnum(enumAtt)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(namedEnumType))
+					Ω(written).Should(ContainSubstring(namedEnumNumberEncoder))
+				})
+
+				Context("encoding the value names", func() {
+					BeforeEach(func() {
+						enumAtt.Metadata["enum:encode"] = []string{"name"}
+					})
+
+					It("writes the enum type encoding the value names", func() {
+						err := writer.WriteEnum(enumAtt)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(namedEnumNameEncoder))
 					})
 				})
 			})
+
+			Context("with a flag set", func() {
+				It("writes the flag set type", func() {
+					att := &design.AttributeDefinition{
+						Type:     design.String,
+						Flags:    []*design.FlagDefinition{{Name: "READ", Value: 1}, {Name: "WRITE", Value: 2}},
+						Metadata: dslengine.MetadataDefinition{"flags:type": {"permissions"}},
+					}
+					err := writer.WriteFlagSet(att)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(flagSetType))
+				})
+			})
+
+			Context("with a user type including hash", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"name": &design.AttributeDefinition{
+								Type: design.String,
+							},
+							"misc": &design.AttributeDefinition{
+								Type: &design.Hash{
+									KeyType: &design.AttributeDefinition{
+										Type: design.Integer,
+									},
+									ElemType: &design.AttributeDefinition{
+										Type: &design.UserTypeDefinition{
+											AttributeDefinition: &design.AttributeDefinition{
+												Type: &design.UserTypeDefinition{
+													AttributeDefinition: &design.AttributeDefinition{
+														Type: design.Object{},
+													},
+													TypeName: "Misc",
+												},
+											},
+											TypeName: "MiscPayload",
+										},
+									},
+								},
+							},
+						},
+					}
+					typeName = "ComplexPayload"
+				})
+				It("writes the user type including hash", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(userTypeIncludingHash))
+				})
+			})
 		})
 	})
 })
 
-var _ = Describe("UserTypesWriter", func() {
-	var writer *genapp.UserTypesWriter
-	var workspace *codegen.Workspace
-	var filename string
+const (
+	namedEnumType = `// BottleStatus is an integer enum with named values.
+type BottleStatus int
 
-	BeforeEach(func() {
-		var err error
-		workspace, err = codegen.NewWorkspace("test")
-		Ω(err).ShouldNot(HaveOccurred())
-		pkg, err := workspace.NewPackage("controllers")
-		Ω(err).ShouldNot(HaveOccurred())
-		src, err := pkg.CreateSourceFile("test.go")
-		Ω(err).ShouldNot(HaveOccurred())
-		defer src.Close()
-		filename = src.Abs()
-	})
+// BottleStatus values.
+const (
+	BottleStatusActive BottleStatus = 1
+	BottleStatusInStock BottleStatus = 2
+)
 
-	JustBeforeEach(func() {
-		var err error
-		writer, err = genapp.NewUserTypesWriter(filename)
-		Ω(err).ShouldNot(HaveOccurred())
-	})
+// String returns the name of the value or its number if the value is unknown.
+func (e BottleStatus) String() string {
+	switch e {
+	case BottleStatusActive:
+		return "ACTIVE"
+	case BottleStatusInStock:
+		return "IN_STOCK"
+	}
+	return strconv.Itoa(int(e))
+}
 
-	AfterEach(func() {
-		workspace.Delete()
-	})
+// ParseBottleStatus returns the BottleStatus value with the given name or number.
+func ParseBottleStatus(s string) (BottleStatus, error) {
+	switch s {
+	case "ACTIVE", "1":
+		return BottleStatusActive, nil
+	case "IN_STOCK", "2":
+		return BottleStatusInStock, nil
+	}
+	return 0, fmt.Errorf("invalid BottleStatus value %q", s)
+}
+`
+
+	flagSetType = `// Permissions is a set of flags encoded as a comma separated list of flag names.
+type Permissions int
+
+// Permissions flags.
+const (
+	PermissionsRead Permissions = 1
+	PermissionsWrite Permissions = 2
+)
+
+// Has returns true if all the given flags are set.
+func (f Permissions) Has(flags Permissions) bool {
+	return f&flags == flags
+}
+
+// String returns the comma separated list of the names of the flags that are set.
+func (f Permissions) String() string {
+	var names []string
+	if f&PermissionsRead != 0 {
+		names = append(names, "READ")
+	}
+	if f&PermissionsWrite != 0 {
+		names = append(names, "WRITE")
+	}
+	return strings.Join(names, ",")
+}
+
+// ParsePermissions returns the Permissions value with the flags named in the given comma
+// separated list.
+func ParsePermissions(s string) (Permissions, error) {
+	var f Permissions
+	if s == "" {
+		return f, nil
+	}
+	for _, n := range strings.Split(s, ",") {
+		switch strings.TrimSpace(n) {
+		case "READ":
+			f |= PermissionsRead
+		case "WRITE":
+			f |= PermissionsWrite
+		default:
+			return 0, fmt.Errorf("invalid Permissions flag %q", n)
+		}
+	}
+	return f, nil
+}
+`
+
+	namedEnumNumberEncoder = `// MarshalJSON encodes the value using its number.
+func (e BottleStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(e))
+}
+
+// UnmarshalJSON decodes the value from either its name or its number.
+func (e *BottleStatus) UnmarshalJSON(b []byte) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		*e = BottleStatus(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := ParseBottleStatus(s)
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+`
+
+	namedEnumNameEncoder = `// MarshalJSON encodes the value using its name.
+func (e BottleStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+`
+
+	userPayloadFields = `// UserPayloadFields lists the canonical paths of the UserPayload attributes as used by the
+// validation errors. Use them as the "attribute" value of hand-written errors so that these
+// reference the attributes the same way the validation errors do.
+var UserPayloadFields = struct {
+	Address struct {
+		Zip string
+	}
+}{
+	Address: struct {
+		Zip string
+	}{
+		Zip: "address.zip",
+	},
+}`
+
+	updatedFieldsCode = `func (payload *ListBottlePayload) UpdatedFields() []string {
+	if payload == nil {
+		return nil
+	}
+	var fields []string
+	if payload.CreatedAt != nil {
+		fields = append(fields, "created_at")
+	}
+	if payload.Name.Present {
+		fields = append(fields, "name")
+	}
+	if payload.Tags != nil {
+		fields = append(fields, "tags")
+	}
+	return fields
+}`
+
+	echoUpdatedResponse = `	updated := ctx.Payload.UpdatedFields()
+	for i, f := range updated {
+		if n, ok := map[string]string{"created_at": "createdAt"}[f]; ok {
+			updated[i] = n
+		}
+	}
+	body, err := goa.SelectFields(r, updated...)
+	if err != nil {
+		return err
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, body)
+}`
+
+	transitionsCode = `func ValidateOrderStatusTransition(from, to string) error {
+	if from == to {
+		return nil
+	}
+	var allowed []string
+	switch from {
+	case "pending":
+		allowed = []string{"active", "cancelled"}
+	case "active":
+		allowed = []string{"closed"}
+	}
+	for _, a := range allowed {
+		if a == to {
+			return nil
+		}
+	}
+	return goa.InvalidTransitionError(` + "`" + `type.status` + "`" + `, from, to, allowed)
+}`
+
+	validateTransitionsCode = `func (ut *Order) ValidateTransitions(old *Order) (err error) {
+	if old == nil {
+		return
+	}
+	if ut.Status != nil && old.Status != nil {
+		if err2 := ValidateOrderStatusTransition(*old.Status, *ut.Status); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}
+	}
+	return
+}`
+
+	sqlBindQuestionUserType = `// SQLWhere returns a SQL WHERE clause made of equality predicates for the fields that are set
+// joined with AND, and the corresponding query arguments.
+func (ut *FilterPayload) SQLWhere() (string, []interface{}) {
+	var preds []string
+	var args []interface{}
+	args = append(args, ut.Count)
+	preds = append(preds, "count = ?")
+	if ut.Deleted.Present {
+		if ut.Deleted.Null {
+			preds = append(preds, "deleted IS NULL")
+		} else {
+			args = append(args, ut.Deleted.Value)
+			preds = append(preds, "deleted = ?")
+		}
+	}
+	if ut.OwnerID != nil {
+		args = append(args, *ut.OwnerID)
+		preds = append(preds, "owner_id = ?")
+	}
+	if ut.Status != nil {
+		args = append(args, *ut.Status)
+		preds = append(preds, "status = ?")
+	}
+	return strings.Join(preds, " AND "), args
+}
+`
+
+	sqlBindDollarUserType = `	args = append(args, ut.Count)
+	preds = append(preds, "count = $"+strconv.Itoa(len(args)))
+	if ut.Deleted.Present {
+		if ut.Deleted.Null {
+			preds = append(preds, "deleted IS NULL")
+		} else {
+			args = append(args, ut.Deleted.Value)
+			preds = append(preds, "deleted = $"+strconv.Itoa(len(args)))
+		}
+	}
+	if ut.OwnerID != nil {
+		args = append(args, *ut.OwnerID)
+		preds = append(preds, "owner_id = $"+strconv.Itoa(len(args)))
+	}
+`
+
+	respondFromField = `// Respond sends a HTTP response with status code 201 if the created field of r is true,
+// with status code 200 otherwise.
+func (ctx *ListBottleContext) Respond(r *Bottle) error {
+	if r != nil && r.Created != nil && *r.Created {
+		return ctx.Created(r)
+	}
+	return ctx.OK(r)
+}
+`
+
+	surrogateKeysResponse = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r *Bottle) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.test")
+	}
+	var keys []interface{}
+	if r != nil {
+		keys = append(keys, r.Account, r.ID)
+	}
+	if k := goa.SurrogateKeys(keys...); k != "" {
+		ctx.ResponseData.Header().Set("Surrogate-Key", k)
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+`
+
+	envelopeResponse = `
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, &goa.Envelope{DataField: "data", MetaField: "meta", Data: r, Meta: ctx.ResponseData.Meta})
+}`
+
+	sparseFieldsResponse = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r *Bottle) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.test")
+	}
+	if ctx.Fields != nil {
+		v, err := goa.SparseFieldset(r, *ctx.Fields)
+		if err != nil {
+			return err
+		}
+		return ctx.ResponseData.Service.Send(ctx.Context, 200, v)
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+`
+
+	versionHiddenResponse = `	body, err := goa.OmitFields(r, "rating")
+	if err != nil {
+		return err
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, body)
+}
+`
+
+	scopedFieldsResponse = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r *Employee) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.test")
+	}
+	body, err := goa.OmitScopedFields(ctx.Context, r, map[string]string{"salary": "admin"})
+	if err != nil {
+		return err
+	}
+	if ctx.Fields != nil {
+		v, err := goa.SparseFieldset(body, *ctx.Fields)
+		if err != nil {
+			return err
+		}
+		return ctx.ResponseData.Service.Send(ctx.Context, 200, v)
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, body)
+}
+`
+
+	encodeTransformsResponse = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r *Employee) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.test")
+	}
+	body, err := goa.OmitScopedFields(ctx.Context, r, map[string]string{"salary": "admin"})
+	if err != nil {
+		return err
+	}
+	body, err = goa.ApplyEncodeTransforms(body, map[string]string{"phone": "e164"})
+	if err != nil {
+		return err
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, body)
+}
+`
+
+	computedResponse = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r *Person) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.test")
+	}
+	if r != nil {
+		r.ComputeFields()
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+`
 
-	Context("correctly configured", func() {
-		Context("with data", func() {
-			var data *design.UserTypeDefinition
-			var attDef *design.AttributeDefinition
-			var typeName string
+	cookieResponse = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r *Login) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.test")
+	}
+	if r != nil && r.Session != nil {
+		http.SetCookie(ctx.ResponseData, &http.Cookie{Name: "session", Value: *r.Session, MaxAge: 86400, HttpOnly: true, Secure: true, SameSite: http.SameSiteStrictMode})
+	}
+	body, err := goa.OmitFields(r, "session")
+	if err != nil {
+		return err
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, body)
+}
+`
 
-			BeforeEach(func() {
-				data = nil
-				attDef = nil
-				typeName = ""
-			})
+	batchController = `type BottlesController interface {
+	goa.Muxer
+	Create(*CreateBottleContext) error
+}
+`
 
-			JustBeforeEach(func() {
-				data = &design.UserTypeDefinition{
-					AttributeDefinition: attDef,
-					TypeName:            typeName,
+	batchMount = `		// Handle each item like the requests of the Create action
+		items, ok := goa.ContextRequest(ctx).Payload.([]*createBottlePayload)
+		if !ok {
+			return goa.MissingPayloadError()
+		}
+		results := make(GoaBatchResultCollection, len(items))
+		for i, item := range items {
+			status, body := goa.BatchItem(ctx, req, func(ctx context.Context) error {
+				if item == nil {
+					return goa.MissingPayloadError()
 				}
+				if err := item.Validate(); err != nil {
+					return goa.ErrBadRequest(err)
+				}
+				rctx, err := NewCreateBottleContext(ctx, req, service)
+				if err != nil {
+					return err
+				}
+				rctx.Payload = item.Publicize()
+				return ctrl.Create(rctx)
 			})
+			results[i] = &GoaBatchResult{Status: status, Body: body}
+		}
+		return rctx.OK(results)
+	}
+	service.Mux.Handle("POST", "/bottles/batch", ctrl.MuxHandler("batch_create", h, unmarshalBatchCreateBottlePayload))
+`
 
-			Context("with a simple user type", func() {
-				BeforeEach(func() {
-					attDef = &design.AttributeDefinition{
-						Type: design.Object{
-							"name": &design.AttributeDefinition{
-								Type: design.String,
-							},
-						},
+	batchCacheableMount = `			status, body := goa.BatchItem(ctx, req, service.ReplayIdempotentItem(i, func(ctx context.Context) error {
+				if item == nil {
+					return goa.MissingPayloadError()
+				}
+				if err := item.Validate(); err != nil {
+					return goa.ErrBadRequest(err)
+				}
+				rctx, err := NewCreateBottleContext(ctx, req, service)
+				if err != nil {
+					return err
+				}
+				rctx.Payload = item.Publicize()
+				if err := ctrl.Create(rctx); err != nil {
+					return err
+				}
+				if emitter == nil || rctx.ResponseData.Status >= 400 {
+					return nil
+				}
+				if rctx.BottleCreatedEvent != nil {
+					if err := emitter.EmitBottleCreated(rctx, rctx.BottleCreatedEvent); err != nil {
+						goa.LogError(rctx, "failed to emit event", "event", "BottleCreated", "err", err)
 					}
-					typeName = "SimplePayload"
-				})
-				It("writes the simple user type code", func() {
-					err := writer.Execute(data)
-					Ω(err).ShouldNot(HaveOccurred())
-					b, err := ioutil.ReadFile(filename)
-					Ω(err).ShouldNot(HaveOccurred())
-					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(simpleUserType))
-				})
-			})
+				}
+				return nil
+			}))
+`
 
-			Context("with a user type including hash", func() {
-				BeforeEach(func() {
-					attDef = &design.AttributeDefinition{
-						Type: design.Object{
-							"name": &design.AttributeDefinition{
-								Type: design.String,
-							},
-							"misc": &design.AttributeDefinition{
-								Type: &design.Hash{
-									KeyType: &design.AttributeDefinition{
-										Type: design.Integer,
-									},
-									ElemType: &design.AttributeDefinition{
-										Type: &design.UserTypeDefinition{
-											AttributeDefinition: &design.AttributeDefinition{
-												Type: &design.UserTypeDefinition{
-													AttributeDefinition: &design.AttributeDefinition{
-														Type: design.Object{},
-													},
-													TypeName: "Misc",
-												},
-											},
-											TypeName: "MiscPayload",
-										},
-									},
-								},
-							},
-						},
-					}
-					typeName = "ComplexPayload"
-				})
-				It("writes the user type including hash", func() {
-					err := writer.Execute(data)
-					Ω(err).ShouldNot(HaveOccurred())
-					b, err := ioutil.ReadFile(filename)
-					Ω(err).ShouldNot(HaveOccurred())
-					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(userTypeIncludingHash))
-				})
-			})
-		})
-	})
-})
+	batchUnmarshal = `func unmarshalBatchCreateBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	var payload []*createBottlePayload
+	if err := service.DecodeRequest(req, &payload); err != nil {
+		return err
+	}
+	for _, item := range payload {
+		if item == nil {
+			continue
+		}
+		item.Finalize()
+	}
+	// The items are validated individually so that invalid items do not fail the batch
+	goa.ContextRequest(ctx).Payload = payload
+	return nil
+}
+`
+
+	deprecatedContextFactory = `	rctx.ResponseData.Header().Set("Deprecation", "true")
+	rctx.ResponseData.Header().Set("Sunset", "Wed, 31 Dec 2025 00:00:00 GMT")
+	return &rctx, err
+`
+
+	pageSizeContextFactory = `	paramPageSize := req.Params["page_size"]
+	if len(paramPageSize) == 0 {
+		rctx.PageSize = 20
+	} else {
+		rawPageSize := paramPageSize[0]
+		if pageSize, err2 := strconv.Atoi(rawPageSize); err2 == nil {
+			rctx.PageSize = pageSize
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("page_size", rawPageSize, "integer"))
+		}
+	}
+	if rctx.PageSize > 100 {
+		rctx.PageSize = 100
+		rctx.ResponseData.Header().Set("Warning", ` + "`" + `299 - "page_size clamped to 100"` + "`" + `)
+	}
+	return &rctx, err
+}
+`
+
+	sortFilterContext = `	Sort *string
+	// SortFields lists the fields used to sort the results in order of precedence.
+	SortFields []*goa.SortField
+	// Filters contains the values of the filter params indexed by field name.
+	Filters map[string][]string
+}
+`
+
+	sortDefaultsContextFactory = `	if sortFields, err2 := goa.ParseSortFields(req.Params["sort"], &goa.SortField{Name: "name"}, &goa.SortField{Name: "created_at", Descending: true, Nulls: goa.NullsLast}); err2 == nil {
+		rctx.SortFields = sortFields
+	} else {
+		err = goa.MergeErrors(err, err2)
+	}
+`
+
+	sortFilterContextFactory = `	if sortFields, err2 := goa.ParseSort(req.Params["sort"], "name", "created_at"); err2 == nil {
+		rctx.SortFields = sortFields
+	} else {
+		err = goa.MergeErrors(err, err2)
+	}
+	if filters, err2 := goa.ParseFilters(req.Params, "status"); err2 == nil {
+		rctx.Filters = filters
+	} else {
+		err = goa.MergeErrors(err, err2)
+	}
+	return &rctx, err
+}
+`
+
+	streamJSONResponse = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r BottleCollection) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	if r == nil {
+		r = BottleCollection{}
+	}
+	ctx.ResponseData.WriteHeader(200)
+	enc := goa.NewNDJSONEncoder(ctx.ResponseData)
+	for _, e := range r {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`
 
-const (
 	emptyContext = `
 type ListBottleContext struct {
 	context.Context
@@ -2188,6 +4106,89 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 }
 `
 
+	intArrayItemsContextFactory = `
+		rctx.Param = params
+			if len(rctx.Param) > 5 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `param` + "`" + `, rctx.Param, len(rctx.Param), 5, false))
+		}
+		if len(rctx.Param) > 1 {
+			seen := make(map[interface{}]int, len(rctx.Param))
+			for i, e := range rctx.Param {
+				if j, ok := seen[e]; ok {
+					err = goa.MergeErrors(err, goa.DuplicateItemError(` + "`" + `param` + "`" + `, e, i, j))
+				} else {
+					seen[e] = i
+				}
+			}
+		}
+	}
+	return &rctx, err
+}
+`
+
+	deepObjectContext = `
+type ListBottleContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Filter *struct {
+		Status *string
+	}
+}
+`
+
+	deepObjectContextFactory = `
+	paramFilter := goa.DeepObjectParams(req.Params, "filter")
+	if len(paramFilter) > 0 {
+		rctx.Filter = &struct {
+			Status *string
+		}{}
+		paramFilterStatus := paramFilter["status"]
+		if len(paramFilterStatus) > 0 {
+			rawFilterStatus := paramFilterStatus[0]
+			rctx.Filter.Status = &rawFilterStatus
+		}
+	}
+	return &rctx, err
+`
+
+	deepObjectArrayContextFactory = `
+	paramFilter := goa.DeepObjectParams(req.Params, "filter")
+	if len(paramFilter) > 0 {
+		rctx.Filter = &struct {
+			Ids []int
+			Status string
+			Type *string
+		}{}
+		paramFilterIds := paramFilter["ids"]
+		if len(paramFilterIds) > 0 {
+			params := make([]int, len(paramFilterIds))
+			for i, rawFilterIds := range paramFilterIds {
+				if filterIds, err2 := strconv.Atoi(rawFilterIds); err2 == nil {
+					params[i] = filterIds
+				} else {
+					err = goa.MergeErrors(err, goa.InvalidParamTypeError("filter[ids]", rawFilterIds, "integer"))
+				}
+			}
+			rctx.Filter.Ids = params
+		}
+		paramFilterStatus := paramFilter["status"]
+		if len(paramFilterStatus) > 0 {
+			rawFilterStatus := paramFilterStatus[0]
+			rctx.Filter.Status = rawFilterStatus
+		}
+		paramFilterType := paramFilter["type"]
+		if len(paramFilterType) > 0 {
+			rawFilterType := paramFilterType[0]
+			rctx.Filter.Type = &rawFilterType
+		}
+		if rctx.Filter.Status == "" {
+			err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `filter` + "`" + `, "status"))
+		}
+	}
+	return &rctx, err
+`
+
 	intArrayDefaultContextFactory = `
 func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Service) (*ListBottleContext, error) {
 	var err error
@@ -2488,6 +4489,112 @@ func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *
 }
 `
 
+	payloadMaxBodySizeUnmarshal = `
+func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	goa.LimitRequestBody(ctx, req, 1024)
+	payload := &listBottlePayload{}
+	if err := service.DecodeRequest(req, payload); err != nil {
+		return err
+	}
+	goa.ContextRequest(ctx).Payload = payload.Publicize()
+	return nil
+}
+`
+
+	versionMount = `	service.HandleVersion("application/vnd.cellar.{version}+json", "v2", "GET", "/bottles/:id", ctrl.MuxHandler("show", h, nil))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "Show", "route", "GET /bottles/:id", "version", "v2")`
+
+	compressMount = `	h = goa.Compress(h, 1024, "br", "gzip")
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))`
+
+	dedupeMount = `	service.Mux.Handle("POST", "/accounts/:accountID/bottles", service.Dedupe(time.Duration(600000000000), ctrl.MuxHandler("create", h, nil)))`
+
+	checkBodyMount = `	service.Mux.Handle("POST", "/accounts/:accountID/bottles", service.CheckRequestBody(true, 1024, []string{"application/octet-stream"}, ctrl.MuxHandler("create", h, nil)))`
+
+	cacheableMount = `	service.Mux.Handle("POST", "/accounts/:accountID/bottles", service.ReplayIdempotent(ctrl.MuxHandler("create", h, nil)))`
+
+	payloadNoValidationUnmarshal = `
+func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	payload := &listBottlePayload{}
+	if err := service.DecodeRequest(req, payload); err != nil {
+		return err
+	}
+	goa.ContextRequest(ctx).Payload = payload.Publicize()
+	return nil
+}
+`
+
+	payloadEnvelopeUnmarshal = `
+func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	payload := &listBottlePayload{}
+	env := &goa.Envelope{DataField: "data", MetaField: "meta", Data: payload}
+	if err := service.DecodeRequest(req, env); err != nil {
+		return err
+	}
+	goa.ContextRequest(ctx).Meta = env.Meta
+	goa.ContextRequest(ctx).Payload = payload.Publicize()
+	return nil
+}
+`
+
+	payloadVersionUnmarshal = `	payload := &listBottlePayload{}
+	if err := service.DecodeRequest(req, payload); err != nil {
+		return err
+	}
+	payload.Rating = nil
+	goa.ContextRequest(ctx).Payload = payload.Publicize()
+`
+
+	payloadSignatureUnmarshal = `
+func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	goa.LimitRequestBody(ctx, req, 1024)
+	if err := service.VerifySignature(ctx, req, "X-Signature"); err != nil {
+		return err
+	}
+	payload := &listBottlePayload{}
+	if err := service.DecodeRequest(req, payload); err != nil {
+		return err
+	}
+	goa.ContextRequest(ctx).Payload = payload.Publicize()
+	return nil
+}
+`
+
+	eventEmitterInterface = `// BottlesEventEmitter dispatches the domain events emitted by the Bottles actions. The
+// events set in the action contexts are dispatched once the actions succeed if the controller
+// implements this interface.
+type BottlesEventEmitter interface {
+	EmitBottleCreated(ctx context.Context, event *BottleCreated) error
+}
+`
+
+	eventEmitterMount = `	emitter, _ := ctrl.(BottlesEventEmitter)
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewCreateBottleContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		if err := ctrl.Create(rctx); err != nil {
+			return err
+		}
+		if emitter == nil || rctx.ResponseData.Status >= 400 {
+			return nil
+		}
+		if rctx.BottleCreatedEvent != nil {
+			if err := emitter.EmitBottleCreated(rctx, rctx.BottleCreatedEvent); err != nil {
+				goa.LogError(rctx, "failed to emit event", "event", "BottleCreated", "err", err)
+			}
+		}
+		return nil
+	}
+`
+
 	simpleFileServer = `// PublicController is the controller interface for the Public actions.
 type PublicController interface {
 	goa.Muxer
@@ -2520,7 +4627,7 @@ func handleBottlesOrigin(h goa.Handler) goa.Handler {
 		if cors.MatchOrigin(origin, "here.example.com") {
 			ctx = goa.WithLogContext(ctx, "origin", origin)
 			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
+			rw.Header().Add("Vary", "Origin")
 			rw.Header().Set("Access-Control-Expose-Headers", "X-Three")
 			rw.Header().Set("Access-Control-Allow-Credentials", "true")
 			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
@@ -2533,7 +4640,7 @@ func handleBottlesOrigin(h goa.Handler) goa.Handler {
 		if cors.MatchOrigin(origin, "there.example.com") {
 			ctx = goa.WithLogContext(ctx, "origin", origin)
 			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
+			rw.Header().Add("Vary", "Origin")
 			rw.Header().Set("Access-Control-Allow-Credentials", "false")
 			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
 				// We are handling a preflight request
@@ -2543,6 +4650,8 @@ func handleBottlesOrigin(h goa.Handler) goa.Handler {
 			return h(ctx, rw, req)
 		}
 
+		// The origin is not allowed, the response does not include the CORS headers.
+		rw.Header().Add("Vary", "Origin")
 		return h(ctx, rw, req)
 	}
 }
@@ -2561,7 +4670,7 @@ func handleBottlesOrigin(h goa.Handler) goa.Handler {
 		if cors.MatchOriginRegexp(origin, spec0) {
 			ctx = goa.WithLogContext(ctx, "origin", origin)
 			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
+			rw.Header().Add("Vary", "Origin")
 			rw.Header().Set("Access-Control-Expose-Headers", "X-Three")
 			rw.Header().Set("Access-Control-Allow-Credentials", "true")
 			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
@@ -2574,7 +4683,7 @@ func handleBottlesOrigin(h goa.Handler) goa.Handler {
 		if cors.MatchOrigin(origin, "there.example.com") {
 			ctx = goa.WithLogContext(ctx, "origin", origin)
 			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
+			rw.Header().Add("Vary", "Origin")
 			rw.Header().Set("Access-Control-Allow-Credentials", "false")
 			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
 				// We are handling a preflight request
@@ -2584,6 +4693,8 @@ func handleBottlesOrigin(h goa.Handler) goa.Handler {
 			return h(ctx, rw, req)
 		}
 
+		// The origin is not allowed, the response does not include the CORS headers.
+		rw.Header().Add("Vary", "Origin")
 		return h(ctx, rw, req)
 	}
 }
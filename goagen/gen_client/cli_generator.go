@@ -154,13 +154,13 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 
 	file.Write([]byte("type (\n"))
 	var fs []*design.FileServerDefinition
-	if err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+	for _, res := range g.API.SortedResources() {
 		fs = append(fs, res.FileServers...)
-		return res.IterateActions(func(action *design.ActionDefinition) error {
+		if err = res.IterateActions(func(action *design.ActionDefinition) error {
 			return commandTypesTmpl.Execute(file, action)
-		})
-	}); err != nil {
-		return err
+		}); err != nil {
+			return err
+		}
 	}
 	if len(fs) > 0 {
 		file.Write([]byte(downloadCommandType))
@@ -169,11 +169,11 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 
 	actions := make(map[string][]*design.ActionDefinition)
 	hasDownloads := false
-	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+	for _, res := range g.API.SortedResources() {
 		if len(res.FileServers) > 0 {
 			hasDownloads = true
 		}
-		return res.IterateActions(func(action *design.ActionDefinition) error {
+		res.IterateActions(func(action *design.ActionDefinition) error {
 			name := codegen.Goify(action.Name, false)
 			if as, ok := actions[name]; ok {
 				actions[name] = append(as, action)
@@ -182,7 +182,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 			}
 			return nil
 		})
-	})
+	}
 	data := struct {
 		Actions      map[string][]*design.ActionDefinition
 		Package      string
@@ -197,7 +197,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	}
 
 	var fsdata []map[string]interface{}
-	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+	for _, res := range g.API.SortedResources() {
 		if res.FileServers != nil {
 			res.IterateFileServers(func(fs *design.FileServerDefinition) error {
 				wcs := design.ExtractWildcards(fs.RequestPath)
@@ -219,8 +219,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 				return nil
 			})
 		}
-		return nil
-	})
+	}
 	if fsdata != nil {
 		data := struct {
 			Package     string
@@ -233,8 +232,8 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 			return err
 		}
 	}
-	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
-		return res.IterateActions(func(action *design.ActionDefinition) error {
+	for _, res := range g.API.SortedResources() {
+		err = res.IterateActions(func(action *design.ActionDefinition) error {
 			data := map[string]interface{}{
 				"Action":          action,
 				"Resource":        action.Parent,
@@ -254,7 +253,10 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 			err = registerTmpl.Execute(file, data)
 			return err
 		})
-	})
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -321,7 +323,10 @@ func joinNames(useNil bool, atts ...*design.AttributeDefinition) string {
 		for _, n := range keys {
 			a := obj[n]
 			field := fmt.Sprintf("cmd.%s", codegen.Goify(n, true))
-			if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) {
+			if a.IsDeepObject() {
+				// The command line does not support object params
+				field = "nil"
+			} else if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) {
 				if useNil {
 					field = flagTypeVal(a, n, field)
 				} else {
@@ -702,9 +707,9 @@ const commandTypesTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Name (titl
 		ContentType string
 {{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false }}
-{{ end }}{{ end }}{{ $params := .QueryParams }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
+{{ end }}{{ end }}{{ $params := .QueryParams }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if not $att.IsDeepObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
-{{ end }}{{ end }}{{ $headers := .Headers }}{{ if $headers }}{{ range $name, $att := $headers.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
+{{ end }}{{ end }}{{ end }}{{ $headers := .Headers }}{{ if $headers }}{{ range $name, $att := $headers.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
 {{ end }}{{ end }}		PrettyPrint bool
 	}
@@ -801,11 +806,11 @@ func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Cl
 */}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam.Type false }}
 {{ end }}	cc.Flags().{{ flagType $pparam }}Var(&cmd.{{ goify $pname true }}, "{{ $pname }}", {{/*
 */}}{{ if $pparam.DefaultValue }}{{ defaultVal $pparam }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $pparam.Description }}` + "`" + `)
-{{ end }}{{ end }}{{ $params := .Action.QueryParams }}{{ if $params }}{{ range $name, $param := $params.Type.ToObject }}{{ $tmp := goify $name false }}{{/*
+{{ end }}{{ end }}{{ $params := .Action.QueryParams }}{{ if $params }}{{ range $name, $param := $params.Type.ToObject }}{{ if not $param.IsDeepObject }}{{ $tmp := goify $name false }}{{/*
 */}}{{ if not $param.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $param.Type false }}
 {{ end }}	cc.Flags().{{ flagType $param }}Var(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
 */}}{{ if $param.DefaultValue }}{{ defaultVal $param }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $param.Description }}` + "`" + `)
-{{ end }}{{ end }}{{ $headers := .Action.Headers }}{{ if $headers }}{{ range $name, $header := $headers.Type.ToObject }}{{/*
+{{ end }}{{ end }}{{ end }}{{ $headers := .Action.Headers }}{{ if $headers }}{{ range $name, $header := $headers.Type.ToObject }}{{/*
 */}} cc.Flags().StringVar(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
 */}}{{ if $header.DefaultValue }}{{ defaultVal $header }}{{ else }}""{{ end }}, ` + "`" + `{{ escapeBackticks $header.Description }}` + "`" + `)
 {{ end }}{{ end }}}`
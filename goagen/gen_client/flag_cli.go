@@ -0,0 +1,508 @@
+package genclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	goformat "go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+type (
+	// cliCommand is the data used to render the subcommand of an action.
+	cliCommand struct {
+		// Name is the name of the subcommand.
+		Name string
+		// Description describes the subcommand.
+		Description string
+		// Flags lists the subcommand flags.
+		Flags []*cliFlag
+		// RunFunc is the name of the function that sends the request.
+		RunFunc string
+		// ClientFunc is the name of the client method that sends the request.
+		ClientFunc string
+		// PathFunc is the name of the client function that builds the request path.
+		PathFunc string
+		// PathVars lists the arguments of PathFunc.
+		PathVars []*cliVar
+		// PayloadType is the Go type of the payload if any.
+		PayloadType string
+		// PayloadRef is the expression used to pass the payload to the client method.
+		PayloadRef string
+		// PayloadFields lists the names of the flags that set the payload attributes when the
+		// payload is an object, nil if the payload is set with a single "payload" flag.
+		PayloadFields []string
+		// Params lists the query string and header arguments of the client method.
+		Params []*cliVar
+	}
+
+	// cliFlag describes a subcommand flag.
+	cliFlag struct {
+		Name     string
+		Kind     string
+		Usage    string
+		Required bool
+		Default  string
+	}
+
+	// cliVar describes a variable initialized from a flag value.
+	cliVar struct {
+		// Name is the name of the flag.
+		Name string
+		// VarName is the name of the variable.
+		VarName string
+		// Type is the Go type of the variable.
+		Type string
+		// Nil is true if the value cannot be set from the command line, nil is used instead.
+		Nil bool
+	}
+)
+
+// GenerateCLI returns the source code of a command line tool for the given API indexed by file
+// name. clientPkg is the import path of the package produced by the client generator. The tool
+// uses the standard library flag package and defines one subcommand per action named after the
+// action and its resource, e.g. "show-bottle". The flags of a subcommand set the action path and
+// query string parameters, headers and payload attributes, their types, default values and
+// required markers are derived from the design. Attributes that are arrays, hashes or objects are
+// set with JSON values. Payloads that are not objects are set with a single "payload" flag.
+// Successful responses are printed to stdout and JSON bodies are indented. WebSocket actions are
+// not supported and are skipped.
+func GenerateCLI(api *design.APIDefinition, clientPkg string) (map[string][]byte, error) {
+	elems := strings.Split(clientPkg, "/")
+	pkg := elems[len(elems)-1]
+	var commands []*cliCommand
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			cmd, err := newCLICommand(a, pkg)
+			if err != nil {
+				return err
+			}
+			commands = append(commands, cmd)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hasBasicAuthSigners, hasAPIKeySigners, hasTokenSigners bool
+	for _, s := range api.SecuritySchemes {
+		switch s.Kind {
+		case design.BasicAuthSecurityKind:
+			hasBasicAuthSigners = true
+		case design.APIKeySecurityKind:
+			hasAPIKeySigners = true
+		case design.JWTSecurityKind, design.OAuth2SecurityKind:
+			hasTokenSigners = true
+		}
+	}
+	apiVersion := api.Version
+	if apiVersion == "" {
+		apiVersion = "0"
+	}
+	funcs := template.FuncMap{
+		"goify":      codegen.Goify,
+		"signerType": signerType,
+	}
+	files := make(map[string][]byte)
+	src, err := renderCLI("cliMain", cliMainT, funcs, map[string]interface{}{
+		"API":                 api,
+		"Name":                defaultToolName(api),
+		"Version":             apiVersion,
+		"Package":             pkg,
+		"ClientPkg":           clientPkg,
+		"HasBasicAuthSigners": hasBasicAuthSigners,
+		"HasAPIKeySigners":    hasAPIKeySigners,
+		"HasTokenSigners":     hasTokenSigners,
+		"ToolVersion":         version.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	files["main.go"] = src
+	src, err = renderCLI("cliCommands", cliCommandsT, funcs, map[string]interface{}{
+		"API":         api,
+		"Commands":    commands,
+		"Package":     pkg,
+		"ClientPkg":   clientPkg,
+		"HasMulti":    len(api.Consumes) > 1,
+		"ToolVersion": version.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	files["commands.go"] = src
+	return files, nil
+}
+
+// newCLICommand computes the subcommand of the given action.
+func newCLICommand(a *design.ActionDefinition, pkg string) (*cliCommand, error) {
+	clientFunc := codegen.Goify(a.Name+strings.Title(a.Parent.Name), true)
+	cmd := &cliCommand{
+		Name:        codegen.KebabCase(a.Name) + "-" + codegen.KebabCase(a.Parent.Name),
+		Description: a.Description,
+		RunFunc:     "run" + clientFunc,
+		ClientFunc:  clientFunc,
+		PathFunc:    clientFunc + "Path",
+	}
+	if cmd.Description == "" {
+		cmd.Description = fmt.Sprintf("%s %s", a.Name, a.Parent.Name)
+	}
+	vars := map[string]bool{"ctx": true, "c": true, "fs": true, "err": true, "payload": true, pkg: true}
+	varName := func(name string) string {
+		v := codegen.Goify(name, false)
+		for i := 2; vars[v]; i++ {
+			v = codegen.Goify(name, false) + strconv.Itoa(i)
+		}
+		vars[v] = true
+		return v
+	}
+	flags := make(map[string]bool)
+	addFlag := func(name string, att *design.AttributeDefinition, required bool) error {
+		if flags[name] {
+			return fmt.Errorf("%s: cannot generate CLI, flag %#v is defined multiple times", a.Context(), name)
+		}
+		flags[name] = true
+		cmd.Flags = append(cmd.Flags, newCLIFlag(name, att, required))
+		return nil
+	}
+
+	// Path parameters
+	var params design.Object
+	if a.Params != nil {
+		params = a.Params.Type.ToObject()
+	}
+	inPath := make(map[string]bool)
+	for _, p := range a.Routes[0].Params() {
+		att := params[p]
+		inPath[p] = true
+		if err := addFlag(p, att, true); err != nil {
+			return nil, err
+		}
+		cmd.PathVars = append(cmd.PathVars, &cliVar{Name: p, VarName: varName(p), Type: cmdFieldType(att.Type, false)})
+	}
+
+	// Query string parameters and headers
+	for _, att := range []*design.AttributeDefinition{a.QueryParams, a.Headers} {
+		req, opt := initParams(att)
+		sort.Sort(byParamName(req))
+		sort.Sort(byParamName(opt))
+		for _, p := range append(req, opt...) {
+			if inPath[p.Name] {
+				continue
+			}
+			required := att.IsRequired(p.Name)
+			if p.Attribute.IsDeepObject() {
+				// The command line does not support deep object parameters
+				cmd.Params = append(cmd.Params, &cliVar{Name: p.Name, Nil: true})
+				continue
+			}
+			if err := addFlag(p.Name, p.Attribute, required); err != nil {
+				return nil, err
+			}
+			cmd.Params = append(cmd.Params, &cliVar{
+				Name:    p.Name,
+				VarName: varName(p.Name),
+				Type:    paramFieldType(p.Attribute, !required && p.Attribute.Type.IsPrimitive()),
+			})
+		}
+	}
+
+	// Payload
+	if p := a.Payload; p != nil {
+		cmd.PayloadType = goTypeRefExt(p, 1, pkg)
+		cmd.PayloadRef = "payload"
+		if p.Type.IsObject() {
+			cmd.PayloadRef = "&payload"
+			obj := p.Type.ToObject()
+			names := make([]string, 0, len(obj))
+			for n := range obj {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			for _, n := range names {
+				if err := addFlag(n, obj[n], !a.PayloadOptional && p.IsRequired(n)); err != nil {
+					return nil, err
+				}
+			}
+			cmd.PayloadFields = names
+		} else if err := addFlag("payload", p.AttributeDefinition, !a.PayloadOptional); err != nil {
+			return nil, err
+		}
+	}
+	return cmd, nil
+}
+
+// newCLIFlag computes the flag used to set the given attribute.
+func newCLIFlag(name string, att *design.AttributeDefinition, required bool) *cliFlag {
+	f := &cliFlag{Name: name, Kind: "JSONFlag", Usage: att.Description, Required: required}
+	t := att.Type
+	if ds, ok := t.(design.DataStructure); ok {
+		t = ds.Definition().Type
+	}
+	switch t.Kind() {
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.FileKind:
+		f.Kind = "StringFlag"
+	case design.IntegerKind:
+		f.Kind = "IntegerFlag"
+	case design.NumberKind:
+		f.Kind = "NumberFlag"
+	case design.BooleanKind:
+		f.Kind = "BooleanFlag"
+	}
+	if att.DefaultValue != nil {
+		if f.Kind == "JSONFlag" {
+			b, _ := json.Marshal(att.DefaultValue)
+			f.Default = string(b)
+		} else if f.Kind == "IntegerFlag" {
+			switch v := att.DefaultValue.(type) {
+			case float64:
+				f.Default = strconv.FormatInt(int64(v), 10)
+			default:
+				f.Default = fmt.Sprint(v)
+			}
+		} else {
+			f.Default = fmt.Sprint(att.DefaultValue)
+		}
+	}
+	return f
+}
+
+// renderCLI renders the given template, removes the unused imports and formats the result.
+func renderCLI(name, source string, funcs template.FuncMap, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(codegen.DefaultFuncMap).Funcs(funcs).Parse(source)
+	if err != nil {
+		panic(err) // bug
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name+".go", buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("%s\n========\nContent:\n%s", err, buf.String())
+	}
+	for _, group := range astutil.Imports(fset, file) {
+		for _, imp := range group {
+			path := strings.Trim(imp.Path.Value, `"`)
+			if astutil.UsesImport(file, path) {
+				continue
+			}
+			if imp.Name != nil {
+				astutil.DeleteNamedImport(fset, file, imp.Name.Name, path)
+			} else {
+				astutil.DeleteImport(fset, file, path)
+			}
+		}
+	}
+	ast.SortImports(fset, file)
+	var out bytes.Buffer
+	if err := goformat.Node(&out, fset, file); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+const cliMainT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }} command line tool
+//
+// Command:
+{{ comment commandLine }}
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	goaclient "github.com/goadesign/goa/client"
+	{{ printf "%q" .ClientPkg }}
+)
+
+// command is a subcommand of the command line tool.
+type command struct {
+	// Name is the name of the subcommand.
+	Name string
+	// Description describes the subcommand.
+	Description string
+	// Flags lists the subcommand flags.
+	Flags []*goaclient.Flag
+	// Run sends the request using the subcommand flag values.
+	Run func(ctx context.Context, c *{{ .Package }}.Client, fs *goaclient.FlagSet) (*http.Response, error)
+}
+
+func main() {
+	global := flag.NewFlagSet({{ printf "%q" .Name }}, flag.ExitOnError)
+	var (
+		scheme  = global.String("scheme", "", "Set the requests scheme")
+		host    = global.String("host", {{ printf "%q" .API.Host }}, "API hostname")
+		timeout = global.Duration("timeout", 20*time.Second, "Set the request timeout")
+		dump    = global.Bool("dump", false, "Dump HTTP request and response")
+{{ if .HasBasicAuthSigners }}		user    = global.String("user", "", "Username used for authentication")
+		pass    = global.String("pass", "", "Password used for authentication")
+{{ end }}{{ if .HasAPIKeySigners }}		key     = global.String("key", "", "API key used for authentication")
+		format  = global.String("format", "Bearer %s", "Format used to create auth header or query from key")
+{{ end }}{{ if .HasTokenSigners }}		token   = global.String("token", "", "Token used for authentication")
+		typ     = global.String("token-type", "Bearer", "Token type used for authentication")
+{{ end }}	)
+	global.Usage = func() { usage(global) }
+	global.Parse(os.Args[1:])
+	args := global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+	var cmd *command
+	for _, c := range commands {
+		if c.Name == args[0] {
+			cmd = c
+			break
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		global.Usage()
+		os.Exit(2)
+	}
+	fs := goaclient.NewFlagSet(cmd.Name, cmd.Flags...)
+	if err := fs.Parse(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+
+	c := {{ .Package }}.New(goaclient.HTTPClientDoer(&http.Client{Timeout: *timeout}))
+	c.Scheme = *scheme
+	c.Host = *host
+	c.Dump = *dump
+	c.UserAgent = "{{ .Name }}/{{ .Version }}"
+{{ if .HasTokenSigners }}	source := &goaclient.StaticTokenSource{
+		StaticToken: &goaclient.StaticToken{Type: *typ, Value: *token},
+	}
+{{ end }}{{ range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
+*/}}	c.Set{{ goify $security.SchemeName true }}Signer(&{{ $signer }}{{ if eq $signer "goaclient.BasicSigner" }}{Username: *user, Password: *pass}{{/*
+*/}}{{ else if eq $signer "goaclient.APIKeySigner" }}{SignQuery: {{ eq $security.In "query" }}, KeyName: {{ printf "%q" $security.Name }}, KeyValue: *key, Format: {{ if eq $security.In "query" }}"%s"{{ else }}*format{{ end }}}{{/*
+*/}}{{ else }}{TokenSource: source}{{ end }})
+{{ end }}{{ end }}
+	resp, err := cmd.Run(context.Background(), c, fs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := printResponse(os.Stdout, resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the command line tool usage.
+func usage(global *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] COMMAND [command flags]\n\nFlags:\n", global.Name())
+	global.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n    \t%s\n", cmd.Name, cmd.Description)
+	}
+}
+
+// printResponse writes the response body to w, JSON bodies are indented. It returns an error if
+// the response status is not a success status.
+func printResponse(w io.Writer, resp *http.Response) error {
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %s", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error: %s: %s", resp.Status, body)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(body), "", "    "); err != nil {
+		_, err = w.Write(body)
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(w)
+	return err
+}
+`
+
+const cliCommandsT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }} command line tool commands
+//
+// Command:
+{{ comment commandLine }}
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	goaclient "github.com/goadesign/goa/client"
+	"github.com/goadesign/goa/uuid"
+	{{ printf "%q" .ClientPkg }}
+)
+
+// commands lists the subcommands of the command line tool.
+var commands = []*command{
+{{ range .Commands }}	{
+		Name:        {{ printf "%q" .Name }},
+		Description: {{ printf "%q" .Description }},
+		Flags: []*goaclient.Flag{
+{{ range .Flags }}			{Name: {{ printf "%q" .Name }}, Kind: goaclient.{{ .Kind }}{{ if .Usage }}, Usage: {{ printf "%q" .Usage }}{{ end }}{{ if .Required }}, Required: true{{ end }}{{ if .Default }}, Default: {{ printf "%q" .Default }}{{ end }}},
+{{ end }}		},
+		Run: {{ .RunFunc }},
+	},
+{{ end }}}
+{{ $pkg := .Package }}{{ $multi := .HasMulti }}{{ range .Commands }}
+// {{ .RunFunc }} sends the request of the {{ .Name }} command.
+func {{ .RunFunc }}(ctx context.Context, c *{{ $pkg }}.Client, fs *goaclient.FlagSet) (*http.Response, error) {
+{{ range .PathVars }}	var {{ .VarName }} {{ .Type }}
+	if err := fs.Value({{ printf "%q" .Name }}, &{{ .VarName }}); err != nil {
+		return nil, err
+	}
+{{ end }}{{ if .PayloadType }}	var payload {{ .PayloadType }}
+{{ if .PayloadFields }}	if err := fs.Decode(&payload{{ range .PayloadFields }}, {{ printf "%q" . }}{{ end }}); err != nil {
+{{ else }}	if err := fs.Value("payload", &payload); err != nil {
+{{ end }}		return nil, err
+	}
+{{ end }}{{ range .Params }}{{ if not .Nil }}	var {{ .VarName }} {{ .Type }}
+	if err := fs.Value({{ printf "%q" .Name }}, &{{ .VarName }}); err != nil {
+		return nil, err
+	}
+{{ end }}{{ end }}	return c.{{ .ClientFunc }}(ctx, {{ $pkg }}.{{ .PathFunc }}({{ range $i, $v := .PathVars }}{{ if $i }}, {{ end }}{{ $v.VarName }}{{ end }}){{/*
+*/}}{{ if .PayloadType }}, {{ .PayloadRef }}{{ end }}{{ range .Params }}, {{ if .Nil }}nil{{ else }}{{ .VarName }}{{ end }}{{ end }}{{/*
+*/}}{{ if and .PayloadType $multi }}, ""{{ end }})
+}
+{{ end }}`
@@ -0,0 +1,153 @@
+package genclient_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateCLI", func() {
+	var files map[string][]byte
+	var genErr error
+
+	BeforeEach(func() {
+		resetDesign()
+		API("cellar", func() {
+			Host("localhost:8081")
+			Security(JWTSecurity("jwt", func() { Header("Authorization") }))
+		})
+		Resource("bottle", func() {
+			Action("create", func() {
+				Description("Create a bottle")
+				Routing(POST("/accounts/:accountID/bottles"))
+				Params(func() {
+					Param("accountID", Integer)
+					Param("dry", Boolean)
+				})
+				Headers(func() {
+					Header("X-Request-Id")
+				})
+				Payload(func() {
+					Attribute("name", String, "Bottle name")
+					Attribute("vintage", Integer, func() { Default(2012) })
+					Attribute("tags", ArrayOf(String))
+					Attribute("details", func() {
+						Attribute("color", String)
+					})
+					Required("name")
+				})
+				Response(Created)
+			})
+			Action("rate", func() {
+				Routing(PUT("/bottles/:id/rating"))
+				Params(func() {
+					Param("id", String)
+				})
+				Payload(Integer)
+				Response(NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		files, genErr = genclient.GenerateCLI(Design, "github.com/goadesign/cellar/client")
+	})
+
+	It("generates the main and commands files", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(2))
+		Ω(files).Should(HaveKey("main.go"))
+		Ω(files).Should(HaveKey("commands.go"))
+		for name, src := range files {
+			_, err := parser.ParseFile(token.NewFileSet(), name, src, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+	})
+
+	It("sets the signers and prints the responses", func() {
+		main := string(files["main.go"])
+		Ω(main).Should(ContainSubstring(`"github.com/goadesign/cellar/client"`))
+		Ω(main).Should(ContainSubstring(`host    = global.String("host", "localhost:8081", "API hostname")`))
+		Ω(main).Should(ContainSubstring("c.SetJWTSigner(&goaclient.JWTSigner{TokenSource: source})"))
+		Ω(main).Should(ContainSubstring("printResponse(os.Stdout, resp)"))
+		Ω(main).ShouldNot(ContainSubstring(`"github.com/goadesign/goa/uuid"`))
+	})
+
+	It("derives the flags from the params, headers and payload attributes", func() {
+		Ω(string(files["commands.go"])).Should(ContainSubstring(cliCreateFlags))
+	})
+
+	It("decodes the flags into the request arguments", func() {
+		Ω(string(files["commands.go"])).Should(ContainSubstring(cliCreateRun))
+	})
+
+	It("sets payloads that are not objects with a single flag", func() {
+		commands := string(files["commands.go"])
+		Ω(commands).Should(ContainSubstring(`{Name: "payload", Kind: goaclient.IntegerFlag, Required: true},`))
+		Ω(commands).Should(ContainSubstring(`	var payload client.RateBottlePayload
+	if err := fs.Value("payload", &payload); err != nil {`))
+		Ω(commands).Should(ContainSubstring(`return c.RateBottle(ctx, client.RateBottlePath(id), payload, "")`))
+	})
+
+	Context("with a payload attribute named after a param", func() {
+		BeforeEach(func() {
+			Resource("account", func() {
+				Action("update", func() {
+					Routing(PUT("/accounts/:name"))
+					Params(func() {
+						Param("name", String)
+					})
+					Payload(func() {
+						Attribute("name", String)
+					})
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`flag "name" is defined multiple times`))
+		})
+	})
+})
+
+const cliCreateFlags = `		Name:        "create-bottle",
+		Description: "Create a bottle",
+		Flags: []*goaclient.Flag{
+			{Name: "accountID", Kind: goaclient.IntegerFlag, Required: true},
+			{Name: "dry", Kind: goaclient.BooleanFlag},
+			{Name: "X-Request-Id", Kind: goaclient.StringFlag},
+			{Name: "details", Kind: goaclient.JSONFlag},
+			{Name: "name", Kind: goaclient.StringFlag, Usage: "Bottle name", Required: true},
+			{Name: "tags", Kind: goaclient.JSONFlag},
+			{Name: "vintage", Kind: goaclient.IntegerFlag, Default: "2012"},
+		},
+		Run: runCreateBottle,`
+
+const cliCreateRun = `func runCreateBottle(ctx context.Context, c *client.Client, fs *goaclient.FlagSet) (*http.Response, error) {
+	var accountID int
+	if err := fs.Value("accountID", &accountID); err != nil {
+		return nil, err
+	}
+	var payload client.CreateBottlePayload
+	if err := fs.Decode(&payload, "details", "name", "tags", "vintage"); err != nil {
+		return nil, err
+	}
+	var dry *bool
+	if err := fs.Value("dry", &dry); err != nil {
+		return nil, err
+	}
+	var xRequestID *string
+	if err := fs.Value("X-Request-Id", &xRequestID); err != nil {
+		return nil, err
+	}
+	return c.CreateBottle(ctx, client.CreateBottlePath(accountID), &payload, dry, xRequestID, "")
+}`
@@ -33,23 +33,27 @@ func NewGenerator(options ...Option) *Generator {
 
 // Generator is the application code generator.
 type Generator struct {
-	API            *design.APIDefinition // The API definition
-	OutDir         string                // Path to output directory
-	Target         string                // Name of generated package
-	ToolDirName    string                // Name of tool directory where CLI main is generated once
-	Tool           string                // Name of CLI tool
-	NoTool         bool                  // Whether to skip tool generation
-	genfiles       []string
-	encoders       []*genapp.EncoderTemplateData
-	decoders       []*genapp.EncoderTemplateData
-	encoderImports []string
+	API              *design.APIDefinition // The API definition
+	OutDir           string                // Path to output directory
+	Target           string                // Name of generated package
+	ToolDirName      string                // Name of tool directory where CLI main is generated once
+	Tool             string                // Name of CLI tool
+	NoTool           bool                  // Whether to skip tool generation
+	LegacySignatures bool                  // Whether to generate decode functions that don't accept a context
+	Resources        []string              // Names of the resources to generate the client for, all if empty
+	genfiles         []string
+	encoders         []*genapp.EncoderTemplateData
+	decoders         []*genapp.EncoderTemplateData
+	encoderImports   []string
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, target, toolDir, tool, ver string
-		notool, regen                      bool
+		header, buildTags                              string
+		outDir, target, toolDir, tool, ver, modulePath string
+		resources                                      string
+		notool, regen, legacySig                       bool
 	)
 	dtool := defaultToolName(design.Design)
 
@@ -61,10 +65,26 @@ func Generate() (files []string, err error) {
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&legacySig, "legacy-signatures", false, "")
+	set.StringVar(&resources, "client-resources", "", "")
+	set.String("openapi-ui", "", "")
+	set.String("openapi-spec", "", "")
+	set.String("openapi-ui-assets", "", "")
 	set.String("design", "", "")
 	set.Bool("force", false, "")
 	set.Bool("notest", false, "")
+	set.StringVar(&modulePath, "module-path", "", "")
+	set.StringVar(&header, "header", "", "")
+	set.StringVar(&buildTags, "build-tags", "", "")
 	set.Parse(os.Args[1:])
+	if err := codegen.ConfigureHeader(header, buildTags); err != nil {
+		return nil, err
+	}
+	if modulePath != "" {
+		if err := codegen.MapImportPath(outDir, modulePath); err != nil {
+			return nil, err
+		}
+	}
 
 	// First check compatibility
 	if err := codegen.CheckVersion(ver); err != nil {
@@ -73,7 +93,18 @@ func Generate() (files []string, err error) {
 
 	// Now proceed
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, ToolDirName: toolDir, Tool: tool, NoTool: notool, API: design.Design}
+	g := &Generator{
+		OutDir:           outDir,
+		Target:           target,
+		ToolDirName:      toolDir,
+		Tool:             tool,
+		NoTool:           notool,
+		LegacySignatures: legacySig,
+		API:              design.Design,
+	}
+	if resources != "" {
+		g.Resources = strings.Split(resources, ",")
+	}
 
 	return g.Generate()
 }
@@ -83,6 +114,11 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if g.API == nil {
 		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
 	}
+	if len(g.Resources) > 0 {
+		if g.API, err = restrictAPI(g.API, g.Resources); err != nil {
+			return nil, err
+		}
+	}
 
 	go utils.Catch(nil, func() { g.Cleanup() })
 
@@ -153,6 +189,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"gotyperefext":       goTypeRefExt,
 			"join":               join,
 			"joinStrings":        strings.Join,
+			"jsonMarshaler":      codegen.JSONMarshaler,
 			"multiComment":       multiComment,
 			"pathParams":         pathParams,
 			"pathTemplate":       pathTemplate,
@@ -209,6 +246,47 @@ func (g *Generator) Generate() (_ []string, err error) {
 	return g.genfiles, nil
 }
 
+// restrictAPI returns a copy of the given API definition that only contains the resources with
+// the given names and the user types and media types they use directly or transitively so that
+// the generated client package is self-contained. It returns an error if a resource does not
+// exist.
+func restrictAPI(api *design.APIDefinition, names []string) (*design.APIDefinition, error) {
+	res := *api
+	res.Resources = make(map[string]*design.ResourceDefinition, len(names))
+	res.Types = make(map[string]*design.UserTypeDefinition)
+	res.MediaTypes = make(map[string]*design.MediaTypeDefinition)
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		r, ok := api.Resources[n]
+		if !ok {
+			return nil, fmt.Errorf("unknown resource %#v, API %s defines %s", n, api.Name, strings.Join(sortedResourceNames(api), ", "))
+		}
+		res.Resources[n] = r
+		types, mediaTypes := r.TypeClosure()
+		for tn, ut := range types {
+			if _, ok := api.Types[tn]; ok {
+				res.Types[tn] = ut
+			}
+		}
+		for id, mt := range mediaTypes {
+			if _, ok := api.MediaTypes[id]; ok {
+				res.MediaTypes[id] = mt
+			}
+		}
+	}
+	return &res, nil
+}
+
+// sortedResourceNames returns the sorted names of the resources of the given API.
+func sortedResourceNames(api *design.APIDefinition) []string {
+	names := make([]string, 0, len(api.Resources))
+	for n := range api.Resources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func defaultToolName(api *design.APIDefinition) string {
 	if api == nil {
 		return ""
@@ -349,6 +427,12 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("golang.org/x/net/websocket"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
+	res.IterateActions(func(a *design.ActionDefinition) error {
+		if a.Payload != nil {
+			imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
+		}
+		return nil
+	})
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
 		return err
@@ -366,7 +450,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		if action.Payload != nil {
 			found := false
 			typeName := action.Payload.TypeName
-			for _, t := range design.Design.Types {
+			for _, t := range g.API.Types {
 				if t.TypeName == typeName {
 					found = true
 					break
@@ -479,13 +563,21 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		// Update closure
 		for _, p := range reqData {
 			names = append(names, p.VarName)
-			params = append(params, p.VarName+" "+cmdFieldType(p.Attribute.Type, false))
+			params = append(params, p.VarName+" "+paramFieldType(p.Attribute, false))
 		}
 		for _, p := range optData {
 			names = append(names, p.VarName)
-			params = append(params, p.VarName+" "+cmdFieldType(p.Attribute.Type, p.Attribute.Type.IsPrimitive()))
+			params = append(params, p.VarName+" "+paramFieldType(p.Attribute, p.Attribute.Type.IsPrimitive()))
+		}
+		var res []*paramData
+		for _, p := range append(reqData, optData...) {
+			if p.Attribute.IsDeepObject() {
+				res = append(res, deepObjectParams(p)...)
+				continue
+			}
+			res = append(res, p)
 		}
-		return append(reqData, optData...)
+		return res
 	}
 	queryParams = initParamsScoped(action.QueryParams)
 	headers = initParamsScoped(action.Headers)
@@ -571,6 +663,7 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 	funcs["decodegotyperef"] = decodeGoTypeRef
 	funcs["decodegotypename"] = decodeGoTypeName
 	typeDecodeTmpl := template.Must(template.New("typeDecode").Funcs(funcs).Parse(typeDecodeTmpl))
+	typeStreamTmpl := template.Must(template.New("typeStream").Funcs(funcs).Parse(typeStreamTmpl))
 	var (
 		mtFile string
 		mtWr   *genapp.MediaTypesWriter
@@ -591,10 +684,15 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("mime"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
 	for _, v := range g.API.MediaTypes {
@@ -604,6 +702,8 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 		return err
 	}
 	g.genfiles = append(g.genfiles, mtFile)
+	streamed := streamedMediaTypes(g.API)
+	cookies := responseCookies(g.API)
 	err = g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
 		if (mt.Type.IsObject() || mt.Type.IsArray()) && !mt.IsError() {
 			if err := mtWr.Execute(mt); err != nil {
@@ -615,13 +715,107 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 			if err != nil {
 				return err
 			}
-			return typeDecodeTmpl.Execute(mtWr.SourceFile, p)
+			data := map[string]interface{}{
+				"MediaType":        p,
+				"LegacySignatures": g.LegacySignatures,
+				"ProblemDetails":   mt.IsError() && g.API.ProblemTypeBase != "",
+				"Cookies":          cookieFields(p, cookies[mt.Identifier]),
+			}
+			if err := typeDecodeTmpl.Execute(mtWr.SourceFile, data); err != nil {
+				return err
+			}
+			if streamed[mt.Identifier] && p.Type.IsArray() {
+				return typeStreamTmpl.Execute(mtWr.SourceFile, data)
+			}
+			return nil
 		})
 		return err
 	})
 	return
 }
 
+// streamedMediaTypes returns the identifiers of the media types of the success responses of the
+// actions that stream their responses as newline delimited JSON.
+func streamedMediaTypes(api *design.APIDefinition) map[string]bool {
+	streamed := make(map[string]bool)
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !a.StreamsJSON() {
+				return nil
+			}
+			for _, resp := range a.Responses {
+				if resp.Status < 200 || resp.Status > 299 {
+					continue
+				}
+				mt, ok := resp.Type.(*design.MediaTypeDefinition)
+				if !ok {
+					mt = api.MediaTypeWithIdentifier(resp.MediaType)
+				}
+				if mt != nil {
+					streamed[mt.Identifier] = true
+				}
+			}
+			return nil
+		})
+	})
+	return streamed
+}
+
+// responseCookies returns the cookies set by the responses of the API actions indexed by the
+// identifier of the response media types.
+func responseCookies(api *design.APIDefinition) map[string][]*design.CookieDefinition {
+	cookies := make(map[string][]*design.CookieDefinition)
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			for _, resp := range a.Responses {
+				if len(resp.Cookies) == 0 {
+					continue
+				}
+				mt, ok := resp.Type.(*design.MediaTypeDefinition)
+				if !ok {
+					mt = api.MediaTypeWithIdentifier(resp.MediaType)
+				}
+				if mt == nil {
+					continue
+				}
+			next:
+				for _, c := range resp.Cookies {
+					for _, existing := range cookies[mt.Identifier] {
+						if existing.Name == c.Name {
+							continue next
+						}
+					}
+					cookies[mt.Identifier] = append(cookies[mt.Identifier], c)
+				}
+			}
+			return nil
+		})
+	})
+	return cookies
+}
+
+// cookieFields returns the data used to render the code that reads the given cookies into the
+// fields of the given projected media type. Cookies whose attribute is not part of the projected
+// media type are ignored.
+func cookieFields(projected *design.MediaTypeDefinition, cookies []*design.CookieDefinition) []map[string]interface{} {
+	if !projected.Type.IsObject() || projected.IsError() {
+		return nil
+	}
+	var fields []map[string]interface{}
+	for _, c := range cookies {
+		att, ok := projected.Type.ToObject()[c.Name]
+		if !ok {
+			continue
+		}
+		fields = append(fields, map[string]interface{}{
+			"Name":    c.Name,
+			"Field":   codegen.GoifyAtt(att, c.Name, true),
+			"Pointer": projected.IsPrimitivePointer(c.Name),
+		})
+	}
+	return fields
+}
+
 // generateUserTypes iterates through the user types and generates the data structures and
 // marshaling code.
 func (g *Generator) generateUserTypes(pkgDir string) (err error) {
@@ -645,7 +839,10 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
@@ -666,6 +863,31 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 		}
 		return utWr.Execute(t)
 	})
+	if err != nil {
+		return
+	}
+	enums := g.API.NamedEnums()
+	names := make([]string, 0, len(enums))
+	for n := range enums {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err = utWr.WriteEnum(enums[n]); err != nil {
+			return
+		}
+	}
+	sets := g.API.FlagSets()
+	names = make([]string, 0, len(sets))
+	for n := range sets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err = utWr.WriteFlagSet(sets[n]); err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -885,6 +1107,40 @@ func toValueTypeName(varName, name string, att *design.AttributeDefinition) stri
 	return "*" + varName
 }
 
+// paramFieldType computes the Go type of the client method argument used to set the given param.
+func paramFieldType(att *design.AttributeDefinition, point bool) string {
+	if att.IsDeepObject() {
+		return codegen.GoTypeRef(att.Type, att.AllRequired(), 1, false)
+	}
+	return cmdFieldType(att.Type, point)
+}
+
+// deepObjectParams returns the paramData of the fields of the given object param. The fields are
+// set in the query string using the bracketed syntax, e.g. "filter[status]=active".
+func deepObjectParams(p *paramData) []*paramData {
+	obj := p.Attribute
+	if ds, ok := obj.Type.(design.DataStructure); ok {
+		obj = ds.Definition()
+	}
+	reqData, optData := initParams(obj)
+	fields := append(reqData, optData...)
+	sort.Sort(byParamName(fields))
+	for _, f := range fields {
+		varName := p.VarName + "." + codegen.GoifyAtt(f.Attribute, f.Name, true)
+		f.VarName = varName
+		f.ValueName = varName
+		if f.Attribute.Type.IsPrimitive() {
+			f.CheckNil = obj.IsPrimitivePointer(f.Name)
+			if f.CheckNil {
+				f.ValueName = "*" + varName
+			}
+		}
+		f.Name = fmt.Sprintf("%s[%s]", p.Name, f.Name)
+		f.Guard = p.VarName
+	}
+	return fields
+}
+
 // initParams returns required and optional paramData extracted from given attribute definition.
 func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 	if att == nil {
@@ -939,6 +1195,7 @@ type paramData struct {
 	MustToString  bool
 	IsArray       bool
 	CheckNil      bool
+	Guard         string
 }
 
 type byParamName []*paramData
@@ -957,13 +1214,61 @@ const (
 
 	payloadTmpl = `// {{ gotypename .Payload nil 0 false }} is the {{ .Parent.Name }} {{ .Name }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
+{{ jsonMarshaler .Payload.AttributeDefinition (gotypename .Payload nil 1 false) false }}`
+
+	typeDecodeTmpl = `{{ $mt := .MediaType }}{{ $typeName := typeName $mt }}{{ $funcName := printf "Decode%s" $typeName }}{{/*
+*/}}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.{{ if not .LegacySignatures }} Decoding
+// stops with the context error once ctx is done.{{ end }}
+func (c *Client) {{ $funcName }}({{ if not .LegacySignatures }}ctx context.Context, {{ end }}resp *http.Response) ({{ decodegotyperef $mt $mt.AllRequired 0 false }}, error) {
+{{ if .LegacySignatures }}	body := resp.Body
+{{ else }}	body := goaclient.ContextBody(ctx, resp.Body)
+{{ end }}{{ if .ProblemDetails }}	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct == goa.ProblemDetailsMediaIdentifier {
+		var problem goa.ProblemDetails
+		err := c.Decoder.Decode(&problem, body, ct)
+		return problem.ErrorResponse(), err
+	}
+{{ end }}	var decoded {{ decodegotypename $mt $mt.AllRequired 0 false }}
+	err := c.Decoder.Decode(&decoded, body, resp.Header.Get("Content-Type"))
+{{ if .Cookies }}	for _, cookie := range resp.Cookies() {
+		switch cookie.Name {
+{{ range .Cookies }}		case {{ printf "%q" .Name }}:
+{{ if .Pointer }}			value := cookie.Value
+			decoded.{{ .Field }} = &value
+{{ else }}			decoded.{{ .Field }} = cookie.Value
+{{ end }}{{ end }}		}
+	}
+{{ end }}	return {{ if $mt.IsObject }}&{{ end }}decoded, err
+}
 `
 
-	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
-func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
-	var decoded {{ decodegotypename . .AllRequired 0 false }}
-	err := c.Decoder.Decode(&decoded, resp.Body, resp.Header.Get("Content-Type"))
-	return {{ if .IsObject }}&{{ end }}decoded, err
+	typeStreamTmpl = `{{ $mt := .MediaType }}{{ $typeName := typeName $mt }}{{ $streamName := printf "%sStream" $typeName }}{{/*
+*/}}{{ $elem := $mt.Type.ToArray.ElemType }}// {{ $streamName }} decodes the elements of a {{ $typeName }} instance streamed as newline
+// delimited JSON one at a time.
+type {{ $streamName }} struct {
+	body io.ReadCloser
+	dec  *goa.NDJSONDecoder
+}
+
+// Decode{{ $streamName }} returns a stream that decodes the {{ $typeName }} elements encoded in
+// resp body as they are received.{{ if not .LegacySignatures }} Decoding stops with the context error once ctx is done.{{ end }}
+func (c *Client) Decode{{ $streamName }}({{ if not .LegacySignatures }}ctx context.Context, {{ end }}resp *http.Response) *{{ $streamName }} {
+{{ if .LegacySignatures }}	return &{{ $streamName }}{body: resp.Body, dec: goa.NewNDJSONDecoder(resp.Body)}
+{{ else }}	return &{{ $streamName }}{body: resp.Body, dec: goa.NewNDJSONDecoder(goaclient.ContextBody(ctx, resp.Body))}
+{{ end }}}
+
+// Recv decodes the next element of the stream. It returns io.EOF once all the elements have been
+// received.
+func (s *{{ $streamName }}) Recv() ({{ decodegotyperef $elem.Type $elem.AllRequired 0 false }}, error) {
+	var decoded {{ decodegotypename $elem.Type $elem.AllRequired 0 false }}
+	if err := s.dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+// Close closes the response body.
+func (s *{{ $streamName }}) Close() error {
+	return s.body.Close()
 }
 `
 
@@ -998,7 +1303,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
 {{ if .QueryParams }}	values := u.Query()
-{{ range .QueryParams }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
+{{ range .QueryParams }}{{ if .Guard }}	if {{ .Guard }} != nil {
+{{ end }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}{{/*
 
 // ARRAY
@@ -1016,6 +1322,7 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 // STRING
 */}}{{ else }}	values.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}
+{{ end }}{{ if .Guard }}	}
 {{ end }}{{ end }}	u.RawQuery = values.Encode()
 {{ end }}	url_ := u.String()
 	cfg, err := websocket.NewConfig(url_, url_)
@@ -1124,7 +1431,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
 {{ if .QueryParams }}	values := u.Query()
-{{ range .QueryParams }}{{/*
+{{ range .QueryParams }}{{ if .Guard }}	if {{ .Guard }} != nil {
+{{ end }}{{/*
 
 // ARRAY
 */}}{{ if .IsArray }}		for _, p := range {{ .VarName }} {
@@ -1145,7 +1453,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 */}}{{ else }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}	values.Set("{{ .Name }}", {{ .ValueName }})
 {{ if .CheckNil }}	}
-{{ end }}{{ end }}{{ end }}	u.RawQuery = values.Encode()
+{{ end }}{{ end }}{{ if .Guard }}	}
+{{ end }}{{ end }}	u.RawQuery = values.Encode()
 {{ end }}{{ if .HasPayload }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), &body)
 {{ else }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), nil)
 {{ end }}	if err != nil {
@@ -1202,7 +1511,10 @@ func New(c goaclient.Doer) *Client {
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}
-{{ end }}	return client
+{{ end }}	// Setup encoders and decoders registered with goa.RegisterCodec
+	goa.UseCodecs(client.Encoder, client.Decoder)
+
+	return client
 }
 
 {{range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
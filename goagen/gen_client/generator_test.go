@@ -566,6 +566,129 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with --client-resources", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			fooType := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+				},
+				TypeName: "FooPayload",
+			}
+			barType := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"count": &design.AttributeDefinition{Type: design.Integer}},
+				},
+				TypeName: "BarPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Types: map[string]*design.UserTypeDefinition{
+					"FooPayload": fooType,
+					"BarPayload": barType,
+				},
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:    "create",
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: ""}},
+								Payload: fooType,
+							},
+						},
+					},
+					"bar": {
+						Name: "bar",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:    "create",
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: ""}},
+								Payload: barType,
+							},
+						},
+					},
+				},
+			}
+			for _, res := range design.Design.Resources {
+				act := res.Actions["create"]
+				act.Parent = res
+				act.Routes[0].Parent = act
+			}
+			os.Args = append(os.Args, "--client-resources=foo", "--notool")
+		})
+
+		It("only generates the client of the given resources and the types they use", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(filepath.Join(outDir, "client", "foo.go")).Should(BeAnExistingFile())
+			Ω(filepath.Join(outDir, "client", "bar.go")).ShouldNot(BeAnExistingFile())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "user_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("type fooPayload struct"))
+			Ω(string(content)).ShouldNot(ContainSubstring("barPayload"))
+		})
+
+		Context("with an unknown resource", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--client-resources=baz")
+			})
+
+			It("returns an error", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(genErr.Error()).Should(ContainSubstring(`unknown resource "baz"`))
+			})
+		})
+	})
+
+	Context("with a merge patch action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			name := &design.AttributeDefinition{Type: design.String}
+			name.SetNullable()
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"name":    name,
+						"vintage": &design.AttributeDefinition{Type: design.Integer},
+					},
+				},
+				TypeName: "PatchFooPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"patch": {
+								Name:    "patch",
+								Routes:  []*design.RouteDefinition{{Verb: "PATCH", Path: ""}},
+								Payload: payload,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			patchAct := fooRes.Actions["patch"]
+			patchAct.Parent = fooRes
+			patchAct.Routes[0].Parent = patchAct
+			os.Args = append(os.Args, "--notool")
+		})
+
+		It("generates a payload that omits the absent nullable fields", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"github.com/goadesign/goa"`))
+			Ω(string(content)).Should(MatchRegexp(`Name\s+goa\.NullableString`))
+			Ω(string(content)).Should(ContainSubstring("func (ut PatchFooPayload) MarshalJSON() ([]byte, error) {"))
+			Ω(string(content)).Should(MatchRegexp(`Name:\s+ut\.Name\.OrNil\(\),`))
+		})
+	})
+
 	Context("with a multipartform action with a user type payload", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -624,6 +747,156 @@ var _ = Describe("Generate", func() {
 			Ω(string(content)).Should(ContainSubstring("tmp_UUID := payload.UUID"))
 		})
 	})
+
+	Context("with a media type", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"name": &design.AttributeDefinition{Type: design.String},
+						},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+			}
+			mt.Views = map[string]*design.ViewDefinition{
+				"default": {
+					AttributeDefinition: mt.AttributeDefinition,
+					Name:                "default",
+					Parent:              mt,
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					"application/vnd.bottle": mt,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates decode functions that accept a context", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func (c *Client) DecodeBottle(ctx context.Context, resp *http.Response) (*Bottle, error) {"))
+			Ω(string(content)).Should(ContainSubstring(`"context"`))
+		})
+
+		It("stops decoding once the context is done", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("body := goaclient.ContextBody(ctx, resp.Body)"))
+			Ω(string(content)).Should(ContainSubstring(`goaclient "github.com/goadesign/goa/client"`))
+		})
+
+		Context("returned as a streamed JSON collection", func() {
+			BeforeEach(func() {
+				mt := design.Design.MediaTypes["application/vnd.bottle"]
+				collection := &design.MediaTypeDefinition{
+					UserTypeDefinition: &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: &design.Array{ElemType: &design.AttributeDefinition{Type: mt}},
+						},
+						TypeName: "BottleCollection",
+					},
+					Identifier: "application/vnd.bottle; type=collection",
+				}
+				collection.Views = map[string]*design.ViewDefinition{
+					"default": {
+						AttributeDefinition: collection.AttributeDefinition,
+						Name:                "default",
+						Parent:              collection,
+					},
+				}
+				design.Design.MediaTypes["application/vnd.bottle; type=collection"] = collection
+				showAct := design.Design.Resources["foo"].Actions["show"]
+				showAct.Responses = map[string]*design.ResponseDefinition{
+					"OK": {Name: "OK", Status: 200, MediaType: collection.Identifier, Parent: showAct},
+				}
+				showAct.Metadata = dslengine.MetadataDefinition{"http:response:ndjson": {"true"}}
+			})
+
+			It("generates a stream that decodes one element at a time", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("func (c *Client) DecodeBottleCollectionStream(ctx context.Context, resp *http.Response) *BottleCollectionStream {"))
+				Ω(string(content)).Should(ContainSubstring("goa.NewNDJSONDecoder(goaclient.ContextBody(ctx, resp.Body))"))
+				Ω(string(content)).Should(ContainSubstring("func (s *BottleCollectionStream) Recv() (*Bottle, error) {"))
+				Ω(string(content)).Should(ContainSubstring("dec  *goa.NDJSONDecoder"))
+			})
+
+			Context("with --legacy-signatures", func() {
+				BeforeEach(func() {
+					os.Args = append(os.Args, "--legacy-signatures")
+				})
+
+				It("generates a stream decode function that does not accept a context", func() {
+					Ω(genErr).Should(BeNil())
+					content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(content)).Should(ContainSubstring("func (c *Client) DecodeBottleCollectionStream(resp *http.Response) *BottleCollectionStream {"))
+				})
+			})
+		})
+
+		Context("with a response that sets a cookie", func() {
+			BeforeEach(func() {
+				mt := design.Design.MediaTypes["application/vnd.bottle"]
+				mt.Type.ToObject()["session"] = &design.AttributeDefinition{Type: design.String}
+				showAct := design.Design.Resources["foo"].Actions["show"]
+				ok := &design.ResponseDefinition{Name: "OK", Status: 200, MediaType: mt.Identifier, Parent: showAct}
+				ok.Cookies = []*design.CookieDefinition{{Name: "session", HTTPOnly: true, Parent: ok}}
+				showAct.Responses = map[string]*design.ResponseDefinition{"OK": ok}
+			})
+
+			It("generates a decode function that reads the cookie into the result", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(cookieDecode))
+			})
+		})
+
+		Context("with --legacy-signatures", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--legacy-signatures")
+			})
+
+			It("generates decode functions that do not accept a context", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("func (c *Client) DecodeBottle(resp *http.Response) (*Bottle, error) {"))
+			})
+		})
+	})
 })
 
 var _ = Describe("NewGenerator", func() {
@@ -636,6 +909,7 @@ var _ = Describe("NewGenerator", func() {
 		toolDirName string
 		tool        string
 		noTool      bool
+		legacySigs  bool
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
@@ -644,6 +918,7 @@ var _ = Describe("NewGenerator", func() {
 		toolDirName: "test_dir",
 		tool:        "mycli",
 		noTool:      true,
+		legacySigs:  true,
 	}
 
 	Context("with options all options set", func() {
@@ -656,6 +931,7 @@ var _ = Describe("NewGenerator", func() {
 				genclient.ToolDirName(args.toolDirName),
 				genclient.Tool(args.tool),
 				genclient.NoTool(args.noTool),
+				genclient.LegacySignatures(args.legacySigs),
 			)
 		})
 
@@ -667,6 +943,7 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator.ToolDirName).Should(Equal(args.toolDirName))
 			Ω(generator.Tool).Should(Equal(args.tool))
 			Ω(generator.NoTool).Should(Equal(args.noTool))
+			Ω(generator.LegacySignatures).Should(Equal(args.legacySigs))
 		})
 
 	})
@@ -682,3 +959,14 @@ const clientHeaderTmpl = `// Code generated by goagen {{ .version }}, DO NOT EDI
 // --design={{.design}}
 // --version={{.version}}
 `
+
+const cookieDecode = `	err := c.Decoder.Decode(&decoded, body, resp.Header.Get("Content-Type"))
+	for _, cookie := range resp.Cookies() {
+		switch cookie.Name {
+		case "session":
+			value := cookie.Value
+			decoded.Session = &value
+		}
+	}
+	return &decoded, err
+`
@@ -48,3 +48,17 @@ func NoTool(noTool bool) Option {
 		g.NoTool = noTool
 	}
 }
+
+//Resources Names of the resources to generate the client for, all the resources if empty
+func Resources(names ...string) Option {
+	return func(g *Generator) {
+		g.Resources = names
+	}
+}
+
+//LegacySignatures Whether to generate decode functions that don't accept a context
+func LegacySignatures(legacy bool) Option {
+	return func(g *Generator) {
+		g.LegacySignatures = legacy
+	}
+}
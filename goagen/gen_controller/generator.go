@@ -41,8 +41,9 @@ type Generator struct {
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, designPkg, appPkg, ver, res, pkg string
-		force, regen                             bool
+		header, buildTags                                    string
+		outDir, designPkg, appPkg, ver, res, pkg, modulePath string
+		force, regen                                         bool
 	)
 
 	set := flag.NewFlagSet("controller", flag.PanicOnError)
@@ -55,7 +56,18 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&force, "force", false, "")
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("notest", false, "")
+	set.StringVar(&modulePath, "module-path", "", "")
+	set.StringVar(&header, "header", "", "")
+	set.StringVar(&buildTags, "build-tags", "", "")
 	set.Parse(os.Args[1:])
+	if err := codegen.ConfigureHeader(header, buildTags); err != nil {
+		return nil, err
+	}
+	if modulePath != "" {
+		if err := codegen.MapImportPath(outDir, modulePath); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
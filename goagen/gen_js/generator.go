@@ -41,10 +41,11 @@ type Generator struct {
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, ver  string
-		timeout      time.Duration
-		scheme, host string
-		noexample    bool
+		header, buildTags string
+		outDir, ver       string
+		timeout           time.Duration
+		scheme, host      string
+		noexample         bool
 	)
 
 	set := flag.NewFlagSet("client", flag.PanicOnError)
@@ -55,7 +56,13 @@ func Generate() (files []string, err error) {
 	set.StringVar(&host, "host", "", "")
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&noexample, "noexample", false, "")
+	set.String("module-path", "", "")
+	set.StringVar(&header, "header", "", "")
+	set.StringVar(&buildTags, "build-tags", "", "")
 	set.Parse(os.Args[1:])
+	if err := codegen.ConfigureHeader(header, buildTags); err != nil {
+		return nil, err
+	}
 
 	// First check compatibility
 	if err := codegen.CheckVersion(ver); err != nil {
@@ -152,8 +159,8 @@ func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err e
 	}
 
 	actions := make(map[string][]*design.ActionDefinition)
-	g.API.IterateResources(func(res *design.ResourceDefinition) error {
-		return res.IterateActions(func(action *design.ActionDefinition) error {
+	for _, res := range g.API.SortedResources() {
+		res.IterateActions(func(action *design.ActionDefinition) error {
 			if as, ok := actions[action.Name]; ok {
 				actions[action.Name] = append(as, action)
 			} else {
@@ -161,7 +168,7 @@ func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err e
 			}
 			return nil
 		})
-	})
+	}
 
 	var exampleAction *design.ActionDefinition
 	keys := []string{}
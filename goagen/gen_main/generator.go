@@ -45,8 +45,9 @@ type Generator struct {
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, toolDir, designPkg, target, ver string
-		force, notool, regen                    bool
+		header, buildTags                                   string
+		outDir, toolDir, designPkg, target, ver, modulePath string
+		force, notool, regen                                bool
 	)
 
 	set := flag.NewFlagSet("main", flag.PanicOnError)
@@ -59,7 +60,23 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&force, "force", false, "")
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("notest", false, "")
+	set.Bool("legacy-signatures", false, "")
+	set.String("client-resources", "", "")
+	set.String("openapi-ui", "", "")
+	set.String("openapi-spec", "", "")
+	set.String("openapi-ui-assets", "", "")
+	set.StringVar(&modulePath, "module-path", "", "")
+	set.StringVar(&header, "header", "", "")
+	set.StringVar(&buildTags, "build-tags", "", "")
 	set.Parse(os.Args[1:])
+	if err := codegen.ConfigureHeader(header, buildTags); err != nil {
+		return nil, err
+	}
+	if modulePath != "" {
+		if err := codegen.MapImportPath(outDir, modulePath); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
@@ -193,6 +210,9 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		return "", err
 	}
 	err = r.IterateActions(func(a *design.ActionDefinition) error {
+		if a.BatchSource() != nil {
+			return nil
+		}
 		if a.WebSocket() {
 			return file.ExecuteTemplate("actionWS", actionWST, funcs, a)
 		}
@@ -276,13 +296,7 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 		}
 	}()
 	g.genfiles = append(g.genfiles, mainFile)
-	funcs["getPort"] = func(hostport string) string {
-		_, port, err := net.SplitHostPort(hostport)
-		if err != nil {
-			return "8080"
-		}
-		return port
-	}
+	funcs["getPort"] = getPort
 	outPkg, err := codegen.PackagePath(g.OutDir)
 	if err != nil {
 		return err
@@ -313,6 +327,15 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 	return
 }
 
+// getPort returns the port of the given host, 8080 if none.
+func getPort(hostport string) string {
+	_, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "8080"
+	}
+	return port
+}
+
 // tempCount is the counter used to create unique temporary variable names.
 var tempCount int
 
@@ -456,7 +479,7 @@ func main() {
 	service := goa.New({{ printf "%q" .Name }})
 
 	// Mount middleware
-	service.Use(middleware.RequestID())
+	service.Use({{ if .API.RequestIDHeader }}{{ targetPkg }}.RequestID(){{ else }}middleware.RequestID(){{ end }})
 	service.Use(middleware.LogRequest(true))
 	service.Use(middleware.ErrorHandler(service, true))
 	service.Use(middleware.Recover())
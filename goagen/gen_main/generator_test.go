@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_main"
 	"github.com/goadesign/goa/version"
 	. "github.com/onsi/ginkgo"
@@ -77,6 +78,52 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with a module path", func() {
+		var modDir string
+
+		BeforeEach(func() {
+			var err error
+			modDir, err = ioutil.TempDir("", "mono")
+			Ω(err).ShouldNot(HaveOccurred())
+			delete(codegen.Reserved, "app")
+			os.Args = []string{"goagen", "--out=" + modDir, "--design=foo", "--version=" + version.String(), "--module-path=example.com/mono/svc"}
+			resource := &design.ResourceDefinition{
+				Name:    "first",
+				Actions: map[string]*design.ActionDefinition{},
+			}
+			resource.Actions["alpha"] = &design.ActionDefinition{Parent: resource, Name: "alpha"}
+			design.Design = &design.APIDefinition{
+				Name:      "whatever",
+				Resources: map[string]*design.ResourceDefinition{"first": resource},
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(modDir)
+		})
+
+		It("imports the generated packages using the module path", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(modDir, "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"example.com/mono/svc/app"`))
+			content, err = ioutil.ReadFile(filepath.Join(modDir, "first.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"example.com/mono/svc/app"`))
+		})
+
+		Context("that is not a valid import path", func() {
+			BeforeEach(func() {
+				os.Args[len(os.Args)-1] = "--module-path=example.com/../svc"
+			})
+
+			It("returns an error", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(genErr.Error()).Should(ContainSubstring("invalid import path"))
+			})
+		})
+	})
+
 	Context("with resources", func() {
 		var resource *design.ResourceDefinition
 
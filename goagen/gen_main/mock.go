@@ -0,0 +1,233 @@
+package genmain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+// mockAction is the data used to render the mock implementation of an action.
+type mockAction struct {
+	// Action is the mocked action.
+	Action *design.ActionDefinition
+	// Status is the status of the mock response.
+	Status int
+	// ContentType is the value of the mock response Content-Type header if any.
+	ContentType string
+	// Body is the mock response body if any.
+	Body string
+}
+
+// GenerateMockServer returns the source code of a mock server for the given API indexed by file
+// name. appPkg is the import path of the package produced by the app generator. The server mounts
+// a controller for each resource whose actions respond with the success response that has the
+// lowest status. The response body is rendered from the example of the response media type: the
+// examples defined in the design are used first and values are generated for the attributes that
+// do not define one unless the API disables example generation with NoExample. The requests are
+// decoded and validated by the app package before the mock actions run.
+func GenerateMockServer(api *design.APIDefinition, appPkg string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	elems := strings.Split(appPkg, "/")
+	funcs := template.FuncMap{
+		"targetPkg": func() string { return elems[len(elems)-1] },
+		"getPort":   getPort,
+	}
+
+	src, err := renderMock("mockMain", mockMainT, funcs, map[string]interface{}{
+		"API":         api,
+		"AppPkg":      appPkg,
+		"ToolVersion": version.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	files["main.go"] = src
+
+	err = api.IterateResources(func(r *design.ResourceDefinition) error {
+		var actions []*mockAction
+		err := r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.BatchSource() != nil {
+				return nil
+			}
+			ma, err := newMockAction(api, a)
+			if err != nil {
+				return err
+			}
+			actions = append(actions, ma)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		src, err := renderMock("mockController", mockCtrlT, funcs, map[string]interface{}{
+			"Resource":    r,
+			"Actions":     actions,
+			"AppPkg":      appPkg,
+			"ToolVersion": version.String(),
+		})
+		if err != nil {
+			return err
+		}
+		files[codegen.SnakeCase(r.Name)+".go"] = src
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// newMockAction computes the mock response of the given action.
+func newMockAction(api *design.APIDefinition, a *design.ActionDefinition) (*mockAction, error) {
+	ma := &mockAction{Action: a, Status: 204}
+	if a.WebSocket() {
+		ma.Status = 501
+		return ma, nil
+	}
+	var statuses []int
+	byStatus := make(map[int]*design.ResponseDefinition)
+	for _, resp := range a.Responses {
+		if resp.Status >= 200 && resp.Status < 300 {
+			statuses = append(statuses, resp.Status)
+			byStatus[resp.Status] = resp
+		}
+	}
+	if len(statuses) == 0 {
+		return ma, nil
+	}
+	sort.Ints(statuses)
+	resp := byStatus[statuses[0]]
+	ma.Status = resp.Status
+
+	var att *design.AttributeDefinition
+	if mt := api.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		projected, _, err := mt.Project(view)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to project media type %#v: %s", a.Context(), mt.Identifier, err)
+		}
+		att = projected.AttributeDefinition
+		ma.ContentType = mt.ContentType
+		if ma.ContentType == "" {
+			ma.ContentType = resp.MediaType
+		}
+	} else if resp.Type != nil {
+		att = &design.AttributeDefinition{Type: resp.Type}
+		ma.ContentType = "application/json"
+	}
+	if att == nil {
+		return ma, nil
+	}
+	ex := att.GenerateExample(api.RandomGenerator(), nil)
+	if ex == nil || ex == "-" {
+		ma.ContentType = ""
+		return ma, nil
+	}
+	b, err := json.Marshal(ex)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to render example response: %s", a.Context(), err)
+	}
+	ma.Body = string(b)
+	return ma, nil
+}
+
+// renderMock renders the given template and formats the result.
+func renderMock(name, source string, funcs template.FuncMap, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(codegen.DefaultFuncMap).Funcs(funcs).Parse(source)
+	if err != nil {
+		panic(err) // bug
+	}
+	header, err := codegen.FileHeader()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(header)
+	if err := tmpl.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s\n========\nContent:\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+const mockMainT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }} mock server
+//
+// Command:
+{{ comment commandLine }}
+
+package main
+
+import (
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+{{ if or .API.Resources .API.RequestIDHeader }}	{{ printf "%q" .AppPkg }}
+{{ end }})
+
+func main() {
+	// Create service
+	service := goa.New({{ printf "%q" .API.Name }})
+
+	// Mount middleware
+	service.Use({{ if .API.RequestIDHeader }}{{ targetPkg }}.RequestID(){{ else }}middleware.RequestID(){{ end }})
+	service.Use(middleware.LogRequest(true))
+	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(middleware.Recover())
+{{ range $res := .API.Resources }}{{ $name := goify $res.Name true }}
+	// Mount "{{ $res.Name }}" mock controller
+	{{ targetPkg }}.Mount{{ $name }}Controller(service, New{{ $name }}MockController(service))
+{{ end }}
+	// Start service
+	if err := service.ListenAndServe(":{{ getPort .API.Host }}"); err != nil {
+		service.LogError("startup", "err", err)
+	}
+}
+`
+
+const mockCtrlT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Resource.Name }} mock controller
+//
+// Command:
+{{ comment commandLine }}
+
+package main
+
+import (
+	"github.com/goadesign/goa"
+{{ if .Actions }}	{{ printf "%q" .AppPkg }}
+{{ end }})
+
+{{ $ctrlName := printf "%sMockController" (goify .Resource.Name true) }}// {{ $ctrlName }} implements the {{ .Resource.Name }} resource with canned responses.
+type {{ $ctrlName }} struct {
+	*goa.Controller
+}
+
+// New{{ $ctrlName }} creates a {{ .Resource.Name }} mock controller.
+func New{{ $ctrlName }}(service *goa.Service) *{{ $ctrlName }} {
+	return &{{ $ctrlName }}{Controller: service.NewController("{{ $ctrlName }}")}
+}
+{{ range .Actions }}{{ $action := goify .Action.Name true }}
+// {{ $action }} responds to the {{ .Action.Name }} action with status {{ .Status }}{{ if .Body }} and an example body{{ end }}.
+func (c *{{ $ctrlName }}) {{ $action }}(ctx *{{ targetPkg }}.{{ $action }}{{ goify .Action.Parent.Name true }}Context) error {
+{{ if .ContentType }}	ctx.ResponseData.Header().Set("Content-Type", {{ printf "%q" .ContentType }})
+{{ end }}	ctx.ResponseData.WriteHeader({{ .Status }})
+{{ if .Body }}	_, err := ctx.ResponseData.Write([]byte({{ printf "%q" .Body }}))
+	return err
+{{ else }}	return nil
+{{ end }}}
+{{ end }}`
@@ -0,0 +1,118 @@
+package genmain_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_main"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateMockServer", func() {
+	var files map[string][]byte
+	var genErr error
+
+	BeforeEach(func() {
+		resetDesign()
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer, func() { Example(1) })
+				Attribute("name", String, func() { Example("Number 8") })
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+		})
+		API("cellar", func() {
+			Host("localhost:8081")
+		})
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/bottles/:id"))
+				Params(func() {
+					Param("id", Integer)
+				})
+				Response(OK, bottle)
+				Response(NotFound)
+			})
+			Action("create", func() {
+				Routing(POST("/bottles"))
+				Payload(func() {
+					Attribute("name", String)
+					Required("name")
+				})
+				Response(Created)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		files, genErr = genmain.GenerateMockServer(Design, "github.com/goadesign/cellar/app")
+	})
+
+	AfterEach(func() {
+		resetDesign()
+	})
+
+	It("generates the main and controller files", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(2))
+		Ω(files).Should(HaveKey("main.go"))
+		Ω(files).Should(HaveKey("bottle.go"))
+		for name, src := range files {
+			_, err := parser.ParseFile(token.NewFileSet(), name, src, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+	})
+
+	It("mounts the mock controllers", func() {
+		main := string(files["main.go"])
+		Ω(main).Should(ContainSubstring(`"github.com/goadesign/cellar/app"`))
+		Ω(main).Should(ContainSubstring("app.MountBottleController(service, NewBottleMockController(service))"))
+		Ω(main).Should(ContainSubstring(`service.ListenAndServe(":8081")`))
+	})
+
+	Context("with build tags", func() {
+		BeforeEach(func() {
+			Ω(codegen.SetBuildTags("test")).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			codegen.SetBuildTags("")
+		})
+
+		It("writes the build constraint before the package clause", func() {
+			for _, src := range files {
+				Ω(string(src)).Should(HavePrefix("//go:build test\n\n// Code generated by goagen"))
+			}
+		})
+	})
+
+	It("responds with the example of the success response", func() {
+		Ω(string(files["bottle.go"])).Should(ContainSubstring(mockShowCode))
+	})
+
+	It("responds with the success status when there is no body", func() {
+		Ω(string(files["bottle.go"])).Should(ContainSubstring(mockCreateCode))
+	})
+})
+
+const mockShowCode = `func (c *BottleMockController) Show(ctx *app.ShowBottleContext) error {
+	ctx.ResponseData.Header().Set("Content-Type", "application/vnd.bottle")
+	ctx.ResponseData.WriteHeader(200)
+	_, err := ctx.ResponseData.Write([]byte("{\"id\":1,\"name\":\"Number 8\"}"))
+	return err
+}`
+
+const mockCreateCode = `func (c *BottleMockController) Create(ctx *app.CreateBottleContext) error {
+	ctx.ResponseData.WriteHeader(201)
+	return nil
+}`
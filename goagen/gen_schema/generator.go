@@ -25,25 +25,37 @@ func NewGenerator(options ...Option) *Generator {
 
 // Generator is the application code generator.
 type Generator struct {
-	API      *design.APIDefinition // The API definition
-	OutDir   string                // Path to output directory
-	genfiles []string              // Generated files
+	API       *design.APIDefinition // The API definition
+	OutDir    string                // Path to output directory
+	Validator bool                  // Whether to generate the request validator middleware
+	genfiles  []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
-	var outDir, ver string
+	var (
+		header, buildTags string
+		outDir, ver       string
+		validator         bool
+	)
 	set := flag.NewFlagSet("app", flag.PanicOnError)
 	set.StringVar(&outDir, "out", "", "")
 	set.StringVar(&ver, "version", "", "")
 	set.String("design", "", "")
+	set.BoolVar(&validator, "validator", false, "")
+	set.String("module-path", "", "")
+	set.StringVar(&header, "header", "", "")
+	set.StringVar(&buildTags, "build-tags", "", "")
 	set.Parse(os.Args[1:])
+	if err := codegen.ConfigureHeader(header, buildTags); err != nil {
+		return nil, err
+	}
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, API: design.Design}
+	g := &Generator{OutDir: outDir, API: design.Design, Validator: validator}
 
 	return g.Generate()
 }
@@ -78,9 +90,63 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 	g.genfiles = append(g.genfiles, schemaFile)
 
+	if g.Validator {
+		if err = g.generateValidator(); err != nil {
+			return
+		}
+	}
+
 	return g.genfiles, nil
 }
 
+// generateValidator writes the Go package that embeds the JSON schema and exposes a HTTP
+// middleware validating the action payloads against it.
+func (g *Generator) generateValidator() (err error) {
+	var routes []map[string]string
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload == nil || a.PayloadMultipart {
+				return nil
+			}
+			for _, route := range a.Routes {
+				routes = append(routes, map[string]string{
+					"Method": route.Verb,
+					"Path":   route.FullPath(),
+					"Ref":    TypeRef(g.API, a.Payload),
+				})
+			}
+			return nil
+		})
+	})
+
+	validatorFile := filepath.Join(g.OutDir, "validator.go")
+	file, err := codegen.SourceFileFor(validatorFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.NewImport("_", "embed"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/jsonschema"),
+	}
+	if err = file.WriteHeader(fmt.Sprintf("%s JSON schema request validator", g.API.Name), "schema", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, validatorFile)
+
+	data := map[string]interface{}{
+		"API":    g.API,
+		"Routes": routes,
+	}
+	return file.ExecuteTemplate("validator", validatorT, nil, data)
+}
+
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
 func (g *Generator) Cleanup() {
 	for _, f := range g.genfiles {
@@ -88,3 +154,30 @@ func (g *Generator) Cleanup() {
 	}
 	g.genfiles = nil
 }
+
+const validatorT = `//go:embed schema.json
+var schemaJSON []byte
+
+// payloadRoutes lists the routes of the actions that accept a payload and the references to the
+// payload schemas.
+var payloadRoutes = []jsonschema.Route{
+{{- range .Routes }}
+	{Method: {{ printf "%q" .Method }}, Path: {{ printf "%q" .Path }}, Ref: {{ printf "%q" .Ref }}},
+{{- end }}
+}
+
+// NewRequestValidator returns a HTTP middleware that validates the request bodies sent to the
+// actions of the {{ .API.Name }} API against the JSON schema of their payload. Requests whose body
+// violates the schema are rejected with a 400 Bad Request response that lists the JSON pointers to
+// the invalid elements and to the violated schema keywords. The middleware wraps the service HTTP
+// handler, e.g.:
+//
+//	validate, err := schema.NewRequestValidator()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	http.ListenAndServe(":8080", validate(service.Mux))
+func NewRequestValidator() (func(http.Handler) http.Handler, error) {
+	return jsonschema.New(schemaJSON, payloadRoutes)
+}
+`
@@ -3,6 +3,8 @@ package genschema_test
 import (
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_schema"
+	"github.com/goadesign/goa/middleware/jsonschema"
 	"github.com/goadesign/goa/version"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -63,6 +66,85 @@ var _ = Describe("Generate", func() {
 	})
 })
 
+var _ = Describe("Generate with the validator", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("schematest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--validator", "--version=" + version.String()}
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		apidsl.API("test api", func() {
+			apidsl.BasePath("/api")
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST("/bottles/:id"))
+				apidsl.Payload(func() {
+					apidsl.Attribute("name", design.String, func() {
+						apidsl.MinLength(2)
+					})
+					apidsl.Attribute("vintage", design.Integer, func() {
+						apidsl.Minimum(1900)
+					})
+					apidsl.Required("name")
+				})
+				apidsl.Response(design.NoContent)
+			})
+		})
+		dslengine.Run()
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genschema.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the request validator", func() {
+		Ω(genErr).Should(BeNil())
+		Ω(files).Should(HaveLen(3))
+		content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "schema", "validator.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`{Method: "POST", Path: "/api/bottles/:id", Ref: "#/definitions/CreateBottlePayload"},`))
+		Ω(string(content)).Should(ContainSubstring(`_ "embed"`))
+		Ω(string(content)).Should(ContainSubstring("func NewRequestValidator() (func(http.Handler) http.Handler, error) {"))
+	})
+
+	It("generates a schema that rejects the invalid request bodies", func() {
+		Ω(genErr).Should(BeNil())
+		schema, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "schema", "schema.json"))
+		Ω(err).ShouldNot(HaveOccurred())
+		validate, err := jsonschema.New(schema, []jsonschema.Route{
+			{Method: "POST", Path: "/api/bottles/:id", Ref: "#/definitions/CreateBottlePayload"},
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		handler := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/bottles/1", strings.NewReader(`{"name":"x","vintage":1800}`))
+		handler.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(http.StatusBadRequest))
+		Ω(rw.Body.String()).Should(ContainSubstring("/name: length must be greater than or equal to 2, got 1 (#/definitions/CreateBottlePayload/properties/name/minLength)"))
+		Ω(rw.Body.String()).Should(ContainSubstring("/vintage: value must be greater than or equal to 1900, got 1800"))
+
+		rw = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/bottles/1", strings.NewReader(`{"name":"xyz","vintage":2000}`))
+		handler.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(http.StatusNoContent))
+	})
+})
+
 var _ = Describe("NewGenerator", func() {
 	var generator *genschema.Generator
 
@@ -34,6 +34,9 @@ type (
 		Links     []*JSONLink `json:"links,omitempty"`
 		Ref       string      `json:"$ref,omitempty"`
 
+		// Extensions
+		Nullable bool `json:"x-nullable,omitempty"`
+
 		// Validation
 		Enum                 []interface{} `json:"enum,omitempty"`
 		Format               string        `json:"format,omitempty"`
@@ -44,11 +47,16 @@ type (
 		MaxLength            *int          `json:"maxLength,omitempty"`
 		MinItems             *int          `json:"minItems,omitempty"`
 		MaxItems             *int          `json:"maxItems,omitempty"`
+		UniqueItems          bool          `json:"uniqueItems,omitempty"`
 		Required             []string      `json:"required,omitempty"`
 		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
 
 		// Union
 		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+
+		// Inheritance
+		AllOf         []*JSONSchema `json:"allOf,omitempty"`
+		Discriminator string        `json:"discriminator,omitempty"`
 	}
 
 	// JSONType is the JSON type enum.
@@ -276,7 +284,36 @@ func GenerateTypeDefinition(api *design.APIDefinition, ut *design.UserTypeDefini
 	s := NewJSONSchema()
 	s.Title = ut.TypeName
 	Definitions[ut.TypeName] = s
+	if base := ut.Base(); base != nil {
+		buildExtendedTypeSchema(api, s, ut, base)
+		return
+	}
 	buildAttributeSchema(api, s, ut.AttributeDefinition)
+	s.Discriminator = ut.Discriminator()
+}
+
+// buildExtendedTypeSchema initializes the given JSON schema that corresponds to the given type
+// which extends base. The schema combines a reference to the base type schema with the schema of
+// the attributes that are not defined in the base type using "allOf".
+func buildExtendedTypeSchema(api *design.APIDefinition, s *JSONSchema, ut, base *design.UserTypeDefinition) {
+	baseObj := base.ToObject()
+	own := make(design.Object)
+	for n, at := range ut.ToObject() {
+		if _, ok := baseObj[n]; !ok {
+			own[n] = at
+		}
+	}
+	ext := NewJSONSchema()
+	buildAttributeSchema(api, ext, &design.AttributeDefinition{Type: own})
+	if ut.Validation != nil {
+		for _, r := range ut.Validation.Required {
+			if _, ok := own[r]; ok {
+				ext.Required = append(ext.Required, r)
+			}
+		}
+	}
+	s.Description = ut.Description
+	s.AllOf = []*JSONSchema{{Ref: TypeRef(api, base)}, ext}
 }
 
 // TypeSchema produces the JSON schema corresponding to the given data type.
@@ -339,6 +376,7 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Title, other.Title, s.Title == ""},
 		{&s.Media, other.Media, s.Media == nil},
 		{&s.ReadOnly, other.ReadOnly, s.ReadOnly == false},
+		{&s.Nullable, other.Nullable, s.Nullable == false},
 		{&s.PathStart, other.PathStart, s.PathStart == ""},
 		{&s.Enum, other.Enum, s.Enum == nil},
 		{&s.Format, other.Format, s.Format == ""},
@@ -415,6 +453,7 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Title:                s.Title,
 		Media:                s.Media,
 		ReadOnly:             s.ReadOnly,
+		Nullable:             s.Nullable,
 		PathStart:            s.PathStart,
 		Links:                s.Links,
 		Ref:                  s.Ref,
@@ -427,8 +466,11 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		MaxLength:            s.MaxLength,
 		MinItems:             s.MinItems,
 		MaxItems:             s.MaxItems,
+		UniqueItems:          s.UniqueItems,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
+		AllOf:                s.AllOf,
+		Discriminator:        s.Discriminator,
 	}
 	for n, p := range s.Properties {
 		js.Properties[n] = p.Dup()
@@ -459,12 +501,14 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	s.Description = at.Description
 	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
 	s.ReadOnly = at.IsReadOnly()
+	s.Nullable = at.IsNullable()
 	val := at.Validation
 	if val == nil {
+		wireSchema(s, at)
 		return s
 	}
 	s.Enum = val.Values
-	s.Format = val.Format
+	s.Format = design.OpenAPIFormat(val.Format)
 	s.Pattern = val.Pattern
 	if val.Minimum != nil {
 		s.Minimum = val.Minimum
@@ -488,10 +532,49 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 			s.MaxLength = val.MaxLength
 		}
 	}
+	if val.MinItems != nil {
+		s.MinItems = val.MinItems
+	}
+	if val.MaxItems != nil {
+		s.MaxItems = val.MaxItems
+	}
+	s.UniqueItems = val.UniqueItems
 	s.Required = val.Required
+	wireSchema(s, at)
 	return s
 }
 
+// wireSchema describes the attributes encoded using the string wire type as strings, the format
+// records the type of the encoded value.
+func wireSchema(s *JSONSchema, at *design.AttributeDefinition) {
+	if at.WireType() != design.String.Name() {
+		return
+	}
+	switch at.Type.Kind() {
+	case design.IntegerKind:
+		s.Format = "int64"
+	case design.NumberKind:
+		s.Format = "double"
+	case design.BooleanKind:
+		s.Format = "boolean"
+	}
+	s.Type = JSONString
+	if s.Example != nil {
+		s.Example = fmt.Sprint(s.Example)
+	}
+	if s.DefaultValue != nil {
+		s.DefaultValue = fmt.Sprint(s.DefaultValue)
+	}
+	s.Minimum, s.Maximum = nil, nil
+	if s.Enum != nil {
+		enum := make([]interface{}, len(s.Enum))
+		for i, v := range s.Enum {
+			enum[i] = fmt.Sprint(v)
+		}
+		s.Enum = enum
+	}
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
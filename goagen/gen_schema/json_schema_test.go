@@ -46,6 +46,49 @@ var _ = Describe("TypeSchema", func() {
 		})
 	})
 
+	Context("with a type with a nullable attribute", func() {
+		BeforeEach(func() {
+			Type("Foo", func() {
+				Attribute("bar", design.String, func() { Nullable() })
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Foo"]
+		})
+
+		It("marks the property as nullable", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Foo"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties).Should(HaveKey("bar"))
+			Ω(def.Properties["bar"].Nullable).Should(BeTrue())
+		})
+	})
+
+	Context("with a type with an attribute using the string wire type", func() {
+		BeforeEach(func() {
+			Type("Account", func() {
+				Attribute("id", design.Integer, func() {
+					WireType(design.String)
+					Example(9007199254740993)
+				})
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Account"]
+		})
+
+		It("describes the property as a string", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Account"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties).Should(HaveKey("id"))
+			Ω(def.Properties["id"].Type).Should(Equal(genschema.JSONType(genschema.JSONString)))
+			Ω(def.Properties["id"].Format).Should(Equal("int64"))
+			Ω(def.Properties["id"].Example).Should(Equal("9007199254740993"))
+		})
+	})
+
 	Context("with a media type with self-referencing attributes", func() {
 		BeforeEach(func() {
 			MediaType("application/vnd.menu+json", func() {
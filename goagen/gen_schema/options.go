@@ -18,3 +18,10 @@ func OutDir(outDir string) Option {
 		g.OutDir = outDir
 	}
 }
+
+//Validator Whether to generate the request validator middleware
+func Validator(validator bool) Option {
+	return func(g *Generator) {
+		g.Validator = validator
+	}
+}
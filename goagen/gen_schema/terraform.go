@@ -0,0 +1,328 @@
+package genschema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+type (
+	// terraformResource is the data used to render the schema of a Terraform resource.
+	terraformResource struct {
+		Name      string
+		Resource  string
+		Func      string
+		Lifecycle []*terraformOperation
+		Fields    []*terraformField
+	}
+
+	// terraformOperation describes the API action that implements a Terraform resource
+	// lifecycle operation.
+	terraformOperation struct {
+		Name   string
+		Action string
+		Verb   string
+		Path   string
+	}
+
+	// terraformField is a field of a Terraform resource schema.
+	terraformField struct {
+		Name string
+		Def  string
+	}
+)
+
+// GenerateTerraformSchemas returns the source code of a Go file that defines the schemas of the
+// Terraform resources backed by the API resources tagged with the "terraform:resource" metadata
+// indexed by file name. pkg is the name of the generated package, typically the package of the
+// Terraform provider. Each resource gets a XxxResourceSchema function that returns the schema
+// built with the Terraform plugin SDK:
+//
+//   - the attributes of the create action payload are required or optional depending on whether
+//     the payload requires them. Optional attributes that the show action also returns are
+//     computed as the API may set them.
+//   - the attributes that only the show action returns are computed.
+//   - the attributes of the create action payload that the update action payload does not
+//     define force the creation of a new resource when they change.
+//
+// The "id" attribute is omitted as Terraform manages the resource identifier separately. The
+// documentation of the schema functions lists the actions that implement the create, read, update
+// and delete operations of the resources, the provider implements these operations with the
+// generated client.
+func GenerateTerraformSchemas(api *design.APIDefinition, pkg string) (map[string][]byte, error) {
+	var resources []*terraformResource
+	for _, r := range api.SortedResources() {
+		if r.TerraformResource() == "" {
+			continue
+		}
+		tr, err := buildTerraformResource(api, r)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, tr)
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf(`no resource defines the "terraform:resource" metadata`)
+	}
+
+	tmpl, err := template.New("terraform").Funcs(codegen.DefaultFuncMap).Parse(terraformT)
+	if err != nil {
+		panic(err) // bug
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"API":         api,
+		"Package":     pkg,
+		"Resources":   resources,
+		"ToolVersion": version.String(),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s\n========\nContent:\n%s", err, buf.String())
+	}
+	return map[string][]byte{"terraform_schemas.go": src}, nil
+}
+
+// buildTerraformResource computes the schema of the Terraform resource backed by r.
+func buildTerraformResource(api *design.APIDefinition, r *design.ResourceDefinition) (*terraformResource, error) {
+	name := r.TerraformResource()
+	tr := &terraformResource{
+		Name:     name,
+		Resource: r.Name,
+		Func:     codegen.Goify(name, true) + "ResourceSchema",
+	}
+	for i, n := range design.TerraformLifecycle {
+		a, ok := r.Actions[n]
+		if !ok {
+			return nil, fmt.Errorf("terraform resource %#v requires a %#v action", name, n)
+		}
+		op := &terraformOperation{Name: []string{"Create", "Read", "Update", "Delete"}[i], Action: a.Name}
+		if len(a.Routes) > 0 {
+			op.Verb = a.Routes[0].Verb
+			op.Path = a.Routes[0].FullPath()
+		}
+		tr.Lifecycle = append(tr.Lifecycle, op)
+	}
+
+	create := r.Actions["create"]
+	if create.Payload == nil || create.Payload.ToObject() == nil {
+		return nil, fmt.Errorf("terraform resource %#v requires the create action to have an object payload", name)
+	}
+	input := create.Payload.ToObject()
+	var update design.Object
+	if p := r.Actions["update"].Payload; p != nil {
+		update = p.ToObject()
+	}
+	result, err := terraformResult(api, r.Actions["show"])
+	if err != nil {
+		return nil, fmt.Errorf("terraform resource %#v: %s", name, err)
+	}
+
+	names := make(map[string]bool)
+	for _, o := range []design.Object{input, update, result} {
+		for n := range o {
+			if n != "id" {
+				names[n] = true
+			}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+	for _, n := range sorted {
+		var (
+			att  *design.AttributeDefinition
+			opts []string
+		)
+		_, returned := result[n]
+		switch {
+		case input[n] != nil:
+			att = input[n]
+			if create.Payload.IsRequired(n) && !create.Payload.HasDefaultValue(n) {
+				opts = append(opts, "Required: true")
+			} else {
+				opts = append(opts, "Optional: true")
+				if returned {
+					opts = append(opts, "Computed: true")
+				}
+			}
+			if _, ok := update[n]; !ok {
+				opts = append(opts, "ForceNew: true")
+			}
+		case update[n] != nil:
+			att = update[n]
+			opts = append(opts, "Optional: true")
+			if returned {
+				opts = append(opts, "Computed: true")
+			}
+		default:
+			att = result[n]
+			opts = append(opts, "Computed: true")
+		}
+		def, err := terraformSchemaDef(att, input[n] != nil || update[n] != nil, opts)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %#v of terraform resource %#v: %s", n, name, err)
+		}
+		tr.Fields = append(tr.Fields, &terraformField{Name: n, Def: def})
+	}
+	return tr, nil
+}
+
+// terraformResult returns the attributes of the body of the OK response of the given action.
+func terraformResult(api *design.APIDefinition, a *design.ActionDefinition) (design.Object, error) {
+	resp, ok := a.Responses[design.OK]
+	if !ok {
+		return nil, fmt.Errorf("the show action must have an OK response")
+	}
+	mt, ok := resp.Type.(*design.MediaTypeDefinition)
+	if !ok && resp.Type == nil {
+		mt = api.MediaTypeWithIdentifier(resp.MediaType)
+	}
+	if mt == nil {
+		if resp.Type != nil && resp.Type.ToObject() != nil {
+			return resp.Type.ToObject(), nil
+		}
+		return nil, fmt.Errorf("the OK response of the show action must describe an object")
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	projected, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return projected.ToObject(), nil
+}
+
+// terraformSchemaDef returns the Go code of the Terraform schema of the given attribute with the
+// given options. input indicates whether the attribute is set by the user in which case the nested
+// object fields are required or optional, they are computed otherwise.
+func terraformSchemaDef(att *design.AttributeDefinition, input bool, opts []string) (string, error) {
+	typ, err := terraformType(att, input)
+	if err != nil {
+		return "", err
+	}
+	lines := append(typ, opts...)
+	if att.Description != "" {
+		lines = append(lines, fmt.Sprintf("Description: %q", att.Description))
+	}
+	return "{\n" + strings.Join(lines, ",\n") + ",\n}", nil
+}
+
+// terraformType returns the fields of the Terraform schema that describe the type of att.
+func terraformType(att *design.AttributeDefinition, input bool) ([]string, error) {
+	switch {
+	case att.Type.IsArray():
+		elem, err := terraformElem(att.Type.ToArray().ElemType, input)
+		if err != nil {
+			return nil, err
+		}
+		return []string{"Type: schema.TypeList", "Elem: " + elem}, nil
+	case att.Type.IsHash():
+		h := att.Type.ToHash()
+		if h.KeyType.Type.Kind() != design.StringKind || !h.ElemType.Type.IsPrimitive() {
+			return nil, fmt.Errorf("terraform maps must map strings to primitive values")
+		}
+		elem, err := terraformElem(h.ElemType, input)
+		if err != nil {
+			return nil, err
+		}
+		return []string{"Type: schema.TypeMap", "Elem: " + elem}, nil
+	case att.Type.IsObject():
+		elem, err := terraformElem(att, input)
+		if err != nil {
+			return nil, err
+		}
+		return []string{"Type: schema.TypeList", "MaxItems: 1", "Elem: " + elem}, nil
+	}
+	dt := att.Type
+	if ut, ok := dt.(*design.UserTypeDefinition); ok {
+		dt = ut.Type
+	}
+	switch dt.Kind() {
+	case design.BooleanKind:
+		return []string{"Type: schema.TypeBool"}, nil
+	case design.IntegerKind:
+		return []string{"Type: schema.TypeInt"}, nil
+	case design.NumberKind:
+		return []string{"Type: schema.TypeFloat"}, nil
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return []string{"Type: schema.TypeString"}, nil
+	}
+	return nil, fmt.Errorf("type %s is not supported", att.Type.Name())
+}
+
+// terraformElem returns the Go code of the Terraform schema elements of arrays, maps and nested
+// objects.
+func terraformElem(att *design.AttributeDefinition, input bool) (string, error) {
+	obj := att.Type.ToObject()
+	if obj == nil {
+		typ, err := terraformType(att, input)
+		if err != nil {
+			return "", err
+		}
+		return "&schema.Schema{\n" + strings.Join(typ, ",\n") + ",\n}", nil
+	}
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var fields []string
+	for _, n := range names {
+		opts := []string{"Computed: true"}
+		if input {
+			opts = []string{"Optional: true"}
+			if att.IsRequired(n) {
+				opts = []string{"Required: true"}
+			}
+		}
+		def, err := terraformSchemaDef(obj[n], input, opts)
+		if err != nil {
+			return "", fmt.Errorf("field %#v: %s", n, err)
+		}
+		fields = append(fields, fmt.Sprintf("%q: %s,", n, def))
+	}
+	return "&schema.Resource{\nSchema: map[string]*schema.Schema{\n" + strings.Join(fields, "\n") + "\n},\n}", nil
+}
+
+const terraformT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .API.Name }}: Terraform Resource Schemas
+//
+// Command:
+{{ comment commandLine }}
+
+package {{ .Package }}
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+{{ range .Resources }}
+// {{ .Func }} returns the schema of the {{ printf "%q" .Name }} Terraform resource backed by the
+// {{ .Resource }} API resource. The resource lifecycle operations map to the API actions as follows:
+//
+{{- range .Lifecycle }}
+//	{{ .Name }}: {{ .Verb }} {{ .Path }} ({{ .Action }})
+{{- end }}
+func {{ .Func }}() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+{{- range .Fields }}
+		{{ printf "%q" .Name }}: {{ .Def }},
+{{- end }}
+	}
+}
+{{ end }}`
@@ -0,0 +1,161 @@
+package genschema_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strconv"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateTerraformSchemas", func() {
+	var tagged bool
+	var files map[string][]byte
+	var genErr error
+
+	BeforeEach(func() {
+		tagged = true
+	})
+
+	JustBeforeEach(func() {
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+		API("users", func() {})
+		user := MediaType("application/vnd.user+json", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("email", String, "Email address")
+				Attribute("name", String)
+				Attribute("tags", ArrayOf(String))
+				Attribute("created_at", DateTime)
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("email")
+				Attribute("name")
+				Attribute("tags")
+				Attribute("created_at")
+			})
+		})
+		Resource("user", func() {
+			if tagged {
+				Metadata("terraform:resource", "user")
+			}
+			BasePath("/users")
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(func() {
+					Attribute("email", String, "Email address")
+					Attribute("name", String)
+					Attribute("tags", ArrayOf(String))
+					Required("email")
+				})
+				Response(Created)
+			})
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(OK, user)
+			})
+			Action("update", func() {
+				Routing(PATCH("/:id"))
+				Payload(func() {
+					Attribute("name", String)
+					Attribute("tags", ArrayOf(String))
+				})
+				Response(NoContent)
+			})
+			Action("delete", func() {
+				Routing(DELETE("/:id"))
+				Response(NoContent)
+			})
+		})
+		Resource("health", func() {
+			Action("check", func() {
+				Routing(GET("/health"))
+				Response(OK)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genschema.GenerateTerraformSchemas(Design, "provider")
+	})
+
+	// fields returns the fields of the schema returned by the given function indexed by name
+	// with the source code of their properties.
+	fields := func(src []byte, fn string) map[string]map[string]string {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "terraform_schemas.go", src, 0)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(f.Name.Name).Should(Equal("provider"))
+		res := make(map[string]map[string]string)
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Name.Name != fn {
+				continue
+			}
+			lit := fd.Body.List[0].(*ast.ReturnStmt).Results[0].(*ast.CompositeLit)
+			for _, elt := range lit.Elts {
+				kv := elt.(*ast.KeyValueExpr)
+				name, err := strconv.Unquote(kv.Key.(*ast.BasicLit).Value)
+				Ω(err).ShouldNot(HaveOccurred())
+				props := make(map[string]string)
+				for _, p := range kv.Value.(*ast.CompositeLit).Elts {
+					pkv := p.(*ast.KeyValueExpr)
+					var buf bytes.Buffer
+					Ω(printer.Fprint(&buf, fset, pkv.Value)).ShouldNot(HaveOccurred())
+					props[pkv.Key.(*ast.Ident).Name] = buf.String()
+				}
+				res[name] = props
+			}
+		}
+		return res
+	}
+
+	It("generates the schema of the tagged resources", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveKey("terraform_schemas.go"))
+		src := files["terraform_schemas.go"]
+		Ω(string(src)).Should(ContainSubstring("//	Create: POST /users (create)\n"))
+		Ω(string(src)).Should(ContainSubstring("//	Read: GET /users/:id (show)\n"))
+		Ω(string(src)).ShouldNot(ContainSubstring("Health"))
+
+		schema := fields(src, "UserResourceSchema")
+		Ω(schema).Should(HaveLen(4))
+		Ω(schema).ShouldNot(HaveKey("id"))
+		Ω(schema["email"]).Should(Equal(map[string]string{
+			"Type":        "schema.TypeString",
+			"Required":    "true",
+			"ForceNew":    "true",
+			"Description": `"Email address"`,
+		}))
+		Ω(schema["name"]).Should(Equal(map[string]string{
+			"Type":     "schema.TypeString",
+			"Optional": "true",
+			"Computed": "true",
+		}))
+		Ω(schema["tags"]).Should(HaveKeyWithValue("Type", "schema.TypeList"))
+		Ω(schema["tags"]).Should(HaveKeyWithValue("Elem", "&schema.Schema{\n\tType: schema.TypeString,\n}"))
+		Ω(schema["created_at"]).Should(Equal(map[string]string{
+			"Type":     "schema.TypeString",
+			"Computed": "true",
+		}))
+	})
+
+	Context("with no tagged resource", func() {
+		BeforeEach(func() {
+			tagged = false
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`no resource defines the "terraform:resource" metadata`))
+		})
+	})
+})
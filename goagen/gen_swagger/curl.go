@@ -0,0 +1,264 @@
+package genswagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/design"
+)
+
+// GenerateCurlExamples returns example curl commands for the actions of the given API indexed by
+// "resource.action". The commands use the first route of each action, the example values of the
+// action parameters and payload and placeholders for the credentials required by the action
+// security scheme.
+func GenerateCurlExamples(api *design.APIDefinition) map[string]string {
+	examples := make(map[string]string)
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(action *design.ActionDefinition) error {
+			if len(action.Routes) == 0 {
+				return nil
+			}
+			examples[res.Name+"."+action.Name] = curlExample(api, action, action.Routes[0])
+			return nil
+		})
+	})
+	return examples
+}
+
+// curlExample builds the curl command for the given action route.
+func curlExample(api *design.APIDefinition, action *design.ActionDefinition, route *design.RouteDefinition) string {
+	rand := api.RandomGenerator()
+	var params design.Object
+	if action.Params != nil {
+		params = action.Params.Type.ToObject()
+	}
+
+	// Path
+	inPath := make(map[string]bool)
+	path := design.WildcardRegex.ReplaceAllStringFunc(route.FullPath(), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		inPath[name] = true
+		val := "<" + name + ">"
+		if att, ok := params[name]; ok {
+			if ex := att.GenerateExample(rand, nil); ex != nil {
+				val = url.PathEscape(exampleString(ex))
+			}
+		}
+		return "/" + val
+	})
+
+	// Query string
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Generate examples in a deterministic order
+	query := make(url.Values)
+	for _, name := range names {
+		if inPath[name] {
+			continue
+		}
+		ex := params[name].GenerateExample(rand, nil)
+		if ex == nil {
+			continue
+		}
+		if m, ok := ex.(map[string]interface{}); ok && params[name].IsDeepObject() {
+			for key, val := range m {
+				field := name + "[" + key + "]"
+				if reflect.ValueOf(val).Kind() == reflect.Slice {
+					field += "[]"
+				}
+				addQueryValue(query, field, val)
+			}
+			continue
+		}
+		addQueryValue(query, name, ex)
+	}
+	qs := encodeQuery(query)
+
+	// Security
+	var headers []string
+	if action.Security != nil && action.Security.Scheme != nil {
+		scheme := action.Security.Scheme
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			headers = append(headers, "Authorization: Basic <credentials>")
+		case design.OAuth2SecurityKind:
+			headers = append(headers, "Authorization: Bearer <token>")
+		case design.APIKeySecurityKind, design.JWTSecurityKind:
+			placeholder := "<api-key>"
+			if scheme.Kind == design.JWTSecurityKind {
+				placeholder = "<token>"
+			}
+			if scheme.In == "query" {
+				if qs != "" {
+					qs += "&"
+				}
+				qs += scheme.Name + "=" + placeholder
+			} else if scheme.Name != "" {
+				if scheme.Kind == design.JWTSecurityKind && scheme.Name == "Authorization" {
+					placeholder = "Bearer " + placeholder
+				}
+				headers = append(headers, scheme.Name+": "+placeholder)
+			}
+		}
+	}
+
+	// Required headers
+	if action.Headers != nil {
+		hdrs := action.Headers.Type.ToObject()
+		names := make([]string, 0, len(hdrs))
+		for name := range hdrs {
+			if action.Headers.IsRequired(name) && !hasHeader(headers, name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			val := "<" + name + ">"
+			if ex := hdrs[name].Example; ex != nil {
+				val = exampleString(ex)
+			}
+			headers = append(headers, name+": "+val)
+		}
+	}
+
+	// Body
+	var body string
+	if action.Payload != nil {
+		if ex := action.Payload.GenerateExample(rand, nil); ex != nil {
+			if b, err := json.Marshal(ex); err == nil {
+				body = string(b)
+			}
+		}
+	}
+	if action.Payload != nil || len(action.RequiredContentTypes()) > 0 {
+		contentType := "application/json"
+		switch action.PatchFormat() {
+		case design.MergePatch:
+			contentType = "application/merge-patch+json"
+		case design.JSONPatch:
+			contentType = "application/json-patch+json"
+		}
+		if required := action.RequiredContentTypes(); len(required) > 0 {
+			accepted := false
+			for _, ct := range required {
+				if strings.EqualFold(ct, contentType) {
+					accepted = true
+					break
+				}
+			}
+			if !accepted {
+				contentType = required[0]
+			}
+		}
+		if !hasHeader(headers, "Content-Type") {
+			headers = append(headers, "Content-Type: "+contentType)
+		}
+	}
+
+	scheme := "http"
+	if len(action.Schemes) > 0 {
+		scheme = action.Schemes[0]
+	} else if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	host := api.Host
+	if host == "" {
+		host = "localhost"
+	}
+	u := scheme + "://" + host + path
+	if qs != "" {
+		u += "?" + qs
+	}
+
+	curl := "curl"
+	if strings.ContainsAny(qs, "[]") {
+		// Keep curl from interpreting the deep object brackets as URL globbing ranges
+		curl += " -g"
+	}
+	lines := []string{fmt.Sprintf("%s -X %s %q", curl, route.Verb, u)}
+	for _, h := range headers {
+		lines = append(lines, fmt.Sprintf("-H %q", h))
+	}
+	if body != "" {
+		lines = append(lines, "-d '"+strings.Replace(body, "'", `'\''`, -1)+"'")
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// addQueryValue adds the given example value to the query string values, slices are added as
+// repeated values.
+func addQueryValue(query url.Values, name string, ex interface{}) {
+	if v := reflect.ValueOf(ex); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			query.Add(name, exampleString(v.Index(i).Interface()))
+		}
+		return
+	}
+	query.Add(name, exampleString(ex))
+}
+
+// encodeQuery encodes the query string values sorted by name like url.Values.Encode but keeps the
+// brackets of the deep object parameter names unescaped.
+func encodeQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var elems []string
+	for _, name := range names {
+		key := url.QueryEscape(name)
+		key = strings.Replace(strings.Replace(key, "%5B", "[", -1), "%5D", "]", -1)
+		for _, v := range query[name] {
+			elems = append(elems, key+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(elems, "&")
+}
+
+// hasHeader returns true if the given "Name: value" headers include the header with the given
+// name.
+func hasHeader(headers []string, name string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(strings.SplitN(h, ":", 2)[0], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// exampleString returns the string representation of the given example value as used in paths
+// and query strings.
+func exampleString(ex interface{}) string {
+	switch v := ex.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		elems := make([]string, len(keys))
+		for i, k := range keys {
+			elems[i] = k + "=" + exampleString(v[k])
+		}
+		return strings.Join(elems, ",")
+	default:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+			elems := make([]string, rv.Len())
+			for i := range elems {
+				elems[i] = exampleString(rv.Index(i).Interface())
+			}
+			return strings.Join(elems, ",")
+		}
+		return fmt.Sprint(v)
+	}
+}
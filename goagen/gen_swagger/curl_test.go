@@ -0,0 +1,180 @@
+package genswagger_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_swagger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateCurlExamples", func() {
+	var examples map[string]string
+	var security func()
+
+	BeforeEach(func() {
+		security = nil
+		dslengine.Reset()
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		examples = genswagger.GenerateCurlExamples(Design)
+	})
+
+	Context("with a GET action using query parameters", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Host("example.com")
+				Scheme("https")
+				BasePath("/api")
+				if security != nil {
+					security()
+				}
+			})
+			Resource("bottles", func() {
+				BasePath("/bottles")
+				Action("list", func() {
+					Routing(GET("/:account"))
+					Params(func() {
+						Param("account", String, func() { Example("acme") })
+						Param("year", Integer, func() { Example(1999) })
+						Param("tags", ArrayOf(String), func() { Example([]string{"red", "dry"}) })
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("sets the path and query parameters", func() {
+			Ω(examples).Should(HaveKeyWithValue("bottles.list",
+				`curl -X GET "https://example.com/api/bottles/acme?tags=red&tags=dry&year=1999"`))
+		})
+
+		Context("secured with an API key passed in the query string", func() {
+			BeforeEach(func() {
+				security = func() {
+					Security(APIKeySecurity("key", func() { Query("k") }))
+				}
+			})
+
+			It("adds the API key placeholder to the query string", func() {
+				Ω(examples).Should(HaveKeyWithValue("bottles.list",
+					`curl -X GET "https://example.com/api/bottles/acme?tags=red&tags=dry&year=1999&k=<api-key>"`))
+			})
+		})
+	})
+
+	Context("with a POST action using a payload", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Host("example.com")
+				Security(JWTSecurity("jwt", func() { Header("Authorization") }))
+			})
+			Resource("bottles", func() {
+				Action("create", func() {
+					Routing(POST("/bottles"))
+					Payload(func() {
+						Attribute("name", String, func() { Example("Number 8") })
+						Attribute("vintage", Integer, func() { Example(2012) })
+						Required("name")
+					})
+					Response(Created)
+				})
+			})
+		})
+
+		It("sets the security and content type headers and the body", func() {
+			Ω(examples).Should(HaveKeyWithValue("bottles.create", `curl -X POST "http://example.com/bottles" \
+  -H "Authorization: Bearer <token>" \
+  -H "Content-Type: application/json" \
+  -d '{"name":"Number 8","vintage":2012}'`))
+		})
+	})
+
+	Context("with a deep object query parameter", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Host("example.com")
+			})
+			Resource("bottles", func() {
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Params(func() {
+						Param("filter", func() {
+							DeepObject()
+							Attribute("status", String, func() { Example("active") })
+							Attribute("ids", ArrayOf(Integer), func() { Example([]int{1, 2}) })
+						})
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("uses the bracketed syntax and turns off curl globbing", func() {
+			Ω(examples).Should(HaveKeyWithValue("bottles.list",
+				`curl -g -X GET "http://example.com/bottles?filter[ids][]=1&filter[ids][]=2&filter[status]=active"`))
+		})
+	})
+
+	Context("with required headers and content type", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Host("example.com")
+			})
+			Resource("charges", func() {
+				Action("charge", func() {
+					Routing(POST("/charges"))
+					Headers(func() {
+						Header("X-Tenant", String, func() { Example("acme") })
+						Header("X-Trace", String)
+						Required("X-Tenant")
+					})
+					IdempotencyKey()
+					RequireContentType("application/vnd.charge+json")
+					Payload(func() {
+						Attribute("amount", Integer, func() { Example(100) })
+					})
+					Response(Created)
+				})
+			})
+		})
+
+		It("sets the required headers and the required content type", func() {
+			Ω(examples).Should(HaveKeyWithValue("charges.charge", `curl -X POST "http://example.com/charges" \
+  -H "Idempotency-Key: <Idempotency-Key>" \
+  -H "X-Tenant: acme" \
+  -H "Content-Type: application/vnd.charge+json" \
+  -d '{"amount":100}'`))
+		})
+	})
+
+	Context("with a payload field encoded as a string", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Host("example.com")
+			})
+			Resource("accounts", func() {
+				Action("create", func() {
+					Routing(POST("/accounts"))
+					Payload(func() {
+						Attribute("id", Integer, func() {
+							WireType(String)
+							Example(1252288009208053951)
+						})
+					})
+					Response(Created)
+				})
+			})
+		})
+
+		It("quotes the field value in the body", func() {
+			Ω(examples).Should(HaveKeyWithValue("accounts.create", `curl -X POST "http://example.com/accounts" \
+  -H "Content-Type: application/json" \
+  -d '{"id":"1252288009208053951"}'`))
+		})
+	})
+})
@@ -5,8 +5,12 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
@@ -30,13 +34,40 @@ func NewGenerator(options ...Option) *Generator {
 type Generator struct {
 	API      *design.APIDefinition // The API definition
 	OutDir   string                // Path to output directory
+	UI       string                // Documentation UI served by the generated handler: "redoc", "swagger" or none
+	SpecPath string                // Path to the spec served by the UI handler, uses the generated spec if empty
+	UIAssets string                // Directory containing the UI assets, downloaded if empty
 	genfiles []string              // Generated files
 }
 
+// uiAsset is a file of the documentation UI served by the generated handler.
+type uiAsset struct {
+	// Name is the name of the file.
+	Name string
+	// ContentType is the content type of the file.
+	ContentType string
+	// URL is the location the file is downloaded from when no assets directory is provided.
+	URL string
+}
+
+// uiAssets lists the assets of each documentation UI. The versions are pinned so that generating
+// the UI is reproducible.
+var uiAssets = map[string][]*uiAsset{
+	"redoc": {
+		{"redoc.standalone.js", "application/javascript", "https://unpkg.com/redoc@2.1.3/bundles/redoc.standalone.js"},
+	},
+	"swagger": {
+		{"swagger-ui.css", "text/css; charset=utf-8", "https://unpkg.com/swagger-ui-dist@3.52.5/swagger-ui.css"},
+		{"swagger-ui-bundle.js", "application/javascript", "https://unpkg.com/swagger-ui-dist@3.52.5/swagger-ui-bundle.js"},
+	},
+}
+
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
+		header, buildTags            string
 		outDir, toolDir, target, ver string
+		ui, specPath, uiAssetsDir    string
 		notool, regen                bool
 	)
 
@@ -50,13 +81,24 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("force", false, "")
 	set.Bool("notest", false, "")
+	set.Bool("legacy-signatures", false, "")
+	set.String("client-resources", "", "")
+	set.StringVar(&ui, "openapi-ui", "", "")
+	set.StringVar(&specPath, "openapi-spec", "", "")
+	set.StringVar(&uiAssetsDir, "openapi-ui-assets", "", "")
+	set.String("module-path", "", "")
+	set.StringVar(&header, "header", "", "")
+	set.StringVar(&buildTags, "build-tags", "", "")
 	set.Parse(os.Args[1:])
+	if err := codegen.ConfigureHeader(header, buildTags); err != nil {
+		return nil, err
+	}
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, API: design.Design}
+	g := &Generator{OutDir: outDir, API: design.Design, UI: ui, SpecPath: specPath, UIAssets: uiAssetsDir}
 
 	return g.Generate()
 }
@@ -66,6 +108,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if g.API == nil {
 		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
 	}
+	if g.UI != "" && g.UI != "redoc" && g.UI != "swagger" {
+		return nil, fmt.Errorf(`invalid OpenAPI UI %#v, must be "redoc" or "swagger"`, g.UI)
+	}
 
 	go utils.Catch(nil, func() { g.Cleanup() })
 
@@ -114,9 +159,110 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
 
+	if g.UI != "" {
+		if err = g.generateUI(swaggerDir, rawJSON); err != nil {
+			return nil, err
+		}
+	}
+
 	return g.genfiles, nil
 }
 
+// generateUI writes the HTML page rendering the documentation UI, the Redoc or Swagger UI assets
+// and the Go handler that serves them alongside the spec. The page, the assets and the spec are
+// all embedded in the handler package so that serving the documentation requires no external
+// files. The assets are copied from the UIAssets directory if set, downloaded otherwise.
+func (g *Generator) generateUI(swaggerDir string, rawJSON []byte) (err error) {
+	specName := "swagger.json"
+	if g.SpecPath != "" {
+		if rawJSON, err = ioutil.ReadFile(g.SpecPath); err != nil {
+			return err
+		}
+		specName = "spec" + filepath.Ext(g.SpecPath)
+		specFile := filepath.Join(swaggerDir, specName)
+		if err = ioutil.WriteFile(specFile, rawJSON, 0644); err != nil {
+			return err
+		}
+		g.genfiles = append(g.genfiles, specFile)
+	}
+	assets := uiAssets[g.UI]
+	for _, a := range assets {
+		var content []byte
+		if content, err = g.readUIAsset(a); err != nil {
+			return err
+		}
+		assetFile := filepath.Join(swaggerDir, a.Name)
+		if err = ioutil.WriteFile(assetFile, content, 0644); err != nil {
+			return err
+		}
+		g.genfiles = append(g.genfiles, assetFile)
+	}
+	data := map[string]interface{}{
+		"API":      g.API,
+		"UI":       g.UI,
+		"SpecName": specName,
+		"Assets":   assets,
+	}
+
+	htmlFile := filepath.Join(swaggerDir, "index.html")
+	hf, err := codegen.SourceFileFor(htmlFile)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, htmlFile)
+	err = hf.ExecuteTemplate("uiHTML", uiHTMLT, nil, data)
+	hf.Close()
+	if err != nil {
+		return err
+	}
+
+	handlerFile := filepath.Join(swaggerDir, "ui.go")
+	file, err := codegen.SourceFileFor(handlerFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("embed"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("strings"),
+	}
+	if err = file.WriteHeader(fmt.Sprintf("%s OpenAPI UI", g.API.Name), "swagger", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, handlerFile)
+
+	funcs := template.FuncMap{"hasSuffix": strings.HasSuffix}
+	return file.ExecuteTemplate("uiHandler", uiHandlerT, funcs, data)
+}
+
+// readUIAsset returns the content of the given UI asset read from the UIAssets directory if set or
+// downloaded from its URL otherwise.
+func (g *Generator) readUIAsset(a *uiAsset) ([]byte, error) {
+	if g.UIAssets != "" {
+		b, err := ioutil.ReadFile(filepath.Join(g.UIAssets, a.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OpenAPI UI asset: %s", err)
+		}
+		return b, nil
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(a.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download OpenAPI UI asset %s, use --openapi-ui-assets to provide it: %s", a.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download OpenAPI UI asset %s from %s, use --openapi-ui-assets to provide it: %s", a.Name, a.URL, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
 func (g *Generator) Cleanup() {
 	for _, f := range g.genfiles {
@@ -124,3 +270,72 @@ func (g *Generator) Cleanup() {
 	}
 	g.genfiles = nil
 }
+
+const uiHandlerT = `//go:embed index.html {{ .SpecName }}{{ range .Assets }} {{ .Name }}{{ end }}
+var uiFS embed.FS
+
+// NewUIHandler returns a HTTP handler that serves the {{ if eq .UI "redoc" }}Redoc{{ else }}Swagger UI{{ end }} documentation of the {{ .API.Name }} API
+// and its OpenAPI specification. prefix is the path the handler is mounted under, e.g. "/docs".
+func NewUIHandler(prefix string) http.Handler {
+	prefix = strings.TrimRight(prefix, "/")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			w.Header().Set("Allow", "GET, HEAD")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == prefix {
+			// Redirect so that the page can refer to the spec using a relative URL.
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		var name, contentType string
+		switch strings.TrimPrefix(r.URL.Path, prefix+"/") {
+		case "", "index.html":
+			name, contentType = "index.html", "text/html; charset=utf-8"
+		case "{{ .SpecName }}":
+			name, contentType = "{{ .SpecName }}", "{{ if or (hasSuffix .SpecName ".yaml") (hasSuffix .SpecName ".yml") }}application/x-yaml{{ else }}application/json{{ end }}"
+{{- range .Assets }}
+		case "{{ .Name }}":
+			name, contentType = "{{ .Name }}", "{{ .ContentType }}"
+{{- end }}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		b, err := uiFS.ReadFile(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(b)
+	})
+}
+`
+
+const uiHTMLT = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>{{ .API.Name }} API documentation</title>
+    <meta charset="utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1">{{ if eq .UI "swagger" }}
+    <link rel="stylesheet" href="swagger-ui.css">{{ end }}
+  </head>
+  <body>{{ if eq .UI "redoc" }}
+    <redoc spec-url="{{ .SpecName }}"></redoc>
+    <script src="redoc.standalone.js"></script>{{ else }}
+    <div id="swagger-ui"></div>
+    <script src="swagger-ui-bundle.js"></script>
+    <script>
+      window.onload = function() {
+        SwaggerUIBundle({url: "{{ .SpecName }}", dom_id: "#swagger-ui"});
+      };
+    </script>{{ end }}
+  </body>
+</html>
+`
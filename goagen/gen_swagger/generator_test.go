@@ -1,8 +1,13 @@
 package genswagger_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/gen_swagger"
+	"github.com/goadesign/goa/version"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -11,13 +16,19 @@ var _ = Describe("NewGenerator", func() {
 	var generator *genswagger.Generator
 
 	var args = struct {
-		api    *design.APIDefinition
-		outDir string
+		api      *design.APIDefinition
+		outDir   string
+		ui       string
+		specPath string
+		uiAssets string
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
 		},
-		outDir: "out_dir",
+		outDir:   "out_dir",
+		ui:       "redoc",
+		specPath: "spec.yaml",
+		uiAssets: "assets",
 	}
 
 	Context("with options all options set", func() {
@@ -26,6 +37,9 @@ var _ = Describe("NewGenerator", func() {
 			generator = genswagger.NewGenerator(
 				genswagger.API(args.api),
 				genswagger.OutDir(args.outDir),
+				genswagger.UI(args.ui),
+				genswagger.SpecPath(args.specPath),
+				genswagger.UIAssets(args.uiAssets),
 			)
 		})
 
@@ -33,6 +47,124 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator).ShouldNot(BeNil())
 			Ω(generator.API.Name).Should(Equal(args.api.Name))
 			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.UI).Should(Equal(args.ui))
+			Ω(generator.SpecPath).Should(Equal(args.specPath))
+			Ω(generator.UIAssets).Should(Equal(args.uiAssets))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	const testgenPackagePath = "github.com/goadesign/goa/goagen/gen_swagger/test_"
+
+	var outDir string
+	var ui, assetsDir string
+	var files []string
+	var genErr error
+	var api *design.APIDefinition
+
+	BeforeEach(func() {
+		api = design.Design
+		gopath := filepath.SplitList(os.Getenv("GOPATH"))[0]
+		outDir = filepath.Join(gopath, "src", testgenPackagePath)
+		err := os.MkdirAll(outDir, 0777)
+		Ω(err).ShouldNot(HaveOccurred())
+		ui = ""
+		assetsDir, err = ioutil.TempDir("", "assets")
+		Ω(err).ShouldNot(HaveOccurred())
+		for _, name := range []string{"redoc.standalone.js", "swagger-ui.css", "swagger-ui-bundle.js"} {
+			err = ioutil.WriteFile(filepath.Join(assetsDir, name), []byte("/* "+name+" */"), 0644)
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+		design.Design = &design.APIDefinition{
+			Name:        "testapi",
+			Title:       "dummy API with no resource",
+			Description: "I told you it's dummy",
+		}
+	})
+
+	JustBeforeEach(func() {
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		if ui != "" {
+			os.Args = append(os.Args, "--openapi-ui="+ui, "--openapi-ui-assets="+assetsDir)
+		}
+		files, genErr = genswagger.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+		os.RemoveAll(assetsDir)
+		design.Design = api
+	})
+
+	It("generates the spec only", func() {
+		Ω(genErr).Should(BeNil())
+		Ω(files).Should(HaveLen(3))
+	})
+
+	Context("with the redoc UI", func() {
+		BeforeEach(func() {
+			ui = "redoc"
+		})
+
+		It("generates the UI handler", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "swagger", "ui.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "ui.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("//go:embed index.html swagger.json redoc.standalone.js"))
+			Ω(string(content)).Should(ContainSubstring("func NewUIHandler(prefix string) http.Handler {"))
+			Ω(string(content)).Should(ContainSubstring(`name, contentType = "swagger.json", "application/json"`))
+			Ω(string(content)).Should(ContainSubstring(`name, contentType = "redoc.standalone.js", "application/javascript"`))
+			html, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "index.html"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(html)).Should(ContainSubstring(`<redoc spec-url="swagger.json"></redoc>`))
+			Ω(string(html)).Should(ContainSubstring(`<script src="redoc.standalone.js"></script>`))
+		})
+
+		It("copies the UI assets alongside the spec", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "swagger", "redoc.standalone.js")))
+			js, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "redoc.standalone.js"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(js)).Should(Equal("/* redoc.standalone.js */"))
+		})
+
+		Context("with a missing asset", func() {
+			BeforeEach(func() {
+				Ω(os.Remove(filepath.Join(assetsDir, "redoc.standalone.js"))).ShouldNot(HaveOccurred())
+			})
+
+			It("fails", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(genErr.Error()).Should(ContainSubstring("redoc.standalone.js"))
+			})
+		})
+	})
+
+	Context("with the swagger UI", func() {
+		BeforeEach(func() {
+			ui = "swagger"
+		})
+
+		It("generates the Swagger UI page", func() {
+			Ω(genErr).Should(BeNil())
+			html, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "index.html"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(html)).Should(ContainSubstring(`SwaggerUIBundle({url: "swagger.json", dom_id: "#swagger-ui"});`))
+			Ω(string(html)).Should(ContainSubstring(`<link rel="stylesheet" href="swagger-ui.css">`))
+			Ω(string(html)).Should(ContainSubstring(`<script src="swagger-ui-bundle.js"></script>`))
+			Ω(string(html)).ShouldNot(ContainSubstring("https://"))
+		})
+	})
+
+	Context("with an unknown UI", func() {
+		BeforeEach(func() {
+			ui = "foo"
+		})
+
+		It("fails", func() {
+			Ω(genErr).Should(HaveOccurred())
 		})
 	})
 })
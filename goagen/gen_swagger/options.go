@@ -18,3 +18,24 @@ func OutDir(outDir string) Option {
 		g.OutDir = outDir
 	}
 }
+
+//UI Documentation UI served by the generated handler, "redoc" or "swagger"
+func UI(ui string) Option {
+	return func(g *Generator) {
+		g.UI = ui
+	}
+}
+
+//SpecPath Path to the spec served by the UI handler instead of the generated one
+func SpecPath(specPath string) Option {
+	return func(g *Generator) {
+		g.SpecPath = specPath
+	}
+}
+
+//UIAssets Directory containing the documentation UI assets, the assets are downloaded if empty
+func UIAssets(dir string) Option {
+	return func(g *Generator) {
+		g.UIAssets = dir
+	}
+}
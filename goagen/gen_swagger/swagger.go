@@ -615,6 +615,31 @@ func tagNamesFromDefinitions(mdatas ...dslengine.MetadataDefinition) (tagNames [
 	return
 }
 
+// addTag adds a tag with the given name to the top level tags of the Swagger specification
+// unless one already exists. The tags are kept sorted by name.
+func addTag(s *Swagger, name, description string) {
+	for _, t := range s.Tags {
+		if t.Name == name {
+			if t.Description == "" {
+				t.Description = description
+			}
+			return
+		}
+	}
+	s.Tags = append(s.Tags, &Tag{Name: name, Description: description})
+	sort.SliceStable(s.Tags, func(i, j int) bool { return s.Tags[i].Name < s.Tags[j].Name })
+}
+
+// containsString returns true if vals contains val.
+func containsString(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
 func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) string {
 	for n, mdata := range metadata {
 		if n == "swagger:summary" && len(mdata) > 0 {
@@ -659,8 +684,7 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 	if obj == nil {
 		return nil, fmt.Errorf("invalid parameters definition, not an object")
 	}
-	res := make([]*Parameter, len(obj))
-	i := 0
+	var res []*Parameter
 	wildcards := design.ExtractWildcards(path)
 	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
 		in := "query"
@@ -672,14 +696,33 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 				break
 			}
 		}
+		if at.IsDeepObject() {
+			res = append(res, deepObjectParams(at, n, required)...)
+			return nil
+		}
 		param := paramFor(at, n, in, required)
-		res[i] = param
-		i++
+		res = append(res, param)
 		return nil
 	})
 	return res, nil
 }
 
+// deepObjectParams returns one query string parameter per field of the given object parameter.
+// The field parameters use the bracketed syntax, e.g. "filter[status]".
+func deepObjectParams(param *design.AttributeDefinition, name string, required bool) []*Parameter {
+	obj := param
+	if ds, ok := param.Type.(design.DataStructure); ok {
+		obj = ds.Definition()
+	}
+	var res []*Parameter
+	obj.Type.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		pname := fmt.Sprintf("%s[%s]", name, n)
+		res = append(res, paramFor(at, pname, "query", required && obj.IsRequired(n)))
+		return nil
+	})
+	return res
+}
+
 func paramsFromHeaders(action *design.ActionDefinition) []*Parameter {
 	params := []*Parameter{}
 	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
@@ -723,6 +766,9 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 	if at.Type.IsArray() {
 		p.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
 		p.CollectionFormat = "multi"
+		if style := at.ArrayStyle(); style != "" {
+			p.CollectionFormat = style
+		}
 	}
 	p.Extensions = extensionsFromDefinition(at.Metadata)
 	initValidations(at, p)
@@ -777,7 +823,11 @@ func itemsFromDefinition(at *design.AttributeDefinition) *Items {
 func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
 	var schema *genschema.JSONSchema
 	if r.MediaType != "" {
-		if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+		mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]
+		if r.MediaType == design.ProblemDetailsMediaIdentifier {
+			mt, ok = design.ProblemDetailsMedia, true
+		}
+		if ok {
 			view := r.ViewName
 			if view == "" {
 				view = design.DefaultView
@@ -911,7 +961,16 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
 	if len(tagNames) == 0 {
 		// By default tag with resource name
-		tagNames = []string{route.Parent.Parent.Name}
+		tagNames = []string{action.Parent.Name}
+		addTag(s, action.Parent.Name, action.Parent.Description)
+	}
+	for _, md := range []dslengine.MetadataDefinition{action.Parent.Metadata, action.Metadata} {
+		for _, name := range md["swagger:tags"] {
+			if !containsString(tagNames, name) {
+				tagNames = append(tagNames, name)
+			}
+			addTag(s, name, "")
+		}
 	}
 	params, err := paramsFromDefinition(action.AllParams(), route.FullPath())
 	if err != nil {
@@ -922,6 +981,18 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 
 	responses := make(map[string]*Response, len(action.Responses))
 	for _, r := range action.Responses {
+		if emt := action.Parent.ErrorMedia(); emt != nil && r.MediaType == design.ErrorMediaIdentifier {
+			// The resource renders errors using its own media type, the response cannot be
+			// shared with other resources.
+			dup := r.Dup()
+			dup.MediaType = emt.Identifier
+			dup.Standard = false
+			r = dup
+		} else if api.ProblemTypeBase != "" && r.MediaType == design.ErrorMediaIdentifier {
+			dup := r.Dup()
+			dup.MediaType = design.ProblemDetailsMediaIdentifier
+			r = dup
+		}
 		resp, err := responseFromDefinition(s, api, r)
 		if err != nil {
 			return err
@@ -951,7 +1022,10 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		}
 	}
 
-	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	operationID := action.OperationID()
+	if operationID == "" {
+		operationID = fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	}
 	index := 0
 	for i, rt := range action.Routes {
 		if rt == route {
@@ -977,7 +1051,7 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Parameters:   params,
 		Responses:    responses,
 		Schemes:      schemes,
-		Deprecated:   false,
+		Deprecated:   action.IsDeprecated(),
 		Extensions:   extensionsFromDefinition(route.Metadata),
 	}
 
@@ -985,18 +1059,22 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		operation.Consumes = append(operation.Consumes, "multipart/form-data")
 	}
 
-	computeProduces(operation, s, action)
+	computeProduces(operation, s, api, action)
 	applySecurity(operation, action.Security)
 
 	computePaths(operation, s, route, basePath)
 	return nil
 }
 
-func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
+func computeProduces(operation *Operation, s *Swagger, api *design.APIDefinition, action *design.ActionDefinition) {
 	produces := make(map[string]struct{})
 	action.IterateResponses(func(resp *design.ResponseDefinition) error {
 		if resp.MediaType != "" {
-			produces[resp.MediaType] = struct{}{}
+			mt := resp.MediaType
+			if mt == design.ErrorMediaIdentifier && api.ProblemTypeBase != "" && action.Parent.ErrorMedia() == nil {
+				mt = design.ProblemDetailsMediaIdentifier
+			}
+			produces[mt] = struct{}{}
 		}
 		return nil
 	})
@@ -1068,6 +1146,15 @@ func computePaths(operation *Operation, s *Swagger, route *design.RouteDefinitio
 		p.Patch = operation
 	}
 	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
+	switch route.Verb {
+	case "GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH":
+	default:
+		// Swagger only supports the standard methods, describe the others with extensions.
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions["x-"+strings.ToLower(route.Verb)] = operation
+	}
 }
 
 func applySecurity(operation *Operation, security *design.SecurityDefinition) {
@@ -1198,13 +1285,37 @@ func initMaxLengthValidation(def interface{}, isArray bool, max *int) {
 	}
 }
 
+func initItemsValidation(def interface{}, min, max *int, unique bool) {
+	var minItems, maxItems **int
+	var uniqueItems *bool
+	switch actual := def.(type) {
+	case *Parameter:
+		minItems, maxItems, uniqueItems = &actual.MinItems, &actual.MaxItems, &actual.UniqueItems
+	case *Header:
+		minItems, maxItems, uniqueItems = &actual.MinItems, &actual.MaxItems, &actual.UniqueItems
+	case *Items:
+		minItems, maxItems, uniqueItems = &actual.MinItems, &actual.MaxItems, &actual.UniqueItems
+	default:
+		return
+	}
+	if min != nil {
+		*minItems = min
+	}
+	if max != nil {
+		*maxItems = max
+	}
+	if unique {
+		*uniqueItems = true
+	}
+}
+
 func initValidations(attr *design.AttributeDefinition, def interface{}) {
 	val := attr.Validation
 	if val == nil {
 		return
 	}
 	initEnumValidation(def, val.Values)
-	initFormatValidation(def, val.Format)
+	initFormatValidation(def, design.OpenAPIFormat(val.Format))
 	initPatternValidation(def, val.Pattern)
 	if val.Minimum != nil {
 		initMinimumValidation(def, val.Minimum)
@@ -1218,4 +1329,7 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 	if val.MaxLength != nil {
 		initMaxLengthValidation(def, attr.Type.IsArray(), val.MaxLength)
 	}
+	if val.MinItems != nil || val.MaxItems != nil || val.UniqueItems {
+		initItemsValidation(def, val.MinItems, val.MaxItems, val.UniqueItems)
+	}
 }
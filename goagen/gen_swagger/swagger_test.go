@@ -214,6 +214,70 @@ var _ = Describe("New", func() {
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 		})
 
+		Context("with a custom format", func() {
+			BeforeEach(func() {
+				RegisterFormat("isbn", func(string) error { return nil }, "isbn-13")
+				p := Type("Book", func() {
+					Attribute("isbn", String, func() {
+						Format("isbn")
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PUT("/:isbn"),
+						)
+						Params(func() {
+							Param("isbn", String, func() {
+								Format("isbn")
+							})
+						})
+						Payload(p)
+					})
+				})
+			})
+
+			It("uses the registered OpenAPI format", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				params := swagger.Paths["/{isbn}"].(*genswagger.Path).Put.Parameters
+				Ω(params).ShouldNot(BeEmpty())
+				Ω(params[0].Format).Should(Equal("isbn-13"))
+				Ω(swagger.Definitions["Book"].Properties["isbn"].Format).Should(Equal("isbn-13"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with items validations", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Params(func() {
+							Param("ids", ArrayOf(Integer), func() {
+								MinItems(1)
+								MaxItems(10)
+								UniqueItems()
+							})
+						})
+					})
+				})
+			})
+
+			It("sets the minItems, maxItems and uniqueItems fields", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				params := swagger.Paths["/"].(*genswagger.Path).Get.Parameters
+				Ω(params).Should(HaveLen(1))
+				Ω(*params[0].MinItems).Should(Equal(1))
+				Ω(*params[0].MaxItems).Should(Equal(10))
+				Ω(params[0].UniqueItems).Should(BeTrue())
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with required payload", func() {
 			BeforeEach(func() {
 				p := Type("RequiredPayload", func() {
@@ -258,6 +322,32 @@ var _ = Describe("New", func() {
 
 		})
 
+		Context("with a payload example that skips validation", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PUT("/"),
+						)
+						Payload(func() {
+							Member("card", String, func() {
+								Pattern("^[0-9]{16}$")
+								Example("XXXX-XXXX-XXXX-1234", func() {
+									SkipValidation()
+								})
+							})
+						})
+					})
+				})
+			})
+
+			It("serializes the example", func() {
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"example":"XXXX-XXXX-XXXX-1234"`),
+				})
+			})
+		})
+
 		Context("with optional payload", func() {
 			BeforeEach(func() {
 				p := Type("OptionalPayload", func() {
@@ -737,6 +827,131 @@ var _ = Describe("New", func() {
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 		})
 
+		Context("with a deep object param", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("list", func() {
+						Routing(GET("/"))
+						Params(func() {
+							Param("filter", func() {
+								DeepObject()
+								Attribute("status", String)
+								Attribute("ids", ArrayOf(Integer))
+								Required("status")
+							})
+							Required("filter")
+						})
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("generates one query string parameter per field", func() {
+				ps := swagger.Paths["/"].(*genswagger.Path).Get.Parameters
+				Ω(ps).Should(HaveLen(2))
+				Ω(ps[0].Name).Should(Equal("filter[ids]"))
+				Ω(ps[0].In).Should(Equal("query"))
+				Ω(ps[0].Type).Should(Equal("array"))
+				Ω(ps[0].Required).Should(BeFalse())
+				Ω(ps[1].Name).Should(Equal("filter[status]"))
+				Ω(ps[1].Type).Should(Equal("string"))
+				Ω(ps[1].Required).Should(BeTrue())
+			})
+		})
+
+		Context("with a deprecated action", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("list", func() {
+						Routing(GET("/"))
+						Deprecated(func() {
+							Sunset("2025-12-31")
+						})
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("flags the operation as deprecated", func() {
+				Ω(swagger.Paths["/"].(*genswagger.Path).Get.Deprecated).Should(BeTrue())
+			})
+		})
+
+		Context("with an array param using a style", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("list", func() {
+						Routing(GET("/"))
+						Params(func() {
+							Param("ids", ArrayOf(Integer), func() {
+								ArrayParamStyle("csv")
+							})
+							Param("tags", ArrayOf(String))
+						})
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("sets the collection format of the parameter", func() {
+				ps := swagger.Paths["/"].(*genswagger.Path).Get.Parameters
+				Ω(ps).Should(HaveLen(2))
+				Ω(ps[0].Name).Should(Equal("ids"))
+				Ω(ps[0].CollectionFormat).Should(Equal("csv"))
+				Ω(ps[1].Name).Should(Equal("tags"))
+				Ω(ps[1].CollectionFormat).Should(Equal("multi"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with tags", func() {
+			BeforeEach(func() {
+				Resource("invoice", func() {
+					Description("Invoice management")
+					Tags("billing")
+					Action("pay", func() {
+						Routing(POST("/invoices/:id/pay"))
+						Tags("v2", "billing")
+						Response(NoContent)
+					})
+					Action("show", func() {
+						Routing(GET("/invoices/:id"))
+						Response(NoContent)
+					})
+				})
+				Resource("account", func() {
+					Description("Account management")
+					Action("show", func() {
+						Routing(GET("/accounts/:id"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("tags the operations with the resource name and the extra tags", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				pay := swagger.Paths["/invoices/{id}/pay"].(*genswagger.Path)
+				Ω(pay.Post.Tags).Should(Equal([]string{"invoice", "billing", "v2"}))
+				show := swagger.Paths["/invoices/{id}"].(*genswagger.Path)
+				Ω(show.Get.Tags).Should(Equal([]string{"invoice", "billing"}))
+				account := swagger.Paths["/accounts/{id}"].(*genswagger.Path)
+				Ω(account.Get.Tags).Should(Equal([]string{"account"}))
+			})
+
+			It("lists all the tags sorted by name", func() {
+				var names []string
+				for _, t := range swagger.Tags {
+					names = append(names, t.Name)
+				}
+				Ω(names).Should(Equal([]string{"account", "billing", "invoice", "tag", "v2"}))
+				Ω(swagger.Tags[0].Description).Should(Equal("Account management"))
+				Ω(swagger.Tags[2].Description).Should(Equal("Invoice management"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with metadata", func() {
 			const gat = "gat"
 			const extension = `{"foo":"bar"}`
@@ -824,4 +1039,121 @@ var _ = Describe("New", func() {
 
 		})
 	})
+	Context("with problem details", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				ProblemDetails("https://goa.design/problems/")
+			})
+			Resource("res", func() {
+				Action("show", func() {
+					Routing(GET("/"))
+					Response(NoContent)
+					Response(BadRequest, ErrorMedia)
+				})
+			})
+		})
+
+		It("describes error responses with the problem details schema", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			op := swagger.Paths["/"].(*genswagger.Path).Get
+			Ω(op.Responses["400"].Schema.Ref).Should(Equal("#/definitions/ProblemDetails"))
+			Ω(op.Produces).Should(Equal([]string{"application/problem+json"}))
+			Ω(swagger.Definitions).Should(HaveKey("ProblemDetails"))
+			Ω(swagger.Definitions["ProblemDetails"].Properties).Should(HaveKey("type"))
+			Ω(swagger.Definitions["ProblemDetails"].Properties).Should(HaveKey("instance"))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+	})
+
+	Context("with types extending a base type", func() {
+		BeforeEach(func() {
+			animal := Type("Animal", func() {
+				Attribute("kind", String, func() {
+					Enum("Dog", "Cat")
+				})
+				Attribute("name", String)
+				Discriminator("kind")
+			})
+			dog := Type("Dog", func() {
+				Extend(animal)
+				Attribute("barks", Boolean)
+				Required("barks")
+			})
+			Type("Cat", func() {
+				Extend(animal)
+			})
+			API("test", func() {})
+			Resource("res", func() {
+				Action("create", func() {
+					Routing(POST("/"))
+					Payload(dog)
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("sets the discriminator on the base type definition", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.Definitions).Should(HaveKey("Animal"))
+			animal := swagger.Definitions["Animal"]
+			Ω(animal.Discriminator).Should(Equal("kind"))
+			Ω(animal.Required).Should(ContainElement("kind"))
+		})
+
+		It("describes the extending type with allOf", func() {
+			Ω(swagger.Definitions).Should(HaveKey("Dog"))
+			dog := swagger.Definitions["Dog"]
+			Ω(dog.AllOf).Should(HaveLen(2))
+			Ω(dog.AllOf[0].Ref).Should(Equal("#/definitions/Animal"))
+			Ω(dog.AllOf[1].Properties).Should(HaveLen(1))
+			Ω(dog.AllOf[1].Properties).Should(HaveKey("barks"))
+			Ω(dog.AllOf[1].Required).Should(Equal([]string{"barks"}))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+	})
+	Context("with an action using a custom HTTP method", func() {
+		BeforeEach(func() {
+			API("test", func() {})
+			Resource("res", func() {
+				Action("find", func() {
+					Routing(Method("PROPFIND", "/props"))
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("describes the operation with a path extension", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.Paths).Should(HaveKey("/props"))
+			p := swagger.Paths["/props"].(*genswagger.Path)
+			Ω(p.Extensions).Should(HaveKey("x-propfind"))
+			op := p.Extensions["x-propfind"].(*genswagger.Operation)
+			Ω(op.OperationID).Should(Equal("res#find"))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+	})
+
+	Context("with an action that overrides its operation ID", func() {
+		BeforeEach(func() {
+			API("test", func() {})
+			Resource("users", func() {
+				Action("list", func() {
+					Routing(GET("/users"), GET("/people"))
+					OperationID("listUsersV2")
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("uses the operation ID", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.Paths["/users"].(*genswagger.Path).Get.OperationID).Should(Equal("listUsersV2"))
+			Ω(swagger.Paths["/people"].(*genswagger.Path).Get.OperationID).Should(Equal("listUsersV2#1"))
+		})
+
+		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+	})
 })
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/meta"
 	"github.com/goadesign/goa/goagen/utils"
@@ -48,6 +49,9 @@ package and tool and the Swagger specification for the API.
 	rootCmd.PersistentFlags().StringP("out", "o", ".", "output directory")
 	rootCmd.PersistentFlags().StringVarP(&designPkg, "design", "d", "", "design package import path")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug mode, does not cleanup temporary files.")
+	rootCmd.PersistentFlags().String("module-path", "", "import path of the output directory, used to compute the import paths of the generated packages when the output directory is not in GOPATH")
+	rootCmd.PersistentFlags().String("header", "", "path to a file containing the template of the header written at the top of the generated Go files, e.g. a license header")
+	rootCmd.PersistentFlags().String("build-tags", "", `build constraint added to the generated Go files with a "//go:build" line, e.g. "test"`)
 
 	// versionCmd implements the "version" command
 	versionCmd := &cobra.Command{
@@ -61,8 +65,9 @@ package and tool and the Swagger specification for the API.
 
 	// appCmd implements the "app" command.
 	var (
-		pkg    string
-		notest bool
+		pkg       string
+		notest    bool
+		legacySig bool
 	)
 	appCmd := &cobra.Command{
 		Use:   "app",
@@ -71,6 +76,7 @@ package and tool and the Swagger specification for the API.
 	}
 	appCmd.Flags().StringVar(&pkg, "pkg", "app", "Name of generated Go package containing controllers supporting code (contexts, media types, user types etc.)")
 	appCmd.Flags().BoolVar(&notest, "notest", false, "Prevent generation of test helpers")
+	appCmd.Flags().BoolVar(&legacySig, "legacy-signatures", false, "Generate test helpers that accept the testing interface before the context (legacy signatures)")
 	rootCmd.AddCommand(appCmd)
 
 	// mainCmd implements the "main" command.
@@ -88,8 +94,9 @@ package and tool and the Swagger specification for the API.
 
 	// clientCmd implements the "client" command.
 	var (
-		toolDir, tool string
-		notool        bool
+		toolDir, tool   string
+		clientResources string
+		notool          bool
 	)
 	clientCmd := &cobra.Command{
 		Use:   "client",
@@ -100,14 +107,22 @@ package and tool and the Swagger specification for the API.
 	clientCmd.Flags().StringVar(&toolDir, "tooldir", "tool", "Name of generated tool directory")
 	clientCmd.Flags().StringVar(&tool, "tool", "[API-name]-cli", "Name of generated tool")
 	clientCmd.Flags().BoolVar(&notool, "notool", false, "Prevent generation of cli tool")
+	clientCmd.Flags().BoolVar(&legacySig, "legacy-signatures", false, "Generate decode functions that don't accept a context (legacy signatures)")
+	clientCmd.Flags().StringVar(&clientResources, "client-resources", "", "Comma separated list of the resources to generate the client for, all the resources by default")
 	rootCmd.AddCommand(clientCmd)
 
 	// swaggerCmd implements the "swagger" command.
+	var (
+		openapiUI, openapiSpec, openapiUIAssets string
+	)
 	swaggerCmd := &cobra.Command{
 		Use:   "swagger",
 		Short: "Generate Swagger",
 		Run:   func(c *cobra.Command, _ []string) { files, err = run("genswagger", c) },
 	}
+	swaggerCmd.Flags().StringVar(&openapiUI, "openapi-ui", "", `Generate a HTTP handler serving the spec with the given documentation UI ("redoc" or "swagger")`)
+	swaggerCmd.Flags().StringVar(&openapiSpec, "openapi-spec", "", "Path to the spec served by the documentation UI handler, defaults to the generated spec")
+	swaggerCmd.Flags().StringVar(&openapiUIAssets, "openapi-ui-assets", "", "Directory containing the documentation UI assets, downloaded at generation time if not set")
 	rootCmd.AddCommand(swaggerCmd)
 
 	// jsCmd implements the "js" command.
@@ -128,11 +143,15 @@ package and tool and the Swagger specification for the API.
 	rootCmd.AddCommand(jsCmd)
 
 	// schemaCmd implements the "schema" command.
+	var (
+		validator bool
+	)
 	schemaCmd := &cobra.Command{
 		Use:   "schema",
 		Short: "Generate JSON Schema",
 		Run:   func(c *cobra.Command, _ []string) { files, err = run("genschema", c) },
 	}
+	schemaCmd.Flags().BoolVar(&validator, "validator", false, "Generate a HTTP middleware that validates the request bodies against the JSON schema")
 	rootCmd.AddCommand(schemaCmd)
 
 	// genCmd implements the "gen" command.
@@ -232,6 +251,10 @@ package and tool and the Swagger specification for the API.
 		os.Exit(1)
 	}
 
+	// Print the warnings reported by the generators once, "bootstrap" runs the DSL once per
+	// generator.
+	dslengine.PrintWarnings()
+
 	rels := make([]string, len(files))
 	cd, _ := os.Getwd()
 	for i, f := range files {
@@ -287,6 +310,21 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 	if err != nil {
 		return nil, err
 	}
+	if mp, ok := m["module-path"]; ok {
+		if err := codegen.ValidateImportPath(mp); err != nil {
+			return nil, err
+		}
+	}
+	if bt, ok := m["build-tags"]; ok {
+		if err := codegen.ValidateBuildTags(bt); err != nil {
+			return nil, err
+		}
+	}
+	if h, ok := m["header"]; ok {
+		if m["header"], err = filepath.Abs(h); err != nil {
+			return nil, err
+		}
+	}
 
 	gen, err := meta.NewGenerator(
 		pkgName+".Generate",
@@ -347,17 +385,19 @@ func runCommands(root *cobra.Command) {
 // Remember to update as goagen commands and flags evolve
 //
 // The flag argument values use variable names that cary semantic:
-// $DIR for file system directories, $DESIGN_PKG for import path to Go goa design Go packages, $PKG
-// for import path to any Go package.
+// $DIR for file system directories, $FILE for files, $DESIGN_PKG for import path to Go goa design
+// Go packages, $PKG for import path to any Go package.
 func flagJSON(fl *pflag.Flag) *flag {
 	f := &flag{Long: fl.Name, Short: fl.Shorthand, Description: fl.Usage}
 	f.Required = fl.Name == "pkg-path" || fl.Name == "design"
 	switch fl.Name {
 	case "out":
 		f.Argument = "$DIR"
+	case "header":
+		f.Argument = "$FILE"
 	case "design":
 		f.Argument = "$DESIGN_PKG"
-	case "pkg-path":
+	case "pkg-path", "module-path":
 		f.Argument = "$PKG"
 	}
 	return f
@@ -1,6 +1,7 @@
 package meta
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/version"
 )
@@ -191,11 +193,23 @@ func (m *Generator) spawn(genbin string) ([]string, error) {
 	args = append(args, "--version="+version.String())
 	args = append(args, m.CustomFlags...)
 	cmd := exec.Command(genbin, args...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("%s\n%s", err, string(out))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s\n%s%s", err, stderr.String(), stdout.String())
+	}
+	// Collect the DSL warnings so that goagen prints them once even when running multiple
+	// generators, forward anything else.
+	for _, line := range strings.SplitAfter(stderr.String(), "\n") {
+		if strings.HasPrefix(line, dslengine.WarningPrefix) {
+			w := strings.TrimSuffix(strings.TrimPrefix(line, dslengine.WarningPrefix), "\n")
+			dslengine.Warnings = append(dslengine.Warnings, w)
+			continue
+		}
+		os.Stderr.WriteString(line)
 	}
-	res := strings.Split(string(out), "\n")
+	res := strings.Split(stdout.String(), "\n")
 	for (len(res) > 0) && (res[len(res)-1] == "") {
 		res = res[:len(res)-1]
 	}
@@ -209,6 +223,7 @@ func main() {
 
 	// Now run the secondary DSLs
 	dslengine.FailOnError(dslengine.Run())
+	dslengine.PrintWarnings()
 
 	files, err := {{.Genfunc}}()
 	dslengine.FailOnError(err)
@@ -0,0 +1,13 @@
+/*
+Package jsonschema provides a HTTP middleware that validates the request bodies against a JSON
+schema document. The middleware is meant to be used with the request validator generated by
+"goagen schema --validator" which validates the payloads of the API actions against the JSON
+schema produced by goagen. This makes it possible to catch any divergence between the published
+schema and the validations performed by the generated Go code.
+
+The validator is implemented in pure Go and supports the subset of JSON schema draft 4 used by the
+schemas that goagen generates: "$ref", "type", "enum", "format", "pattern", "minimum", "maximum",
+"minLength", "maxLength", "minItems", "maxItems", "uniqueItems", "required", "properties",
+"additionalProperties", "items", "anyOf", "allOf" and the "x-nullable" extension.
+*/
+package jsonschema
@@ -0,0 +1,145 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// Route identifies the requests whose body is validated and the schema used to validate them.
+type Route struct {
+	// Method is the HTTP method of the requests, e.g. "POST".
+	Method string
+	// Path is the path of the requests as defined in the design, it may contain wildcards,
+	// e.g. "/bottles/:id".
+	Path string
+	// Ref is the JSON reference to the schema the request bodies must validate against, e.g.
+	// "#/definitions/CreateBottlePayload".
+	Ref string
+}
+
+// New returns a HTTP middleware that validates the JSON bodies of the requests that match the
+// given routes against the given JSON schema document. Requests whose body is invalid are rejected
+// with a 400 Bad Request response whose body is a goa error that lists the violations in its
+// "errors" meta field. Each violation carries the JSON pointer to the invalid element and to the
+// violated schema keyword. Requests that do not match any route, that have no body or whose
+// content type is not JSON are passed to the next handler unchanged. New returns an error if the
+// schema document is invalid or if a route references a schema that it does not define.
+//
+// The middleware wraps the HTTP handler of the service so that the request bodies are validated
+// before they are decoded:
+//
+//	validate, err := jsonschema.New(schemaJSON, routes)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	http.ListenAndServe(":8080", validate(service.Mux))
+func New(schema []byte, routes []Route) (func(http.Handler) http.Handler, error) {
+	s, err := NewSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		if _, err := s.resolve(r.Ref); err != nil {
+			return nil, fmt.Errorf("invalid route %s %s: %s", r.Method, r.Path, err)
+		}
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ref := match(routes, req)
+			if ref == "" || req.Body == nil || !isJSON(req.Header.Get("Content-Type")) {
+				h.ServeHTTP(w, req)
+				return
+			}
+			body, err := ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				reject(w, goa.ErrBadRequest(err))
+				return
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			if len(bytes.TrimSpace(body)) == 0 {
+				h.ServeHTTP(w, req)
+				return
+			}
+			errs, err := s.Validate(ref, body)
+			if err != nil {
+				reject(w, goa.ErrBadRequest(fmt.Sprintf("invalid JSON request body: %s", err)))
+				return
+			}
+			if len(errs) > 0 {
+				msgs := make([]string, len(errs))
+				for i, e := range errs {
+					msgs[i] = e.Error()
+				}
+				msg := "request body does not validate against the JSON schema: " + strings.Join(msgs, "; ")
+				reject(w, goa.ErrBadRequest(msg, "errors", errs))
+				return
+			}
+			h.ServeHTTP(w, req)
+		})
+	}, nil
+}
+
+// match returns the schema reference of the first route that matches the request, the empty
+// string if none.
+func match(routes []Route, req *http.Request) string {
+	for _, r := range routes {
+		if r.Method == req.Method && matchPath(r.Path, req.URL.Path) {
+			return r.Ref
+		}
+	}
+	return ""
+}
+
+// matchPath returns true if the request path matches the route path. Route path segments that
+// start with ":" match any non-empty segment and segments that start with "*" match the remainder
+// of the path.
+func matchPath(pattern, path string) bool {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	rs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, p := range ps {
+		if strings.HasPrefix(p, "*") {
+			return true
+		}
+		if i >= len(rs) {
+			return false
+		}
+		if strings.HasPrefix(p, ":") {
+			if rs[i] == "" {
+				return false
+			}
+			continue
+		}
+		if p != rs[i] {
+			return false
+		}
+	}
+	return len(ps) == len(rs)
+}
+
+// isJSON returns true if the given content type is empty, goa decodes such bodies as JSON, or is a
+// JSON media type.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || mt == "text/json" || strings.HasSuffix(mt, "+json")
+}
+
+// reject writes the response for the given bad request error.
+func reject(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", goa.ErrorMediaIdentifier)
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(err)
+}
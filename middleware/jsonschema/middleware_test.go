@@ -0,0 +1,147 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa/middleware/jsonschema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const schema = `{
+	"definitions": {
+		"Bottle": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 2},
+				"color": {"type": "string", "enum": ["red", "white"]},
+				"vintage": {"type": "integer", "minimum": 1900},
+				"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+			},
+			"required": ["name"]
+		},
+		"Wrapper": {
+			"allOf": [{"$ref": "#/definitions/Bottle"}, {"type": "object", "required": ["vintage"]}]
+		}
+	}
+}`
+
+var _ = Describe("New", func() {
+	var routes []jsonschema.Route
+	var method, path, contentType, body string
+	var rw *httptest.ResponseRecorder
+	var received string
+	var newErr error
+
+	BeforeEach(func() {
+		routes = []jsonschema.Route{
+			{Method: "POST", Path: "/bottles/:id", Ref: "#/definitions/Bottle"},
+			{Method: "PUT", Path: "/wrappers/*rest", Ref: "#/definitions/Wrapper"},
+		}
+		method, path, contentType = "POST", "/bottles/1", "application/json"
+		body = `{"name": "Number 8", "vintage": 2012}`
+		received = ""
+	})
+
+	JustBeforeEach(func() {
+		var validate func(http.Handler) http.Handler
+		validate, newErr = jsonschema.New([]byte(schema), routes)
+		if newErr != nil {
+			return
+		}
+		h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			received = string(b)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rw = httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+	})
+
+	It("passes valid requests with their body to the handler", func() {
+		Ω(newErr).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(http.StatusNoContent))
+		Ω(received).Should(Equal(body))
+	})
+
+	Context("with a body that violates the schema", func() {
+		BeforeEach(func() {
+			body = `{"name": "x", "color": "blue", "vintage": 20.5, "tags": ["a", "b", "c"]}`
+		})
+
+		It("rejects the request with path annotated errors", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(http.StatusBadRequest))
+			Ω(received).Should(BeEmpty())
+			var resp struct {
+				Detail string `json:"detail"`
+				Meta   struct {
+					Errors []*jsonschema.ValidationError `json:"errors"`
+				} `json:"meta"`
+			}
+			Ω(json.Unmarshal(rw.Body.Bytes(), &resp)).Should(Succeed())
+			Ω(resp.Meta.Errors).Should(HaveLen(4))
+			Ω(resp.Meta.Errors[0].Path).Should(Equal("/color"))
+			Ω(resp.Meta.Errors[0].SchemaPath).Should(Equal("#/definitions/Bottle/properties/color/enum"))
+			Ω(resp.Meta.Errors[1].Path).Should(Equal("/name"))
+			Ω(resp.Meta.Errors[1].SchemaPath).Should(Equal("#/definitions/Bottle/properties/name/minLength"))
+			Ω(resp.Meta.Errors[2].Path).Should(Equal("/tags"))
+			Ω(resp.Meta.Errors[2].SchemaPath).Should(Equal("#/definitions/Bottle/properties/tags/maxItems"))
+			Ω(resp.Meta.Errors[3].Path).Should(Equal("/vintage"))
+			Ω(resp.Meta.Errors[3].SchemaPath).Should(Equal("#/definitions/Bottle/properties/vintage/type"))
+			Ω(resp.Detail).Should(ContainSubstring(`/color: value must be one of ["red","white"] (#/definitions/Bottle/properties/color/enum)`))
+		})
+	})
+
+	Context("with a body missing a required property", func() {
+		BeforeEach(func() {
+			method, path = "PUT", "/wrappers/a/b"
+			body = `{"name": "Number 8"}`
+		})
+
+		It("rejects the request", func() {
+			Ω(rw.Code).Should(Equal(http.StatusBadRequest))
+			Ω(rw.Body.String()).Should(ContainSubstring(`/: missing required property \"vintage\" (#/definitions/Wrapper/allOf/1/required)`))
+		})
+	})
+
+	Context("with a request that does not match any route", func() {
+		BeforeEach(func() {
+			path = "/bottles/1/tags"
+			body = `{"name": 1}`
+		})
+
+		It("passes the request to the handler", func() {
+			Ω(rw.Code).Should(Equal(http.StatusNoContent))
+		})
+	})
+
+	Context("with a body that is not JSON", func() {
+		BeforeEach(func() {
+			contentType = "application/xml"
+			body = `<bottle/>`
+		})
+
+		It("passes the request to the handler", func() {
+			Ω(rw.Code).Should(Equal(http.StatusNoContent))
+			Ω(received).Should(Equal(body))
+		})
+	})
+
+	Context("with a route that references an unknown schema", func() {
+		BeforeEach(func() {
+			routes = []jsonschema.Route{{Method: "POST", Path: "/bottles", Ref: "#/definitions/Unknown"}}
+		})
+
+		It("returns an error", func() {
+			Ω(newErr).Should(HaveOccurred())
+			Ω(newErr.Error()).Should(ContainSubstring("cannot be resolved"))
+		})
+	})
+})
@@ -0,0 +1,438 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// Schema is a JSON schema document that values can be validated against.
+	Schema struct {
+		// root is the decoded schema document.
+		root interface{}
+		// patterns caches the compiled regular expressions indexed by pattern.
+		patterns map[string]*regexp.Regexp
+	}
+
+	// ValidationError describes a violation of the schema by a value.
+	ValidationError struct {
+		// Path is the JSON pointer to the invalid element of the value, e.g. "/items/0/name".
+		Path string `json:"path"`
+		// SchemaPath is the JSON pointer to the schema keyword that the element violates, e.g.
+		// "#/definitions/Bottle/properties/name/minLength".
+		SchemaPath string `json:"schema_path"`
+		// Message describes the violation.
+		Message string `json:"message"`
+	}
+)
+
+// uuidRegex matches the string representation of UUIDs.
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// NewSchema parses the given JSON schema document. NewSchema returns an error if the document is
+// not valid JSON or if one of its patterns is not a valid regular expression.
+func NewSchema(doc []byte) (*Schema, error) {
+	root, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %s", err)
+	}
+	s := &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.compilePatterns(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate validates the JSON document doc against the schema that the JSON reference ref points
+// to, e.g. "#/definitions/Bottle". Validate returns the violations sorted by path, nil if the
+// document is valid. Validate returns an error if doc is not valid JSON or if ref cannot be
+// resolved.
+func (s *Schema) Validate(ref string, doc []byte) ([]*ValidationError, error) {
+	v, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := s.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	var errs []*ValidationError
+	s.validate(schema, ref, "", v, &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs, nil
+}
+
+// Error returns the message of the violation prefixed with the path of the invalid element and
+// followed by the path of the violated schema keyword.
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s (%s)", path, e.Message, e.SchemaPath)
+}
+
+// decode decodes the given JSON document using json.Number to represent numbers.
+func decode(doc []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// compilePatterns compiles the patterns defined in the given schema and its sub-schemas.
+func (s *Schema) compilePatterns(schema interface{}) error {
+	switch actual := schema.(type) {
+	case map[string]interface{}:
+		if p, ok := actual["pattern"].(string); ok {
+			if _, ok := s.patterns[p]; !ok {
+				re, err := regexp.Compile(p)
+				if err != nil {
+					return fmt.Errorf("invalid pattern %#v in JSON schema: %s", p, err)
+				}
+				s.patterns[p] = re
+			}
+		}
+		for _, v := range actual {
+			if err := s.compilePatterns(v); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, v := range actual {
+			if err := s.compilePatterns(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema that the given local JSON reference points to.
+func (s *Schema) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported JSON reference %#v, only local references are supported", ref)
+	}
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON reference %#v: %s", ref, err)
+	}
+	cur := s.root
+	if fragment != "" {
+		for _, tok := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+			tok = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+			switch actual := cur.(type) {
+			case map[string]interface{}:
+				cur = actual[tok]
+			case []interface{}:
+				i, err := strconv.Atoi(tok)
+				if err != nil || i < 0 || i >= len(actual) {
+					return nil, fmt.Errorf("JSON reference %#v cannot be resolved", ref)
+				}
+				cur = actual[i]
+			default:
+				cur = nil
+			}
+			if cur == nil {
+				return nil, fmt.Errorf("JSON reference %#v cannot be resolved", ref)
+			}
+		}
+	}
+	schema, ok := cur.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("JSON reference %#v does not point to a schema", ref)
+	}
+	return schema, nil
+}
+
+// validate validates v against schema and appends the violations to errs. schemaPath is the JSON
+// pointer to schema and path the JSON pointer to v.
+func (s *Schema) validate(schema map[string]interface{}, schemaPath, path string, v interface{}, errs *[]*ValidationError) {
+	fail := func(keyword, format string, args ...interface{}) {
+		*errs = append(*errs, &ValidationError{
+			Path:       path,
+			SchemaPath: schemaPath + "/" + keyword,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := s.resolve(ref)
+		if err != nil {
+			fail("$ref", "%s", err)
+			return
+		}
+		s.validate(target, ref, path, v, errs)
+		return
+	}
+	if v == nil {
+		if nullable, _ := schema["x-nullable"].(bool); nullable {
+			return
+		}
+	}
+	if t, ok := schema["type"]; ok && !hasType(t, v) {
+		fail("type", "value must be of type %s", typeNames(t))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if equal(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("enum", "value must be one of %s", jsonString(enum))
+		}
+	}
+	for i, sub := range subSchemas(schema["allOf"]) {
+		s.validate(sub, fmt.Sprintf("%s/allOf/%d", schemaPath, i), path, v, errs)
+	}
+	if anyOf := subSchemas(schema["anyOf"]); len(anyOf) > 0 {
+		matched := false
+		for i, sub := range anyOf {
+			var subErrs []*ValidationError
+			s.validate(sub, fmt.Sprintf("%s/anyOf/%d", schemaPath, i), path, v, &subErrs)
+			if len(subErrs) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("anyOf", "value must match at least one of the schemas")
+		}
+	}
+
+	switch actual := v.(type) {
+	case string:
+		s.validateString(schema, actual, fail)
+	case json.Number:
+		validateNumber(schema, actual, fail)
+	case []interface{}:
+		if min, ok := intKeyword(schema, "minItems"); ok && len(actual) < min {
+			fail("minItems", "array must contain at least %d items, got %d", min, len(actual))
+		}
+		if max, ok := intKeyword(schema, "maxItems"); ok && len(actual) > max {
+			fail("maxItems", "array must contain at most %d items, got %d", max, len(actual))
+		}
+		if unique, _ := schema["uniqueItems"].(bool); unique {
+		outer:
+			for i := range actual {
+				for j := i + 1; j < len(actual); j++ {
+					if equal(actual[i], actual[j]) {
+						fail("uniqueItems", "array items must be unique, items %d and %d are equal", i, j)
+						break outer
+					}
+				}
+			}
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, e := range actual {
+				s.validate(items, schemaPath+"/items", fmt.Sprintf("%s/%d", path, i), e, errs)
+			}
+		}
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				if n, ok := r.(string); ok {
+					if _, ok := actual[n]; !ok {
+						fail("required", "missing required property %#v", n)
+					}
+				}
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(actual))
+		for n := range actual {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			elemPath := path + "/" + escape(n)
+			if prop, ok := props[n].(map[string]interface{}); ok {
+				s.validate(prop, schemaPath+"/properties/"+escape(n), elemPath, actual[n], errs)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional && props != nil {
+					*errs = append(*errs, &ValidationError{
+						Path:       elemPath,
+						SchemaPath: schemaPath + "/additionalProperties",
+						Message:    fmt.Sprintf("property %#v is not allowed", n),
+					})
+				}
+			case map[string]interface{}:
+				s.validate(additional, schemaPath+"/additionalProperties", elemPath, actual[n], errs)
+			}
+		}
+	}
+}
+
+// validateString validates the string v against the string keywords of schema.
+func (s *Schema) validateString(schema map[string]interface{}, v string, fail func(string, string, ...interface{})) {
+	l := utf8.RuneCountInString(v)
+	if min, ok := intKeyword(schema, "minLength"); ok && l < min {
+		fail("minLength", "length must be greater than or equal to %d, got %d", min, l)
+	}
+	if max, ok := intKeyword(schema, "maxLength"); ok && l > max {
+		fail("maxLength", "length must be less than or equal to %d, got %d", max, l)
+	}
+	if p, ok := schema["pattern"].(string); ok && !s.patterns[p].MatchString(v) {
+		fail("pattern", "value must match the regexp %#v", p)
+	}
+	if f, ok := schema["format"].(string); ok {
+		switch f {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				fail("format", "value must be a RFC3339 date-time")
+			}
+		case "uuid":
+			if !uuidRegex.MatchString(v) {
+				fail("format", "value must be a UUID")
+			}
+		}
+	}
+}
+
+// validateNumber validates the number v against the numeric keywords of schema.
+func validateNumber(schema map[string]interface{}, v json.Number, fail func(string, string, ...interface{})) {
+	f, err := v.Float64()
+	if err != nil {
+		return
+	}
+	if min, ok := schema["minimum"].(json.Number); ok {
+		if m, err := min.Float64(); err == nil && f < m {
+			fail("minimum", "value must be greater than or equal to %s, got %s", min, v)
+		}
+	}
+	if max, ok := schema["maximum"].(json.Number); ok {
+		if m, err := max.Float64(); err == nil && f > m {
+			fail("maximum", "value must be less than or equal to %s, got %s", max, v)
+		}
+	}
+}
+
+// hasType returns true if v is of the JSON type or one of the JSON types listed in t.
+func hasType(t interface{}, v interface{}) bool {
+	switch actual := t.(type) {
+	case string:
+		return isType(actual, v)
+	case []interface{}:
+		for _, e := range actual {
+			if n, ok := e.(string); ok && isType(n, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// isType returns true if v is of the given JSON type.
+func isType(t string, v interface{}) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := n.Int64(); err == nil {
+			return true
+		}
+		f, err := n.Float64()
+		return err == nil && f == float64(int64(f))
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	}
+	// Unknown types such as the "file" or "any" extensions accept any value.
+	return true
+}
+
+// typeNames returns the human readable list of the types given by the "type" keyword value t.
+func typeNames(t interface{}) string {
+	if ts, ok := t.([]interface{}); ok {
+		names := make([]string, len(ts))
+		for i, e := range ts {
+			names[i] = fmt.Sprintf("%v", e)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprintf("%v", t)
+}
+
+// subSchemas returns the schemas listed in the given "allOf" or "anyOf" keyword value.
+func subSchemas(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	var res []map[string]interface{}
+	for _, e := range list {
+		if m, ok := e.(map[string]interface{}); ok {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// intKeyword returns the value of the given integer keyword of schema if set.
+func intKeyword(schema map[string]interface{}, keyword string) (int, bool) {
+	n, ok := schema[keyword].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, false
+	}
+	return int(i), true
+}
+
+// equal returns true if the given JSON values are equal, numbers are compared by value.
+func equal(a, b interface{}) bool {
+	na, aok := a.(json.Number)
+	nb, bok := b.(json.Number)
+	if aok && bok {
+		fa, erra := na.Float64()
+		fb, errb := nb.Float64()
+		return erra == nil && errb == nil && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// jsonString returns the JSON representation of v.
+func jsonString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// escape escapes the given JSON pointer reference token.
+func escape(tok string) string {
+	return strings.Replace(strings.Replace(tok, "~", "~0", -1), "/", "~1", -1)
+}
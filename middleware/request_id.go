@@ -50,6 +50,19 @@ func RequestIDWithHeader(requestIDHeader string) goa.Middleware {
 // request id header as the (first) argument and a length limit for truncation of the request
 // header value if it exceeds a reasonable length. The limit can be negative for unlimited.
 func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int) goa.Middleware {
+	return requestID(requestIDHeader, lengthLimit, false)
+}
+
+// RequestIDWithResponseHeader behaves like the middleware RequestIDWithHeader and also sets the
+// request ID in the requestIDHeader header of the response so that clients may correlate their
+// requests with the service logs, including when the ID was generated by the service.
+func RequestIDWithResponseHeader(requestIDHeader string) goa.Middleware {
+	return requestID(requestIDHeader, DefaultRequestIDLengthLimit, true)
+}
+
+// requestID returns the request ID middleware that reads the request ID from the given header,
+// generates one if missing and optionally sets it on the response.
+func requestID(requestIDHeader string, lengthLimit int, echo bool) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			id := req.Header.Get(requestIDHeader)
@@ -59,6 +72,9 @@ func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int)
 				id = id[:lengthLimit]
 			}
 			ctx = context.WithValue(ctx, reqIDKey, id)
+			if echo {
+				rw.Header().Set(requestIDHeader, id)
+			}
 
 			return h(ctx, rw, req)
 		}
@@ -83,6 +83,48 @@ var _ = Describe("RequestID", func() {
 		Ω(middleware.ContextRequestID(newCtx)).Should(Equal(string(original)))
 	})
 
+	Context("with the response header", func() {
+		var trw *testResponseWriter
+		var newCtx context.Context
+
+		BeforeEach(func() {
+			trw = newTestResponseWriter()
+			rw = trw
+			ctx = newContext(service, rw, req, params)
+		})
+
+		JustBeforeEach(func() {
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				newCtx = ctx
+				return service.Send(ctx, 200, "ok")
+			}
+			rg := middleware.RequestIDWithResponseHeader("X-Request-ID")(h)
+			Ω(rg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		})
+
+		Context("and a request with the header", func() {
+			BeforeEach(func() {
+				req.Header.Set("X-Request-ID", "abc")
+			})
+
+			It("uses the request ID and sets it on the response", func() {
+				Ω(middleware.ContextRequestID(newCtx)).Should(Equal("abc"))
+				Ω(trw.Header().Get("X-Request-ID")).Should(Equal("abc"))
+			})
+		})
+
+		Context("and a request without the header", func() {
+			BeforeEach(func() {
+				req.Header.Del("X-Request-ID")
+			})
+
+			It("generates a request ID and sets it on the response", func() {
+				id := middleware.ContextRequestID(newCtx)
+				Ω(id).ShouldNot(BeEmpty())
+				Ω(trw.Header().Get("X-Request-ID")).Should(Equal(id))
+			})
+		})
+	})
 })
 
 func makeRequestID(length int) string {
@@ -24,6 +24,9 @@ import (
 //     3. If scopes are defined in the design for the action, validate them
 //        against the scopes presented by the JWT in the claim "scope", or if
 //        that's not defined, "scopes".
+//     4. Store a goa.ScopeChecker backed by the scopes presented by the JWT in the
+//        request context so that the response attributes guarded by the RequiredScope
+//        DSL are omitted when the scope is missing.
 //
 // The `exp` (expiration) and `nbf` (not before) date checks are validated by the JWT library.
 //
@@ -129,6 +132,7 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 			}
 
 			ctx = WithJWT(ctx, token)
+			ctx = goa.WithScopeChecker(ctx, func(scope string) bool { return scopesInClaim[scope] })
 			if validationFunc != nil {
 				nextHandler = validationFunc(nextHandler)
 			}
@@ -66,6 +66,26 @@ var _ = Describe("Mux", func() {
 		})
 	})
 
+	Context("with a handler registered with a custom method", func() {
+		const reqMeth = "PROPFIND"
+		const reqPath = "/foo"
+
+		var readMeth string
+
+		BeforeEach(func() {
+			var err error
+			req, err = http.NewRequest(reqMeth, reqPath, nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			mux.Handle(reqMeth, reqPath, func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+				readMeth = req.Method
+			})
+		})
+
+		It("handles requests", func() {
+			Ω(readMeth).Should(Equal(reqMeth))
+		})
+	})
+
 	Context("with registered handlers and wrong method", func() {
 		const handlerMeth = "POST"
 		const reqMeth = "GET"
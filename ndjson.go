@@ -0,0 +1,62 @@
+package goa
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// NDJSONMediaIdentifier is the media type identifier of newline delimited JSON documents, each
+// line contains one JSON value.
+const NDJSONMediaIdentifier = "application/x-ndjson"
+
+// NDJSONEncoder writes values as newline delimited JSON.
+type NDJSONEncoder struct {
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+// NDJSONDecoder reads values encoded as newline delimited JSON.
+type NDJSONDecoder struct {
+	r *bufio.Reader
+}
+
+// NewNDJSONEncoder returns an encoder that writes one JSON value per line to w. The encoder
+// flushes w after each value if w implements http.Flusher so that the values are sent to the
+// client as soon as they are encoded.
+func NewNDJSONEncoder(w io.Writer) *NDJSONEncoder {
+	f, _ := w.(http.Flusher)
+	return &NDJSONEncoder{enc: json.NewEncoder(w), flusher: f}
+}
+
+// Encode writes the JSON encoding of v followed by a newline and flushes the writer.
+func (e *NDJSONEncoder) Encode(v interface{}) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+	return nil
+}
+
+// NewNDJSONDecoder returns a decoder that reads one JSON value per line from r.
+func NewNDJSONDecoder(r io.Reader) *NDJSONDecoder {
+	return &NDJSONDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next line and stores its JSON value in v. Empty lines are skipped. Decode
+// returns io.EOF once all the lines have been read.
+func (d *NDJSONDecoder) Decode(v interface{}) error {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			return json.Unmarshal(line, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package goa_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NDJSONEncoder", func() {
+	type bottle struct {
+		Name string `json:"name"`
+	}
+
+	It("writes one value per line and flushes after each", func() {
+		rw := httptest.NewRecorder()
+		enc := goa.NewNDJSONEncoder(rw)
+		Ω(enc.Encode(&bottle{Name: "a"})).ShouldNot(HaveOccurred())
+		Ω(rw.Flushed).Should(BeTrue())
+		Ω(enc.Encode(&bottle{Name: "b"})).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal("{\"name\":\"a\"}\n{\"name\":\"b\"}\n"))
+	})
+
+	It("writes to writers that cannot flush", func() {
+		var buf bytes.Buffer
+		Ω(goa.NewNDJSONEncoder(&buf).Encode(&bottle{Name: "a"})).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal("{\"name\":\"a\"}\n"))
+	})
+})
+
+var _ = Describe("NDJSONDecoder", func() {
+	type bottle struct {
+		Name string `json:"name"`
+	}
+
+	It("decodes one value per line", func() {
+		dec := goa.NewNDJSONDecoder(strings.NewReader("{\"name\":\"a\"}\n\n{\"name\":\"b\"}"))
+		var names []string
+		for {
+			var b bottle
+			err := dec.Decode(&b)
+			if err == io.EOF {
+				break
+			}
+			Ω(err).ShouldNot(HaveOccurred())
+			names = append(names, b.Name)
+		}
+		Ω(names).Should(Equal([]string{"a", "b"}))
+	})
+
+	It("returns an error for invalid lines", func() {
+		dec := goa.NewNDJSONDecoder(strings.NewReader("{\"name\":\n"))
+		var b bottle
+		Ω(dec.Decode(&b)).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,135 @@
+package goa
+
+import "encoding/json"
+
+type (
+	// NullableString is the type of the fields generated for nullable string attributes. It
+	// makes it possible to distinguish an attribute that is absent from the request body from
+	// one that is explicitly set to null.
+	NullableString struct {
+		// Present is true if the attribute was set in the request body, possibly to null.
+		Present bool
+		// Null is true if the attribute was explicitly set to null.
+		Null bool
+		// Value is the attribute value if present and not null.
+		Value string
+	}
+
+	// NullableInteger is the type of the fields generated for nullable integer attributes.
+	// See NullableString.
+	NullableInteger struct {
+		Present bool
+		Null    bool
+		Value   int
+	}
+
+	// NullableNumber is the type of the fields generated for nullable number attributes.
+	// See NullableString.
+	NullableNumber struct {
+		Present bool
+		Null    bool
+		Value   float64
+	}
+
+	// NullableBoolean is the type of the fields generated for nullable boolean attributes.
+	// See NullableString.
+	NullableBoolean struct {
+		Present bool
+		Null    bool
+		Value   bool
+	}
+)
+
+// UnmarshalJSON records the presence of the attribute and decodes its value unless null.
+func (n *NullableString) UnmarshalJSON(b []byte) error {
+	return unmarshalNullable(b, &n.Present, &n.Null, &n.Value)
+}
+
+// MarshalJSON encodes the attribute value or null if it is absent or null.
+func (n NullableString) MarshalJSON() ([]byte, error) {
+	return marshalNullable(n.Present, n.Null, n.Value)
+}
+
+// OrNil returns a pointer to a copy of n or nil if the attribute is absent.
+func (n NullableString) OrNil() *NullableString {
+	if !n.Present {
+		return nil
+	}
+	return &n
+}
+
+// UnmarshalJSON records the presence of the attribute and decodes its value unless null.
+func (n *NullableInteger) UnmarshalJSON(b []byte) error {
+	return unmarshalNullable(b, &n.Present, &n.Null, &n.Value)
+}
+
+// MarshalJSON encodes the attribute value or null if it is absent or null.
+func (n NullableInteger) MarshalJSON() ([]byte, error) {
+	return marshalNullable(n.Present, n.Null, n.Value)
+}
+
+// OrNil returns a pointer to a copy of n or nil if the attribute is absent.
+func (n NullableInteger) OrNil() *NullableInteger {
+	if !n.Present {
+		return nil
+	}
+	return &n
+}
+
+// UnmarshalJSON records the presence of the attribute and decodes its value unless null.
+func (n *NullableNumber) UnmarshalJSON(b []byte) error {
+	return unmarshalNullable(b, &n.Present, &n.Null, &n.Value)
+}
+
+// MarshalJSON encodes the attribute value or null if it is absent or null.
+func (n NullableNumber) MarshalJSON() ([]byte, error) {
+	return marshalNullable(n.Present, n.Null, n.Value)
+}
+
+// OrNil returns a pointer to a copy of n or nil if the attribute is absent.
+func (n NullableNumber) OrNil() *NullableNumber {
+	if !n.Present {
+		return nil
+	}
+	return &n
+}
+
+// UnmarshalJSON records the presence of the attribute and decodes its value unless null.
+func (n *NullableBoolean) UnmarshalJSON(b []byte) error {
+	return unmarshalNullable(b, &n.Present, &n.Null, &n.Value)
+}
+
+// MarshalJSON encodes the attribute value or null if it is absent or null.
+func (n NullableBoolean) MarshalJSON() ([]byte, error) {
+	return marshalNullable(n.Present, n.Null, n.Value)
+}
+
+// OrNil returns a pointer to a copy of n or nil if the attribute is absent.
+func (n NullableBoolean) OrNil() *NullableBoolean {
+	if !n.Present {
+		return nil
+	}
+	return &n
+}
+
+// unmarshalNullable is called by the JSON decoder only when the attribute is present in the
+// document, including when it is set to null.
+func unmarshalNullable(b []byte, present, null *bool, v interface{}) error {
+	*present = true
+	if string(b) == "null" {
+		*null = true
+		return nil
+	}
+	*null = false
+	return json.Unmarshal(b, v)
+}
+
+// marshalNullable encodes null for absent attributes as encoding/json ignores the omitempty option
+// of struct fields. The MarshalJSON methods generated for types with nullable fields use OrNil to
+// omit the absent attributes instead.
+func marshalNullable(present, null bool, v interface{}) ([]byte, error) {
+	if !present || null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v)
+}
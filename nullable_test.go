@@ -0,0 +1,156 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// nullablePayload mimics the types generated for objects with nullable attributes.
+type nullablePayload struct {
+	Name goa.NullableString  `json:"name,omitempty"`
+	Age  goa.NullableInteger `json:"age,omitempty"`
+}
+
+func (ut nullablePayload) MarshalJSON() ([]byte, error) {
+	type alias nullablePayload
+	return json.Marshal(&struct {
+		*alias
+		Name *goa.NullableString  `json:"name,omitempty"`
+		Age  *goa.NullableInteger `json:"age,omitempty"`
+	}{
+		alias: (*alias)(&ut),
+		Name:  ut.Name.OrNil(),
+		Age:   ut.Age.OrNil(),
+	})
+}
+
+var _ = Describe("Nullable", func() {
+	type payload struct {
+		Name goa.NullableString  `json:"name,omitempty"`
+		Age  goa.NullableInteger `json:"age,omitempty"`
+	}
+
+	var body string
+	var p payload
+	var decodeErr error
+
+	JustBeforeEach(func() {
+		p = payload{}
+		decodeErr = json.Unmarshal([]byte(body), &p)
+	})
+
+	Context("with an absent attribute", func() {
+		BeforeEach(func() {
+			body = `{"age":42}`
+		})
+
+		It("is not present", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(p.Name.Present).Should(BeFalse())
+			Ω(p.Name.Null).Should(BeFalse())
+		})
+	})
+
+	Context("with a null attribute", func() {
+		BeforeEach(func() {
+			body = `{"name":null}`
+		})
+
+		It("is present and null", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(p.Name.Present).Should(BeTrue())
+			Ω(p.Name.Null).Should(BeTrue())
+			Ω(p.Age.Present).Should(BeFalse())
+		})
+
+		It("encodes null", func() {
+			b, err := json.Marshal(p.Name)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(Equal("null"))
+		})
+	})
+
+	Context("with an attribute value", func() {
+		BeforeEach(func() {
+			body = `{"name":"foo","age":42}`
+		})
+
+		It("is present with the value", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(p.Name).Should(Equal(goa.NullableString{Present: true, Value: "foo"}))
+			Ω(p.Age).Should(Equal(goa.NullableInteger{Present: true, Value: 42}))
+		})
+
+		It("encodes the value", func() {
+			b, err := json.Marshal(p)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(Equal(`{"name":"foo","age":42}`))
+		})
+	})
+
+	Context("with an attribute value of the wrong type", func() {
+		BeforeEach(func() {
+			body = `{"age":"foo"}`
+		})
+
+		It("fails", func() {
+			Ω(decodeErr).Should(HaveOccurred())
+		})
+	})
+
+	Describe("round trip with a generated type", func() {
+		var body string
+		var encoded string
+
+		JustBeforeEach(func() {
+			var p nullablePayload
+			Ω(json.Unmarshal([]byte(body), &p)).ShouldNot(HaveOccurred())
+			b, err := json.Marshal(p)
+			Ω(err).ShouldNot(HaveOccurred())
+			encoded = string(b)
+		})
+
+		Context("with an absent attribute", func() {
+			BeforeEach(func() {
+				body = `{"age":42}`
+			})
+
+			It("omits the attribute", func() {
+				Ω(encoded).Should(Equal(body))
+			})
+		})
+
+		Context("with a null attribute", func() {
+			BeforeEach(func() {
+				body = `{"name":null}`
+			})
+
+			It("encodes null", func() {
+				Ω(encoded).Should(Equal(body))
+			})
+		})
+
+		Context("with an attribute value", func() {
+			BeforeEach(func() {
+				body = `{"name":"foo","age":42}`
+			})
+
+			It("encodes the value", func() {
+				Ω(encoded).Should(Equal(body))
+			})
+		})
+
+		Context("with no attribute", func() {
+			BeforeEach(func() {
+				body = `{}`
+			})
+
+			It("encodes an empty object", func() {
+				Ω(encoded).Should(Equal(body))
+			})
+		})
+	})
+})
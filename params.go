@@ -0,0 +1,63 @@
+package goa
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DeepObjectParams extracts the values of the object parameter with the given name from params.
+// The object fields are given using the bracketed query string syntax, for example
+// "filter[status]=active&filter[ids][]=1&filter[ids][]=2". The values are indexed by field name,
+// DeepObjectParams returns nil if the parameter is not set.
+func DeepObjectParams(params url.Values, name string) url.Values {
+	prefix := name + "["
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var res url.Values
+	for _, key := range keys {
+		field := strings.TrimSuffix(key[len(prefix):], "[]")
+		if !strings.HasSuffix(field, "]") {
+			continue
+		}
+		field = field[:len(field)-1]
+		if field == "" || strings.ContainsAny(field, "[]") || len(params[key]) == 0 {
+			continue
+		}
+		if res == nil {
+			res = make(url.Values)
+		}
+		res[field] = append(res[field], params[key]...)
+	}
+	return res
+}
+
+// arrayParamSeparators lists the separators of the array parameter styles that give all the
+// values in a single parameter.
+var arrayParamSeparators = map[string]string{"csv": ",", "ssv": " ", "pipes": "|"}
+
+// SplitArrayParam returns the values of an array parameter given using the style with the given
+// name: "csv", "ssv" and "pipes" separate the values with commas, spaces and pipes respectively
+// while "multi" repeats the parameter for each value. Each raw value is split so that a parameter
+// may also be repeated when using a separator, empty values are discarded. The generated code
+// calls SplitArrayParam for the parameters and headers that define the ArrayParamStyle DSL.
+func SplitArrayParam(values []string, style string) []string {
+	sep, ok := arrayParamSeparators[style]
+	if !ok {
+		return values
+	}
+	var res []string
+	for _, v := range values {
+		for _, elem := range strings.Split(v, sep) {
+			if elem != "" {
+				res = append(res, elem)
+			}
+		}
+	}
+	return res
+}
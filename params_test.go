@@ -0,0 +1,126 @@
+package goa_test
+
+import (
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeepObjectParams", func() {
+	var query string
+	var values url.Values
+
+	JustBeforeEach(func() {
+		params, err := url.ParseQuery(query)
+		Ω(err).ShouldNot(HaveOccurred())
+		values = goa.DeepObjectParams(params, "filter")
+	})
+
+	Context("with no bracketed parameter", func() {
+		BeforeEach(func() {
+			query = "filter=foo&other[status]=active"
+		})
+
+		It("returns nil", func() {
+			Ω(values).Should(BeNil())
+		})
+	})
+
+	Context("with a single bracketed parameter", func() {
+		BeforeEach(func() {
+			query = "filter[status]=active"
+		})
+
+		It("returns the field value", func() {
+			Ω(values).Should(Equal(url.Values{"status": {"active"}}))
+		})
+	})
+
+	Context("with multiple bracketed parameters", func() {
+		BeforeEach(func() {
+			query = "filter[status]=active&filter[type]=x&sort=name"
+		})
+
+		It("returns the field values", func() {
+			Ω(values).Should(Equal(url.Values{"status": {"active"}, "type": {"x"}}))
+		})
+	})
+
+	Context("with an array field", func() {
+		BeforeEach(func() {
+			query = "filter[ids][]=1&filter[ids][]=2&filter[status]=active"
+		})
+
+		It("returns all the array values", func() {
+			Ω(values).Should(Equal(url.Values{"ids": {"1", "2"}, "status": {"active"}}))
+		})
+	})
+
+	Context("with nested bracketed parameters", func() {
+		BeforeEach(func() {
+			query = "filter[owner][name]=joe"
+		})
+
+		It("ignores them", func() {
+			Ω(values).Should(BeNil())
+		})
+	})
+})
+
+var _ = Describe("SplitArrayParam", func() {
+	var query string
+	var style string
+	var values []string
+
+	JustBeforeEach(func() {
+		params, err := url.ParseQuery(query)
+		Ω(err).ShouldNot(HaveOccurred())
+		values = goa.SplitArrayParam(params["ids"], style)
+	})
+
+	Context("with the csv style", func() {
+		BeforeEach(func() {
+			query = "ids=1,2,3"
+			style = "csv"
+		})
+
+		It("splits the values", func() {
+			Ω(values).Should(Equal([]string{"1", "2", "3"}))
+		})
+	})
+
+	Context("with the multi style", func() {
+		BeforeEach(func() {
+			query = "ids=1&ids=2"
+			style = "multi"
+		})
+
+		It("returns the repeated values", func() {
+			Ω(values).Should(Equal([]string{"1", "2"}))
+		})
+	})
+
+	Context("with the ssv style", func() {
+		BeforeEach(func() {
+			query = "ids=1+2"
+			style = "ssv"
+		})
+
+		It("splits the values", func() {
+			Ω(values).Should(Equal([]string{"1", "2"}))
+		})
+	})
+
+	Context("with the pipes style and repeated parameters", func() {
+		BeforeEach(func() {
+			query = "ids=1|2&ids=3||"
+			style = "pipes"
+		})
+
+		It("splits each value and discards the empty ones", func() {
+			Ω(values).Should(Equal([]string{"1", "2", "3"}))
+		})
+	})
+})
@@ -27,6 +27,70 @@ func WithRequiredScopes(ctx context.Context, scopes []string) context.Context {
 	return context.WithValue(ctx, securityScopesKey, scopes)
 }
 
+// ScopeChecker returns true if the caller of the request being handled was granted the given
+// scope.
+type ScopeChecker func(scope string) bool
+
+// WithScopeChecker builds a context containing the given scope checker. Security middlewares call
+// WithScopeChecker once the request credentials are validated so that the generated code may omit
+// the response attributes that require scopes the caller was not granted.
+func WithScopeChecker(ctx context.Context, checker ScopeChecker) context.Context {
+	return context.WithValue(ctx, scopeCheckerKey, checker)
+}
+
+// ContextScopeChecker extracts the scope checker from the given context, nil if there isn't one.
+func ContextScopeChecker(ctx context.Context) ScopeChecker {
+	if c := ctx.Value(scopeCheckerKey); c != nil {
+		return c.(ScopeChecker)
+	}
+	return nil
+}
+
+// OmitScopedFields returns the JSON representation of v without the attributes that require a
+// scope the caller was not granted. scopes maps the names of the guarded attributes to the scope
+// they require as defined by the RequiredScope DSL. The scopes are checked with the scope checker
+// stored in ctx, all the guarded attributes are omitted if there is none. v must be a value whose
+// JSON representation is an object or an array of objects, any other value is returned as is. v
+// is also returned as is if the caller was granted all the scopes.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func OmitScopedFields(ctx context.Context, v interface{}, scopes map[string]string) (interface{}, error) {
+	check := ContextScopeChecker(ctx)
+	omit := make(map[string]bool)
+	for field, scope := range scopes {
+		if check == nil || !check(scope) {
+			omit[field] = true
+		}
+	}
+	if len(omit) == 0 {
+		return v, nil
+	}
+	raw, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	switch actual := raw.(type) {
+	case map[string]interface{}:
+		omitFields(actual, omit)
+	case []interface{}:
+		for _, e := range actual {
+			if m, ok := e.(map[string]interface{}); ok {
+				omitFields(m, omit)
+			}
+		}
+	default:
+		return v, nil
+	}
+	return raw, nil
+}
+
+// omitFields deletes the keys of m that are in omit.
+func omitFields(m map[string]interface{}, omit map[string]bool) {
+	for k := range omit {
+		delete(m, k)
+	}
+}
+
 // OAuth2Security represents the `oauth2` security scheme. It is instantiated by the generated code
 // accordingly to the use of the different `*Security()` DSL functions and `Security()` in the
 // design.
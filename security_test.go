@@ -0,0 +1,78 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OmitScopedFields", func() {
+	type employee struct {
+		Name   string `json:"name"`
+		Salary *int   `json:"salary,omitempty"`
+	}
+	salary := 100
+	e := &employee{Name: "Alice", Salary: &salary}
+	scopes := map[string]string{"salary": "admin"}
+
+	var ctx context.Context
+	var v interface{}
+	var body string
+	var filterErr error
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		v = e
+	})
+
+	JustBeforeEach(func() {
+		var res interface{}
+		res, filterErr = goa.OmitScopedFields(ctx, v, scopes)
+		out, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		body = string(out)
+	})
+
+	Context("with a caller granted the scope", func() {
+		BeforeEach(func() {
+			ctx = goa.WithScopeChecker(ctx, func(scope string) bool { return scope == "admin" })
+		})
+
+		It("keeps the guarded attributes", func() {
+			Ω(filterErr).ShouldNot(HaveOccurred())
+			Ω(body).Should(Equal(`{"name":"Alice","salary":100}`))
+		})
+	})
+
+	Context("with a caller missing the scope", func() {
+		BeforeEach(func() {
+			ctx = goa.WithScopeChecker(ctx, func(scope string) bool { return scope == "user" })
+		})
+
+		It("omits the guarded attributes", func() {
+			Ω(filterErr).ShouldNot(HaveOccurred())
+			Ω(body).Should(Equal(`{"name":"Alice"}`))
+		})
+
+		Context("and a collection", func() {
+			BeforeEach(func() {
+				v = []*employee{e, {Name: "Bob"}}
+			})
+
+			It("filters each element", func() {
+				Ω(filterErr).ShouldNot(HaveOccurred())
+				Ω(body).Should(Equal(`[{"name":"Alice"},{"name":"Bob"}]`))
+			})
+		})
+	})
+
+	Context("with no scope checker", func() {
+		It("omits the guarded attributes", func() {
+			Ω(filterErr).ShouldNot(HaveOccurred())
+			Ω(body).Should(Equal(`{"name":"Alice"}`))
+		})
+	})
+})
@@ -1,10 +1,15 @@
 package goa
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
@@ -13,6 +18,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -39,9 +45,16 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
-
-		middleware []Middleware       // Middleware chain
-		cancel     context.CancelFunc // Service context cancel signal trigger
+		// SignatureVerifier verifies the request body signatures of the actions that define
+		// one with the VerifySignature DSL.
+		SignatureVerifier SignatureVerifier
+		// DedupeStore stores the responses replayed to duplicate requests by the actions that
+		// define the Dedupe DSL or the IdempotencyKey and Cacheable DSLs.
+		DedupeStore DedupeStore
+
+		middleware []Middleware                 // Middleware chain
+		cancel     context.CancelFunc           // Service context cancel signal trigger
+		versions   map[string]*versionedHandler // Handlers of the resource versions indexed by method and path
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -78,6 +91,33 @@ type (
 		FileHandler(path, filename string) Handler
 	}
 
+	// SignatureVerifier is the interface implemented by the verifiers of request body
+	// signatures, for example HMAC signatures computed by webhook senders.
+	SignatureVerifier interface {
+		// VerifySignature returns an error if signature is not a valid signature of body.
+		VerifySignature(ctx context.Context, body []byte, signature string) error
+	}
+
+	// DedupeStore is the interface implemented by the stores of the responses replayed to
+	// duplicate requests. Implementations must be safe for concurrent use.
+	DedupeStore interface {
+		// Get returns the response stored under the given key if any. ok is false if there
+		// is no response stored under the key or if it has expired.
+		Get(ctx context.Context, key string) (resp *CachedResponse, ok bool, err error)
+		// Set stores the response under the given key for the duration of ttl.
+		Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error
+	}
+
+	// CachedResponse is a response stored in a DedupeStore.
+	CachedResponse struct {
+		// Status is the response status code.
+		Status int
+		// Header contains the response headers.
+		Header http.Header
+		// Body is the response body.
+		Body []byte
+	}
+
 	// Handler defines the request handler signatures.
 	Handler func(context.Context, http.ResponseWriter, *http.Request) error
 
@@ -88,6 +128,9 @@ type (
 	DecodeFunc func(context.Context, io.ReadCloser, interface{}) error
 )
 
+// defaultMaxRequestBodyLength is the default maximum length read from request bodies.
+const defaultMaxRequestBodyLength = 1073741824 // 1 GB
+
 // New instantiates a service with the given name.
 func New(name string) *Service {
 	var (
@@ -223,7 +266,7 @@ func (service *Service) NewController(name string) *Controller {
 		Name:                 name,
 		Service:              service,
 		Context:              context.WithValue(service.Context, ctrlKey, name),
-		MaxRequestBodyLength: 1073741824, // 1 GB
+		MaxRequestBodyLength: defaultMaxRequestBodyLength,
 		FileSystem: func(dir string) http.FileSystem {
 			return http.Dir(dir)
 		},
@@ -260,6 +303,245 @@ func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 	return nil
 }
 
+// LimitRequestBody limits the number of bytes read from the request body to n. Reading past the
+// limit fails and causes the request to be rejected with a 413 Request Entity Too Large response.
+// The generated code calls LimitRequestBody prior to decoding the bodies of the requests made to
+// actions that define a maximum body size. n applies on top of the controller
+// MaxRequestBodyLength so that the smallest limit wins.
+func LimitRequestBody(ctx context.Context, req *http.Request, n int64) {
+	req.Body = http.MaxBytesReader(ContextResponse(ctx), req.Body, n)
+	if r := ContextRequest(ctx); r != nil {
+		r.maxBodyLength = n
+	}
+}
+
+// VerifySignature reads the request body and verifies its signature contained in the given header
+// using the service SignatureVerifier. The body is restored so that it can still be decoded.
+// VerifySignature returns an ErrInvalidSignature error if the header is missing or the signature
+// cannot be verified. The generated code calls VerifySignature before decoding the request bodies
+// of the actions that define the VerifySignature DSL.
+func (service *Service) VerifySignature(ctx context.Context, req *http.Request, header string) error {
+	if service.SignatureVerifier == nil {
+		return ErrNoSignatureVerifier("no signature verifier set on service", "header", header)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	sig := req.Header.Get(header)
+	if sig == "" {
+		return ErrInvalidSignature(fmt.Sprintf("missing signature header %#v", header), "header", header)
+	}
+	if err := service.SignatureVerifier.VerifySignature(ctx, body, sig); err != nil {
+		return ErrInvalidSignature(err, "header", header)
+	}
+	return nil
+}
+
+// CheckRequestBody wraps the given MuxHandler so that the request bodies are checked before they
+// are read. If requireLength is true requests that do not set the Content-Length header are
+// rejected with a 411 Length Required response and requests whose Content-Length exceeds max
+// bytes, if max is greater than 0, are rejected with a 413 Request Entity Too Large response. If
+// contentTypes is not empty requests whose Content-Type header does not match one of the given
+// media types are rejected with a 415 Unsupported Media Type response, media type parameters are
+// ignored.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func (service *Service) CheckRequestBody(requireLength bool, max int64, contentTypes []string, h MuxHandler) MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		var err error
+		switch {
+		case requireLength && (req.ContentLength < 0 || req.ContentLength == 0 && req.Header.Get("Content-Length") == ""):
+			err = ErrLengthRequired("missing Content-Length header")
+		case requireLength && max > 0 && req.ContentLength > max:
+			err = ErrRequestBodyTooLarge(fmt.Sprintf("request body length exceeds %d bytes", max))
+		case len(contentTypes) > 0 && !matchContentType(req.Header.Get("Content-Type"), contentTypes):
+			err = ErrUnsupportedMediaType("unsupported content type", "content-type", req.Header.Get("Content-Type"))
+		}
+		if err != nil {
+			ctx := NewContext(service.Context, rw, req, params)
+			service.Send(ctx, err.(ServiceError).ResponseStatus(), err)
+			return
+		}
+		h(rw, req, params)
+	}
+}
+
+// matchContentType returns true if the media type of the given Content-Type header value is one
+// of the given media types.
+func matchContentType(ct string, contentTypes []string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	for _, t := range contentTypes {
+		if strings.EqualFold(mt, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// IdempotencyKeyHeader is the name of the request header whose value identifies duplicate
+// requests in place of the request body, see Service.Dedupe.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Dedupe wraps the given MuxHandler so that the response to the first request is replayed to the
+// identical requests received within the given window. Requests are identified by a SHA-256 hash
+// of their method, URI and either the value of their Idempotency-Key header or their body. The
+// responses are stored in the service DedupeStore, only the 2xx responses are stored so that
+// failed requests can be retried. Dedupe responds with a ErrNoDedupeStore error if the service
+// has no DedupeStore. Errors returned by the store are logged and the request is handled as if
+// it was not a duplicate. The request bodies are read in memory to compute the hash, at most
+// 1GB is read.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func (service *Service) Dedupe(window time.Duration, h MuxHandler) MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		store := service.DedupeStore
+		if store == nil {
+			ctx := NewContext(service.Context, rw, req, params)
+			service.Send(ctx, 500, ErrNoDedupeStore("no dedupe store set on service"))
+			return
+		}
+		key, err := dedupeKey(req, defaultMaxRequestBodyLength)
+		if err != nil {
+			ctx := NewContext(service.Context, rw, req, params)
+			if strings.HasSuffix(err.Error(), "http: request body too large") {
+				err = ErrRequestBodyTooLarge(fmt.Sprintf("request body length exceeds %d bytes", defaultMaxRequestBodyLength))
+			} else {
+				err = ErrBadRequest(err)
+			}
+			service.Send(ctx, err.(ServiceError).ResponseStatus(), err)
+			return
+		}
+		service.replay(window, key, h, rw, req, params)
+	}
+}
+
+// IdempotentResponseTTL is the duration during which the responses of the actions that define
+// the IdempotencyKey and Cacheable DSLs are replayed, see Service.ReplayIdempotent.
+var IdempotentResponseTTL = 24 * time.Hour
+
+// ReplayIdempotent wraps the given MuxHandler so that the response to the first request that
+// carries a given Idempotency-Key header value is replayed to the retries of the request for the
+// duration of IdempotentResponseTTL instead of handling them again. Requests are identified by a
+// SHA-256 hash of their method, URI and idempotency key, requests without key are handled
+// normally. The responses are stored in the service DedupeStore, only the 2xx responses are
+// stored so that failed requests can be retried. ReplayIdempotent responds with a
+// ErrNoDedupeStore error if the service has no DedupeStore. Errors returned by the store are
+// logged and the request is handled as if it was not a retry.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func (service *Service) ReplayIdempotent(h MuxHandler) MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		k := req.Header.Get(IdempotencyKeyHeader)
+		if k == "" {
+			h(rw, req, params)
+			return
+		}
+		if service.DedupeStore == nil {
+			ctx := NewContext(service.Context, rw, req, params)
+			service.Send(ctx, 500, ErrNoDedupeStore("no dedupe store set on service"))
+			return
+		}
+		hash := sha256.New()
+		io.WriteString(hash, req.Method)
+		io.WriteString(hash, " ")
+		io.WriteString(hash, req.URL.RequestURI())
+		io.WriteString(hash, "\nkey:")
+		io.WriteString(hash, k)
+		service.replay(IdempotentResponseTTL, hex.EncodeToString(hash.Sum(nil)), h, rw, req, params)
+	}
+}
+
+// replay writes the response stored in the service DedupeStore under the given key if any,
+// otherwise it calls h and stores its response under the key for the duration of ttl if
+// successful.
+func (service *Service) replay(ttl time.Duration, key string, h MuxHandler, rw http.ResponseWriter, req *http.Request, params url.Values) {
+	store := service.DedupeStore
+	ctx := req.Context()
+	resp, ok, err := store.Get(ctx, key)
+	if err != nil {
+		service.LogError("dedupe store get failed", "err", err)
+	} else if ok {
+		for k, v := range resp.Header {
+			rw.Header()[k] = v
+		}
+		rw.WriteHeader(resp.Status)
+		rw.Write(resp.Body)
+		return
+	}
+	rec := &dedupeRecorder{ResponseWriter: rw}
+	h(rec, req, params)
+	if rec.status < 200 || rec.status > 299 {
+		return
+	}
+	resp = &CachedResponse{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}
+	if err := store.Set(ctx, key, resp, ttl); err != nil {
+		service.LogError("dedupe store set failed", "err", err)
+	}
+}
+
+// dedupeKey computes the key that identifies duplicates of the given request. The request body
+// is restored so that it can still be decoded.
+func dedupeKey(req *http.Request, max int64) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, req.Method)
+	io.WriteString(h, " ")
+	io.WriteString(h, req.URL.RequestURI())
+	io.WriteString(h, "\n")
+	if k := req.Header.Get(IdempotencyKeyHeader); k != "" {
+		io.WriteString(h, "key:")
+		io.WriteString(h, k)
+	} else if req.Body != nil {
+		body := req.Body
+		if max > 0 {
+			body = http.MaxBytesReader(nil, body, max)
+		}
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		io.WriteString(h, "body:")
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// dedupeRecorder records the response written by the handlers wrapped by Dedupe.
+type dedupeRecorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+// WriteHeader records the response status and headers.
+func (r *dedupeRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.header = make(http.Header, len(r.Header()))
+		for k, v := range r.Header() {
+			r.header[k] = append([]string(nil), v...)
+		}
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the response body.
+func (r *dedupeRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
 // EncodeResponse uses the HTTP encoder to marshal and write the response body based on the request
 // Accept header.
 func (service *Service) EncodeResponse(ctx context.Context, v interface{}) error {
@@ -329,10 +611,14 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 		// Load body if any
 		if req.ContentLength > 0 && unm != nil {
 			if err := unm(ctx, ctrl.Service, req); err != nil {
-				if err.Error() == "http: request body too large" {
-					msg := fmt.Sprintf("request body length exceeds %d bytes", ctrl.MaxRequestBodyLength)
+				if strings.HasSuffix(err.Error(), "http: request body too large") {
+					max := ctrl.MaxRequestBodyLength
+					if l := ContextRequest(ctx).maxBodyLength; l > 0 && (max <= 0 || l < max) {
+						max = l
+					}
+					msg := fmt.Sprintf("request body length exceeds %d bytes", max)
 					err = ErrRequestBodyTooLarge(msg)
-				} else {
+				} else if se, ok := err.(ServiceError); !ok || se.ResponseStatus() == http.StatusBadRequest {
 					err = ErrBadRequest(err)
 				}
 				ctx = WithError(ctx, err)
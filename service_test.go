@@ -2,12 +2,18 @@ package goa_test
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"context"
 
@@ -142,6 +148,355 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("LimitRequestBody", func() {
+		var body string
+		var rw *TestResponseWriter
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				goa.LimitRequestBody(ctx, req, 4)
+				var payload string
+				if err := service.DecodeRequest(req, &payload); err != nil {
+					return err
+				}
+				goa.ContextRequest(ctx).Payload = payload
+				return nil
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					rw.WriteHeader(400)
+					rw.Write([]byte(err.Error()))
+					return nil
+				}
+				rw.WriteHeader(200)
+				rw.Write([]byte(goa.ContextRequest(ctx).Payload.(string)))
+				return nil
+			}
+			muxHandler = ctrl.MuxHandler("testLimit", handler, unmarshaler)
+		})
+
+		JustBeforeEach(func() {
+			req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			muxHandler(rw, req, nil)
+		})
+
+		Context("with a body under the limit", func() {
+			BeforeEach(func() {
+				body = `"23"`
+			})
+
+			It("decodes the body", func() {
+				Ω(rw.Status).Should(Equal(200))
+				Ω(string(rw.Body)).Should(Equal("23"))
+			})
+		})
+
+		Context("with a body over the limit", func() {
+			BeforeEach(func() {
+				body = `"2345"`
+			})
+
+			It("responds with 413", func() {
+				Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 413 request_too_large: request body length exceeds 4 bytes`))
+			})
+		})
+	})
+
+	Describe("VerifySignature", func() {
+		const secret = "secret"
+		var body, signature string
+		var rw *TestResponseWriter
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			s.SignatureVerifier = hmacVerifier(secret)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				if err := service.VerifySignature(ctx, req, "X-Signature"); err != nil {
+					return err
+				}
+				var payload string
+				if err := service.DecodeRequest(req, &payload); err != nil {
+					return err
+				}
+				goa.ContextRequest(ctx).Payload = payload
+				return nil
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					rw.WriteHeader(err.(goa.ServiceError).ResponseStatus())
+					rw.Write([]byte(err.Error()))
+					return nil
+				}
+				rw.WriteHeader(200)
+				rw.Write([]byte(goa.ContextRequest(ctx).Payload.(string)))
+				return nil
+			}
+			muxHandler = ctrl.MuxHandler("testSignature", handler, unmarshaler)
+			body = `"23"`
+			signature = sign(secret, body)
+		})
+
+		JustBeforeEach(func() {
+			req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			if signature != "" {
+				req.Header.Set("X-Signature", signature)
+			}
+			muxHandler(rw, req, nil)
+		})
+
+		Context("with a valid signature", func() {
+			It("decodes the body", func() {
+				Ω(rw.Status).Should(Equal(200))
+				Ω(string(rw.Body)).Should(Equal("23"))
+			})
+		})
+
+		Context("with an invalid signature", func() {
+			BeforeEach(func() {
+				signature = sign("other", body)
+			})
+
+			It("responds with 401", func() {
+				Ω(rw.Status).Should(Equal(401))
+				Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 401 invalid_signature: signature mismatch`))
+			})
+		})
+
+		Context("with a missing signature", func() {
+			BeforeEach(func() {
+				signature = ""
+			})
+
+			It("responds with 401", func() {
+				Ω(rw.Status).Should(Equal(401))
+				Ω(string(rw.Body)).Should(ContainSubstring(`missing signature header "X-Signature"`))
+			})
+		})
+
+		Context("with no signature verifier", func() {
+			BeforeEach(func() {
+				s.SignatureVerifier = nil
+			})
+
+			It("responds with 500", func() {
+				Ω(rw.Status).Should(Equal(500))
+				Ω(string(rw.Body)).Should(ContainSubstring("no_signature_verifier"))
+			})
+		})
+	})
+
+	Describe("Dedupe", func() {
+		var store *fakeDedupeStore
+		var calls int
+		var status int
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			store = &fakeDedupeStore{responses: make(map[string]*goa.CachedResponse)}
+			s.DedupeStore = store
+			calls = 0
+			status = 201
+			ctrl := s.NewController("test")
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				var payload string
+				if err := service.DecodeRequest(req, &payload); err != nil {
+					return err
+				}
+				goa.ContextRequest(ctx).Payload = payload
+				return nil
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				calls++
+				rw.Header().Set("X-Call", fmt.Sprint(calls))
+				rw.WriteHeader(status)
+				rw.Write([]byte(fmt.Sprintf("%s-%d", goa.ContextRequest(ctx).Payload, calls)))
+				return nil
+			}
+			muxHandler = s.Dedupe(time.Minute, ctrl.MuxHandler("testDedupe", handler, unmarshaler))
+		})
+
+		send := func(body, key string) *TestResponseWriter {
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			if key != "" {
+				req.Header.Set(goa.IdempotencyKeyHeader, key)
+			}
+			muxHandler(rw, req, nil)
+			return rw
+		}
+
+		It("replays the response to identical requests", func() {
+			first := send(`"23"`, "")
+			second := send(`"23"`, "")
+			Ω(calls).Should(Equal(1))
+			Ω(store.ttl).Should(Equal(time.Minute))
+			Ω(second.Status).Should(Equal(201))
+			Ω(string(second.Body)).Should(Equal("23-1"))
+			Ω(string(second.Body)).Should(Equal(string(first.Body)))
+			Ω(second.ParentHeader.Get("X-Call")).Should(Equal("1"))
+		})
+
+		It("handles requests with different bodies", func() {
+			send(`"23"`, "")
+			rw := send(`"24"`, "")
+			Ω(calls).Should(Equal(2))
+			Ω(string(rw.Body)).Should(Equal("24-2"))
+		})
+
+		It("identifies requests with their idempotency key", func() {
+			send(`"23"`, "key")
+			rw := send(`"24"`, "key")
+			Ω(calls).Should(Equal(1))
+			Ω(string(rw.Body)).Should(Equal("23-1"))
+			rw = send(`"23"`, "other")
+			Ω(calls).Should(Equal(2))
+			Ω(string(rw.Body)).Should(Equal("23-2"))
+		})
+
+		Context("with failed responses", func() {
+			BeforeEach(func() {
+				status = 503
+			})
+
+			It("does not replay them", func() {
+				send(`"23"`, "")
+				rw := send(`"23"`, "")
+				Ω(calls).Should(Equal(2))
+				Ω(string(rw.Body)).Should(Equal("23-2"))
+			})
+		})
+
+		Context("with no dedupe store", func() {
+			BeforeEach(func() {
+				s.DedupeStore = nil
+			})
+
+			It("responds with 500", func() {
+				rw := send(`"23"`, "")
+				Ω(calls).Should(Equal(0))
+				Ω(rw.Status).Should(Equal(500))
+				Ω(string(rw.Body)).Should(ContainSubstring("no_dedupe_store"))
+			})
+		})
+	})
+
+	Describe("ReplayIdempotent", func() {
+		var store *fakeDedupeStore
+		var calls int
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			store = &fakeDedupeStore{responses: make(map[string]*goa.CachedResponse)}
+			s.DedupeStore = store
+			calls = 0
+			ctrl := s.NewController("test")
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				calls++
+				rw.WriteHeader(201)
+				rw.Write([]byte(fmt.Sprintf("charge-%d", calls)))
+				return nil
+			}
+			muxHandler = s.ReplayIdempotent(ctrl.MuxHandler("testReplay", handler, nil))
+		})
+
+		send := func(key string) *TestResponseWriter {
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			req, _ := http.NewRequest("POST", "/charges", nil)
+			if key != "" {
+				req.Header.Set(goa.IdempotencyKeyHeader, key)
+			}
+			muxHandler(rw, req, nil)
+			return rw
+		}
+
+		It("stores the response of the first call", func() {
+			rw := send("key")
+			Ω(calls).Should(Equal(1))
+			Ω(rw.Status).Should(Equal(201))
+			Ω(store.responses).Should(HaveLen(1))
+			Ω(store.ttl).Should(Equal(goa.IdempotentResponseTTL))
+		})
+
+		It("returns the cached response to retries", func() {
+			send("key")
+			rw := send("key")
+			Ω(calls).Should(Equal(1))
+			Ω(rw.Status).Should(Equal(201))
+			Ω(string(rw.Body)).Should(Equal("charge-1"))
+			rw = send("other")
+			Ω(calls).Should(Equal(2))
+			Ω(string(rw.Body)).Should(Equal("charge-2"))
+		})
+
+		It("handles requests without idempotency key", func() {
+			send("")
+			send("")
+			Ω(calls).Should(Equal(2))
+			Ω(store.responses).Should(BeEmpty())
+		})
+	})
+
+	Describe("CheckRequestBody", func() {
+		var calls int
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			calls = 0
+			ctrl := s.NewController("test")
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				calls++
+				rw.WriteHeader(204)
+				return nil
+			}
+			muxHandler = s.CheckRequestBody(true, 10, []string{"application/octet-stream"}, ctrl.MuxHandler("testCheck", handler, nil))
+		})
+
+		send := func(body string, length int64, contentType string) *TestResponseWriter {
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			req, _ := http.NewRequest("POST", "/uploads", strings.NewReader(body))
+			req.ContentLength = length
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			muxHandler(rw, req, nil)
+			return rw
+		}
+
+		It("accepts requests that satisfy the requirements", func() {
+			rw := send("data", 4, "application/octet-stream; charset=binary")
+			Ω(rw.Status).Should(Equal(204))
+			Ω(calls).Should(Equal(1))
+		})
+
+		It("rejects requests with no content length", func() {
+			rw := send("data", -1, "application/octet-stream")
+			Ω(rw.Status).Should(Equal(411))
+			Ω(calls).Should(Equal(0))
+		})
+
+		It("rejects requests whose content length exceeds the limit", func() {
+			rw := send("data", 11, "application/octet-stream")
+			Ω(rw.Status).Should(Equal(413))
+			Ω(calls).Should(Equal(0))
+		})
+
+		It("rejects requests with the wrong content type", func() {
+			rw := send("data", 4, "application/json")
+			Ω(rw.Status).Should(Equal(415))
+			rw = send("data", 4, "")
+			Ω(rw.Status).Should(Equal(415))
+			Ω(calls).Should(Equal(0))
+		})
+	})
+
 	Describe("MuxHandler", func() {
 		var handler goa.Handler
 		var unmarshaler goa.Unmarshaler
@@ -497,3 +852,36 @@ func (t *TestResponseWriter) Write(b []byte) (int, error) {
 func (t *TestResponseWriter) WriteHeader(s int) {
 	t.Status = s
 }
+
+// fakeDedupeStore stores the responses in memory and records the TTL of the last response.
+type fakeDedupeStore struct {
+	responses map[string]*goa.CachedResponse
+	ttl       time.Duration
+}
+
+func (f *fakeDedupeStore) Get(ctx context.Context, key string) (*goa.CachedResponse, bool, error) {
+	resp, ok := f.responses[key]
+	return resp, ok, nil
+}
+
+func (f *fakeDedupeStore) Set(ctx context.Context, key string, resp *goa.CachedResponse, ttl time.Duration) error {
+	f.responses[key] = resp
+	f.ttl = ttl
+	return nil
+}
+
+// hmacVerifier verifies hex encoded HMAC-SHA256 signatures computed with the given secret.
+type hmacVerifier string
+
+func (v hmacVerifier) VerifySignature(ctx context.Context, body []byte, signature string) error {
+	if !hmac.Equal([]byte(signature), []byte(sign(string(v), string(body)))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
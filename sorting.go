@@ -0,0 +1,123 @@
+package goa
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NullsOrder describes where null values are placed when sorting the results of a list action.
+type NullsOrder string
+
+const (
+	// NullsUnspecified leaves the placement of null values to the query builder.
+	NullsUnspecified NullsOrder = ""
+	// NullsFirst places null values before the other values.
+	NullsFirst NullsOrder = "first"
+	// NullsLast places null values after the other values.
+	NullsLast NullsOrder = "last"
+)
+
+// SortField describes a field used to sort the results of a list action as given in the "sort"
+// query string parameter.
+type SortField struct {
+	// Name is the name of the field.
+	Name string
+	// Descending is true if the results are sorted in descending order of the field values.
+	Descending bool
+	// Nulls is the placement of null values.
+	Nulls NullsOrder
+}
+
+// ParseSort parses the values of the "sort" query string parameter. Each value contains a comma
+// separated list of field names, a name prefixed with "-" sorts the results in descending order,
+// for example "-created_at,name". The generated code calls ParseSort for the actions that define
+// the Sortable DSL, ParseSort returns a bad request error if a field is not one of the allowed
+// fields.
+func ParseSort(values []string, allowed ...string) ([]*SortField, error) {
+	fields := make([]*SortField, len(allowed))
+	for i, a := range allowed {
+		fields[i] = &SortField{Name: a}
+	}
+	return ParseSortFields(values, fields...)
+}
+
+// ParseSortFields is like ParseSort but the allowed fields also carry their default sort options
+// as defined with the SortableField DSL. The direction of a field that is not prefixed with "-" or
+// "+" is the default direction of the field and the placement of null values is always the
+// default placement of the field. The generated code calls ParseSortFields for the actions that
+// define default sort options.
+func ParseSortFields(values []string, allowed ...*SortField) ([]*SortField, error) {
+	var (
+		fields []*SortField
+		err    error
+	)
+	names := make([]string, len(allowed))
+	for i, a := range allowed {
+		names[i] = a.Name
+	}
+	for _, v := range values {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			var dir string
+			if strings.HasPrefix(name, "-") || strings.HasPrefix(name, "+") {
+				dir, name = name[:1], name[1:]
+			}
+			var def *SortField
+			for _, a := range allowed {
+				if a.Name == name {
+					def = a
+					break
+				}
+			}
+			if def == nil {
+				err = MergeErrors(err, InvalidEnumValueError("sort", name, allowedValues(names)))
+				continue
+			}
+			f := &SortField{Name: name, Descending: def.Descending, Nulls: def.Nulls}
+			if dir != "" {
+				f.Descending = dir == "-"
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields, err
+}
+
+// ParseFilters extracts the values of the filter fields given in the query string using the
+// "filter[field]" syntax, for example "filter[status]=active". The values are indexed by field
+// name. The generated code calls ParseFilters for the actions that define the Filterable DSL,
+// ParseFilters returns a bad request error if a field is not one of the allowed fields.
+func ParseFilters(params url.Values, allowed ...string) (map[string][]string, error) {
+	var err error
+	filters := make(map[string][]string)
+	for name, vals := range DeepObjectParams(params, "filter") {
+		if !isAllowed(name, allowed) {
+			err = MergeErrors(err, InvalidEnumValueError("filter", name, allowedValues(allowed)))
+			continue
+		}
+		filters[name] = vals
+	}
+	return filters, err
+}
+
+// isAllowed returns true if name is one of allowed.
+func isAllowed(name string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedValues returns the allowed field names in the form expected by InvalidEnumValueError.
+func allowedValues(allowed []string) []interface{} {
+	vals := make([]interface{}, len(allowed))
+	for i, a := range allowed {
+		vals[i] = a
+	}
+	return vals
+}
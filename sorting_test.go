@@ -0,0 +1,141 @@
+package goa_test
+
+import (
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseSort", func() {
+	var values []string
+	var fields []*goa.SortField
+	var err error
+
+	JustBeforeEach(func() {
+		fields, err = goa.ParseSort(values, "name", "created_at")
+	})
+
+	Context("with allowed fields", func() {
+		BeforeEach(func() {
+			values = []string{"-created_at, name", "+name"}
+		})
+
+		It("returns the sort fields in order", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fields).Should(Equal([]*goa.SortField{
+				{Name: "created_at", Descending: true},
+				{Name: "name"},
+				{Name: "name"},
+			}))
+		})
+	})
+
+	Context("with no value", func() {
+		BeforeEach(func() {
+			values = nil
+		})
+
+		It("returns no sort field", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fields).Should(BeEmpty())
+		})
+	})
+
+	Context("with a field that is not allowed", func() {
+		BeforeEach(func() {
+			values = []string{"name,-vintage"}
+		})
+
+		It("returns a bad request error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`value of sort must be one of "name", "created_at" but got value "vintage"`))
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+		})
+	})
+})
+
+var _ = Describe("ParseSortFields", func() {
+	var values []string
+	var fields []*goa.SortField
+	var err error
+
+	JustBeforeEach(func() {
+		fields, err = goa.ParseSortFields(values,
+			&goa.SortField{Name: "name"},
+			&goa.SortField{Name: "created_at", Descending: true, Nulls: goa.NullsLast},
+		)
+	})
+
+	Context("with fields that omit the direction", func() {
+		BeforeEach(func() {
+			values = []string{"created_at,name"}
+		})
+
+		It("uses the default sort options", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fields).Should(Equal([]*goa.SortField{
+				{Name: "created_at", Descending: true, Nulls: goa.NullsLast},
+				{Name: "name"},
+			}))
+		})
+	})
+
+	Context("with fields that set the direction", func() {
+		BeforeEach(func() {
+			values = []string{"+created_at,-name"}
+		})
+
+		It("uses the given direction and the default placement of null values", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fields).Should(Equal([]*goa.SortField{
+				{Name: "created_at", Nulls: goa.NullsLast},
+				{Name: "name", Descending: true},
+			}))
+		})
+	})
+
+	Context("with a field that is not allowed", func() {
+		BeforeEach(func() {
+			values = []string{"vintage"}
+		})
+
+		It("returns a bad request error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`value of sort must be one of "name", "created_at" but got value "vintage"`))
+		})
+	})
+})
+
+var _ = Describe("ParseFilters", func() {
+	var params url.Values
+	var filters map[string][]string
+	var err error
+
+	JustBeforeEach(func() {
+		filters, err = goa.ParseFilters(params, "status")
+	})
+
+	Context("with allowed fields", func() {
+		BeforeEach(func() {
+			params = url.Values{"filter[status]": {"active"}, "page": {"2"}}
+		})
+
+		It("returns the filter values", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(filters).Should(Equal(map[string][]string{"status": {"active"}}))
+		})
+	})
+
+	Context("with a field that is not allowed", func() {
+		BeforeEach(func() {
+			params = url.Values{"filter[color]": {"red"}}
+		})
+
+		It("returns a bad request error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`value of filter must be one of "status" but got value "color"`))
+		})
+	})
+})
@@ -0,0 +1,106 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// SparseFieldset returns the JSON representation of v restricted to the attributes listed in the
+// comma separated list fields as defined by JSON:API sparse fieldsets. The generated code calls
+// SparseFieldset to filter the success responses of the actions that define the SparseFieldsets DSL
+// when the request sets the "fields" query string parameter. v must be a value whose JSON
+// representation is an object or an array of objects, any other value is returned as is. The
+// result is made of maps and slices and must be encoded with a JSON encoder.
+func SparseFieldset(v interface{}, fields string) (interface{}, error) {
+	var keep []string
+	for _, f := range strings.Split(fields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			keep = append(keep, f)
+		}
+	}
+	return SelectFields(v, keep...)
+}
+
+// SelectFields returns the JSON representation of v restricted to the given attributes. The
+// generated code calls SelectFields to echo only the attributes updated by the request in the
+// responses of the actions that define the EchoUpdatedFields DSL. v must be a value whose JSON
+// representation is an object or an array of objects, any other value is returned as is. The
+// result is made of maps and slices and must be encoded with a JSON encoder.
+func SelectFields(v interface{}, fields ...string) (interface{}, error) {
+	raw, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		keep[f] = true
+	}
+	switch actual := raw.(type) {
+	case map[string]interface{}:
+		filterFields(actual, keep)
+	case []interface{}:
+		for _, e := range actual {
+			if m, ok := e.(map[string]interface{}); ok {
+				filterFields(m, keep)
+			}
+		}
+	default:
+		return v, nil
+	}
+	return raw, nil
+}
+
+// OmitFields returns the JSON representation of v without the given attributes. The generated code
+// calls OmitFields to remove the attributes whose values are sent as cookies from the response
+// bodies. v must be a value whose JSON representation is an object or an array of objects, any
+// other value is returned as is. The result is made of maps and slices and must be encoded with a
+// JSON encoder.
+func OmitFields(v interface{}, fields ...string) (interface{}, error) {
+	raw, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	omit := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		omit[f] = true
+	}
+	switch actual := raw.(type) {
+	case map[string]interface{}:
+		omitFields(actual, omit)
+	case []interface{}:
+		for _, e := range actual {
+			if m, ok := e.(map[string]interface{}); ok {
+				omitFields(m, omit)
+			}
+		}
+	default:
+		return v, nil
+	}
+	return raw, nil
+}
+
+// filterFields deletes the keys of m that are not in keep.
+func filterFields(m map[string]interface{}, keep map[string]bool) {
+	for k := range m {
+		if !keep[k] {
+			delete(m, k)
+		}
+	}
+}
+
+// jsonValue returns the JSON representation of v made of maps, slices and primitive values.
+// Numbers are represented with json.Number so that they are encoded back unchanged.
+func jsonValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var raw interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
@@ -0,0 +1,99 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SparseFieldset", func() {
+	type bottle struct {
+		ID      int     `json:"id"`
+		Name    string  `json:"name"`
+		Vintage *int    `json:"vintage,omitempty"`
+		Rating  float64 `json:"rating"`
+	}
+	vintage := 2012
+	b := &bottle{ID: 1, Name: "Number 8", Vintage: &vintage, Rating: 4.5}
+
+	var v interface{}
+	var fields string
+	var body string
+	var filterErr error
+
+	JustBeforeEach(func() {
+		var res interface{}
+		res, filterErr = goa.SparseFieldset(v, fields)
+		out, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		body = string(out)
+	})
+
+	Context("with an object", func() {
+		BeforeEach(func() {
+			v = b
+			fields = "id, name"
+		})
+
+		It("keeps the listed attributes only", func() {
+			Ω(filterErr).ShouldNot(HaveOccurred())
+			Ω(body).Should(Equal(`{"id":1,"name":"Number 8"}`))
+		})
+	})
+
+	Context("with a collection", func() {
+		BeforeEach(func() {
+			v = []*bottle{b, {ID: 2, Rating: 3}}
+			fields = "id,rating,unknown"
+		})
+
+		It("filters each element", func() {
+			Ω(filterErr).ShouldNot(HaveOccurred())
+			Ω(body).Should(Equal(`[{"id":1,"rating":4.5},{"id":2,"rating":3}]`))
+		})
+	})
+
+	Context("with a value that is not an object", func() {
+		BeforeEach(func() {
+			v = "foo"
+			fields = "id"
+		})
+
+		It("returns the value", func() {
+			Ω(filterErr).ShouldNot(HaveOccurred())
+			Ω(body).Should(Equal(`"foo"`))
+		})
+	})
+})
+
+var _ = Describe("OmitFields", func() {
+	It("removes the given attributes", func() {
+		v := map[string]interface{}{"id": 1, "session": "abc"}
+		res, err := goa.OmitFields(v, "session")
+		Ω(err).ShouldNot(HaveOccurred())
+		out, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(out)).Should(Equal(`{"id":1}`))
+	})
+})
+
+var _ = Describe("SelectFields", func() {
+	It("keeps the given attributes only", func() {
+		v := map[string]interface{}{"id": 1, "name": "foo", "vintage": 2012}
+		res, err := goa.SelectFields(v, "name", "vintage")
+		Ω(err).ShouldNot(HaveOccurred())
+		out, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(out)).Should(Equal(`{"name":"foo","vintage":2012}`))
+	})
+
+	It("removes all the attributes if none is given", func() {
+		res, err := goa.SelectFields(map[string]interface{}{"id": 1})
+		Ω(err).ShouldNot(HaveOccurred())
+		out, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(out)).Should(Equal(`{}`))
+	})
+})
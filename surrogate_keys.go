@@ -0,0 +1,46 @@
+package goa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SurrogateKeys returns the value of the Surrogate-Key header built from the given values. The
+// generated code calls SurrogateKeys with the values of the response attributes listed in the
+// design with the SurrogateKeys DSL. Values may be strings, integers or pointers to strings or
+// integers, nil pointers and empty values are skipped as well as duplicates. The keys are
+// separated with spaces, SurrogateKeys returns an empty string if there is no key.
+func SurrogateKeys(values ...interface{}) string {
+	keys := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		var key string
+		switch actual := v.(type) {
+		case nil:
+			continue
+		case string:
+			key = actual
+		case *string:
+			if actual == nil {
+				continue
+			}
+			key = *actual
+		case int:
+			key = strconv.Itoa(actual)
+		case *int:
+			if actual == nil {
+				continue
+			}
+			key = strconv.Itoa(*actual)
+		default:
+			key = fmt.Sprintf("%v", actual)
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return strings.Join(keys, " ")
+}
@@ -0,0 +1,50 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SurrogateKeys", func() {
+	var values []interface{}
+	var keys string
+
+	JustBeforeEach(func() {
+		keys = goa.SurrogateKeys(values...)
+	})
+
+	Context("with strings and integers", func() {
+		BeforeEach(func() {
+			name := "cellar"
+			id := 42
+			values = []interface{}{"bottle-1", &name, 7, &id}
+		})
+
+		It("joins the values with spaces", func() {
+			Ω(keys).Should(Equal("bottle-1 cellar 7 42"))
+		})
+	})
+
+	Context("with nil pointers, empty and duplicate values", func() {
+		BeforeEach(func() {
+			var name *string
+			var id *int
+			values = []interface{}{"a", name, "", id, "b", "a"}
+		})
+
+		It("skips them", func() {
+			Ω(keys).Should(Equal("a b"))
+		})
+	})
+
+	Context("with no value", func() {
+		BeforeEach(func() {
+			values = nil
+		})
+
+		It("returns an empty string", func() {
+			Ω(keys).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package goa
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// EncodeTransform converts a string value to its canonical form before it is encoded in a
+// response.
+type EncodeTransform func(string) (string, error)
+
+// encodeTransforms indexes the encode transforms by name.
+var encodeTransforms = map[string]EncodeTransform{
+	"e164":      transformE164,
+	"lowercase": func(s string) (string, error) { return strings.ToLower(s), nil },
+	"trim":      func(s string) (string, error) { return strings.TrimSpace(s), nil },
+	"uppercase": func(s string) (string, error) { return strings.ToUpper(s), nil },
+	"url":       transformURL,
+}
+
+// builtinEncodeTransforms records the names of the built-in encode transforms.
+var builtinEncodeTransforms = map[string]bool{"e164": true, "lowercase": true, "trim": true, "uppercase": true, "url": true}
+
+// encodeTransformsLock is the mutex used to access encodeTransforms.
+var encodeTransformsLock = &sync.RWMutex{}
+
+// RegisterEncodeTransform registers the custom encode transform with the given name. Services that
+// make use of transforms registered in the design with design.RegisterEncodeTransform must register
+// the same transforms with RegisterEncodeTransform before handling requests, typically in an init
+// function. RegisterEncodeTransform panics if name is the name of a built-in transform or if t is
+// nil.
+func RegisterEncodeTransform(name string, t EncodeTransform) {
+	if builtinEncodeTransforms[name] {
+		panic(fmt.Sprintf("goa: cannot register built-in encode transform %#v", name))
+	}
+	if t == nil {
+		panic(fmt.Sprintf("goa: nil encode transform %#v", name))
+	}
+	encodeTransformsLock.Lock()
+	defer encodeTransformsLock.Unlock()
+	encodeTransforms[name] = t
+}
+
+// ApplyEncodeTransforms returns the JSON representation of v where the string values of the given
+// attributes are normalized with the transform named by the corresponding map value. v itself is
+// not modified. v must be a value whose JSON representation is an object or an array of objects,
+// any other value is returned as is. The result is made of maps and slices and must be encoded
+// with a JSON encoder.
+// This function is intended for the generated code. User code should not need to call it directly.
+func ApplyEncodeTransforms(v interface{}, transforms map[string]string) (interface{}, error) {
+	raw, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	switch actual := raw.(type) {
+	case map[string]interface{}:
+		if err := transformFields(actual, transforms); err != nil {
+			return nil, err
+		}
+	case []interface{}:
+		for _, e := range actual {
+			if m, ok := e.(map[string]interface{}); ok {
+				if err := transformFields(m, transforms); err != nil {
+					return nil, err
+				}
+			}
+		}
+	default:
+		return v, nil
+	}
+	return raw, nil
+}
+
+// transformFields replaces the string values of the given fields of m with their transformed
+// values.
+func transformFields(m map[string]interface{}, transforms map[string]string) error {
+	for field, name := range transforms {
+		s, ok := m[field].(string)
+		if !ok {
+			continue
+		}
+		encodeTransformsLock.RLock()
+		t, ok := encodeTransforms[name]
+		encodeTransformsLock.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown encode transform %#v", name)
+		}
+		res, err := t(s)
+		if err != nil {
+			return fmt.Errorf("failed to transform %s: %s", field, err)
+		}
+		m[field] = res
+	}
+	return nil
+}
+
+// transformE164 formats phone numbers in E.164 format: a "+" followed by the country code and
+// subscriber number digits. Spaces, dots, dashes and parentheses are removed and a leading
+// international "00" prefix is replaced with "+".
+func transformE164(s string) (string, error) {
+	var digits []rune
+	plus := false
+	for i, r := range strings.TrimSpace(s) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, r)
+		case r == '+' && i == 0:
+			plus = true
+		case r == ' ' || r == '.' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("invalid phone number %#v", s)
+		}
+	}
+	if !plus && len(digits) > 2 && digits[0] == '0' && digits[1] == '0' {
+		plus = true
+		digits = digits[2:]
+	}
+	if !plus || len(digits) < 2 || len(digits) > 15 || digits[0] == '0' {
+		return "", fmt.Errorf("phone number %#v cannot be formatted as E.164, it must include the country code", s)
+	}
+	return "+" + string(digits), nil
+}
+
+// transformURL lowercases the scheme and host of the URL.
+func transformURL(s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), nil
+}
@@ -0,0 +1,80 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyEncodeTransforms", func() {
+	type contact struct {
+		Phone   string `json:"phone"`
+		Website string `json:"website"`
+		Name    string `json:"name"`
+	}
+	var c *contact
+	var transforms map[string]string
+
+	BeforeEach(func() {
+		c = &contact{Phone: "+1 (415) 555-0100", Website: "https://Example.COM/About", Name: "Alice"}
+		transforms = map[string]string{"phone": "e164", "website": "url"}
+	})
+
+	It("encodes the transformed values and leaves the value untouched", func() {
+		res, err := goa.ApplyEncodeTransforms(c, transforms)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"name":"Alice","phone":"+14155550100","website":"https://example.com/About"}`))
+		Ω(c.Phone).Should(Equal("+1 (415) 555-0100"))
+		Ω(c.Website).Should(Equal("https://Example.COM/About"))
+	})
+
+	It("transforms the elements of collections", func() {
+		res, err := goa.ApplyEncodeTransforms([]*contact{c, {Phone: "0033 1 23 45 67 89"}}, transforms)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(ContainSubstring(`"phone":"+14155550100"`))
+		Ω(string(b)).Should(ContainSubstring(`"phone":"+33123456789"`))
+	})
+
+	Context("with a phone number without country code", func() {
+		BeforeEach(func() {
+			c.Phone = "415 555 0100"
+		})
+
+		It("returns an error", func() {
+			_, err := goa.ApplyEncodeTransforms(c, transforms)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("cannot be formatted as E.164"))
+		})
+	})
+
+	Context("with a custom transform", func() {
+		BeforeEach(func() {
+			goa.RegisterEncodeTransform("initials", func(s string) (string, error) {
+				var initials string
+				for _, w := range strings.Fields(s) {
+					initials += w[:1]
+				}
+				return initials, nil
+			})
+			c.Name = "Alice Liddell"
+			transforms = map[string]string{"name": "initials"}
+		})
+
+		It("uses the registered transform", func() {
+			res, err := goa.ApplyEncodeTransforms(c, transforms)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(res.(map[string]interface{})["name"]).Should(Equal("AL"))
+		})
+	})
+
+	It("panics when registering a built-in transform", func() {
+		Ω(func() { goa.RegisterEncodeTransform("e164", func(s string) (string, error) { return s, nil }) }).Should(Panic())
+	})
+})
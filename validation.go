@@ -6,6 +6,7 @@ import (
 	"net/mail"
 	"net/url"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -54,6 +55,9 @@ const (
 
 	// FormatRFC1123 defines RFC1123 date time values.
 	FormatRFC1123 = "rfc1123"
+
+	// FormatDecimal defines decimal number values represented as strings, e.g. "-12.50".
+	FormatDecimal = "decimal"
 )
 
 var (
@@ -62,6 +66,9 @@ var (
 
 	// Simple regular expression for IPv4 values, more rigorous checking is done via net.ParseIP
 	ipv4Regex = regexp.MustCompile(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`)
+
+	// Regular expression used to validate decimal values
+	decimalRegex = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
 )
 
 // ValidateFormat validates a string against a standard format.
@@ -80,6 +87,9 @@ var (
 //     - "cidr": RFC4632 and RFC4291 CIDR notation IP address value
 //     - "regexp": Regular expression syntax accepted by RE2
 //     - "rfc1123": RFC1123 date time value
+//     - "decimal": decimal number value, e.g. "-12.50"
+//
+// Additional formats may be registered with RegisterFormat.
 func ValidateFormat(f Format, val string) error {
 	var err error
 	switch f {
@@ -121,8 +131,18 @@ func ValidateFormat(f Format, val string) error {
 		_, err = regexp.Compile(val)
 	case FormatRFC1123:
 		_, err = time.Parse(time.RFC1123, val)
+	case FormatDecimal:
+		if !decimalRegex.MatchString(val) {
+			err = fmt.Errorf("\"%s\" is an invalid decimal value", val)
+		}
 	default:
-		return fmt.Errorf("unknown format %#v", f)
+		customFormatsLock.RLock()
+		validate, ok := customFormats[f]
+		customFormatsLock.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown format %#v", f)
+		}
+		err = validate(val)
 	}
 	if err != nil {
 		go IncrCounter([]string{"goa", "validation", "error", string(f)}, 1.0)
@@ -131,6 +151,46 @@ func ValidateFormat(f Format, val string) error {
 	return nil
 }
 
+// customFormats records the formats registered with RegisterFormat.
+var customFormats = make(map[Format]func(string) error)
+
+// customFormatsLock is the mutex used to access customFormats
+var customFormatsLock = &sync.RWMutex{}
+
+// RegisterFormat registers the function used by ValidateFormat to validate values of the custom
+// format f. Services that make use of formats registered in the design with design.RegisterFormat
+// must register the same formats with RegisterFormat before handling requests, typically in an
+// init function. RegisterFormat panics if f is the name of a built-in format or if validate is
+// nil.
+func RegisterFormat(f Format, validate func(string) error) {
+	switch f {
+	case FormatDate, FormatDateTime, FormatUUID, FormatEmail, FormatHostname, FormatIPv4,
+		FormatIPv6, FormatIP, FormatURI, FormatMAC, FormatCIDR, FormatRegexp, FormatRFC1123,
+		FormatDecimal:
+		panic(fmt.Sprintf("goa: cannot register built-in format %#v", f))
+	}
+	if validate == nil {
+		panic(fmt.Sprintf("goa: nil validation function for format %#v", f))
+	}
+	customFormatsLock.Lock()
+	defer customFormatsLock.Unlock()
+	customFormats[f] = validate
+}
+
+// DecimalDigits returns the precision and scale of the decimal value val, that is the total number
+// of significant digits and the number of fractional digits. Leading zeros of the integer part are
+// not significant, trailing zeros of the fractional part are. val must be a valid decimal value,
+// see FormatDecimal.
+func DecimalDigits(val string) (precision, scale int) {
+	val = strings.TrimLeft(val, "+-")
+	integer, fraction := val, ""
+	if i := strings.IndexByte(val, '.'); i >= 0 {
+		integer, fraction = val[:i], val[i+1:]
+	}
+	integer = strings.TrimLeft(integer, "0")
+	return len(integer) + len(fraction), len(fraction)
+}
+
 // knownPatterns records the compiled patterns.
 // TBD: refactor all this so that the generated code initializes the map on start to get rid of the
 // need for a RW mutex.
@@ -1,6 +1,9 @@
 package goa_test
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/goadesign/goa"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -395,4 +398,144 @@ var _ = Describe("ValidateFormat", func() {
 			})
 		})
 	})
+
+	Context("Decimal", func() {
+		BeforeEach(func() {
+			f = goa.FormatDecimal
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				val = "12.5e3"
+			})
+
+			It("does not validate", func() {
+				Ω(valErr).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a valid value", func() {
+			BeforeEach(func() {
+				val = "-012.50"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("custom format", func() {
+		BeforeEach(func() {
+			f = goa.Format("isbn")
+			goa.RegisterFormat(f, validateISBN)
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				val = "978-0-306-40615-6"
+			})
+
+			It("does not validates", func() {
+				Ω(valErr).Should(HaveOccurred())
+				Ω(valErr.Error()).Should(ContainSubstring("invalid isbn value"))
+			})
+		})
+
+		Context("with a valid value", func() {
+			BeforeEach(func() {
+				val = "978-0-306-40615-7"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("unknown format", func() {
+		BeforeEach(func() {
+			f = goa.Format("unknown")
+			val = "foo"
+		})
+
+		It("does not validates", func() {
+			Ω(valErr).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("RegisterFormat", func() {
+	It("panics when registering a built-in format", func() {
+		Ω(func() { goa.RegisterFormat(goa.FormatEmail, validateISBN) }).Should(Panic())
+	})
+
+	It("panics when the validation function is nil", func() {
+		Ω(func() { goa.RegisterFormat("isbn", nil) }).Should(Panic())
+	})
+})
+
+var _ = Describe("DecimalDigits", func() {
+	var val string
+	var precision, scale int
+
+	JustBeforeEach(func() {
+		precision, scale = goa.DecimalDigits(val)
+	})
+
+	Context("with an integer value", func() {
+		BeforeEach(func() {
+			val = "-123"
+		})
+
+		It("has no fractional digits", func() {
+			Ω(precision).Should(Equal(3))
+			Ω(scale).Should(Equal(0))
+		})
+	})
+
+	Context("with leading and trailing zeros", func() {
+		BeforeEach(func() {
+			val = "0012.50"
+		})
+
+		It("counts the trailing zeros only", func() {
+			Ω(precision).Should(Equal(4))
+			Ω(scale).Should(Equal(2))
+		})
+	})
+
+	Context("with a zero integer part", func() {
+		BeforeEach(func() {
+			val = "0.05"
+		})
+
+		It("counts the fractional digits only", func() {
+			Ω(precision).Should(Equal(2))
+			Ω(scale).Should(Equal(2))
+		})
+	})
 })
+
+// validateISBN validates ISBN-13 values.
+func validateISBN(val string) error {
+	digits := strings.Replace(val, "-", "", -1)
+	if len(digits) != 13 {
+		return fmt.Errorf("%q must contain 13 digits", val)
+	}
+	sum := 0
+	for i, c := range digits {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("%q must contain only digits", val)
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	if sum%10 != 0 {
+		return fmt.Errorf("%q has an invalid check digit", val)
+	}
+	return nil
+}
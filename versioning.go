@@ -0,0 +1,90 @@
+package goa
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// versionedHandler dispatches the requests sent to one method and path to the handlers of the
+// resource versions.
+type versionedHandler struct {
+	// pattern is the media type pattern that carries the version.
+	pattern string
+	// handlers indexes the handlers by lower case version.
+	handlers map[string]MuxHandler
+	// first is the version registered first.
+	first string
+}
+
+// HandleVersion registers the handler of the given version of the action routed to the given
+// method and path. The handlers of all the versions registered for the same method and path share
+// one mux handler that dispatches the requests to the version selected by their Accept header as
+// described by mediaPattern, see RequestVersion. Requests that do not select a version are
+// dispatched to the handler registered with an empty version if any, to the handler registered
+// first otherwise. Requests that select a version that has no handler are rejected with a
+// ErrUnsupportedVersion error.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func (service *Service) HandleVersion(mediaPattern, version, method, path string, h MuxHandler) {
+	if service.versions == nil {
+		service.versions = make(map[string]*versionedHandler)
+	}
+	key := method + path
+	vh, ok := service.versions[key]
+	if !ok {
+		vh = &versionedHandler{pattern: mediaPattern, handlers: make(map[string]MuxHandler), first: version}
+		service.versions[key] = vh
+		service.Mux.Handle(method, path, func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+			vh.dispatch(service, rw, req, params)
+		})
+	}
+	vh.handlers[strings.ToLower(version)] = h
+}
+
+// dispatch invokes the handler of the version selected by the request.
+func (vh *versionedHandler) dispatch(service *Service, rw http.ResponseWriter, req *http.Request, params url.Values) {
+	version := RequestVersion(req, vh.pattern)
+	if version == "" {
+		if h, ok := vh.handlers[""]; ok {
+			h(rw, req, params)
+			return
+		}
+		version = vh.first
+	}
+	h, ok := vh.handlers[version]
+	if !ok {
+		ctx := NewContext(service.Context, rw, req, params)
+		err := ErrUnsupportedVersion(fmt.Sprintf("unsupported version %#v", version), "version", version)
+		service.Send(ctx, http.StatusNotAcceptable, err)
+		return
+	}
+	h(rw, req, params)
+}
+
+// RequestVersion returns the version selected by the media types listed in the Accept header of
+// the given request, the empty string if none. mediaPattern is the pattern of the media types that
+// carry the version, "{version}" stands for the version, for example
+// "application/vnd.cellar.{version}+json". The version is returned in lower case.
+func RequestVersion(req *http.Request, mediaPattern string) string {
+	i := strings.Index(mediaPattern, "{version}")
+	if i < 0 {
+		return ""
+	}
+	prefix := strings.ToLower(mediaPattern[:i])
+	suffix := strings.ToLower(mediaPattern[i+len("{version}"):])
+	for _, accept := range req.Header["Accept"] {
+		for _, mt := range strings.Split(accept, ",") {
+			mt, _, err := mime.ParseMediaType(strings.TrimSpace(mt))
+			if err != nil {
+				continue
+			}
+			if len(mt) > len(prefix)+len(suffix) && strings.HasPrefix(mt, prefix) && strings.HasSuffix(mt, suffix) {
+				return mt[len(prefix) : len(mt)-len(suffix)]
+			}
+		}
+	}
+	return ""
+}
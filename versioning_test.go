@@ -0,0 +1,98 @@
+package goa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HandleVersion", func() {
+	const pattern = "application/vnd.cellar.{version}+json"
+	var service *goa.Service
+	var accept string
+	var rw *httptest.ResponseRecorder
+
+	handler := func(name string) goa.MuxHandler {
+		return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+			rw.Write([]byte(name + " " + params.Get("id")))
+		}
+	}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		service.HandleVersion(pattern, "v1", "GET", "/bottles/:id", handler("v1"))
+		service.HandleVersion(pattern, "v2", "GET", "/bottles/:id", handler("v2"))
+		accept = ""
+	})
+
+	JustBeforeEach(func() {
+		req, _ := http.NewRequest("GET", "/bottles/42", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rw = httptest.NewRecorder()
+		service.Mux.ServeHTTP(rw, req)
+	})
+
+	Context("with a request that selects a version", func() {
+		BeforeEach(func() {
+			accept = "text/plain, application/vnd.cellar.v2+json; q=0.9"
+		})
+
+		It("dispatches the request to the version", func() {
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Body.String()).Should(Equal("v2 42"))
+		})
+	})
+
+	Context("with a request that selects another version", func() {
+		BeforeEach(func() {
+			accept = "application/vnd.cellar.V1+json"
+		})
+
+		It("dispatches the request to the version", func() {
+			Ω(rw.Body.String()).Should(Equal("v1 42"))
+		})
+	})
+
+	Context("with a request that does not select a version", func() {
+		It("dispatches the request to the first version", func() {
+			Ω(rw.Body.String()).Should(Equal("v1 42"))
+		})
+
+		Context("and an unversioned handler", func() {
+			BeforeEach(func() {
+				service.HandleVersion(pattern, "", "GET", "/bottles/:id", handler("default"))
+			})
+
+			It("dispatches the request to the unversioned handler", func() {
+				Ω(rw.Body.String()).Should(Equal("default 42"))
+			})
+		})
+	})
+
+	Context("with a request that selects an unknown version", func() {
+		BeforeEach(func() {
+			accept = "application/vnd.cellar.v3+json"
+		})
+
+		It("responds with 406", func() {
+			Ω(rw.Code).Should(Equal(406))
+			Ω(rw.Body.String()).Should(ContainSubstring("unsupported_version"))
+		})
+	})
+})
+
+var _ = Describe("RequestVersion", func() {
+	It("extracts the version from the Accept header", func() {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "application/vnd.cellar.2016-01-01+json")
+		Ω(goa.RequestVersion(req, "application/vnd.cellar.{version}+json")).Should(Equal("2016-01-01"))
+		Ω(goa.RequestVersion(req, "application/vnd.other.{version}+json")).Should(BeEmpty())
+	})
+})